@@ -0,0 +1,66 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/entrypoint"
+	"github.com/apache/incubator-yunikorn-core/pkg/log"
+)
+
+var (
+	active    = flag.String("active", "", "base URL of the REST API of the active scheduler instance, e.g. http://yunikorn:9080")
+	interval  = flag.Duration("interval", 5*time.Second, "refresh interval of the snapshot of the active instance")
+	tokenFile = flag.String("token-file", "", "file with the bearer token of an admin of the active instance, required if authentication is enabled")
+)
+
+// Run the web service as a read-only replica of an active scheduler instance.
+func main() {
+	flag.Parse()
+	if *active == "" || *interval <= 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	var token string
+	if *tokenFile != "" {
+		content, err := ioutil.ReadFile(*tokenFile)
+		if err != nil {
+			log.Logger().Fatal("failed to read the token file",
+				zap.String("file", *tokenFile),
+				zap.Error(err))
+		}
+		token = strings.TrimSpace(string(content))
+	}
+	services := entrypoint.StartReplicaWebService(*active, token, *interval)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	<-signals
+	log.Logger().Info("stopping read-only web application replica")
+	services.StopAll()
+}
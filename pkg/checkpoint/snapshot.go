@@ -0,0 +1,84 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package checkpoint
+
+import (
+	"time"
+)
+
+// Version of the snapshot format, a snapshot with a different version is not restored.
+const SnapshotVersion = 1
+
+// A snapshot of the scheduler state. The snapshot only contains plain values and can be stored as is.
+type Snapshot struct {
+	Version    int               `json:"version"`
+	Created    time.Time         `json:"created"`
+	Partitions []*PartitionState `json:"partitions"`
+}
+
+// The state of one partition in the snapshot.
+type PartitionState struct {
+	Name         string              `json:"name"`
+	RmID         string              `json:"rmID"`
+	Queues       []string            `json:"queues,omitempty"`
+	Applications []*ApplicationState `json:"applications,omitempty"`
+	Reservations []*ReservationState `json:"reservations,omitempty"`
+}
+
+// The state of an application and its allocations.
+type ApplicationState struct {
+	ApplicationID  string             `json:"applicationID"`
+	QueueName      string             `json:"queueName"`
+	User           string             `json:"user"`
+	Groups         []string           `json:"groups,omitempty"`
+	Tags           map[string]string  `json:"tags,omitempty"`
+	State          string             `json:"state"`
+	SubmissionTime time.Time          `json:"submissionTime"`
+	Allocations    []*AllocationState `json:"allocations,omitempty"`
+}
+
+// The state of an allocation: resources are stored as a simple name to quantity map.
+type AllocationState struct {
+	AllocationKey string           `json:"allocationKey"`
+	UUID          string           `json:"uuid"`
+	NodeID        string           `json:"nodeID"`
+	Resource      map[string]int64 `json:"resource,omitempty"`
+	Placeholder   bool             `json:"placeholder,omitempty"`
+	TaskGroupName string           `json:"taskGroupName,omitempty"`
+}
+
+// The state of a reservation of an ask on a node.
+type ReservationState struct {
+	ApplicationID string `json:"applicationID"`
+	AllocationKey string `json:"allocationKey"`
+	NodeID        string `json:"nodeID"`
+}
+
+// Get the state of a partition from the snapshot, returns nil if the partition is not part of the snapshot.
+func (s *Snapshot) GetPartition(name string) *PartitionState {
+	if s == nil {
+		return nil
+	}
+	for _, part := range s.Partitions {
+		if part.Name == name {
+			return part
+		}
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package checkpoint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common"
+)
+
+// Environment variables used to configure the scheduler state store.
+const (
+	EnvFile               = "SCHEDULER_STATE_FILE"
+	EnvInterval           = "SCHEDULER_STATE_INTERVAL"
+	EnvRestoreGracePeriod = "SCHEDULER_STATE_RESTORE_GRACE_PERIOD"
+)
+
+const (
+	defaultInterval           = time.Minute
+	defaultRestoreGracePeriod = 5 * time.Minute
+)
+
+// A store persists snapshots of the scheduler state.
+// Save is only called from the checkpoint service routine, Load is called once on startup.
+type Store interface {
+	Save(snapshot *Snapshot) error
+	Load() (*Snapshot, error)
+	Close() error
+}
+
+// Create the store based on the environment, returns nil if checkpointing is not configured.
+func CreateStoreFromEnv() (Store, error) {
+	if path := os.Getenv(EnvFile); path != "" {
+		return NewFileStore(path)
+	}
+	return nil, nil
+}
+
+// Get the interval between two snapshots from the environment.
+func GetInterval() time.Duration {
+	interval := common.GetDurationEnvVar(EnvInterval, defaultInterval)
+	if interval == 0 {
+		return defaultInterval
+	}
+	return interval
+}
+
+// Get the time restored applications wait for the RM to add them again before they are removed.
+func GetRestoreGracePeriod() time.Duration {
+	return common.GetDurationEnvVar(EnvRestoreGracePeriod, defaultRestoreGracePeriod)
+}
+
+// The file store keeps the last snapshot as a JSON document.
+// The snapshot is written to a temporary file first and then renamed to never leave a partial snapshot behind.
+type fileStore struct {
+	path string
+
+	sync.Mutex
+}
+
+func NewFileStore(path string) (Store, error) {
+	dir := filepath.Dir(path)
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("state store location %s is not a directory", dir)
+	}
+	return &fileStore{path: path}, nil
+}
+
+func (fs *fileStore) Save(snapshot *Snapshot) error {
+	fs.Lock()
+	defer fs.Unlock()
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(fs.path), filepath.Base(fs.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), fs.path)
+}
+
+// Load the last snapshot, returns nil if no snapshot was stored yet or the snapshot has a different version.
+func (fs *fileStore) Load() (*Snapshot, error) {
+	fs.Lock()
+	defer fs.Unlock()
+	data, err := ioutil.ReadFile(fs.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	snapshot := &Snapshot{}
+	if err = json.Unmarshal(data, snapshot); err != nil {
+		return nil, fmt.Errorf("state store file %s is corrupt: %v", fs.path, err)
+	}
+	if snapshot.Version != SnapshotVersion {
+		return nil, nil
+	}
+	return snapshot, nil
+}
+
+func (fs *fileStore) Close() error {
+	return nil
+}
@@ -0,0 +1,112 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package checkpoint
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+func TestFileStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "state-store")
+	assert.NilError(t, err, "failed to create temp dir")
+	defer os.RemoveAll(dir)
+
+	_, err = NewFileStore(filepath.Join(dir, "missing", "state.json"))
+	assert.Assert(t, err != nil, "store in a non existing directory should fail")
+
+	path := filepath.Join(dir, "state.json")
+	store, err := NewFileStore(path)
+	assert.NilError(t, err, "failed to create file store")
+	var snapshot *Snapshot
+	snapshot, err = store.Load()
+	assert.NilError(t, err, "load without a stored snapshot should not fail")
+	assert.Assert(t, snapshot == nil, "no snapshot should be returned before saving")
+
+	saved := &Snapshot{
+		Version: SnapshotVersion,
+		Created: time.Now().Truncate(time.Second).UTC(),
+		Partitions: []*PartitionState{{
+			Name:   "[rm-1]default",
+			RmID:   "rm-1",
+			Queues: []string{"root.dynamic"},
+			Applications: []*ApplicationState{{
+				ApplicationID: "app-1",
+				QueueName:     "root.dynamic",
+				User:          "testuser",
+				Tags:          map[string]string{"tag": "value"},
+				State:         "Running",
+				Allocations: []*AllocationState{{
+					AllocationKey: "alloc-1",
+					UUID:          "uuid-1",
+					NodeID:        "node-1",
+					Resource:      map[string]int64{"memory": 10},
+				}},
+			}},
+			Reservations: []*ReservationState{{ApplicationID: "app-1", AllocationKey: "alloc-2", NodeID: "node-1"}},
+		}},
+	}
+	assert.NilError(t, store.Save(saved), "save of snapshot failed")
+	snapshot, err = store.Load()
+	assert.NilError(t, err, "load of snapshot failed")
+	assert.DeepEqual(t, snapshot, saved)
+	assert.Assert(t, snapshot.GetPartition("[rm-1]default") != nil, "partition not found in snapshot")
+	assert.Assert(t, snapshot.GetPartition("unknown") == nil, "unknown partition found in snapshot")
+	files, err := ioutil.ReadDir(dir)
+	assert.NilError(t, err, "failed to read temp dir")
+	assert.Equal(t, len(files), 1, "temporary snapshot file not cleaned up")
+
+	// snapshots of a different version are ignored
+	saved.Version = SnapshotVersion + 1
+	assert.NilError(t, store.Save(saved), "save of snapshot failed")
+	snapshot, err = store.Load()
+	assert.NilError(t, err, "load of snapshot failed")
+	assert.Assert(t, snapshot == nil, "snapshot with a different version should not be returned")
+
+	// a corrupt file fails the load
+	assert.NilError(t, ioutil.WriteFile(path, []byte("{"), 0600), "failed to write corrupt file")
+	_, err = store.Load()
+	assert.ErrorContains(t, err, "corrupt")
+	assert.NilError(t, store.Close(), "close of file store failed")
+}
+
+func TestCreateStoreFromEnv(t *testing.T) {
+	store, err := CreateStoreFromEnv()
+	assert.NilError(t, err, "unconfigured store should not fail")
+	assert.Assert(t, store == nil, "store should not be created without configuration")
+
+	dir, err := ioutil.TempDir("", "state-store")
+	assert.NilError(t, err, "failed to create temp dir")
+	defer os.RemoveAll(dir)
+	os.Setenv(EnvFile, filepath.Join(dir, "state.json"))
+	defer os.Unsetenv(EnvFile)
+	store, err = CreateStoreFromEnv()
+	assert.NilError(t, err, "file store creation failed")
+	assert.Assert(t, store != nil, "file store should be created")
+
+	assert.Equal(t, GetInterval(), defaultInterval)
+	os.Setenv(EnvInterval, "10s")
+	defer os.Unsetenv(EnvInterval)
+	assert.Equal(t, GetInterval(), 10*time.Second)
+}
@@ -25,6 +25,7 @@ import (
 	"os"
 	"path"
 	"strings"
+	"time"
 
 	"go.uber.org/zap"
 	"gopkg.in/yaml.v2"
@@ -34,30 +35,220 @@ import (
 
 // The configuration can contain multiple partitions. Each partition contains the queue definition for a logical
 // set of scheduler resources.
+// The redaction section controls the fields hidden in the REST API responses.
+// The authentication section controls the access to the REST API endpoints that change the scheduler state.
+// The placement rule sets are a library of named placement rule lists that partitions can reference.
+// The feature gates override the gate states set in the environment. The gates are shared by all RMs: the last
+// loaded configuration sets them.
 type SchedulerConfig struct {
-	Partitions []PartitionConfig
-	Checksum   string `yaml:",omitempty" json:",omitempty"`
+	Partitions        []PartitionConfig
+	PlacementRuleSets []PlacementRuleSet   `yaml:",omitempty" json:",omitempty"`
+	Redaction         RedactionConfig      `yaml:",omitempty" json:",omitempty"`
+	Authentication    AuthenticationConfig `yaml:",omitempty" json:",omitempty"`
+	Tracing           TracingConfig        `yaml:",omitempty" json:",omitempty"`
+	WebService        WebServiceConfig     `yaml:",omitempty" json:",omitempty"`
+	FeatureGates      map[string]bool      `yaml:",omitempty" json:",omitempty"`
+	Checksum          string               `yaml:",omitempty" json:",omitempty"`
+
+	content []byte // the configuration as stored, before validation changed it
+}
+
+// A named set of placement rules shared between partitions
+// - name: the name the partitions use to reference the set
+// - rules: the placement rules in the order they are evaluated
+type PlacementRuleSet struct {
+	Name  string
+	Rules []PlacementRule
+}
+
+// REST API redaction section
+// - enabled: redact the sensitive fields in the REST API responses for callers that are not an admin
+// - adminacl: ACL for the callers that see the responses without redaction, the caller is identified by the
+// bearer token of the request as defined in the authentication section
+// - tags: regular expressions, tags and properties with a key that matches an expression are removed
+type RedactionConfig struct {
+	Enabled  bool
+	AdminACL string   `yaml:",omitempty" json:",omitempty"`
+	Tags     []string `yaml:",omitempty" json:",omitempty"`
+}
+
+// REST API authentication section
+// - enabled: callers of the endpoints that change the scheduler state must authenticate with a bearer token
+// - adminacl: ACL for the authenticated callers that are allowed to change the scheduler state
+// - tokens: static bearer tokens, only used if the shim has not registered an authentication plugin
+type AuthenticationConfig struct {
+	Enabled  bool
+	AdminACL string        `yaml:",omitempty" json:",omitempty"`
+	Tokens   []TokenConfig `yaml:",omitempty" json:",omitempty"`
+}
+
+// Static bearer token, the token itself is not part of the configuration
+// - sha256: the hex encoded SHA-256 hash of the token
+// - user: the user the token belongs to
+// - groups: the groups of the user
+type TokenConfig struct {
+	SHA256 string
+	User   string
+	Groups []string `yaml:",omitempty" json:",omitempty"`
+}
+
+// Scheduling cycle tracing section
+// - enabled: trace the scheduling cycles and report the spans to the collector
+// - mode: Sampling (default), Debug or DebugWithFilter, see the trace package for details
+// - collectorendpoint: the HTTP URL of the Jaeger collector to send the spans to, if not set the standard JAEGER_*
+// environment variables are used to configure the reporter
+// The spans are reported by the Jaeger client in the Jaeger format. OpenTelemetry OTLP export is not supported, an
+// OpenTelemetry collector must be configured with a Jaeger receiver to accept the spans.
+// - filtertags: tags a trace must have to be reported in the DebugWithFilter mode
+type TracingConfig struct {
+	Enabled           bool
+	Mode              string            `yaml:",omitempty" json:",omitempty"`
+	CollectorEndpoint string            `yaml:",omitempty" json:",omitempty"`
+	FilterTags        map[string]string `yaml:",omitempty" json:",omitempty"`
+}
+
+// REST API web service section, changes are applied on a config reload
+// - address: the host and port the web service listens on, defaults to :9080
+// - allowedorigins: the origins allowed to make cross-origin requests, an origin is written as scheme://host[:port]
+// or * to allow all origins, defaults to all origins
+// - tls: the certificate of the web service, the web service listens without TLS if not set
+type WebServiceConfig struct {
+	Address        string        `yaml:",omitempty" json:",omitempty"`
+	AllowedOrigins []string      `yaml:",omitempty" json:",omitempty"`
+	TLS            WebServiceTLS `yaml:",omitempty" json:",omitempty"`
+}
+
+// Web service TLS section: the certificate and key are either files or set inline as PEM, the files are used if
+// both are set. A changed certificate is applied on a config reload, TLS can only be turned on or off by a restart.
+// - certfile, keyfile: the paths of the PEM encoded certificate chain and private key
+// - cert, key: the PEM encoded certificate chain and private key
+// - reloadinterval: the interval to check the files for a rotated certificate, written as a duration (i.e. 1m),
+// defaults to 1m
+type WebServiceTLS struct {
+	CertFile       string        `yaml:",omitempty" json:",omitempty"`
+	KeyFile        string        `yaml:",omitempty" json:",omitempty"`
+	Cert           string        `yaml:",omitempty" json:",omitempty"`
+	Key            string        `yaml:",omitempty" json:",omitempty"`
+	ReloadInterval time.Duration `yaml:",omitempty" json:",omitempty"`
+}
+
+// Check if the certificate is set.
+func (wt WebServiceTLS) IsSet() bool {
+	return wt.CertFile != "" || wt.KeyFile != "" || wt.Cert != "" || wt.Key != ""
 }
 
 // The partition object for each partition:
 // - the name of the partition
 // - a list of sub or child queues
 // - a list of placement rule definition objects
+// - the name of a shared placement rule set: the placement rules of the partition override the rules with the same
+// name in the set, other rules of the partition are added after the rules of the set
 // - a list of users specifying limits on the partition
 // - the preemption configuration for the partition
+// - the parallel allocation configuration for the partition
+// - the cleanup configuration for the dynamic queues in the partition
+// - the system queue configuration for the partition
+// - a list of node attributes the partition resources are grouped by
+// - the policy for applications with a user that cannot be resolved
 type PartitionConfig struct {
-	Name           string
-	Queues         []QueueConfig
-	PlacementRules []PlacementRule           `yaml:",omitempty" json:",omitempty"`
-	Limits         []Limit                   `yaml:",omitempty" json:",omitempty"`
-	Preemption     PartitionPreemptionConfig `yaml:",omitempty" json:",omitempty"`
-	NodeSortPolicy NodeSortingPolicy         `yaml:",omitempty" json:",omitempty"`
+	Name               string
+	Queues             []QueueConfig
+	PlacementRules     []PlacementRule           `yaml:",omitempty" json:",omitempty"`
+	PlacementRuleSet   string                    `yaml:",omitempty" json:",omitempty"`
+	Limits             []Limit                   `yaml:",omitempty" json:",omitempty"`
+	Preemption         PartitionPreemptionConfig `yaml:",omitempty" json:",omitempty"`
+	NodeSortPolicy     NodeSortingPolicy         `yaml:",omitempty" json:",omitempty"`
+	ParallelAllocation ParallelAllocationConfig  `yaml:",omitempty" json:",omitempty"`
+	QueueCleanup       QueueCleanupConfig        `yaml:",omitempty" json:",omitempty"`
+	SystemQueue        SystemQueueConfig         `yaml:",omitempty" json:",omitempty"`
+	NodeGroups         []string                  `yaml:",omitempty" json:",omitempty"`
+	UnresolvedUser     UnresolvedUserConfig      `yaml:",omitempty" json:",omitempty"`
+	DynamicQueues      DynamicQueuesConfig       `yaml:",omitempty" json:",omitempty"`
+	Recovery           RecoveryConfig            `yaml:",omitempty" json:",omitempty"`
+	IdleApplications   IdleApplicationsConfig    `yaml:",omitempty" json:",omitempty"`
+	Scheduling         PartitionSchedulingConfig `yaml:",omitempty" json:",omitempty"`
 }
 
 type PartitionPreemptionConfig struct {
 	Enabled bool
 }
 
+// Parallel allocation section
+// - enabled: evaluate the leaf queues of the partition concurrently
+// - workers: the maximum number of leaf queues evaluated at the same time (defaults to the number of CPUs)
+type ParallelAllocationConfig struct {
+	Enabled bool
+	Workers int `yaml:",omitempty" json:",omitempty"`
+}
+
+// Queue cleanup section
+// - idletimeout: the time a dynamic leaf queue must be without applications before it is removed,
+// written as a duration (i.e. 30m), defaults to 0 which removes the queue on the first cleanup run
+type QueueCleanupConfig struct {
+	IdleTimeout time.Duration `yaml:",omitempty" json:",omitempty"`
+}
+
+// Recovery section
+// - window: the time after an application was recovered during which its asks are prioritized over the asks of
+// applications that were not recovered, written as a duration (i.e. 10m), defaults to 0 which disables it
+// - boost: the priority added to the pending asks of a recovered application in the queues that sort applications
+// on priority, queues with other sort policies move the recovered applications in front of the other applications
+type RecoveryConfig struct {
+	Window time.Duration `yaml:",omitempty" json:",omitempty"`
+	Boost  int32         `yaml:",omitempty" json:",omitempty"`
+}
+
+// Idle applications section
+// - timeout: the time an application with allocations can go without new asks or releases before it is checked for
+// being idle, written as a duration (i.e. 1h), defaults to 0 which disables the detection
+// - reclaim: the fraction of the allocated resources of an idle application that is released by preempting its
+// newest, lowest priority allocations, between 0 and 1, defaults to 0 which only reports idle applications
+// An application is only idle when the RM also flags its usage as idle through the application usage plugin.
+// The reclaim can be disabled per leaf queue with the application.idle.reclaim property.
+type IdleApplicationsConfig struct {
+	Timeout time.Duration `yaml:",omitempty" json:",omitempty"`
+	Reclaim float64       `yaml:",omitempty" json:",omitempty"`
+}
+
+// Scheduling section
+// - disabled: no allocation cycles are run for the partition, existing allocations are not changed and new asks
+// are queued until scheduling is enabled again
+type PartitionSchedulingConfig struct {
+	Disabled bool `yaml:",omitempty" json:",omitempty"`
+}
+
+// System queue section
+// - enabled: the core creates the root.system leaf queue for the applications the RM flags as system applications
+// - guaranteed: the guaranteed resources of the system queue
+// - submitacl: the users and groups, normally the RM identity, that can run system applications
+// System applications are placed in the system queue without placement rules but must pass the submit ACL
+// of the system queue. The tag set by the RM alone does not give access.
+type SystemQueueConfig struct {
+	Enabled    bool
+	Guaranteed map[string]string `yaml:",omitempty" json:",omitempty"`
+	SubmitACL  string            `yaml:",omitempty" json:",omitempty"`
+}
+
+// Dynamic queues section
+// - maxdepth: the maximum number of levels below the root a dynamic queue can be created at, 0 means no limit
+// - maxqueues: the maximum number of queues in the partition, dynamic queues are not created beyond it, 0 means no limit
+// The limits do not apply to queues defined in the configuration.
+type DynamicQueuesConfig struct {
+	MaxDepth  int `yaml:",omitempty" json:",omitempty"`
+	MaxQueues int `yaml:",omitempty" json:",omitempty"`
+}
+
+// Unresolved user section
+// - policy: handling of applications with a user that cannot be resolved: reject (default), quarantine or anonymous
+// - queue: the fully qualified leaf queue quarantined applications are placed in
+// - user: the identity used for quarantined and anonymous applications (defaults to nobody)
+// Quarantined applications are placed in the queue without placement rules or submit ACL checks.
+type UnresolvedUserConfig struct {
+	Policy string `yaml:",omitempty" json:",omitempty"`
+	Queue  string `yaml:",omitempty" json:",omitempty"`
+	User   string `yaml:",omitempty" json:",omitempty"`
+}
+
 // The queue object for each queue:
 // - the name of the queue
 // - a resources object to specify resource limits on the queue
@@ -66,16 +257,54 @@ type PartitionPreemptionConfig struct {
 // - ACL for submit and or admin access
 // - a list of sub or child queues
 // - a list of users specifying limits on a queue
+// - a template for the queues created by the placement rules below this queue
+// - a quota on the resources used by high priority asks in the queue
+// - the resource types asks in the queue may request, an empty list allows all types
 type QueueConfig struct {
-	Name            string
-	Parent          bool              `yaml:",omitempty" json:",omitempty"`
-	Resources       Resources         `yaml:",omitempty" json:",omitempty"`
-	MaxApplications uint64            `yaml:",omitempty" json:",omitempty"`
-	Properties      map[string]string `yaml:",omitempty" json:",omitempty"`
-	AdminACL        string            `yaml:",omitempty" json:",omitempty"`
-	SubmitACL       string            `yaml:",omitempty" json:",omitempty"`
-	Queues          []QueueConfig     `yaml:",omitempty" json:",omitempty"`
-	Limits          []Limit           `yaml:",omitempty" json:",omitempty"`
+	Name                 string
+	Parent               bool              `yaml:",omitempty" json:",omitempty"`
+	Resources            Resources         `yaml:",omitempty" json:",omitempty"`
+	MaxApplications      uint64            `yaml:",omitempty" json:",omitempty"`
+	Weight               float64           `yaml:",omitempty" json:",omitempty"`
+	Properties           map[string]string `yaml:",omitempty" json:",omitempty"`
+	AdminACL             string            `yaml:",omitempty" json:",omitempty"`
+	SubmitACL            string            `yaml:",omitempty" json:",omitempty"`
+	Queues               []QueueConfig     `yaml:",omitempty" json:",omitempty"`
+	Limits               []Limit           `yaml:",omitempty" json:",omitempty"`
+	ChildTemplate        ChildTemplate     `yaml:",omitempty" json:",omitempty"`
+	PriorityQuota        PriorityQuota     `yaml:",omitempty" json:",omitempty"`
+	AllowedResourceTypes []string          `yaml:",omitempty" json:",omitempty"`
+	ContentionCap        ContentionCap     `yaml:",omitempty" json:",omitempty"`
+}
+
+// The quota for high priority asks in a queue and all queues below it.
+// Asks with a priority above the threshold can only be allocated while the resources allocated to
+// asks above the threshold fit in the maximum. An empty maximum means no quota.
+type PriorityQuota struct {
+	Threshold int32             `yaml:",omitempty" json:",omitempty"`
+	Max       map[string]string `yaml:",omitempty" json:",omitempty"`
+}
+
+// The cap on the usage of a queue while the partition is under contention.
+// The partition is under contention when its pending resources, as a ratio of the partition resources, exceed
+// the pending threshold for any resource type. The usage of the queue is then limited to the share of the
+// partition resources, even if the maximum resource of the queue is higher. A zero share means no cap.
+type ContentionCap struct {
+	Share            float64 `yaml:",omitempty" json:",omitempty"`
+	PendingThreshold float64 `yaml:",omitempty" json:",omitempty"`
+}
+
+// The template applied to the leaf queues created by the placement rules below a parent queue.
+// Queues created by a placement rule have no limits unless a template is defined.
+// A dynamically created parent queue inherits the template from its parent.
+// - a resources object to specify resource limits on the created queue
+// - a set of properties, merged with the properties inherited from the parent
+// - ACL for submit and or admin access on the created queue
+type ChildTemplate struct {
+	Resources  Resources         `yaml:",omitempty" json:",omitempty"`
+	Properties map[string]string `yaml:",omitempty" json:",omitempty"`
+	AdminACL   string            `yaml:",omitempty" json:",omitempty"`
+	SubmitACL  string            `yaml:",omitempty" json:",omitempty"`
 }
 
 // The resource limits to set on the queue. The definition allows for an unlimited number of types to be used.
@@ -94,12 +323,37 @@ type Resources struct {
 // - rule link to allow setting a rule to generate the parent
 // - value a generic value interpreted depending on the rule type (i.e queue name for the "fixed" rule
 // or the application label name for the "tag" rule)
+// - fallback rules to try in order when the rule does not place the application (providedfallback rule only)
+// - mappings of the tag value to a queue name, first match wins (tagmapping rule only)
+// - resolution of the groups of the user (group rule only)
 type PlacementRule struct {
 	Name   string
 	Create bool           `yaml:",omitempty" json:",omitempty"`
 	Filter Filter         `yaml:",omitempty" json:",omitempty"`
 	Parent *PlacementRule `yaml:",omitempty" json:",omitempty"`
 	Value  string         `yaml:",omitempty" json:",omitempty"`
+	// How to handle generated queue names that are not valid: reject (default) or replace
+	Sanitize string          `yaml:",omitempty" json:",omitempty"`
+	Fallback []PlacementRule `yaml:",omitempty" json:",omitempty"`
+	Mappings []TagMapping    `yaml:",omitempty" json:",omitempty"`
+	// How the groups of the user are resolved
+	GroupResolver GroupResolver `yaml:",omitempty" json:",omitempty"`
+}
+
+// The group resolution for the group rule.
+// - type of resolver: application (default, groups of the submitting user), plugin or static
+// - list of groups keyed on the user name, the first group is the primary group (static resolver only)
+type GroupResolver struct {
+	Type  string              `yaml:",omitempty" json:",omitempty"`
+	Users map[string][]string `yaml:",omitempty" json:",omitempty"`
+}
+
+// The mapping of a tag value to a queue name for the tagmapping rule.
+// - regular expression that must match the whole tag value
+// - queue name which can reference the groups of the expression ($1, ${name})
+type TagMapping struct {
+	Match string
+	Queue string
 }
 
 // The user and group filter for a rule.
@@ -135,8 +389,10 @@ type Limit struct {
 
 // Global Node Sorting Policy section
 // - type: different type of policies supported (binpacking, fair etc)
+// - tiebreak: order of the nodes with an equal sorting score: none (default), random or roundrobin
 type NodeSortingPolicy struct {
-	Type string
+	Type     string
+	TieBreak string `yaml:",omitempty" json:",omitempty"`
 }
 
 type LoadSchedulerConfigFunc func(policyGroup string) (*SchedulerConfig, error)
@@ -149,9 +405,17 @@ func LoadSchedulerConfigFromByteArray(content []byte) (*SchedulerConfig, error)
 	}
 	// Create a sha256 checksum for this validated config
 	SetChecksum(content, conf)
+	conf.content = []byte(GetConfigurationString(content))
 	return conf, err
 }
 
+// Get the configuration content as stored, without the checksum. The validation of the configuration adds
+// defaults and changes names: changes to the stored configuration must be based on this content.
+// Returns nil if the config was not loaded from content.
+func (conf *SchedulerConfig) GetContent() []byte {
+	return conf.content
+}
+
 func SetChecksum(content []byte, conf *SchedulerConfig) {
 	noChecksumContent := GetConfigurationString(content)
 	conf.Checksum = fmt.Sprintf("%X", sha256.Sum256([]byte(noChecksumContent)))
@@ -161,6 +425,10 @@ func ParseAndValidateConfig(content []byte) (*SchedulerConfig, error) {
 	conf := &SchedulerConfig{}
 	err := yaml.UnmarshalStrict(content, conf)
 	if err != nil {
+		// add the full path of the mistyped keys to the error
+		if paths := unknownFieldPaths(content); len(paths) != 0 {
+			err = fmt.Errorf("unknown fields %s in configuration: %v", strings.Join(paths, ", "), err)
+		}
 		log.Logger().Error("failed to parse queue configuration",
 			zap.Error(err))
 		return nil, err
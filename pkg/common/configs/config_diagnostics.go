@@ -0,0 +1,213 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package configs
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+)
+
+// The levels of the config diagnostics
+const (
+	DiagnosticError   = "error"
+	DiagnosticWarning = "warning"
+)
+
+// The line number in the yaml parser errors
+var yamlLineRegExp = regexp.MustCompile(`line (\d+): `)
+
+// A single issue found in a configuration.
+// The line is the best effort location of the issue in the configuration, zero if it could not be found.
+// The path is the partition and queue the issue was found in, empty for issues not linked to a queue.
+type ConfigDiagnostic struct {
+	Level   string
+	Line    int
+	Path    string
+	Message string
+}
+
+// Diagnose the configuration and return all issues found instead of stopping at the first one.
+// The checks that can be performed independently are run on each queue: unknown fields, the queue setting checks
+// of the validation, resource definitions and maximum resources that exceed the parent maximum.
+// The full validation is always run and reported if it fails for an issue not already found.
+// User and group limits that exceed the queue maximum are reported as warnings as they have no effect.
+func DiagnoseConfig(content []byte) []*ConfigDiagnostic {
+	cd := &configDiagnoser{lines: strings.Split(string(content), "\n")}
+	conf := &SchedulerConfig{}
+	if err := yaml.UnmarshalStrict(content, conf); err != nil {
+		typeErr, ok := err.(*yaml.TypeError)
+		if !ok {
+			// syntax errors stop the parsing, nothing else can be checked
+			cd.addYAMLError(err.Error())
+			return cd.diagnostics
+		}
+		for _, msg := range typeErr.Errors {
+			cd.addYAMLError(msg)
+		}
+		// unknown fields are ignored in the normal parse, all other parts are checked
+		conf = &SchedulerConfig{}
+		if err = yaml.Unmarshal(content, conf); err != nil {
+			return cd.diagnostics
+		}
+	}
+	for _, partition := range conf.Partitions {
+		name := partition.Name
+		if name == "" {
+			name = DefaultPartition
+		}
+		if err := checkLimits(partition.Limits, name); err != nil {
+			cd.add(DiagnosticError, cd.findLine([]string{name}, "limits"), name, err.Error())
+		}
+		queues := partition.Queues
+		if len(queues) != 1 || !strings.EqualFold(queues[0].Name, RootQueue) {
+			queues = []QueueConfig{{Name: RootQueue, Parent: true, Queues: queues}}
+		}
+		cd.checkQueue(queues[0], nil, []string{name}, 1)
+	}
+	// the full validation covers the checks that are not run above
+	if err := Validate(conf); err != nil && !cd.hasMessage(err.Error()) {
+		cd.add(DiagnosticError, 0, "", err.Error())
+	}
+	return cd.diagnostics
+}
+
+type configDiagnoser struct {
+	lines       []string
+	diagnostics []*ConfigDiagnostic
+}
+
+func (cd *configDiagnoser) add(level string, line int, path, message string) {
+	cd.diagnostics = append(cd.diagnostics, &ConfigDiagnostic{
+		Level:   level,
+		Line:    line,
+		Path:    path,
+		Message: message,
+	})
+}
+
+func (cd *configDiagnoser) addYAMLError(message string) {
+	line := 0
+	if match := yamlLineRegExp.FindStringSubmatch(message); match != nil {
+		if value, err := strconv.Atoi(match[1]); err == nil {
+			line = value
+		}
+	}
+	cd.add(DiagnosticError, line, "", message)
+}
+
+func (cd *configDiagnoser) hasMessage(message string) bool {
+	for _, diagnostic := range cd.diagnostics {
+		if diagnostic.Message == message {
+			return true
+		}
+	}
+	return false
+}
+
+// Check a queue and all its children, the parent maximum is nil for the root queue.
+// The checks of the validation are used: every failed check is reported, not only the first one.
+func (cd *configDiagnoser) checkQueue(queue QueueConfig, parentM *resources.Resource, path []string, level int) {
+	path = append(path[:len(path):len(path)], queue.Name)
+	for _, setting := range queueSettingChecks {
+		if err := setting.check(&queue, level); err != nil {
+			cd.add(DiagnosticError, cd.findLine(path, setting.key), cd.queuePath(path), err.Error())
+		}
+	}
+	curM := parentM
+	if _, queueM, err := checkResourceConfig(queue, parentM); err != nil {
+		cd.add(DiagnosticError, cd.findLine(path, "resources"), cd.queuePath(path), err.Error())
+	} else {
+		if err = checkMaxFitsParent(queue.Name, queueM, parentM); err != nil {
+			cd.add(DiagnosticError, cd.findLine(path, "max"), cd.queuePath(path), err.Error())
+		}
+		curM = resources.ComponentWiseMinPermissive(queueM, parentM)
+	}
+	cd.checkLimitsMax(queue.Limits, curM, path)
+	for _, child := range queue.Queues {
+		cd.checkQueue(child, curM, path, level+1)
+	}
+}
+
+// Report the limits that exceed the maximum resource as a warning, invalid limits are reported by the checks.
+func (cd *configDiagnoser) checkLimitsMax(limits []Limit, maxResource *resources.Resource, path []string) {
+	for _, limit := range limits {
+		if checkLimit(limit) != nil {
+			continue
+		}
+		limitResource, err := resources.NewResourceFromConf(limit.MaxResources)
+		if err == nil && !maxResource.FitInMaxUndef(limitResource) {
+			cd.add(DiagnosticWarning, cd.findLine(path, "limits"), cd.queuePath(path),
+				fmt.Sprintf("limit %s for '%s' exceeds the max resource %s and has no effect", limitResource.String(), limit.Limit, maxResource.String()))
+		}
+	}
+}
+
+// The path of the queue including the partition: partition.root.parent.leaf
+func (cd *configDiagnoser) queuePath(path []string) string {
+	return strings.Join(path, DOT)
+}
+
+// Find the line for the key of the queue on a best effort basis: the names in the path are located one after the
+// other followed by the first line starting with the key. If the key cannot be found the line of the last name
+// found is returned, zero if nothing was found. The lines returned start at 1 like in the yaml parser errors.
+func (cd *configDiagnoser) findLine(path []string, key string) int {
+	start := 0
+	found := 0
+	for _, name := range path {
+		if idx := cd.findFrom(start, func(line string) bool {
+			value, ok := yamlValue(line, "name")
+			return ok && strings.EqualFold(value, name)
+		}); idx >= 0 {
+			start = idx + 1
+			found = start
+		}
+	}
+	if idx := cd.findFrom(start, func(line string) bool {
+		_, ok := yamlValue(line, key)
+		return ok
+	}); idx >= 0 {
+		return idx + 1
+	}
+	return found
+}
+
+func (cd *configDiagnoser) findFrom(start int, match func(line string) bool) int {
+	for i := start; i < len(cd.lines); i++ {
+		if match(cd.lines[i]) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Return the value if the yaml line sets the key, list markers and quotes are removed.
+func yamlValue(line, key string) (string, bool) {
+	line = strings.TrimPrefix(strings.TrimSpace(line), "- ")
+	prefix := key + ":"
+	if !strings.HasPrefix(strings.ToLower(line), prefix) {
+		return "", false
+	}
+	return strings.Trim(strings.TrimSpace(line[len(prefix):]), `"'`), true
+}
@@ -0,0 +1,145 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package configs
+
+import (
+	"strings"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestDiagnoseConfigValid(t *testing.T) {
+	data := `
+partitions:
+  - name: default
+    queues:
+      - name: root
+        queues:
+          - name: leaf
+`
+	assert.Equal(t, len(DiagnoseConfig([]byte(data))), 0, "valid config should not have diagnostics")
+}
+
+func TestDiagnoseConfigSyntax(t *testing.T) {
+	data := `
+partitions:
+  - name: default
+    queues: [
+`
+	diagnostics := DiagnoseConfig([]byte(data))
+	assert.Equal(t, len(diagnostics), 1, "syntax error should stop the diagnostics")
+	assert.Equal(t, diagnostics[0].Level, DiagnosticError)
+	assert.Assert(t, diagnostics[0].Line > 0, "syntax error should have a line")
+}
+
+func TestDiagnoseConfig(t *testing.T) {
+	data := `
+partitions:
+  - name: default
+    queues:
+      - name: root
+        unknown: value
+        queues:
+          - name: parent
+            resources:
+              max:
+                memory: 100
+            queues:
+              - name: child
+                resources:
+                  max:
+                    memory: 200
+                limits:
+                  - limit: child limit
+                    users:
+                      - user1
+                    maxresources:
+                      memory: 50
+          - name: acl
+            adminacl: "user1 group1 other"
+          - name: broken
+            resources:
+              guaranteed:
+                memory: nan
+          - name: limited
+            resources:
+              max:
+                memory: 10
+            limits:
+              - limit: large limit
+                users:
+                  - user2
+                maxresources:
+                  memory: 20
+`
+	diagnostics := DiagnoseConfig([]byte(data))
+	errs := make(map[string]*ConfigDiagnostic)
+	warnings := make(map[string]*ConfigDiagnostic)
+	for _, diagnostic := range diagnostics {
+		if diagnostic.Level == DiagnosticError {
+			errs[diagnostic.Path] = diagnostic
+		} else {
+			warnings[diagnostic.Path] = diagnostic
+		}
+	}
+	assert.Equal(t, len(errs), 4, "unexpected number of errors: %v", errs)
+	assert.Equal(t, len(warnings), 1, "unexpected number of warnings: %v", warnings)
+	// the unknown field has the line from the parser and no path
+	assert.Equal(t, errs[""].Line, 6, "unexpected line for the unknown field")
+	assert.Assert(t, strings.Contains(errs[""].Message, "field unknown not found"), "unexpected message: %s", errs[""].Message)
+	assert.Equal(t, errs["default.root.parent.child"].Line, 15, "unexpected line for the max resource")
+	assert.Assert(t, strings.Contains(errs["default.root.parent.child"].Message, "max resource of parent"), "unexpected message: %s", errs["default.root.parent.child"].Message)
+	assert.Equal(t, errs["default.root.acl"].Line, 24, "unexpected line for the ACL")
+	assert.Assert(t, strings.Contains(errs["default.root.acl"].Message, "multiple spaces found in ACL"), "unexpected message: %s", errs["default.root.acl"].Message)
+	assert.Equal(t, errs["default.root.broken"].Line, 26, "unexpected line for the resources")
+	assert.Equal(t, warnings["default.root.limited"].Line, 33, "unexpected line for the limit")
+	assert.Assert(t, strings.Contains(warnings["default.root.limited"].Message, "has no effect"), "unexpected message: %s", warnings["default.root.limited"].Message)
+}
+
+func TestDiagnoseConfigSettings(t *testing.T) {
+	data := `
+partitions:
+  - name: default
+    queues:
+      - name: root
+        queues:
+          - name: weighted
+            weight: -1
+          - name: backoff
+            properties:
+              placement.failure.threshold: NaN
+`
+	diagnostics := DiagnoseConfig([]byte(data))
+	assert.Equal(t, len(diagnostics), 2, "unexpected number of diagnostics: %v", diagnostics)
+	assert.Equal(t, diagnostics[0].Path, "default.root.weighted")
+	assert.Equal(t, diagnostics[0].Line, 8, "unexpected line for the weight")
+	assert.Assert(t, strings.Contains(diagnostics[0].Message, "invalid weight"), "unexpected message: %s", diagnostics[0].Message)
+	assert.Equal(t, diagnostics[1].Path, "default.root.backoff")
+	assert.Equal(t, diagnostics[1].Line, 10, "unexpected line for the properties")
+	assert.Assert(t, strings.Contains(diagnostics[1].Message, "invalid placement failure threshold"), "unexpected message: %s", diagnostics[1].Message)
+}
+
+func TestYAMLValue(t *testing.T) {
+	value, ok := yamlValue(`  - name: "root"`, "name")
+	assert.Assert(t, ok, "name should have been found")
+	assert.Equal(t, value, "root")
+	_, ok = yamlValue("  names: root", "name")
+	assert.Assert(t, !ok, "other key should not match")
+}
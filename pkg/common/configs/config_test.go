@@ -1210,6 +1210,59 @@ partitions:
 	}
 }
 
+func TestPlacementRuleSets(t *testing.T) {
+	data := `
+placementrulesets:
+  - name: tenants
+    rules:
+      - name: tag
+        value: namespace
+        create: true
+      - name: fixed
+        value: root.default
+partitions:
+  - name: default
+    placementruleset: tenants
+    queues:
+      - name: root
+  - name: gpu
+    placementruleset: tenants
+    placementrules:
+      - name: fixed
+        value: root.gpu
+    queues:
+      - name: root
+`
+	conf, err := LoadSchedulerConfigFromByteArray([]byte(data))
+	assert.NilError(t, err, "config with placement rule sets should be valid")
+	assert.Equal(t, len(conf.Partitions[0].PlacementRules), 2, "rules of the set should be used")
+	assert.Equal(t, conf.Partitions[0].PlacementRules[1].Value, "root.default")
+	assert.Equal(t, len(conf.Partitions[1].PlacementRules), 2, "override should replace the rule of the set")
+	assert.Equal(t, conf.Partitions[1].PlacementRules[0].Name, "tag")
+	assert.Equal(t, conf.Partitions[1].PlacementRules[1].Value, "root.gpu")
+
+	_, err = LoadSchedulerConfigFromByteArray([]byte(strings.Replace(data, "placementruleset: tenants", "placementruleset: other", 1)))
+	assert.ErrorContains(t, err, "unknown placement rule set other")
+}
+
+func TestFeatureGatesConfig(t *testing.T) {
+	data := `
+featuregates:
+  Preemption: false
+  AdaptiveCycles: true
+partitions:
+  - name: default
+    queues:
+      - name: root
+`
+	conf, err := LoadSchedulerConfigFromByteArray([]byte(data))
+	assert.NilError(t, err, "config with feature gates should be valid")
+	assert.DeepEqual(t, conf.FeatureGates, map[string]bool{"Preemption": false, "AdaptiveCycles": true})
+
+	_, err = LoadSchedulerConfigFromByteArray([]byte(strings.Replace(data, "Preemption", "Unknown", 1)))
+	assert.ErrorContains(t, err, "unknown feature gate")
+}
+
 func TestGetConfigurationString(t *testing.T) {
 	configBytes := []byte(validConf)
 	checksum := "checksum: " + fmt.Sprintf("%X", sha256.Sum256(configBytes))
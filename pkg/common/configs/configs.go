@@ -18,11 +18,16 @@
 
 package configs
 
-import "sync"
+import (
+	"sync"
+	"time"
+)
 
 const (
 	SchedulerConfigPath        = "scheduler-config-path"
 	DefaultSchedulerConfigPath = "/etc/yunikorn"
+	// number of loaded configurations kept in the history of a policy group
+	MaxConfigHistory = 10
 )
 
 var ConfigMap map[string]string
@@ -32,6 +37,7 @@ func init() {
 	ConfigMap = make(map[string]string)
 	ConfigContext = &SchedulerConfigContext{
 		configs: make(map[string]*SchedulerConfig),
+		history: make(map[string][]*ConfigVersion),
 		lock:    &sync.RWMutex{},
 	}
 }
@@ -39,13 +45,69 @@ func init() {
 // scheduler config context provides thread-safe access for scheduler configurations
 type SchedulerConfigContext struct {
 	configs map[string]*SchedulerConfig
+	history map[string][]*ConfigVersion
 	lock    *sync.RWMutex
 }
 
+// a scheduler configuration that was loaded for a policy group
+type ConfigVersion struct {
+	Version  uint64
+	Checksum string
+	LoadTime time.Time
+	Config   *SchedulerConfig
+	Content  []byte // the configuration as stored
+}
+
+// Set the current config for the policy group and add it to the history.
+// A config with the same checksum as the last loaded config is not added to the history again.
 func (ctx *SchedulerConfigContext) Set(policyGroup string, config *SchedulerConfig) {
 	ctx.lock.Lock()
 	defer ctx.lock.Unlock()
 	ctx.configs[policyGroup] = config
+	if config == nil {
+		return
+	}
+	history := ctx.history[policyGroup]
+	var version uint64 = 1
+	if len(history) > 0 {
+		last := history[len(history)-1]
+		if last.Checksum == config.Checksum {
+			return
+		}
+		version = last.Version + 1
+	}
+	history = append(history, &ConfigVersion{
+		Version:  version,
+		Checksum: config.Checksum,
+		LoadTime: time.Now(),
+		Config:   config,
+		Content:  config.GetContent(),
+	})
+	if len(history) > MaxConfigHistory {
+		history = history[len(history)-MaxConfigHistory:]
+	}
+	ctx.history[policyGroup] = history
+}
+
+// Get the config history for the policy group, oldest version first.
+func (ctx *SchedulerConfigContext) GetHistory(policyGroup string) []*ConfigVersion {
+	ctx.lock.RLock()
+	defer ctx.lock.RUnlock()
+	history := make([]*ConfigVersion, len(ctx.history[policyGroup]))
+	copy(history, ctx.history[policyGroup])
+	return history
+}
+
+// Get a version from the config history for the policy group, nil if the version is not in the history.
+func (ctx *SchedulerConfigContext) GetVersion(policyGroup string, version uint64) *ConfigVersion {
+	ctx.lock.RLock()
+	defer ctx.lock.RUnlock()
+	for _, entry := range ctx.history[policyGroup] {
+		if entry.Version == version {
+			return entry
+		}
+	}
+	return nil
 }
 
 func (ctx *SchedulerConfigContext) Get(policyGroup string) *SchedulerConfig {
@@ -0,0 +1,56 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package configs
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestConfigHistory(t *testing.T) {
+	ctx := &SchedulerConfigContext{
+		configs: make(map[string]*SchedulerConfig),
+		history: make(map[string][]*ConfigVersion),
+		lock:    &sync.RWMutex{},
+	}
+	assert.Equal(t, len(ctx.GetHistory("group")), 0, "history should be empty")
+	ctx.Set("group", &SchedulerConfig{Checksum: "1", content: []byte("content")})
+	// same checksum is not added again
+	ctx.Set("group", &SchedulerConfig{Checksum: "1"})
+	history := ctx.GetHistory("group")
+	assert.Equal(t, len(history), 1, "same config should only be added once")
+	assert.Equal(t, history[0].Version, uint64(1), "unexpected first version")
+	assert.Equal(t, string(history[0].Content), "content", "stored content should be kept in the history")
+	assert.Assert(t, ctx.GetVersion("group", 1) != nil, "version 1 should be in the history")
+	assert.Assert(t, ctx.GetVersion("other", 1) == nil, "version 1 should not be in the history of another group")
+
+	// history is capped, oldest versions are removed
+	for i := 2; i <= MaxConfigHistory+2; i++ {
+		ctx.Set("group", &SchedulerConfig{Checksum: strconv.Itoa(i)})
+	}
+	history = ctx.GetHistory("group")
+	assert.Equal(t, len(history), MaxConfigHistory, "history should be capped")
+	assert.Equal(t, history[0].Version, uint64(3), "oldest versions should have been removed")
+	assert.Equal(t, history[MaxConfigHistory-1].Version, uint64(MaxConfigHistory+2), "unexpected last version")
+	assert.Assert(t, ctx.GetVersion("group", 1) == nil, "version 1 should have been removed")
+	assert.Equal(t, ctx.Get("group").Checksum, strconv.Itoa(MaxConfigHistory+2), "unexpected current config")
+}
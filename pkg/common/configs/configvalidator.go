@@ -19,25 +19,84 @@
 package configs
 
 import (
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
 	"fmt"
+	"math"
+	"net"
+	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"go.uber.org/zap"
 
+	"github.com/apache/incubator-yunikorn-core/pkg/common/features"
 	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
 	"github.com/apache/incubator-yunikorn-core/pkg/common/security"
 	"github.com/apache/incubator-yunikorn-core/pkg/log"
 	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/policies"
+	"github.com/apache/incubator-yunikorn-core/pkg/trace"
 )
 
 const (
 	RootQueue        = "root"
+	SystemQueue      = "system"
 	DOT              = "."
 	DotReplace       = "_dot_"
 	DefaultPartition = "default"
+	// Placement rule sanitize options for generated queue names: reject the name or replace invalid characters
+	SanitizeReject  = "reject"
+	SanitizeReplace = "replace"
+	// Group resolvers for the group placement rule: groups of the application, from a plugin or from the config
+	GroupResolverApplication = "application"
+	GroupResolverPlugin      = "plugin"
+	GroupResolverStatic      = "static"
+	// Ordering of nodes with an equal sorting score: a random or rotating start within each group of equal nodes
+	NodeTieBreakRandom     = "random"
+	NodeTieBreakRoundRobin = "roundrobin"
+	// Maximum length of a queue name, must be in line with the QueueNameRegExp
+	MaxQueueNameLength = 64
 	// How to sort applications in leaf queues, valid options are defined in the scheduler.policies
 	ApplicationSortPolicy = "application.sort.policy"
+	// How to sort child queues in parent queues, valid options are fair or drf
+	QueueSortPolicy = "queue.sort.policy"
+	// Applications carrying the tag are sorted before all other applications in a leaf queue, format: key or key=value
+	ApplicationBoostTag = "application.sort.boost.tag"
+	// Applications carrying the tag are sorted after all other applications in a leaf queue, format: key or key=value
+	ApplicationDemoteTag = "application.sort.demote.tag"
+	// Node taints tolerated by all asks in a leaf queue, comma separated list, format: key or key=value
+	QueueTolerations = "scheduling.tolerations"
+	// Maximum number of completed applications retained for a leaf queue, unlimited if not set
+	ApplicationRetentionCount = "application.retention.count"
+	// Maximum age of completed applications retained for a leaf queue as a duration (i.e. 24h), unlimited if not set
+	ApplicationRetentionAge = "application.retention.age"
+	// Export removed completed applications of a leaf queue to the decision export sink: true or false (default)
+	ApplicationRetentionExport = "application.retention.export"
+	// Handling of applications with a user that cannot be resolved: reject, park in a queue or use an anonymous identity
+	UnresolvedUserReject     = "reject"
+	UnresolvedUserQuarantine = "quarantine"
+	UnresolvedUserAnonymous  = "anonymous"
+	DefaultAnonymousUser     = "nobody"
+	// Time the head application of a strictfifo leaf queue can go without an allocation before the applications
+	// behind it are considered as a duration (i.e. 5m), the head application is never skipped if not set
+	ApplicationHeadOfLineTimeout = "application.sort.headofline.timeout"
+	// Maximum time the applications in a leaf queue can run after their first allocation as a duration (i.e. 24h),
+	// applications running longer are failed and their allocations released, unlimited if not set
+	ApplicationMaxRuntime = "application.runtime.max"
+	// Failure ratio of the placement attempts of a leaf queue that triggers a back off, between 0 and 1, disabled if not set
+	PlacementFailureThreshold = "placement.failure.threshold"
+	// Time a leaf queue is sorted after its siblings when the failure threshold is exceeded as a duration (i.e. 30s)
+	PlacementFailureBackoff = "placement.failure.backoff"
+	// Reclaim resources of the idle applications in a leaf queue: true (default) or false
+	ApplicationIdleReclaim = "application.idle.reclaim"
+	// Only demand from inside the subtree of the queue can preempt allocations in the subtree: true or false (default)
+	PreemptionFence = "preemption.fence"
+	// Skip the queue, and the queues below it, when scheduling: true or false (default)
+	// Applications and asks are still accepted by a paused queue.
+	SchedulingPaused = "scheduling.paused"
 )
 
 // A queue can be a username with the dot replaced. Most systems allow a 32 character user name.
@@ -75,14 +134,22 @@ func checkACL(acl string) error {
 }
 
 func checkQueueResource(cur QueueConfig, parentM *resources.Resource) (*resources.Resource, error) {
-	curG, curM, err := checkResourceConfig(cur)
+	curG, curM, err := checkResourceConfig(cur, parentM)
 	if err != nil {
 		return nil, err
 	}
-	if !parentM.FitInMaxUndef(curM) {
-		return nil, fmt.Errorf("max resource of parent %s is smaller than maximum resource %s for queue %s", parentM.String(), curM.String(), cur.Name)
+	if err = checkMaxFitsParent(cur.Name, curM, parentM); err != nil {
+		return nil, err
 	}
 	curM = resources.ComponentWiseMinPermissive(curM, parentM)
+	err = checkChildTemplateResource(cur, curM)
+	if err != nil {
+		return nil, err
+	}
+	err = checkPriorityQuota(cur, curM)
+	if err != nil {
+		return nil, err
+	}
 	sumG := resources.NewResource()
 	for _, child := range cur.Queues {
 		var childG *resources.Resource
@@ -104,14 +171,24 @@ func checkQueueResource(cur QueueConfig, parentM *resources.Resource) (*resource
 	return curG, nil
 }
 
-func checkResourceConfig(cur QueueConfig) (*resources.Resource, *resources.Resource, error) {
+// Check that the maximum resource of the queue fits in the maximum resource of the parent.
+func checkMaxFitsParent(name string, curM, parentM *resources.Resource) error {
+	if !parentM.FitInMaxUndef(curM) {
+		return fmt.Errorf("max resource of parent %s is smaller than maximum resource %s for queue %s", parentM.String(), curM.String(), name)
+	}
+	return nil
+}
+
+// Check the resources of the queue: the quantities can be expressions relative to the maximum resource of the parent.
+// Expressions that cannot be resolved because the parent does not set the resource type are not checked.
+func checkResourceConfig(cur QueueConfig, parentM *resources.Resource) (*resources.Resource, *resources.Resource, error) {
 	var g, m *resources.Resource
 	var err error
-	g, err = resources.NewResourceFromConf(cur.Resources.Guaranteed)
+	g, err = resources.NewResourceFromConfWithParent(cur.Resources.Guaranteed, parentM)
 	if err != nil {
 		return nil, nil, err
 	}
-	m, err = resources.NewResourceFromConf(cur.Resources.Max)
+	m, err = resources.NewResourceFromConfWithParent(cur.Resources.Max, parentM)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -121,6 +198,104 @@ func checkResourceConfig(cur QueueConfig) (*resources.Resource, *resources.Resou
 	return g, m, nil
 }
 
+// Check the priority quota of the queue: the maximum must be a valid resource that fits in the maximum resource
+// of the queue.
+func checkPriorityQuota(cur QueueConfig, curM *resources.Resource) error {
+	quotaM, err := resources.NewResourceFromConf(cur.PriorityQuota.Max)
+	if err != nil {
+		return err
+	}
+	if !curM.FitInMaxUndef(quotaM) {
+		return fmt.Errorf("max resource %s is smaller than priority quota %s for queue %s", curM.String(), quotaM.String(), cur.Name)
+	}
+	return nil
+}
+
+// Check the resources defined in the child template of the queue: the template resources must be valid and the
+// maximum resource must fit in the maximum resource of the queue the template is defined on.
+// Expressions are not supported in the template: the quantities must be fixed.
+func checkChildTemplateResource(cur QueueConfig, curM *resources.Resource) error {
+	templateName := cur.Name + " child template"
+	for _, conf := range []map[string]string{cur.ChildTemplate.Resources.Max, cur.ChildTemplate.Resources.Guaranteed} {
+		if _, err := resources.NewResourceFromConf(conf); err != nil {
+			return fmt.Errorf("invalid resource for queue %s: %v", templateName, err)
+		}
+	}
+	_, templateM, err := checkResourceConfig(QueueConfig{Name: templateName, Resources: cur.ChildTemplate.Resources}, nil)
+	if err != nil {
+		return err
+	}
+	if !curM.FitInMaxUndef(templateM) {
+		return fmt.Errorf("max resource %s is smaller than maximum resource %s for queue %s", curM.String(), templateM.String(), templateName)
+	}
+	return nil
+}
+
+// Check the shared placement rule sets: the names must be unique and all rules must be valid.
+func checkPlacementRuleSets(ruleSets []PlacementRuleSet) error {
+	names := make(map[string]bool)
+	for _, ruleSet := range ruleSets {
+		if ruleSet.Name == "" {
+			return fmt.Errorf("placement rule set name must be set")
+		}
+		if names[ruleSet.Name] {
+			return fmt.Errorf("duplicate placement rule set name %s", ruleSet.Name)
+		}
+		names[ruleSet.Name] = true
+		if len(ruleSet.Rules) == 0 {
+			return fmt.Errorf("placement rule set %s has no rules", ruleSet.Name)
+		}
+		for _, rule := range ruleSet.Rules {
+			if err := checkPlacementRule(rule); err != nil {
+				return fmt.Errorf("placement rule set %s: %v", ruleSet.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Replace the placement rule set reference of the partition with the rules of the set.
+// A rule of the partition replaces the rule with the same name in the set, the other rules of the partition are
+// added after the rules of the set. The reference is cleared after the rules are resolved.
+func resolvePlacementRuleSet(partition *PartitionConfig, ruleSets []PlacementRuleSet) error {
+	if partition.PlacementRuleSet == "" {
+		return nil
+	}
+	var ruleSet *PlacementRuleSet
+	for i := range ruleSets {
+		if ruleSets[i].Name == partition.PlacementRuleSet {
+			ruleSet = &ruleSets[i]
+			break
+		}
+	}
+	if ruleSet == nil {
+		return fmt.Errorf("partition %s references unknown placement rule set %s", partition.Name, partition.PlacementRuleSet)
+	}
+	rules := make([]PlacementRule, len(ruleSet.Rules))
+	copy(rules, ruleSet.Rules)
+	for _, override := range partition.PlacementRules {
+		index := -1
+		for i, rule := range ruleSet.Rules {
+			if rule.Name != override.Name {
+				continue
+			}
+			if index != -1 {
+				return fmt.Errorf("partition %s cannot override placement rule %s: the rule occurs more than once in set %s",
+					partition.Name, override.Name, ruleSet.Name)
+			}
+			index = i
+		}
+		if index == -1 {
+			rules = append(rules, override)
+		} else {
+			rules[index] = override
+		}
+	}
+	partition.PlacementRules = rules
+	partition.PlacementRuleSet = ""
+	return nil
+}
+
 // Check the placement rules for correctness
 func checkPlacementRules(partition *PartitionConfig) error {
 	// return if nothing defined
@@ -146,6 +321,10 @@ func checkPlacementRule(rule PlacementRule) error {
 	if !RuleNameRegExp.MatchString(rule.Name) {
 		return fmt.Errorf("invalid rule name %s, a name must be a valid identifier", rule.Name)
 	}
+	// sanitize option must be known if set
+	if rule.Sanitize != "" && !strings.EqualFold(rule.Sanitize, SanitizeReject) && !strings.EqualFold(rule.Sanitize, SanitizeReplace) {
+		return fmt.Errorf("invalid rule sanitize option %s, option must be either '', %s or %s", rule.Sanitize, SanitizeReject, SanitizeReplace)
+	}
 	// check the parent rule
 	if rule.Parent != nil {
 		if err := checkPlacementRule(*rule.Parent); err != nil {
@@ -155,6 +334,25 @@ func checkPlacementRule(rule PlacementRule) error {
 			return err
 		}
 	}
+	// check the fallback rules
+	for _, fallback := range rule.Fallback {
+		if err := checkPlacementRule(fallback); err != nil {
+			log.Logger().Debug("fallback placement rule failed",
+				zap.String("rule", rule.Name),
+				zap.String("fallbackRule", fallback.Name))
+			return err
+		}
+	}
+	// check the group resolver
+	if err := checkGroupResolver(rule.GroupResolver); err != nil {
+		return err
+	}
+	// check the tag mappings
+	for _, mapping := range rule.Mappings {
+		if err := checkTagMapping(mapping); err != nil {
+			return err
+		}
+	}
 	// check filter if given
 	if err := checkPlacementFilter(rule.Filter); err != nil {
 		log.Logger().Debug("placement rule filter failed",
@@ -165,6 +363,37 @@ func checkPlacementRule(rule PlacementRule) error {
 	return nil
 }
 
+// Check the group resolver: the type must be known and the static memberships must use valid names
+func checkGroupResolver(resolver GroupResolver) error {
+	if resolver.Type != "" && !strings.EqualFold(resolver.Type, GroupResolverApplication) &&
+		!strings.EqualFold(resolver.Type, GroupResolverPlugin) && !strings.EqualFold(resolver.Type, GroupResolverStatic) {
+		return fmt.Errorf("invalid group resolver type %s, type must be either '', %s, %s or %s",
+			resolver.Type, GroupResolverApplication, GroupResolverPlugin, GroupResolverStatic)
+	}
+	for userName, groups := range resolver.Users {
+		if !UserRegExp.MatchString(userName) {
+			return fmt.Errorf("invalid user name '%s' in group resolver", userName)
+		}
+		for _, group := range groups {
+			if !GroupRegExp.MatchString(group) {
+				return fmt.Errorf("invalid group name '%s' for user '%s' in group resolver", group, userName)
+			}
+		}
+	}
+	return nil
+}
+
+// Check the tag mapping: the expression must compile and the queue must be set
+func checkTagMapping(mapping TagMapping) error {
+	if _, err := regexp.Compile(mapping.Match); err != nil {
+		return fmt.Errorf("invalid tag mapping expression %s: %v", mapping.Match, err)
+	}
+	if mapping.Queue == "" {
+		return fmt.Errorf("tag mapping for expression %s has no queue set", mapping.Match)
+	}
+	return nil
+}
+
 // Check the filter for syntax issues
 // Trickery for the regexp part to make sure we filter out just a name and do not see it as a regexp.
 // If the list is 1 item check if it is a valid user, then compile as a regexp and check for regexp characters.
@@ -258,31 +487,227 @@ func checkNodeSortingPolicy(partition *PartitionConfig) error {
 	policy := partition.NodeSortPolicy
 
 	// Defined polices.
-	_, err := policies.FromString(policy.Type)
+	if _, err := policies.FromString(policy.Type); err != nil {
+		return err
+	}
+	// Tie break must be known if set
+	if policy.TieBreak != "" && !strings.EqualFold(policy.TieBreak, NodeTieBreakRandom) && !strings.EqualFold(policy.TieBreak, NodeTieBreakRoundRobin) {
+		return fmt.Errorf("invalid node sorting tie break %s, option must be either '', %s or %s", policy.TieBreak, NodeTieBreakRandom, NodeTieBreakRoundRobin)
+	}
+	return nil
+}
 
-	return err
+// Check the parallel allocation settings: the number of workers cannot be negative
+func checkParallelAllocation(partition *PartitionConfig) error {
+	if partition.ParallelAllocation.Workers < 0 {
+		return fmt.Errorf("parallel allocation workers cannot be negative for partition %s: %d", partition.Name, partition.ParallelAllocation.Workers)
+	}
+	return nil
 }
 
-// Check the queue names configured for compliance and uniqueness
-// - no duplicate names at each branched level in the tree
-// - queue name is alphanumeric (case ignore) with - and _
-// - queue name is maximum 16 char long
-func checkQueues(queue *QueueConfig, level int) error {
-	// check the ACLs (if defined)
-	err := checkACL(queue.AdminACL)
-	if err != nil {
-		return err
+// Check the queue cleanup settings: the idle timeout cannot be negative
+func checkQueueCleanup(partition *PartitionConfig) error {
+	if partition.QueueCleanup.IdleTimeout < 0 {
+		return fmt.Errorf("queue cleanup idle timeout cannot be negative for partition %s: %s", partition.Name, partition.QueueCleanup.IdleTimeout)
 	}
-	err = checkACL(queue.SubmitACL)
-	if err != nil {
+	return nil
+}
+
+// Check the recovery settings: the window and the boost cannot be negative
+func checkRecovery(partition *PartitionConfig) error {
+	if partition.Recovery.Window < 0 {
+		return fmt.Errorf("recovery window cannot be negative for partition %s: %s", partition.Name, partition.Recovery.Window)
+	}
+	if partition.Recovery.Boost < 0 {
+		return fmt.Errorf("recovery boost cannot be negative for partition %s: %d", partition.Name, partition.Recovery.Boost)
+	}
+	return nil
+}
+
+// Check the idle application settings: the timeout cannot be negative and the reclaim fraction must be between
+// 0 and 1. Reclaiming resources requires the detection to be enabled.
+func checkIdleApplications(partition *PartitionConfig) error {
+	idle := partition.IdleApplications
+	if idle.Timeout < 0 {
+		return fmt.Errorf("idle application timeout cannot be negative for partition %s: %s", partition.Name, idle.Timeout)
+	}
+	if idle.Reclaim < 0 || idle.Reclaim > 1 {
+		return fmt.Errorf("idle application reclaim must be between 0 and 1 for partition %s: %v", partition.Name, idle.Reclaim)
+	}
+	if idle.Reclaim > 0 && idle.Timeout == 0 {
+		return fmt.Errorf("idle application reclaim set without a timeout for partition %s", partition.Name)
+	}
+	return nil
+}
+
+// Check the system queue settings: the guaranteed resources and the ACL must be valid and the queue cannot be part
+// of the configured queues as it is created by the core.
+func checkSystemQueue(partition *PartitionConfig) error {
+	if !partition.SystemQueue.Enabled {
+		return nil
+	}
+	if _, err := resources.NewResourceFromConf(partition.SystemQueue.Guaranteed); err != nil {
+		return fmt.Errorf("invalid system queue guaranteed resource for partition %s: %v", partition.Name, err)
+	}
+	if err := checkACL(partition.SystemQueue.SubmitACL); err != nil {
+		return fmt.Errorf("invalid system queue submit ACL for partition %s: %v", partition.Name, err)
+	}
+	for _, queue := range partition.Queues[0].Queues {
+		if strings.EqualFold(queue.Name, SystemQueue) {
+			return fmt.Errorf("queue %s.%s cannot be configured for partition %s: it is created by the core", RootQueue, SystemQueue, partition.Name)
+		}
+	}
+	return nil
+}
+
+// Check the node attributes used to group the partition resources: names must be set and unique
+func checkNodeGroups(partition *PartitionConfig) error {
+	seen := make(map[string]bool)
+	for _, attribute := range partition.NodeGroups {
+		if attribute == "" {
+			return fmt.Errorf("empty node group attribute for partition %s", partition.Name)
+		}
+		if seen[attribute] {
+			return fmt.Errorf("duplicate node group attribute %s for partition %s", attribute, partition.Name)
+		}
+		seen[attribute] = true
+	}
+	return nil
+}
+
+// Check the unresolved user policy: the policy must be known and quarantine needs an existing leaf queue
+func checkUnresolvedUser(partition *PartitionConfig) error {
+	unresolved := partition.UnresolvedUser
+	switch strings.ToLower(unresolved.Policy) {
+	case "", UnresolvedUserReject, UnresolvedUserAnonymous:
+		return nil
+	case UnresolvedUserQuarantine:
+		queue := partition.GetQueueConfig(unresolved.Queue)
+		if queue == nil || queue.Parent || len(queue.Queues) != 0 {
+			return fmt.Errorf("quarantine queue %s for partition %s must be a configured leaf queue", unresolved.Queue, partition.Name)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown unresolved user policy %s for partition %s", unresolved.Policy, partition.Name)
+	}
+}
+
+// Check the dynamic queue limits: limits cannot be negative
+func checkDynamicQueues(partition *PartitionConfig) error {
+	limits := partition.DynamicQueues
+	if limits.MaxDepth < 0 || limits.MaxQueues < 0 {
+		return fmt.Errorf("negative dynamic queue limits for partition %s", partition.Name)
+	}
+	return nil
+}
+
+// Check the redaction settings: the ACL must be valid and the tag expressions must compile
+func checkRedaction(redaction RedactionConfig) error {
+	if err := checkACL(redaction.AdminACL); err != nil {
 		return err
 	}
+	for _, tag := range redaction.Tags {
+		if _, err := regexp.Compile(tag); err != nil {
+			return fmt.Errorf("invalid redaction tag expression %s: %v", tag, err)
+		}
+	}
+	return nil
+}
 
-	// check the limits for this child (if defined)
-	err = checkLimits(queue.Limits, queue.Name)
-	if err != nil {
+// Check the authentication settings: the ACL must be valid and the static tokens must be a valid SHA-256 hash
+// that is unique and linked to a user
+func checkAuthentication(authentication AuthenticationConfig) error {
+	if err := checkACL(authentication.AdminACL); err != nil {
 		return err
 	}
+	hashes := make(map[string]bool)
+	for _, token := range authentication.Tokens {
+		if token.User == "" {
+			return fmt.Errorf("authentication token without a user")
+		}
+		hash := strings.ToLower(token.SHA256)
+		if decoded, err := hex.DecodeString(hash); err != nil || len(decoded) != sha256.Size {
+			return fmt.Errorf("invalid SHA-256 hash for the authentication token of user %s", token.User)
+		}
+		if hashes[hash] {
+			return fmt.Errorf("duplicate authentication token for user %s", token.User)
+		}
+		hashes[hash] = true
+	}
+	return nil
+}
+
+// Check the tracing settings: the mode must be known and the filter mode needs tags to filter on.
+// The collector endpoint must be the HTTP URL of a Jaeger collector, OTLP endpoints are not supported.
+func checkTracing(tracing TracingConfig) error {
+	if tracing.CollectorEndpoint != "" {
+		parsed, err := url.Parse(tracing.CollectorEndpoint)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("invalid tracing collector endpoint %s: expected the HTTP URL of a Jaeger collector", tracing.CollectorEndpoint)
+		}
+	}
+	switch tracing.Mode {
+	case "", trace.Sampling, trace.Debug:
+	case trace.DebugWithFilter:
+		if len(tracing.FilterTags) == 0 {
+			return fmt.Errorf("tracing mode %s requires filter tags", tracing.Mode)
+		}
+	default:
+		return fmt.Errorf("unknown tracing mode %s", tracing.Mode)
+	}
+	return nil
+}
+
+// Check the web service settings: the address must be a host and port and the origins must be * or scheme://host
+func checkWebService(webService WebServiceConfig) error {
+	if webService.Address != "" {
+		if _, _, err := net.SplitHostPort(webService.Address); err != nil {
+			return fmt.Errorf("invalid web service address %s: %v", webService.Address, err)
+		}
+	}
+	for _, origin := range webService.AllowedOrigins {
+		if origin == "*" {
+			continue
+		}
+		parsed, err := url.Parse(origin)
+		if err != nil || parsed.Scheme == "" || parsed.Host == "" || (parsed.Path != "" && parsed.Path != "/") {
+			return fmt.Errorf("invalid web service allowed origin %s", origin)
+		}
+	}
+	return checkWebServiceTLS(webService.TLS)
+}
+
+// Check the web service TLS settings: the certificate and key must be set together, an inline certificate must
+// match the key. The files are only read when the certificate is applied: they can be rotated after validation.
+func checkWebServiceTLS(webTLS WebServiceTLS) error {
+	if (webTLS.CertFile == "") != (webTLS.KeyFile == "") {
+		return fmt.Errorf("web service TLS requires both the certificate file and the key file")
+	}
+	if webTLS.CertFile == "" && (webTLS.Cert == "") != (webTLS.Key == "") {
+		return fmt.Errorf("web service TLS requires both the certificate and the key")
+	}
+	if webTLS.CertFile == "" && webTLS.Cert != "" {
+		if _, err := tls.X509KeyPair([]byte(webTLS.Cert), []byte(webTLS.Key)); err != nil {
+			return fmt.Errorf("invalid web service TLS certificate: %v", err)
+		}
+	}
+	if webTLS.ReloadInterval < 0 {
+		return fmt.Errorf("web service TLS reload interval cannot be negative: %s", webTLS.ReloadInterval)
+	}
+	return nil
+}
+
+// Check the queue names configured for compliance and uniqueness
+// - no duplicate names at each branched level in the tree
+// - queue name is alphanumeric (case ignore) with - and _
+// - queue name is maximum 16 char long
+func checkQueues(queue *QueueConfig, level int) error {
+	// check the settings of this queue
+	for _, setting := range queueSettingChecks {
+		if err := setting.check(queue, level); err != nil {
+			return err
+		}
+	}
 
 	// check this level for name compliance and uniqueness
 	queueMap := make(map[string]bool)
@@ -299,14 +724,112 @@ func checkQueues(queue *QueueConfig, level int) error {
 
 	// recurse into the depth if this level passed
 	for _, child := range queue.Queues {
-		err = checkQueues(&child, level+1)
-		if err != nil {
+		if err := checkQueues(&child, level+1); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+// A check of a setting of a single queue, the key is the configuration key of the setting.
+type queueSettingCheck struct {
+	key   string
+	check func(queue *QueueConfig, level int) error
+}
+
+// The checks of the settings of a single queue, used by the validation and the diagnostics.
+// The resources and the child queues are checked separately as they depend on the parent queue.
+var queueSettingChecks = []queueSettingCheck{
+	{"adminacl", func(queue *QueueConfig, _ int) error { return checkACL(queue.AdminACL) }},
+	{"submitacl", func(queue *QueueConfig, _ int) error { return checkACL(queue.SubmitACL) }},
+	{"childtemplate", func(queue *QueueConfig, _ int) error { return checkACL(queue.ChildTemplate.AdminACL) }},
+	{"childtemplate", func(queue *QueueConfig, _ int) error { return checkACL(queue.ChildTemplate.SubmitACL) }},
+	{"weight", func(queue *QueueConfig, _ int) error { return checkWeight(queue) }},
+	{"limits", func(queue *QueueConfig, _ int) error { return checkLimits(queue.Limits, queue.Name) }},
+	{"allowedresourcetypes", func(queue *QueueConfig, _ int) error { return checkAllowedResourceTypes(queue) }},
+	{"contentioncap", checkContentionCap},
+	{"properties", func(queue *QueueConfig, _ int) error { return checkPlacementFailure(queue) }},
+}
+
+// Check the weight of the queue: zero means not set, a set weight must be a positive finite number.
+func checkWeight(queue *QueueConfig) error {
+	if queue.Weight < 0 || math.IsNaN(queue.Weight) || math.IsInf(queue.Weight, 0) {
+		return fmt.Errorf("invalid weight %v for queue %s, weight must be a positive finite number", queue.Weight, queue.Name)
+	}
+	return nil
+}
+
+// Check the resource types allowed in the queue: names cannot be empty and must be unique.
+func checkAllowedResourceTypes(queue *QueueConfig) error {
+	types := make(map[string]bool)
+	for _, name := range queue.AllowedResourceTypes {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("empty allowed resource type for queue %s", queue.Name)
+		}
+		if types[name] {
+			return fmt.Errorf("duplicate allowed resource type %s for queue %s", name, queue.Name)
+		}
+		types[name] = true
+	}
+	return nil
+}
+
+// Check the placement failure properties of the queue, both properties are optional.
+func checkPlacementFailure(queue *QueueConfig) error {
+	if value, ok := queue.Properties[PlacementFailureThreshold]; ok {
+		if _, err := ParsePlacementFailureThreshold(value); err != nil {
+			return fmt.Errorf("queue %s: %v", queue.Name, err)
+		}
+	}
+	if value, ok := queue.Properties[PlacementFailureBackoff]; ok {
+		if _, err := ParsePlacementFailureBackoff(value); err != nil {
+			return fmt.Errorf("queue %s: %v", queue.Name, err)
+		}
+	}
+	return nil
+}
+
+// Parse the placement failure threshold property: a number larger than 0 and at most 1.
+func ParsePlacementFailureThreshold(value string) (float64, error) {
+	threshold, err := strconv.ParseFloat(value, 64)
+	// NaN fails every comparison: check the valid range instead of the invalid one
+	if err != nil || !(threshold > 0 && threshold <= 1) {
+		return 0, fmt.Errorf("invalid placement failure threshold %s, must be larger than 0 and at most 1", value)
+	}
+	return threshold, nil
+}
+
+// Parse the placement failure back off property: a positive duration.
+func ParsePlacementFailureBackoff(value string) (time.Duration, error) {
+	backoff, err := time.ParseDuration(value)
+	if err != nil || backoff <= 0 {
+		return 0, fmt.Errorf("invalid placement failure backoff %s, must be a positive duration", value)
+	}
+	return backoff, nil
+}
+
+// Check the contention cap of the queue: the share must be between 0 and 1 and the threshold cannot be negative.
+// The root queue cannot have a cap as it always uses the whole partition.
+func checkContentionCap(queue *QueueConfig, level int) error {
+	contention := queue.ContentionCap
+	if contention.Share == 0 {
+		if contention.PendingThreshold != 0 {
+			return fmt.Errorf("contention cap pending threshold set without a share for queue %s", queue.Name)
+		}
+		return nil
+	}
+	if level == 1 {
+		return fmt.Errorf("contention cap cannot be set on the root queue")
+	}
+	if contention.Share < 0 || contention.Share > 1 {
+		return fmt.Errorf("contention cap share %v must be between 0 and 1 for queue %s", contention.Share, queue.Name)
+	}
+	if contention.PendingThreshold < 0 {
+		return fmt.Errorf("contention cap pending threshold %v cannot be negative for queue %s", contention.PendingThreshold, queue.Name)
+	}
+	return nil
+}
+
 // Check the structure of the queue in the config:
 // - exactly 1 root queue, added if missing
 // - the parent flag is set on queues that are missing it
@@ -320,33 +843,9 @@ func checkQueuesStructure(partition *PartitionConfig) error {
 	log.Logger().Debug("checking partition queue config",
 		zap.String("partitionName", partition.Name))
 
-	// handle no root queue cases
-	var insertRoot bool
-	if len(partition.Queues) != 1 {
-		// multiple or no top level queues: insert the root queue
-		insertRoot = true
-	} else {
-		// A single queue at the top must be the root queue, if not insert it
-		if strings.ToLower(partition.Queues[0].Name) != RootQueue {
-			insertRoot = true
-		} else {
-			// make sure root is a parent
-			partition.Queues[0].Parent = true
-		}
-	}
-
-	// insert the root queue if not there
-	if insertRoot {
-		log.Logger().Debug("inserting root queue",
-			zap.Int("numOfQueues", len(partition.Queues)))
-		var rootQueue QueueConfig
-		rootQueue.Name = RootQueue
-		rootQueue.Parent = true
-		rootQueue.Queues = partition.Queues
-		var newRoot []QueueConfig
-		newRoot = append(newRoot, rootQueue)
-		partition.Queues = newRoot
-	}
+	// handle no root queue cases: insert the root queue if not there and make sure root is a parent
+	partition.insertRootQueue()
+	partition.Queues[0].Parent = true
 
 	// check name uniqueness: we have a root to start with directly
 	var rootQueue = partition.Queues[0]
@@ -372,6 +871,31 @@ func Validate(newConfig *SchedulerConfig) error {
 		return fmt.Errorf("scheduler config is not set")
 	}
 
+	// check the REST API redaction settings
+	if err := checkRedaction(newConfig.Redaction); err != nil {
+		return err
+	}
+	// check the REST API authentication settings
+	if err := checkAuthentication(newConfig.Authentication); err != nil {
+		return err
+	}
+	// check the scheduling cycle tracing settings
+	if err := checkTracing(newConfig.Tracing); err != nil {
+		return err
+	}
+	// check the feature gate overrides
+	if err := features.CheckGates(newConfig.FeatureGates); err != nil {
+		return err
+	}
+	// check the REST API web service settings
+	if err := checkWebService(newConfig.WebService); err != nil {
+		return err
+	}
+	// check the shared placement rules before the partitions reference them
+	if err := checkPlacementRuleSets(newConfig.PlacementRuleSets); err != nil {
+		return err
+	}
+
 	// check for the default partition, if the partion is unnamed set it to default
 	var defaultPartition bool
 	for i, partition := range newConfig.Partitions {
@@ -391,6 +915,10 @@ func Validate(newConfig *SchedulerConfig) error {
 		if err != nil {
 			return err
 		}
+		err = resolvePlacementRuleSet(&partition, newConfig.PlacementRuleSets)
+		if err != nil {
+			return err
+		}
 		err = checkPlacementRules(&partition)
 		if err != nil {
 			return err
@@ -403,6 +931,38 @@ func Validate(newConfig *SchedulerConfig) error {
 		if err != nil {
 			return err
 		}
+		err = checkParallelAllocation(&partition)
+		if err != nil {
+			return err
+		}
+		err = checkQueueCleanup(&partition)
+		if err != nil {
+			return err
+		}
+		err = checkRecovery(&partition)
+		if err != nil {
+			return err
+		}
+		err = checkIdleApplications(&partition)
+		if err != nil {
+			return err
+		}
+		err = checkSystemQueue(&partition)
+		if err != nil {
+			return err
+		}
+		err = checkNodeGroups(&partition)
+		if err != nil {
+			return err
+		}
+		err = checkUnresolvedUser(&partition)
+		if err != nil {
+			return err
+		}
+		err = checkDynamicQueues(&partition)
+		if err != nil {
+			return err
+		}
 		// write back the partition to keep changes
 		newConfig.Partitions[i] = partition
 	}
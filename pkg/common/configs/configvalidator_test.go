@@ -19,7 +19,10 @@
 package configs
 
 import (
+	"math"
+	"strings"
 	"testing"
+	"time"
 
 	"gotest.tools/assert"
 )
@@ -228,3 +231,432 @@ func createQueueWithSkippedGuaranteedRes() QueueConfig {
 	}
 	return root
 }
+
+func TestCheckParallelAllocation(t *testing.T) {
+	partition := &PartitionConfig{Name: "default"}
+	assert.NilError(t, checkParallelAllocation(partition), "unset parallel allocation should pass")
+	partition.ParallelAllocation = ParallelAllocationConfig{Enabled: true, Workers: 4}
+	assert.NilError(t, checkParallelAllocation(partition), "positive worker count should pass")
+	partition.ParallelAllocation.Workers = -1
+	assert.ErrorContains(t, checkParallelAllocation(partition), "cannot be negative")
+}
+
+func TestCheckPlacementRuleSanitize(t *testing.T) {
+	rule := PlacementRule{Name: "user"}
+	assert.NilError(t, checkPlacementRule(rule), "unset sanitize option should pass")
+	rule.Sanitize = "Replace"
+	assert.NilError(t, checkPlacementRule(rule), "replace sanitize option should pass")
+	rule.Sanitize = "reject"
+	assert.NilError(t, checkPlacementRule(rule), "reject sanitize option should pass")
+	rule.Sanitize = "unknown"
+	assert.ErrorContains(t, checkPlacementRule(rule), "invalid rule sanitize option")
+	rule.Sanitize = ""
+	rule.Parent = &PlacementRule{Name: "tag", Value: "namespace", Sanitize: "unknown"}
+	assert.ErrorContains(t, checkPlacementRule(rule), "invalid rule sanitize option")
+}
+
+func TestCheckQueueWeight(t *testing.T) {
+	child := QueueConfig{Name: "child", Weight: 2.5}
+	root := QueueConfig{Name: RootQueue, Queues: []QueueConfig{child}}
+	assert.NilError(t, checkQueues(&root, 1), "positive weight should pass")
+	root.Queues[0].Weight = 0
+	assert.NilError(t, checkQueues(&root, 1), "unset weight should pass")
+	root.Queues[0].Weight = -1
+	assert.ErrorContains(t, checkQueues(&root, 1), "weight must be a positive finite number")
+	root.Queues[0].Weight = math.NaN()
+	assert.ErrorContains(t, checkQueues(&root, 1), "weight must be a positive finite number")
+	root.Queues[0].Weight = math.Inf(1)
+	assert.ErrorContains(t, checkQueues(&root, 1), "weight must be a positive finite number")
+}
+
+func TestCheckAllowedResourceTypes(t *testing.T) {
+	child := QueueConfig{Name: "child", AllowedResourceTypes: []string{"memory", "vcore"}}
+	root := QueueConfig{Name: RootQueue, Queues: []QueueConfig{child}}
+	assert.NilError(t, checkQueues(&root, 1), "unique resource types should pass")
+	root.Queues[0].AllowedResourceTypes = []string{"memory", " "}
+	assert.ErrorContains(t, checkQueues(&root, 1), "empty allowed resource type for queue child")
+	root.Queues[0].AllowedResourceTypes = []string{"memory", "memory"}
+	assert.ErrorContains(t, checkQueues(&root, 1), "duplicate allowed resource type memory for queue child")
+}
+
+func TestCheckPlacementFailure(t *testing.T) {
+	queue := &QueueConfig{Name: "leaf"}
+	assert.NilError(t, checkPlacementFailure(queue), "queue without properties should pass")
+	queue.Properties = map[string]string{PlacementFailureThreshold: "0.5", PlacementFailureBackoff: "10s"}
+	assert.NilError(t, checkPlacementFailure(queue), "valid properties should pass")
+	for _, value := range []string{"0", "1.5", "NaN", "x"} {
+		queue.Properties[PlacementFailureThreshold] = value
+		assert.ErrorContains(t, checkPlacementFailure(queue), "invalid placement failure threshold")
+	}
+	queue.Properties[PlacementFailureThreshold] = "1"
+	queue.Properties[PlacementFailureBackoff] = "-1s"
+	assert.ErrorContains(t, checkPlacementFailure(queue), "invalid placement failure backoff")
+}
+
+func TestCheckContentionCap(t *testing.T) {
+	child := QueueConfig{Name: "child", ContentionCap: ContentionCap{Share: 0.5, PendingThreshold: 1}}
+	root := QueueConfig{Name: RootQueue, Queues: []QueueConfig{child}}
+	assert.NilError(t, checkQueues(&root, 1), "valid contention cap should pass")
+	root.Queues[0].ContentionCap = ContentionCap{PendingThreshold: 1}
+	assert.ErrorContains(t, checkQueues(&root, 1), "pending threshold set without a share for queue child")
+	root.Queues[0].ContentionCap = ContentionCap{Share: 1.5}
+	assert.ErrorContains(t, checkQueues(&root, 1), "contention cap share 1.5 must be between 0 and 1 for queue child")
+	root.Queues[0].ContentionCap = ContentionCap{Share: 0.5, PendingThreshold: -1}
+	assert.ErrorContains(t, checkQueues(&root, 1), "cannot be negative for queue child")
+	root.Queues[0].ContentionCap = ContentionCap{}
+	root.ContentionCap = ContentionCap{Share: 0.5}
+	assert.ErrorContains(t, checkQueues(&root, 1), "contention cap cannot be set on the root queue")
+}
+
+func TestCheckChildTemplate(t *testing.T) {
+	parent := QueueConfig{
+		Name:      "parent",
+		Parent:    true,
+		Resources: Resources{Max: map[string]string{"memory": "100"}},
+		ChildTemplate: ChildTemplate{
+			Resources: Resources{
+				Max:        map[string]string{"memory": "50"},
+				Guaranteed: map[string]string{"memory": "10"},
+			},
+			SubmitACL: "user1 group1",
+		},
+	}
+	root := QueueConfig{Name: RootQueue, Parent: true, Queues: []QueueConfig{parent}}
+	assert.NilError(t, checkQueues(&root, 1), "valid template ACL should pass")
+	_, err := checkQueueResource(root, nil)
+	assert.NilError(t, err, "valid template resources should pass")
+
+	root.Queues[0].ChildTemplate.Resources.Max = map[string]string{"memory": "200"}
+	_, err = checkQueueResource(root, nil)
+	assert.ErrorContains(t, err, "for queue parent child template")
+
+	root.Queues[0].ChildTemplate.Resources.Max = map[string]string{"memory": "5"}
+	_, err = checkQueueResource(root, nil)
+	assert.ErrorContains(t, err, "guaranteed resource")
+
+	root.Queues[0].ChildTemplate.Resources.Max = map[string]string{"memory": "x"}
+	_, err = checkQueueResource(root, nil)
+	assert.Assert(t, err != nil, "unparsable template resource should fail")
+
+	root.Queues[0].ChildTemplate.Resources.Max = map[string]string{"memory": "50%"}
+	_, err = checkQueueResource(root, nil)
+	assert.ErrorContains(t, err, "invalid resource for queue parent child template")
+
+	root.Queues[0].ChildTemplate.SubmitACL = "user1 group1 other"
+	assert.ErrorContains(t, checkQueues(&root, 1), "multiple spaces found in ACL")
+}
+
+func TestCheckPriorityQuota(t *testing.T) {
+	leaf := QueueConfig{
+		Name:          "leaf",
+		Resources:     Resources{Max: map[string]string{"memory": "100"}},
+		PriorityQuota: PriorityQuota{Threshold: 100, Max: map[string]string{"memory": "50"}},
+	}
+	root := QueueConfig{Name: RootQueue, Parent: true, Queues: []QueueConfig{leaf}}
+	_, err := checkQueueResource(root, nil)
+	assert.NilError(t, err, "valid priority quota should pass")
+
+	root.Queues[0].PriorityQuota.Max = map[string]string{"memory": "200"}
+	_, err = checkQueueResource(root, nil)
+	assert.ErrorContains(t, err, "priority quota")
+
+	root.Queues[0].PriorityQuota.Max = map[string]string{"memory": "x"}
+	_, err = checkQueueResource(root, nil)
+	assert.Assert(t, err != nil, "unparsable priority quota should fail")
+}
+
+func TestCheckQueueResourceExpressions(t *testing.T) {
+	leaf := QueueConfig{
+		Name: "leaf",
+		Resources: Resources{
+			Max:        map[string]string{"memory": "50% of parent", "vcore": "parent - 10"},
+			Guaranteed: map[string]string{"memory": "25%"},
+		},
+	}
+	parent := QueueConfig{Name: "parent", Parent: true, Queues: []QueueConfig{leaf},
+		Resources: Resources{Max: map[string]string{"memory": "100", "vcore": "20"}}}
+	root := QueueConfig{Name: RootQueue, Parent: true, Queues: []QueueConfig{parent}}
+	_, err := checkQueueResource(root, nil)
+	assert.NilError(t, err, "valid expressions should pass")
+
+	// expressions are resolved against the parent: guaranteed 75 is larger than max 50
+	root.Queues[0].Queues[0].Resources.Guaranteed = map[string]string{"memory": "75% of parent"}
+	_, err = checkQueueResource(root, nil)
+	assert.ErrorContains(t, err, "guaranteed resource")
+
+	root.Queues[0].Queues[0].Resources.Guaranteed = nil
+	root.Queues[0].Queues[0].Resources.Max = map[string]string{"memory": "parent + 10"}
+	_, err = checkQueueResource(root, nil)
+	assert.ErrorContains(t, err, "max resource of parent")
+
+	// parent without the resource type: not resolved and not checked
+	root.Queues[0].Queues[0].Resources.Max = map[string]string{"gpu": "200%"}
+	_, err = checkQueueResource(root, nil)
+	assert.NilError(t, err, "unresolved expression should pass")
+
+	root.Queues[0].Queues[0].Resources.Max = map[string]string{"memory": "parent * 2"}
+	_, err = checkQueueResource(root, nil)
+	assert.ErrorContains(t, err, "invalid operator in resource expression")
+}
+
+func TestCheckQueueCleanup(t *testing.T) {
+	partition := &PartitionConfig{Name: "default"}
+	assert.NilError(t, checkQueueCleanup(partition), "unset idle timeout should pass")
+	partition.QueueCleanup.IdleTimeout = 30 * time.Minute
+	assert.NilError(t, checkQueueCleanup(partition), "positive idle timeout should pass")
+	partition.QueueCleanup.IdleTimeout = -time.Second
+	assert.ErrorContains(t, checkQueueCleanup(partition), "idle timeout cannot be negative")
+}
+
+func TestCheckRecovery(t *testing.T) {
+	partition := &PartitionConfig{Name: "default"}
+	assert.NilError(t, checkRecovery(partition), "unset recovery should pass")
+	partition.Recovery = RecoveryConfig{Window: 10 * time.Minute, Boost: 100}
+	assert.NilError(t, checkRecovery(partition), "positive window and boost should pass")
+	partition.Recovery.Window = -time.Second
+	assert.ErrorContains(t, checkRecovery(partition), "recovery window cannot be negative")
+	partition.Recovery = RecoveryConfig{Window: time.Minute, Boost: -1}
+	assert.ErrorContains(t, checkRecovery(partition), "recovery boost cannot be negative")
+}
+
+func TestCheckIdleApplications(t *testing.T) {
+	partition := &PartitionConfig{Name: "default"}
+	assert.NilError(t, checkIdleApplications(partition), "unset idle applications should pass")
+	partition.IdleApplications = IdleApplicationsConfig{Timeout: time.Hour}
+	assert.NilError(t, checkIdleApplications(partition), "detection only should pass")
+	partition.IdleApplications.Reclaim = 0.5
+	assert.NilError(t, checkIdleApplications(partition), "detection with reclaim should pass")
+	partition.IdleApplications.Timeout = -time.Second
+	assert.ErrorContains(t, checkIdleApplications(partition), "idle application timeout cannot be negative")
+	partition.IdleApplications = IdleApplicationsConfig{Timeout: time.Hour, Reclaim: 1.5}
+	assert.ErrorContains(t, checkIdleApplications(partition), "idle application reclaim must be between 0 and 1")
+	partition.IdleApplications = IdleApplicationsConfig{Reclaim: 0.5}
+	assert.ErrorContains(t, checkIdleApplications(partition), "idle application reclaim set without a timeout")
+}
+
+func TestCheckAuthentication(t *testing.T) {
+	hash := "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+	authentication := AuthenticationConfig{Enabled: true, AdminACL: "admin admins", Tokens: []TokenConfig{
+		{SHA256: hash, User: "admin"},
+	}}
+	assert.NilError(t, checkAuthentication(authentication), "valid authentication should pass")
+	authentication.Tokens = append(authentication.Tokens, TokenConfig{SHA256: strings.ToUpper(hash), User: "other"})
+	assert.ErrorContains(t, checkAuthentication(authentication), "duplicate authentication token")
+	authentication.Tokens[1] = TokenConfig{SHA256: "abcd", User: "other"}
+	assert.ErrorContains(t, checkAuthentication(authentication), "invalid SHA-256 hash")
+	authentication.Tokens[1] = TokenConfig{SHA256: "not hex", User: "other"}
+	assert.ErrorContains(t, checkAuthentication(authentication), "invalid SHA-256 hash")
+	authentication.Tokens[1] = TokenConfig{SHA256: hash}
+	assert.ErrorContains(t, checkAuthentication(authentication), "without a user")
+	authentication.Tokens = nil
+	authentication.AdminACL = "admin admins other"
+	assert.ErrorContains(t, checkAuthentication(authentication), "multiple spaces found in ACL")
+}
+
+func TestCheckRedaction(t *testing.T) {
+	redaction := RedactionConfig{Enabled: true, AdminACL: "admin admins", Tags: []string{"^secret\\."}}
+	assert.NilError(t, checkRedaction(redaction), "valid redaction should pass")
+	redaction.Tags = []string{"("}
+	assert.ErrorContains(t, checkRedaction(redaction), "invalid redaction tag expression")
+	redaction.Tags = nil
+	redaction.AdminACL = "admin admins other"
+	assert.ErrorContains(t, checkRedaction(redaction), "multiple spaces found in ACL")
+}
+
+func TestCheckTracing(t *testing.T) {
+	assert.NilError(t, checkTracing(TracingConfig{}), "empty tracing config should pass")
+	tracing := TracingConfig{Enabled: true, Mode: "Debug", CollectorEndpoint: "http://localhost:14268/api/traces"}
+	assert.NilError(t, checkTracing(tracing), "valid tracing config should pass")
+	tracing.Mode = "unknown"
+	assert.ErrorContains(t, checkTracing(tracing), "unknown tracing mode")
+	tracing.Mode = "DebugWithFilter"
+	assert.ErrorContains(t, checkTracing(tracing), "requires filter tags")
+	tracing.FilterTags = map[string]string{"state": "Allocated"}
+	assert.NilError(t, checkTracing(tracing), "filter mode with tags should pass")
+	tracing.CollectorEndpoint = "localhost:4317"
+	assert.ErrorContains(t, checkTracing(tracing), "invalid tracing collector endpoint")
+	tracing.CollectorEndpoint = "grpc://localhost:4317"
+	assert.ErrorContains(t, checkTracing(tracing), "invalid tracing collector endpoint")
+}
+
+func TestCheckWebService(t *testing.T) {
+	assert.NilError(t, checkWebService(WebServiceConfig{}), "empty web service config should pass")
+	webService := WebServiceConfig{
+		Address:        "127.0.0.1:9080",
+		AllowedOrigins: []string{"*", "https://dashboard.example.com", "http://localhost:8080/"},
+	}
+	assert.NilError(t, checkWebService(webService), "valid web service config should pass")
+	webService.Address = ":9090"
+	assert.NilError(t, checkWebService(webService), "address without host should pass")
+	webService.Address = "localhost"
+	assert.ErrorContains(t, checkWebService(webService), "invalid web service address")
+	webService.Address = ""
+	webService.AllowedOrigins = []string{"dashboard.example.com"}
+	assert.ErrorContains(t, checkWebService(webService), "invalid web service allowed origin")
+	webService.AllowedOrigins = []string{"https://dashboard.example.com/path"}
+	assert.ErrorContains(t, checkWebService(webService), "invalid web service allowed origin")
+
+	// the files are not read during validation
+	webService.AllowedOrigins = nil
+	webService.TLS = WebServiceTLS{CertFile: "/etc/tls/tls.crt", KeyFile: "/etc/tls/tls.key", ReloadInterval: time.Minute}
+	assert.NilError(t, checkWebService(webService), "certificate files should pass")
+	webService.TLS.KeyFile = ""
+	assert.ErrorContains(t, checkWebService(webService), "both the certificate file and the key file")
+	webService.TLS = WebServiceTLS{Cert: "cert"}
+	assert.ErrorContains(t, checkWebService(webService), "both the certificate and the key")
+	webService.TLS.Key = "key"
+	assert.ErrorContains(t, checkWebService(webService), "invalid web service TLS certificate")
+	webService.TLS = WebServiceTLS{CertFile: "/etc/tls/tls.crt", KeyFile: "/etc/tls/tls.key", ReloadInterval: -time.Minute}
+	assert.ErrorContains(t, checkWebService(webService), "reload interval cannot be negative")
+}
+
+func TestCheckPlacementRuleFallback(t *testing.T) {
+	rule := PlacementRule{
+		Name:     "providedfallback",
+		Fallback: []PlacementRule{{Name: "tag", Value: "namespace"}, {Name: "fixed", Value: "root.default"}},
+	}
+	assert.NilError(t, checkPlacementRule(rule), "valid fallback rules should pass")
+	rule.Fallback = append(rule.Fallback, PlacementRule{Name: "fixed", Sanitize: "unknown"})
+	assert.ErrorContains(t, checkPlacementRule(rule), "invalid rule sanitize option")
+}
+
+func TestCheckPlacementRuleMappings(t *testing.T) {
+	rule := PlacementRule{
+		Name:     "tagmapping",
+		Value:    "namespace",
+		Mappings: []TagMapping{{Match: "team-(.*)", Queue: "root.teams.$1"}},
+	}
+	assert.NilError(t, checkPlacementRule(rule), "valid tag mapping should pass")
+	rule.Mappings = append(rule.Mappings, TagMapping{Match: "(", Queue: "root.default"})
+	assert.ErrorContains(t, checkPlacementRule(rule), "invalid tag mapping expression")
+	rule.Mappings[1] = TagMapping{Match: ".*"}
+	assert.ErrorContains(t, checkPlacementRule(rule), "has no queue set")
+}
+
+func TestCheckPlacementRuleGroupResolver(t *testing.T) {
+	rule := PlacementRule{Name: "group"}
+	assert.NilError(t, checkPlacementRule(rule), "unset group resolver should pass")
+	rule.GroupResolver = GroupResolver{Type: "Static", Users: map[string][]string{"user1": {"group1", "group2"}}}
+	assert.NilError(t, checkPlacementRule(rule), "static group resolver should pass")
+	rule.GroupResolver.Users["user1"] = []string{"group 1"}
+	assert.ErrorContains(t, checkPlacementRule(rule), "invalid group name")
+	rule.GroupResolver = GroupResolver{Type: "unknown"}
+	assert.ErrorContains(t, checkPlacementRule(rule), "invalid group resolver type")
+}
+
+func TestCheckNodeSortingTieBreak(t *testing.T) {
+	partition := &PartitionConfig{Name: "default"}
+	assert.NilError(t, checkNodeSortingPolicy(partition), "unset tie break should pass")
+	partition.NodeSortPolicy = NodeSortingPolicy{Type: "binpacking", TieBreak: "RoundRobin"}
+	assert.NilError(t, checkNodeSortingPolicy(partition), "round robin tie break should pass")
+	partition.NodeSortPolicy.TieBreak = "random"
+	assert.NilError(t, checkNodeSortingPolicy(partition), "random tie break should pass")
+	partition.NodeSortPolicy.TieBreak = "unknown"
+	assert.ErrorContains(t, checkNodeSortingPolicy(partition), "invalid node sorting tie break")
+}
+
+func TestCheckSystemQueue(t *testing.T) {
+	partition := &PartitionConfig{
+		Name:   "default",
+		Queues: []QueueConfig{{Name: RootQueue, Queues: []QueueConfig{{Name: "default"}}}},
+	}
+	assert.NilError(t, checkSystemQueue(partition), "disabled system queue should pass")
+	partition.SystemQueue = SystemQueueConfig{Enabled: true, Guaranteed: map[string]string{"memory": "100"}}
+	assert.NilError(t, checkSystemQueue(partition), "valid system queue should pass")
+	partition.SystemQueue.Guaranteed = map[string]string{"memory": "-1"}
+	assert.ErrorContains(t, checkSystemQueue(partition), "invalid system queue guaranteed resource")
+	partition.SystemQueue.Guaranteed = nil
+	partition.SystemQueue.SubmitACL = "a b c"
+	assert.ErrorContains(t, checkSystemQueue(partition), "invalid system queue submit ACL")
+	partition.SystemQueue.SubmitACL = "rm"
+	assert.NilError(t, checkSystemQueue(partition), "valid system queue ACL should pass")
+	partition.Queues[0].Queues = append(partition.Queues[0].Queues, QueueConfig{Name: "System"})
+	assert.ErrorContains(t, checkSystemQueue(partition), "cannot be configured")
+}
+
+func TestCheckNodeGroups(t *testing.T) {
+	partition := &PartitionConfig{Name: "default"}
+	assert.NilError(t, checkNodeGroups(partition), "no node groups should pass")
+	partition.NodeGroups = []string{"instance-type", "zone"}
+	assert.NilError(t, checkNodeGroups(partition), "unique node groups should pass")
+	partition.NodeGroups = []string{"zone", ""}
+	assert.ErrorContains(t, checkNodeGroups(partition), "empty node group attribute")
+	partition.NodeGroups = []string{"zone", "zone"}
+	assert.ErrorContains(t, checkNodeGroups(partition), "duplicate node group attribute")
+}
+
+func TestCheckUnresolvedUser(t *testing.T) {
+	partition := &PartitionConfig{
+		Name: "default",
+		Queues: []QueueConfig{{
+			Name:   RootQueue,
+			Parent: true,
+			Queues: []QueueConfig{{Name: "quarantine"}, {Name: "parent", Parent: true}},
+		}},
+	}
+	assert.NilError(t, checkUnresolvedUser(partition), "default policy should pass")
+	partition.UnresolvedUser = UnresolvedUserConfig{Policy: "Anonymous", User: "guest"}
+	assert.NilError(t, checkUnresolvedUser(partition), "anonymous policy should pass")
+	partition.UnresolvedUser = UnresolvedUserConfig{Policy: "unknown"}
+	assert.ErrorContains(t, checkUnresolvedUser(partition), "unknown unresolved user policy")
+	partition.UnresolvedUser = UnresolvedUserConfig{Policy: UnresolvedUserQuarantine}
+	assert.ErrorContains(t, checkUnresolvedUser(partition), "must be a configured leaf queue")
+	partition.UnresolvedUser.Queue = "root.parent"
+	assert.ErrorContains(t, checkUnresolvedUser(partition), "must be a configured leaf queue")
+	partition.UnresolvedUser.Queue = "root.quarantine"
+	assert.NilError(t, checkUnresolvedUser(partition), "quarantine policy with a leaf queue should pass")
+}
+
+func TestCheckDynamicQueues(t *testing.T) {
+	partition := &PartitionConfig{Name: "default"}
+	assert.NilError(t, checkDynamicQueues(partition), "no limits should pass")
+	partition.DynamicQueues = DynamicQueuesConfig{MaxDepth: 3, MaxQueues: 100}
+	assert.NilError(t, checkDynamicQueues(partition), "positive limits should pass")
+	partition.DynamicQueues = DynamicQueuesConfig{MaxDepth: -1}
+	assert.ErrorContains(t, checkDynamicQueues(partition), "negative dynamic queue limits")
+	partition.DynamicQueues = DynamicQueuesConfig{MaxQueues: -1}
+	assert.ErrorContains(t, checkDynamicQueues(partition), "negative dynamic queue limits")
+}
+
+func TestCheckPlacementRuleSets(t *testing.T) {
+	assert.NilError(t, checkPlacementRuleSets(nil), "no rule sets should pass")
+	ruleSets := []PlacementRuleSet{{Name: "tenants", Rules: []PlacementRule{{Name: "provided"}, {Name: "user", Create: true}}}}
+	assert.NilError(t, checkPlacementRuleSets(ruleSets), "valid rule set should pass")
+	ruleSets = append(ruleSets, PlacementRuleSet{Name: "tenants", Rules: []PlacementRule{{Name: "user"}}})
+	assert.ErrorContains(t, checkPlacementRuleSets(ruleSets), "duplicate placement rule set name")
+	ruleSets[1] = PlacementRuleSet{Rules: []PlacementRule{{Name: "user"}}}
+	assert.ErrorContains(t, checkPlacementRuleSets(ruleSets), "name must be set")
+	ruleSets[1] = PlacementRuleSet{Name: "empty"}
+	assert.ErrorContains(t, checkPlacementRuleSets(ruleSets), "has no rules")
+	ruleSets[1] = PlacementRuleSet{Name: "invalid", Rules: []PlacementRule{{Name: "fixed", Sanitize: "unknown"}}}
+	assert.ErrorContains(t, checkPlacementRuleSets(ruleSets), "placement rule set invalid")
+}
+
+func TestResolvePlacementRuleSet(t *testing.T) {
+	ruleSets := []PlacementRuleSet{
+		{Name: "tenants", Rules: []PlacementRule{{Name: "provided"}, {Name: "user", Create: true}}},
+		{Name: "tags", Rules: []PlacementRule{{Name: "tag", Value: "namespace"}, {Name: "tag", Value: "team"}}},
+	}
+	// no reference leaves the rules alone
+	partition := &PartitionConfig{Name: "default", PlacementRules: []PlacementRule{{Name: "user"}}}
+	assert.NilError(t, resolvePlacementRuleSet(partition, ruleSets), "partition without reference should pass")
+	assert.DeepEqual(t, partition.PlacementRules, []PlacementRule{{Name: "user"}})
+
+	partition = &PartitionConfig{Name: "default", PlacementRuleSet: "unknown"}
+	assert.ErrorContains(t, resolvePlacementRuleSet(partition, ruleSets), "unknown placement rule set")
+
+	// the set is copied, overrides replace rules in place and other rules are added at the end
+	partition = &PartitionConfig{
+		Name:             "default",
+		PlacementRuleSet: "tenants",
+		PlacementRules:   []PlacementRule{{Name: "fixed", Value: "root.default"}, {Name: "user", Create: false}},
+	}
+	assert.NilError(t, resolvePlacementRuleSet(partition, ruleSets), "resolving the rule set should pass")
+	assert.DeepEqual(t, partition.PlacementRules, []PlacementRule{{Name: "provided"}, {Name: "user"}, {Name: "fixed", Value: "root.default"}})
+	assert.Equal(t, partition.PlacementRuleSet, "", "reference should be cleared")
+	assert.DeepEqual(t, ruleSets[0].Rules, []PlacementRule{{Name: "provided"}, {Name: "user", Create: true}})
+
+	// a rule that occurs more than once cannot be overridden
+	partition = &PartitionConfig{Name: "default", PlacementRuleSet: "tags", PlacementRules: []PlacementRule{{Name: "tag", Value: "queue"}}}
+	assert.ErrorContains(t, resolvePlacementRuleSet(partition, ruleSets), "occurs more than once")
+}
@@ -0,0 +1,161 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package configs
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Parse the stored configuration content without validating it. Changes made to the returned config keep the
+// form of the stored configuration: the validation defaults and name changes are not added. The only change made
+// is adding the root queue to the partitions that do not define it, which the validation also does.
+func ParseStoredConfig(content []byte) (*SchedulerConfig, error) {
+	conf := &SchedulerConfig{}
+	if err := yaml.UnmarshalStrict(content, conf); err != nil {
+		return nil, err
+	}
+	conf.Checksum = ""
+	for i := range conf.Partitions {
+		conf.Partitions[i].insertRootQueue()
+	}
+	return conf, nil
+}
+
+// Add the root queue to the partition if the partition does not have a single root queue at the top.
+// All queues at the top are moved below the root queue.
+func (partition *PartitionConfig) insertRootQueue() {
+	if len(partition.Queues) == 1 && strings.EqualFold(partition.Queues[0].Name, RootQueue) {
+		return
+	}
+	partition.Queues = []QueueConfig{{
+		Name:   RootQueue,
+		Parent: true,
+		Queues: partition.Queues,
+	}}
+}
+
+// Get the partition config with the given name, nil if the partition is not configured.
+func (conf *SchedulerConfig) GetPartitionConfig(name string) *PartitionConfig {
+	for i := range conf.Partitions {
+		if strings.EqualFold(conf.Partitions[i].Name, name) {
+			return &conf.Partitions[i]
+		}
+	}
+	return nil
+}
+
+// Get the config of the queue with the fully qualified path, nil if the queue is not configured.
+// The returned config is part of the partition config, changes are made to the partition config.
+func (partition *PartitionConfig) GetQueueConfig(queuePath string) *QueueConfig {
+	if len(partition.Queues) == 0 {
+		return nil
+	}
+	names := strings.Split(queuePath, DOT)
+	queue := &partition.Queues[0]
+	if !strings.EqualFold(queue.Name, names[0]) {
+		return nil
+	}
+	for _, name := range names[1:] {
+		queue = queue.getChildConfig(name)
+		if queue == nil {
+			return nil
+		}
+	}
+	return queue
+}
+
+// Add the queue to the parent in the queue path, the name of the queue is the last element of the path.
+// The parent must be configured and the queue must not be configured.
+func (partition *PartitionConfig) AddQueueConfig(queuePath string, queue QueueConfig) error {
+	split := strings.LastIndex(queuePath, DOT)
+	if split == -1 {
+		return fmt.Errorf("queue %s cannot be added: the root queue already exists", queuePath)
+	}
+	parent := partition.GetQueueConfig(queuePath[:split])
+	if parent == nil {
+		return fmt.Errorf("parent queue %s not found in the configuration", queuePath[:split])
+	}
+	queue.Name = queuePath[split+1:]
+	if parent.getChildConfig(queue.Name) != nil {
+		return fmt.Errorf("queue %s already exists in the configuration", queuePath)
+	}
+	parent.Parent = true
+	parent.Queues = append(parent.Queues, queue)
+	return nil
+}
+
+// Remove the queue, and all queues below it, from the configuration.
+func (partition *PartitionConfig) RemoveQueueConfig(queuePath string) error {
+	split := strings.LastIndex(queuePath, DOT)
+	if split == -1 {
+		return fmt.Errorf("root queue cannot be removed")
+	}
+	parent := partition.GetQueueConfig(queuePath[:split])
+	if parent == nil {
+		return fmt.Errorf("queue %s not found in the configuration", queuePath)
+	}
+	name := queuePath[split+1:]
+	for i := range parent.Queues {
+		if strings.EqualFold(parent.Queues[i].Name, name) {
+			parent.Queues = append(parent.Queues[:i], parent.Queues[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("queue %s not found in the configuration", queuePath)
+}
+
+// Move the queue, and all queues below it, to the new path. The last element of the new path is the new name of
+// the queue. The new parent must be configured and the new path must not be configured.
+func (partition *PartitionConfig) MoveQueueConfig(queuePath, newPath string) error {
+	queue := partition.GetQueueConfig(queuePath)
+	if queue == nil {
+		return fmt.Errorf("queue %s not found in the configuration", queuePath)
+	}
+	// check the new path before changing anything: a failed move must not remove the queue
+	split := strings.LastIndex(newPath, DOT)
+	if split == -1 {
+		return fmt.Errorf("queue %s cannot be moved to the root queue", queuePath)
+	}
+	if strings.HasPrefix(strings.ToLower(newPath+DOT), strings.ToLower(queuePath+DOT)) {
+		return fmt.Errorf("queue %s cannot be moved below itself", queuePath)
+	}
+	if partition.GetQueueConfig(newPath[:split]) == nil {
+		return fmt.Errorf("parent queue %s not found in the configuration", newPath[:split])
+	}
+	if partition.GetQueueConfig(newPath) != nil {
+		return fmt.Errorf("queue %s already exists in the configuration", newPath)
+	}
+	moved := *queue
+	if err := partition.RemoveQueueConfig(queuePath); err != nil {
+		return err
+	}
+	return partition.AddQueueConfig(newPath, moved)
+}
+
+func (queue *QueueConfig) getChildConfig(name string) *QueueConfig {
+	for i := range queue.Queues {
+		if strings.EqualFold(queue.Queues[i].Name, name) {
+			return &queue.Queues[i]
+		}
+	}
+	return nil
+}
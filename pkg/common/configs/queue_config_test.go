@@ -0,0 +1,109 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package configs
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestQueueConfigChanges(t *testing.T) {
+	conf := &SchedulerConfig{
+		Partitions: []PartitionConfig{{
+			Name: "default",
+			Queues: []QueueConfig{{
+				Name:   "root",
+				Parent: true,
+				Queues: []QueueConfig{{Name: "leaf"}},
+			}},
+		}},
+	}
+	assert.Assert(t, conf.GetPartitionConfig("unknown") == nil, "unknown partition should not be found")
+	partition := conf.GetPartitionConfig("DEFAULT")
+	assert.Assert(t, partition != nil, "partition lookup should ignore case")
+	assert.Assert(t, partition.GetQueueConfig("root.unknown") == nil, "unknown queue should not be found")
+	assert.Assert(t, partition.GetQueueConfig("other.leaf") == nil, "queue below unknown root should not be found")
+	assert.Equal(t, partition.GetQueueConfig("root.Leaf").Name, "leaf", "queue lookup should ignore case")
+
+	// add below a leaf turns the leaf into a parent
+	err := partition.AddQueueConfig("root.leaf.child", QueueConfig{Properties: map[string]string{"key": "value"}})
+	assert.NilError(t, err, "add of child queue failed")
+	assert.Assert(t, partition.GetQueueConfig("root.leaf").Parent, "queue with a child should be a parent")
+	child := partition.GetQueueConfig("root.leaf.child")
+	assert.Assert(t, child != nil, "added queue should be found")
+	assert.Equal(t, child.Properties["key"], "value", "added queue should keep its config")
+	assert.ErrorContains(t, partition.AddQueueConfig("root.leaf.child", QueueConfig{}), "already exists")
+	assert.ErrorContains(t, partition.AddQueueConfig("root.unknown.child", QueueConfig{}), "parent queue root.unknown not found")
+	assert.ErrorContains(t, partition.AddQueueConfig("root", QueueConfig{}), "root queue already exists")
+
+	// changes through the returned config are made to the partition
+	child.MaxApplications = 10
+	assert.Equal(t, partition.GetQueueConfig("root.leaf.child").MaxApplications, uint64(10), "change should be made in the partition")
+
+	// move and rename a queue with its children
+	assert.ErrorContains(t, partition.MoveQueueConfig("root.unknown", "root.other"), "not found")
+	assert.ErrorContains(t, partition.MoveQueueConfig("root.leaf", "root.unknown.other"), "parent queue root.unknown not found")
+	assert.ErrorContains(t, partition.MoveQueueConfig("root.leaf", "root.leaf.child.other"), "below itself")
+	assert.ErrorContains(t, partition.MoveQueueConfig("root.leaf.child", "root.leaf"), "already exists")
+	assert.ErrorContains(t, partition.MoveQueueConfig("root.leaf", "root"), "cannot be moved to the root")
+	assert.Assert(t, partition.GetQueueConfig("root.leaf.child") != nil, "failed move should not change the config")
+	assert.NilError(t, partition.MoveQueueConfig("root.leaf", "root.moved"), "move of queue failed")
+	assert.Assert(t, partition.GetQueueConfig("root.leaf") == nil, "queue should have been removed from the old path")
+	assert.Equal(t, partition.GetQueueConfig("root.moved.child").MaxApplications, uint64(10), "child should have moved with the queue")
+	assert.NilError(t, partition.MoveQueueConfig("root.moved", "root.leaf"), "move back of queue failed")
+
+	assert.ErrorContains(t, partition.RemoveQueueConfig("root"), "root queue cannot be removed")
+	assert.ErrorContains(t, partition.RemoveQueueConfig("root.unknown"), "not found")
+	assert.ErrorContains(t, partition.RemoveQueueConfig("root.unknown.child"), "not found")
+	assert.NilError(t, partition.RemoveQueueConfig("root.leaf"), "remove of queue failed")
+	assert.Assert(t, partition.GetQueueConfig("root.leaf.child") == nil, "child of removed queue should be removed")
+	assert.Equal(t, len(partition.Queues[0].Queues), 0, "root should have no children left")
+}
+
+func TestParseStoredConfig(t *testing.T) {
+	content := []byte(`
+partitions:
+  - name: Default
+    queues:
+      - name: Leaf
+        properties:
+          key: value
+checksum: ABC
+`)
+	conf, err := ParseStoredConfig(content)
+	assert.NilError(t, err, "parse of stored config failed")
+	assert.Equal(t, conf.Checksum, "", "checksum should have been removed")
+	partition := conf.GetPartitionConfig("default")
+	assert.Assert(t, partition != nil, "partition should be found")
+	assert.Equal(t, partition.Name, "Default", "partition name should not be normalised")
+	assert.Equal(t, len(partition.Queues), 1, "root queue should have been inserted")
+	leaf := partition.GetQueueConfig("root.leaf")
+	assert.Assert(t, leaf != nil, "queue should be below the inserted root")
+	assert.Equal(t, leaf.Name, "Leaf", "queue name should not be normalised")
+
+	_, err = ParseStoredConfig([]byte("unknown: field"))
+	assert.ErrorContains(t, err, "unknown", "unknown fields should fail the parse")
+
+	// the content is kept on load without the checksum
+	loaded, err := LoadSchedulerConfigFromByteArray(content)
+	assert.NilError(t, err, "load of config failed")
+	assert.Equal(t, loaded.Partitions[0].Name, "default", "loaded config should be normalised")
+	assert.Equal(t, string(loaded.GetContent()), GetConfigurationString(content), "content should be kept without the checksum")
+}
@@ -0,0 +1,115 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package configs
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Return the paths of all fields in the content that do not exist in the scheduler config, i.e. mistyped keys.
+// The path is built from the yaml keys with the index for list entries: partitions[0].queues[1].resources.gauranteed
+// Returns nil if the content cannot be parsed or has no unknown fields.
+func unknownFieldPaths(content []byte) []string {
+	var raw interface{}
+	if err := yaml.Unmarshal(content, &raw); err != nil {
+		return nil
+	}
+	var paths []string
+	collectUnknownFields(raw, reflect.TypeOf(SchedulerConfig{}), "", &paths)
+	return paths
+}
+
+// Walk the parsed yaml and the type it is decoded into side by side and collect the keys that have no field.
+func collectUnknownFields(value interface{}, typ reflect.Type, path string, paths *[]string) {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	switch typ.Kind() {
+	case reflect.Struct:
+		entries, ok := value.(map[interface{}]interface{})
+		if !ok {
+			return
+		}
+		fields := yamlFields(typ)
+		for _, key := range sortedKeys(entries) {
+			fieldPath := key
+			if path != "" {
+				fieldPath = path + DOT + key
+			}
+			field, found := fields[key]
+			if !found {
+				*paths = append(*paths, fieldPath)
+				continue
+			}
+			collectUnknownFields(entries[key], field, fieldPath, paths)
+		}
+	case reflect.Slice:
+		list, ok := value.([]interface{})
+		if !ok {
+			return
+		}
+		for i, entry := range list {
+			collectUnknownFields(entry, typ.Elem(), fmt.Sprintf("%s[%d]", path, i), paths)
+		}
+	case reflect.Map:
+		entries, ok := value.(map[interface{}]interface{})
+		if !ok {
+			return
+		}
+		for _, key := range sortedKeys(entries) {
+			collectUnknownFields(entries[key], typ.Elem(), path+DOT+key, paths)
+		}
+	}
+}
+
+// Return the keys of the parsed yaml map as strings in a stable order.
+func sortedKeys(entries map[interface{}]interface{}) []string {
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, fmt.Sprint(key))
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Return the yaml key for each field of the struct type: the name from the yaml tag or the lower case field name
+// like the yaml decoder uses.
+func yamlFields(typ reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		fields[name] = field.Type
+	}
+	return fields
+}
@@ -0,0 +1,58 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package configs
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestUnknownFieldPaths(t *testing.T) {
+	data := `
+partitions:
+  - name: default
+    queues:
+      - name: root
+        queues:
+          - name: leaf
+            resources:
+              gauranteed:
+                memory: 100
+            properties:
+              any.key: value
+    placementrules:
+      - name: tag
+        value: namespace
+        filter:
+          tpye: allow
+    unknown: true
+`
+	paths := unknownFieldPaths([]byte(data))
+	assert.DeepEqual(t, paths, []string{
+		"partitions[0].placementrules[0].filter.tpye",
+		"partitions[0].queues[0].queues[0].resources.gauranteed",
+		"partitions[0].unknown",
+	})
+	assert.Assert(t, unknownFieldPaths([]byte(validConf)) == nil, "valid config should not have unknown fields")
+	assert.Assert(t, unknownFieldPaths([]byte("partitions: [")) == nil, "invalid yaml should not have unknown fields")
+
+	_, err := ParseAndValidateConfig([]byte(data))
+	assert.ErrorContains(t, err, "unknown fields partitions[0].placementrules[0].filter.tpye, partitions[0].queues[0].queues[0].resources.gauranteed, partitions[0].unknown in configuration")
+}
@@ -0,0 +1,186 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package features
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/log"
+)
+
+// The feature gates that control the scheduler subsystems.
+const (
+	Preemption     = "Preemption"
+	GangScheduling = "GangScheduling"
+	AdaptiveCycles = "AdaptiveCycles"
+)
+
+// The environment variable to override the default gate states, format: name=true|false[,name=true|false]
+const EnvFeatureGates = "FEATURE_GATES"
+
+// The default state for each known gate.
+var defaultGates = map[string]bool{
+	Preemption:     true,
+	GangScheduling: true,
+	AdaptiveCycles: false,
+}
+
+// The gate states are the defaults, overridden by the environment, overridden by the configuration.
+var (
+	gates       map[string]bool
+	envGates    map[string]bool
+	configGates map[string]bool
+	lock        sync.RWMutex
+)
+
+// The state of a feature gate.
+type GateState struct {
+	Name    string
+	Enabled bool
+	Default bool
+}
+
+func init() {
+	if err := SetGates(os.Getenv(EnvFeatureGates)); err != nil {
+		log.Logger().Warn("failed to parse feature gates from the environment, using defaults",
+			zap.String("name", EnvFeatureGates),
+			zap.Error(err))
+	}
+}
+
+// Return true if the gate is enabled. An unknown gate is never enabled.
+func Enabled(name string) bool {
+	lock.RLock()
+	defer lock.RUnlock()
+	return gates[name]
+}
+
+// Set the gate states from a string with the format: name=true|false[,name=true|false]
+// Gates not mentioned are set to their default, an empty string resets all gates to the default.
+// An unknown gate or a value that cannot be parsed returns an error and resets all gates to the default.
+// Gates set in the configuration are not changed.
+func SetGates(value string) error {
+	newGates := make(map[string]bool)
+	var err error
+	if value != "" {
+		err = parseGates(value, newGates)
+	}
+	lock.Lock()
+	defer lock.Unlock()
+	if err != nil {
+		envGates = nil
+		updateGates()
+		return err
+	}
+	envGates = newGates
+	updateGates()
+	return nil
+}
+
+// Set the gate states from the configuration, these override the defaults and the environment.
+// Gates not mentioned are set from the environment or to their default, nil removes all configured states.
+// An unknown gate returns an error and leaves the gates unchanged.
+func SetConfigGates(overrides map[string]bool) error {
+	if err := CheckGates(overrides); err != nil {
+		return err
+	}
+	newGates := make(map[string]bool, len(overrides))
+	for name, enabled := range overrides {
+		newGates[name] = enabled
+	}
+	lock.Lock()
+	defer lock.Unlock()
+	configGates = newGates
+	updateGates()
+	return nil
+}
+
+// Check that all gates are known gates.
+func CheckGates(overrides map[string]bool) error {
+	for name := range overrides {
+		if _, ok := defaultGates[name]; !ok {
+			return fmt.Errorf("unknown feature gate '%s'", name)
+		}
+	}
+	return nil
+}
+
+// Combine the defaults, the environment and the configuration into the gate states.
+// NOTE: this is a lock free call. It must only be called holding the lock.
+func updateGates() {
+	gates = make(map[string]bool, len(defaultGates))
+	for _, layer := range []map[string]bool{defaultGates, envGates, configGates} {
+		for name, enabled := range layer {
+			gates[name] = enabled
+		}
+	}
+}
+
+func parseGates(value string, newGates map[string]bool) error {
+	for _, entry := range strings.Split(value, ",") {
+		parts := strings.SplitN(strings.TrimSpace(entry), "=", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("invalid feature gate entry '%s', expected name=true|false", entry)
+		}
+		if _, ok := defaultGates[parts[0]]; !ok {
+			return fmt.Errorf("unknown feature gate '%s'", parts[0])
+		}
+		enabled, err := strconv.ParseBool(parts[1])
+		if err != nil {
+			return fmt.Errorf("invalid value for feature gate '%s': %s", parts[0], parts[1])
+		}
+		newGates[parts[0]] = enabled
+	}
+	return nil
+}
+
+// Return the state of all known gates sorted on the name.
+func GetGates() []GateState {
+	lock.RLock()
+	defer lock.RUnlock()
+	states := make([]GateState, 0, len(gates))
+	for name, enabled := range gates {
+		states = append(states, GateState{
+			Name:    name,
+			Enabled: enabled,
+			Default: defaultGates[name],
+		})
+	}
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].Name < states[j].Name
+	})
+	return states
+}
+
+// Log the state of all known gates.
+func LogGates() {
+	for _, state := range GetGates() {
+		log.Logger().Info("feature gate",
+			zap.String("name", state.Name),
+			zap.Bool("enabled", state.Enabled),
+			zap.Bool("default", state.Default))
+	}
+}
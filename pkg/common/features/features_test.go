@@ -0,0 +1,81 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package features
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestSetGates(t *testing.T) {
+	defer func() {
+		assert.NilError(t, SetGates(""), "reset of gates failed")
+	}()
+	assert.NilError(t, SetGates(""), "empty gates should not fail")
+	assert.Assert(t, Enabled(Preemption), "preemption should be enabled by default")
+	assert.Assert(t, Enabled(GangScheduling), "gang scheduling should be enabled by default")
+	assert.Assert(t, !Enabled("unknown"), "unknown gate should not be enabled")
+
+	assert.NilError(t, SetGates("Preemption=false"), "valid gates should not fail")
+	assert.Assert(t, !Enabled(Preemption), "preemption should be disabled")
+	assert.Assert(t, Enabled(GangScheduling), "gang scheduling should not be changed")
+
+	// failures reset to the defaults
+	assert.ErrorContains(t, SetGates("Preemption=false, Unknown=true"), "unknown feature gate")
+	assert.Assert(t, Enabled(Preemption), "preemption should be reset after failure")
+	assert.ErrorContains(t, SetGates("GangScheduling"), "expected name=true|false")
+	assert.ErrorContains(t, SetGates("GangScheduling=maybe"), "invalid value for feature gate")
+	assert.Assert(t, Enabled(GangScheduling), "gang scheduling should be reset after failure")
+}
+
+func TestGetGates(t *testing.T) {
+	defer func() {
+		assert.NilError(t, SetGates(""), "reset of gates failed")
+	}()
+	assert.NilError(t, SetGates("GangScheduling=false"), "valid gates should not fail")
+	expected := []GateState{
+		{Name: AdaptiveCycles, Enabled: false, Default: false},
+		{Name: GangScheduling, Enabled: false, Default: true},
+		{Name: Preemption, Enabled: true, Default: true},
+	}
+	assert.DeepEqual(t, GetGates(), expected)
+}
+
+func TestSetConfigGates(t *testing.T) {
+	defer func() {
+		assert.NilError(t, SetConfigGates(nil), "reset of config gates failed")
+		assert.NilError(t, SetGates(""), "reset of gates failed")
+	}()
+	assert.NilError(t, SetGates("Preemption=false,GangScheduling=false"), "valid gates should not fail")
+	// the configuration overrides the environment, gates not configured keep the environment state
+	assert.NilError(t, SetConfigGates(map[string]bool{Preemption: true, AdaptiveCycles: true}), "valid config gates should not fail")
+	assert.Assert(t, Enabled(Preemption), "config should override the environment")
+	assert.Assert(t, !Enabled(GangScheduling), "gate not in the config should keep the environment state")
+	assert.Assert(t, Enabled(AdaptiveCycles), "config should override the default")
+
+	// an unknown gate leaves the gates unchanged
+	assert.ErrorContains(t, SetConfigGates(map[string]bool{"Unknown": true}), "unknown feature gate")
+	assert.Assert(t, Enabled(AdaptiveCycles), "gates should not change on failure")
+
+	// removing the config gates falls back to the environment
+	assert.NilError(t, SetConfigGates(nil), "removing config gates should not fail")
+	assert.Assert(t, !Enabled(Preemption), "environment state expected")
+	assert.Assert(t, !Enabled(AdaptiveCycles), "default state expected")
+}
@@ -0,0 +1,132 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resources
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	parentKeyword = "parent"
+	ofParent      = "of " + parentKeyword
+)
+
+// Check if the configured quantity is an expression relative to the parent instead of a fixed quantity.
+func IsQuantityExpression(value string) bool {
+	value = strings.TrimSpace(value)
+	return strings.HasPrefix(value, parentKeyword) || strings.Contains(value, "%")
+}
+
+// Create a new resource from the configuration, the quantities can be expressions relative to the parent resource.
+// The supported formats for a quantity are:
+// - "100", "2Gi" or "1.5k": a fixed quantity, see ParseQuantity for the supported units
+// - "25%" or "25% of parent": a percentage of the parent quantity, rounded down
+// - "parent", "parent - 10" or "parent + 1Gi": the parent quantity with a fixed quantity subtracted or added
+// An expression for a resource type that is not set in the parent cannot be resolved and is left out of the result.
+// A resolved quantity smaller than zero is set to zero.
+func NewResourceFromConfWithParent(configMap map[string]string, parent *Resource) (*Resource, error) {
+	res := NewResource()
+	for key, strVal := range configMap {
+		if !IsQuantityExpression(strVal) {
+			quantity, err := ParseQuantity(key, strVal)
+			if err != nil {
+				return nil, err
+			}
+			if quantity < 0 {
+				return nil, fmt.Errorf("negative resources not permitted: %v", configMap)
+			}
+			res.Resources[key] = quantity
+			continue
+		}
+		resolve, err := parseQuantityExpression(key, strVal)
+		if err != nil {
+			return nil, err
+		}
+		if parent == nil {
+			continue
+		}
+		if parentValue, ok := parent.Resources[key]; ok {
+			res.Resources[key] = resolve(parentValue)
+		}
+	}
+	return res, nil
+}
+
+// Parse the expression for the resource type and return the function that resolves it against the parent quantity.
+func parseQuantityExpression(resourceType, expression string) (func(Quantity) Quantity, error) {
+	value := strings.TrimSpace(expression)
+	if strings.HasSuffix(value, ofParent) {
+		value = strings.TrimSpace(strings.TrimSuffix(value, ofParent))
+	}
+	if strings.HasSuffix(value, "%") {
+		percentage, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(value, "%")), 64)
+		if err != nil || percentage < 0 || math.IsInf(percentage, 0) || math.IsNaN(percentage) {
+			return nil, fmt.Errorf("invalid percentage in resource expression: %s", expression)
+		}
+		return func(parent Quantity) Quantity {
+			return clampQuantity(math.Floor(float64(parent) * percentage / 100))
+		}, nil
+	}
+	if !strings.HasPrefix(value, parentKeyword) {
+		return nil, fmt.Errorf("invalid resource expression: %s", expression)
+	}
+	value = strings.TrimSpace(strings.TrimPrefix(value, parentKeyword))
+	if value == "" {
+		return func(parent Quantity) Quantity {
+			return parent
+		}, nil
+	}
+	sign := value[:1]
+	if sign != "+" && sign != "-" {
+		return nil, fmt.Errorf("invalid operator in resource expression: %s", expression)
+	}
+	quantity, err := ParseQuantity(resourceType, value[1:])
+	delta := int64(quantity)
+	if err != nil || delta < 0 {
+		return nil, fmt.Errorf("invalid quantity in resource expression: %s", expression)
+	}
+	if sign == "-" {
+		delta = -delta
+	}
+	return func(parent Quantity) Quantity {
+		sum := int64(parent) + delta
+		// overflow when adding to a large quantity
+		if delta > 0 && sum < int64(parent) {
+			return math.MaxInt64
+		}
+		if sum < 0 {
+			return 0
+		}
+		return Quantity(sum)
+	}, nil
+}
+
+// Convert to a quantity limited to the range zero to the maximum quantity.
+func clampQuantity(value float64) Quantity {
+	if value <= 0 {
+		return 0
+	}
+	if value >= math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return Quantity(value)
+}
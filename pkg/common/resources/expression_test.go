@@ -0,0 +1,73 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resources
+
+import (
+	"math"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestIsQuantityExpression(t *testing.T) {
+	assert.Assert(t, !IsQuantityExpression("100"), "fixed quantity is not an expression")
+	assert.Assert(t, !IsQuantityExpression(""), "empty quantity is not an expression")
+	assert.Assert(t, IsQuantityExpression("25%"), "percentage is an expression")
+	assert.Assert(t, IsQuantityExpression("25% of parent"), "percentage of parent is an expression")
+	assert.Assert(t, IsQuantityExpression(" parent - 10"), "parent arithmetic is an expression")
+}
+
+func TestNewResourceFromConfWithParent(t *testing.T) {
+	parent := NewResourceFromMap(map[string]Quantity{"memory": 1000, "vcore": 100, "large": math.MaxInt64 - 5})
+	tests := []struct {
+		name     string
+		value    string
+		expected Quantity
+	}{
+		{"fixed", "42", 42},
+		{"percentage", "25%", 250},
+		{"percentage of parent", "25% of parent", 250},
+		{"fraction rounded down", "12.55 % of parent", 125},
+		{"parent", "parent", 1000},
+		{"parent minus", "parent - 10", 990},
+		{"parent plus", "parent+10", 1010},
+		{"negative result", "parent - 2000", 0},
+	}
+	for _, tt := range tests {
+		res, err := NewResourceFromConfWithParent(map[string]string{"memory": tt.value}, parent)
+		assert.NilError(t, err, "%s: unexpected error", tt.name)
+		assert.Equal(t, res.Resources["memory"], tt.expected, "%s: unexpected quantity", tt.name)
+	}
+
+	// overflow and missing parent values
+	res, err := NewResourceFromConfWithParent(map[string]string{"large": "parent + 10", "unknown": "50%", "vcore": "parent"}, parent)
+	assert.NilError(t, err, "unexpected error")
+	assert.Equal(t, res.Resources["large"], Quantity(math.MaxInt64), "overflow should be capped")
+	assert.Equal(t, res.Resources["vcore"], Quantity(100), "unexpected vcore quantity")
+	_, ok := res.Resources["unknown"]
+	assert.Assert(t, !ok, "expression without a parent value should not be resolved")
+	res, err = NewResourceFromConfWithParent(map[string]string{"memory": "50%", "vcore": "10"}, nil)
+	assert.NilError(t, err, "unexpected error without parent")
+	assert.DeepEqual(t, res.Resources, map[string]Quantity{"vcore": 10})
+
+	for _, value := range []string{"ten", "-10", "-5%", "x%", "25% of queue", "parent * 2", "parent - x", "parent - -1", "parents"} {
+		_, err = NewResourceFromConfWithParent(map[string]string{"memory": value}, parent)
+		assert.Assert(t, err != nil, "value '%s' should fail", value)
+	}
+}
@@ -0,0 +1,81 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resources
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// A quantity with an optional fraction followed by an optional unit suffix.
+var quantityPattern = regexp.MustCompile(`^([+-]?(?:[0-9]+(?:\.[0-9]*)?|\.[0-9]+))([a-zA-Z]*)$`)
+
+// Multipliers of the supported unit suffixes, these follow the Kubernetes quantity conventions.
+var quantitySuffixes = map[string]*big.Rat{
+	"":   big.NewRat(1, 1),
+	"m":  big.NewRat(1, 1000),
+	"k":  big.NewRat(1e3, 1),
+	"M":  big.NewRat(1e6, 1),
+	"G":  big.NewRat(1e9, 1),
+	"T":  big.NewRat(1e12, 1),
+	"P":  big.NewRat(1e15, 1),
+	"E":  big.NewRat(1e18, 1),
+	"Ki": big.NewRat(1<<10, 1),
+	"Mi": big.NewRat(1<<20, 1),
+	"Gi": big.NewRat(1<<30, 1),
+	"Ti": big.NewRat(1<<40, 1),
+	"Pi": big.NewRat(1<<50, 1),
+	"Ei": big.NewRat(1<<60, 1),
+}
+
+// Parse a configured quantity for the resource type.
+// Every resource type is tracked in one internal unit: the unit the resource manager reports the type in, for
+// instance milli cores for vcore. A configured value is always in that internal unit, a unit suffix is a plain
+// multiplier of it: "1", "1.0" and "1000m" are all one unit and "2Ki" is 2048 units. The value must be a whole
+// number of units, a fraction is only accepted if the suffix turns it into a whole number, like "1.5k".
+func ParseQuantity(resourceType, value string) (Quantity, error) {
+	value = strings.TrimSpace(value)
+	if intValue, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return Quantity(intValue), nil
+	}
+	parts := quantityPattern.FindStringSubmatch(value)
+	if parts == nil {
+		return 0, fmt.Errorf("invalid quantity for resource %s: %s", resourceType, value)
+	}
+	multiplier, ok := quantitySuffixes[parts[2]]
+	if !ok {
+		return 0, fmt.Errorf("unknown unit suffix for resource %s: %s", resourceType, value)
+	}
+	number, ok := new(big.Rat).SetString(parts[1])
+	if !ok {
+		return 0, fmt.Errorf("invalid quantity for resource %s: %s", resourceType, value)
+	}
+	number.Mul(number, multiplier)
+	if !number.IsInt() {
+		return 0, fmt.Errorf("quantity for resource %s is not a whole number of units: %s", resourceType, value)
+	}
+	result := number.Num()
+	if !result.IsInt64() {
+		return 0, fmt.Errorf("quantity out of range for resource %s: %s", resourceType, value)
+	}
+	return Quantity(result.Int64()), nil
+}
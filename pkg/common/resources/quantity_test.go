@@ -0,0 +1,85 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestParseQuantity(t *testing.T) {
+	tests := []struct {
+		name         string
+		resourceType string
+		value        string
+		expected     Quantity
+	}{
+		{"plain integer", MEMORY, "1024", 1024},
+		{"plain vcore", VCORE, "1500", 1500},
+		{"negative integer", MEMORY, "-10", -10},
+		{"spaces", MEMORY, " 10 ", 10},
+		{"binary suffix", MEMORY, "2Gi", 2 * 1024 * 1024 * 1024},
+		{"decimal suffix", MEMORY, "5M", 5000000},
+		{"fraction with suffix", MEMORY, "1.5Ki", 1536},
+		{"whole fraction", VCORE, "2.0", 2},
+		{"milli suffix", VCORE, "1500000m", 1500},
+		{"fraction vcore with suffix", VCORE, "1.5k", 1500},
+		{"negative fraction with suffix", MEMORY, "-1.5k", -1500},
+		{"leading dot", MEMORY, ".5Ki", 512},
+	}
+	for _, tt := range tests {
+		quantity, err := ParseQuantity(tt.resourceType, tt.value)
+		assert.NilError(t, err, "%s: unexpected error", tt.name)
+		assert.Equal(t, quantity, tt.expected, "%s: unexpected quantity", tt.name)
+	}
+
+	failures := []string{"", "xx", "1.2.3", "10 Gi", "10Xi", "Gi", "9Ei", "1e3", "0.5", "1500m", "-1.5", "1.0001k"}
+	for _, value := range failures {
+		_, err := ParseQuantity(MEMORY, value)
+		assert.Assert(t, err != nil, "parsing '%s' should have failed", value)
+	}
+	quantity, err := ParseQuantity(MEMORY, "7Ei")
+	assert.NilError(t, err, "largest binary suffix should fit")
+	assert.Equal(t, quantity, Quantity(7*(1<<60)), "unexpected quantity")
+	_, err = ParseQuantity(MEMORY, "8Ei")
+	assert.ErrorContains(t, err, "out of range", "quantity should overflow")
+}
+
+// all input forms are in the same internal unit
+func TestParseQuantitySameUnit(t *testing.T) {
+	for _, value := range []string{"1", "1.0", "1000m", "0.001k"} {
+		quantity, err := ParseQuantity(VCORE, value)
+		assert.NilError(t, err, "parsing '%s' failed", value)
+		assert.Equal(t, quantity, Quantity(1), "unexpected vcore quantity for '%s'", value)
+	}
+}
+
+func TestNewResourceFromConfUnits(t *testing.T) {
+	res, err := NewResourceFromConf(map[string]string{MEMORY: "2Gi", VCORE: "1.5k"})
+	assert.NilError(t, err, "unexpected error")
+	assert.Assert(t, Equals(res, NewResourceFromMap(map[string]Quantity{MEMORY: 2 * 1024 * 1024 * 1024, VCORE: 1500})), "unexpected resource: %v", res)
+	_, err = NewResourceFromConf(map[string]string{MEMORY: "-1Gi"})
+	assert.ErrorContains(t, err, "negative resources not permitted", "negative quantity with a unit should fail")
+
+	parent := NewResourceFromMap(map[string]Quantity{MEMORY: 4 * 1024 * 1024 * 1024, VCORE: 4000})
+	res, err = NewResourceFromConfWithParent(map[string]string{MEMORY: "parent - 1Gi", VCORE: "0.5k"}, parent)
+	assert.NilError(t, err, "unexpected error")
+	assert.Assert(t, Equals(res, NewResourceFromMap(map[string]Quantity{MEMORY: 3 * 1024 * 1024 * 1024, VCORE: 500})), "unexpected resource: %v", res)
+}
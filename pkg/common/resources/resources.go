@@ -25,6 +25,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync/atomic"
 
 	"go.uber.org/zap"
 
@@ -87,14 +88,14 @@ func NewResourceFromString(str string) (*Resource, error) {
 func NewResourceFromConf(configMap map[string]string) (*Resource, error) {
 	res := NewResource()
 	for key, strVal := range configMap {
-		intValue, err := strconv.ParseInt(strVal, 10, 64)
+		quantity, err := ParseQuantity(key, strVal)
 		if err != nil {
 			return nil, err
 		}
-		if intValue < 0 {
+		if quantity < 0 {
 			return nil, fmt.Errorf("negative resources not permitted: %v", configMap)
 		}
-		res.Resources[key] = Quantity(intValue)
+		res.Resources[key] = quantity
 	}
 	return res, nil
 }
@@ -150,7 +151,7 @@ func (r *Resource) AddTo(add *Resource) {
 			return
 		}
 		for k, v := range add.Resources {
-			r.Resources[k] = addVal(r.Resources[k], v)
+			r.Resources[k] = addVal(k, r.Resources[k], v)
 		}
 	}
 }
@@ -165,7 +166,7 @@ func (r *Resource) SubFrom(sub *Resource) {
 			return
 		}
 		for k, v := range sub.Resources {
-			r.Resources[k] = subVal(r.Resources[k], v)
+			r.Resources[k] = subVal(k, r.Resources[k], v)
 		}
 	}
 }
@@ -175,7 +176,7 @@ func (r *Resource) SubFrom(sub *Resource) {
 func (r *Resource) MultiplyTo(ratio float64) {
 	if r != nil {
 		for k, v := range r.Resources {
-			r.Resources[k] = mulValRatio(v, ratio)
+			r.Resources[k] = mulValRatio(k, v, ratio)
 		}
 	}
 }
@@ -221,38 +222,99 @@ func (r *Resource) FitInScore(fit *Resource) float64 {
 	return score
 }
 
+// Operations reported to the overflow observer.
+const (
+	OperationAdd      = "add"
+	OperationSubtract = "subtract"
+	OperationMultiply = "multiply"
+)
+
+// Called for each calculation on a quantity that overflowed, with the resource type and the operation.
+type OverflowObserver func(resourceType, operation string)
+
+var overflowObserver atomic.Value
+
+// Set the observer that is called when a calculation on a quantity overflows, replaces the current observer.
+func SetOverflowObserver(observer OverflowObserver) {
+	overflowObserver.Store(observer)
+}
+
+// Log the overflow of the calculation for the resource type and notify the observer.
+func reportOverflow(resourceType, operation string, fields ...zap.Field) {
+	fields = append([]zap.Field{zap.String("resource", resourceType), zap.String("operation", operation)}, fields...)
+	log.Logger().Warn("Resource calculation overflowed: returned limit value", fields...)
+	if observer, ok := overflowObserver.Load().(OverflowObserver); ok && observer != nil {
+		observer(resourceType, operation)
+	}
+}
+
 // Wrapping safe calculators for the quantities of resources.
-// They will always return a valid int64. Logging if the calculator wrapped the value.
-// Returning the appropriate MaxInt64 or MinInt64 value.
-func addVal(valA, valB Quantity) Quantity {
+// They will always return a valid int64, returning the appropriate MaxInt64 or MinInt64 value if the calculation
+// overflowed. The overflow is logged with the resource type and reported to the overflow observer.
+func addVal(resourceType string, valA, valB Quantity) Quantity {
+	result, ok := checkedAdd(valA, valB)
+	if !ok {
+		reportOverflow(resourceType, OperationAdd,
+			zap.Int64("valueA", int64(valA)),
+			zap.Int64("valueB", int64(valB)),
+			zap.Int64("result", int64(result)))
+	}
+	return result
+}
+
+func subVal(resourceType string, valA, valB Quantity) Quantity {
+	result, ok := checkedSub(valA, valB)
+	if !ok {
+		reportOverflow(resourceType, OperationSubtract,
+			zap.Int64("valueA", int64(valA)),
+			zap.Int64("valueB", int64(valB)),
+			zap.Int64("result", int64(result)))
+	}
+	return result
+}
+
+func mulVal(resourceType string, valA, valB Quantity) Quantity {
+	result, ok := checkedMul(valA, valB)
+	if !ok {
+		reportOverflow(resourceType, OperationMultiply,
+			zap.Int64("valueA", int64(valA)),
+			zap.Int64("valueB", int64(valB)),
+			zap.Int64("result", int64(result)))
+	}
+	return result
+}
+
+// Add the quantities, returns false and the limit value if the result does not fit.
+func checkedAdd(valA, valB Quantity) (Quantity, bool) {
 	result := valA + valB
 	// check if the sign wrapped
 	if (result < valA) != (valB < 0) {
 		if valA < 0 {
-			// return the minimum possible
-			log.Logger().Warn("Resource calculation wrapped: returned minimum value possible",
-				zap.Int64("valueA", int64(valA)),
-				zap.Int64("valueB", int64(valB)))
-			return math.MinInt64
-		}
-		// return the maximum possible
-		log.Logger().Warn("Resource calculation wrapped: returned maximum value possible",
-			zap.Int64("valueA", int64(valA)),
-			zap.Int64("valueB", int64(valB)))
-		return math.MaxInt64
+			return math.MinInt64, false
+		}
+		return math.MaxInt64, false
 	}
-	// not wrapped normal case
-	return result
+	return result, true
 }
 
-func subVal(valA, valB Quantity) Quantity {
-	return addVal(valA, -valB)
+// Subtract the quantities, returns false and the limit value if the result does not fit.
+func checkedSub(valA, valB Quantity) (Quantity, bool) {
+	result := valA - valB
+	// check if the sign wrapped: negating MinInt64 wraps so it cannot be added as a negative value
+	if (result > valA) != (valB < 0) {
+		if valA < 0 {
+			return math.MinInt64, false
+		}
+		return math.MaxInt64, false
+	}
+	return result, true
 }
 
-func mulVal(valA, valB Quantity) Quantity {
+// Multiply the quantities, returns false and the limit value if the result does not fit.
+func checkedMul(valA, valB Quantity) (Quantity, bool) {
 	// optimise the zero cases (often hit with zero resource)
 	if valA == 0 || valB == 0 {
-		return 0
+		return 0, true
 	}
 	result := valA * valB
 	// check the wrapping
@@ -260,40 +322,33 @@ func mulVal(valA, valB Quantity) Quantity {
 	// wrapping if not specially checked
 	if (result/valB != valA) || (valA == math.MinInt64 && valB == -1) {
 		if (valA < 0) != (valB < 0) {
-			// return the minimum possible
-			log.Logger().Warn("Resource calculation wrapped: returned minimum value possible",
-				zap.Int64("valueA", int64(valA)),
-				zap.Int64("valueB", int64(valB)))
-			return math.MinInt64
-		}
-		// return the maximum possible
-		log.Logger().Warn("Resource calculation wrapped: returned maximum value possible",
-			zap.Int64("valueA", int64(valA)),
-			zap.Int64("valueB", int64(valB)))
-		return math.MaxInt64
+			return math.MinInt64, false
+		}
+		return math.MaxInt64, false
 	}
-	// not wrapped normal case
-	return result
+	return result, true
 }
 
-func mulValRatio(value Quantity, ratio float64) Quantity {
+func mulValRatio(resourceType string, value Quantity, ratio float64) Quantity {
 	// optimise the zero cases (often hit with zero resource)
 	if value == 0 || ratio == 0 {
 		return 0
 	}
 	result := float64(value) * ratio
 	// protect against positive integer overflow
-	if result > math.MaxInt64 {
-		log.Logger().Warn("Multiplication result positive overflow",
-			zap.Float64("value", float64(value)),
-			zap.Float64("ratio", ratio))
+	if result >= math.MaxInt64 {
+		reportOverflow(resourceType, OperationMultiply,
+			zap.Int64("value", int64(value)),
+			zap.Float64("ratio", ratio),
+			zap.Int64("result", math.MaxInt64))
 		return math.MaxInt64
 	}
 	// protect against negative integer overflow
 	if result < math.MinInt64 {
-		log.Logger().Warn("Multiplication result negative overflow",
-			zap.Float64("value", float64(value)),
-			zap.Float64("ratio", ratio))
+		reportOverflow(resourceType, OperationMultiply,
+			zap.Int64("value", int64(value)),
+			zap.Float64("ratio", ratio),
+			zap.Int64("result", math.MinInt64))
 		return math.MinInt64
 	}
 	// not wrapped normal case
@@ -321,7 +376,7 @@ func Add(left, right *Resource) *Resource {
 	// neither are nil, clone one and add the other
 	out := left.Clone()
 	for k, v := range right.Resources {
-		out.Resources[k] = addVal(out.Resources[k], v)
+		out.Resources[k] = addVal(k, out.Resources[k], v)
 	}
 	return out
 }
@@ -341,7 +396,7 @@ func Sub(left, right *Resource) *Resource {
 	// neither are nil, clone one and sub the other
 	out := left.Clone()
 	for k, v := range right.Resources {
-		out.Resources[k] = subVal(out.Resources[k], v)
+		out.Resources[k] = subVal(k, out.Resources[k], v)
 	}
 	return out
 }
@@ -382,7 +437,7 @@ func subNonNegative(left, right *Resource) (*Resource, string) {
 	// neither are nil, clone one and sub the other
 	out := left.Clone()
 	for k, v := range right.Resources {
-		out.Resources[k] = subVal(out.Resources[k], v)
+		out.Resources[k] = subVal(k, out.Resources[k], v)
 		// make sure value is not negative
 		if out.Resources[k] < 0 {
 			if message == "" {
@@ -510,6 +565,31 @@ func CompUsageRatioSeparately(left, leftTotal, right, rightTotal *Resource) int
 	return compareShares(lshares, rshares)
 }
 
+// Calculate share for left of total and right of total separately, each share is divided by its weight.
+// A higher weight thus lowers the share which entitles the resource to a larger part of the total.
+// A weight that is not positive is ignored and handled as a weight of 1.
+// This returns the same value as compareShares does:
+// 0 for equal shares
+// 1 if the left share is larger
+// -1 if the right share is larger
+func CompWeightedUsageRatioSeparately(left, leftTotal *Resource, leftWeight float64, right, rightTotal *Resource, rightWeight float64) int {
+	lshares := weighShares(getShares(left, leftTotal), leftWeight)
+	rshares := weighShares(getShares(right, rightTotal), rightWeight)
+
+	return compareShares(lshares, rshares)
+}
+
+// Divide all shares by the weight, the order of the shares does not change.
+func weighShares(shares []float64, weight float64) []float64 {
+	if weight <= 0 || weight == 1 {
+		return shares
+	}
+	for i := range shares {
+		shares[i] /= weight
+	}
+	return shares
+}
+
 // Compare two resources usage shares and assumes a nil total resource.
 // The share is thus equivalent to the usage passed in.
 // This returns the same value as compareShares does:
@@ -655,7 +735,7 @@ func Multiply(base *Resource, ratio int64) *Resource {
 	}
 	qRatio := Quantity(ratio)
 	for k, v := range base.Resources {
-		ret.Resources[k] = mulVal(v, qRatio)
+		ret.Resources[k] = mulVal(k, v, qRatio)
 	}
 	return ret
 }
@@ -670,7 +750,7 @@ func MultiplyBy(base *Resource, ratio float64) *Resource {
 		return ret
 	}
 	for k, v := range base.Resources {
-		ret.Resources[k] = mulValRatio(v, ratio)
+		ret.Resources[k] = mulValRatio(k, v, ratio)
 	}
 	return ret
 }
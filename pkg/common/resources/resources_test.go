@@ -703,63 +703,93 @@ func TestMultiplyTo(t *testing.T) {
 
 func TestWrapSafe(t *testing.T) {
 	// additions and subtract use the same code
-	if addVal(math.MaxInt64, 1) != math.MaxInt64 {
+	if addVal("test", math.MaxInt64, 1) != math.MaxInt64 {
 		t.Error("MaxInt64 + 1 != MaxInt64")
 	}
-	if addVal(math.MinInt64, -1) != math.MinInt64 {
+	if addVal("test", math.MinInt64, -1) != math.MinInt64 {
 		t.Error("MinInt64 + (-1) != MinInt64")
 	}
-	if addVal(10, 10) != 20 {
+	if addVal("test", 10, 10) != 20 {
 		t.Error("10 + 10 != 20")
 	}
-	if addVal(10, -20) != -10 {
+	if addVal("test", 10, -20) != -10 {
 		t.Error("10 + (-20) != -10")
 	}
-	if addVal(-10, 20) != 10 {
+	if addVal("test", -10, 20) != 10 {
 		t.Error("-10 + 20 != 10")
 	}
-	if addVal(-20, 10) != -10 {
+	if addVal("test", -20, 10) != -10 {
 		t.Error("-20 + 10 != -10")
 	}
-	if addVal(20, -10) != 10 {
+	if addVal("test", 20, -10) != 10 {
 		t.Error("20 + (-10) != 10")
 	}
 	// subtract special cases
-	if subVal(math.MinInt64, 1) != math.MinInt64 {
+	if subVal("test", math.MinInt64, 1) != math.MinInt64 {
 		t.Error("MinInt64 - 1 != MinInt64")
 	}
-	if subVal(math.MaxInt64, -1) != math.MaxInt64 {
+	if subVal("test", math.MaxInt64, -1) != math.MaxInt64 {
 		t.Error("MaxInt64 - (-1) != MaxInt64")
 	}
 
 	// multiplications
-	if mulVal(0, 0) != 0 {
+	if mulVal("test", 0, 0) != 0 {
 		t.Error("0 * 0 != 0")
 	}
-	if mulVal(math.MaxInt64, -1) != math.MinInt64+1 {
+	if mulVal("test", math.MaxInt64, -1) != math.MinInt64+1 {
 		t.Error("MaxInt64 * -1 != MinInt64 + 1")
 	}
-	if mulVal(math.MinInt64, -1) != math.MaxInt64 {
+	if mulVal("test", math.MinInt64, -1) != math.MaxInt64 {
 		t.Error("MinInt64 * -1 != MaxInt64")
 	}
-	if mulVal(100000000000000000, -2000) != math.MinInt64 {
+	if mulVal("test", 100000000000000000, -2000) != math.MinInt64 {
 		t.Error("100000000000000000 * -2000 != MinInt64")
 	}
 	// strange one this returns -4 without checks
-	if mulVal(math.MaxInt64/2, 4) != math.MaxInt64 {
+	if mulVal("test", math.MaxInt64/2, 4) != math.MaxInt64 {
 		t.Error("math.MaxInt64/2 * 4 != MaxInt64")
 	}
 
 	// large base
-	if mulValRatio(math.MaxInt64, 2) != math.MaxInt64 {
+	if mulValRatio("test", math.MaxInt64, 2) != math.MaxInt64 {
 		t.Error("float MaxInt64 * 2 != MaxInt64")
 	}
 	// small base
-	if mulValRatio(math.MinInt64, 2) != math.MinInt64 {
+	if mulValRatio("test", math.MinInt64, 2) != math.MinInt64 {
 		t.Error("float MinInt64 * 2 != MinInt64")
 	}
 }
 
+func TestOverflowObserver(t *testing.T) {
+	overflows := make(map[string]int)
+	SetOverflowObserver(func(resourceType, operation string) {
+		overflows[resourceType+"/"+operation]++
+	})
+	defer SetOverflowObserver(nil)
+
+	// negating MinInt64 wraps: the subtraction must be checked directly
+	if subVal("test", 0, math.MinInt64) != math.MaxInt64 {
+		t.Error("0 - MinInt64 != MaxInt64")
+	}
+	if subVal("test", -1, math.MinInt64) != math.MaxInt64 {
+		t.Error("-1 - MinInt64 != MaxInt64")
+	}
+	assert.Equal(t, len(overflows), 1, "unexpected overflows reported: %v", overflows)
+	assert.Equal(t, overflows["test/subtract"], 1, "subtract overflow not reported")
+
+	res := NewResourceFromMap(map[string]Quantity{"first": math.MaxInt64, "second": 1})
+	res.AddTo(NewResourceFromMap(map[string]Quantity{"first": 1, "second": 1}))
+	assert.Assert(t, Equals(res, NewResourceFromMap(map[string]Quantity{"first": math.MaxInt64, "second": 2})), "unexpected result: %v", res)
+	res = Sub(NewResourceFromMap(map[string]Quantity{"second": math.MinInt64}), NewResourceFromMap(map[string]Quantity{"second": 1}))
+	assert.Equal(t, res.Resources["second"], Quantity(math.MinInt64), "unexpected result: %v", res)
+	Multiply(NewResourceFromMap(map[string]Quantity{"third": math.MaxInt64}), 2)
+	MultiplyBy(NewResourceFromMap(map[string]Quantity{"third": math.MaxInt64}), 1.5)
+	assert.Equal(t, overflows["first/add"], 1, "add overflow not reported: %v", overflows)
+	assert.Equal(t, overflows["second/subtract"], 1, "subtract overflow not reported: %v", overflows)
+	assert.Equal(t, overflows["third/multiply"], 2, "multiply overflow not reported: %v", overflows)
+	assert.Equal(t, len(overflows), 4, "unexpected overflows reported: %v", overflows)
+}
+
 func TestAdd(t *testing.T) {
 	// simple case (nil checks)
 	result := Add(nil, nil)
@@ -1325,6 +1355,32 @@ func TestCompUsage(t *testing.T) {
 	}
 }
 
+func TestCompWeightedUsage(t *testing.T) {
+	left := &Resource{Resources: map[string]Quantity{"first": 50, "second": 20}}
+	right := &Resource{Resources: map[string]Quantity{"first": 30, "second": 30}}
+	total := &Resource{Resources: map[string]Quantity{"first": 100, "second": 100}}
+	tests := []struct {
+		name        string
+		leftWeight  float64
+		rightWeight float64
+		expected    int
+	}{
+		{"equal weights", 1, 1, 1},
+		{"unset weights", 0, -1, 1},
+		{"left double weight", 2, 1, -1},
+		{"right double weight", 1, 2, 1},
+		{"right half weight", 1, 0.5, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			comp := CompWeightedUsageRatioSeparately(left, total, tt.leftWeight, right, total, tt.rightWeight)
+			assert.Equal(t, comp, tt.expected, "unexpected weighted comparison")
+		})
+	}
+	// weights must not change the outcome for empty resources
+	assert.Equal(t, CompWeightedUsageRatioSeparately(nil, total, 2, nil, total, 1), 0, "empty resources should be equal")
+}
+
 func TestFitInScoreNil(t *testing.T) {
 	// make sure we're nil safe IDE will complain about the non nil check
 	defer func() {
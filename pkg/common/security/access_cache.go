@@ -0,0 +1,99 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package security
+
+import (
+	"sync"
+	"time"
+)
+
+// Maximum number of entries in the access cache, the cache is cleaned when it is full
+const maxAccessCacheEntries = 10000
+
+// Cache for the results of access checks.
+// An entry expires after the time to live. The cache must be cleared by the owner when the ACLs change.
+// A nil cache is allowed and never returns a cached result.
+type AccessCache struct {
+	ttl     time.Duration
+	entries map[string]accessEntry
+
+	sync.RWMutex
+}
+
+type accessEntry struct {
+	allowed bool
+	expires time.Time
+}
+
+// Create a new access cache with the time to live for the entries.
+func NewAccessCache(ttl time.Duration) *AccessCache {
+	return &AccessCache{
+		ttl:     ttl,
+		entries: make(map[string]accessEntry),
+	}
+}
+
+// Return the cached result for the key, the second value is false if the result is not cached or has expired.
+func (ac *AccessCache) Get(key string) (bool, bool) {
+	if ac == nil {
+		return false, false
+	}
+	ac.RLock()
+	defer ac.RUnlock()
+	entry, ok := ac.entries[key]
+	if !ok || time.Now().After(entry.expires) {
+		return false, false
+	}
+	return entry.allowed, true
+}
+
+// Cache the result for the key. If the cache is full the expired entries are removed first, if that does not free
+// up space the cache is cleared.
+func (ac *AccessCache) Set(key string, allowed bool) {
+	if ac == nil {
+		return
+	}
+	ac.Lock()
+	defer ac.Unlock()
+	now := time.Now()
+	if len(ac.entries) >= maxAccessCacheEntries {
+		for k, entry := range ac.entries {
+			if now.After(entry.expires) {
+				delete(ac.entries, k)
+			}
+		}
+		if len(ac.entries) >= maxAccessCacheEntries {
+			ac.entries = make(map[string]accessEntry)
+		}
+	}
+	ac.entries[key] = accessEntry{
+		allowed: allowed,
+		expires: now.Add(ac.ttl),
+	}
+}
+
+// Remove all cached results.
+func (ac *AccessCache) Clear() {
+	if ac == nil {
+		return
+	}
+	ac.Lock()
+	defer ac.Unlock()
+	ac.entries = make(map[string]accessEntry)
+}
@@ -0,0 +1,62 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package security
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+func TestAccessCache(t *testing.T) {
+	var nilCache *AccessCache
+	nilCache.Set("key", true)
+	_, ok := nilCache.Get("key")
+	assert.Assert(t, !ok, "nil cache should not return a result")
+	nilCache.Clear()
+
+	cache := NewAccessCache(time.Minute)
+	_, ok = cache.Get("key")
+	assert.Assert(t, !ok, "empty cache should not return a result")
+	cache.Set("key", true)
+	cache.Set("other", false)
+	var allowed bool
+	allowed, ok = cache.Get("key")
+	assert.Assert(t, ok && allowed, "cached allow not returned")
+	allowed, ok = cache.Get("other")
+	assert.Assert(t, ok && !allowed, "cached deny not returned")
+	cache.Clear()
+	_, ok = cache.Get("key")
+	assert.Assert(t, !ok, "cleared cache should not return a result")
+
+	// expired entries are not returned
+	cache = NewAccessCache(-time.Second)
+	cache.Set("key", true)
+	_, ok = cache.Get("key")
+	assert.Assert(t, !ok, "expired entry should not be returned")
+
+	// full cache removes expired entries
+	for i := 0; i < maxAccessCacheEntries; i++ {
+		cache.entries[strconv.Itoa(i)] = accessEntry{allowed: true, expires: time.Now().Add(-time.Second)}
+	}
+	cache.Set("key", true)
+	assert.Equal(t, len(cache.entries), 1, "expired entries should have been removed")
+}
@@ -39,10 +39,37 @@ const (
 var userNameRegExp = regexp.MustCompile("^[_a-zA-Z][a-zA-Z0-9_.@-]*[$]?$")
 var groupRegExp = regexp.MustCompile("^[_a-zA-Z][a-zA-Z0-9_-]*$")
 
+// Maximum depth of the group hierarchy that is followed when expanding the groups of a user
+const maxGroupDepth = 10
+
 type ACL struct {
-	users      map[string]bool
-	groups     map[string]bool
-	allAllowed bool
+	users         map[string]bool
+	groups        map[string]bool
+	userPatterns  []aclPattern
+	groupPatterns []aclPattern
+	allAllowed    bool
+}
+
+// ACL entry with a wildcard and the expression it was converted into
+type aclPattern struct {
+	entry string
+	exp   *regexp.Regexp
+}
+
+// Returns the parent groups of a group, used to expand the groups of a user for nested group support.
+type GroupHierarchy func(group string) []string
+
+// Convert an ACL entry that contains a wildcard into a regular expression, the wildcard matches any characters.
+// Returns nil if the entry with the wildcards replaced is not a valid name.
+func wildcardRegExp(entry string, nameRegExp *regexp.Regexp) *regexp.Regexp {
+	if !nameRegExp.MatchString(strings.Replace(entry, WildCard, "x", -1)) {
+		return nil
+	}
+	parts := strings.Split(entry, WildCard)
+	for i := range parts {
+		parts[i] = regexp.QuoteMeta(parts[i])
+	}
+	return regexp.MustCompile("^" + strings.Join(parts, ".*") + "$")
 }
 
 // the ACL allows all access, set the flag
@@ -54,6 +81,7 @@ func (a *ACL) setAllAllowed(part string) {
 // set the user list in the ACL, invalid user names are ignored
 func (a *ACL) setUsers(userList []string) {
 	a.users = make(map[string]bool)
+	a.userPatterns = nil
 	// list could be empty
 	if len(userList) == 0 {
 		return
@@ -73,6 +101,8 @@ func (a *ACL) setUsers(userList []string) {
 		// check the users validity
 		if userNameRegExp.MatchString(user) {
 			a.users[user] = true
+		} else if pattern := a.wildcardEntry(user, userNameRegExp); pattern != nil {
+			a.userPatterns = append(a.userPatterns, aclPattern{entry: user, exp: pattern})
 		} else {
 			log.Logger().Info("ignoring user in ACL definition",
 				zap.String("user", user))
@@ -83,6 +113,7 @@ func (a *ACL) setUsers(userList []string) {
 // set the group list in the ACL, invalid group names are ignored
 func (a *ACL) setGroups(groupList []string) {
 	a.groups = make(map[string]bool)
+	a.groupPatterns = nil
 	// list could be empty
 	if len(groupList) == 0 {
 		return
@@ -95,6 +126,7 @@ func (a *ACL) setGroups(groupList []string) {
 	if len(groupList) == 1 && groupList[0] == WildCard {
 		log.Logger().Info("group list is wildcard, allowing all access")
 		a.users = make(map[string]bool)
+		a.userPatterns = nil
 		a.allAllowed = true
 		return
 	}
@@ -107,6 +139,8 @@ func (a *ACL) setGroups(groupList []string) {
 		// check the group validity
 		if groupRegExp.MatchString(group) {
 			a.groups[group] = true
+		} else if pattern := a.wildcardEntry(group, groupRegExp); pattern != nil {
+			a.groupPatterns = append(a.groupPatterns, aclPattern{entry: group, exp: pattern})
 		} else {
 			log.Logger().Info("ignoring group in ACL",
 				zap.String("group", group))
@@ -114,6 +148,14 @@ func (a *ACL) setGroups(groupList []string) {
 	}
 }
 
+// return the expression for an entry with a wildcard, nil if the entry has no wildcard or is not valid
+func (a *ACL) wildcardEntry(entry string, nameRegExp *regexp.Regexp) *regexp.Regexp {
+	if !strings.Contains(entry, WildCard) {
+		return nil
+	}
+	return wildcardRegExp(entry, nameRegExp)
+}
+
 // create a new ACL from scratch
 func NewACL(aclStr string) (ACL, error) {
 	acl := ACL{}
@@ -142,20 +184,68 @@ func NewACL(aclStr string) (ACL, error) {
 
 // Check if the user has access
 func (a ACL) CheckAccess(userObj UserGroup) bool {
+	return a.GetMatch(userObj) != ""
+}
+
+// Return the ACL entry that gives the user access, an empty string means no access.
+// Users are matched before groups, the entry is prefixed with the type: "user:" or "group:".
+// The wildcard ACL returns just the wildcard.
+func (a ACL) GetMatch(userObj UserGroup) string {
 	// shortcut allow all
 	if a.allAllowed {
-		return true
+		return WildCard
 	}
 	// if the ACL is not the wildcard we have non nil lists
 	// check user access
 	if a.users[userObj.User] {
-		return true
+		return "user:" + userObj.User
+	}
+	for _, pattern := range a.userPatterns {
+		if pattern.exp.MatchString(userObj.User) {
+			return "user:" + pattern.entry
+		}
 	}
 	// get groups for the user and check them
 	for _, group := range userObj.Groups {
 		if a.groups[group] {
-			return true
+			return "group:" + group
+		}
+		for _, pattern := range a.groupPatterns {
+			if pattern.exp.MatchString(group) {
+				return "group:" + pattern.entry
+			}
+		}
+	}
+	return ""
+}
+
+// Expand the groups with all the parent groups from the hierarchy, the original groups are kept first and in order.
+// Each group is only added once and the hierarchy is followed up to a maximum depth to guard against loops.
+func ExpandGroups(groups []string, parents GroupHierarchy) []string {
+	if parents == nil || len(groups) == 0 {
+		return groups
+	}
+	seen := make(map[string]bool, len(groups))
+	expanded := make([]string, 0, len(groups))
+	for _, group := range groups {
+		if !seen[group] {
+			seen[group] = true
+			expanded = append(expanded, group)
+		}
+	}
+	current := expanded
+	for depth := 0; depth < maxGroupDepth && len(current) > 0; depth++ {
+		var next []string
+		for _, group := range current {
+			for _, parent := range parents(group) {
+				if parent != "" && !seen[parent] {
+					seen[parent] = true
+					next = append(next, parent)
+				}
+			}
 		}
+		expanded = append(expanded, next...)
+		current = next
 	}
-	return false
+	return expanded
 }
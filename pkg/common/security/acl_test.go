@@ -135,3 +135,75 @@ func TestACLAccess(t *testing.T) {
 	user = UserGroup{User: "user1", Groups: []string{"group1"}}
 	assert.Assert(t, !acl.CheckAccess(user), "user1/group1, empty ACL always deny")
 }
+
+func TestACLWildcardAccess(t *testing.T) {
+	acl, err := NewACL("team-*,*@example.com dev-*")
+	assert.NilError(t, err, "parsing failed for wildcard ACL")
+	assert.Equal(t, len(acl.userPatterns), 2, "user patterns not parsed")
+	assert.Equal(t, len(acl.groupPatterns), 1, "group patterns not parsed")
+	tests := []struct {
+		name    string
+		user    UserGroup
+		allowed bool
+	}{
+		{"user prefix", UserGroup{User: "team-a"}, true},
+		{"user suffix", UserGroup{User: "bob@example.com"}, true},
+		{"user no match", UserGroup{User: "teama"}, false},
+		{"user partial match", UserGroup{User: "bob@example.com.org"}, false},
+		{"group prefix", UserGroup{User: "bob", Groups: []string{"other", "dev-ops"}}, true},
+		{"group no match", UserGroup{User: "bob", Groups: []string{"devops"}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, acl.CheckAccess(tt.user), tt.allowed, "unexpected access result")
+		})
+	}
+	// invalid wildcard entries are ignored
+	acl, err = NewACL("te:am*,user1 gr.oup*")
+	assert.NilError(t, err, "parsing failed for invalid wildcard ACL")
+	assert.Equal(t, len(acl.userPatterns)+len(acl.groupPatterns), 0, "invalid patterns should have been ignored")
+	assert.Equal(t, len(acl.users), 1, "valid user should have been kept")
+}
+
+func TestExpandGroups(t *testing.T) {
+	hierarchy := map[string][]string{
+		"dev":      {"eng"},
+		"eng":      {"all", "dev"},
+		"platform": {"eng"},
+	}
+	parents := func(group string) []string {
+		return hierarchy[group]
+	}
+	assert.DeepEqual(t, ExpandGroups([]string{"dev"}, nil), []string{"dev"})
+	assert.DeepEqual(t, ExpandGroups([]string{"dev", "platform", "dev"}, parents), []string{"dev", "platform", "eng", "all"})
+	assert.DeepEqual(t, ExpandGroups([]string{"other"}, parents), []string{"other"})
+	// nested groups give access
+	acl, err := NewACL(" all")
+	assert.NilError(t, err, "parsing failed for group ACL")
+	user := UserGroup{User: "bob", Groups: []string{"dev"}}
+	assert.Assert(t, !acl.CheckAccess(user), "user without the nested group should not have access")
+	user.Groups = ExpandGroups(user.Groups, parents)
+	assert.Assert(t, acl.CheckAccess(user), "user with the nested group should have access")
+}
+
+func TestGetMatch(t *testing.T) {
+	acl, err := NewACL("*")
+	assert.NilError(t, err, "parsing failed for wildcard ACL")
+	assert.Equal(t, acl.GetMatch(UserGroup{User: "any"}), WildCard, "wildcard ACL should match on the wildcard")
+
+	acl, err = NewACL("john,dev* admins,te*")
+	assert.NilError(t, err, "parsing failed for ACL")
+	var tests = []struct {
+		user  UserGroup
+		match string
+	}{
+		{UserGroup{User: "john"}, "user:john"},
+		{UserGroup{User: "devuser", Groups: []string{"admins"}}, "user:dev*"},
+		{UserGroup{User: "jane", Groups: []string{"other", "admins"}}, "group:admins"},
+		{UserGroup{User: "jane", Groups: []string{"testers"}}, "group:te*"},
+		{UserGroup{User: "jane", Groups: []string{"other"}}, ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, acl.GetMatch(tt.user), tt.match, "unexpected match for user %v", tt.user)
+	}
+}
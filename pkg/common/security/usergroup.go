@@ -58,8 +58,16 @@ type UserGroup struct {
 	Groups   []string
 	failed   bool
 	resolved int64
+	// groups resolved by an external resolver, cached separately from the groups above
+	resolvedGroups []string
+	groupsFailed   bool
+	groupsResolved int64
 }
 
+// Resolves the groups for a user outside of the cache (i.e. a plugin provided by the shim).
+// The first group returned is the primary group.
+type GroupResolver func(userName string) ([]string, error)
+
 // Get the resolver for the user and group info.
 // Current setup allows three resolvers:
 // * NO resolver: default, no user or group resolution just return the info (k8s use case)
@@ -137,6 +145,12 @@ func (c *UserGroupCache) ConvertUGI(ugi *si.UserGroupInformation) (UserGroup, er
 	newUG.resolved = now.Unix()
 	c.lock.Lock()
 	defer c.lock.Unlock()
+	// keep the groups from an external resolver: they are not part of the UGI
+	if ug, ok := c.ugs[ugi.User]; ok {
+		newUG.resolvedGroups = ug.resolvedGroups
+		newUG.groupsFailed = ug.groupsFailed
+		newUG.groupsResolved = ug.groupsResolved
+	}
 	c.ugs[ugi.User] = &newUG
 	return newUG, nil
 }
@@ -198,6 +212,48 @@ func (c *UserGroupCache) GetUserGroup(userName string) (UserGroup, error) {
 	return *ug, err
 }
 
+// Get the groups for the user using the external resolver.
+// The result is cached, negatively and positively, in the entry of the user. If the user is not in the cache the
+// result is not cached.
+func (c *UserGroupCache) ResolveGroups(userName string, resolver GroupResolver) ([]string, error) {
+	// check if we have a user to resolve
+	if userName == "" {
+		return nil, fmt.Errorf("empty user cannot resolve")
+	}
+	current := time.Now().Unix()
+	// look in the cache before resolving
+	c.lock.RLock()
+	ug, ok := c.ugs[userName]
+	if ok && ug.groupsResolved != 0 {
+		if !ug.groupsFailed && current-ug.groupsResolved < poscache {
+			groups := ug.resolvedGroups
+			c.lock.RUnlock()
+			return groups, nil
+		}
+		if ug.groupsFailed && current-ug.groupsResolved < negcache {
+			resolved := ug.groupsResolved
+			c.lock.RUnlock()
+			return nil, fmt.Errorf("group resolution failed, cached failure returned: %v", time.Unix(resolved, 0))
+		}
+	}
+	c.lock.RUnlock()
+	groups, err := resolver(userName)
+	if err != nil {
+		log.Logger().Error("Error resolving groups for user using external resolver",
+			zap.String("userName", userName),
+			zap.Error(err))
+		groups = nil
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if ug, ok = c.ugs[userName]; ok {
+		ug.resolvedGroups = groups
+		ug.groupsFailed = err != nil
+		ug.groupsResolved = current
+	}
+	return groups, err
+}
+
 // Resolve the groups for the user if the user exists
 func (ug *UserGroup) resolveGroups(osUser *user.User, c *UserGroupCache) error {
 	// resolve the primary group and add it first
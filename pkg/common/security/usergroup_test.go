@@ -19,6 +19,7 @@
 package security
 
 import (
+	"fmt"
 	"strings"
 	"testing"
 
@@ -232,3 +233,54 @@ func TestConvertUGI(t *testing.T) {
 		t.Errorf("groups not initialised correctly on convert: expected '%s' got '%s'", group, ug.Groups[0])
 	}
 }
+
+func TestResolveGroups(t *testing.T) {
+	testCache := GetUserGroupCache("test")
+	testCache.resetCache()
+	calls := 0
+	resolver := func(userName string) ([]string, error) {
+		calls++
+		if userName == "unknown" {
+			return nil, fmt.Errorf("unknown user")
+		}
+		return []string{"group-" + userName}, nil
+	}
+
+	_, err := testCache.ResolveGroups("", resolver)
+	assert.ErrorContains(t, err, "empty user")
+	// user not in the cache: resolved but not cached
+	groups, err := testCache.ResolveGroups("testuser1", resolver)
+	assert.NilError(t, err, "group resolution failed")
+	assert.DeepEqual(t, groups, []string{"group-testuser1"})
+	_, err = testCache.ResolveGroups("testuser1", resolver)
+	assert.NilError(t, err, "group resolution failed")
+	assert.Equal(t, calls, 2, "user not in the cache should not be cached")
+
+	// user in the cache: second call is cached and survives a UGI conversion
+	_, err = testCache.GetUserGroup("testuser1")
+	assert.NilError(t, err, "Lookup should not have failed: testuser1")
+	calls = 0
+	_, err = testCache.ResolveGroups("testuser1", resolver)
+	assert.NilError(t, err, "group resolution failed")
+	_, err = testCache.ConvertUGI(&si.UserGroupInformation{User: "testuser1", Groups: []string{"other"}})
+	assert.NilError(t, err, "UGI conversion failed")
+	groups, err = testCache.ResolveGroups("testuser1", resolver)
+	assert.NilError(t, err, "group resolution failed")
+	assert.DeepEqual(t, groups, []string{"group-testuser1"})
+	assert.Equal(t, calls, 1, "resolved groups should have been cached")
+	// expire the cached entry
+	testCache.ugs["testuser1"].groupsResolved -= poscache
+	_, err = testCache.ResolveGroups("testuser1", resolver)
+	assert.NilError(t, err, "group resolution failed")
+	assert.Equal(t, calls, 2, "expired groups should have been resolved")
+
+	// failures are cached negatively
+	_, err = testCache.ConvertUGI(&si.UserGroupInformation{User: "unknown", Groups: []string{"other"}})
+	assert.NilError(t, err, "UGI conversion failed")
+	calls = 0
+	_, err = testCache.ResolveGroups("unknown", resolver)
+	assert.ErrorContains(t, err, "unknown user")
+	_, err = testCache.ResolveGroups("unknown", resolver)
+	assert.ErrorContains(t, err, "cached failure")
+	assert.Equal(t, calls, 1, "failure should have been cached")
+}
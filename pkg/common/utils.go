@@ -95,6 +95,36 @@ func GetBoolEnvVar(key string, defaultVal bool) bool {
 	return defaultVal
 }
 
+func GetIntEnvVar(key string, defaultVal int) int {
+	if value, ok := os.LookupEnv(key); ok {
+		intValue, err := strconv.Atoi(value)
+		if err != nil {
+			log.Logger().Debug("Failed to parse environment variable, using default value",
+				zap.String("name", key),
+				zap.String("value", value),
+				zap.Int("default", defaultVal))
+			return defaultVal
+		}
+		return intValue
+	}
+	return defaultVal
+}
+
+func GetDurationEnvVar(key string, defaultVal time.Duration) time.Duration {
+	if value, ok := os.LookupEnv(key); ok {
+		durationValue, err := time.ParseDuration(value)
+		if err != nil || durationValue < 0 {
+			log.Logger().Debug("Failed to parse environment variable, using default value",
+				zap.String("name", key),
+				zap.String("value", value),
+				zap.Duration("default", defaultVal))
+			return defaultVal
+		}
+		return durationValue
+	}
+	return defaultVal
+}
+
 // Convert a SI execution timeout, given in milliseconds into a time.Duration object.
 // This will always return a positive value or zero (0).
 // A negative timeout will be converted into zero (0), which means never timeout.
@@ -98,6 +98,57 @@ func TestGetBoolEnvVar(t *testing.T) {
 	}
 }
 
+func TestGetDurationEnvVar(t *testing.T) {
+	envVarName := "VAR"
+	testCases := []struct {
+		name     string
+		value    string
+		expected time.Duration
+	}{
+		{"ENV var not set", "", time.Minute},
+		{"ENV var set", "10s", 10 * time.Second},
+		{"Invalid value", "someValue", time.Minute},
+		{"Negative value", "-10s", time.Minute},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.value != "" {
+				err := os.Setenv(envVarName, tc.value)
+				assert.NilError(t, err, "setting environment variable failed")
+			}
+			val := GetDurationEnvVar(envVarName, time.Minute)
+			assert.Equal(t, val, tc.expected, "test case failure: %s", tc.name)
+			err := os.Unsetenv(envVarName)
+			assert.NilError(t, err, "cleaning up environment variable failed")
+		})
+	}
+}
+
+func TestGetIntEnvVar(t *testing.T) {
+	envVarName := "VAR"
+	testCases := []struct {
+		name     string
+		value    string
+		expected int
+	}{
+		{"ENV var not set", "", 5},
+		{"ENV var set", "10", 10},
+		{"Invalid value", "someValue", 5},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.value != "" {
+				err := os.Setenv(envVarName, tc.value)
+				assert.NilError(t, err, "setting environment variable failed")
+			}
+			val := GetIntEnvVar(envVarName, 5)
+			assert.Equal(t, val, tc.expected, "test case failure: %s", tc.name)
+			err := os.Unsetenv(envVarName)
+			assert.NilError(t, err, "cleaning up environment variable failed")
+		})
+	}
+}
+
 func TestConvertSITimeout(t *testing.T) {
 	testCases := []struct {
 		name     string
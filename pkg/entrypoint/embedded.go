@@ -0,0 +1,185 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package entrypoint
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/checkpoint"
+	"github.com/apache/incubator-yunikorn-core/pkg/common"
+	"github.com/apache/incubator-yunikorn-core/pkg/log"
+	"github.com/apache/incubator-yunikorn-core/pkg/webservice/dao"
+	"github.com/apache/incubator-yunikorn-scheduler-interface/lib/go/api"
+	"github.com/apache/incubator-yunikorn-scheduler-interface/lib/go/si"
+)
+
+// Options for a scheduler core embedded in another process.
+// - ManualSchedule: scheduling cycles only run when requested via Schedule
+// - WebService: start the REST web service next to the core
+// - EventCache: collect the scheduler events and publish them to the registered RMs
+// - MetricsHistorySize: number of internal metrics samples kept, 0 disables the history
+type EmbeddedOptions struct {
+	ManualSchedule     bool
+	WebService         bool
+	EventCache         bool
+	MetricsHistorySize int
+}
+
+// A scheduler core embedded in another process. This is the stable API for resource managers that integrate with
+// the core directly instead of via a shim. All calls are safe for concurrent use. The updates are passed on
+// asynchronously, the results are communicated back via the callback registered with the RM. The query calls
+// return copies of the scheduler state: the caller never holds or needs any of the scheduler locks.
+// All calls fail after the core is stopped.
+type Embedded struct {
+	context *ServiceContext
+	stopped bool
+
+	sync.RWMutex
+}
+
+// Start the scheduler core services and return the handle to use them.
+func StartEmbedded(opts EmbeddedOptions) *Embedded {
+	log.Logger().Info("ServiceContext start embedded scheduler core")
+	return &Embedded{
+		context: startAllServicesWithParameters(
+			startupOptions{
+				manualScheduleFlag: opts.ManualSchedule,
+				startWebAppFlag:    opts.WebService,
+				metricsHistorySize: opts.MetricsHistorySize,
+				eventCacheEnabled:  opts.EventCache,
+			}),
+	}
+}
+
+// Shut the scheduler core down gracefully. Stopping a stopped core is a no-op.
+func (e *Embedded) Stop() {
+	e.Lock()
+	defer e.Unlock()
+	if e.stopped {
+		return
+	}
+	e.stopped = true
+	e.context.StopAll()
+}
+
+// Check that the core is running, returns an error after the core is stopped.
+func (e *Embedded) checkRunning() error {
+	e.RLock()
+	defer e.RUnlock()
+	if e.stopped {
+		return fmt.Errorf("embedded scheduler core is stopped")
+	}
+	return nil
+}
+
+// Register the resource manager with the core. The callback receives the results of the updates of the RM.
+func (e *Embedded) RegisterResourceManager(request *si.RegisterResourceManagerRequest, callback api.ResourceManagerCallback) (*si.RegisterResourceManagerResponse, error) {
+	if err := e.checkRunning(); err != nil {
+		return nil, err
+	}
+	return e.context.RMProxy.RegisterResourceManager(request, callback)
+}
+
+// Pass an update from a registered RM to the core. An error means the update was not accepted and must be retried.
+func (e *Embedded) Update(request *si.UpdateRequest) error {
+	if err := e.checkRunning(); err != nil {
+		return err
+	}
+	if request == nil {
+		return fmt.Errorf("update request cannot be nil")
+	}
+	return e.context.RMProxy.Update(request)
+}
+
+// Reload the scheduler configuration for the RM.
+func (e *Embedded) ReloadConfiguration(rmID string) error {
+	if err := e.checkRunning(); err != nil {
+		return err
+	}
+	return e.context.RMProxy.ReloadConfiguration(rmID)
+}
+
+// Run the number of scheduling cycles requested. Only allowed when the core was started with manual scheduling.
+func (e *Embedded) Schedule(cycles int) error {
+	if err := e.checkRunning(); err != nil {
+		return err
+	}
+	if !e.context.manualSchedule {
+		return fmt.Errorf("scheduling cycles can only be requested with manual scheduling")
+	}
+	for i := 0; i < cycles; i++ {
+		e.context.Scheduler.RunSchedulingCycle()
+	}
+	return nil
+}
+
+// Return the status of the core.
+func (e *Embedded) GetStatus() (*dao.StatusDAOInfo, error) {
+	if err := e.checkRunning(); err != nil {
+		return nil, err
+	}
+	return e.context.Scheduler.GetClusterContext().GetStatus(), nil
+}
+
+// Return the names of the partitions registered by the RM, sorted on the name.
+func (e *Embedded) GetPartitions(rmID string) ([]string, error) {
+	if err := e.checkRunning(); err != nil {
+		return nil, err
+	}
+	names := make([]string, 0)
+	for _, partition := range e.context.Scheduler.GetClusterContext().GetPartitionMapClone() {
+		if partition.RmID == rmID {
+			names = append(names, common.GetPartitionNameWithoutClusterID(partition.Name))
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Return the queue hierarchy of the partition of the RM.
+func (e *Embedded) GetQueues(rmID, partitionName string) (*dao.PartitionQueueDAOInfo, error) {
+	if err := e.checkRunning(); err != nil {
+		return nil, err
+	}
+	partition := e.context.Scheduler.GetClusterContext().GetPartition(common.GetNormalizedPartitionName(partitionName, rmID))
+	if partition == nil {
+		return nil, fmt.Errorf("partition %s not found for RM %s", partitionName, rmID)
+	}
+	queues := partition.GetPartitionQueues()
+	return &queues, nil
+}
+
+// Return the resources per RM.
+func (e *Embedded) GetRMInfos() ([]dao.RMDAOInfo, error) {
+	if err := e.checkRunning(); err != nil {
+		return nil, err
+	}
+	return e.context.Scheduler.GetClusterContext().GetRMInfos(), nil
+}
+
+// Return a snapshot of the applications and allocations in all partitions. The snapshot has the same format as the
+// scheduler state checkpoint.
+func (e *Embedded) GetStateSnapshot() (*checkpoint.Snapshot, error) {
+	if err := e.checkRunning(); err != nil {
+		return nil, err
+	}
+	return e.context.Scheduler.GetClusterContext().GetStateSnapshot(), nil
+}
@@ -19,7 +19,14 @@
 package entrypoint
 
 import (
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/checkpoint"
+	"github.com/apache/incubator-yunikorn-core/pkg/common/features"
 	"github.com/apache/incubator-yunikorn-core/pkg/events"
+	"github.com/apache/incubator-yunikorn-core/pkg/export"
 	"github.com/apache/incubator-yunikorn-core/pkg/handler"
 	"github.com/apache/incubator-yunikorn-core/pkg/log"
 	"github.com/apache/incubator-yunikorn-core/pkg/metrics"
@@ -60,7 +67,25 @@ func StartAllServicesWithManualScheduler() *ServiceContext {
 		})
 }
 
+// Start only the web service as a read-only replica of an active scheduler instance.
+// The replica serves the scheduler GET endpoints from the active instance refreshed using the interval.
+// The token is the bearer token of an admin of the active instance, empty if authentication is not enabled.
+func StartReplicaWebService(active, token string, interval time.Duration) *ServiceContext {
+	log.Logger().Info("ServiceContext start read-only web application replica",
+		zap.String("active", active))
+	webapp := webservice.NewReplicaWebApp(active, token, interval)
+	webapp.StartWebApp()
+	context := &ServiceContext{
+		WebApp: webapp,
+	}
+	registerReadinessChecks(context)
+	return context
+}
+
 func startAllServicesWithParameters(opts startupOptions) *ServiceContext {
+	// log the feature gates for supportability
+	features.LogGates()
+
 	var eventCache *events.EventCache
 	var eventPublisher events.EventPublisher
 	if opts.eventCacheEnabled {
@@ -70,9 +95,28 @@ func startAllServicesWithParameters(opts startupOptions) *ServiceContext {
 		eventPublisher = events.CreateShimPublisher(eventCache.Store)
 	}
 
+	// decision export is configured via the environment, a broken configuration does not stop the scheduler
+	if sink, err := export.CreateSinkFromEnv(); err != nil {
+		log.Logger().Warn("failed to create decision export sink, export disabled",
+			zap.Error(err))
+	} else if sink != nil {
+		log.Logger().Info("creating decision exporter")
+		export.CreateAndSetDecisionExporter(sink)
+		export.GetDecisionExporter().StartService()
+	}
+
 	sched := scheduler.NewScheduler()
 	proxy := rmproxy.NewRMProxy()
 
+	// the state store is configured via the environment, a broken configuration does not stop the scheduler
+	if store, err := checkpoint.CreateStoreFromEnv(); err != nil {
+		log.Logger().Warn("failed to create scheduler state store, checkpointing disabled",
+			zap.Error(err))
+	} else if store != nil {
+		log.Logger().Info("setting scheduler state store")
+		sched.SetStateStore(store, checkpoint.GetInterval())
+	}
+
 	eventHandler := handler.EventHandlers{
 		SchedulerEventHandler: sched,
 		RMProxyEventHandler:   proxy,
@@ -90,8 +134,12 @@ func startAllServicesWithParameters(opts startupOptions) *ServiceContext {
 	}
 
 	context := &ServiceContext{
-		RMProxy:   proxy,
-		Scheduler: sched,
+		RMProxy:        proxy,
+		Scheduler:      sched,
+		proxy:          proxy,
+		eventCache:     eventCache,
+		eventPublisher: eventPublisher,
+		manualSchedule: opts.manualScheduleFlag,
 	}
 
 	var imHistory *history.InternalMetricsHistory
@@ -99,7 +147,9 @@ func startAllServicesWithParameters(opts startupOptions) *ServiceContext {
 		log.Logger().Info("creating InternalMetricsHistory")
 		imHistory = history.NewInternalMetricsHistory(opts.metricsHistorySize)
 		metricsCollector := metrics.NewInternalMetricsCollector(imHistory)
+		metricsCollector.SetQueueUsageSource(sched.GetClusterContext().GetQueueUsage)
 		metricsCollector.StartService()
+		context.metricsCollector = metricsCollector
 	}
 
 	if opts.startWebAppFlag {
@@ -109,5 +159,6 @@ func startAllServicesWithParameters(opts startupOptions) *ServiceContext {
 		context.WebApp = webapp
 	}
 
+	registerReadinessChecks(context)
 	return context
 }
@@ -0,0 +1,68 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package entrypoint
+
+import (
+	"strings"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/metrics"
+	"github.com/apache/incubator-yunikorn-core/pkg/readiness"
+	"github.com/apache/incubator-yunikorn-core/pkg/webservice"
+)
+
+// Subsystem names used in the readiness report.
+const (
+	ReadinessScheduler     = "scheduler"
+	ReadinessConfiguration = "configuration"
+	ReadinessPlugins       = "plugins"
+	ReadinessMetrics       = "metrics"
+	ReadinessWebService    = "webservice"
+)
+
+// Register the readiness checks for the started services and log the report.
+// The report is logged once after startup, the configuration is only loaded when the first RM registers.
+func registerReadinessChecks(context *ServiceContext) {
+	readiness.Reset()
+	if context.Scheduler != nil {
+		cc := context.Scheduler.GetClusterContext()
+		readiness.Register(ReadinessScheduler, cc.GetSchedulerReadiness)
+		readiness.Register(ReadinessConfiguration, cc.GetConfigReadiness)
+		readiness.Register(ReadinessPlugins, cc.GetPluginReadiness)
+		readiness.Register(ReadinessMetrics, checkMetricsReadiness)
+	}
+	readiness.Register(ReadinessWebService, webServiceReadiness(context.WebApp))
+	readiness.LogReport()
+}
+
+// Readiness check for the metrics: all collectors created at startup must be registered.
+func checkMetricsReadiness() (string, string) {
+	if errs := metrics.GetRegistrationErrors(); len(errs) != 0 {
+		return readiness.StatusNotReady, "failed to register collectors: " + strings.Join(errs, "; ")
+	}
+	return readiness.StatusReady, ""
+}
+
+func webServiceReadiness(webapp *webservice.WebService) readiness.Check {
+	if webapp == nil {
+		return func() (string, string) {
+			return readiness.StatusDisabled, "web service not started"
+		}
+	}
+	return webapp.GetReadiness
+}
@@ -21,7 +21,10 @@ package entrypoint
 import (
 	"go.uber.org/zap"
 
+	"github.com/apache/incubator-yunikorn-core/pkg/events"
+	"github.com/apache/incubator-yunikorn-core/pkg/export"
 	"github.com/apache/incubator-yunikorn-core/pkg/log"
+	"github.com/apache/incubator-yunikorn-core/pkg/rmproxy"
 	"github.com/apache/incubator-yunikorn-core/pkg/scheduler"
 	"github.com/apache/incubator-yunikorn-core/pkg/webservice"
 	"github.com/apache/incubator-yunikorn-scheduler-interface/lib/go/api"
@@ -31,15 +34,28 @@ type ServiceContext struct {
 	RMProxy   api.SchedulerAPI
 	Scheduler *scheduler.Scheduler
 	WebApp    *webservice.WebService
+
+	// services that take part in the graceful shutdown, nil if not started
+	proxy            *rmproxy.RMProxy
+	eventCache       *events.EventCache
+	eventPublisher   events.EventPublisher
+	metricsCollector historyCollector
+	// scheduling cycles are only run on request
+	manualSchedule bool
 }
 
 func (s *ServiceContext) StopAll() {
 	log.Logger().Info("ServiceContext stop all services")
-	// TODO implement stop for services
+	// shut the scheduler down before the web service to allow the shutdown progress to be checked
+	s.shutdown()
 	if s.WebApp != nil {
 		if err := s.WebApp.StopWebApp(); err != nil {
 			log.Logger().Error("failed to stop web-app",
 				zap.Error(err))
 		}
 	}
+	// flush the exported decisions that are still queued
+	if exporter := export.GetDecisionExporter(); exporter != nil {
+		exporter.Stop()
+	}
 }
@@ -0,0 +1,105 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package entrypoint
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common"
+	"github.com/apache/incubator-yunikorn-core/pkg/log"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler"
+)
+
+// Environment variable that sets the maximum time each shutdown phase waits before moving on to the next phase.
+const EnvShutdownPhaseTimeout = "SHUTDOWN_PHASE_TIMEOUT"
+
+const defaultShutdownPhaseTimeout = 10 * time.Second
+
+// A service that adds the current state to the history on a tick or on demand.
+type historyCollector interface {
+	Flush()
+	Stop()
+}
+
+// Shut the scheduler down in phases, the progress is reported in the scheduler status to allow the shim to fail
+// over cleanly:
+// - stop accepting updates from the RMs
+// - process the RM events that were accepted before the shutdown
+// - stop scheduling after the running cycle, completing the allocations proposed in the cycle
+// - flush the events to the shim
+// - add the last status to the metrics history and stop collecting
+// - store the final snapshot of the scheduler state
+// A phase that does not finish within the timeout is abandoned and the shutdown continues with the next phase.
+func (s *ServiceContext) shutdown() {
+	if s.Scheduler == nil {
+		return
+	}
+	timeout := common.GetDurationEnvVar(EnvShutdownPhaseTimeout, defaultShutdownPhaseTimeout)
+	cc := s.Scheduler.GetClusterContext()
+	start := time.Now()
+
+	cc.SetShutdownPhase(scheduler.ShutdownRejectingUpdates)
+	if s.proxy != nil {
+		if err := s.proxy.StopAcceptingUpdates(timeout); err != nil {
+			log.Logger().Warn("shutdown continues with RM updates in flight",
+				zap.Error(err))
+		}
+	}
+
+	cc.SetShutdownPhase(scheduler.ShutdownDrainingEvents)
+	if err := s.Scheduler.DrainEvents(timeout); err != nil {
+		log.Logger().Warn("shutdown continues without processing all RM events",
+			zap.Error(err))
+	}
+
+	cc.SetShutdownPhase(scheduler.ShutdownStoppingScheduling)
+	if err := s.Scheduler.StopScheduling(timeout); err != nil {
+		log.Logger().Warn("shutdown continues with the scheduling cycle in flight",
+			zap.Error(err))
+	}
+
+	cc.SetShutdownPhase(scheduler.ShutdownFlushingEvents)
+	if s.eventCache != nil {
+		if err := s.eventCache.Flush(timeout); err != nil {
+			log.Logger().Warn("shutdown continues without storing all events",
+				zap.Error(err))
+		}
+	}
+	if s.eventPublisher != nil {
+		s.eventPublisher.Flush()
+	}
+
+	cc.SetShutdownPhase(scheduler.ShutdownFlushingMetrics)
+	if s.metricsCollector != nil {
+		s.metricsCollector.Flush()
+		s.metricsCollector.Stop()
+	}
+
+	cc.SetShutdownPhase(scheduler.ShutdownStoringState)
+	if err := s.Scheduler.StopCheckpointing(timeout); err != nil {
+		log.Logger().Warn("shutdown continues without the final state snapshot",
+			zap.Error(err))
+	}
+
+	cc.SetShutdownPhase(scheduler.ShutdownCompleted)
+	log.Logger().Info("scheduler shutdown completed",
+		zap.Duration("duration", time.Since(start)))
+}
@@ -19,8 +19,11 @@
 package events
 
 import (
+	"fmt"
 	"sync"
+	"time"
 
+	"github.com/apache/incubator-yunikorn-core/pkg/common"
 	"github.com/apache/incubator-yunikorn-core/pkg/log"
 	"github.com/apache/incubator-yunikorn-core/pkg/metrics"
 	"github.com/apache/incubator-yunikorn-scheduler-interface/lib/go/si"
@@ -33,6 +36,7 @@ var cache *EventCache
 
 type EventCache struct {
 	Store EventStore // storing eventChannel
+	Feed  *EventFeed // recent events for clients polling for changes
 
 	channel chan *si.EventRecord // channelling input eventChannel
 	stop    chan bool            // whether the service is stop
@@ -56,6 +60,7 @@ func createEventStoreAndCache() *EventCache {
 func createEventCacheInternal(store EventStore) *EventCache {
 	return &EventCache{
 		Store:   store,
+		Feed:    newEventFeed(defaultEventFeedSize),
 		channel: make(chan *si.EventRecord, defaultEventChannelSize),
 		stop:    make(chan bool),
 	}
@@ -73,6 +78,7 @@ func (ec *EventCache) StartService() {
 				}
 				if event != nil {
 					ec.Store.Store(event)
+					ec.Feed.Add(event)
 					metrics.GetEventMetrics().IncEventsProcessed()
 				}
 			}
@@ -91,6 +97,22 @@ func (ec *EventCache) Stop() {
 	}
 }
 
+// Wait until all events added to the cache have been passed on to the store.
+func (ec *EventCache) Flush(timeout time.Duration) error {
+	ec.Lock()
+	channel := ec.channel
+	ec.Unlock()
+	if channel == nil {
+		return nil
+	}
+	if err := common.WaitFor(10*time.Millisecond, timeout, func() bool {
+		return len(channel) == 0
+	}); err != nil {
+		return fmt.Errorf("event cache not flushed, %d events left: %v", len(channel), err)
+	}
+	return nil
+}
+
 func (ec *EventCache) AddEvent(event *si.EventRecord) {
 	metrics.GetEventMetrics().IncEventsCreated()
 	select {
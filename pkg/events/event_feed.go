@@ -0,0 +1,111 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package events
+
+import (
+	"sync"
+	"time"
+
+	"github.com/apache/incubator-yunikorn-scheduler-interface/lib/go/si"
+)
+
+// need to change for testing
+var defaultEventFeedSize = 10000
+
+// The EventFeed keeps the most recent events processed by the event cache for clients that poll for changes.
+// Each event gets a sequence number, the cursor of a client is the sequence number of the last event it has seen.
+// The feed has a fixed size: the oldest events are dropped when it is full.
+type EventFeed struct {
+	events  []*si.EventRecord // ring buffer, the event with sequence number n is at index n % size
+	last    uint64            // sequence number of the last event added, zero if the feed is empty
+	changed chan struct{}     // closed when the next event is added
+
+	sync.RWMutex
+}
+
+func newEventFeed(size int) *EventFeed {
+	return &EventFeed{
+		events:  make([]*si.EventRecord, size),
+		changed: make(chan struct{}),
+	}
+}
+
+func (ef *EventFeed) Add(event *si.EventRecord) {
+	ef.Lock()
+	defer ef.Unlock()
+	ef.last++
+	ef.events[ef.last%uint64(len(ef.events))] = event
+	// wake up all waiting clients
+	close(ef.changed)
+	ef.changed = make(chan struct{})
+}
+
+// Get the cursor of the last event added: a new client starts polling from here.
+func (ef *EventFeed) GetCursor() uint64 {
+	ef.RLock()
+	defer ef.RUnlock()
+	return ef.last
+}
+
+// Get the events added after the cursor that pass the filter, a nil filter passes all events.
+// If there are no events, wait until an event is added, the timeout passes or the done channel is closed.
+// Returns the events, the cursor for the next poll and true if events after the cursor were dropped from the feed:
+// the client missed changes and must reload the full state. A cursor ahead of the feed, for instance after a restart
+// of the scheduler, is also reported as missed.
+func (ef *EventFeed) Poll(cursor uint64, filter func(event *si.EventRecord) bool, timeout time.Duration, done <-chan struct{}) ([]*si.EventRecord, uint64, bool) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		events, next, missed, changed := ef.getEvents(cursor, filter)
+		if len(events) != 0 || missed || timeout <= 0 {
+			return events, next, missed
+		}
+		cursor = next
+		select {
+		case <-changed:
+		case <-timer.C:
+			return nil, cursor, false
+		case <-done:
+			return nil, cursor, false
+		}
+	}
+}
+
+// Get the events after the cursor and the channel that is closed when the next event is added.
+func (ef *EventFeed) getEvents(cursor uint64, filter func(event *si.EventRecord) bool) ([]*si.EventRecord, uint64, bool, <-chan struct{}) {
+	ef.RLock()
+	defer ef.RUnlock()
+	if cursor > ef.last {
+		return nil, ef.last, true, ef.changed
+	}
+	size := uint64(len(ef.events))
+	missed := false
+	if ef.last > size && cursor < ef.last-size {
+		missed = true
+		cursor = ef.last - size
+	}
+	var events []*si.EventRecord
+	for seq := cursor + 1; seq <= ef.last; seq++ {
+		event := ef.events[seq%size]
+		if filter == nil || filter(event) {
+			events = append(events, event)
+		}
+	}
+	return events, ef.last, missed, ef.changed
+}
@@ -0,0 +1,124 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package events
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+
+	"github.com/apache/incubator-yunikorn-scheduler-interface/lib/go/si"
+)
+
+func addFeedEvents(feed *EventFeed, count int) {
+	for i := 0; i < count; i++ {
+		feed.Add(&si.EventRecord{
+			Type:     si.EventRecord_Type(i % 2),
+			ObjectID: "object" + strconv.Itoa(i),
+		})
+	}
+}
+
+func TestFeedPoll(t *testing.T) {
+	feed := newEventFeed(10)
+	assert.Equal(t, feed.GetCursor(), uint64(0))
+
+	// nothing to return without waiting
+	events, cursor, missed := feed.Poll(0, nil, 0, nil)
+	assert.Equal(t, len(events), 0)
+	assert.Equal(t, cursor, uint64(0))
+	assert.Assert(t, !missed, "no events missed in empty feed")
+
+	addFeedEvents(feed, 5)
+	assert.Equal(t, feed.GetCursor(), uint64(5))
+	events, cursor, missed = feed.Poll(0, nil, 0, nil)
+	assert.Equal(t, len(events), 5)
+	assert.Equal(t, events[0].ObjectID, "object0")
+	assert.Equal(t, cursor, uint64(5))
+	assert.Assert(t, !missed, "no events missed")
+
+	// only the events after the cursor
+	events, cursor, _ = feed.Poll(3, nil, 0, nil)
+	assert.Equal(t, len(events), 2)
+	assert.Equal(t, events[0].ObjectID, "object3")
+	assert.Equal(t, cursor, uint64(5))
+
+	// the filter skips events but the cursor moves past them
+	appsOnly := func(event *si.EventRecord) bool {
+		return event.Type == si.EventRecord_APP
+	}
+	events, cursor, _ = feed.Poll(0, appsOnly, 0, nil)
+	assert.Equal(t, len(events), 2)
+	assert.Equal(t, events[0].ObjectID, "object1")
+	assert.Equal(t, cursor, uint64(5))
+
+	// the oldest events are dropped from a full feed: only the second batch is left
+	addFeedEvents(feed, 10)
+	events, cursor, missed = feed.Poll(3, nil, 0, nil)
+	assert.Assert(t, missed, "dropped events not reported")
+	assert.Equal(t, len(events), 10)
+	assert.Equal(t, events[0].ObjectID, "object0")
+	assert.Equal(t, cursor, uint64(15))
+
+	// a cursor ahead of the feed is reported as missed
+	events, cursor, missed = feed.Poll(20, nil, 0, nil)
+	assert.Assert(t, missed, "cursor ahead of feed not reported")
+	assert.Equal(t, len(events), 0)
+	assert.Equal(t, cursor, uint64(15))
+}
+
+func TestFeedPollWait(t *testing.T) {
+	feed := newEventFeed(10)
+	addFeedEvents(feed, 1)
+
+	// the poll returns when an event passing the filter is added
+	result := make(chan []*si.EventRecord)
+	go func() {
+		events, _, _ := feed.Poll(1, func(event *si.EventRecord) bool {
+			return event.ObjectID == "wanted"
+		}, 5*time.Second, nil)
+		result <- events
+	}()
+	time.Sleep(10 * time.Millisecond)
+	feed.Add(&si.EventRecord{ObjectID: "other"})
+	feed.Add(&si.EventRecord{ObjectID: "wanted"})
+	select {
+	case events := <-result:
+		assert.Equal(t, len(events), 1)
+		assert.Equal(t, events[0].ObjectID, "wanted")
+	case <-time.After(time.Second):
+		t.Fatal("poll did not return after the event was added")
+	}
+
+	// timeout without events
+	start := time.Now()
+	events, cursor, missed := feed.Poll(3, nil, 20*time.Millisecond, nil)
+	assert.Equal(t, len(events), 0)
+	assert.Equal(t, cursor, uint64(3))
+	assert.Assert(t, !missed, "no events missed")
+	assert.Assert(t, time.Since(start) >= 20*time.Millisecond, "poll returned before the timeout")
+
+	// closing the done channel stops the wait
+	done := make(chan struct{})
+	close(done)
+	events, _, _ = feed.Poll(3, nil, 5*time.Second, done)
+	assert.Equal(t, len(events), 0)
+}
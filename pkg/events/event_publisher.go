@@ -19,27 +19,48 @@
 package events
 
 import (
+	"sync"
 	"sync/atomic"
 	"time"
 
 	"go.uber.org/zap"
 
+	"github.com/apache/incubator-yunikorn-core/pkg/common"
 	"github.com/apache/incubator-yunikorn-core/pkg/log"
+	"github.com/apache/incubator-yunikorn-core/pkg/metrics"
 	"github.com/apache/incubator-yunikorn-core/pkg/plugins"
+	"github.com/apache/incubator-yunikorn-scheduler-interface/lib/go/si"
+)
+
+// Environment variables used to configure the pacing of the events pushed to the shim.
+// A max batch of zero or less pushes all collected events every interval.
+const (
+	EnvPushEventInterval = "EVENT_PUSH_INTERVAL"
+	EnvPushEventMaxBatch = "EVENT_PUSH_MAX_BATCH"
 )
 
 // stores the push event internal
 var defaultPushEventInterval = 2 * time.Second
 
+// the maximum number of events carried over to the next interval, the oldest events are dropped first
+const defaultMaxEventBacklog = 100000
+
 type EventPublisher interface {
 	StartService()
 	Stop()
+	// Push all events collected in the store to the shim without waiting for the next interval
+	Flush()
 }
 
 type shimPublisher struct {
 	store             EventStore
 	pushEventInterval time.Duration
+	maxBatch          int
+	maxBacklog        int
+	backlog           []*si.EventRecord // events collected but not yet pushed, guarded by the lock
 	stop              atomic.Value
+
+	sync.Mutex
 }
 
 func CreateShimPublisher(store EventStore) EventPublisher {
@@ -47,13 +68,17 @@ func CreateShimPublisher(store EventStore) EventPublisher {
 }
 
 func createShimPublisherInternal(store EventStore) *shimPublisher {
-	return createShimPublisherWithParameters(store, defaultPushEventInterval)
+	return createShimPublisherWithParameters(store,
+		common.GetDurationEnvVar(EnvPushEventInterval, defaultPushEventInterval),
+		common.GetIntEnvVar(EnvPushEventMaxBatch, 0))
 }
 
-func createShimPublisherWithParameters(store EventStore, pushEventInterval time.Duration) *shimPublisher {
+func createShimPublisherWithParameters(store EventStore, pushEventInterval time.Duration, maxBatch int) *shimPublisher {
 	publisher := &shimPublisher{
 		store:             store,
 		pushEventInterval: pushEventInterval,
+		maxBatch:          maxBatch,
+		maxBacklog:        defaultMaxEventBacklog,
 	}
 	publisher.stop.Store(false)
 	return publisher
@@ -65,22 +90,57 @@ func (sp *shimPublisher) StartService() {
 			if sp.stop.Load().(bool) {
 				break
 			}
-			messages := sp.store.CollectEvents()
-			if len(messages) > 0 {
-				if eventPlugin := plugins.GetEventPlugin(); eventPlugin != nil {
-					log.Logger().Debug("Sending eventChannel", zap.Int("number of messages", len(messages)))
-					eventPlugin.SendEvent(messages)
-				}
-			}
+			sp.push()
 			time.Sleep(sp.pushEventInterval)
 		}
 	}()
 }
 
+// Push the next batch of events to the shim, returns the number of events left in the backlog.
+func (sp *shimPublisher) push() int {
+	sp.Lock()
+	defer sp.Unlock()
+	messages := sp.nextBatch()
+	if len(messages) > 0 {
+		if eventPlugin := plugins.GetEventPlugin(); eventPlugin != nil {
+			log.Logger().Debug("Sending eventChannel", zap.Int("number of messages", len(messages)))
+			eventPlugin.SendEvent(messages)
+		}
+	}
+	return len(sp.backlog)
+}
+
+// Collect the events from the store and return the batch to push to the shim.
+// Events that do not fit in the batch are carried over to the next interval.
+func (sp *shimPublisher) nextBatch() []*si.EventRecord {
+	sp.backlog = append(sp.backlog, sp.store.CollectEvents()...)
+	if overflow := len(sp.backlog) - sp.maxBacklog; overflow > 0 {
+		log.Logger().Debug("event backlog full, dropping oldest events", zap.Int("dropped", overflow))
+		sp.backlog = sp.backlog[overflow:]
+	}
+	batch := sp.backlog
+	sp.backlog = nil
+	if sp.maxBatch > 0 && len(batch) > sp.maxBatch {
+		sp.backlog = append(sp.backlog, batch[sp.maxBatch:]...)
+		batch = batch[:sp.maxBatch]
+	}
+	metrics.GetEventMetrics().SetEventsBacklog(len(sp.backlog))
+	return batch
+}
+
 func (sp *shimPublisher) Stop() {
 	sp.stop.Store(true)
 }
 
+// Push the collected events in batches until the backlog is empty.
+func (sp *shimPublisher) Flush() {
+	for {
+		if sp.push() == 0 {
+			return
+		}
+	}
+}
+
 func (sp *shimPublisher) getEventStore() EventStore {
 	return sp.store
 }
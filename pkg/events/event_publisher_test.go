@@ -80,16 +80,17 @@ func TestServiceStartStopInternal(t *testing.T) {
 	store := newEventStoreImpl()
 	publisher := createShimPublisherInternal(store)
 	publisher.StartService()
+	defer publisher.Stop()
 	assert.Equal(t, publisher.getEventStore(), store)
-	publisher.Stop()
 }
 
 func TestNoFillWithoutEventPluginRegistered(t *testing.T) {
 	pushEventInterval := 2 * time.Millisecond
 
 	store := newEventStoreImpl()
-	publisher := createShimPublisherWithParameters(store, pushEventInterval)
+	publisher := createShimPublisherWithParameters(store, pushEventInterval, 0)
 	publisher.StartService()
+	defer publisher.Stop()
 
 	event := &si.EventRecord{
 		Type:          si.EventRecord_REQUEST,
@@ -114,8 +115,9 @@ func TestPublisherSendsEvent(t *testing.T) {
 	assert.NilError(t, err, "could not create event plugin for test")
 
 	store := newEventStoreImpl()
-	publisher := createShimPublisherWithParameters(store, pushEventInterval)
+	publisher := createShimPublisherWithParameters(store, pushEventInterval, 0)
 	publisher.StartService()
+	defer publisher.Stop()
 
 	event := &si.EventRecord{
 		Type:          si.EventRecord_REQUEST,
@@ -137,6 +139,62 @@ func TestPublisherSendsEvent(t *testing.T) {
 	assert.Equal(t, eventFromPlugin.Reason, "reason")
 	assert.Equal(t, eventFromPlugin.Message, "message")
 	assert.Equal(t, eventFromPlugin.TimestampNano, int64(123456))
+}
 
-	publisher.Stop()
+// events that do not fit in the batch are pushed in the next interval
+func TestPublisherMaxBatch(t *testing.T) {
+	store := newEventStoreImpl()
+	publisher := createShimPublisherWithParameters(store, time.Second, 2)
+	for i := 0; i < 5; i++ {
+		store.Store(&si.EventRecord{
+			Type:     si.EventRecord_REQUEST,
+			ObjectID: fmt.Sprintf("ask-%d", i),
+			Reason:   "reason",
+		})
+	}
+	assert.Equal(t, len(publisher.nextBatch()), 2, "first batch should be capped")
+	assert.Equal(t, len(publisher.backlog), 3, "remaining events should be carried over")
+	assert.Equal(t, store.CountStoredEvents(), 0, "store should have been emptied")
+	assert.Equal(t, len(publisher.nextBatch()), 2, "second batch should be capped")
+	assert.Equal(t, len(publisher.nextBatch()), 1, "third batch should have the last event")
+	assert.Equal(t, len(publisher.nextBatch()), 0, "no events should be left")
+
+	// the oldest events are dropped when the backlog is full
+	publisher = createShimPublisherWithParameters(store, time.Second, 1)
+	publisher.maxBacklog = 3
+	for i := 0; i < 5; i++ {
+		store.Store(&si.EventRecord{
+			Type:     si.EventRecord_REQUEST,
+			ObjectID: fmt.Sprintf("ask-%d", i),
+			Reason:   "reason",
+		})
+	}
+	assert.Equal(t, len(publisher.nextBatch()), 1, "batch should be capped")
+	assert.Equal(t, len(publisher.backlog), 2, "backlog should be capped")
+}
+
+// a flush pushes all events in batches without waiting for the interval
+func TestPublisherFlush(t *testing.T) {
+	eventPlugin, err := createEventPluginForTest()
+	assert.NilError(t, err, "could not create event plugin for test")
+
+	store := newEventStoreImpl()
+	publisher := createShimPublisherWithParameters(store, time.Hour, 2)
+	for i := 0; i < 3; i++ {
+		store.Store(&si.EventRecord{
+			Type:     si.EventRecord_REQUEST,
+			ObjectID: fmt.Sprintf("ask-%d", i),
+			Reason:   "reason",
+		})
+	}
+	publisher.Flush()
+	assert.Equal(t, store.CountStoredEvents(), 0, "store should have been emptied")
+	assert.Equal(t, len(publisher.backlog), 0, "backlog should have been pushed")
+	pushed := make(map[string]bool)
+	for record := eventPlugin.getNextEventRecord(); record != nil; record = eventPlugin.getNextEventRecord() {
+		pushed[record.ObjectID] = true
+	}
+	for i := 0; i < 3; i++ {
+		assert.Assert(t, pushed[fmt.Sprintf("ask-%d", i)], "event %d should have been pushed", i)
+	}
 }
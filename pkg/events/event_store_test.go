@@ -89,6 +89,8 @@ func TestMultiEventsSameObjectID(t *testing.T) {
 // if we push more events to the EventStore than its
 // allowed maximum, those that couldn't fit will be omitted
 func TestStoreWithLimitedSize(t *testing.T) {
+	current := maxEventStoreSize
+	defer func() { maxEventStoreSize = current }()
 	maxEventStoreSize = 3
 
 	store := newEventStoreImpl()
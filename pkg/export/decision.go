@@ -0,0 +1,177 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package export
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/log"
+)
+
+// need to change for testing
+var (
+	defaultChannelSize   = 10000
+	defaultBatchSize     = 500
+	defaultFlushInterval = time.Second
+)
+
+var exporter *DecisionExporter
+
+// The type of scheduling decision exported.
+type DecisionType string
+
+const (
+	Allocation DecisionType = "allocation"
+	Release    DecisionType = "release"
+	Preemption DecisionType = "preemption"
+	// a completed application removed from the partition based on the retention of the queue
+	ApplicationRemoved DecisionType = "applicationRemoved"
+)
+
+// A confirmed scheduling decision as written to the sink.
+type DecisionRecord struct {
+	Type            DecisionType     `json:"type"`
+	Timestamp       int64            `json:"timestamp"`
+	Partition       string           `json:"partition"`
+	ApplicationID   string           `json:"applicationID"`
+	QueueName       string           `json:"queueName,omitempty"`
+	AllocationKey   string           `json:"allocationKey,omitempty"`
+	UUID            string           `json:"uuid"`
+	NodeID          string           `json:"nodeID,omitempty"`
+	Resource        map[string]int64 `json:"resource,omitempty"`
+	Placeholder     bool             `json:"placeholder,omitempty"`
+	TerminationType string           `json:"terminationType,omitempty"`
+	Message         string           `json:"message,omitempty"`
+}
+
+// The exporter collects decision records and writes them in batches to the sink.
+// Records are dropped if the exporter cannot keep up: exporting must never block scheduling.
+type DecisionExporter struct {
+	sink          Sink
+	channel       chan *DecisionRecord
+	stop          chan bool
+	done          chan bool
+	batchSize     int
+	flushInterval time.Duration
+
+	sync.Mutex
+}
+
+// Return the exporter, nil if decision export is not configured.
+func GetDecisionExporter() *DecisionExporter {
+	return exporter
+}
+
+func CreateAndSetDecisionExporter(sink Sink) {
+	exporter = createDecisionExporter(sink, defaultBatchSize, defaultFlushInterval)
+}
+
+func createDecisionExporter(sink Sink, batchSize int, flushInterval time.Duration) *DecisionExporter {
+	return &DecisionExporter{
+		sink:          sink,
+		channel:       make(chan *DecisionRecord, defaultChannelSize),
+		stop:          make(chan bool),
+		done:          make(chan bool),
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+	}
+}
+
+func (de *DecisionExporter) StartService() {
+	go func() {
+		ticker := time.NewTicker(de.flushInterval)
+		defer ticker.Stop()
+		batch := make([]*DecisionRecord, 0, de.batchSize)
+		for {
+			select {
+			case <-de.stop:
+				// drain what is left in the channel before closing the sink
+			drain:
+				for {
+					select {
+					case record := <-de.channel:
+						batch = append(batch, record)
+					default:
+						break drain
+					}
+				}
+				de.flush(batch)
+				if err := de.sink.Close(); err != nil {
+					log.Logger().Warn("failed to close decision export sink",
+						zap.Error(err))
+				}
+				close(de.done)
+				return
+			case record := <-de.channel:
+				batch = append(batch, record)
+				if len(batch) >= de.batchSize {
+					batch = de.flush(batch)
+				}
+			case <-ticker.C:
+				batch = de.flush(batch)
+			}
+		}
+	}()
+}
+
+// Stop the exporter, records still in the channel are written before the sink is closed.
+func (de *DecisionExporter) Stop() {
+	de.Lock()
+	defer de.Unlock()
+	if de.stop == nil {
+		return
+	}
+	de.stop <- true
+	<-de.done
+	de.stop = nil
+}
+
+// Add a record to be exported. Safe to call on a nil exporter, the record is then dropped.
+func (de *DecisionExporter) AddRecord(record *DecisionRecord) {
+	if de == nil || record == nil {
+		return
+	}
+	if record.Timestamp == 0 {
+		record.Timestamp = time.Now().UnixNano()
+	}
+	select {
+	case de.channel <- record:
+	default:
+		log.Logger().Debug("could not add decision record to channel",
+			zap.String("type", string(record.Type)),
+			zap.String("uuid", record.UUID))
+	}
+}
+
+// Write the batch to the sink and return an empty batch to continue with.
+// A failed write is logged and the records are dropped.
+func (de *DecisionExporter) flush(batch []*DecisionRecord) []*DecisionRecord {
+	if len(batch) == 0 {
+		return batch
+	}
+	if err := de.sink.Write(batch); err != nil {
+		log.Logger().Warn("failed to export scheduling decisions",
+			zap.Int("records", len(batch)),
+			zap.Error(err))
+	}
+	return make([]*DecisionRecord, 0, de.batchSize)
+}
@@ -0,0 +1,101 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package export
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+// sink keeping all written batches in memory
+type memorySink struct {
+	batches [][]*DecisionRecord
+	closed  bool
+
+	sync.Mutex
+}
+
+func (ms *memorySink) Write(records []*DecisionRecord) error {
+	ms.Lock()
+	defer ms.Unlock()
+	ms.batches = append(ms.batches, records)
+	return nil
+}
+
+func (ms *memorySink) Close() error {
+	ms.Lock()
+	defer ms.Unlock()
+	ms.closed = true
+	return nil
+}
+
+func (ms *memorySink) getBatches() [][]*DecisionRecord {
+	ms.Lock()
+	defer ms.Unlock()
+	return ms.batches
+}
+
+func TestNilExporter(t *testing.T) {
+	var de *DecisionExporter
+	// must not panic
+	de.AddRecord(&DecisionRecord{Type: Allocation})
+}
+
+func TestExporterBatchSize(t *testing.T) {
+	sink := &memorySink{}
+	de := createDecisionExporter(sink, 2, time.Hour)
+	de.StartService()
+	for _, uuid := range []string{"uuid-1", "uuid-2", "uuid-3"} {
+		de.AddRecord(&DecisionRecord{Type: Allocation, UUID: uuid})
+	}
+	// the first batch is written when it is full, the last record is only written on stop
+	for i := 0; i < 100 && len(sink.getBatches()) == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	batches := sink.getBatches()
+	assert.Equal(t, len(batches), 1, "expected one full batch")
+	assert.Equal(t, len(batches[0]), 2, "unexpected batch size")
+	assert.Equal(t, batches[0][0].UUID, "uuid-1", "records out of order")
+	assert.Assert(t, batches[0][0].Timestamp != 0, "timestamp not set")
+
+	de.Stop()
+	batches = sink.getBatches()
+	assert.Equal(t, len(batches), 2, "remaining records not written on stop")
+	assert.Equal(t, batches[1][0].UUID, "uuid-3", "unexpected record in last batch")
+	assert.Assert(t, sink.closed, "sink not closed on stop")
+	// second stop must not block
+	de.Stop()
+}
+
+func TestExporterFlushInterval(t *testing.T) {
+	sink := &memorySink{}
+	de := createDecisionExporter(sink, 100, 10*time.Millisecond)
+	de.StartService()
+	defer de.Stop()
+	de.AddRecord(&DecisionRecord{Type: Release, UUID: "uuid-1", Timestamp: 1})
+	for i := 0; i < 100 && len(sink.getBatches()) == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	batches := sink.getBatches()
+	assert.Equal(t, len(batches), 1, "record not flushed after interval")
+	assert.Equal(t, batches[0][0].Timestamp, int64(1), "timestamp should not have been changed")
+}
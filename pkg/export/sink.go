@@ -0,0 +1,203 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common"
+	"github.com/apache/incubator-yunikorn-core/pkg/log"
+)
+
+// Environment variables used to configure the decision export sink.
+// If both an endpoint and a file are set the HTTP endpoint is used.
+const (
+	EnvHTTPEndpoint = "DECISION_EXPORT_HTTP_ENDPOINT"
+	EnvHTTPTimeout  = "DECISION_EXPORT_HTTP_TIMEOUT"
+	EnvFile         = "DECISION_EXPORT_FILE"
+	EnvFileMaxSize  = "DECISION_EXPORT_FILE_MAX_SIZE"
+	EnvFileMaxFiles = "DECISION_EXPORT_FILE_MAX_FILES"
+)
+
+const (
+	defaultHTTPTimeout  = 5 * time.Second
+	defaultFileMaxSize  = 100 * 1024 * 1024
+	defaultFileMaxFiles = 5
+)
+
+// A sink receives batches of decision records from the exporter.
+// Write is only called from the exporter service routine.
+type Sink interface {
+	Write(records []*DecisionRecord) error
+	Close() error
+}
+
+// Create the sink based on the environment, returns nil if decision export is not configured.
+func CreateSinkFromEnv() (Sink, error) {
+	if endpoint := os.Getenv(EnvHTTPEndpoint); endpoint != "" {
+		return NewHTTPSink(endpoint, common.GetDurationEnvVar(EnvHTTPTimeout, defaultHTTPTimeout)), nil
+	}
+	if path := os.Getenv(EnvFile); path != "" {
+		return NewFileSink(path, int64(common.GetIntEnvVar(EnvFileMaxSize, defaultFileMaxSize)),
+			common.GetIntEnvVar(EnvFileMaxFiles, defaultFileMaxFiles))
+	}
+	return nil, nil
+}
+
+// The HTTP sink posts each batch as a JSON array to the endpoint.
+type httpSink struct {
+	endpoint string
+	client   *http.Client
+}
+
+func NewHTTPSink(endpoint string, timeout time.Duration) Sink {
+	return &httpSink{
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: timeout},
+	}
+}
+
+func (hs *httpSink) Write(records []*DecisionRecord) error {
+	body, err := json.Marshal(records)
+	if err != nil {
+		return err
+	}
+	var resp *http.Response
+	resp, err = hs.client.Post(hs.endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("decision export to %s failed with status %s", hs.endpoint, resp.Status)
+	}
+	return nil
+}
+
+func (hs *httpSink) Close() error {
+	hs.client.CloseIdleConnections()
+	return nil
+}
+
+// The file sink writes one JSON record per line.
+// The file is rotated when the next write would make it larger than the maximum size:
+// the rotated files are numbered path.1 (newest) up to path.maxFiles (oldest).
+type fileSink struct {
+	path     string
+	maxSize  int64
+	maxFiles int
+	file     *os.File
+	size     int64
+
+	sync.Mutex
+}
+
+func NewFileSink(path string, maxSize int64, maxFiles int) (Sink, error) {
+	fs := &fileSink{
+		path:     path,
+		maxSize:  maxSize,
+		maxFiles: maxFiles,
+	}
+	if err := fs.open(); err != nil {
+		return nil, err
+	}
+	return fs, nil
+}
+
+func (fs *fileSink) open() error {
+	file, err := os.OpenFile(fs.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	var info os.FileInfo
+	info, err = file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	fs.file = file
+	fs.size = info.Size()
+	return nil
+}
+
+func (fs *fileSink) Write(records []*DecisionRecord) error {
+	fs.Lock()
+	defer fs.Unlock()
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+	}
+	if fs.maxSize > 0 && fs.size > 0 && fs.size+int64(buf.Len()) > fs.maxSize {
+		if err := fs.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := fs.file.Write(buf.Bytes())
+	fs.size += int64(n)
+	return err
+}
+
+// Rotate the current file and open a new empty file.
+// If the file cannot be rotated the current file is reopened and writes continue in that file.
+// NOTE: this is a lock free call. It must only be called holding the fileSink lock.
+func (fs *fileSink) rotate() error {
+	if err := fs.file.Close(); err != nil {
+		log.Logger().Debug("failed to close decision export file",
+			zap.String("path", fs.path),
+			zap.Error(err))
+	}
+	if fs.maxFiles > 0 {
+		for i := fs.maxFiles - 1; i > 0; i-- {
+			oldPath := fmt.Sprintf("%s.%d", fs.path, i)
+			// older files might not exist yet: only log other errors
+			if err := os.Rename(oldPath, fmt.Sprintf("%s.%d", fs.path, i+1)); err != nil && !os.IsNotExist(err) {
+				log.Logger().Warn("failed to rotate decision export file",
+					zap.String("path", oldPath),
+					zap.Error(err))
+			}
+		}
+		if err := os.Rename(fs.path, fs.path+".1"); err != nil {
+			log.Logger().Warn("failed to rotate decision export file, continuing with current file",
+				zap.String("path", fs.path),
+				zap.Error(err))
+		}
+	} else if err := os.Truncate(fs.path, 0); err != nil {
+		log.Logger().Warn("failed to truncate decision export file, continuing with current file",
+			zap.String("path", fs.path),
+			zap.Error(err))
+	}
+	return fs.open()
+}
+
+func (fs *fileSink) Close() error {
+	fs.Lock()
+	defer fs.Unlock()
+	return fs.file.Close()
+}
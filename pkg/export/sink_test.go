@@ -0,0 +1,159 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+func TestHTTPSink(t *testing.T) {
+	var received []*DecisionRecord
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, r.Method, http.MethodPost, "unexpected method")
+		assert.Equal(t, r.Header.Get("Content-Type"), "application/json", "unexpected content type")
+		err := json.NewDecoder(r.Body).Decode(&received)
+		assert.NilError(t, err, "failed to decode posted records")
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	sink := NewHTTPSink(server.URL, time.Second)
+	records := []*DecisionRecord{
+		{Type: Allocation, UUID: "uuid-1", Resource: map[string]int64{"memory": 10}},
+		{Type: Preemption, UUID: "uuid-2", TerminationType: "PREEMPTED_BY_SCHEDULER"},
+	}
+	err := sink.Write(records)
+	assert.NilError(t, err, "write to http sink failed")
+	assert.DeepEqual(t, received, records)
+
+	status = http.StatusInternalServerError
+	err = sink.Write(records)
+	assert.ErrorContains(t, err, "500", "failed status should return an error")
+	assert.NilError(t, sink.Close(), "close of http sink failed")
+}
+
+func TestFileSinkRotation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "decision-export")
+	assert.NilError(t, err, "failed to create temp dir")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "decisions.log")
+
+	record := &DecisionRecord{Type: Allocation, UUID: "uuid-1", Timestamp: 1}
+	line, err := json.Marshal(record)
+	assert.NilError(t, err, "failed to marshal record")
+	// room for two records per file, keep two rotated files
+	var sink Sink
+	sink, err = NewFileSink(path, int64(2*(len(line)+1)), 2)
+	assert.NilError(t, err, "failed to create file sink")
+	for i := 0; i < 7; i++ {
+		err = sink.Write([]*DecisionRecord{record})
+		assert.NilError(t, err, "write %d to file sink failed", i)
+	}
+	assert.NilError(t, sink.Close(), "close of file sink failed")
+
+	assert.Equal(t, countLines(t, path), 1, "current file should hold the last record")
+	assert.Equal(t, countLines(t, path+".1"), 2, "first rotated file should be full")
+	assert.Equal(t, countLines(t, path+".2"), 2, "second rotated file should be full")
+	_, err = os.Stat(path + ".3")
+	assert.Assert(t, os.IsNotExist(err), "only the configured number of rotated files should be kept")
+
+	// reopen appends to the existing file
+	sink, err = NewFileSink(path, 0, 0)
+	assert.NilError(t, err, "failed to reopen file sink")
+	err = sink.Write([]*DecisionRecord{record, record})
+	assert.NilError(t, err, "write to reopened file sink failed")
+	assert.NilError(t, sink.Close(), "close of file sink failed")
+	assert.Equal(t, countLines(t, path), 3, "records should have been appended")
+}
+
+func TestFileSinkRotationFailure(t *testing.T) {
+	dir, err := ioutil.TempDir("", "decision-export")
+	assert.NilError(t, err, "failed to create temp dir")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "decisions.log")
+	// a non empty directory in place of the rotated file fails the rename
+	assert.NilError(t, os.MkdirAll(filepath.Join(path+".1", "blocked"), 0755), "failed to create blocking directory")
+
+	record := &DecisionRecord{Type: Allocation, UUID: "uuid-1", Timestamp: 1}
+	line, err := json.Marshal(record)
+	assert.NilError(t, err, "failed to marshal record")
+	var sink Sink
+	sink, err = NewFileSink(path, int64(len(line)+1), 1)
+	assert.NilError(t, err, "failed to create file sink")
+	for i := 0; i < 3; i++ {
+		err = sink.Write([]*DecisionRecord{record})
+		assert.NilError(t, err, "write %d to file sink failed", i)
+	}
+	assert.NilError(t, sink.Close(), "close of file sink failed")
+	assert.Equal(t, countLines(t, path), 3, "records should have been written to the current file")
+}
+
+func TestCreateSinkFromEnv(t *testing.T) {
+	sink, err := CreateSinkFromEnv()
+	assert.NilError(t, err, "no configuration should not fail")
+	assert.Assert(t, sink == nil, "sink created without configuration")
+
+	dir, err := ioutil.TempDir("", "decision-export")
+	assert.NilError(t, err, "failed to create temp dir")
+	defer os.RemoveAll(dir)
+	assert.NilError(t, os.Setenv(EnvFile, filepath.Join(dir, "decisions.log")))
+	defer os.Unsetenv(EnvFile)
+	sink, err = CreateSinkFromEnv()
+	assert.NilError(t, err, "file sink creation failed")
+	_, ok := sink.(*fileSink)
+	assert.Assert(t, ok, "expected a file sink")
+	assert.NilError(t, sink.Close())
+
+	// the endpoint takes precedence
+	assert.NilError(t, os.Setenv(EnvHTTPEndpoint, "http://localhost:9999/decisions"))
+	defer os.Unsetenv(EnvHTTPEndpoint)
+	sink, err = CreateSinkFromEnv()
+	assert.NilError(t, err, "http sink creation failed")
+	_, ok = sink.(*httpSink)
+	assert.Assert(t, ok, "expected a http sink")
+
+	// file that cannot be created
+	assert.NilError(t, os.Unsetenv(EnvHTTPEndpoint))
+	assert.NilError(t, os.Setenv(EnvFile, filepath.Join(dir, "missing", "decisions.log")))
+	_, err = CreateSinkFromEnv()
+	assert.Assert(t, err != nil, "file sink in a missing directory should fail")
+}
+
+func countLines(t *testing.T, path string) int {
+	file, err := os.Open(path)
+	assert.NilError(t, err, "failed to open %s", path)
+	defer file.Close()
+	count := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		count++
+	}
+	return count
+}
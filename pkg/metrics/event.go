@@ -18,7 +18,9 @@
 
 package metrics
 
-import "github.com/prometheus/client_golang/prometheus"
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
 
 type eventMetrics struct {
 	totalEventsCreated      prometheus.Gauge
@@ -28,6 +30,7 @@ type eventMetrics struct {
 	totalEventsStored       prometheus.Gauge
 	totalEventsNotStored    prometheus.Gauge
 	totalEventsCollected    prometheus.Gauge
+	eventsBacklog           prometheus.Gauge
 }
 
 func initEventMetrics() CoreEventMetrics {
@@ -82,6 +85,15 @@ func initEventMetrics() CoreEventMetrics {
 			Name:      "total_collected",
 			Help:      "total events collected",
 		})
+	// the backlog is registered to allow alerting on events that are not pushed to the shim in time
+	metrics.eventsBacklog = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Subsystem: EventSubsystem,
+			Name:      "backlog",
+			Help:      "events collected but not yet pushed to the shim",
+		})
+	registerCollector(metrics.eventsBacklog)
 
 	return metrics
 }
@@ -113,3 +125,7 @@ func (em *eventMetrics) IncEventsNotStored() {
 func (em *eventMetrics) AddEventsCollected(collectedEvents int) {
 	em.totalEventsCollected.Add(float64(collectedEvents))
 }
+
+func (em *eventMetrics) SetEventsBacklog(backlog int) {
+	em.eventsBacklog.Set(float64(backlog))
+}
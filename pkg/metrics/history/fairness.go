@@ -0,0 +1,151 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package history
+
+import (
+	"sort"
+	"time"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+)
+
+// Fairness of the allocations of a single user in a queue over the reported window.
+type UserFairness struct {
+	User string
+	// average dominant share of the allocated resources of the queue
+	Share float64
+	// average fair share: the allocated resources of the queue divided equally over its users
+	FairShare float64
+	// number of samples the user was active in the queue
+	Samples int
+}
+
+// Ratio of the share and the fair share: above 1 the user is over served, below 1 the user is under served.
+func (uf *UserFairness) Ratio() float64 {
+	if uf.FairShare == 0 {
+		return 0
+	}
+	return uf.Share / uf.FairShare
+}
+
+// Fairness of the allocations of all users of a queue over the reported window.
+type QueueUserFairness struct {
+	Partition string
+	QueuePath string
+	// users sorted on the ratio of their share and fair share, most over served first
+	Users []*UserFairness
+	// most over and under served users, empty if no user is over or under served
+	MostOverServed  string
+	MostUnderServed string
+}
+
+// Calculate the fairness of the allocations per user of all queues with records in the window.
+// Each record with allocated resources in the queue is a sample: a user's share is the dominant share of the user's
+// allocated resources in the allocated resources of all users in the queue. The fair share divides the allocated
+// resources equally over the users active in the queue at that time. Both are averaged over the samples the user
+// was active in.
+func (h *InternalMetricsHistory) GetUserFairness(window time.Duration) []*QueueUserFairness {
+	since := time.Now().Add(-window)
+	queues := make(map[string]*QueueUserFairness)
+	users := make(map[string]map[string]*UserFairness)
+	for _, record := range h.GetQueueRecords() {
+		if record == nil || record.Timestamp.Before(since) {
+			continue
+		}
+		for _, usage := range record.Queues {
+			shares := userShares(usage.Users)
+			if len(shares) == 0 {
+				continue
+			}
+			key := usage.Partition + "/" + usage.QueuePath
+			if _, ok := queues[key]; !ok {
+				queues[key] = &QueueUserFairness{
+					Partition: usage.Partition,
+					QueuePath: usage.QueuePath,
+				}
+				users[key] = make(map[string]*UserFairness)
+			}
+			fairShare := 1 / float64(len(shares))
+			for user, share := range shares {
+				uf, ok := users[key][user]
+				if !ok {
+					uf = &UserFairness{User: user}
+					users[key][user] = uf
+				}
+				uf.Share += share
+				uf.FairShare += fairShare
+				uf.Samples++
+			}
+		}
+	}
+	result := make([]*QueueUserFairness, 0, len(queues))
+	for key, qf := range queues {
+		for _, uf := range users[key] {
+			uf.Share /= float64(uf.Samples)
+			uf.FairShare /= float64(uf.Samples)
+			qf.Users = append(qf.Users, uf)
+		}
+		sort.Slice(qf.Users, func(i, j int) bool {
+			if qf.Users[i].Ratio() != qf.Users[j].Ratio() {
+				return qf.Users[i].Ratio() > qf.Users[j].Ratio()
+			}
+			return qf.Users[i].User < qf.Users[j].User
+		})
+		if first := qf.Users[0]; first.Ratio() > 1 {
+			qf.MostOverServed = first.User
+		}
+		if last := qf.Users[len(qf.Users)-1]; last.Ratio() < 1 {
+			qf.MostUnderServed = last.User
+		}
+		result = append(result, qf)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Partition != result[j].Partition {
+			return result[i].Partition < result[j].Partition
+		}
+		return result[i].QueuePath < result[j].QueuePath
+	})
+	return result
+}
+
+// Dominant share of each user in the total allocated resources of all users.
+// Returns nil if nothing is allocated.
+func userShares(users map[string]*resources.Resource) map[string]float64 {
+	total := resources.NewResource()
+	for _, allocated := range users {
+		total = resources.Add(total, allocated)
+	}
+	if resources.IsZero(total) {
+		return nil
+	}
+	shares := make(map[string]float64)
+	for user, allocated := range users {
+		var share float64
+		for name, quantity := range total.Resources {
+			if quantity <= 0 || allocated == nil {
+				continue
+			}
+			if typeShare := float64(allocated.Resources[name]) / float64(quantity); typeShare > share {
+				share = typeShare
+			}
+		}
+		shares[user] = share
+	}
+	return shares
+}
@@ -0,0 +1,94 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package history
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+)
+
+func TestUserShares(t *testing.T) {
+	assert.Assert(t, userShares(nil) == nil, "no users should have no shares")
+	idle := map[string]*resources.Resource{"user1": resources.NewResource(), "user2": nil}
+	assert.Assert(t, userShares(idle) == nil, "nothing allocated should have no shares")
+
+	users := map[string]*resources.Resource{
+		"user1": resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 30, "vcore": 1}),
+		"user2": resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 10, "vcore": 3}),
+		"user3": nil,
+	}
+	shares := userShares(users)
+	assert.Equal(t, len(shares), 3, "expected a share for each user")
+	assert.Equal(t, shares["user1"], 0.75, "memory should be the dominant share")
+	assert.Equal(t, shares["user2"], 0.75, "vcore should be the dominant share")
+	assert.Equal(t, shares["user3"], 0.0, "user without allocations should have no share")
+}
+
+func TestGetUserFairness(t *testing.T) {
+	h := NewInternalMetricsHistory(4)
+	assert.Equal(t, len(h.GetUserFairness(time.Hour)), 0, "empty history should not return fairness")
+
+	usage := func(alloc1, alloc2 resources.Quantity) []*QueueUsage {
+		return []*QueueUsage{
+			{
+				Partition: "default",
+				QueuePath: "root.a",
+				Users: map[string]*resources.Resource{
+					"user1": resources.NewResourceFromMap(map[string]resources.Quantity{"memory": alloc1}),
+					"user2": resources.NewResourceFromMap(map[string]resources.Quantity{"memory": alloc2}),
+				},
+			},
+			{
+				Partition: "default",
+				QueuePath: "root",
+				Users:     map[string]*resources.Resource{"user1": resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 10})},
+			},
+		}
+	}
+	now := time.Now()
+	// the oldest record is outside the window
+	h.StoreQueues(usage(0, 100))
+	h.queueRecords[0].Timestamp = now.Add(-2 * time.Hour)
+	h.StoreQueues(usage(60, 40))
+	h.StoreQueues(usage(100, 0))
+
+	fairness := h.GetUserFairness(time.Hour)
+	assert.Equal(t, len(fairness), 2, "expected fairness for each queue")
+	assert.Equal(t, fairness[0].QueuePath, "root", "fairness should be sorted by queue")
+	assert.Equal(t, len(fairness[0].Users), 1, "unexpected users for the root")
+	assert.Equal(t, fairness[0].Users[0].Ratio(), 1.0, "single user should get the fair share")
+	assert.Equal(t, fairness[0].MostOverServed, "", "single user should not be over served")
+	assert.Equal(t, fairness[0].MostUnderServed, "", "single user should not be under served")
+
+	queue := fairness[1]
+	assert.Equal(t, queue.QueuePath, "root.a")
+	assert.Equal(t, len(queue.Users), 2, "unexpected users for the queue")
+	assert.Equal(t, queue.Users[0].User, "user1", "most over served user should be first")
+	assert.Equal(t, queue.Users[0].Samples, 2, "record outside the window should be ignored")
+	assert.Equal(t, queue.Users[0].Share, 0.8, "unexpected average share")
+	assert.Equal(t, queue.Users[0].FairShare, 0.5, "unexpected average fair share")
+	assert.Equal(t, queue.Users[1].User, "user2")
+	assert.Equal(t, queue.Users[1].Share, 0.2, "unexpected average share")
+	assert.Equal(t, queue.MostOverServed, "user1")
+	assert.Equal(t, queue.MostUnderServed, "user2")
+}
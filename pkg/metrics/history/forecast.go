@@ -0,0 +1,171 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package history
+
+import (
+	"sort"
+	"time"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+)
+
+// smoothing factor used for the exponentially weighted moving average of the queue usage
+const ewmaAlpha = 0.3
+
+// Forecast of the usage of a single queue after the requested horizon.
+type QueueForecast struct {
+	Partition   string
+	QueuePath   string
+	Allocated   *resources.Resource
+	Pending     *resources.Resource
+	MaxResource *resources.Resource
+	// number of samples the forecast is based on
+	Samples int
+}
+
+// Will the forecasted allocated and pending resources together exceed the queue maximum.
+// Types not set in the maximum are not limited.
+func (qf *QueueForecast) ExceedsMax() bool {
+	if qf.MaxResource == nil {
+		return false
+	}
+	return !qf.MaxResource.FitInMaxUndef(resources.Add(qf.Allocated, qf.Pending))
+}
+
+type sample struct {
+	seconds float64
+	value   float64
+}
+
+// Forecast the allocated and pending resources of all queues in the history for the given horizon.
+// The forecast combines an exponentially weighted moving average of the samples as the current level with
+// the least squares trend over all samples. Queues that are not part of the latest record are not reported.
+func (h *InternalMetricsHistory) GetQueueForecast(horizon time.Duration) []*QueueForecast {
+	records := h.GetQueueRecords()
+	var latest *queueMetricsRecord
+	allocated := make(map[string]map[string][]sample)
+	pending := make(map[string]map[string][]sample)
+	// nil records are always at the start: finish iterating
+	for _, record := range records {
+		if record == nil {
+			continue
+		}
+		latest = record
+		seconds := float64(record.Timestamp.UnixNano()) / float64(time.Second)
+		for _, usage := range record.Queues {
+			key := usage.Partition + "/" + usage.QueuePath
+			addSamples(allocated, key, seconds, usage.Allocated)
+			addSamples(pending, key, seconds, usage.Pending)
+		}
+	}
+	if latest == nil {
+		return nil
+	}
+	var result []*QueueForecast
+	for _, usage := range latest.Queues {
+		key := usage.Partition + "/" + usage.QueuePath
+		result = append(result, &QueueForecast{
+			Partition:   usage.Partition,
+			QueuePath:   usage.QueuePath,
+			Allocated:   forecastResource(allocated[key], horizon),
+			Pending:     forecastResource(pending[key], horizon),
+			MaxResource: usage.MaxResource,
+			Samples:     sampleCount(allocated[key]),
+		})
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Partition != result[j].Partition {
+			return result[i].Partition < result[j].Partition
+		}
+		return result[i].QueuePath < result[j].QueuePath
+	})
+	return result
+}
+
+// Add a sample for each resource type in the resource, types not set in a record do not add a sample.
+func addSamples(samples map[string]map[string][]sample, key string, seconds float64, res *resources.Resource) {
+	perType, ok := samples[key]
+	if !ok {
+		perType = make(map[string][]sample)
+		samples[key] = perType
+	}
+	if res != nil {
+		for name, quantity := range res.Resources {
+			perType[name] = append(perType[name], sample{seconds: seconds, value: float64(quantity)})
+		}
+	}
+}
+
+func sampleCount(perType map[string][]sample) int {
+	count := 0
+	for _, values := range perType {
+		if len(values) > count {
+			count = len(values)
+		}
+	}
+	return count
+}
+
+func forecastResource(perType map[string][]sample, horizon time.Duration) *resources.Resource {
+	res := resources.NewResource()
+	for name, values := range perType {
+		res.Resources[name] = resources.Quantity(forecastValue(values, horizon))
+	}
+	return res
+}
+
+// Forecast a single series: the EWMA level extrapolated with the least squares slope.
+// The result is never negative.
+func forecastValue(values []sample, horizon time.Duration) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	level := values[0].value
+	for _, s := range values[1:] {
+		level = ewmaAlpha*s.value + (1-ewmaAlpha)*level
+	}
+	forecast := level + linearSlope(values)*horizon.Seconds()
+	if forecast < 0 {
+		return 0
+	}
+	return forecast
+}
+
+// Least squares slope of the samples in units per second, zero if it cannot be calculated.
+func linearSlope(values []sample) float64 {
+	n := float64(len(values))
+	if n < 2 {
+		return 0
+	}
+	// offset the time to the first sample to keep the numbers small
+	base := values[0].seconds
+	var sumX, sumY, sumXY, sumXX float64
+	for _, s := range values {
+		x := s.seconds - base
+		sumX += x
+		sumY += s.value
+		sumXY += x * s.value
+		sumXX += x * x
+	}
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return 0
+	}
+	return (n*sumXY - sumX*sumY) / denominator
+}
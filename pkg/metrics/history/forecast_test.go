@@ -0,0 +1,86 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package history
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+)
+
+func TestLinearSlope(t *testing.T) {
+	assert.Equal(t, linearSlope(nil), 0.0, "no samples should have no slope")
+	assert.Equal(t, linearSlope([]sample{{seconds: 10, value: 5}}), 0.0, "single sample should have no slope")
+	assert.Equal(t, linearSlope([]sample{{seconds: 10, value: 5}, {seconds: 10, value: 7}}), 0.0, "same time samples should have no slope")
+	values := []sample{{seconds: 100, value: 10}, {seconds: 110, value: 20}, {seconds: 120, value: 30}}
+	assert.Equal(t, linearSlope(values), 1.0, "unexpected slope for linear growth")
+}
+
+func TestForecastValue(t *testing.T) {
+	assert.Equal(t, forecastValue(nil, time.Hour), 0.0, "no samples should forecast 0")
+	// flat usage stays flat
+	values := []sample{{seconds: 0, value: 10}, {seconds: 60, value: 10}, {seconds: 120, value: 10}}
+	assert.Equal(t, forecastValue(values, time.Hour), 10.0, "flat usage should not change")
+	// shrinking usage never goes below zero
+	values = []sample{{seconds: 0, value: 10}, {seconds: 60, value: 5}, {seconds: 120, value: 0}}
+	assert.Equal(t, forecastValue(values, time.Hour), 0.0, "forecast should not be negative")
+	// growing usage: level plus one unit per minute for an hour
+	values = []sample{{seconds: 0, value: 10}, {seconds: 60, value: 11}, {seconds: 120, value: 12}}
+	level := 0.3*12 + 0.7*(0.3*11+0.7*10)
+	assert.Equal(t, forecastValue(values, time.Hour), level+60, "unexpected forecast for growing usage")
+}
+
+func TestGetQueueForecast(t *testing.T) {
+	h := NewInternalMetricsHistory(3)
+	assert.Assert(t, h.GetQueueForecast(time.Hour) == nil, "empty history should not return a forecast")
+
+	maxRes := resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 100})
+	start := time.Now().Add(-2 * time.Minute)
+	for i := 0; i < 3; i++ {
+		h.StoreQueues([]*QueueUsage{
+			{
+				Partition:   "default",
+				QueuePath:   "root.a",
+				Allocated:   resources.NewResourceFromMap(map[string]resources.Quantity{"memory": resources.Quantity(10 * (i + 1))}),
+				Pending:     resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 5}),
+				MaxResource: maxRes,
+			},
+			{
+				Partition: "default",
+				QueuePath: "root",
+				Allocated: resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 10}),
+			},
+		})
+		// fix the timestamps to one minute apart
+		h.queueRecords[i].Timestamp = start.Add(time.Duration(i) * time.Minute)
+	}
+	forecast := h.GetQueueForecast(time.Hour)
+	assert.Equal(t, len(forecast), 2, "expected a forecast for each queue")
+	assert.Equal(t, forecast[0].QueuePath, "root", "forecast should be sorted by queue")
+	assert.Equal(t, forecast[0].Samples, 3, "unexpected number of samples")
+	assert.Equal(t, forecast[0].Allocated.Resources["memory"], resources.Quantity(10), "flat usage should not change")
+	assert.Assert(t, !forecast[0].ExceedsMax(), "queue without a max should never exceed it")
+	assert.Equal(t, forecast[1].QueuePath, "root.a", "forecast should be sorted by queue")
+	assert.Assert(t, forecast[1].Allocated.Resources["memory"] > 600, "growing usage should be extrapolated")
+	assert.Equal(t, forecast[1].Pending.Resources["memory"], resources.Quantity(5), "flat pending should not change")
+	assert.Assert(t, forecast[1].ExceedsMax(), "growing queue should exceed the max")
+}
@@ -21,6 +21,8 @@ package history
 import (
 	"sync"
 	"time"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
 )
 
 // This class collects basic information about the cluster
@@ -33,6 +35,10 @@ type InternalMetricsHistory struct {
 	// internal implementation of limited array
 	pointer int
 
+	// queue usage snapshots, same limit as the records but stored independently
+	queueRecords []*queueMetricsRecord
+	queuePointer int
+
 	sync.RWMutex
 }
 
@@ -42,10 +48,27 @@ type metricsRecord struct {
 	TotalContainers   int
 }
 
+// Usage of a single queue at the time of collection.
+type QueueUsage struct {
+	Partition   string
+	QueuePath   string
+	Allocated   *resources.Resource
+	Pending     *resources.Resource
+	MaxResource *resources.Resource
+	// allocated resources per user of the applications in the queue and its children
+	Users map[string]*resources.Resource
+}
+
+type queueMetricsRecord struct {
+	Timestamp time.Time
+	Queues    []*QueueUsage
+}
+
 func NewInternalMetricsHistory(limit int) *InternalMetricsHistory {
 	return &InternalMetricsHistory{
-		records: make([]*metricsRecord, limit),
-		limit:   limit,
+		records:      make([]*metricsRecord, limit),
+		queueRecords: make([]*queueMetricsRecord, limit),
+		limit:        limit,
 	}
 }
 
@@ -76,6 +99,31 @@ func (h *InternalMetricsHistory) GetRecords() []*metricsRecord {
 	return returnRecords
 }
 
+func (h *InternalMetricsHistory) StoreQueues(queues []*QueueUsage) {
+	h.Lock()
+	defer h.Unlock()
+
+	h.queueRecords[h.queuePointer] = &queueMetricsRecord{
+		Timestamp: time.Now(),
+		Queues:    queues,
+	}
+	h.queuePointer++
+	if h.queuePointer == h.limit {
+		h.queuePointer = 0
+	}
+}
+
+// Return the queue records oldest first, unused slots are nil and always at the start.
+func (h *InternalMetricsHistory) GetQueueRecords() []*queueMetricsRecord {
+	h.RLock()
+	defer h.RUnlock()
+
+	returnRecords := make([]*queueMetricsRecord, h.limit-h.queuePointer)
+	copy(returnRecords, h.queueRecords[h.queuePointer:])
+	returnRecords = append(returnRecords, h.queueRecords[:h.queuePointer]...)
+	return returnRecords
+}
+
 func (h *InternalMetricsHistory) GetLimit() int {
 	h.RLock()
 	defer h.RUnlock()
@@ -0,0 +1,96 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package history
+
+import (
+	"sync"
+	"time"
+)
+
+// Counter that tracks the number of events that occurred in a rolling window.
+// The events are stored in fixed size time buckets in a ring. The oldest bucket is reused when
+// time moves on. The counter can answer for any window up to the number of buckets times the bucket size.
+type WindowCounter struct {
+	bucketSize time.Duration
+	buckets    []int
+	// start time of each bucket in the ring, used to detect stale buckets
+	bucketTime []time.Time
+	// time source, replaced in tests
+	now func() time.Time
+
+	sync.RWMutex
+}
+
+// Create a counter with the given bucket size and number of buckets.
+// The maximum window that can be queried is bucketSize * buckets.
+func NewWindowCounter(bucketSize time.Duration, buckets int) *WindowCounter {
+	return &WindowCounter{
+		bucketSize: bucketSize,
+		buckets:    make([]int, buckets),
+		bucketTime: make([]time.Time, buckets),
+		now:        time.Now,
+	}
+}
+
+// Add the number of events to the current bucket.
+func (wc *WindowCounter) Add(value int) {
+	wc.Lock()
+	defer wc.Unlock()
+	start := wc.now().Truncate(wc.bucketSize)
+	idx := wc.bucketIndex(start)
+	if !wc.bucketTime[idx].Equal(start) {
+		wc.bucketTime[idx] = start
+		wc.buckets[idx] = 0
+	}
+	wc.buckets[idx] += value
+}
+
+// Add one event to the current bucket.
+func (wc *WindowCounter) Inc() {
+	wc.Add(1)
+}
+
+// Return the number of events in the window ending now.
+// The window is rounded up to a whole number of buckets and capped at the size of the ring.
+func (wc *WindowCounter) Count(window time.Duration) int {
+	wc.RLock()
+	defer wc.RUnlock()
+	current := wc.now().Truncate(wc.bucketSize)
+	// the current bucket is always included
+	oldest := current.Add(-window).Add(wc.bucketSize)
+	total := 0
+	for i, value := range wc.buckets {
+		bucketStart := wc.bucketTime[i]
+		if bucketStart.IsZero() || bucketStart.Before(oldest) || bucketStart.After(current) {
+			continue
+		}
+		total += value
+	}
+	return total
+}
+
+// Return the maximum window the counter can report on.
+func (wc *WindowCounter) MaxWindow() time.Duration {
+	return wc.bucketSize * time.Duration(len(wc.buckets))
+}
+
+// Index of the bucket in the ring for the given bucket start time
+func (wc *WindowCounter) bucketIndex(start time.Time) int {
+	return int((start.UnixNano() / int64(wc.bucketSize)) % int64(len(wc.buckets)))
+}
@@ -0,0 +1,56 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package history
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+func TestWindowCounter(t *testing.T) {
+	counter := NewWindowCounter(10*time.Second, 6)
+	assert.Equal(t, counter.MaxWindow(), time.Minute, "unexpected max window")
+	current := time.Unix(1000, 0)
+	counter.now = func() time.Time { return current }
+	assert.Equal(t, counter.Count(time.Minute), 0, "new counter should be empty")
+
+	counter.Inc()
+	counter.Add(2)
+	assert.Equal(t, counter.Count(10*time.Second), 3, "events not counted in current bucket")
+	assert.Equal(t, counter.Count(time.Minute), 3, "events not counted in full window")
+
+	// move into the next bucket
+	current = current.Add(10 * time.Second)
+	counter.Inc()
+	assert.Equal(t, counter.Count(10*time.Second), 1, "only the current bucket should be counted")
+	assert.Equal(t, counter.Count(20*time.Second), 4, "two buckets should be counted")
+
+	// move past the window for the first bucket: it drops out of the count
+	current = current.Add(50 * time.Second)
+	assert.Equal(t, counter.Count(time.Minute), 1, "first bucket should have dropped out of the window")
+	// reuse of the first bucket in the ring must reset it
+	counter.Inc()
+	assert.Equal(t, counter.Count(time.Minute), 2, "reused bucket not reset")
+
+	// after a long quiet period nothing is counted
+	current = current.Add(time.Hour)
+	assert.Equal(t, counter.Count(time.Minute), 0, "old events should not be counted")
+}
@@ -21,6 +21,12 @@ package metrics
 import (
 	"sync"
 	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+	"github.com/apache/incubator-yunikorn-core/pkg/log"
 )
 
 const (
@@ -30,18 +36,23 @@ const (
 	SchedulerSubsystem = "scheduler"
 	// EventSubsystem - subsystem name used by event cache
 	EventSubsystem = "event"
+	// WebServiceSubsystem - subsystem name used by the REST handlers
+	WebServiceSubsystem = "webservice"
 	// replacement of invalid byte for prometheus metric names
 	MetricNameInvalidByteReplacement = '_'
 )
 
 var once sync.Once
 var m *Metrics
+var registrationLock sync.Mutex
+var registrationErrors []string
 
 type Metrics struct {
-	scheduler CoreSchedulerMetrics
-	queues    map[string]CoreQueueMetrics
-	event     CoreEventMetrics
-	lock      sync.RWMutex
+	scheduler  CoreSchedulerMetrics
+	queues     map[string]CoreQueueMetrics
+	event      CoreEventMetrics
+	webservice CoreWebServiceMetrics
+	lock       sync.RWMutex
 }
 
 type CoreQueueMetrics interface {
@@ -111,11 +122,51 @@ type CoreSchedulerMetrics interface {
 	SetNodeResourceUsage(resourceName string, rangeIdx int, value float64)
 	GetFailedNodes() (int, error)
 
+	// Metrics Ops related to the rolling window partition counters
+	SetPartitionWindowCount(partition, event, window string, value float64)
+
+	// Metrics Ops related to the partition reconciler
+	AddReconcileDiscrepancies(partition, kind string, value int)
+
+	// Metrics Ops related to the merged node status updates
+	AddMergedNodeUpdates(value int)
+
+	// Metrics Ops related to the RM update queue
+	SetRMUpdateQueueDepth(priority string, value int)
+	IncRMUpdateOverflow(result string)
+	GetRMUpdateOverflow(result string) (int, error)
+
+	// Metrics Ops related to applications with a user that could not be resolved
+	IncUnresolvedUser(partition, policy string)
+
+	// Metrics Ops related to rejected application submissions
+	IncApplicationRejected(partition, queue, reason string)
+	GetApplicationRejected(partition, queue, reason string) (int, error)
+
+	// Metrics Ops related to the queue hierarchy and dynamic queue creation
+	SetQueueTree(partition string, depth, size int)
+	AddDynamicQueuesCreated(partition string, value int)
+	IncDynamicQueuesLimited(partition string)
+	GetDynamicQueuesLimited(partition string) (int, error)
+
+	// Metrics Ops related to resource calculations that overflowed
+	IncResourceOverflow(resourceType, operation string)
+	GetResourceOverflow(resourceType, operation string) (int, error)
+
 	//latency change
 	ObserveSchedulingLatency(start time.Time)
 	ObserveNodeSortingLatency(start time.Time)
 	ObserveAppSortingLatency(start time.Time)
 	ObserveQueueSortingLatency(start time.Time)
+	ObserveReservationConversionLatency(start time.Time)
+	ObserveAllocationLatency(start time.Time)
+	ObserveConfirmationLatency(start time.Time)
+}
+
+// Declare the metrics ops for the REST handlers, the endpoint is the route pattern of the handler
+type CoreWebServiceMetrics interface {
+	ObserveRequest(endpoint, method string, code, size int, start time.Time)
+	GetRequests(endpoint, method string, code int) (int, error)
 }
 
 type CoreEventMetrics interface {
@@ -126,19 +177,41 @@ type CoreEventMetrics interface {
 	IncEventsStored()
 	IncEventsNotStored()
 	AddEventsCollected(collectedEvents int)
+	SetEventsBacklog(backlog int)
 }
 
 func init() {
 	once.Do(func() {
 		m = &Metrics{
-			scheduler: InitSchedulerMetrics(),
-			queues:    make(map[string]CoreQueueMetrics),
-			event:     initEventMetrics(),
-			lock:      sync.RWMutex{},
+			scheduler:  InitSchedulerMetrics(),
+			queues:     make(map[string]CoreQueueMetrics),
+			event:      initEventMetrics(),
+			webservice: initWebServiceMetrics(),
+			lock:       sync.RWMutex{},
 		}
+		resources.SetOverflowObserver(m.scheduler.IncResourceOverflow)
 	})
 }
 
+// Register the collector created at startup, a failure is logged and recorded for the readiness report.
+func registerCollector(collector prometheus.Collector) {
+	if err := prometheus.Register(collector); err != nil {
+		log.Logger().Warn("failed to register metrics collector", zap.Error(err))
+		registrationLock.Lock()
+		defer registrationLock.Unlock()
+		registrationErrors = append(registrationErrors, err.Error())
+	}
+}
+
+// Get the errors of the collectors that failed to register at startup.
+func GetRegistrationErrors() []string {
+	registrationLock.Lock()
+	defer registrationLock.Unlock()
+	errs := make([]string, len(registrationErrors))
+	copy(errs, registrationErrors)
+	return errs
+}
+
 func GetSchedulerMetrics() CoreSchedulerMetrics {
 	return m.scheduler
 }
@@ -158,6 +231,10 @@ func GetEventMetrics() CoreEventMetrics {
 	return m.event
 }
 
+func GetWebServiceMetrics() CoreWebServiceMetrics {
+	return m.webservice
+}
+
 // Format metric name based on the definition of metric name in prometheus, as per
 // https://prometheus.io/docs/concepts/data_model/#metric-names-and-labels
 func formatMetricName(metricName string) string {
@@ -35,6 +35,8 @@ type internalMetricsCollector struct {
 	ticker         *time.Ticker
 	stopped        chan bool
 	metricsHistory *history.InternalMetricsHistory
+	// optional source of the queue usage, queue history is not collected if not set
+	queueSource func() []*history.QueueUsage
 }
 
 func NewInternalMetricsCollector(hcInfo *history.InternalMetricsHistory) *internalMetricsCollector {
@@ -42,46 +44,65 @@ func NewInternalMetricsCollector(hcInfo *history.InternalMetricsHistory) *intern
 	ticker := time.NewTicker(tickerDefault)
 
 	return &internalMetricsCollector{
-		ticker,
-		finished,
-		hcInfo,
+		ticker:         ticker,
+		stopped:        finished,
+		metricsHistory: hcInfo,
 	}
 }
 
+// Set the source for the queue usage history, must be called before the service is started.
+func (u *internalMetricsCollector) SetQueueUsageSource(source func() []*history.QueueUsage) {
+	u.queueSource = source
+}
+
 func (u *internalMetricsCollector) StartService() {
 	go func() {
 		for {
 			select {
 			case <-u.stopped:
+				u.ticker.Stop()
 				return
 			case <-u.ticker.C:
-				log.Logger().Debug("Adding current status to historical partition data")
-
-				totalAppsRunning, err := m.scheduler.getTotalApplicationsRunning()
-				if err != nil {
-					log.Logger().Warn("Could not encode totalApplications metric.", zap.Error(err))
-					totalAppsRunning = -1
-				}
-				allocatedContainers, err := m.scheduler.getAllocatedContainers()
-				if err != nil {
-					log.Logger().Warn("Could not encode allocatedContainers metric.", zap.Error(err))
-				}
-				releasedContainers, err := m.scheduler.getReleasedContainers()
-				if err != nil {
-					log.Logger().Warn("Could not encode releasedContainers metric.", zap.Error(err))
-				}
-				totalContainersRunning := allocatedContainers - releasedContainers
-				if totalContainersRunning < 0 {
-					log.Logger().Warn("Could not calculate the totalContainersRunning.",
-						zap.Int("allocatedContainers", allocatedContainers),
-						zap.Int("releasedContainers", releasedContainers))
-				}
-				u.metricsHistory.Store(totalAppsRunning, totalContainersRunning)
+				u.collect()
 			}
 		}
 	}()
 }
 
+// Add the current status to the history.
+func (u *internalMetricsCollector) collect() {
+	log.Logger().Debug("Adding current status to historical partition data")
+
+	totalAppsRunning, err := m.scheduler.getTotalApplicationsRunning()
+	if err != nil {
+		log.Logger().Warn("Could not encode totalApplications metric.", zap.Error(err))
+		totalAppsRunning = -1
+	}
+	allocatedContainers, err := m.scheduler.getAllocatedContainers()
+	if err != nil {
+		log.Logger().Warn("Could not encode allocatedContainers metric.", zap.Error(err))
+	}
+	releasedContainers, err := m.scheduler.getReleasedContainers()
+	if err != nil {
+		log.Logger().Warn("Could not encode releasedContainers metric.", zap.Error(err))
+	}
+	totalContainersRunning := allocatedContainers - releasedContainers
+	if totalContainersRunning < 0 {
+		log.Logger().Warn("Could not calculate the totalContainersRunning.",
+			zap.Int("allocatedContainers", allocatedContainers),
+			zap.Int("releasedContainers", releasedContainers))
+	}
+	u.metricsHistory.Store(totalAppsRunning, totalContainersRunning)
+	if u.queueSource != nil {
+		u.metricsHistory.StoreQueues(u.queueSource())
+	}
+}
+
+// Add the current status to the history without waiting for the next tick.
+func (u *internalMetricsCollector) Flush() {
+	u.collect()
+}
+
 func (u *internalMetricsCollector) Stop() {
 	u.stopped <- true
 }
@@ -20,12 +20,17 @@ package metrics
 
 import (
 	"crypto/rand"
+	"math"
 	"testing"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/prometheus/common/model"
 	"go.uber.org/zap"
 	"gotest.tools/assert"
 
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
 	"github.com/apache/incubator-yunikorn-core/pkg/log"
 )
 
@@ -56,3 +61,32 @@ func generateRandomString(len int) string {
 	}
 	return string(randomBytes)
 }
+
+func TestAllocationLatency(t *testing.T) {
+	sm := GetSchedulerMetrics().(*SchedulerMetrics)
+	allocated := getHistogramCount(t, sm.allocationLatency)
+	confirmed := getHistogramCount(t, sm.confirmationLatency)
+	start := time.Now().Add(-time.Second)
+	sm.ObserveAllocationLatency(start)
+	sm.ObserveConfirmationLatency(start)
+	assert.Equal(t, getHistogramCount(t, sm.allocationLatency), allocated+1, "allocation latency not observed")
+	assert.Equal(t, getHistogramCount(t, sm.confirmationLatency), confirmed+1, "confirmation latency not observed")
+}
+
+func TestResourceOverflow(t *testing.T) {
+	sm := GetSchedulerMetrics()
+	before, err := sm.GetResourceOverflow("overflow", resources.OperationAdd)
+	assert.NilError(t, err, "failed to read overflow counter")
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"overflow": math.MaxInt64})
+	res.AddTo(res)
+	after, err := sm.GetResourceOverflow("overflow", resources.OperationAdd)
+	assert.NilError(t, err, "failed to read overflow counter")
+	assert.Equal(t, after, before+1, "overflow not counted")
+}
+
+func getHistogramCount(t *testing.T, histogram prometheus.Histogram) uint64 {
+	metric := &dto.Metric{}
+	err := histogram.Write(metric)
+	assert.NilError(t, err, "failed to read histogram")
+	return metric.Histogram.GetSampleCount()
+}
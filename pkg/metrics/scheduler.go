@@ -56,6 +56,20 @@ type SchedulerMetrics struct {
 	nodeSortingLatency         prometheus.Histogram
 	appSortingLatency          prometheus.Histogram
 	queueSortingLatency        prometheus.Histogram
+	reservationConversion      prometheus.Histogram
+	allocationLatency          prometheus.Histogram
+	confirmationLatency        prometheus.Histogram
+	partitionWindowCounts      *prometheus.GaugeVec
+	reconcileDiscrepancies     *prometheus.CounterVec
+	mergedNodeUpdates          prometheus.Counter
+	rmUpdateQueueDepth         *prometheus.GaugeVec
+	rmUpdateOverflow           *prometheus.CounterVec
+	unresolvedUsers            *prometheus.CounterVec
+	applicationRejections      *prometheus.CounterVec
+	queueTreeDepth             *prometheus.GaugeVec
+	queueTreeSize              *prometheus.GaugeVec
+	dynamicQueuesCreated       *prometheus.CounterVec
+	resourceOverflows          *prometheus.CounterVec
 	lock                       sync.RWMutex
 }
 
@@ -155,6 +169,127 @@ func InitSchedulerMetrics() *SchedulerMetrics {
 			Buckets:   prometheus.ExponentialBuckets(0.0001, 10, 6), //start from 0.1ms
 		},
 	)
+	s.reservationConversion = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Subsystem: SchedulerSubsystem,
+			Name:      "reservation_conversion_latency_seconds",
+			Help:      "Time between a reservation being made and it being converted into an allocation, in seconds.",
+			Buckets:   prometheus.ExponentialBuckets(0.1, 2, 12), //start from 100ms
+		},
+	)
+
+	s.allocationLatency = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Subsystem: SchedulerSubsystem,
+			Name:      "allocation_latency_seconds",
+			Help:      "Time between an ask arriving and an allocation being proposed for it, in seconds.",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 4, 12), //start from 1ms
+		},
+	)
+	s.confirmationLatency = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Subsystem: SchedulerSubsystem,
+			Name:      "confirmation_latency_seconds",
+			Help:      "Time between an allocation being proposed and it being confirmed to the RM, in seconds.",
+			Buckets:   prometheus.ExponentialBuckets(0.0001, 10, 6), //start from 0.1ms
+		},
+	)
+
+	// Rolling window event counts per partition
+	s.partitionWindowCounts = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Subsystem: SchedulerSubsystem,
+			Name:      "partition_window_events",
+			Help:      "Number of events in a partition in the last window. Events include `allocation`, `release`, `rejection` and `preemption`, windows are `1m`, `5m` and `1h`.",
+		}, []string{"partition", "event", "window"})
+
+	// Discrepancies found by the partition reconciler
+	s.reconcileDiscrepancies = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: SchedulerSubsystem,
+			Name:      "reconcile_discrepancy_total",
+			Help:      "Total number of discrepancies found by the partition reconciler. Types include `nodeSnapshot`, `allocationCount`, `nodeAllocation`, `appAllocation` and `queueAllocated`.",
+		}, []string{"partition", "type"})
+
+	// Node status updates merged in the event handling
+	s.mergedNodeUpdates = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: SchedulerSubsystem,
+			Name:      "node_update_merged_total",
+			Help:      "Total number of node status updates merged into a later update for the same node.",
+		})
+
+	// RM updates waiting to be passed on to the scheduler
+	s.rmUpdateQueueDepth = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Subsystem: SchedulerSubsystem,
+			Name:      "rm_update_queue_depth",
+			Help:      "Number of RM updates waiting to be passed on to the scheduler. Priorities include `high` and `low`.",
+		}, []string{"priority"})
+	s.rmUpdateOverflow = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: SchedulerSubsystem,
+			Name:      "rm_update_overflow_total",
+			Help:      "Total number of RM updates that were not queued as received. Results include `replaced` for node status updates replaced by a newer status of the same nodes and `rejected` for updates the RM must retry because the RM update queue is full.",
+		}, []string{"result"})
+
+	// Applications with a user that could not be resolved
+	s.unresolvedUsers = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: SchedulerSubsystem,
+			Name:      "unresolved_user_total",
+			Help:      "Total number of applications with a user that could not be resolved. Policies include `reject`, `quarantine` and `anonymous`.",
+		}, []string{"partition", "policy"})
+
+	// Application submissions rejected by the scheduler
+	s.applicationRejections = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: SchedulerSubsystem,
+			Name:      "application_rejected_total",
+			Help:      "Total number of rejected application submissions per queue. Reasons include `partition`, `user`, `duplicate_id`, `placement`, `acl`, `quota` and `invalid_config`.",
+		}, []string{"partition", "queue", "reason"})
+
+	// Queue hierarchy per partition
+	s.queueTreeDepth = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Subsystem: SchedulerSubsystem,
+			Name:      "queue_tree_depth",
+			Help:      "Number of levels below the root queue in the queue hierarchy of the partition.",
+		}, []string{"partition"})
+	s.queueTreeSize = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Subsystem: SchedulerSubsystem,
+			Name:      "queue_total",
+			Help:      "Total number of queues in the queue hierarchy of the partition.",
+		}, []string{"partition"})
+	s.dynamicQueuesCreated = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: SchedulerSubsystem,
+			Name:      "dynamic_queue_created_total",
+			Help:      "Total number of queues created by the placement rules. Result of the attempt includes `created` and `limited`.",
+		}, []string{"partition", "result"})
+
+	// Resource calculations that overflowed
+	s.resourceOverflows = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: SchedulerSubsystem,
+			Name:      "resource_overflow_total",
+			Help:      "Total number of resource calculations that overflowed and were limited to the minimum or maximum quantity, by resource type. Operations include `add`, `subtract` and `multiply`.",
+		}, []string{"resource", "operation"})
 
 	// Register metrics
 	var metricsList = []prometheus.Collector{
@@ -164,15 +299,27 @@ func InitSchedulerMetrics() *SchedulerMetrics {
 		s.nodeSortingLatency,
 		s.queueSortingLatency,
 		s.appSortingLatency,
+		s.reservationConversion,
+		s.allocationLatency,
+		s.confirmationLatency,
 		s.totalApplicationsRunning,
 		s.totalApplicationsCompleted,
 		s.totalNodesActive,
 		s.totalNodesFailed,
+		s.partitionWindowCounts,
+		s.reconcileDiscrepancies,
+		s.mergedNodeUpdates,
+		s.rmUpdateQueueDepth,
+		s.rmUpdateOverflow,
+		s.unresolvedUsers,
+		s.applicationRejections,
+		s.queueTreeDepth,
+		s.queueTreeSize,
+		s.dynamicQueuesCreated,
+		s.resourceOverflows,
 	}
 	for _, metric := range metricsList {
-		if err := prometheus.Register(metric); err != nil {
-			log.Logger().Warn("failed to register metrics collector", zap.Error(err))
-		}
+		registerCollector(metric)
 	}
 	return s
 }
@@ -201,6 +348,18 @@ func (m *SchedulerMetrics) ObserveQueueSortingLatency(start time.Time) {
 	m.queueSortingLatency.Observe(SinceInSeconds(start))
 }
 
+func (m *SchedulerMetrics) ObserveReservationConversionLatency(start time.Time) {
+	m.reservationConversion.Observe(SinceInSeconds(start))
+}
+
+func (m *SchedulerMetrics) ObserveAllocationLatency(start time.Time) {
+	m.allocationLatency.Observe(SinceInSeconds(start))
+}
+
+func (m *SchedulerMetrics) ObserveConfirmationLatency(start time.Time) {
+	m.confirmationLatency.Observe(SinceInSeconds(start))
+}
+
 // Below is to define and implement all the metrics operation for Prometheus
 
 func (m *SchedulerMetrics) IncAllocatedContainer() {
@@ -377,6 +536,87 @@ func (m *SchedulerMetrics) GetFailedNodes() (int, error) {
 	return -1, err
 }
 
+func (m *SchedulerMetrics) SetPartitionWindowCount(partition, event, window string, value float64) {
+	m.partitionWindowCounts.With(prometheus.Labels{"partition": partition, "event": event, "window": window}).Set(value)
+}
+
+func (m *SchedulerMetrics) AddReconcileDiscrepancies(partition, kind string, value int) {
+	m.reconcileDiscrepancies.With(prometheus.Labels{"partition": partition, "type": kind}).Add(float64(value))
+}
+
+func (m *SchedulerMetrics) AddMergedNodeUpdates(value int) {
+	m.mergedNodeUpdates.Add(float64(value))
+}
+
+func (m *SchedulerMetrics) SetRMUpdateQueueDepth(priority string, value int) {
+	m.rmUpdateQueueDepth.With(prometheus.Labels{"priority": priority}).Set(float64(value))
+}
+
+func (m *SchedulerMetrics) IncRMUpdateOverflow(result string) {
+	m.rmUpdateOverflow.With(prometheus.Labels{"result": result}).Inc()
+}
+
+func (m *SchedulerMetrics) GetRMUpdateOverflow(result string) (int, error) {
+	metricDto := &dto.Metric{}
+	err := m.rmUpdateOverflow.With(prometheus.Labels{"result": result}).Write(metricDto)
+	if err == nil {
+		return int(*metricDto.Counter.Value), nil
+	}
+	return -1, err
+}
+
+func (m *SchedulerMetrics) IncUnresolvedUser(partition, policy string) {
+	m.unresolvedUsers.With(prometheus.Labels{"partition": partition, "policy": policy}).Inc()
+}
+
+func (m *SchedulerMetrics) IncApplicationRejected(partition, queue, reason string) {
+	m.applicationRejections.With(prometheus.Labels{"partition": partition, "queue": queue, "reason": reason}).Inc()
+}
+
+func (m *SchedulerMetrics) GetApplicationRejected(partition, queue, reason string) (int, error) {
+	metricDto := &dto.Metric{}
+	err := m.applicationRejections.With(prometheus.Labels{"partition": partition, "queue": queue, "reason": reason}).Write(metricDto)
+	if err == nil {
+		return int(*metricDto.Counter.Value), nil
+	}
+	return -1, err
+}
+
+func (m *SchedulerMetrics) SetQueueTree(partition string, depth, size int) {
+	m.queueTreeDepth.With(prometheus.Labels{"partition": partition}).Set(float64(depth))
+	m.queueTreeSize.With(prometheus.Labels{"partition": partition}).Set(float64(size))
+}
+
+func (m *SchedulerMetrics) AddDynamicQueuesCreated(partition string, value int) {
+	m.dynamicQueuesCreated.With(prometheus.Labels{"partition": partition, "result": "created"}).Add(float64(value))
+}
+
+func (m *SchedulerMetrics) IncDynamicQueuesLimited(partition string) {
+	m.dynamicQueuesCreated.With(prometheus.Labels{"partition": partition, "result": "limited"}).Inc()
+}
+
+func (m *SchedulerMetrics) GetDynamicQueuesLimited(partition string) (int, error) {
+	metricDto := &dto.Metric{}
+	err := m.dynamicQueuesCreated.With(prometheus.Labels{"partition": partition, "result": "limited"}).Write(metricDto)
+	if err == nil {
+		return int(*metricDto.Counter.Value), nil
+	}
+	return -1, err
+}
+
+func (m *SchedulerMetrics) IncResourceOverflow(resourceType, operation string) {
+	m.resourceOverflows.With(prometheus.Labels{"resource": resourceType, "operation": operation}).Inc()
+}
+
+func (m *SchedulerMetrics) GetResourceOverflow(resourceType, operation string) (int, error) {
+	metricDto := &dto.Metric{}
+	err := m.resourceOverflows.With(prometheus.Labels{"resource": resourceType, "operation": operation}).Write(metricDto)
+	if err == nil {
+		return int(*metricDto.Counter.Value), nil
+	}
+	return -1, err
+}
+
 func (m *SchedulerMetrics) SetNodeResourceUsage(resourceName string, rangeIdx int, value float64) {
 	m.lock.Lock()
 	defer m.lock.Unlock()
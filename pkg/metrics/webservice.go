@@ -0,0 +1,87 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+type webServiceMetrics struct {
+	requests     *prometheus.CounterVec
+	latency      *prometheus.HistogramVec
+	responseSize *prometheus.HistogramVec
+}
+
+func initWebServiceMetrics() CoreWebServiceMetrics {
+	metrics := &webServiceMetrics{}
+
+	metrics.requests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: WebServiceSubsystem,
+			Name:      "requests_total",
+			Help:      "Total number of REST requests, by endpoint, method and response code.",
+		}, []string{"endpoint", "method", "code"})
+	metrics.latency = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Subsystem: WebServiceSubsystem,
+			Name:      "request_latency_seconds",
+			Help:      "Latency of the REST requests, by endpoint and method.",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 4, 10), //start from 1ms
+		}, []string{"endpoint", "method"})
+	metrics.responseSize = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Subsystem: WebServiceSubsystem,
+			Name:      "response_size_bytes",
+			Help:      "Size of the REST responses as sent to the client, by endpoint and method.",
+			Buckets:   prometheus.ExponentialBuckets(128, 4, 10), //start from 128 bytes
+		}, []string{"endpoint", "method"})
+
+	var metricsList = []prometheus.Collector{
+		metrics.requests,
+		metrics.latency,
+		metrics.responseSize,
+	}
+	for _, metric := range metricsList {
+		registerCollector(metric)
+	}
+	return metrics
+}
+
+func (wm *webServiceMetrics) ObserveRequest(endpoint, method string, code, size int, start time.Time) {
+	wm.requests.With(prometheus.Labels{"endpoint": endpoint, "method": method, "code": strconv.Itoa(code)}).Inc()
+	labels := prometheus.Labels{"endpoint": endpoint, "method": method}
+	wm.latency.With(labels).Observe(SinceInSeconds(start))
+	wm.responseSize.With(labels).Observe(float64(size))
+}
+
+func (wm *webServiceMetrics) GetRequests(endpoint, method string, code int) (int, error) {
+	metricDto := &dto.Metric{}
+	err := wm.requests.With(prometheus.Labels{"endpoint": endpoint, "method": method, "code": strconv.Itoa(code)}).Write(metricDto)
+	if err == nil {
+		return int(*metricDto.Counter.Value), nil
+	}
+	return -1, err
+}
@@ -19,7 +19,10 @@
 package plugins
 
 import (
+	"go.uber.org/zap"
+
 	"github.com/apache/incubator-yunikorn-core/pkg/log"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/policies"
 )
 
 var plugins SchedulerPlugins
@@ -48,10 +51,68 @@ func RegisterSchedulerPlugin(plugin interface{}) {
 		log.Logger().Info("register scheduler plugin: ContainerSchedulingStateUpdater")
 		plugins.schedulingStateUpdater = t
 	}
+	if t, ok := plugin.(NodeSortingPlugin); ok {
+		log.Logger().Info("register scheduler plugin: NodeSortingPlugin")
+		for name, less := range t.NodeSortingPolicies() {
+			if err := policies.RegisterNodeSortingPolicy(name, less); err != nil {
+				log.Logger().Warn("node sorting policy from plugin not registered",
+					zap.String("policyName", name),
+					zap.Error(err))
+			}
+		}
+	}
 	if t, ok := plugin.(ConfigurationPlugin); ok {
 		log.Logger().Info("register scheduler plugin: ConfigMapPlugin")
 		plugins.configPlugin = t
 	}
+	if t, ok := plugin.(GroupResolverPlugin); ok {
+		log.Logger().Info("register scheduler plugin: GroupResolverPlugin")
+		plugins.groupResolverPlugin = t
+	}
+	if t, ok := plugin.(GroupHierarchyPlugin); ok {
+		log.Logger().Info("register scheduler plugin: GroupHierarchyPlugin")
+		plugins.groupHierarchyPlugin = t
+	}
+	if t, ok := plugin.(AuthenticationPlugin); ok {
+		log.Logger().Info("register scheduler plugin: AuthenticationPlugin")
+		plugins.authenticationPlugin = t
+	}
+	if t, ok := plugin.(ApplicationUsagePlugin); ok {
+		log.Logger().Info("register scheduler plugin: ApplicationUsagePlugin")
+		plugins.appUsagePlugin = t
+	}
+	if t, ok := plugin.(PreemptionPolicyPlugin); ok {
+		log.Logger().Info("register scheduler plugin: PreemptionPolicyPlugin")
+		plugins.preemptionPlugin = t
+	}
+}
+
+// Get the names of the registered plugin types, in a fixed order.
+func GetRegisteredPlugins() []string {
+	plugins.RLock()
+	defer plugins.RUnlock()
+
+	registered := make([]string, 0)
+	for _, plugin := range []struct {
+		name       string
+		registered bool
+	}{
+		{"PredicatesPlugin", plugins.predicatesPlugin != nil},
+		{"ReconcilePlugin", plugins.reconcilePlugin != nil},
+		{"EventPlugin", plugins.eventPlugin != nil},
+		{"ContainerSchedulingStateUpdater", plugins.schedulingStateUpdater != nil},
+		{"ConfigurationPlugin", plugins.configPlugin != nil},
+		{"GroupResolverPlugin", plugins.groupResolverPlugin != nil},
+		{"GroupHierarchyPlugin", plugins.groupHierarchyPlugin != nil},
+		{"AuthenticationPlugin", plugins.authenticationPlugin != nil},
+		{"ApplicationUsagePlugin", plugins.appUsagePlugin != nil},
+		{"PreemptionPolicyPlugin", plugins.preemptionPlugin != nil},
+	} {
+		if plugin.registered {
+			registered = append(registered, plugin.name)
+		}
+	}
+	return registered
 }
 
 func GetPredicatesPlugin() PredicatesPlugin {
@@ -88,3 +149,76 @@ func GetConfigPlugin() ConfigurationPlugin {
 
 	return plugins.configPlugin
 }
+
+func GetGroupResolverPlugin() GroupResolverPlugin {
+	plugins.RLock()
+	defer plugins.RUnlock()
+
+	return plugins.groupResolverPlugin
+}
+
+func GetGroupHierarchyPlugin() GroupHierarchyPlugin {
+	plugins.RLock()
+	defer plugins.RUnlock()
+
+	return plugins.groupHierarchyPlugin
+}
+
+func GetAuthenticationPlugin() AuthenticationPlugin {
+	plugins.RLock()
+	defer plugins.RUnlock()
+
+	return plugins.authenticationPlugin
+}
+
+func GetApplicationUsagePlugin() ApplicationUsagePlugin {
+	plugins.RLock()
+	defer plugins.RUnlock()
+
+	return plugins.appUsagePlugin
+}
+
+// Return the registered preemption policy plugin, the default policy if no plugin is registered.
+func GetPreemptionPolicyPlugin() PreemptionPolicyPlugin {
+	plugins.RLock()
+	defer plugins.RUnlock()
+
+	if plugins.preemptionPlugin == nil {
+		return &DefaultPreemptionPolicy{}
+	}
+	return plugins.preemptionPlugin
+}
+
+// Remove the registered authentication plugin.
+// The REST API falls back to the static tokens from the configuration.
+func UnregisterAuthenticationPlugin() {
+	plugins.Lock()
+	defer plugins.Unlock()
+
+	plugins.authenticationPlugin = nil
+}
+
+// Remove the registered application usage plugin.
+// Idle application detection is disabled without the plugin.
+func UnregisterApplicationUsagePlugin() {
+	plugins.Lock()
+	defer plugins.Unlock()
+
+	plugins.appUsagePlugin = nil
+}
+
+// Remove the registered predicates plugin, all predicate checks pass without the plugin.
+func UnregisterPredicatesPlugin() {
+	plugins.Lock()
+	defer plugins.Unlock()
+
+	plugins.predicatesPlugin = nil
+}
+
+// Remove the registered preemption policy plugin, the default policy is used.
+func UnregisterPreemptionPolicyPlugin() {
+	plugins.Lock()
+	defer plugins.Unlock()
+
+	plugins.preemptionPlugin = nil
+}
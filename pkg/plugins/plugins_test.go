@@ -23,6 +23,8 @@ import (
 
 	"gotest.tools/assert"
 
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/policies"
 	"github.com/apache/incubator-yunikorn-scheduler-interface/lib/go/si"
 )
 
@@ -46,6 +48,24 @@ func (f FakeConfigPlugin) UpdateConfiguration(args *si.UpdateConfigurationReques
 	return nil
 }
 
+type fakeNodeSortingPlugin struct{}
+
+func (f *fakeNodeSortingPlugin) NodeSortingPolicies() map[string]policies.NodeLessFunc {
+	return map[string]policies.NodeLessFunc{
+		"plugin": func(l, r policies.SortableNode) bool { return false },
+		"fair":   func(l, r policies.SortableNode) bool { return false },
+	}
+}
+
+func TestRegisterNodeSortingPlugin(t *testing.T) {
+	plugins = SchedulerPlugins{}
+	defer policies.UnregisterNodeSortingPolicy("plugin")
+	RegisterSchedulerPlugin(&fakeNodeSortingPlugin{})
+	assert.Assert(t, policies.GetNodeSortingFunc("plugin") != nil, "plugin node sorting policy should have been registered")
+	assert.Assert(t, policies.GetNodeSortingFunc("fair") == nil, "built-in node sorting policy should not have been replaced")
+	assert.Assert(t, GetPredicatesPlugin() == nil, "predicates plugin should not have been registered")
+}
+
 func TestRegisterPlugins(t *testing.T) {
 	plugins = SchedulerPlugins{}
 	RegisterSchedulerPlugin(&fakePredicatePluginImpl{})
@@ -63,3 +83,68 @@ func TestRegisterConfigPlugin(t *testing.T) {
 	assert.Assert(t, GetContainerSchedulingStateUpdaterPlugin() == nil, "volume plugin should not have been registered")
 	assert.Assert(t, GetConfigPlugin() != nil, "config plugin should have been registered")
 }
+
+type fakeGroupResolverPlugin struct{}
+
+func (f *fakeGroupResolverPlugin) ResolveGroups(userName string) ([]string, error) {
+	return []string{userName}, nil
+}
+
+func (f *fakeGroupResolverPlugin) GetParentGroups(group string) []string {
+	return nil
+}
+
+func TestRegisterGroupResolverPlugin(t *testing.T) {
+	plugins = SchedulerPlugins{}
+	RegisterSchedulerPlugin(&fakeGroupResolverPlugin{})
+	assert.Assert(t, GetGroupResolverPlugin() != nil, "group resolver plugin should have been registered")
+	assert.Assert(t, GetGroupHierarchyPlugin() != nil, "group hierarchy plugin should have been registered")
+	assert.Assert(t, GetPredicatesPlugin() == nil, "predicates plugin should not have been registered")
+}
+
+type fakeAuthenticationPlugin struct{}
+
+func (f *fakeAuthenticationPlugin) Authenticate(token string) (string, []string, error) {
+	return token, nil, nil
+}
+
+func TestRegisterAuthenticationPlugin(t *testing.T) {
+	plugins = SchedulerPlugins{}
+	RegisterSchedulerPlugin(&fakeAuthenticationPlugin{})
+	assert.Assert(t, GetAuthenticationPlugin() != nil, "authentication plugin should have been registered")
+	assert.Assert(t, GetGroupResolverPlugin() == nil, "group resolver plugin should not have been registered")
+	UnregisterAuthenticationPlugin()
+	assert.Assert(t, GetAuthenticationPlugin() == nil, "authentication plugin should have been removed")
+}
+
+type fakeApplicationUsagePlugin struct{}
+
+func (f *fakeApplicationUsagePlugin) IsApplicationIdle(applicationID string) bool {
+	return true
+}
+
+func TestRegisterApplicationUsagePlugin(t *testing.T) {
+	plugins = SchedulerPlugins{}
+	RegisterSchedulerPlugin(&fakeApplicationUsagePlugin{})
+	assert.Assert(t, GetApplicationUsagePlugin() != nil, "application usage plugin should have been registered")
+	assert.Assert(t, GetAuthenticationPlugin() == nil, "authentication plugin should not have been registered")
+}
+
+type fakePreemptionPolicyPlugin struct{}
+
+func (f *fakePreemptionPolicyPlugin) SelectVictims(candidates []*PreemptionCandidate, askQueue string, required *resources.Resource) []*PreemptionCandidate {
+	return nil
+}
+
+func TestRegisterPreemptionPolicyPlugin(t *testing.T) {
+	plugins = SchedulerPlugins{}
+	_, ok := GetPreemptionPolicyPlugin().(*DefaultPreemptionPolicy)
+	assert.Assert(t, ok, "default policy should be returned without a plugin")
+	RegisterSchedulerPlugin(&fakePreemptionPolicyPlugin{})
+	_, ok = GetPreemptionPolicyPlugin().(*fakePreemptionPolicyPlugin)
+	assert.Assert(t, ok, "preemption policy plugin should have been registered")
+	assert.DeepEqual(t, GetRegisteredPlugins(), []string{"PreemptionPolicyPlugin"})
+	UnregisterPreemptionPolicyPlugin()
+	_, ok = GetPreemptionPolicyPlugin().(*DefaultPreemptionPolicy)
+	assert.Assert(t, ok, "default policy should be returned after unregister")
+}
@@ -0,0 +1,64 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package plugins
+
+import (
+	"sort"
+	"time"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+)
+
+// An allocation that can be preempted, passed to the preemption policy.
+type PreemptionCandidate struct {
+	UUID          string
+	ApplicationID string
+	QueuePath     string
+	NodeID        string
+	Priority      int32
+	CreateTime    time.Time
+	Placeholder   bool
+	Tags          map[string]string
+	Resource      *resources.Resource
+}
+
+// The preemption policy used when no PreemptionPolicyPlugin is registered.
+// The candidates with the lowest priority are preempted first, allocations with the same priority are preempted
+// youngest first to lose the least amount of work. Placeholders are never preempted: they are replaced by the
+// real allocations of their application.
+type DefaultPreemptionPolicy struct{}
+
+func (p *DefaultPreemptionPolicy) SelectVictims(candidates []*PreemptionCandidate, askQueue string, required *resources.Resource) []*PreemptionCandidate {
+	victims := make([]*PreemptionCandidate, 0, len(candidates))
+	for _, candidate := range candidates {
+		if !candidate.Placeholder {
+			victims = append(victims, candidate)
+		}
+	}
+	sort.SliceStable(victims, func(i, j int) bool {
+		if victims[i].Priority != victims[j].Priority {
+			return victims[i].Priority < victims[j].Priority
+		}
+		if !victims[i].CreateTime.Equal(victims[j].CreateTime) {
+			return victims[i].CreateTime.After(victims[j].CreateTime)
+		}
+		return victims[i].UUID < victims[j].UUID
+	})
+	return victims
+}
@@ -0,0 +1,46 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package plugins
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+func TestDefaultPreemptionPolicy(t *testing.T) {
+	now := time.Now()
+	candidates := []*PreemptionCandidate{
+		{UUID: "old-low", Priority: 1, CreateTime: now.Add(-time.Hour)},
+		{UUID: "high", Priority: 10, CreateTime: now},
+		{UUID: "placeholder", Priority: 0, CreateTime: now, Placeholder: true},
+		{UUID: "new-low-b", Priority: 1, CreateTime: now},
+		{UUID: "new-low-a", Priority: 1, CreateTime: now},
+	}
+	policy := &DefaultPreemptionPolicy{}
+	victims := policy.SelectVictims(candidates, "root.queue", nil)
+	order := make([]string, 0, len(victims))
+	for _, victim := range victims {
+		order = append(order, victim.UUID)
+	}
+	assert.DeepEqual(t, order, []string{"new-low-a", "new-low-b", "old-low", "high"})
+	assert.Equal(t, candidates[0].UUID, "old-low", "candidates should not be reordered")
+	assert.Equal(t, len(policy.SelectVictims(nil, "root.queue", nil)), 0, "no candidates should return no victims")
+}
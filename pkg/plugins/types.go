@@ -21,6 +21,8 @@ package plugins
 import (
 	"sync"
 
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/policies"
 	"github.com/apache/incubator-yunikorn-scheduler-interface/lib/go/si"
 )
 
@@ -30,6 +32,11 @@ type SchedulerPlugins struct {
 	eventPlugin            EventPlugin
 	schedulingStateUpdater ContainerSchedulingStateUpdater
 	configPlugin           ConfigurationPlugin
+	groupResolverPlugin    GroupResolverPlugin
+	groupHierarchyPlugin   GroupHierarchyPlugin
+	authenticationPlugin   AuthenticationPlugin
+	appUsagePlugin         ApplicationUsagePlugin
+	preemptionPlugin       PreemptionPolicyPlugin
 
 	sync.RWMutex
 }
@@ -64,6 +71,50 @@ type ContainerSchedulingStateUpdater interface {
 	Update(request *si.UpdateContainerSchedulingStateRequest)
 }
 
+// Provides custom node sorting policies that can be selected by name in the partition configuration.
+// The policies are added to the node sorting registry when the plugin is registered.
+type NodeSortingPlugin interface {
+	// Return the sort functions keyed on the policy name used in the configuration.
+	NodeSortingPolicies() map[string]policies.NodeLessFunc
+}
+
+// Resolves the groups of a user when the groups are not provided by the RM on submission.
+// Used by the group placement rule, results are cached in the core.
+type GroupResolverPlugin interface {
+	// Return the groups the user is a member of, the first group is the primary group.
+	ResolveGroups(userName string) ([]string, error)
+}
+
+// Provides the group hierarchy for nested group support in the ACL checks.
+// A user that is a member of a group is also a member of all the parent groups.
+type GroupHierarchyPlugin interface {
+	// Return the direct parent groups of the group.
+	GetParentGroups(group string) []string
+}
+
+// Authenticates the callers of the REST API endpoints that change the scheduler state.
+// When registered the plugin replaces the static tokens from the configuration.
+type AuthenticationPlugin interface {
+	// Validate the bearer token and return the user and groups the token belongs to.
+	// An error is returned if the token is not valid.
+	Authenticate(token string) (string, []string, error)
+}
+
+// Reports the usage of the applications as seen by the RM. An application that holds allocations and has had no new
+// asks or releases for the idle timeout is only considered idle when the RM also flags its usage as idle.
+type ApplicationUsagePlugin interface {
+	// Return true if the RM considers the usage of the allocations of the application idle.
+	IsApplicationIdle(applicationID string) bool
+}
+
+// Selects the allocations that are preempted to make room for an ask, replaces the DefaultPreemptionPolicy.
+// The candidates are the allocations on a node that the asking queue is allowed to preempt.
+type PreemptionPolicyPlugin interface {
+	// Return the victims in the order they must be preempted. The scheduler preempts victims from the start of the
+	// list until the required resources are released. Candidates left out of the list are never preempted.
+	SelectVictims(candidates []*PreemptionCandidate, askQueue string, required *resources.Resource) []*PreemptionCandidate
+}
+
 type ConfigurationPlugin interface {
 	UpdateConfiguration(args *si.UpdateConfigurationRequest) *si.UpdateConfigurationResponse
 }
@@ -0,0 +1,108 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package readiness
+
+import (
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/log"
+	"github.com/apache/incubator-yunikorn-core/pkg/webservice/dao"
+)
+
+// Status of a subsystem in the readiness report.
+// A disabled subsystem is not started in this instance and does not affect the readiness.
+const (
+	StatusReady    = "Ready"
+	StatusNotReady = "NotReady"
+	StatusDisabled = "Disabled"
+)
+
+// Check the subsystem, returns the status and a message explaining the status.
+type Check func() (status string, message string)
+
+type subsystem struct {
+	name  string
+	check Check
+}
+
+var (
+	subsystems []subsystem
+	lock       sync.RWMutex
+)
+
+// Register the check for the subsystem, replaces an earlier check for the same subsystem.
+// The subsystems are reported in the order they were first registered.
+func Register(name string, check Check) {
+	lock.Lock()
+	defer lock.Unlock()
+	for i := range subsystems {
+		if subsystems[i].name == name {
+			subsystems[i].check = check
+			return
+		}
+	}
+	subsystems = append(subsystems, subsystem{name: name, check: check})
+}
+
+// Remove all registered checks.
+func Reset() {
+	lock.Lock()
+	defer lock.Unlock()
+	subsystems = nil
+}
+
+// Run all checks and build the report. The report is ready if at least one subsystem is registered and all
+// subsystems that are not disabled are ready.
+func GetReport() *dao.ReadinessDAOInfo {
+	lock.RLock()
+	defer lock.RUnlock()
+	report := &dao.ReadinessDAOInfo{
+		Ready:      len(subsystems) != 0,
+		Subsystems: make([]dao.SubsystemReadinessInfo, 0, len(subsystems)),
+	}
+	for _, sub := range subsystems {
+		status, message := sub.check()
+		if status != StatusReady && status != StatusDisabled {
+			report.Ready = false
+		}
+		report.Subsystems = append(report.Subsystems, dao.SubsystemReadinessInfo{
+			Name:    sub.name,
+			Status:  status,
+			Message: message,
+		})
+	}
+	return report
+}
+
+// Log the report, one line per subsystem.
+func LogReport() *dao.ReadinessDAOInfo {
+	report := GetReport()
+	for _, sub := range report.Subsystems {
+		log.Logger().Info("readiness check",
+			zap.String("subsystem", sub.Name),
+			zap.String("status", sub.Status),
+			zap.String("message", sub.Message))
+	}
+	log.Logger().Info("readiness report",
+		zap.Bool("ready", report.Ready),
+		zap.Int("subsystems", len(report.Subsystems)))
+	return report
+}
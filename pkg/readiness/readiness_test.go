@@ -0,0 +1,52 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package readiness
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestGetReport(t *testing.T) {
+	Reset()
+	defer Reset()
+	assert.Assert(t, !GetReport().Ready, "report without subsystems should not be ready")
+
+	status := StatusNotReady
+	Register("first", func() (string, string) { return status, "first message" })
+	Register("second", func() (string, string) { return StatusDisabled, "" })
+	report := GetReport()
+	assert.Assert(t, !report.Ready, "report with a subsystem that is not ready should not be ready")
+	assert.Equal(t, len(report.Subsystems), 2, "unexpected number of subsystems")
+	assert.Equal(t, report.Subsystems[0].Name, "first", "subsystems should be in registration order")
+	assert.Equal(t, report.Subsystems[0].Status, StatusNotReady, "unexpected status")
+	assert.Equal(t, report.Subsystems[0].Message, "first message", "unexpected message")
+
+	// checks are run for each report
+	status = StatusReady
+	assert.Assert(t, GetReport().Ready, "disabled subsystems should not affect readiness")
+
+	// replacing a check keeps the order
+	Register("first", func() (string, string) { return "Unknown", "" })
+	report = LogReport()
+	assert.Assert(t, !report.Ready, "unknown status should not be ready")
+	assert.Equal(t, len(report.Subsystems), 2, "check should have been replaced")
+	assert.Equal(t, report.Subsystems[0].Status, "Unknown", "unexpected status")
+}
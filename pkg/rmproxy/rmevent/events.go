@@ -20,6 +20,8 @@ package rmevent
 
 import (
 	"github.com/apache/incubator-yunikorn-scheduler-interface/lib/go/si"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
 )
 
 // Incoming events from the RM to the scheduler (async)
@@ -80,7 +82,36 @@ type RMReleaseAllocationAskEvent struct {
 }
 
 type RMNodeUpdateEvent struct {
-	RmID          string
-	AcceptedNodes []*si.AcceptedNode
-	RejectedNodes []*si.RejectedNode
+	RmID            string
+	AcceptedNodes   []*si.AcceptedNode
+	RejectedNodes   []*si.RejectedNode
+	PartitionTotals []*PartitionTotals
+}
+
+// The totals of a partition after processing a node update, one entry per partition touched by the update.
+// The scheduler interface response has no field for the totals: they are logged by the RM proxy and not sent to the RM.
+type PartitionTotals struct {
+	Partition       string
+	Nodes           int
+	Capacity        *resources.Resource
+	AllocationCount int
+	AskCount        int
+}
+
+// Check if the request only contains node status updates: no applications, asks, releases or new nodes,
+// and all node updates are plain updates without a state change.
+func IsNodeStatusUpdate(request *si.UpdateRequest) bool {
+	if request == nil || len(request.UpdatedNodes) == 0 {
+		return false
+	}
+	if len(request.Asks) != 0 || request.Releases != nil || len(request.NewSchedulableNodes) != 0 ||
+		len(request.NewApplications) != 0 || len(request.RemoveApplications) != 0 {
+		return false
+	}
+	for _, update := range request.UpdatedNodes {
+		if update.Action != si.UpdateNodeInfo_UPDATE {
+			return false
+		}
+	}
+	return true
 }
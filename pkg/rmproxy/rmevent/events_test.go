@@ -0,0 +1,40 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package rmevent
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/apache/incubator-yunikorn-scheduler-interface/lib/go/si"
+)
+
+func TestIsNodeStatusUpdate(t *testing.T) {
+	assert.Assert(t, !IsNodeStatusUpdate(nil), "nil request is not a node status update")
+	assert.Assert(t, !IsNodeStatusUpdate(&si.UpdateRequest{}), "empty request is not a node status update")
+	update := &si.UpdateNodeInfo{NodeID: "node-1", Action: si.UpdateNodeInfo_UPDATE}
+	request := &si.UpdateRequest{UpdatedNodes: []*si.UpdateNodeInfo{update}}
+	assert.Assert(t, IsNodeStatusUpdate(request), "plain node update should be a node status update")
+	request.Asks = []*si.AllocationAsk{{AllocationKey: "alloc-1"}}
+	assert.Assert(t, !IsNodeStatusUpdate(request), "request with asks is not a node status update")
+	request.Asks = nil
+	request.UpdatedNodes = append(request.UpdatedNodes, &si.UpdateNodeInfo{NodeID: "node-2", Action: si.UpdateNodeInfo_DRAIN_NODE})
+	assert.Assert(t, !IsNodeStatusUpdate(request), "request with a node state change is not a node status update")
+}
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"reflect"
 	"sync"
+	"time"
 
 	"go.uber.org/zap"
 
@@ -37,12 +38,22 @@ import (
 	"github.com/apache/incubator-yunikorn-scheduler-interface/lib/go/si"
 )
 
+// the maximum number of events the scheduler may have waiting before updates are held back
+const schedulerBacklogLimit = 1000
+
+// Implemented by the scheduler event handler to hold back updates while it has a backlog of events.
+type backlogWaiter interface {
+	// Block until less than the limit of events are waiting to be processed.
+	WaitForBacklogBelow(limit int)
+}
+
 // Gateway to talk to ResourceManager (behind grpc/API of scheduler-interface)
 type RMProxy struct {
 	EventHandlers handler.EventHandlers
 
 	// Internal fields
 	pendingRMEvents chan interface{}
+	updateQueue     *updateQueue
 
 	rmIDToCallback map[string]api.ResourceManagerCallback
 
@@ -50,6 +61,11 @@ type RMProxy struct {
 	// it is used to determine if configs need to be reloaded
 	rmIDToConfigWatcher map[string]*configs.ConfigWatcher
 
+	// set when the scheduler shuts down: updates from the RMs are rejected
+	stopping bool
+	// updates accepted but not yet passed on to the scheduler
+	updates sync.WaitGroup
+
 	sync.RWMutex
 }
 
@@ -79,6 +95,8 @@ func NewRMProxy() *RMProxy {
 		rmIDToConfigWatcher: make(map[string]*configs.ConfigWatcher),
 		pendingRMEvents:     make(chan interface{}, 1024*1024),
 	}
+	// a dropped update will never be passed on to the scheduler
+	rm.updateQueue = newUpdateQueue(common.GetIntEnvVar(updateQueueSize, defaultUpdateQueueSize), rm.updates.Done)
 	return rm
 }
 
@@ -86,6 +104,26 @@ func (rmp *RMProxy) StartService(handlers handler.EventHandlers) {
 	rmp.EventHandlers = handlers
 
 	go rmp.handleRMEvents()
+	go rmp.forwardUpdates()
+}
+
+// Pass the queued updates on to the scheduler one by one. While the scheduler is behind the updates are kept in the
+// bounded update queue, not in the scheduler event queue.
+func (rmp *RMProxy) forwardUpdates() {
+	for {
+		request := rmp.updateQueue.next()
+		rmp.waitForScheduler()
+		rmp.EventHandlers.SchedulerEventHandler.HandleEvent(&rmevent.RMUpdateRequestEvent{Request: request})
+		rmp.updates.Done()
+	}
+}
+
+// Wait until the scheduler has less than the backlog limit of events waiting to be processed.
+// A scheduler event handler that does not support waiting for its backlog is never waited for.
+func (rmp *RMProxy) waitForScheduler() {
+	if waiter, ok := rmp.EventHandlers.SchedulerEventHandler.(backlogWaiter); ok {
+		waiter.WaitForBacklogBelow(schedulerBacklogLimit)
+	}
 }
 
 func (rmp *RMProxy) handleRMRecvUpdateResponseError(rmID string, err error) {
@@ -183,6 +221,17 @@ func (rmp *RMProxy) processRMNodeUpdateEvent(event *rmevent.RMNodeUpdateEvent) {
 		RejectedNodes: event.RejectedNodes,
 		AcceptedNodes: event.AcceptedNodes,
 	}
+	// the update response has no place for the partition totals and the RM does not receive them,
+	// log them with the response to allow verifying the registration from the core side
+	for _, totals := range event.PartitionTotals {
+		log.Logger().Info("partition totals after node update",
+			zap.String("rmID", event.RmID),
+			zap.String("partition", totals.Partition),
+			zap.Int("nodes", totals.Nodes),
+			zap.String("capacity", totals.Capacity.String()),
+			zap.Int("allocations", totals.AllocationCount),
+			zap.Int("asks", totals.AskCount))
+	}
 
 	rmp.processUpdateResponse(event.RmID, response)
 }
@@ -332,12 +381,39 @@ func (rmp *RMProxy) Update(request *si.UpdateRequest) error {
 		return fmt.Errorf("received UpdateRequest, but RmID=\"%s\" not registered", request.RmID)
 	}
 
+	rmp.RLock()
+	if rmp.stopping {
+		rmp.RUnlock()
+		return fmt.Errorf("received UpdateRequest from RmID=\"%s\", but the scheduler is shutting down", request.RmID)
+	}
+	rmp.updates.Add(1)
+	rmp.RUnlock()
+
+	normalizeUpdateRequestByRMId(request)
+	err := rmp.updateQueue.add(request)
+	if err != nil {
+		rmp.updates.Done()
+	}
+	return err
+}
+
+// Stop accepting updates from the RMs. Returns after all updates accepted before the call have been passed on to
+// the scheduler, or with an error if that does not happen within the timeout.
+func (rmp *RMProxy) StopAcceptingUpdates(timeout time.Duration) error {
+	rmp.Lock()
+	rmp.stopping = true
+	rmp.Unlock()
+	done := make(chan bool)
 	go func() {
-		normalizeUpdateRequestByRMId(request)
-		rmp.EventHandlers.SchedulerEventHandler.HandleEvent(&rmevent.RMUpdateRequestEvent{Request: request})
+		rmp.updates.Wait()
+		close(done)
 	}()
-
-	return nil
+	select {
+	case <-done:
+		return nil
+	case <-time.After(timeout):
+		return fmt.Errorf("accepted RM updates not passed on to the scheduler within %s", timeout)
+	}
 }
 
 // Triggers scheduler to reload configuration and apply the changes on-the-fly to the scheduler itself.
@@ -0,0 +1,39 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package rmproxy
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+func TestStopAcceptingUpdates(t *testing.T) {
+	rmp := &RMProxy{}
+	// an accepted update that is not passed on to the scheduler
+	rmp.updates.Add(1)
+	err := rmp.StopAcceptingUpdates(10 * time.Millisecond)
+	assert.ErrorContains(t, err, "not passed on to the scheduler within 10ms")
+	assert.Assert(t, rmp.stopping, "proxy should not accept updates")
+
+	rmp.updates.Done()
+	err = rmp.StopAcceptingUpdates(time.Second)
+	assert.NilError(t, err, "all accepted updates were passed on")
+}
@@ -0,0 +1,164 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package rmproxy
+
+import (
+	"container/list"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/log"
+	"github.com/apache/incubator-yunikorn-core/pkg/metrics"
+	"github.com/apache/incubator-yunikorn-core/pkg/rmproxy/rmevent"
+	"github.com/apache/incubator-yunikorn-scheduler-interface/lib/go/si"
+)
+
+const (
+	updateQueueSize        = "RM_UPDATE_QUEUE_SIZE"
+	defaultUpdateQueueSize = 10000
+
+	highPriority = "high"
+	lowPriority  = "low"
+)
+
+// The bounded queue for the updates from the RMs waiting to be passed on to the scheduler.
+// Updates are passed on in the order they were received, the updates of an RM are never reordered.
+// Node status updates, like a node utilization refresh, are low priority: a newer status update of an RM for the
+// same nodes replaces the queued one. The queued update is dropped and the newer update is queued at the end, after
+// all updates the RM sent before it. An update that does not fit in the full queue is rejected and must be retried
+// by the RM: a queued update is never dropped to make room.
+type updateQueue struct {
+	updates  *list.List               // queued updates in order of arrival
+	status   map[string]*list.Element // queued node status updates keyed on RM and nodes
+	capacity int
+	notify   chan struct{} // signals a waiting consumer that an update was added
+	dropped  func()        // called for each queued update that is dropped
+
+	sync.Mutex
+}
+
+type queuedUpdate struct {
+	request *si.UpdateRequest
+	key     string // key of a node status update, empty for all other updates
+}
+
+func newUpdateQueue(capacity int, dropped func()) *updateQueue {
+	return &updateQueue{
+		updates:  list.New(),
+		status:   make(map[string]*list.Element),
+		capacity: capacity,
+		notify:   make(chan struct{}, 1),
+		dropped:  dropped,
+	}
+}
+
+// Get the key of a node status update: the RM and the sorted IDs of the nodes in the update.
+func getNodeStatusKey(request *si.UpdateRequest) string {
+	nodeIDs := make([]string, 0, len(request.UpdatedNodes))
+	for _, node := range request.UpdatedNodes {
+		nodeIDs = append(nodeIDs, node.NodeID)
+	}
+	sort.Strings(nodeIDs)
+	return request.RmID + "/" + strings.Join(nodeIDs, ",")
+}
+
+// Add the update to the queue. Returns an error if the update does not fit in the queue.
+func (q *updateQueue) add(request *si.UpdateRequest) error {
+	q.Lock()
+	defer q.Unlock()
+	update := &queuedUpdate{request: request}
+	if rmevent.IsNodeStatusUpdate(request) {
+		update.key = getNodeStatusKey(request)
+		if element, ok := q.status[update.key]; ok {
+			q.remove(element)
+			metrics.GetSchedulerMetrics().IncRMUpdateOverflow("replaced")
+			q.dropped()
+		}
+	}
+	if q.updates.Len() >= q.capacity {
+		metrics.GetSchedulerMetrics().IncRMUpdateOverflow("rejected")
+		log.Logger().Warn("RM update queue full, update rejected",
+			zap.String("rmID", request.RmID),
+			zap.Int("capacity", q.capacity))
+		q.updateMetrics()
+		return fmt.Errorf("received UpdateRequest from RmID=\"%s\", but the update queue is full: retry later", request.RmID)
+	}
+	element := q.updates.PushBack(update)
+	if update.key != "" {
+		q.status[update.key] = element
+	}
+	q.updateMetrics()
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// NOTE: this is a lock free call. It must only be called holding the updateQueue lock.
+func (q *updateQueue) remove(element *list.Element) *si.UpdateRequest {
+	update, ok := q.updates.Remove(element).(*queuedUpdate)
+	if !ok {
+		return nil
+	}
+	if update.key != "" {
+		delete(q.status, update.key)
+	}
+	return update.request
+}
+
+// Remove and return the oldest update. Returns nil if the queue is empty.
+func (q *updateQueue) pop() *si.UpdateRequest {
+	q.Lock()
+	defer q.Unlock()
+	element := q.updates.Front()
+	if element == nil {
+		return nil
+	}
+	request := q.remove(element)
+	q.updateMetrics()
+	return request
+}
+
+// Return the next update, blocks until an update is available.
+func (q *updateQueue) next() *si.UpdateRequest {
+	for {
+		if request := q.pop(); request != nil {
+			return request
+		}
+		<-q.notify
+	}
+}
+
+// Return the number of queued updates.
+func (q *updateQueue) size() int {
+	q.Lock()
+	defer q.Unlock()
+	return q.updates.Len()
+}
+
+// NOTE: this is a lock free call. It must only be called holding the updateQueue lock.
+func (q *updateQueue) updateMetrics() {
+	metrics.GetSchedulerMetrics().SetRMUpdateQueueDepth(highPriority, q.updates.Len()-len(q.status))
+	metrics.GetSchedulerMetrics().SetRMUpdateQueueDepth(lowPriority, len(q.status))
+}
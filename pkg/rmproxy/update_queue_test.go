@@ -0,0 +1,143 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package rmproxy
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/metrics"
+	"github.com/apache/incubator-yunikorn-scheduler-interface/lib/go/si"
+)
+
+func nodeStatusUpdate(nodeID string) *si.UpdateRequest {
+	return &si.UpdateRequest{
+		RmID:         "rm-1",
+		UpdatedNodes: []*si.UpdateNodeInfo{{NodeID: nodeID, Action: si.UpdateNodeInfo_UPDATE}},
+	}
+}
+
+func askUpdate(key string) *si.UpdateRequest {
+	return &si.UpdateRequest{
+		RmID: "rm-1",
+		Asks: []*si.AllocationAsk{{AllocationKey: key}},
+	}
+}
+
+func TestUpdateQueueOrder(t *testing.T) {
+	queue := newUpdateQueue(10, func() {})
+	assert.Assert(t, queue.pop() == nil, "empty queue should not return an update")
+	low := nodeStatusUpdate("node-1")
+	high1 := askUpdate("ask-1")
+	high2 := askUpdate("ask-2")
+	for _, request := range []*si.UpdateRequest{high1, low, high2} {
+		err := queue.add(request)
+		assert.NilError(t, err, "update should have been queued")
+	}
+	assert.Equal(t, queue.size(), 3, "unexpected queue size")
+	// the updates of the RM are passed on in the order received
+	assert.Equal(t, queue.next(), high1, "first update expected")
+	assert.Equal(t, queue.next(), low, "second update expected")
+	assert.Equal(t, queue.next(), high2, "third update expected")
+	assert.Equal(t, queue.size(), 0, "queue should be empty")
+}
+
+func TestUpdateQueueReplaceStatus(t *testing.T) {
+	var dropped int
+	queue := newUpdateQueue(10, func() { dropped++ })
+	replacedBefore, err := metrics.GetSchedulerMetrics().GetRMUpdateOverflow("replaced")
+	assert.NilError(t, err, "failed to read replaced metric")
+
+	old := nodeStatusUpdate("node-1")
+	other := nodeStatusUpdate("node-2")
+	high := askUpdate("ask-1")
+	newer := nodeStatusUpdate("node-1")
+	otherRM := nodeStatusUpdate("node-1")
+	otherRM.RmID = "rm-2"
+	for _, request := range []*si.UpdateRequest{old, other, high, newer, otherRM} {
+		err = queue.add(request)
+		assert.NilError(t, err, "update should have been queued")
+	}
+	// the newer status of node-1 replaces the old one and is queued after the ask update
+	assert.Equal(t, dropped, 1, "replaced update should have been reported")
+	assert.Equal(t, queue.size(), 4, "unexpected queue size")
+	for _, expected := range []*si.UpdateRequest{other, high, newer, otherRM} {
+		assert.Equal(t, queue.next(), expected, "unexpected update order")
+	}
+	var count int
+	count, err = metrics.GetSchedulerMetrics().GetRMUpdateOverflow("replaced")
+	assert.NilError(t, err, "failed to read replaced metric")
+	assert.Equal(t, count-replacedBefore, 1, "unexpected number of replaced updates")
+}
+
+func TestUpdateQueueOverflow(t *testing.T) {
+	var dropped int
+	queue := newUpdateQueue(2, func() { dropped++ })
+	rejectedBefore, err := metrics.GetSchedulerMetrics().GetRMUpdateOverflow("rejected")
+	assert.NilError(t, err, "failed to read rejected metric")
+
+	first := nodeStatusUpdate("node-1")
+	second := askUpdate("ask-1")
+	for _, request := range []*si.UpdateRequest{first, second} {
+		err = queue.add(request)
+		assert.NilError(t, err, "update should have been queued")
+	}
+	// full: new updates are rejected, queued updates of other nodes are never dropped
+	err = queue.add(nodeStatusUpdate("node-2"))
+	assert.ErrorContains(t, err, "update queue is full")
+	err = queue.add(askUpdate("ask-2"))
+	assert.ErrorContains(t, err, "update queue is full")
+	assert.Equal(t, dropped, 0, "no queued update should have been dropped")
+	// full: a newer status of a queued node replaces the queued status
+	newer := nodeStatusUpdate("node-1")
+	err = queue.add(newer)
+	assert.NilError(t, err, "replacing update should have been queued")
+	assert.Equal(t, dropped, 1, "replaced update should have been reported")
+
+	assert.Equal(t, queue.next(), second, "oldest update expected")
+	assert.Equal(t, queue.next(), newer, "replacing update expected")
+	var count int
+	count, err = metrics.GetSchedulerMetrics().GetRMUpdateOverflow("rejected")
+	assert.NilError(t, err, "failed to read rejected metric")
+	assert.Equal(t, count-rejectedBefore, 2, "unexpected number of rejected updates")
+}
+
+func TestUpdateQueueNextBlocks(t *testing.T) {
+	queue := newUpdateQueue(10, func() {})
+	result := make(chan *si.UpdateRequest)
+	go func() {
+		result <- queue.next()
+	}()
+	select {
+	case <-result:
+		t.Fatal("next should block on an empty queue")
+	case <-time.After(50 * time.Millisecond):
+	}
+	request := askUpdate("ask-1")
+	err := queue.add(request)
+	assert.NilError(t, err, "update should have been queued")
+	select {
+	case got := <-result:
+		assert.Equal(t, got, request, "unexpected update returned")
+	case <-time.After(time.Second):
+		t.Fatal("next should return after an update was added")
+	}
+}
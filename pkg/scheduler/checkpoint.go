@@ -0,0 +1,389 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/checkpoint"
+	"github.com/apache/incubator-yunikorn-core/pkg/common/security"
+	"github.com/apache/incubator-yunikorn-core/pkg/handler"
+	"github.com/apache/incubator-yunikorn-core/pkg/log"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/objects"
+	"github.com/apache/incubator-yunikorn-scheduler-interface/lib/go/si"
+)
+
+// The checkpoint service periodically stores a snapshot of the scheduler state in the state store.
+// A snapshot is only stored if the state of the scheduler changed since the last snapshot.
+type checkpointService struct {
+	done        chan bool
+	stopped     chan bool
+	ticker      *time.Ticker
+	cc          *ClusterContext
+	store       checkpoint.Store
+	lastVersion uint64
+}
+
+func newCheckpointService(cc *ClusterContext, store checkpoint.Store, interval time.Duration) *checkpointService {
+	return &checkpointService{
+		done:    make(chan bool),
+		stopped: make(chan bool),
+		ticker:  time.NewTicker(interval),
+		cc:      cc,
+		store:   store,
+	}
+}
+
+func (cs *checkpointService) start() {
+	go func() {
+		defer close(cs.stopped)
+		for {
+			select {
+			case <-cs.done:
+				cs.ticker.Stop()
+				// store the final state, changes since the last tick would be lost otherwise
+				cs.runOnce()
+				return
+			case <-cs.ticker.C:
+				cs.runOnce()
+			}
+		}
+	}()
+}
+
+func (cs *checkpointService) runOnce() {
+	version := cs.cc.GetStateVersion()
+	if version == cs.lastVersion {
+		return
+	}
+	if err := cs.store.Save(cs.cc.createSnapshot()); err != nil {
+		log.Logger().Warn("failed to store scheduler state snapshot",
+			zap.Error(err))
+		return
+	}
+	cs.lastVersion = version
+}
+
+// Stop the checkpoint service: the final snapshot is stored before the call returns.
+// Returns an error if the final snapshot is not stored within the timeout.
+func (cs *checkpointService) stop(timeout time.Duration) error {
+	close(cs.done)
+	select {
+	case <-cs.stopped:
+		return nil
+	case <-time.After(timeout):
+		return fmt.Errorf("final scheduler state snapshot not stored within %s", timeout)
+	}
+}
+
+// Create a snapshot of the current state of all partitions.
+func (cc *ClusterContext) createSnapshot() *checkpoint.Snapshot {
+	snapshot := &checkpoint.Snapshot{
+		Version: checkpoint.SnapshotVersion,
+		Created: time.Now(),
+	}
+	for _, partition := range cc.GetPartitionMapClone() {
+		snapshot.Partitions = append(snapshot.Partitions, partition.createSnapshot())
+	}
+	return snapshot
+}
+
+// Return a snapshot of the scheduler state. The snapshot is a copy, it does not share objects with the scheduler.
+func (cc *ClusterContext) GetStateSnapshot() *checkpoint.Snapshot {
+	return cc.createSnapshot()
+}
+
+// Set the snapshot to restore when the RM registers.
+func (cc *ClusterContext) setRestoreSnapshot(snapshot *checkpoint.Snapshot) {
+	cc.Lock()
+	defer cc.Unlock()
+	cc.restoreSnapshot = snapshot
+}
+
+// Restore the applications from the snapshot for all partitions of the RM. The snapshot is only used once.
+// Applications that the RM does not add again within the grace period are removed.
+// NOTE: this is a lock free call. It must only be called holding the ClusterContext lock.
+func (cc *ClusterContext) restoreApplications(rmID string) {
+	if cc.restoreSnapshot == nil {
+		return
+	}
+	for _, partition := range cc.partitions {
+		if partition.RmID != rmID {
+			continue
+		}
+		state := cc.restoreSnapshot.GetPartition(partition.Name)
+		if state == nil {
+			continue
+		}
+		restored := partition.restoreApplications(state, cc.rmEventHandler)
+		if len(restored) == 0 {
+			continue
+		}
+		partitionName := partition.Name
+		time.AfterFunc(checkpoint.GetRestoreGracePeriod(), func() {
+			cc.removeUnconfirmedApplications(partitionName, restored)
+		})
+	}
+	cc.restoreSnapshot = nil
+}
+
+// Remove the restored applications that were not added again by the RM.
+// Allocations recovered for these applications are released. The snapshot state that was not recovered is removed.
+func (cc *ClusterContext) removeUnconfirmedApplications(partitionName string, appIDs []string) {
+	partition := cc.GetPartition(partitionName)
+	if partition == nil {
+		return
+	}
+	partition.clearRestoreState()
+	for _, appID := range appIDs {
+		app := partition.getApplication(appID)
+		if app == nil || !app.IsRestored() {
+			continue
+		}
+		allocations := partition.removeApplication(appID)
+		cc.MarkStateChanged()
+		if len(allocations) > 0 {
+			cc.notifyRMAllocationReleased(partition.RmID, allocations, si.TerminationType_STOPPED_BY_RM,
+				"restored application not added by the RM")
+		}
+		log.Logger().Info("removed restored application not added by the RM",
+			zap.String("applicationID", appID),
+			zap.String("partitionName", partitionName),
+			zap.Int("allocations released", len(allocations)))
+	}
+}
+
+// Create a snapshot of the partition: the dynamic queues, the applications with their allocations and the
+// reservations. The snapshot is not taken holding the partition lock and is not guaranteed to be consistent
+// between applications.
+func (pc *PartitionContext) createSnapshot() *checkpoint.PartitionState {
+	pc.RLock()
+	root := pc.root
+	pc.RUnlock()
+	state := &checkpoint.PartitionState{
+		Name:   pc.Name,
+		RmID:   pc.RmID,
+		Queues: getDynamicQueuePaths(root, nil),
+	}
+	for _, app := range pc.GetApplications() {
+		user := app.GetUser()
+		appState := &checkpoint.ApplicationState{
+			ApplicationID:  app.ApplicationID,
+			QueueName:      app.GetQueueName(),
+			User:           user.User,
+			Groups:         user.Groups,
+			Tags:           app.GetTags(),
+			State:          app.CurrentState(),
+			SubmissionTime: app.SubmissionTime,
+		}
+		for _, alloc := range app.GetAllAllocations() {
+			allocState := &checkpoint.AllocationState{
+				AllocationKey: alloc.AllocationKey,
+				UUID:          alloc.UUID,
+				NodeID:        alloc.NodeID,
+				Placeholder:   alloc.IsPlaceholder(),
+				TaskGroupName: alloc.GetTaskGroup(),
+			}
+			if alloc.AllocatedResource != nil {
+				allocState.Resource = make(map[string]int64, len(alloc.AllocatedResource.Resources))
+				for name, quantity := range alloc.AllocatedResource.Resources {
+					allocState.Resource[name] = int64(quantity)
+				}
+			}
+			appState.Allocations = append(appState.Allocations, allocState)
+		}
+		state.Applications = append(state.Applications, appState)
+		for _, reservation := range app.GetReservationInfos() {
+			state.Reservations = append(state.Reservations, &checkpoint.ReservationState{
+				ApplicationID: reservation.ApplicationID,
+				AllocationKey: reservation.AllocationKey,
+				NodeID:        reservation.NodeID,
+			})
+		}
+	}
+	return state
+}
+
+// Collect the paths of all dynamic queues in the hierarchy below the queue.
+func getDynamicQueuePaths(queue *objects.Queue, paths []string) []string {
+	if queue == nil {
+		return paths
+	}
+	for _, child := range queue.GetCopyOfChildren() {
+		if !child.IsManaged() {
+			paths = append(paths, child.GetQueuePath())
+		}
+		paths = getDynamicQueuePaths(child, paths)
+	}
+	return paths
+}
+
+// The state from the snapshot that is recovered when the objects it refers to are added again by the RM.
+type restoreState struct {
+	allocations  map[string][]string                     // UUIDs of the allocations keyed on the node ID
+	reservations map[string]*checkpoint.ReservationState // reservations keyed on the application, ask and node
+}
+
+// Restore the applications from the partition snapshot into the queue they were placed in before.
+// Dynamic queues are recreated when the application is added. The reservations of the restored applications are
+// kept and recovered when the node registers, see recoverNodeState. The allocations of the restored applications
+// are only kept to log the allocations the RM does not report again.
+// Returns the IDs of the restored applications.
+func (pc *PartitionContext) restoreApplications(state *checkpoint.PartitionState, eventHandler handler.EventHandler) []string {
+	var restored []string
+	pending := &restoreState{
+		allocations:  make(map[string][]string),
+		reservations: make(map[string]*checkpoint.ReservationState),
+	}
+	for _, appState := range state.Applications {
+		siApp := &si.AddApplicationRequest{
+			ApplicationID: appState.ApplicationID,
+			QueueName:     appState.QueueName,
+			PartitionName: pc.Name,
+			Tags:          appState.Tags,
+		}
+		ugi := security.UserGroup{User: appState.User, Groups: appState.Groups}
+		app := objects.NewApplication(siApp, ugi, eventHandler, pc.RmID)
+		app.SubmissionTime = appState.SubmissionTime
+		app.SetRestored()
+		if err := pc.addApplication(app, appState.QueueName); err != nil {
+			log.Logger().Warn("failed to restore application from snapshot",
+				zap.String("applicationID", appState.ApplicationID),
+				zap.String("partitionName", pc.Name),
+				zap.Error(err))
+			continue
+		}
+		restored = append(restored, appState.ApplicationID)
+		for _, allocState := range appState.Allocations {
+			pending.allocations[allocState.NodeID] = append(pending.allocations[allocState.NodeID], allocState.UUID)
+		}
+	}
+	for _, reservation := range state.Reservations {
+		if pc.getApplication(reservation.ApplicationID) != nil {
+			pending.reservations[reservation.ApplicationID+"|"+reservation.AllocationKey+"|"+reservation.NodeID] = reservation
+		}
+	}
+	pc.Lock()
+	pc.restored = pending
+	pc.Unlock()
+	log.Logger().Info("restored applications from snapshot",
+		zap.String("partitionName", pc.Name),
+		zap.Int("applications", len(restored)),
+		zap.Int("allocations pending", countAllocations(state)),
+		zap.Int("reservations pending", len(pending.reservations)))
+	return restored
+}
+
+// Recover the reservations from the snapshot on a node that registers. The allocations reported by the RM for
+// the node are the source of truth: allocations from the snapshot that the RM did not report have finished while
+// the scheduler was down and are dropped, they are never added to the node or the application.
+// NOTE: this is a lock free call. It must NOT be called holding the PartitionContext lock.
+func (pc *PartitionContext) recoverNodeState(node *objects.Node, reported []*objects.Allocation) {
+	pc.Lock()
+	if pc.restored == nil {
+		pc.Unlock()
+		return
+	}
+	allocations := pc.restored.allocations[node.NodeID]
+	delete(pc.restored.allocations, node.NodeID)
+	pc.Unlock()
+	known := make(map[string]bool, len(reported))
+	for _, alloc := range reported {
+		known[alloc.UUID] = true
+	}
+	dropped := 0
+	for _, uuid := range allocations {
+		if !known[uuid] {
+			dropped++
+		}
+	}
+	if dropped > 0 {
+		log.Logger().Info("dropped snapshot allocations not reported by the RM",
+			zap.String("partitionName", pc.Name),
+			zap.String("nodeID", node.NodeID),
+			zap.Int("allocations", dropped))
+	}
+	pc.restoreReservations()
+}
+
+// Restore the reservations from the snapshot for which the node is registered and the application has the ask.
+// The RM adds the asks again after the nodes: this is called when a node registers and when an ask is added.
+// NOTE: this is a lock free call. It must NOT be called holding the PartitionContext lock.
+func (pc *PartitionContext) restoreReservations() {
+	pc.RLock()
+	if pc.restored == nil || len(pc.restored.reservations) == 0 {
+		pc.RUnlock()
+		return
+	}
+	pending := make(map[string]*checkpoint.ReservationState, len(pc.restored.reservations))
+	for key, reservation := range pc.restored.reservations {
+		pending[key] = reservation
+	}
+	pc.RUnlock()
+	for key, reservation := range pending {
+		app := pc.getApplication(reservation.ApplicationID)
+		node := pc.GetNode(reservation.NodeID)
+		if app == nil || node == nil {
+			continue
+		}
+		ask := app.GetAllocationAsk(reservation.AllocationKey)
+		if ask == nil {
+			continue
+		}
+		if ask.GetPendingAskRepeat() > 0 {
+			pc.reserve(app, node, ask)
+		}
+		pc.Lock()
+		if pc.restored != nil {
+			delete(pc.restored.reservations, key)
+		}
+		pc.Unlock()
+	}
+}
+
+// Remove the snapshot state that was not recovered within the grace period.
+func (pc *PartitionContext) clearRestoreState() {
+	pc.Lock()
+	defer pc.Unlock()
+	if pc.restored == nil {
+		return
+	}
+	allocations := 0
+	for _, nodeAllocs := range pc.restored.allocations {
+		allocations += len(nodeAllocs)
+	}
+	if allocations > 0 || len(pc.restored.reservations) > 0 {
+		log.Logger().Info("snapshot state not recovered within the grace period",
+			zap.String("partitionName", pc.Name),
+			zap.Int("allocations", allocations),
+			zap.Int("reservations", len(pc.restored.reservations)))
+	}
+	pc.restored = nil
+}
+
+func countAllocations(state *checkpoint.PartitionState) int {
+	count := 0
+	for _, appState := range state.Applications {
+		count += len(appState.Allocations)
+	}
+	return count
+}
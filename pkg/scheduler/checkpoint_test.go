@@ -0,0 +1,238 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/checkpoint"
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+	"github.com/apache/incubator-yunikorn-core/pkg/common/security"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/objects"
+	"github.com/apache/incubator-yunikorn-scheduler-interface/lib/go/si"
+)
+
+// memoryStore keeps the last saved snapshot in memory
+type memoryStore struct {
+	saved []*checkpoint.Snapshot
+}
+
+func (ms *memoryStore) Save(snapshot *checkpoint.Snapshot) error {
+	ms.saved = append(ms.saved, snapshot)
+	return nil
+}
+
+func (ms *memoryStore) Load() (*checkpoint.Snapshot, error) {
+	if len(ms.saved) == 0 {
+		return nil, nil
+	}
+	return ms.saved[len(ms.saved)-1], nil
+}
+
+func (ms *memoryStore) Close() error {
+	return nil
+}
+
+func TestCreateSnapshot(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	app := newApplication(appID1, "default", defQueue)
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "add application to partition should not have failed")
+
+	nodeRes := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1000})
+	node := newNodeMaxResource(nodeID1, nodeRes)
+	appRes := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})
+	ask := newAllocationAsk("alloc-1", appID1, appRes)
+	alloc := objects.NewAllocation("alloc-1-uuid", nodeID1, ask)
+	err = partition.AddNode(node, []*objects.Allocation{alloc})
+	assert.NilError(t, err, "add node to partition should not have failed")
+	ask = newAllocationAsk("alloc-2", appID1, appRes)
+	err = app.AddAllocationAsk(ask)
+	assert.NilError(t, err, "ask should have been added to app")
+	partition.reserve(app, node, ask)
+
+	cc := &ClusterContext{
+		partitions: map[string]*PartitionContext{partition.Name: partition},
+	}
+	snapshot := cc.createSnapshot()
+	assert.Equal(t, snapshot.Version, checkpoint.SnapshotVersion, "unexpected snapshot version")
+	assert.Equal(t, len(snapshot.Partitions), 1, "partition missing from snapshot")
+	state := snapshot.GetPartition(partition.Name)
+	assert.Assert(t, state != nil, "partition state not found")
+	assert.Equal(t, len(state.Queues), 0, "no dynamic queues expected")
+	assert.Equal(t, len(state.Applications), 1, "application missing from snapshot")
+	appState := state.Applications[0]
+	assert.Equal(t, appState.ApplicationID, appID1, "unexpected application")
+	assert.Equal(t, appState.QueueName, defQueue, "unexpected queue")
+	assert.Equal(t, appState.State, app.CurrentState(), "unexpected state")
+	assert.Equal(t, len(appState.Allocations), 1, "allocation missing from snapshot")
+	assert.DeepEqual(t, appState.Allocations[0], &checkpoint.AllocationState{
+		AllocationKey: "alloc-1",
+		UUID:          "alloc-1-uuid",
+		NodeID:        nodeID1,
+		Resource:      map[string]int64{"first": 1},
+	})
+	assert.DeepEqual(t, state.Reservations, []*checkpoint.ReservationState{
+		{ApplicationID: appID1, AllocationKey: "alloc-2", NodeID: nodeID1},
+	})
+}
+
+func TestRestoreApplications(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	submitted := time.Now().Add(-time.Hour)
+	state := &checkpoint.PartitionState{
+		Name: partition.Name,
+		RmID: rmID,
+		Applications: []*checkpoint.ApplicationState{
+			{ApplicationID: appID1, QueueName: defQueue, User: "testuser", SubmissionTime: submitted,
+				Allocations: []*checkpoint.AllocationState{{AllocationKey: "alloc-1", UUID: "uuid-1", NodeID: nodeID1}}},
+			{ApplicationID: appID2, QueueName: "root.unknown", User: "testuser"},
+		},
+	}
+	restored := partition.restoreApplications(state, nil)
+	assert.DeepEqual(t, restored, []string{appID1})
+	app := partition.getApplication(appID1)
+	assert.Assert(t, app != nil, "application should have been restored")
+	assert.Assert(t, app.IsRestored(), "application should be marked as restored")
+	assert.Equal(t, app.GetQueueName(), defQueue, "unexpected queue for restored application")
+	assert.Equal(t, app.GetUser().User, "testuser", "unexpected user for restored application")
+	assert.Equal(t, app.SubmissionTime, submitted, "submission time should have been restored")
+	assert.Assert(t, partition.getApplication(appID2) == nil, "application in unknown queue should not be restored")
+
+	// the RM adding the application again confirms it
+	err = partition.AddApplication(newApplication(appID1, "default", defQueue))
+	assert.NilError(t, err, "adding a restored application should not fail")
+	assert.Equal(t, partition.getApplication(appID1), app, "restored application should have been kept")
+	assert.Assert(t, !app.IsRestored(), "application should have been confirmed")
+	err = partition.AddApplication(newApplication(appID1, "default", defQueue))
+	assert.ErrorContains(t, err, "already existed")
+
+	// a restored application that is not confirmed is removed
+	state.Applications = state.Applications[:1]
+	state.Applications[0].ApplicationID = appID2
+	restored = partition.restoreApplications(state, nil)
+	assert.DeepEqual(t, restored, []string{appID2})
+	cc := &ClusterContext{
+		partitions: map[string]*PartitionContext{partition.Name: partition},
+	}
+	cc.removeUnconfirmedApplications(partition.Name, []string{appID1, appID2})
+	assert.Assert(t, partition.getApplication(appID1) != nil, "confirmed application should not be removed")
+	assert.Assert(t, partition.getApplication(appID2) == nil, "unconfirmed application should have been removed")
+}
+
+func TestRestoreAllocations(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	state := &checkpoint.PartitionState{
+		Name: partition.Name,
+		RmID: rmID,
+		Applications: []*checkpoint.ApplicationState{
+			{ApplicationID: appID1, QueueName: defQueue, User: "restored", Tags: map[string]string{"first": "restored", "second": "restored"},
+				Allocations: []*checkpoint.AllocationState{
+					{AllocationKey: "alloc-1", UUID: "uuid-1", NodeID: nodeID1, Resource: map[string]int64{"first": 1}},
+					{AllocationKey: "alloc-2", UUID: "uuid-2", NodeID: nodeID1, Resource: map[string]int64{"first": 1}},
+				}},
+		},
+		Reservations: []*checkpoint.ReservationState{
+			{ApplicationID: appID1, AllocationKey: "alloc-3", NodeID: nodeID1},
+		},
+	}
+	restored := partition.restoreApplications(state, nil)
+	assert.DeepEqual(t, restored, []string{appID1})
+	app := partition.getApplication(appID1)
+	assert.Assert(t, app != nil, "application should have been restored")
+
+	// confirming the application takes the user and tags from the RM
+	siApp := &si.AddApplicationRequest{
+		ApplicationID: appID1,
+		QueueName:     defQueue,
+		PartitionName: "default",
+		Tags:          map[string]string{"first": "added"},
+	}
+	err = partition.AddApplication(objects.NewApplication(siApp, security.UserGroup{User: "added"}, nil, rmID))
+	assert.NilError(t, err, "adding a restored application should not fail")
+	assert.Equal(t, app.GetUser().User, "added", "user should have been replaced")
+	assert.Equal(t, app.GetTag("first"), "added", "tag should have been replaced")
+	assert.Equal(t, app.GetTag("second"), "restored", "restored tag should have been kept")
+
+	// the ask for the reservation arrives before the node: the reservation is pending
+	res := &si.Resource{Resources: map[string]*si.Quantity{"first": {Value: 1}}}
+	err = partition.addAllocationAsk(&si.AllocationAsk{
+		AllocationKey:  "alloc-3",
+		ApplicationID:  appID1,
+		ResourceAsk:    res,
+		MaxAllocations: 1,
+	})
+	assert.NilError(t, err, "ask should have been added")
+	assert.Equal(t, len(partition.getReservations()), 0, "reservation should wait for the node")
+
+	// the node registers with one of the allocations: the other one finished while the scheduler was down
+	nodeRes := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 10})
+	node := newNodeMaxResource(nodeID1, nodeRes)
+	appRes := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})
+	reported := objects.NewAllocation("uuid-1", nodeID1, newAllocationAsk("alloc-1", appID1, appRes))
+	err = partition.AddNode(node, []*objects.Allocation{reported})
+	assert.NilError(t, err, "add node to partition should not have failed")
+	assert.Equal(t, app.GetAllocation("uuid-1"), reported, "reported allocation should not be replaced")
+	assert.Assert(t, app.GetAllocation("uuid-2") == nil, "allocation not reported by the RM should not be recovered")
+	assert.Assert(t, node.GetAllocation("uuid-2") == nil, "allocation not reported by the RM should not be on the node")
+	assert.Assert(t, resources.Equals(app.GetAllocatedResource(), appRes), "unexpected allocated resources")
+	assert.Assert(t, resources.Equals(node.GetAllocatedResource(), appRes), "unexpected allocated resources on the node")
+	assert.Assert(t, resources.Equals(partition.GetQueue(defQueue).GetAllocatedResource(), appRes), "unexpected allocated resources on the queue")
+	assert.Equal(t, partition.getReservations()[appID1], 1, "reservation should have been restored")
+
+	// nothing is left to restore
+	cc := &ClusterContext{
+		partitions: map[string]*PartitionContext{partition.Name: partition},
+	}
+	cc.removeUnconfirmedApplications(partition.Name, nil)
+	assert.Assert(t, partition.restored == nil, "restore state should have been cleared")
+	assert.Assert(t, partition.getApplication(appID1) != nil, "confirmed application should not be removed")
+}
+
+func TestCheckpointStop(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	cc := &ClusterContext{
+		partitions: map[string]*PartitionContext{partition.Name: partition},
+	}
+	store := &memoryStore{}
+	cs := newCheckpointService(cc, store, time.Hour)
+	cs.start()
+	err = partition.AddApplication(newApplication(appID1, "default", defQueue))
+	assert.NilError(t, err, "add application to partition should not have failed")
+	cc.MarkStateChanged()
+
+	// the change is stored on stop, well before the next tick
+	err = cs.stop(5 * time.Second)
+	assert.NilError(t, err, "stop should have stored the final snapshot")
+	assert.Equal(t, len(store.saved), 1, "final snapshot should have been stored")
+	state := store.saved[0].GetPartition(partition.Name)
+	assert.Assert(t, state != nil, "partition state not found")
+	assert.Equal(t, len(state.Applications), 1, "application missing from final snapshot")
+
+	// stopping without a checkpoint service is a no-op
+	s := &Scheduler{}
+	assert.NilError(t, s.StopCheckpointing(time.Second), "stop without checkpointing should not fail")
+}
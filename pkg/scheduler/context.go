@@ -21,25 +21,40 @@ package scheduler
 import (
 	"fmt"
 	"math"
+	"os"
+	"reflect"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"go.uber.org/zap"
 
+	"github.com/apache/incubator-yunikorn-core/pkg/checkpoint"
 	"github.com/apache/incubator-yunikorn-core/pkg/common"
 	"github.com/apache/incubator-yunikorn-core/pkg/common/configs"
+	"github.com/apache/incubator-yunikorn-core/pkg/common/features"
 	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+	"github.com/apache/incubator-yunikorn-core/pkg/events"
 	"github.com/apache/incubator-yunikorn-core/pkg/handler"
 	"github.com/apache/incubator-yunikorn-core/pkg/log"
 	"github.com/apache/incubator-yunikorn-core/pkg/metrics"
+	"github.com/apache/incubator-yunikorn-core/pkg/metrics/history"
 	"github.com/apache/incubator-yunikorn-core/pkg/plugins"
 	"github.com/apache/incubator-yunikorn-core/pkg/rmproxy/rmevent"
 	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/objects"
+	"github.com/apache/incubator-yunikorn-core/pkg/trace"
 	siCommon "github.com/apache/incubator-yunikorn-scheduler-interface/lib/go/common"
 	"github.com/apache/incubator-yunikorn-scheduler-interface/lib/go/si"
 )
 
-const disableReservation = "DISABLE_RESERVATION"
+const (
+	disableReservation   = "DISABLE_RESERVATION"
+	reservationTimeout   = "RESERVATION_TIMEOUT"
+	reservationBlacklist = "RESERVATION_BLACKLIST"
+	reservationSteal     = "RESERVATION_STEAL_PRIORITY_DELTA"
+	rmSchedulingWeights  = "RM_SCHEDULING_WEIGHTS"
+	rmResourceQuotas     = "RM_RESOURCE_QUOTAS"
+)
 
 type ClusterContext struct {
 	partitions     map[string]*PartitionContext
@@ -49,6 +64,32 @@ type ClusterContext struct {
 	// config values that change scheduling behaviour
 	needPreemption      bool
 	reservationDisabled bool
+	rmWeights           map[string]float64             // scheduling weight per RM, set on creation only
+	rmQuotas            map[string]*resources.Resource // aggregate resource quota per RM, set on creation only
+
+	// scheduling cycle counter, only changed by the scheduling loop
+	cycle uint64
+
+	// version of the scheduler state, increased on every change: must be accessed atomically
+	stateVersion uint64
+
+	// lock free status of the scheduler, see status.go: must be accessed atomically
+	startTime        time.Time    // creation time of the context, never changes
+	lastCycle        int64        // end of the last scheduling cycle in nanoseconds since the epoch
+	schedulingPaused int32        // 1 if no partition could schedule in the last cycle
+	registeredRMs    int32        // number of RMs with a partition
+	configChecksum   atomic.Value // checksum of the last applied configuration
+	shutdownPhase    atomic.Value // current shutdown phase, not set if the scheduler is not shutting down
+
+	// scheduling cycle tracing, the tracer is nil if tracing is disabled
+	tracer      trace.SchedulerTracer
+	tracingConf configs.TracingConfig
+
+	// called with the web service settings on every configuration update, nil if no web service is running
+	webServiceConfigHandler func(conf configs.WebServiceConfig)
+
+	// snapshot of the scheduler state restored when the RM registers, nil if there is nothing to restore
+	restoreSnapshot *checkpoint.Snapshot
 
 	sync.RWMutex
 }
@@ -66,12 +107,16 @@ func NewClusterContext(rmID, policyGroup string) (*ClusterContext, error) {
 		partitions:          make(map[string]*PartitionContext),
 		policyGroup:         policyGroup,
 		reservationDisabled: common.GetBoolEnvVar(disableReservation, false),
+		startTime:           time.Now(),
+		rmWeights:           parseRMWeights(os.Getenv(rmSchedulingWeights)),
+		rmQuotas:            parseRMQuotas(os.Getenv(rmResourceQuotas)),
 	}
 	// If reservation is turned off set the reservation delay to the maximum duration defined.
 	// The time package does not export maxDuration so use the equivalent from the math package.
 	if cc.reservationDisabled {
 		objects.SetReservationDelay(math.MaxInt64)
 	}
+	setReservationTimeout()
 	err = cc.updateSchedulerConfig(conf, rmID)
 	if err != nil {
 		return nil, err
@@ -85,15 +130,28 @@ func newClusterContext() *ClusterContext {
 	cc := &ClusterContext{
 		partitions:          make(map[string]*PartitionContext),
 		reservationDisabled: common.GetBoolEnvVar(disableReservation, false),
+		startTime:           time.Now(),
+		rmWeights:           parseRMWeights(os.Getenv(rmSchedulingWeights)),
+		rmQuotas:            parseRMQuotas(os.Getenv(rmResourceQuotas)),
 	}
 	// If reservation is turned off set the reservation delay to the maximum duration defined.
 	// The time package does not export maxDuration so use the equivalent from the math package.
 	if cc.reservationDisabled {
 		objects.SetReservationDelay(math.MaxInt64)
 	}
+	setReservationTimeout()
 	return cc
 }
 
+// Set the reservation timeout, blacklist time and steal priority delta from the environment.
+// A reservation timeout of 0, the default, means that reservations never time out.
+// A steal priority delta of 0, the default, means that reservations are never stolen.
+func setReservationTimeout() {
+	objects.SetReservationTimeout(common.GetDurationEnvVar(reservationTimeout, 0),
+		common.GetDurationEnvVar(reservationBlacklist, 30*time.Second))
+	objects.SetReservationStealDelta(int32(common.GetIntEnvVar(reservationSteal, 0)))
+}
+
 func (cc *ClusterContext) setEventHandler(rmHandler handler.EventHandler) {
 	cc.rmEventHandler = rmHandler
 }
@@ -101,38 +159,177 @@ func (cc *ClusterContext) setEventHandler(rmHandler handler.EventHandler) {
 // The main scheduling routine.
 // Process each partition in the scheduler, walk over each queue and app to check if anything can be scheduled.
 // This can be forked into a go routine per partition if needed to increase parallel allocations
-func (cc *ClusterContext) schedule() {
+// Returns true if any of the partitions allocated, reserved or released something in the cycle.
+func (cc *ClusterContext) schedule() bool {
 	schedulingStart := time.Now()
-	// schedule each partition defined in the cluster
-	for _, psc := range cc.GetPartitionMapClone() {
-		// if there are no resources in the partition just skip
-		if psc.root.GetMaxResource() == nil {
-			continue
-		}
-		// a stopped partition does not allocate
-		if psc.isStopped() {
-			continue
+	cc.cycle++
+	ctx := cc.newTraceContext()
+	startTrace(ctx, "root", "schedule", "")
+	active := false
+	progress := false
+	// schedule each partition defined in the cluster, ordered and weighted to share the attention fairly between RMs
+	for _, rm := range cc.getSchedulingOrder() {
+		for attempt := 0; attempt < rm.attempts; attempt++ {
+			allocated := false
+			for _, psc := range rm.partitions {
+				// a stopped partition does not allocate
+				if psc.isStopped() {
+					continue
+				}
+				active = true
+				// scheduling is disabled: asks queue up until it is enabled again
+				if !psc.IsSchedulingEnabled() {
+					continue
+				}
+				// if there are no resources in the partition just skip
+				if psc.root.GetMaxResource() == nil {
+					continue
+				}
+				startTrace(ctx, "partition", "", psc.Name)
+				if cc.schedulePartition(ctx, psc) {
+					allocated = true
+				}
+				finishTrace(ctx, "")
+			}
+			// nothing allocated: the next attempts in this cycle will not allocate either
+			if !allocated {
+				break
+			}
+			progress = true
 		}
-		// try reservations first
-		alloc := psc.tryReservedAllocate()
+	}
+	finishTrace(ctx, "")
+	cc.setCycleStatus(active)
+	metrics.GetSchedulerMetrics().ObserveSchedulingLatency(schedulingStart)
+	return progress
+}
+
+// Run one scheduling attempt for the partition, each phase is traced in the context.
+// Returns true if the attempt allocated, reserved or released something.
+func (cc *ClusterContext) schedulePartition(ctx trace.SchedulerTraceContext, psc *PartitionContext) bool {
+	// try reservations first
+	startTrace(ctx, "partition", "reservedAllocate", psc.Name)
+	alloc := psc.tryReservedAllocate()
+	finishTrace(ctx, allocationState(alloc))
+	if alloc == nil {
+		// placeholder replacement second
+		startTrace(ctx, "partition", "placeholderAllocate", psc.Name)
+		alloc = psc.tryPlaceholderAllocate()
+		finishTrace(ctx, allocationState(alloc))
+		// nothing reserved that can be allocated try normal allocate
 		if alloc == nil {
-			// placeholder replacement second
-			alloc = psc.tryPlaceholderAllocate()
-			// nothing reserved that can be allocated try normal allocate
-			if alloc == nil {
-				alloc = psc.tryAllocate()
+			if psc.getParallelWorkers() > 0 {
+				startTrace(ctx, "partition", "tryAllocateParallel", psc.Name)
+				allocs := psc.tryAllocateParallel()
+				finishTrace(ctx, fmt.Sprintf("%d allocated", len(allocs)))
+				for _, parallelAlloc := range allocs {
+					cc.confirmAllocation(ctx, psc, parallelAlloc)
+				}
+				if len(allocs) == 0 && psc.tryStealReservation() {
+					cc.MarkStateChanged()
+				}
+				return len(allocs) != 0
 			}
-		}
-		if alloc != nil {
-			if alloc.Result == objects.Replaced {
-				// communicate the removal to the RM
-				cc.notifyRMAllocationReleased(psc.RmID, alloc.Releases, si.TerminationType_PLACEHOLDER_REPLACED, "replacing UUID: "+alloc.UUID)
-			} else {
-				cc.notifyRMNewAllocation(psc.RmID, alloc)
+			startTrace(ctx, "partition", "tryAllocate", psc.Name)
+			alloc = psc.tryAllocate()
+			finishTrace(ctx, allocationState(alloc))
+			// nothing allocated or reserved: a higher priority ask might be blocked by reservations
+			if alloc == nil && psc.tryStealReservation() {
+				cc.MarkStateChanged()
 			}
 		}
 	}
-	metrics.GetSchedulerMetrics().ObserveSchedulingLatency(schedulingStart)
+	cc.confirmAllocation(ctx, psc, alloc)
+	return alloc != nil
+}
+
+// Trace the confirmation of the allocation to the RM and track the time since the proposal.
+// A nil allocation is ignored.
+func (cc *ClusterContext) confirmAllocation(ctx trace.SchedulerTraceContext, psc *PartitionContext, alloc *objects.Allocation) {
+	if alloc == nil {
+		return
+	}
+	cc.MarkStateChanged()
+	startTrace(ctx, "allocation", "confirm", alloc.AllocationKey)
+	cc.notifyAllocation(psc, alloc)
+	finishTrace(ctx, alloc.Result.String())
+	if proposed := alloc.GetProposalTime(); !proposed.IsZero() {
+		metrics.GetSchedulerMetrics().ObserveConfirmationLatency(proposed)
+	}
+}
+
+// Create a new trace context for a scheduling cycle, returns nil if tracing is disabled.
+func (cc *ClusterContext) newTraceContext() trace.SchedulerTraceContext {
+	cc.RLock()
+	defer cc.RUnlock()
+	if cc.tracer == nil {
+		return nil
+	}
+	return cc.tracer.NewTraceContext()
+}
+
+// Set the handler that applies the web service settings on a configuration update.
+// The handler is called holding the ClusterContext lock and must not call back into the context.
+func (cc *ClusterContext) SetWebServiceConfigHandler(handler func(conf configs.WebServiceConfig)) {
+	cc.Lock()
+	defer cc.Unlock()
+	cc.webServiceConfigHandler = handler
+}
+
+// Update the scheduling cycle tracer if the tracing config changed.
+// A tracer that cannot be created is logged and disables tracing, it never fails the config update.
+// unlocked call must only be called holding the ClusterContext lock
+func (cc *ClusterContext) updateTracer(conf configs.TracingConfig) {
+	if reflect.DeepEqual(cc.tracingConf, conf) {
+		return
+	}
+	cc.tracingConf = conf
+	if cc.tracer != nil {
+		cc.tracer.Close()
+		cc.tracer = nil
+	}
+	if !conf.Enabled {
+		log.Logger().Info("scheduling cycle tracing disabled")
+		return
+	}
+	params := &trace.SchedulerTracerImplParams{
+		Mode:              conf.Mode,
+		CollectorEndpoint: conf.CollectorEndpoint,
+	}
+	if params.Mode == "" {
+		params.Mode = trace.Sampling
+	}
+	if len(conf.FilterTags) > 0 {
+		params.FilterTags = make(map[string]interface{})
+		for key, value := range conf.FilterTags {
+			params.FilterTags[key] = value
+		}
+	}
+	tracer, err := trace.NewSchedulerTracer(params)
+	if err != nil {
+		log.Logger().Warn("scheduling cycle tracer creation failed, tracing disabled",
+			zap.Error(err))
+		return
+	}
+	cc.tracer = tracer
+	log.Logger().Info("scheduling cycle tracing enabled",
+		zap.String("mode", params.Mode),
+		zap.String("collector", params.CollectorEndpoint))
+}
+
+// Communicate the result of a scheduling attempt for the partition to the RM.
+// A nil allocation is ignored.
+func (cc *ClusterContext) notifyAllocation(psc *PartitionContext, alloc *objects.Allocation) {
+	if alloc == nil {
+		return
+	}
+	if alloc.Result == objects.Replaced {
+		// communicate the removal to the RM
+		cc.notifyRMAllocationReleased(psc.RmID, alloc.Releases, si.TerminationType_PLACEHOLDER_REPLACED, "replacing UUID: "+alloc.UUID)
+	} else {
+		alloc.SetSchedulingCycle(cc.cycle)
+		cc.notifyRMNewAllocation(psc.RmID, alloc)
+	}
 }
 
 func (cc *ClusterContext) processRMRegistrationEvent(event *rmevent.RMRegistrationEvent) {
@@ -163,6 +360,8 @@ func (cc *ClusterContext) processRMRegistrationEvent(event *rmevent.RMRegistrati
 	// update global scheduler configs, set the policyGroup for this cluster
 	cc.policyGroup = policyGroup
 	configs.ConfigContext.Set(policyGroup, conf)
+	// restore the applications of the RM from the last snapshot if there is one
+	cc.restoreApplications(rmID)
 
 	// Done, notify channel
 	event.Channel <- &rmevent.Result{
@@ -247,6 +446,7 @@ func (cc *ClusterContext) removePartitionsByRMID(event *rmevent.RMPartitionsRemo
 	for partitionName := range partitionToRemove {
 		delete(cc.partitions, partitionName)
 	}
+	cc.updateRMStatus()
 	// Done, notify channel
 	event.Channel <- &rmevent.Result{
 		Succeeded: true,
@@ -336,6 +536,18 @@ func (cc *ClusterContext) updateSchedulerConfig(conf *configs.SchedulerConfig, r
 		visited[p.Name] = true
 	}
 
+	cc.updateTracer(conf.Tracing)
+	if err := features.SetConfigGates(conf.FeatureGates); err != nil {
+		log.Logger().Warn("feature gates from the configuration not applied",
+			zap.Error(err))
+	}
+	if cc.webServiceConfigHandler != nil {
+		cc.webServiceConfigHandler(conf.WebService)
+	}
+	cc.MarkStateChanged()
+	cc.configChecksum.Store(conf.Checksum)
+	cc.updateRMStatus()
+
 	// get the removed partitions, mark them as deleted
 	for _, part := range cc.partitions {
 		if !visited[part.Name] {
@@ -348,6 +560,17 @@ func (cc *ClusterContext) updateSchedulerConfig(conf *configs.SchedulerConfig, r
 	return nil
 }
 
+// Return the version of the scheduler state. The version changes each time the state of the partitions, queues,
+// applications or nodes could have changed. Used to detect if cached information is still up to date.
+func (cc *ClusterContext) GetStateVersion() uint64 {
+	return atomic.LoadUint64(&cc.stateVersion)
+}
+
+// Record a change of the scheduler state: calls that change the state outside of the event system must call this.
+func (cc *ClusterContext) MarkStateChanged() {
+	atomic.AddUint64(&cc.stateVersion, 1)
+}
+
 // Get the config name.
 func (cc *ClusterContext) GetPolicyGroup() string {
 	cc.RLock()
@@ -366,6 +589,15 @@ func (cc *ClusterContext) GetPartitionMapClone() map[string]*PartitionContext {
 	return newMap
 }
 
+// Get the current usage of the queues in all partitions for the metrics history
+func (cc *ClusterContext) GetQueueUsage() []*history.QueueUsage {
+	var usage []*history.QueueUsage
+	for _, partition := range cc.GetPartitionMapClone() {
+		usage = append(usage, partition.GetQueueUsage()...)
+	}
+	return usage
+}
+
 func (cc *ClusterContext) GetPartition(partitionName string) *PartitionContext {
 	cc.RLock()
 	defer cc.RUnlock()
@@ -438,6 +670,7 @@ func (cc *ClusterContext) processApplications(request *si.UpdateRequest) {
 		partition := cc.GetPartition(app.PartitionName)
 		if partition == nil {
 			msg := fmt.Sprintf("Failed to add application %s to partition %s, partition doesn't exist", app.ApplicationID, app.PartitionName)
+			metrics.GetSchedulerMetrics().IncApplicationRejected(app.PartitionName, app.QueueName, rejectedPartition)
 			rejectedApps = append(rejectedApps, &si.RejectedApplication{
 				ApplicationID: app.ApplicationID,
 				Reason:        msg,
@@ -449,8 +682,14 @@ func (cc *ClusterContext) processApplications(request *si.UpdateRequest) {
 		}
 		// convert and resolve the user: cache can be set per partition
 		// need to do this before we create the application
+		// a user that cannot be resolved is handled based on the policy of the partition
 		ugi, err := partition.convertUGI(app.Ugi)
+		var quarantineQueue string
+		if err != nil {
+			ugi, quarantineQueue, err = partition.applyUnresolvedUserPolicy(err)
+		}
 		if err != nil {
+			metrics.GetSchedulerMetrics().IncApplicationRejected(partition.Name, app.QueueName, rejectedUser)
 			rejectedApps = append(rejectedApps, &si.RejectedApplication{
 				ApplicationID: app.ApplicationID,
 				Reason:        err.Error(),
@@ -463,7 +702,7 @@ func (cc *ClusterContext) processApplications(request *si.UpdateRequest) {
 		}
 		// create a new app object and add it to the partition (partition logs details)
 		schedApp := objects.NewApplication(app, ugi, cc.rmEventHandler, request.RmID)
-		if err = partition.AddApplication(schedApp); err != nil {
+		if err = partition.addApplication(schedApp, quarantineQueue); err != nil {
 			rejectedApps = append(rejectedApps, &si.RejectedApplication{
 				ApplicationID: app.ApplicationID,
 				Reason:        err.Error(),
@@ -529,9 +768,13 @@ func (cc *ClusterContext) removePartition(partitionName string) {
 	defer cc.RUnlock()
 
 	delete(cc.partitions, partitionName)
+	cc.updateRMStatus()
 }
 
+// Process the node updates from the RM. Consecutive plain updates are collected per partition and applied in bulk,
+// a node state change first applies the collected updates to keep the order of the updates for a node.
 func (cc *ClusterContext) updateNodes(request *si.UpdateRequest) {
+	batch := make(map[*PartitionContext][]*si.UpdateNodeInfo)
 	for _, update := range request.UpdatedNodes {
 		var partition *PartitionContext
 		if p, ok := update.Attributes[siCommon.NodePartition]; ok {
@@ -551,6 +794,13 @@ func (cc *ClusterContext) updateNodes(request *si.UpdateRequest) {
 			continue
 		}
 
+		if update.Action == si.UpdateNodeInfo_UPDATE {
+			batch[partition] = append(batch[partition], update)
+			continue
+		}
+		cc.applyNodeUpdates(batch)
+		batch = make(map[*PartitionContext][]*si.UpdateNodeInfo)
+
 		node := partition.GetNode(update.NodeID)
 		if node == nil {
 			log.Logger().Info("Failed to update non existing node",
@@ -561,13 +811,6 @@ func (cc *ClusterContext) updateNodes(request *si.UpdateRequest) {
 		}
 
 		switch update.Action {
-		case si.UpdateNodeInfo_UPDATE:
-			if sr := update.SchedulableResource; sr != nil {
-				partition.updatePartitionResource(node.SetCapacity(resources.NewResourceFromProto(sr)))
-			}
-			if or := update.OccupiedResource; or != nil {
-				node.SetOccupiedResource(resources.NewResourceFromProto(or))
-			}
 		case si.UpdateNodeInfo_DRAIN_NODE:
 			// set the state to not schedulable
 			node.SetSchedulable(false)
@@ -585,11 +828,25 @@ func (cc *ClusterContext) updateNodes(request *si.UpdateRequest) {
 			}
 		}
 	}
+	cc.applyNodeUpdates(batch)
+}
+
+// Apply the collected plain node updates, one bulk update per partition.
+func (cc *ClusterContext) applyNodeUpdates(batch map[*PartitionContext][]*si.UpdateNodeInfo) {
+	for partition, updates := range batch {
+		for _, nodeID := range partition.updateNodes(updates) {
+			log.Logger().Info("Failed to update non existing node",
+				zap.String("nodeID", nodeID),
+				zap.String("partitionName", partition.Name),
+				zap.String("nodeAction", si.UpdateNodeInfo_UPDATE.String()))
+		}
+	}
 }
 
 func (cc *ClusterContext) addNodes(request *si.UpdateRequest) {
 	acceptedNodes := make([]*si.AcceptedNode, 0)
 	rejectedNodes := make([]*si.RejectedNode, 0)
+	partitions := make(map[string]*PartitionContext)
 	for _, node := range request.NewSchedulableNodes {
 		sn := objects.NewNode(node)
 		partition := cc.GetPartition(sn.Partition)
@@ -606,6 +863,7 @@ func (cc *ClusterContext) addNodes(request *si.UpdateRequest) {
 				zap.String("partitionName", sn.Partition))
 			continue
 		}
+		partitions[partition.Name] = partition
 		existingAllocations := cc.convertAllocations(node.ExistingAllocations)
 		err := partition.AddNode(sn, existingAllocations)
 		if err != nil {
@@ -628,12 +886,18 @@ func (cc *ClusterContext) addNodes(request *si.UpdateRequest) {
 			zap.String("partition", sn.Partition))
 	}
 
+	// collect the resulting totals for the partitions the nodes were added to
+	totals := make([]*rmevent.PartitionTotals, 0, len(partitions))
+	for _, partition := range partitions {
+		totals = append(totals, partition.getPartitionTotals())
+	}
 	// inform the RM which nodes have been accepted/rejected
 	cc.rmEventHandler.HandleEvent(
 		&rmevent.RMNodeUpdateEvent{
-			RmID:          request.RmID,
-			AcceptedNodes: acceptedNodes,
-			RejectedNodes: rejectedNodes,
+			RmID:            request.RmID,
+			AcceptedNodes:   acceptedNodes,
+			RejectedNodes:   rejectedNodes,
+			PartitionTotals: totals,
 		})
 }
 
@@ -674,17 +938,20 @@ func (cc *ClusterContext) processAsks(request *si.UpdateRequest) {
 				ApplicationID: siAsk.ApplicationID,
 				Reason:        msg,
 			})
+			askRejectedEvent(siAsk, objects.AskRejectedInvalid, msg)
 			continue
 		}
 
 		// try adding to app
 		if err := partition.addAllocationAsk(siAsk); err != nil {
+			partition.countEvents(counterRejection, 1)
 			rejectedAsks = append(rejectedAsks,
 				&si.RejectedAllocationAsk{
 					AllocationKey: siAsk.AllocationKey,
 					ApplicationID: siAsk.ApplicationID,
 					Reason:        err.Error(),
 				})
+			askRejectedEvent(siAsk, objects.GetAskRejectedReason(err), err.Error())
 			log.Logger().Info("Invalid ask add requested by shim",
 				zap.String("partition", siAsk.PartitionName),
 				zap.String("applicationID", siAsk.ApplicationID),
@@ -702,6 +969,22 @@ func (cc *ClusterContext) processAsks(request *si.UpdateRequest) {
 	}
 }
 
+// Generate an event for the rejected ask with the reason of the rejection. The event is linked to the ask and
+// the application of the ask.
+func askRejectedEvent(siAsk *si.AllocationAsk, reason, message string) {
+	eventCache := events.GetEventCache()
+	if eventCache == nil {
+		return
+	}
+	if event, err := events.CreateRequestEventRecord(siAsk.AllocationKey, siAsk.ApplicationID, reason, message); err != nil {
+		log.Logger().Warn("Event creation failed",
+			zap.String("event message", message),
+			zap.Error(err))
+	} else {
+		eventCache.AddEvent(event)
+	}
+}
+
 func (cc *ClusterContext) processAskReleases(releases []*si.AllocationAskRelease) {
 	for _, toRelease := range releases {
 		partition := cc.GetPartition(toRelease.PartitionName)
@@ -801,6 +1084,7 @@ func (cc *ClusterContext) notifyRMNewAllocation(rmID string, alloc *objects.Allo
 		}
 	}
 
+	alloc.ExportAllocated()
 	// communicate the allocation to the RM
 	cc.rmEventHandler.HandleEvent(&rmevent.RMNewAllocationsEvent{
 		Allocations: []*si.Allocation{alloc.NewSIFromAllocation()},
@@ -823,11 +1107,55 @@ func (cc *ClusterContext) notifyRMAllocationReleased(rmID string, released []*ob
 			TerminationType: terminationType,
 			Message:         message,
 		})
+		alloc.ExportReleased(terminationType, message)
 	}
 
 	cc.rmEventHandler.HandleEvent(releaseEvent)
 }
 
+// Drain a node in the partition, the node is not removed. Allocations released from the node when preempting are
+// communicated to the RM to allow the RM to relocate them.
+// NOTE: this call is used by the webservice
+func (cc *ClusterContext) DrainNode(partition *PartitionContext, nodeID string, preempt bool) error {
+	released, err := partition.drainNode(nodeID, preempt)
+	if err != nil {
+		return err
+	}
+	cc.MarkStateChanged()
+	if len(released) != 0 {
+		cc.notifyRMAllocationReleased(partition.RmID, released, si.TerminationType_PREEMPTED_BY_SCHEDULER,
+			fmt.Sprintf("Node %s drained", nodeID))
+	}
+	return nil
+}
+
+// Detect the idle applications in the partition and reclaim their resources if configured.
+// The allocations released from the idle applications are communicated to the RM.
+func (cc *ClusterContext) reclaimIdleApplications(partition *PartitionContext) {
+	released := partition.reclaimIdleApplications()
+	if len(released) != 0 {
+		cc.MarkStateChanged()
+		cc.notifyRMAllocationReleased(partition.RmID, released, si.TerminationType_PREEMPTED_BY_SCHEDULER,
+			"resources reclaimed from idle application")
+	}
+}
+
+// Kill an application in the partition: the application is failed, its asks and reservations are removed and all
+// its allocations are released. The released allocations are communicated to the RM.
+// NOTE: this call is used by the webservice
+func (cc *ClusterContext) KillApplication(partition *PartitionContext, appID string) (*objects.Application, error) {
+	app, released, err := partition.killApplication(appID, "ApplicationKilled")
+	if err != nil {
+		return nil, err
+	}
+	cc.MarkStateChanged()
+	if len(released) != 0 {
+		cc.notifyRMAllocationReleased(partition.RmID, released, si.TerminationType_STOPPED_BY_RM,
+			fmt.Sprintf("Application %s killed", appID))
+	}
+	return app, nil
+}
+
 // Get a scheduling node based on its name from the partition.
 // Returns nil if the partition or node cannot be found.
 // Visible for tests
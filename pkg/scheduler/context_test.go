@@ -0,0 +1,174 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/configs"
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+	"github.com/apache/incubator-yunikorn-core/pkg/events"
+	"github.com/apache/incubator-yunikorn-core/pkg/rmproxy/rmevent"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/objects"
+	"github.com/apache/incubator-yunikorn-scheduler-interface/lib/go/si"
+)
+
+// records the events sent to the RM
+type rmEventRecorder struct {
+	events []interface{}
+	sync.Mutex
+}
+
+func (r *rmEventRecorder) HandleEvent(ev interface{}) {
+	r.Lock()
+	defer r.Unlock()
+	r.events = append(r.events, ev)
+}
+
+func TestProcessAsksRejectionEvents(t *testing.T) {
+	events.CreateAndSetEventCache()
+	cache := events.GetEventCache()
+	cache.StartService()
+	defer cache.Stop()
+
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	err = partition.AddApplication(newApplication(appID1, "default", defQueue))
+	assert.NilError(t, err, "failed to add application")
+	recorder := &rmEventRecorder{}
+	cc := &ClusterContext{
+		partitions:     map[string]*PartitionContext{partition.Name: partition},
+		rmEventHandler: recorder,
+	}
+	ask := func(key, appID, partitionName string, memory int64) *si.AllocationAsk {
+		return &si.AllocationAsk{
+			AllocationKey:  key,
+			ApplicationID:  appID,
+			PartitionName:  partitionName,
+			ResourceAsk:    &si.Resource{Resources: map[string]*si.Quantity{"memory": {Value: memory}}},
+			MaxAllocations: 1,
+		}
+	}
+	cc.processAsks(&si.UpdateRequest{
+		RmID: "rm:123",
+		Asks: []*si.AllocationAsk{
+			ask("alloc-ok", appID1, partition.Name, 10),
+			ask("alloc-partition", appID1, "unknown", 10),
+			ask("alloc-app", "unknown", partition.Name, 10),
+			ask("alloc-size", appID1, partition.Name, 0),
+		},
+	})
+
+	// the RM gets the rejections as before
+	assert.Equal(t, len(recorder.events), 1, "expected one event for the RM")
+	rejected, ok := recorder.events[0].(*rmevent.RMRejectedAllocationAskEvent)
+	assert.Assert(t, ok, "unexpected event type for the RM: %T", recorder.events[0])
+	assert.Equal(t, len(rejected.RejectedAllocationAsks), 3, "unexpected number of rejected asks")
+
+	// each rejection also generates an event with the reason
+	expected := map[string]string{
+		"alloc-partition": objects.AskRejectedInvalid,
+		"alloc-app":       objects.AskRejectedInvalid,
+		"alloc-size":      objects.AskRejectedSize,
+	}
+	reasons := make(map[string]string)
+	for i := 0; i < 100 && len(reasons) < len(expected); i++ {
+		time.Sleep(10 * time.Millisecond)
+		for _, event := range cache.Store.CollectEvents() {
+			assert.Equal(t, event.Type, si.EventRecord_REQUEST, "unexpected event type")
+			reasons[event.ObjectID] = event.Reason
+		}
+	}
+	assert.DeepEqual(t, reasons, expected)
+}
+
+func TestScheduleSchedulingDisabled(t *testing.T) {
+	partition := createQueuesNodes(t)
+	if partition == nil {
+		t.Fatal("partition create failed")
+	}
+	app := newApplication(appID1, "default", "root.leaf")
+	err := partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app-1 to partition")
+	var res *resources.Resource
+	res, err = resources.NewResourceFromConf(map[string]string{"first": "1"})
+	assert.NilError(t, err, "failed to create resource")
+	err = app.AddAllocationAsk(newAllocationAsk("alloc-1", appID1, res))
+	assert.NilError(t, err, "failed to add ask alloc-1 to app-1")
+	cc := &ClusterContext{
+		partitions:     map[string]*PartitionContext{partition.Name: partition},
+		rmEventHandler: &rmEventRecorder{},
+	}
+
+	assert.Assert(t, partition.IsSchedulingEnabled(), "scheduling should be enabled by default")
+	partition.SetSchedulingEnabled(false)
+	cc.schedule()
+	assert.Equal(t, partition.GetTotalAllocationCount(), 0, "disabled partition should not allocate")
+	assert.Assert(t, resources.Equals(app.GetPendingResource(), res), "ask should still be pending")
+
+	partition.SetSchedulingEnabled(true)
+	cc.schedule()
+	assert.Equal(t, partition.GetTotalAllocationCount(), 1, "enabled partition should allocate")
+
+	// the configuration replaces the runtime state
+	conf := configs.PartitionConfig{
+		Name: "default",
+		Queues: []configs.QueueConfig{
+			{
+				Name:   "root",
+				Parent: true,
+				Queues: []configs.QueueConfig{{Name: "leaf"}},
+			},
+		},
+		Scheduling: configs.PartitionSchedulingConfig{Disabled: true},
+	}
+	err = partition.updatePartitionDetails(conf)
+	assert.NilError(t, err, "partition update failed")
+	assert.Assert(t, !partition.IsSchedulingEnabled(), "scheduling should be disabled by the config")
+}
+
+func TestWebServiceConfigHandler(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(configDefault))
+	cc, err := NewClusterContext("rmID", "policyGroup")
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+	var applied []configs.WebServiceConfig
+	cc.SetWebServiceConfigHandler(func(conf configs.WebServiceConfig) {
+		applied = append(applied, conf)
+	})
+
+	var conf *configs.SchedulerConfig
+	conf, err = configs.LoadSchedulerConfigFromByteArray([]byte(configDefault + `
+webservice:
+  address: 127.0.0.1:9090
+  allowedorigins:
+    - https://dashboard.example.com
+`))
+	assert.NilError(t, err, "failed to load config")
+	cc.Lock()
+	err = cc.updateSchedulerConfig(conf, "rmID")
+	cc.Unlock()
+	assert.NilError(t, err, "config update failed")
+	assert.Equal(t, len(applied), 1, "handler should be called on the config update")
+	assert.Equal(t, applied[0].Address, "127.0.0.1:9090")
+	assert.DeepEqual(t, applied[0].AllowedOrigins, []string{"https://dashboard.example.com"})
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
 	"github.com/apache/incubator-yunikorn-core/pkg/interfaces"
 	"github.com/apache/incubator-yunikorn-core/pkg/log"
+	"github.com/apache/incubator-yunikorn-core/pkg/plugins"
 	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/objects"
 )
 
@@ -137,11 +138,10 @@ func trySurgicalPreemptionOnNode(preemptionPartitionCtx *preemptionPartitionCont
 	// the scheduling node's available resource takes into account what is being allocated
 	resourceToPreempt := resources.SubEliminateNegative(candidate.AllocatedResource, node.GetAvailableResource())
 
-	toReleaseAllocations := make(map[string]*objects.Allocation)
-	totalReleasedResource := resources.NewResource()
-
-	// Otherwise, try to do preemption, list all allocations on the node.
+	// Otherwise, try to do preemption, list all allocations on the node the preemptor queue can preempt.
 	// Fixme: this operation has too many copies, should avoid for better perf
+	allocations := make(map[string]*objects.Allocation)
+	candidates := make([]*plugins.PreemptionCandidate, 0)
 	for _, alloc := range node.GetAllAllocations() {
 		queueName := alloc.QueueName
 		// Try to do preemption.
@@ -150,6 +150,11 @@ func trySurgicalPreemptionOnNode(preemptionPartitionCtx *preemptionPartitionCont
 			continue
 		}
 
+		// Skip when the queue is in a fenced subtree that does not contain the preemptor queue
+		if !preemptQueue.schedulingQueue.CanBePreemptedFrom(preemptorQueue.queuePath) {
+			continue
+		}
+
 		// Skip when the queue has <= 0 preempt-able resource
 		if resources.CompUsageRatio(preemptQueue.resources.preemptable, resources.Zero, preemptionPartitionCtx.partitionTotalResource) <= 0 {
 			continue
@@ -162,6 +167,34 @@ func trySurgicalPreemptionOnNode(preemptionPartitionCtx *preemptionPartitionCont
 		if resources.CompUsageRatio(postPreemption, preemptQueue.resources.preemptable, preemptionPartitionCtx.partitionTotalResource) >= 0 {
 			continue
 		}
+		allocations[alloc.UUID] = alloc
+		candidates = append(candidates, &plugins.PreemptionCandidate{
+			UUID:          alloc.UUID,
+			ApplicationID: alloc.ApplicationID,
+			QueuePath:     alloc.QueueName,
+			NodeID:        alloc.NodeID,
+			Priority:      alloc.Priority,
+			CreateTime:    alloc.GetProposalTime(),
+			Placeholder:   alloc.IsPlaceholder(),
+			Tags:          alloc.Tags,
+			Resource:      alloc.AllocatedResource,
+		})
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	toReleaseAllocations := make(map[string]*objects.Allocation)
+	totalReleasedResource := resources.NewResource()
+
+	// the policy decides which allocations are preempted and in which order
+	for _, victim := range plugins.GetPreemptionPolicyPlugin().SelectVictims(candidates, preemptorQueue.queuePath, resourceToPreempt) {
+		// the policy can only pick from the candidates
+		alloc, ok := allocations[victim.UUID]
+		if !ok || toReleaseAllocations[victim.UUID] != nil {
+			continue
+		}
+		preemptQueue := preemptionPartitionCtx.leafQueues[alloc.QueueName]
 
 		// Add one more check, to make sure that preempted resource will be used by candidate queue.
 		// When this check fails it means preempted container doesn't make a positive contribution towards preemptor queue and its parents' headroom shortages. (
@@ -0,0 +1,110 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/configs"
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+	"github.com/apache/incubator-yunikorn-core/pkg/plugins"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/objects"
+)
+
+// Create a preemption context with a full node, the allocations on the node are in the fenced queue root.tenant.a.
+func newPreemptionTestContext(t *testing.T) (*preemptionPartitionContext, *objects.Node) {
+	conf := configs.PartitionConfig{
+		Name: "test",
+		Queues: []configs.QueueConfig{
+			{
+				Name:      "root",
+				Parent:    true,
+				SubmitACL: "*",
+				Queues: []configs.QueueConfig{
+					{
+						Name:       "tenant",
+						Parent:     true,
+						Properties: map[string]string{configs.PreemptionFence: "true"},
+						Queues:     []configs.QueueConfig{{Name: "a"}, {Name: "b"}},
+					},
+					{Name: "other"},
+				},
+			},
+		},
+	}
+	partition, err := newPartitionContext(conf, rmID, nil)
+	assert.NilError(t, err, "partition create failed")
+
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 5})
+	total := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 10})
+	node := newNodeMaxResource(nodeID1, total)
+	for _, uuid := range []string{"uuid-1", "uuid-2"} {
+		alloc := objects.NewAllocation(uuid, nodeID1, newAllocationAsk(uuid, appID1, res))
+		alloc.QueueName = "root.tenant.a"
+		alloc.Tags = map[string]string{"role": uuid}
+		assert.Assert(t, node.AddAllocation(alloc), "failed to add allocation to node")
+	}
+	ctx := &preemptionPartitionContext{
+		partitionTotalResource: total,
+		leafQueues:             make(map[string]*preemptionQueueContext),
+	}
+	for _, path := range []string{"root.tenant.a", "root.tenant.b", "root.other"} {
+		ctx.leafQueues[path] = &preemptionQueueContext{
+			queuePath:       path,
+			schedulingQueue: partition.GetQueue(path),
+			resources:       newQueuePreemptCalcResource(),
+		}
+	}
+	ctx.leafQueues["root.tenant.a"].resources.preemptable = total.Clone()
+	return ctx, node
+}
+
+func TestSurgicalPreemptionFence(t *testing.T) {
+	ctx, node := newPreemptionTestContext(t)
+	candidate := newAllocationAsk("candidate", appID2, resources.NewResourceFromMap(map[string]resources.Quantity{"first": 5}))
+	result := trySurgicalPreemptionOnNode(ctx, ctx.leafQueues["root.other"], node, candidate, map[string]*resources.Resource{})
+	assert.Assert(t, result == nil, "demand outside the fenced subtree should not preempt")
+	result = trySurgicalPreemptionOnNode(ctx, ctx.leafQueues["root.tenant.b"], node, candidate, map[string]*resources.Resource{})
+	assert.Assert(t, result != nil, "demand inside the fenced subtree should preempt")
+	assert.Equal(t, len(result.toReleaseAllocations), 1, "expected one allocation to be preempted")
+}
+
+func TestSurgicalPreemptionPolicyPlugin(t *testing.T) {
+	ctx, node := newPreemptionTestContext(t)
+	plugin := &fakePreemptionPolicyPlugin{protected: map[string]bool{"uuid-1": true}}
+	plugins.RegisterSchedulerPlugin(plugin)
+	defer plugins.UnregisterPreemptionPolicyPlugin()
+
+	// the plugin protects one allocation: only the other can be preempted
+	candidate := newAllocationAsk("candidate", appID2, resources.NewResourceFromMap(map[string]resources.Quantity{"first": 5}))
+	result := trySurgicalPreemptionOnNode(ctx, ctx.leafQueues["root.tenant.b"], node, candidate, map[string]*resources.Resource{})
+	assert.Assert(t, result != nil, "preemption should have been possible")
+	assert.Equal(t, len(result.toReleaseAllocations), 1, "expected one allocation to be preempted")
+	assert.Assert(t, result.toReleaseAllocations["uuid-2"] != nil, "protected allocation should not be preempted")
+	assert.Equal(t, len(plugin.candidates), 2, "both allocations should have been candidates")
+	assert.Equal(t, plugin.askQueue, "root.tenant.b", "unexpected ask queue passed to the plugin")
+	assert.Assert(t, resources.Equals(plugin.required, candidate.AllocatedResource), "unexpected required resource: %v", plugin.required)
+
+	// nothing left to preempt if more is needed than the plugin allows
+	candidate = newAllocationAsk("candidate", appID2, resources.NewResourceFromMap(map[string]resources.Quantity{"first": 10}))
+	result = trySurgicalPreemptionOnNode(ctx, ctx.leafQueues["root.tenant.b"], node, candidate, map[string]*resources.Resource{})
+	assert.Assert(t, result == nil, "protected allocation should not have been preempted")
+}
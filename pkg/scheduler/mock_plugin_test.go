@@ -5,7 +5,9 @@ import (
 
 	"go.uber.org/zap"
 
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
 	"github.com/apache/incubator-yunikorn-core/pkg/log"
+	"github.com/apache/incubator-yunikorn-core/pkg/plugins"
 	"github.com/apache/incubator-yunikorn-scheduler-interface/lib/go/si"
 )
 
@@ -48,3 +50,33 @@ func newFakePredicatePlugin(mustFail bool, nodes map[string]int) *fakePredicateP
 		nodes:    nodes,
 	}
 }
+
+// A fake application usage plugin that flags the listed applications as idle.
+type fakeAppUsagePlugin struct {
+	idle map[string]bool
+}
+
+func (f *fakeAppUsagePlugin) IsApplicationIdle(applicationID string) bool {
+	return f.idle[applicationID]
+}
+
+// A fake preemption policy that never selects the protected allocations and records the last call.
+type fakePreemptionPolicyPlugin struct {
+	protected  map[string]bool
+	candidates []*plugins.PreemptionCandidate
+	askQueue   string
+	required   *resources.Resource
+}
+
+func (f *fakePreemptionPolicyPlugin) SelectVictims(candidates []*plugins.PreemptionCandidate, askQueue string, required *resources.Resource) []*plugins.PreemptionCandidate {
+	f.candidates = candidates
+	f.askQueue = askQueue
+	f.required = required
+	victims := make([]*plugins.PreemptionCandidate, 0)
+	for _, candidate := range candidates {
+		if !f.protected[candidate.UUID] {
+			victims = append(victims, candidate)
+		}
+	}
+	return victims
+}
@@ -0,0 +1,158 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/objects"
+	"github.com/apache/incubator-yunikorn-core/pkg/webservice/dao"
+)
+
+// Index of the nodes of a partition by the value of the configured node attributes.
+// The index is updated when nodes are added, removed or change attributes. The resources of a group are summed
+// over the nodes in the group when requested: the nodes track their own capacity and allocated resources.
+// Nodes that do not have the attribute set are grouped under the empty value.
+// NOTE: the index is not locked, the partition lock protects it.
+type nodeGroups struct {
+	groups map[string]map[string]map[string]*objects.Node // attribute -> value -> node ID -> node
+	values map[string]map[string]string                   // node ID -> attribute -> value the node is indexed under
+}
+
+func newNodeGroups(attributes []string) *nodeGroups {
+	ng := &nodeGroups{
+		groups: make(map[string]map[string]map[string]*objects.Node),
+		values: make(map[string]map[string]string),
+	}
+	for _, attribute := range attributes {
+		ng.groups[attribute] = make(map[string]map[string]*objects.Node)
+	}
+	return ng
+}
+
+// Check if the index tracks exactly the given attributes.
+func (ng *nodeGroups) hasAttributes(attributes []string) bool {
+	if len(attributes) != len(ng.groups) {
+		return false
+	}
+	for _, attribute := range attributes {
+		if _, ok := ng.groups[attribute]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Add the node to the groups based on the current attributes of the node.
+func (ng *nodeGroups) addNode(node *objects.Node) {
+	values := make(map[string]string, len(ng.groups))
+	for attribute, group := range ng.groups {
+		value := node.GetAttribute(attribute)
+		if group[value] == nil {
+			group[value] = make(map[string]*objects.Node)
+		}
+		group[value][node.NodeID] = node
+		values[attribute] = value
+	}
+	ng.values[node.NodeID] = values
+}
+
+// Remove the node from the groups it was added to, empty groups are removed.
+func (ng *nodeGroups) removeNode(nodeID string) {
+	for attribute, value := range ng.values[nodeID] {
+		group := ng.groups[attribute]
+		delete(group[value], nodeID)
+		if len(group[value]) == 0 {
+			delete(group, value)
+		}
+	}
+	delete(ng.values, nodeID)
+}
+
+// Move the node to the groups that match the current attributes of the node.
+func (ng *nodeGroups) updateNode(node *objects.Node) {
+	if _, ok := ng.values[node.NodeID]; !ok {
+		return
+	}
+	ng.removeNode(node.NodeID)
+	ng.addNode(node)
+}
+
+// Get the resources of the groups for the attribute sorted by the attribute value.
+func (ng *nodeGroups) getResources(attribute string) ([]dao.NodeGroupResourcesDAOInfo, error) {
+	group, ok := ng.groups[attribute]
+	if !ok {
+		return nil, fmt.Errorf("node attribute %s is not configured as a node group", attribute)
+	}
+	result := make([]dao.NodeGroupResourcesDAOInfo, 0, len(group))
+	for value, nodes := range group {
+		capacity := resources.NewResource()
+		allocated := resources.NewResource()
+		for _, node := range nodes {
+			capacity.AddTo(node.GetCapacity())
+			allocated.AddTo(node.GetAllocatedResource())
+		}
+		result = append(result, dao.NodeGroupResourcesDAOInfo{
+			Value:     value,
+			Nodes:     len(nodes),
+			Capacity:  capacity.DAOString(),
+			Allocated: allocated.DAOString(),
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Value < result[j].Value
+	})
+	return result, nil
+}
+
+// Set the node attributes the partition resources are grouped by.
+// The index is rebuilt from the current nodes if the attributes changed.
+func (pc *PartitionContext) setNodeGroups(attributes []string) {
+	if pc.nodeGroups != nil && pc.nodeGroups.hasAttributes(attributes) {
+		return
+	}
+	pc.nodeGroups = newNodeGroups(attributes)
+	for _, node := range pc.nodes {
+		pc.nodeGroups.addNode(node)
+	}
+}
+
+// Replace the attributes of a node and move the node to the matching node groups.
+// NOTE: this is a lock free call. It must only be called holding the PartitionContext lock.
+func (pc *PartitionContext) updateNodeAttributes(node *objects.Node, attributes map[string]string) {
+	node.SetAttributes(attributes)
+	pc.nodeGroups.updateNode(node)
+}
+
+// Get the partition resources grouped by the value of the node attribute to pass to the webservice.
+func (pc *PartitionContext) GetNodeGroupResources(attribute string) (*dao.PartitionResourcesDAOInfo, error) {
+	pc.RLock()
+	defer pc.RUnlock()
+	groups, err := pc.nodeGroups.getResources(attribute)
+	if err != nil {
+		return nil, err
+	}
+	return &dao.PartitionResourcesDAOInfo{
+		Partition: pc.Name,
+		GroupBy:   attribute,
+		Groups:    groups,
+	}, nil
+}
@@ -0,0 +1,103 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/configs"
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/objects"
+	"github.com/apache/incubator-yunikorn-scheduler-interface/lib/go/si"
+)
+
+func newZoneNode(nodeID, zone string, res *resources.Resource) *objects.Node {
+	proto := &si.NewNodeInfo{
+		NodeID:              nodeID,
+		Attributes:          map[string]string{"zone": zone},
+		SchedulableResource: res.ToProto(),
+	}
+	return objects.NewNode(proto)
+}
+
+func TestNodeGroupResources(t *testing.T) {
+	conf := configs.PartitionConfig{
+		Name: "test",
+		Queues: []configs.QueueConfig{
+			{Name: "root", Parent: true, SubmitACL: "*"},
+		},
+		NodeGroups: []string{"zone"},
+	}
+	partition, err := newPartitionContext(conf, rmID, nil)
+	assert.NilError(t, err, "partition create failed")
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 10})
+	node1 := newZoneNode(nodeID1, "zone-a", res)
+	err = partition.AddNode(node1, nil)
+	assert.NilError(t, err, "node-1 add failed")
+	err = partition.AddNode(newZoneNode(nodeID2, "zone-a", res), nil)
+	assert.NilError(t, err, "node-2 add failed")
+	err = partition.AddNode(newZoneNode("node-3", "zone-b", res), nil)
+	assert.NilError(t, err, "node-3 add failed")
+	ask := newAllocationAsk("alloc-1", appID1, resources.NewResourceFromMap(map[string]resources.Quantity{"first": 4}))
+	node1.AddAllocation(objects.NewAllocation("uuid-1", nodeID1, ask))
+
+	_, err = partition.GetNodeGroupResources("rack")
+	assert.ErrorContains(t, err, "not configured as a node group")
+	result, err := partition.GetNodeGroupResources("zone")
+	assert.NilError(t, err, "zone groups should be tracked")
+	assert.Equal(t, result.GroupBy, "zone", "unexpected group by attribute")
+	assert.Equal(t, len(result.Groups), 2, "expected 2 zones")
+	assert.Equal(t, result.Groups[0].Value, "zone-a", "groups should be sorted by value")
+	assert.Equal(t, result.Groups[0].Nodes, 2, "unexpected node count for zone-a")
+	assert.Equal(t, result.Groups[0].Capacity, "[first:20]", "unexpected capacity for zone-a")
+	assert.Equal(t, result.Groups[0].Allocated, "[first:4]", "unexpected allocated for zone-a")
+	assert.Equal(t, result.Groups[1].Nodes, 1, "unexpected node count for zone-b")
+
+	// moving a node to a new zone updates the groups
+	missing := partition.updateNodes([]*si.UpdateNodeInfo{{
+		NodeID:     nodeID1,
+		Attributes: map[string]string{"zone": "zone-b"},
+	}})
+	assert.Equal(t, len(missing), 0, "node should have been found")
+	result, err = partition.GetNodeGroupResources("zone")
+	assert.NilError(t, err, "zone groups should be tracked")
+	assert.Equal(t, result.Groups[0].Nodes, 1, "node should have left zone-a")
+	assert.Equal(t, result.Groups[1].Nodes, 2, "node should have joined zone-b")
+	assert.Equal(t, result.Groups[1].Allocated, "[first:4]", "unexpected allocated for zone-b")
+
+	// removing the last node of a zone removes the group
+	partition.removeNode(nodeID2)
+	result, err = partition.GetNodeGroupResources("zone")
+	assert.NilError(t, err, "zone groups should be tracked")
+	assert.Equal(t, len(result.Groups), 1, "empty zone should have been removed")
+
+	// changing the config rebuilds the index from the current nodes
+	conf.NodeGroups = []string{"instance-type"}
+	err = partition.updatePartitionDetails(conf)
+	assert.NilError(t, err, "partition update failed")
+	_, err = partition.GetNodeGroupResources("zone")
+	assert.ErrorContains(t, err, "not configured as a node group")
+	result, err = partition.GetNodeGroupResources("instance-type")
+	assert.NilError(t, err, "instance type groups should be tracked")
+	assert.Equal(t, len(result.Groups), 1, "nodes without the attribute should be in one group")
+	assert.Equal(t, result.Groups[0].Value, "", "nodes without the attribute should have an empty value")
+	assert.Equal(t, result.Groups[0].Nodes, 2, "unexpected node count")
+}
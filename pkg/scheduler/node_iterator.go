@@ -112,3 +112,28 @@ func (ri *roundRobinNodeIterator) Reset() {
 	ri.countIdx = 0
 	ri.startIdx = -1
 }
+
+// Tie rotating iterator, wraps the base iterator.
+// Iterates over the groups of nodes with an equal sorting score in order. Within each group the iteration starts at
+// a rotated position and wraps at the end of the group, like the round robin iterator does for the whole list.
+// This spreads the placements over nodes that have the same score instead of always starting at the same node.
+type tieRotatingNodeIterator struct {
+	baseIterator
+}
+
+// Create a new tie rotating iterator: the groups are the end indexes of the groups of equal nodes in the sorted list.
+// The rotation sets the starting position in each group as the rotation modulo the group size.
+func newTieRotatingNodeIterator(schedulerNodes []*objects.Node, groups []int, rotation uint32) *tieRotatingNodeIterator {
+	it := &tieRotatingNodeIterator{}
+	it.nodes = make([]*objects.Node, 0, len(schedulerNodes))
+	start := 0
+	for _, end := range groups {
+		size := end - start
+		offset := int(rotation % uint32(size))
+		it.nodes = append(it.nodes, schedulerNodes[start+offset:end]...)
+		it.nodes = append(it.nodes, schedulerNodes[start:start+offset]...)
+		start = end
+	}
+	it.size = len(it.nodes)
+	return it
+}
@@ -183,3 +183,55 @@ func TestDefaultNodeIterating(t *testing.T) {
 		t.Errorf("incorrect node returned expected node-0 got: %v", node)
 	}
 }
+
+// test iterating over groups of equal nodes with a rotated start
+func TestTieRotatingNodeIterating(t *testing.T) {
+	nodes := newSchedNodeList(5)
+	tests := []struct {
+		name     string
+		groups   []int
+		rotation uint32
+		expected []int
+	}{
+		{"empty", []int{}, 1, []int{}},
+		{"no rotation", []int{2, 5}, 0, []int{0, 1, 2, 3, 4}},
+		{"rotate one", []int{2, 5}, 1, []int{1, 0, 3, 4, 2}},
+		{"rotate wraps", []int{2, 5}, 4, []int{0, 1, 3, 4, 2}},
+		{"single group", []int{5}, 7, []int{2, 3, 4, 0, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list := nodes
+			if len(tt.groups) == 0 {
+				list = nil
+			}
+			it := newTieRotatingNodeIterator(list, tt.groups, tt.rotation)
+			got := make([]int, 0)
+			for it.HasNext() {
+				node, ok := it.Next().(*objects.Node)
+				if !ok {
+					t.Fatal("iterator returned a non node object")
+				}
+				idx, err := strconv.Atoi(node.NodeID[len("node-"):])
+				if err != nil {
+					t.Fatalf("unexpected node ID %s", node.NodeID)
+				}
+				got = append(got, idx)
+			}
+			if len(got) != len(tt.expected) {
+				t.Fatalf("iterator returned %v, expected %v", got, tt.expected)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Errorf("iterator returned %v, expected %v", got, tt.expected)
+					break
+				}
+			}
+			// reset starts at the beginning of the same order
+			it.Reset()
+			if len(tt.expected) > 0 && it.Next().(*objects.Node) != nodes[tt.expected[0]] {
+				t.Error("reset did not return to the first node")
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"github.com/apache/incubator-yunikorn-core/pkg/metrics"
+	"github.com/apache/incubator-yunikorn-core/pkg/rmproxy/rmevent"
+	"github.com/apache/incubator-yunikorn-scheduler-interface/lib/go/si"
+)
+
+// The maximum number of queued events merged into one node status update
+const maxMergedNodeUpdateEvents = 100
+
+// Merge the node status updates for the same node, keeping the latest value for each field.
+// The updates are kept in the order the nodes were first seen.
+type nodeUpdateMerger struct {
+	updates []*si.UpdateNodeInfo
+	nodes   map[string]int
+	merged  int
+}
+
+func newNodeUpdateMerger() *nodeUpdateMerger {
+	return &nodeUpdateMerger{
+		updates: make([]*si.UpdateNodeInfo, 0),
+		nodes:   make(map[string]int),
+	}
+}
+
+func (nm *nodeUpdateMerger) add(updates []*si.UpdateNodeInfo) {
+	for _, update := range updates {
+		idx, ok := nm.nodes[update.NodeID]
+		if !ok {
+			nm.nodes[update.NodeID] = len(nm.updates)
+			nm.updates = append(nm.updates, update)
+			continue
+		}
+		// copy the fields that are set, never change the original update
+		latest := *nm.updates[idx]
+		if len(update.Attributes) != 0 {
+			latest.Attributes = update.Attributes
+		}
+		if update.SchedulableResource != nil {
+			latest.SchedulableResource = update.SchedulableResource
+		}
+		if update.OccupiedResource != nil {
+			latest.OccupiedResource = update.OccupiedResource
+		}
+		nm.updates[idx] = &latest
+		nm.merged++
+	}
+}
+
+// Merge the node status updates from the same RM that are already queued into the update.
+// Returns the merged update, or the original update if nothing was merged.
+// Events are never reordered: merging stops at the first event of the RM that is not a node status update.
+func (s *Scheduler) mergeNodeUpdates(event *rmevent.RMUpdateRequestEvent, queue *rmEventQueue) *rmevent.RMUpdateRequestEvent {
+	merger := newNodeUpdateMerger()
+	merger.add(event.Request.UpdatedNodes)
+	for i := 0; i < maxMergedNodeUpdateEvents; i++ {
+		// pick up the events that arrived since the queue was filled
+		s.fillEventQueue(queue)
+		update := queue.nextNodeStatusUpdate(event.Request.RmID)
+		if update == nil {
+			break
+		}
+		merger.add(update.Request.UpdatedNodes)
+	}
+	if merger.merged == 0 {
+		return event
+	}
+	metrics.GetSchedulerMetrics().AddMergedNodeUpdates(merger.merged)
+	return &rmevent.RMUpdateRequestEvent{
+		Request: &si.UpdateRequest{
+			UpdatedNodes: merger.updates,
+			RmID:         event.Request.RmID,
+		},
+	}
+}
@@ -0,0 +1,106 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+	"github.com/apache/incubator-yunikorn-core/pkg/rmproxy/rmevent"
+	siCommon "github.com/apache/incubator-yunikorn-scheduler-interface/lib/go/common"
+	"github.com/apache/incubator-yunikorn-scheduler-interface/lib/go/si"
+)
+
+func nodeUpdateEvent(rmID string, updates ...*si.UpdateNodeInfo) *rmevent.RMUpdateRequestEvent {
+	return &rmevent.RMUpdateRequestEvent{
+		Request: &si.UpdateRequest{
+			UpdatedNodes: updates,
+			RmID:         rmID,
+		},
+	}
+}
+
+func TestUpdateNodesInOrder(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "test partition create failed with error")
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 100})
+	for _, nodeID := range []string{nodeID1, nodeID2} {
+		err = partition.AddNode(newNodeMaxResource(nodeID, res), nil)
+		assert.NilError(t, err, "node add failed unexpected")
+	}
+	cc := &ClusterContext{partitions: map[string]*PartitionContext{partition.Name: partition}}
+	attributes := map[string]string{siCommon.NodePartition: partition.Name}
+	capacity := resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 200}).ToProto()
+	// plain updates before and after a node state change are all applied
+	cc.updateNodes(&si.UpdateRequest{
+		UpdatedNodes: []*si.UpdateNodeInfo{
+			{NodeID: nodeID1, Attributes: attributes, SchedulableResource: capacity, Action: si.UpdateNodeInfo_UPDATE},
+			{NodeID: nodeID1, Attributes: attributes, Action: si.UpdateNodeInfo_DRAIN_NODE},
+			{NodeID: nodeID2, Attributes: attributes, SchedulableResource: capacity, Action: si.UpdateNodeInfo_UPDATE},
+		},
+	})
+	expected := resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 400})
+	assert.Assert(t, resources.Equals(partition.GetTotalPartitionResource(), expected), "unexpected partition resource: %s", partition.GetTotalPartitionResource())
+	assert.Assert(t, !partition.GetNode(nodeID1).IsSchedulable(), "node-1 should have been drained")
+	assert.Assert(t, partition.GetNode(nodeID2).IsSchedulable(), "node-2 should still be schedulable")
+}
+
+func TestMergeNodeUpdates(t *testing.T) {
+	s := &Scheduler{pendingEvents: make(chan interface{}, 10)}
+	queue := newRMEventQueue(func(string) float64 { return 1 })
+	capacity := &si.Resource{Resources: map[string]*si.Quantity{"first": {Value: 10}}}
+	occupied1 := &si.Resource{Resources: map[string]*si.Quantity{"first": {Value: 1}}}
+	occupied2 := &si.Resource{Resources: map[string]*si.Quantity{"first": {Value: 2}}}
+	first := nodeUpdateEvent("rm-1",
+		&si.UpdateNodeInfo{NodeID: nodeID1, SchedulableResource: capacity, Action: si.UpdateNodeInfo_UPDATE})
+
+	// nothing queued: the event is returned unchanged
+	merged := s.mergeNodeUpdates(first, queue)
+	assert.Equal(t, merged, first, "event without merges should be returned unchanged")
+
+	// updates for the same node are merged field by field, events from other RMs are skipped and
+	// merging stops at the first event of the RM that is not a node status update
+	s.pendingEvents <- nodeUpdateEvent("rm-1",
+		&si.UpdateNodeInfo{NodeID: nodeID1, OccupiedResource: occupied1, Action: si.UpdateNodeInfo_UPDATE},
+		&si.UpdateNodeInfo{NodeID: nodeID2, OccupiedResource: occupied1, Action: si.UpdateNodeInfo_UPDATE})
+	s.pendingEvents <- nodeUpdateEvent("rm-1",
+		&si.UpdateNodeInfo{NodeID: nodeID1, OccupiedResource: occupied2, Action: si.UpdateNodeInfo_UPDATE})
+	other := nodeUpdateEvent("rm-2",
+		&si.UpdateNodeInfo{NodeID: nodeID1, OccupiedResource: occupied1, Action: si.UpdateNodeInfo_UPDATE})
+	s.pendingEvents <- other
+	register := &rmevent.RMRegistrationEvent{Registration: &si.RegisterResourceManagerRequest{RmID: "rm-1"}}
+	s.pendingEvents <- register
+	s.pendingEvents <- nodeUpdateEvent("rm-1",
+		&si.UpdateNodeInfo{NodeID: nodeID1, OccupiedResource: occupied1, Action: si.UpdateNodeInfo_UPDATE})
+	merged = s.mergeNodeUpdates(first, queue)
+	assert.Equal(t, len(s.pendingEvents), 0, "all events should have been read")
+	assert.Equal(t, queue.size, 3, "unmerged events should stay queued")
+	assert.Equal(t, queue.next(), register, "merging should stop at the registration")
+	assert.Equal(t, queue.next(), other, "event from other RM should not be merged")
+	assert.Equal(t, merged.Request.RmID, "rm-1", "unexpected RM on merged event")
+	updates := merged.Request.UpdatedNodes
+	assert.Equal(t, len(updates), 2, "expected one update per node")
+	assert.Equal(t, updates[0].NodeID, nodeID1, "node order should be kept")
+	assert.Equal(t, updates[0].SchedulableResource, capacity, "capacity should have been kept")
+	assert.Equal(t, updates[0].OccupiedResource, occupied2, "latest occupied resource should have been kept")
+	assert.Equal(t, updates[1].NodeID, nodeID2, "node order should be kept")
+	assert.Assert(t, first.Request.UpdatedNodes[0].OccupiedResource == nil, "original update should not change")
+}
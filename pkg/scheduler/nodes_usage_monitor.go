@@ -54,6 +54,8 @@ func (m *nodesResourceUsageMonitor) start() {
 
 func (m *nodesResourceUsageMonitor) runOnce() {
 	for _, p := range m.cc.GetPartitionMapClone() {
+		// the rolling window counters change over time even without new events
+		p.updateCounterMetrics()
 		usageMap := p.calculateNodesResourceUsage()
 		if len(usageMap) > 0 {
 			for resourceName, usageBuckets := range usageMap {
@@ -20,11 +20,14 @@ package objects
 
 import (
 	"fmt"
+	"strconv"
+	"time"
 
 	"go.uber.org/zap"
 
 	"github.com/apache/incubator-yunikorn-core/pkg/common"
 	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+	"github.com/apache/incubator-yunikorn-core/pkg/export"
 	"github.com/apache/incubator-yunikorn-core/pkg/log"
 	"github.com/apache/incubator-yunikorn-scheduler-interface/lib/go/si"
 )
@@ -44,6 +47,13 @@ func (ar allocationResult) String() string {
 	return [...]string{"None", "Allocated", "AllocatedReserved", "Reserved", "Unreserved", "Replaced"}[ar]
 }
 
+// Allocation tags added to the allocation communicated to the RM with the cost of scheduling the allocation.
+const (
+	TelemetryQueueWait      = "yunikorn.apache.org/scheduling.queue-wait-ms"
+	TelemetryNodesEvaluated = "yunikorn.apache.org/scheduling.nodes-evaluated"
+	TelemetryCycleID        = "yunikorn.apache.org/scheduling.cycle-id"
+)
+
 /* Related to Allocation */
 type Allocation struct {
 	Ask               *AllocationAsk
@@ -62,6 +72,12 @@ type Allocation struct {
 	placeholder       bool
 	taskGroupName     string
 	released          bool
+	queueWait         time.Duration // time between the ask creation and the allocation
+	proposalTime      time.Time     // time the allocation was proposed by the scheduler
+	nodesEvaluated    int           // number of nodes checked before the allocation was made
+	schedulingCycle   uint64        // scheduling cycle the allocation was made in
+	spreadDomain      string        // node attribute value used for the application spread constraint
+	lifetimeTimer     *time.Timer   // maximum lifetime timer, guarded by the application lock
 }
 
 func NewAllocation(uuid, nodeID string, ask *AllocationAsk) *Allocation {
@@ -114,6 +130,7 @@ func NewAllocationFromSI(alloc *si.Allocation) *Allocation {
 		maxAllocations:    1,
 		taskGroupName:     alloc.TaskGroupName,
 		placeholder:       alloc.Placeholder,
+		maxLifetime:       parseMaxLifetime(alloc.ApplicationID, alloc.AllocationKey, alloc.AllocationTags),
 	}
 	return NewAllocation(alloc.UUID, alloc.NodeID, ask)
 }
@@ -134,6 +151,75 @@ func (a *Allocation) NewSIFromAllocation() *si.Allocation {
 		ResourcePerAlloc: a.AllocatedResource.ToProto(), // needed in tests for restore
 		TaskGroupName:    a.taskGroupName,
 		Placeholder:      a.placeholder,
+		AllocationTags:   a.getTelemetryTags(),
+	}
+}
+
+// Set the scheduling cycle the allocation was made in.
+// Must be called before the allocation is communicated to the RM.
+func (a *Allocation) SetSchedulingCycle(cycle uint64) {
+	a.schedulingCycle = cycle
+}
+
+// Return the time the allocation was proposed by the scheduler.
+// Returns the zero time if the allocation was not made by the scheduler, i.e. a recovered allocation.
+func (a *Allocation) GetProposalTime() time.Time {
+	return a.proposalTime
+}
+
+// Return the scheduling telemetry as allocation tags.
+// Returns nil if the allocation was not made by the scheduler, i.e. a recovered allocation.
+func (a *Allocation) getTelemetryTags() map[string]string {
+	if a.schedulingCycle == 0 && a.nodesEvaluated == 0 {
+		return nil
+	}
+	return map[string]string{
+		TelemetryQueueWait:      strconv.FormatInt(int64(a.queueWait/time.Millisecond), 10),
+		TelemetryNodesEvaluated: strconv.Itoa(a.nodesEvaluated),
+		TelemetryCycleID:        strconv.FormatUint(a.schedulingCycle, 10),
+	}
+}
+
+// Export the allocation as a confirmed scheduling decision if decision export is configured.
+func (a *Allocation) ExportAllocated() {
+	if exporter := export.GetDecisionExporter(); exporter != nil {
+		exporter.AddRecord(a.newDecisionRecord(export.Allocation))
+	}
+}
+
+// Export the release of the allocation as a scheduling decision if decision export is configured.
+// A release initiated by the scheduler to preempt the allocation is exported as a preemption.
+func (a *Allocation) ExportReleased(terminationType si.TerminationType, message string) {
+	if exporter := export.GetDecisionExporter(); exporter != nil {
+		decision := export.Release
+		if terminationType == si.TerminationType_PREEMPTED_BY_SCHEDULER {
+			decision = export.Preemption
+		}
+		record := a.newDecisionRecord(decision)
+		record.TerminationType = terminationType.String()
+		record.Message = message
+		exporter.AddRecord(record)
+	}
+}
+
+func (a *Allocation) newDecisionRecord(decision export.DecisionType) *export.DecisionRecord {
+	var res map[string]int64
+	if a.AllocatedResource != nil {
+		res = make(map[string]int64, len(a.AllocatedResource.Resources))
+		for name, quantity := range a.AllocatedResource.Resources {
+			res[name] = int64(quantity)
+		}
+	}
+	return &export.DecisionRecord{
+		Type:          decision,
+		Partition:     a.PartitionName,
+		ApplicationID: a.ApplicationID,
+		QueueName:     a.QueueName,
+		AllocationKey: a.AllocationKey,
+		UUID:          a.UUID,
+		NodeID:        a.NodeID,
+		Resource:      res,
+		Placeholder:   a.placeholder,
 	}
 }
 
@@ -152,6 +238,6 @@ func (a *Allocation) IsPlaceholder() bool {
 	return a.placeholder
 }
 
-func (a *Allocation) getTaskGroup() string {
+func (a *Allocation) GetTaskGroup() string {
 	return a.taskGroupName
 }
@@ -20,6 +20,7 @@ package objects
 
 import (
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -31,6 +32,15 @@ import (
 	"github.com/apache/incubator-yunikorn-scheduler-interface/lib/go/si"
 )
 
+// Ask tags with this prefix define a node attribute that the node must have for the ask to be placed on it.
+// The remainder of the tag key is the attribute name, the tag value the required attribute value.
+// An empty tag value only requires the attribute to be set on the node.
+const NodeAttributeTagPrefix = "yunikorn.apache.org/node-attribute/"
+
+// Ask tag that sets the maximum time an allocation of the ask can live as a duration (i.e. 24h). When the lifetime
+// is exceeded the allocation is released, the RM is expected to replace it.
+const MaxLifetimeTag = "yunikorn.apache.org/allocation.max-lifetime"
+
 type AllocationAsk struct {
 	// Extracted info
 	AllocationKey     string
@@ -48,6 +58,10 @@ type AllocationAsk struct {
 	createTime       time.Time // the time this ask was created (used in reservations)
 	priority         int32
 	maxAllocations   int32
+	nodeAttributes   map[string]string // node attributes required to place the ask, derived from the tags
+	tolerations      map[string]string // node taints tolerated by the ask, derived from the tags
+	maxLifetime      time.Duration     // maximum lifetime of the allocations of the ask, 0 is unlimited, derived from the tags
+	generation       uint64            // changes every time the ask is updated, invalidates cached predicate results
 
 	sync.RWMutex
 }
@@ -67,6 +81,9 @@ func NewAllocationAsk(ask *si.AllocationAsk) *AllocationAsk {
 		taskGroupName:     ask.TaskGroupName,
 	}
 	saa.priority = saa.normalizePriority(ask.Priority)
+	saa.nodeAttributes = getPrefixedTags(ask.Tags, NodeAttributeTagPrefix)
+	saa.tolerations = getPrefixedTags(ask.Tags, NodeTolerationTagPrefix)
+	saa.maxLifetime = parseMaxLifetime(ask.ApplicationID, ask.AllocationKey, ask.Tags)
 	// this is a safety check placeholder and task group name must be set as a combo
 	// order is important as task group can be set without placeholder but not the other way around
 	if saa.placeholder && saa.taskGroupName == "" {
@@ -84,6 +101,66 @@ func (aa *AllocationAsk) String() string {
 	return fmt.Sprintf("AllocationKey %s, ApplicationID %s, Resource %s, PendingRepeats %d", aa.AllocationKey, aa.ApplicationID, aa.AllocatedResource, aa.pendingRepeatAsk)
 }
 
+// Extract the tags with the prefix from the ask tags, the prefix is removed from the key.
+// Returns nil if the ask does not have any tags with the prefix.
+func getPrefixedTags(tags map[string]string, prefix string) map[string]string {
+	var prefixed map[string]string
+	for key, value := range tags {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		name := strings.TrimPrefix(key, prefix)
+		if name == "" {
+			continue
+		}
+		if prefixed == nil {
+			prefixed = make(map[string]string)
+		}
+		prefixed[name] = value
+	}
+	return prefixed
+}
+
+// Parse the maximum lifetime from the ask tags, an invalid value is ignored.
+func parseMaxLifetime(appID, allocKey string, tags map[string]string) time.Duration {
+	value, ok := tags[MaxLifetimeTag]
+	if !ok {
+		return 0
+	}
+	lifetime, err := time.ParseDuration(value)
+	if err != nil || lifetime <= 0 {
+		log.Logger().Warn("allocation maximum lifetime ignored, value must be a positive duration",
+			zap.String("appID", appID),
+			zap.String("allocationKey", allocKey),
+			zap.String("maxLifetime", value))
+		return 0
+	}
+	return lifetime
+}
+
+// Return the maximum lifetime of the allocations of the ask, 0 means unlimited.
+func (aa *AllocationAsk) GetMaxLifetime() time.Duration {
+	aa.RLock()
+	defer aa.RUnlock()
+	return aa.maxLifetime
+}
+
+// Return the node attributes required to place the ask.
+// Should be treated as read only not to be modified
+func (aa *AllocationAsk) GetRequiredNodeAttributes() map[string]string {
+	aa.RLock()
+	defer aa.RUnlock()
+	return aa.nodeAttributes
+}
+
+// Return the node taints tolerated by the ask.
+// Should be treated as read only not to be modified
+func (aa *AllocationAsk) GetTolerations() map[string]string {
+	aa.RLock()
+	defer aa.RUnlock()
+	return aa.tolerations
+}
+
 // Update pending ask repeat with the delta given.
 // Update the pending ask repeat counter with the delta (pos or neg). The pending repeat is always 0 or higher.
 // If the update would cause the repeat to go negative the update is discarded and false is returned.
@@ -114,6 +191,13 @@ func (aa *AllocationAsk) GetCreateTime() time.Time {
 	return aa.createTime
 }
 
+// Return the priority of the ask
+func (aa *AllocationAsk) GetPriority() int32 {
+	aa.RLock()
+	defer aa.RUnlock()
+	return aa.priority
+}
+
 // Set the queue name after it is added to the application
 func (aa *AllocationAsk) setQueue(queueName string) {
 	aa.Lock()
@@ -128,6 +212,32 @@ func (aa *AllocationAsk) normalizePriority(priority *si.Priority) int32 {
 	return priority.GetPriorityValue()
 }
 
+// Update the resource, repeat, priority and tags of the ask in place from the updated ask sent by the RM.
+// The node attributes, tolerations and maximum lifetime are derived from the new tags.
+// All other values, including the create time, are left unchanged.
+// The ask keeps its position and references from reservations to the ask stay valid.
+func (aa *AllocationAsk) updateFrom(updated *AllocationAsk) {
+	aa.Lock()
+	defer aa.Unlock()
+	aa.AllocatedResource = updated.AllocatedResource
+	aa.pendingRepeatAsk = updated.pendingRepeatAsk
+	aa.maxAllocations = updated.maxAllocations
+	aa.priority = updated.priority
+	aa.Tags = updated.Tags
+	aa.nodeAttributes = getPrefixedTags(updated.Tags, NodeAttributeTagPrefix)
+	aa.tolerations = getPrefixedTags(updated.Tags, NodeTolerationTagPrefix)
+	aa.maxLifetime = parseMaxLifetime(aa.ApplicationID, aa.AllocationKey, updated.Tags)
+	// the predicate results cached on the nodes for the ask are no longer used
+	aa.generation++
+}
+
+// Return the current generation of the ask.
+func (aa *AllocationAsk) getGeneration() uint64 {
+	aa.RLock()
+	defer aa.RUnlock()
+	return aa.generation
+}
+
 // Set the priority after it is created to the application
 func (aa *AllocationAsk) setPriority(prio int32) {
 	aa.Lock()
@@ -152,3 +262,36 @@ func (aa *AllocationAsk) getTimeout() time.Duration {
 	defer aa.RUnlock()
 	return aa.execTimeout
 }
+
+// The reasons an allocation ask is rejected, used as the reason of the event generated for the rejection.
+const (
+	AskRejectedInvalid   = "AskRejectedInvalid"   // the ask, or the partition or application it is for, is not valid
+	AskRejectedSize      = "AskRejectedSize"      // the ask does not request any resources
+	AskRejectedQuota     = "AskRejectedQuota"     // the ask is not allowed by the restrictions of the queue
+	AskRejectedCollision = "AskRejectedCollision" // the ask conflicts with the existing ask with the same key
+)
+
+// The error returned when an allocation ask is rejected with the reason of the rejection.
+type AskRejectedError struct {
+	Reason  string
+	message string
+}
+
+func NewAskRejectedError(reason, format string, args ...interface{}) *AskRejectedError {
+	return &AskRejectedError{
+		Reason:  reason,
+		message: fmt.Sprintf(format, args...),
+	}
+}
+
+func (are *AskRejectedError) Error() string {
+	return are.message
+}
+
+// Return the reason of the rejection for the error. An error without a reason is considered an invalid ask.
+func GetAskRejectedReason(err error) string {
+	if rejected, ok := err.(*AskRejectedError); ok {
+		return rejected.Reason
+	}
+	return AskRejectedInvalid
+}
@@ -19,6 +19,7 @@
 package objects
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
@@ -57,6 +58,75 @@ func TestNewAsk(t *testing.T) {
 	assert.Equal(t, askStr, expected, "Strings should have been equal")
 }
 
+func TestRequiredNodeAttributes(t *testing.T) {
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 10})
+	siAsk := &si.AllocationAsk{
+		AllocationKey:  "ask-1",
+		ApplicationID:  "app-1",
+		MaxAllocations: 1,
+		ResourceAsk:    res.ToProto(),
+	}
+	ask := NewAllocationAsk(siAsk)
+	assert.Assert(t, ask.GetRequiredNodeAttributes() == nil, "ask without tags should not require attributes")
+
+	siAsk.Tags = map[string]string{
+		"other":                                "value",
+		NodeAttributeTagPrefix:                 "ignored",
+		NodeAttributeTagPrefix + "zone":        "zone-a",
+		NodeAttributeTagPrefix + "accelerator": "",
+	}
+	ask = NewAllocationAsk(siAsk)
+	required := ask.GetRequiredNodeAttributes()
+	assert.Equal(t, len(required), 2, "unexpected number of required attributes: %v", required)
+	assert.Equal(t, required["zone"], "zone-a", "zone attribute not set correctly")
+	value, ok := required["accelerator"]
+	assert.Assert(t, ok && value == "", "accelerator attribute not set correctly")
+}
+
+func TestMaxLifetime(t *testing.T) {
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 10})
+	siAsk := &si.AllocationAsk{
+		AllocationKey:  "ask-1",
+		ApplicationID:  "app-1",
+		MaxAllocations: 1,
+		ResourceAsk:    res.ToProto(),
+	}
+	ask := NewAllocationAsk(siAsk)
+	assert.Equal(t, ask.GetMaxLifetime(), time.Duration(0), "ask without tag should not be limited")
+	siAsk.Tags = map[string]string{MaxLifetimeTag: "12h"}
+	ask = NewAllocationAsk(siAsk)
+	assert.Equal(t, ask.GetMaxLifetime(), 12*time.Hour, "lifetime not set from tag")
+	siAsk.Tags = map[string]string{MaxLifetimeTag: "0s"}
+	ask = NewAllocationAsk(siAsk)
+	assert.Equal(t, ask.GetMaxLifetime(), time.Duration(0), "zero lifetime should be ignored")
+	siAsk.Tags = map[string]string{MaxLifetimeTag: "long"}
+	ask = NewAllocationAsk(siAsk)
+	assert.Equal(t, ask.GetMaxLifetime(), time.Duration(0), "invalid lifetime should be ignored")
+}
+
+func TestUpdateFromTags(t *testing.T) {
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 10})
+	siAsk := &si.AllocationAsk{
+		AllocationKey:  "ask-1",
+		ApplicationID:  "app-1",
+		MaxAllocations: 1,
+		ResourceAsk:    res.ToProto(),
+		Tags:           map[string]string{NodeAttributeTagPrefix + "zone": "zone-a"},
+	}
+	ask := NewAllocationAsk(siAsk)
+	generation := ask.getGeneration()
+	siAsk.Tags = map[string]string{
+		NodeTolerationTagPrefix + "dedicated": "batch",
+		MaxLifetimeTag:                        "1h",
+	}
+	ask.updateFrom(NewAllocationAsk(siAsk))
+	assert.DeepEqual(t, ask.Tags, siAsk.Tags)
+	assert.Assert(t, ask.GetRequiredNodeAttributes() == nil, "attributes should have been removed with the tag")
+	assert.DeepEqual(t, ask.GetTolerations(), map[string]string{"dedicated": "batch"})
+	assert.Equal(t, ask.GetMaxLifetime(), time.Hour, "lifetime not updated from tag")
+	assert.Assert(t, ask.getGeneration() > generation, "generation not changed on update")
+}
+
 func TestPendingAskRepeat(t *testing.T) {
 	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 10})
 	ask := newAllocationAsk("alloc-1", "app-1", res)
@@ -131,3 +201,27 @@ func TestGetTimeout(t *testing.T) {
 	ask = NewAllocationAsk(siAsk)
 	assert.Equal(t, ask.getTimeout(), 10*time.Millisecond, "ask timeout not set as expected")
 }
+
+func TestAskRejectedReason(t *testing.T) {
+	assert.Equal(t, GetAskRejectedReason(fmt.Errorf("plain error")), AskRejectedInvalid, "plain error should be an invalid ask")
+	err := NewAskRejectedError(AskRejectedQuota, "ask %s rejected", "alloc-1")
+	assert.Equal(t, err.Error(), "ask alloc-1 rejected", "unexpected message")
+	assert.Equal(t, GetAskRejectedReason(err), AskRejectedQuota, "unexpected reason")
+
+	// the application reports the reason for the asks it rejects
+	app := newApplication(appID1, "default", "root.default")
+	queue, err2 := createRootQueue(nil)
+	assert.NilError(t, err2, "queue create failed")
+	app.queue = queue
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})
+	assert.Equal(t, GetAskRejectedReason(app.AddAllocationAsk(nil)), AskRejectedInvalid, "nil ask should be invalid")
+	ask := newAllocationAskRepeat("alloc-1", appID1, res, 0)
+	assert.Equal(t, GetAskRejectedReason(app.AddAllocationAsk(ask)), AskRejectedInvalid, "ask without repeat should be invalid")
+	ask = newAllocationAsk("alloc-1", appID1, resources.NewResource())
+	assert.Equal(t, GetAskRejectedReason(app.AddAllocationAsk(ask)), AskRejectedSize, "ask without resources should be rejected on size")
+	ask = newAllocationAsk("alloc-1", appID1, res)
+	assert.NilError(t, app.AddAllocationAsk(ask), "ask should have been added")
+	ask = newAllocationAskTG("alloc-1", appID1, "tg-1", res, 1)
+	_, err2 = app.UpdateAllocationAsk(ask)
+	assert.Equal(t, GetAskRejectedReason(err2), AskRejectedCollision, "ask changing the task group should collide")
+}
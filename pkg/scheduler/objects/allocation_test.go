@@ -20,10 +20,12 @@ package objects
 
 import (
 	"testing"
+	"time"
 
 	"gotest.tools/assert"
 
 	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+	"github.com/apache/incubator-yunikorn-core/pkg/export"
 	"github.com/apache/incubator-yunikorn-scheduler-interface/lib/go/si"
 )
 
@@ -103,6 +105,45 @@ func TestSIFromAlloc(t *testing.T) {
 	assert.DeepEqual(t, allocSI, expectedSI)
 }
 
+func TestSIFromAllocTelemetry(t *testing.T) {
+	res, err := resources.NewResourceFromConf(map[string]string{"first": "1"})
+	assert.NilError(t, err, "Resource creation failed")
+	ask := newAllocationAsk("ask-1", "app-1", res)
+	alloc := NewAllocation("test-uuid", "node-1", ask)
+	alloc.queueWait = 1500 * time.Millisecond
+	alloc.nodesEvaluated = 3
+	alloc.SetSchedulingCycle(42)
+	allocSI := alloc.NewSIFromAllocation()
+	expected := map[string]string{
+		TelemetryQueueWait:      "1500",
+		TelemetryNodesEvaluated: "3",
+		TelemetryCycleID:        "42",
+	}
+	assert.DeepEqual(t, allocSI.AllocationTags, expected)
+}
+
+func TestNewDecisionRecord(t *testing.T) {
+	res, err := resources.NewResourceFromConf(map[string]string{"first": "1"})
+	assert.NilError(t, err, "Resource creation failed")
+	ask := newAllocationAsk("ask-1", "app-1", res)
+	ask.QueueName = "root.default"
+	alloc := NewAllocation("test-uuid", "node-1", ask)
+	expected := &export.DecisionRecord{
+		Type:          export.Allocation,
+		Partition:     "default",
+		ApplicationID: "app-1",
+		QueueName:     "root.default",
+		AllocationKey: "ask-1",
+		UUID:          "test-uuid",
+		NodeID:        "node-1",
+		Resource:      map[string]int64{"first": 1},
+	}
+	assert.DeepEqual(t, alloc.newDecisionRecord(export.Allocation), expected)
+	// no exporter configured: must not panic
+	alloc.ExportAllocated()
+	alloc.ExportReleased(si.TerminationType_PREEMPTED_BY_SCHEDULER, "preempted")
+}
+
 func TestNewAllocFromNilSI(t *testing.T) {
 	defer func() {
 		if r := recover(); r != nil {
@@ -133,5 +174,5 @@ func TestNewAllocFromSI(t *testing.T) {
 	alloc = NewAllocationFromSI(allocSI)
 	assert.Assert(t, alloc != nilAlloc, "placeholder ask creation failed unexpectedly")
 	assert.Assert(t, alloc.IsPlaceholder(), "ask should have been a placeholder")
-	assert.Equal(t, alloc.getTaskGroup(), "testgroup", "TaskGroupName not set as expected")
+	assert.Equal(t, alloc.GetTaskGroup(), "testgroup", "TaskGroupName not set as expected")
 }
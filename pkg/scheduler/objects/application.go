@@ -21,6 +21,8 @@ package objects
 import (
 	"fmt"
 	"math"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -35,12 +37,16 @@ import (
 	"github.com/apache/incubator-yunikorn-core/pkg/handler"
 	"github.com/apache/incubator-yunikorn-core/pkg/interfaces"
 	"github.com/apache/incubator-yunikorn-core/pkg/log"
+	"github.com/apache/incubator-yunikorn-core/pkg/metrics"
 	"github.com/apache/incubator-yunikorn-core/pkg/rmproxy/rmevent"
 	"github.com/apache/incubator-yunikorn-scheduler-interface/lib/go/si"
 )
 
 var (
 	reservationDelay          = 2 * time.Second
+	reservationTimeout        = time.Duration(0)
+	reservationBlacklistTime  = 30 * time.Second
+	reservationStealDelta     = int32(0)
 	startingTimeout           = 5 * time.Minute
 	completingTimeout         = 30 * time.Second
 	terminatedTimeout         = 3 * 24 * time.Hour
@@ -52,6 +58,21 @@ const (
 	Hard string = "Hard"
 )
 
+// Application tags that define the spread constraint for the application: the maximum number of allocations of
+// the same task group per node, or per node attribute value if the spread key is set.
+const (
+	SpreadMaxTag = "yunikorn.apache.org/spread-max"
+	SpreadKeyTag = "yunikorn.apache.org/spread-key"
+)
+
+// Application tag that sets the maximum time the application can run after its first allocation as a duration (i.e. 8h).
+// The lower of the tag and the maximum runtime of the queue is used.
+const MaxRuntimeTag = "yunikorn.apache.org/max-runtime"
+
+// Application tag set by the RM to flag a system application, the application is placed in the system queue
+// if the value is true and the system queue is enabled for the partition.
+const SystemApplicationTag = "yunikorn.apache.org/system-app"
+
 type Application struct {
 	ApplicationID  string
 	Partition      string
@@ -62,23 +83,37 @@ type Application struct {
 	queue                *Queue                    // queue the application is running in
 	pending              *resources.Resource       // pending resources from asks for the app
 	reservations         map[string]*reservation   // a map of reservations
+	reserveBlacklist     map[string]time.Time      // node and ask combinations that cannot be reserved until the time set
 	requests             map[string]*AllocationAsk // a map of asks
 	sortedRequests       []*AllocationAsk
-	user                 security.UserGroup     // owner of the application
-	tags                 map[string]string      // application tags used in scheduling
-	allocatedResource    *resources.Resource    // total allocated resources
-	allocatedPlaceholder *resources.Resource    // total allocated placeholder resources
-	allocations          map[string]*Allocation // list of all allocations
-	placeholderAsk       *resources.Resource    // total placeholder request for the app (all task groups)
-	stateMachine         *fsm.FSM               // application state machine
-	stateTimer           *time.Timer            // timer for state time
-	execTimeout          time.Duration          // execTimeout for the application run
-	placeholderTimer     *time.Timer            // placeholder replace timer
-	gangSchedulingStyle  string                 // gang scheduling style can be hard (after timeout we fail the application), or soft (after timeeout we schedule it as a normal application)
-
-	rmEventHandler     handler.EventHandler
-	rmID               string
-	terminatedCallback func(appID string)
+	user                 security.UserGroup        // owner of the application
+	tags                 map[string]string         // application tags used in scheduling
+	allocatedResource    *resources.Resource       // total allocated resources
+	allocatedPlaceholder *resources.Resource       // total allocated placeholder resources
+	allocations          map[string]*Allocation    // list of all allocations
+	placeholderAsk       *resources.Resource       // total placeholder request for the app (all task groups)
+	stateMachine         *fsm.FSM                  // application state machine
+	stateTimer           *time.Timer               // timer for state time
+	execTimeout          time.Duration             // execTimeout for the application run
+	placeholderTimer     *time.Timer               // placeholder replace timer
+	maxRuntime           time.Duration             // maximum runtime set by the application tag, 0 means not set
+	runtimeTimer         *time.Timer               // maximum runtime timer, started on the first allocation
+	gangSchedulingStyle  string                    // gang scheduling style can be hard (after timeout we fail the application), or soft (after timeeout we schedule it as a normal application)
+	spreadMax            int                       // maximum allocations of a task group per spread domain, 0 means no constraint
+	spreadKey            string                    // node attribute that defines the spread domain, empty means the node
+	spreadCounters       map[string]map[string]int // allocations per task group and spread domain, only tracked with a spread constraint
+	placementRule        string                    // name of the placement rule that placed the application
+	requestedQueue       string                    // queue requested on submission, before the placement rules are applied
+	queueCreated         bool                      // the queue was created while placing the application
+	restored             bool                      // restored from a snapshot and not yet added again by the RM
+	recoveredTime        time.Time                 // time the application was recovered, zero if it was never recovered
+	lastActivity         time.Time                 // time of the last ask change or allocation release
+	idle                 bool                      // flagged idle, reset on the next ask change or allocation release
+
+	rmEventHandler       handler.EventHandler
+	rmID                 string
+	terminatedCallback   func(appID string)
+	stateChangedCallback func() // called for every change that is not the direct result of an RM event or scheduling
 
 	sync.RWMutex
 }
@@ -88,6 +123,7 @@ func NewApplication(siApp *si.AddApplicationRequest, ugi security.UserGroup, eve
 		ApplicationID:        siApp.ApplicationID,
 		Partition:            siApp.PartitionName,
 		QueueName:            siApp.QueueName,
+		requestedQueue:       siApp.QueueName,
 		SubmissionTime:       time.Now(),
 		tags:                 siApp.Tags,
 		pending:              resources.NewResource(),
@@ -95,9 +131,11 @@ func NewApplication(siApp *si.AddApplicationRequest, ugi security.UserGroup, eve
 		allocatedPlaceholder: resources.NewResource(),
 		requests:             make(map[string]*AllocationAsk),
 		reservations:         make(map[string]*reservation),
+		reserveBlacklist:     make(map[string]time.Time),
 		allocations:          make(map[string]*Allocation),
 		stateMachine:         NewAppState(),
 		placeholderAsk:       resources.NewResourceFromProto(siApp.PlaceholderAsk),
+		lastActivity:         time.Now(),
 	}
 	placeholderTimeout := common.ConvertSITimeout(siApp.ExecutionTimeoutMilliSeconds)
 	if time.Duration(0) == placeholderTimeout {
@@ -110,6 +148,8 @@ func NewApplication(siApp *si.AddApplicationRequest, ugi security.UserGroup, eve
 		gangSchedStyle = Soft
 	}
 	app.gangSchedulingStyle = gangSchedStyle
+	app.setSpreadConstraint()
+	app.setMaxRuntime()
 	app.execTimeout = placeholderTimeout
 	app.user = ugi
 	app.rmEventHandler = eventHandler
@@ -137,6 +177,25 @@ func SetReservationDelay(delay time.Duration) {
 	reservationDelay = delay
 }
 
+// Set the reservation timeout and the time a node is blacklisted for the ask after the timeout.
+// A reservation that has not been converted into an allocation within the timeout is removed.
+// A timeout of 0 means that reservations never time out.
+func SetReservationTimeout(timeout, blacklist time.Duration) {
+	log.Logger().Debug("Set reservation timeout",
+		zap.Duration("timeout", timeout),
+		zap.Duration("blacklist", blacklist))
+	reservationTimeout = timeout
+	reservationBlacklistTime = blacklist
+}
+
+// Set the minimum priority difference for an ask to steal the reservation of another ask.
+// A delta of 0 or less disables reservation stealing.
+func SetReservationStealDelta(delta int32) {
+	log.Logger().Debug("Set reservation steal delta",
+		zap.Int32("delta", delta))
+	reservationStealDelta = delta
+}
+
 // Return the current state or a checked specific state for the application.
 // The state machine handles the locking.
 func (sa *Application) CurrentState() string {
@@ -204,6 +263,8 @@ func (sa *Application) HandleApplicationEventWithInfo(event applicationEvent, ev
 }
 
 func (sa *Application) OnStateChange(event *fsm.Event, eventInfo string) {
+	// the state also changes on timers: a placeholder timeout, the maximum runtime or a completing application
+	sa.executeStateChangedCallback()
 	updatedApps := make([]*si.UpdatedApplication, 0)
 	var message string
 	if len(eventInfo) == 0 {
@@ -305,6 +366,140 @@ func (sa *Application) clearPlaceholderTimer() {
 		zap.Duration("Timeout", sa.execTimeout))
 }
 
+// Set the maximum runtime from the application tag, an invalid value is ignored.
+func (sa *Application) setMaxRuntime() {
+	var value string
+	for tag, tagValue := range sa.tags {
+		if strings.EqualFold(tag, MaxRuntimeTag) {
+			value = tagValue
+		}
+	}
+	if value == "" {
+		return
+	}
+	limit, err := time.ParseDuration(value)
+	if err != nil || limit <= 0 {
+		log.Logger().Warn("application maximum runtime ignored, value must be a positive duration",
+			zap.String("appID", sa.ApplicationID),
+			zap.String("maxRuntime", value))
+		return
+	}
+	sa.maxRuntime = limit
+}
+
+// Return the maximum runtime of the application: the lower of the application tag and the queue setting.
+// A zero duration means the runtime is not limited.
+// NOTE: this is a lock free call. It must only be called holding the application lock.
+func (sa *Application) getMaxRuntime() time.Duration {
+	limit := sa.maxRuntime
+	if sa.queue != nil {
+		if queueLimit := sa.queue.GetMaxRuntime(); queueLimit > 0 && (limit == 0 || queueLimit < limit) {
+			limit = queueLimit
+		}
+	}
+	return limit
+}
+
+func (sa *Application) initRuntimeTimer() {
+	if sa.runtimeTimer != nil {
+		return
+	}
+	limit := sa.getMaxRuntime()
+	if limit <= 0 {
+		return
+	}
+	log.Logger().Debug("Application runtime timer initiated",
+		zap.String("AppID", sa.ApplicationID),
+		zap.Duration("MaxRuntime", limit))
+	sa.runtimeTimer = time.AfterFunc(limit, sa.timeoutRuntimeProcessing)
+}
+
+func (sa *Application) clearRuntimeTimer() {
+	if sa == nil || sa.runtimeTimer == nil {
+		return
+	}
+	sa.runtimeTimer.Stop()
+	sa.runtimeTimer = nil
+	log.Logger().Debug("Application runtime timer cleared",
+		zap.String("AppID", sa.ApplicationID))
+}
+
+// The application has run longer than the maximum runtime: fail the application and release all asks and
+// allocations. The application moves to Failed when the RM has confirmed the release of all allocations.
+func (sa *Application) timeoutRuntimeProcessing() {
+	sa.Lock()
+	defer sa.Unlock()
+	if len(sa.allocations) == 0 {
+		return
+	}
+	message := fmt.Sprintf("application exceeded the maximum runtime of %s", sa.getMaxRuntime())
+	log.Logger().Info("Maximum runtime exceeded, releasing asks and allocations",
+		zap.String("AppID", sa.ApplicationID),
+		zap.Int("releasing allocations", len(sa.allocations)),
+		zap.Int("releasing asks", len(sa.requests)))
+	if err := sa.HandleApplicationEventWithInfo(FailApplication, "MaxRuntimeExceeded"); err != nil {
+		log.Logger().Debug("Application state change failed when the maximum runtime was exceeded",
+			zap.String("AppID", sa.ApplicationID),
+			zap.String("currentState", sa.CurrentState()),
+			zap.Error(err))
+		return
+	}
+	sa.notifyRMAllocationAskReleased(sa.rmID, sa.getAllRequests(), si.TerminationType_TIMEOUT, message)
+	sa.removeAsksInternal("")
+	allocations := make([]*Allocation, 0, len(sa.allocations))
+	for _, alloc := range sa.allocations {
+		allocations = append(allocations, alloc)
+	}
+	sa.notifyRMAllocationReleased(sa.rmID, allocations, si.TerminationType_TIMEOUT, message)
+}
+
+// Start the maximum lifetime timer for an allocation if the ask sets a lifetime.
+// Placeholders are not limited, they are covered by the placeholder timeout.
+// NOTE: this is a lock free call. It must only be called holding the application lock.
+func (sa *Application) initLifetimeTimer(alloc *Allocation) {
+	if alloc.placeholder || alloc.Ask == nil || alloc.lifetimeTimer != nil {
+		return
+	}
+	lifetime := alloc.Ask.GetMaxLifetime()
+	if lifetime <= 0 {
+		return
+	}
+	uuid := alloc.UUID
+	alloc.lifetimeTimer = time.AfterFunc(lifetime, func() {
+		sa.timeoutAllocationLifetime(uuid)
+	})
+}
+
+// NOTE: this is a lock free call. It must only be called holding the application lock.
+func clearLifetimeTimer(alloc *Allocation) {
+	if alloc.lifetimeTimer == nil {
+		return
+	}
+	alloc.lifetimeTimer.Stop()
+	alloc.lifetimeTimer = nil
+}
+
+// The allocation has lived longer than the maximum lifetime: request the RM to release the allocation.
+// The allocation is removed when the RM confirms the release.
+func (sa *Application) timeoutAllocationLifetime(uuid string) {
+	sa.Lock()
+	defer sa.Unlock()
+	alloc := sa.allocations[uuid]
+	if alloc == nil || alloc.released {
+		return
+	}
+	alloc.released = true
+	alloc.lifetimeTimer = nil
+	lifetime := alloc.Ask.GetMaxLifetime()
+	log.Logger().Info("Allocation maximum lifetime exceeded, releasing allocation",
+		zap.String("AppID", sa.ApplicationID),
+		zap.String("allocationKey", alloc.AllocationKey),
+		zap.String("UUID", uuid),
+		zap.Duration("maxLifetime", lifetime))
+	sa.notifyRMAllocationReleased(sa.rmID, []*Allocation{alloc}, si.TerminationType_TIMEOUT,
+		fmt.Sprintf("allocation exceeded the maximum lifetime of %s", lifetime))
+}
+
 func (sa *Application) timeoutPlaceholderProcessing() {
 	sa.Lock()
 	defer sa.Unlock()
@@ -366,6 +561,40 @@ func (sa *Application) GetReservations() []string {
 	return keys
 }
 
+// Return the details of all reservations for the app ordered by reservation key.
+// This will return an empty array if there are no reservations.
+func (sa *Application) GetReservationInfos() []*ReservationInfo {
+	sa.RLock()
+	defer sa.RUnlock()
+	keys := make([]string, 0, len(sa.reservations))
+	for key := range sa.reservations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	infos := make([]*ReservationInfo, 0, len(keys))
+	for _, key := range keys {
+		infos = append(infos, sa.reservations[key].getInfo())
+	}
+	return infos
+}
+
+// Return the allocation asks with an outstanding repeat ordered by allocation key.
+// This will return an empty array if there are no pending asks.
+func (sa *Application) GetPendingAsks() []*AllocationAsk {
+	sa.RLock()
+	defer sa.RUnlock()
+	asks := make([]*AllocationAsk, 0)
+	for _, request := range sa.requests {
+		if request.GetPendingAskRepeat() > 0 {
+			asks = append(asks, request)
+		}
+	}
+	sort.Slice(asks, func(i, j int) bool {
+		return asks[i].AllocationKey < asks[j].AllocationKey
+	})
+	return asks
+}
+
 // Return the allocation ask for the key, nil if not found
 func (sa *Application) GetAllocationAsk(allocationKey string) *AllocationAsk {
 	sa.RLock()
@@ -402,6 +631,34 @@ func (sa *Application) GetPendingResource() *resources.Resource {
 	return sa.pending
 }
 
+// Return the number of outstanding asks for this application, counting each pending repeat.
+func (sa *Application) GetPendingAskCount() int {
+	sa.RLock()
+	defer sa.RUnlock()
+	count := 0
+	for _, request := range sa.requests {
+		count += int(request.GetPendingAskRepeat())
+	}
+	return count
+}
+
+// Return the highest priority of all asks of the application with an outstanding repeat.
+// Returns the lowest possible priority if there are no outstanding asks.
+func (sa *Application) GetPendingPriority() int32 {
+	sa.RLock()
+	defer sa.RUnlock()
+	priority := int32(math.MinInt32)
+	for _, request := range sa.requests {
+		if request.GetPendingAskRepeat() == 0 {
+			continue
+		}
+		if request.priority > priority {
+			priority = request.priority
+		}
+	}
+	return priority
+}
+
 // Remove one or more allocation asks from this application.
 // This also removes any reservations that are linked to the ask.
 // The return value is the number of reservations released
@@ -417,6 +674,7 @@ func (sa *Application) removeAsksInternal(allocKey string) int {
 	if len(sa.requests) == 0 {
 		return 0
 	}
+	sa.markActive()
 	var deltaPendingResource *resources.Resource = nil
 	// when allocation key not specified, cleanup all allocation ask
 	var toRelease int
@@ -490,10 +748,13 @@ func (sa *Application) AddAllocationAsk(ask *AllocationAsk) error {
 	sa.Lock()
 	defer sa.Unlock()
 	if ask == nil {
-		return fmt.Errorf("ask cannot be nil when added to app %s", sa.ApplicationID)
+		return NewAskRejectedError(AskRejectedInvalid, "ask cannot be nil when added to app %s", sa.ApplicationID)
+	}
+	if ask.GetPendingAskRepeat() == 0 {
+		return NewAskRejectedError(AskRejectedInvalid, "invalid ask added to app %s: %v", sa.ApplicationID, ask)
 	}
-	if ask.GetPendingAskRepeat() == 0 || resources.IsZero(ask.AllocatedResource) {
-		return fmt.Errorf("invalid ask added to app %s: %v", sa.ApplicationID, ask)
+	if resources.IsZero(ask.AllocatedResource) {
+		return NewAskRejectedError(AskRejectedSize, "invalid ask added to app %s: %v", sa.ApplicationID, ask)
 	}
 	ask.setQueue(sa.queue.QueuePath)
 	delta := resources.Multiply(ask.AllocatedResource, int64(ask.GetPendingAskRepeat()))
@@ -515,6 +776,7 @@ func (sa *Application) AddAllocationAsk(ask *AllocationAsk) error {
 		}
 	}
 	sa.requests[ask.AllocationKey] = ask
+	sa.markActive()
 
 	// Update total pending resource
 	delta.SubFrom(oldAskResource)
@@ -530,6 +792,67 @@ func (sa *Application) AddAllocationAsk(ask *AllocationAsk) error {
 	return nil
 }
 
+// Update the resource of an existing pending ask of this application.
+// The existing ask is updated in place which keeps the original create time and with that the ask position.
+// Reservations for the ask on nodes that cannot fit the updated resource are removed.
+// The return value is the number of reservations released
+func (sa *Application) UpdateAllocationAsk(ask *AllocationAsk) (int, error) {
+	sa.Lock()
+	defer sa.Unlock()
+	if ask == nil {
+		return 0, NewAskRejectedError(AskRejectedInvalid, "ask cannot be nil when updated on app %s", sa.ApplicationID)
+	}
+	oldAsk := sa.requests[ask.AllocationKey]
+	if oldAsk == nil {
+		return 0, NewAskRejectedError(AskRejectedInvalid, "ask %s does not exist on app %s", ask.AllocationKey, sa.ApplicationID)
+	}
+	if ask.GetPendingAskRepeat() == 0 {
+		return 0, NewAskRejectedError(AskRejectedInvalid, "invalid ask update on app %s: %v", sa.ApplicationID, ask)
+	}
+	if resources.IsZero(ask.AllocatedResource) {
+		return 0, NewAskRejectedError(AskRejectedSize, "invalid ask update on app %s: %v", sa.ApplicationID, ask)
+	}
+	if oldAsk.placeholder != ask.placeholder || oldAsk.taskGroupName != ask.taskGroupName {
+		return 0, NewAskRejectedError(AskRejectedCollision, "ask %s update on app %s cannot change the placeholder or task group", ask.AllocationKey, sa.ApplicationID)
+	}
+	delta := resources.Multiply(ask.AllocatedResource, int64(ask.GetPendingAskRepeat()))
+	delta.SubFrom(resources.Multiply(oldAsk.AllocatedResource, int64(oldAsk.GetPendingAskRepeat())))
+	oldAsk.updateFrom(ask)
+	sa.markActive()
+
+	// remove the reservations that do not fit the node anymore
+	var toRelease int
+	for _, key := range sa.GetAskReservations(ask.AllocationKey) {
+		reserve := sa.reservations[key]
+		if reserve.node.FitInNode(oldAsk.AllocatedResource) {
+			continue
+		}
+		releases, err := sa.unReserveInternal(reserve.node, reserve.ask)
+		if err != nil {
+			log.Logger().Warn("Removal of reservation failed while updating allocation ask",
+				zap.String("appID", sa.ApplicationID),
+				zap.String("reservationKey", key),
+				zap.Error(err))
+			continue
+		}
+		// clean up the queue reservation
+		sa.queue.UnReserve(sa.ApplicationID, releases)
+		toRelease += releases
+	}
+
+	// Update total pending resource
+	sa.pending = resources.Add(sa.pending, delta)
+	sa.queue.incPendingResource(delta)
+
+	log.Logger().Info("Ask updated successfully on application",
+		zap.String("appID", sa.ApplicationID),
+		zap.String("ask", ask.AllocationKey),
+		zap.String("pendingDelta", delta.String()),
+		zap.Int("reservationsReleased", toRelease))
+
+	return toRelease, nil
+}
+
 // Add the ask when a node allocation is recovered. Maintaining the rule that an Allocation always has a
 // link to an AllocationAsk.
 // Safeguarded against a nil but the recovery generates the ask and should never be nil.
@@ -541,6 +864,9 @@ func (sa *Application) RecoverAllocationAsk(ask *AllocationAsk) {
 	}
 	ask.setQueue(sa.queue.QueuePath)
 	sa.requests[ask.AllocationKey] = ask
+	if sa.recoveredTime.IsZero() {
+		sa.recoveredTime = time.Now()
+	}
 	// progress the application from New to Accepted.
 	if sa.IsNew() {
 		if err := sa.HandleApplicationEvent(RunApplication); err != nil {
@@ -740,7 +1066,7 @@ func (sa *Application) getOutstandingRequests(headRoom *resources.Resource, tota
 	sa.sortRequests(false)
 	for _, request := range sa.sortedRequests {
 		// ignore nil checks resource function calls are nil safe
-		if headRoom.FitInMaxUndef(request.AllocatedResource) {
+		if headRoom.FitInMaxUndef(request.AllocatedResource) && sa.fitsPriorityQuota(request) {
 			// if headroom is still enough for the resources
 			*total = append(*total, request)
 			headRoom.SubFrom(request.AllocatedResource)
@@ -778,6 +1104,10 @@ func (sa *Application) tryAllocate(headRoom *resources.Resource, nodeIterator fu
 			}
 			continue
 		}
+		// high priority requests must also fit in the priority quota
+		if !sa.fitsPriorityQuota(request) {
+			continue
+		}
 		iterator := nodeIterator()
 		if iterator != nil {
 			alloc := sa.tryNodes(request, iterator)
@@ -826,8 +1156,9 @@ func (sa *Application) tryPlaceholderAllocate(nodeIterator func() interfaces.Nod
 			node := getnode(ph.NodeID)
 			// got the node run same checks as for reservation (all but fits)
 			// resource usage should not change anyway between placeholder and real one
-			if node != nil && node.preReserveConditions(request.AllocationKey) {
+			if node != nil && node.preReserveConditions(request) {
 				alloc := NewAllocation(common.GetNewUUID(), node.NodeID, request)
+				sa.setSpreadDomain(alloc, node)
 				// double link to make it easier to find
 				// alloc (the real one) releases points to the placeholder in the releases list
 				alloc.Releases = []*Allocation{ph}
@@ -878,11 +1209,12 @@ func (sa *Application) tryPlaceholderAllocate(nodeIterator func() interfaces.Nod
 				continue
 			}
 			// skip the node if conditions can not be satisfied
-			if !node.preAllocateConditions(reqFit.AllocationKey) {
+			if !node.preAllocateConditions(reqFit) {
 				continue
 			}
 			// allocation worked: on a non placeholder node update result and return
 			alloc := NewAllocation(common.GetNewUUID(), node.NodeID, reqFit)
+			sa.setSpreadDomain(alloc, node)
 			// double link to make it easier to find
 			// alloc (the real one) releases points to the placeholder in the releases list
 			alloc.Releases = []*Allocation{phFit}
@@ -919,6 +1251,10 @@ func (sa *Application) tryReservedAllocate(headRoom *resources.Resource, nodeIte
 	// process all outstanding reservations and pick the first one that fits
 	for _, reserve := range sa.reservations {
 		ask := sa.requests[reserve.askKey]
+		// remove the reservation if it did not convert in time and block the node for a while
+		if ask != nil && sa.reservationTimedOut(reserve) {
+			return newReservedAllocation(Unreserved, reserve.nodeID, ask)
+		}
 		// sanity check and cleanup if needed
 		if ask == nil || ask.GetPendingAskRepeat() == 0 {
 			var unreserveAsk *AllocationAsk
@@ -935,8 +1271,16 @@ func (sa *Application) tryReservedAllocate(headRoom *resources.Resource, nodeIte
 			alloc := newReservedAllocation(Unreserved, reserve.nodeID, unreserveAsk)
 			return alloc
 		}
-		// check if this fits in the queue's head room
-		if !headRoom.FitInMaxUndef(ask.AllocatedResource) {
+		// check if this fits in the queue's head room and priority quota
+		if !headRoom.FitInMaxUndef(ask.AllocatedResource) || !sa.fitsPriorityQuota(ask) {
+			continue
+		}
+		// the spread constraint could have changed since the reservation was made
+		if !sa.spreadAllowed(sa.spreadCounts(ask), reserve.node) {
+			continue
+		}
+		// the node could have been tainted since the reservation was made
+		if !reserve.node.IsTolerated(ask.GetTolerations(), sa.getQueueTolerations()) {
 			continue
 		}
 		// check allocation possibility
@@ -944,6 +1288,8 @@ func (sa *Application) tryReservedAllocate(headRoom *resources.Resource, nodeIte
 		// allocation worked fix the result and return
 		if alloc != nil {
 			alloc.Result = AllocatedReserved
+			alloc.nodesEvaluated = 1
+			metrics.GetSchedulerMetrics().ObserveReservationConversionLatency(reserve.created)
 			return alloc
 		}
 	}
@@ -954,6 +1300,7 @@ func (sa *Application) tryReservedAllocate(headRoom *resources.Resource, nodeIte
 			alloc := sa.tryNodesNoReserve(reserve.ask, iterator, reserve.nodeID)
 			// have a candidate return it, including the node that was reserved
 			if alloc != nil {
+				metrics.GetSchedulerMetrics().ObserveReservationConversionLatency(reserve.created)
 				return alloc
 			}
 		}
@@ -961,24 +1308,85 @@ func (sa *Application) tryReservedAllocate(headRoom *resources.Resource, nodeIte
 	return nil
 }
 
+// Check if the reservation has been outstanding for longer than the reservation timeout.
+// If it has the node is blacklisted for the ask to prevent an immediate new reservation on the same node.
+// NOTE: this is a lock free call. It should only be called holding the Application lock.
+func (sa *Application) reservationTimedOut(reserve *reservation) bool {
+	if reservationTimeout == 0 {
+		return false
+	}
+	age := time.Since(reserve.created)
+	if age <= reservationTimeout {
+		return false
+	}
+	if sa.reserveBlacklist == nil {
+		sa.reserveBlacklist = make(map[string]time.Time)
+	}
+	sa.reserveBlacklist[reserveBlacklistKey(reserve.nodeID, reserve.askKey)] = time.Now().Add(reservationBlacklistTime)
+	log.Logger().Info("reservation timed out, removing reservation and blacklisting node for ask",
+		zap.String("appID", sa.ApplicationID),
+		zap.String("nodeID", reserve.nodeID),
+		zap.String("allocationKey", reserve.askKey),
+		zap.Duration("reservationAge", age),
+		zap.Duration("blacklistTime", reservationBlacklistTime))
+	return true
+}
+
+// Check if the node is blacklisted for reservations of the ask. Expired entries are removed.
+// NOTE: this is a lock free call. It should only be called holding the Application lock.
+func (sa *Application) isReserveBlacklisted(nodeID, askKey string) bool {
+	key := reserveBlacklistKey(nodeID, askKey)
+	expiry, ok := sa.reserveBlacklist[key]
+	if !ok {
+		return false
+	}
+	if time.Now().After(expiry) {
+		delete(sa.reserveBlacklist, key)
+		return false
+	}
+	return true
+}
+
+// Return the key of the node and ask combination in the reservation blacklist.
+func reserveBlacklistKey(nodeID, askKey string) string {
+	return nodeID + "|" + askKey
+}
+
 // Try all the nodes for a reserved request that have not been tried yet.
 // This should never result in a reservation as the ask is already reserved
 func (sa *Application) tryNodesNoReserve(ask *AllocationAsk, iterator interfaces.NodeIterator, reservedNode string) *Allocation {
+	evaluated := 0
+	counts := sa.spreadCounts(ask)
+	queueTolerations := sa.getQueueTolerations()
 	for iterator.HasNext() {
 		node, ok := iterator.Next().(*Node)
 		if !ok {
 			log.Logger().Warn("Node iterator failed to return a node")
 			return nil
 		}
+		evaluated++
 		// skip over the node if the resource does not fit the node or this is the reserved node.
 		if !node.FitInNode(ask.AllocatedResource) || node.NodeID == reservedNode {
 			continue
 		}
+		// skip over the node if the spread constraint of the application does not allow it
+		if !sa.spreadAllowed(counts, node) {
+			continue
+		}
+		// skip over the node if it does not have the required attributes
+		if !node.MatchAttributes(ask.GetRequiredNodeAttributes()) {
+			continue
+		}
+		// skip over the node if the ask does not tolerate the node taints
+		if !node.IsTolerated(ask.GetTolerations(), queueTolerations) {
+			continue
+		}
 		alloc := sa.tryNode(node, ask)
 		// allocation worked: update result and return
 		if alloc != nil {
 			alloc.ReservedNodeID = reservedNode
 			alloc.Result = AllocatedReserved
+			alloc.nodesEvaluated = evaluated
 			return alloc
 		}
 	}
@@ -995,19 +1403,36 @@ func (sa *Application) tryNodes(ask *AllocationAsk, iterator interfaces.NodeIter
 	allocKey := ask.AllocationKey
 	reservedAsks := sa.GetAskReservations(allocKey)
 	allowReserve := len(reservedAsks) < int(ask.pendingRepeatAsk)
+	evaluated := 0
+	counts := sa.spreadCounts(ask)
+	queueTolerations := sa.getQueueTolerations()
 	for iterator.HasNext() {
 		node, ok := iterator.Next().(*Node)
 		if !ok {
 			log.Logger().Warn("Node iterator failed to return a node")
 			return nil
 		}
+		evaluated++
 		// skip over the node if the resource does not fit the node at all.
 		if !node.FitInNode(ask.AllocatedResource) {
 			continue
 		}
+		// skip over the node if the spread constraint of the application does not allow it
+		if !sa.spreadAllowed(counts, node) {
+			continue
+		}
+		// skip over the node if it does not have the required attributes: no allocation or reservation possible
+		if !node.MatchAttributes(ask.GetRequiredNodeAttributes()) {
+			continue
+		}
+		// skip over the node if the ask does not tolerate the node taints: no allocation or reservation possible
+		if !node.IsTolerated(ask.GetTolerations(), queueTolerations) {
+			continue
+		}
 		alloc := sa.tryNode(node, ask)
 		// allocation worked so return
 		if alloc != nil {
+			alloc.nodesEvaluated = evaluated
 			// check if the node was reserved for this ask: if it is set the result and return
 			// NOTE: this is a safeguard as reserved nodes should never be part of the iterator
 			// but we have no locking
@@ -1037,7 +1462,7 @@ func (sa *Application) tryNodes(ask *AllocationAsk, iterator interfaces.NodeIter
 		// nothing allocated should we look at a reservation?
 		// TODO make this smarter a hardcoded delay is not the right thing
 		askAge := time.Since(ask.GetCreateTime())
-		if allowReserve && askAge > reservationDelay {
+		if allowReserve && askAge > reservationDelay && !sa.isReserveBlacklisted(node.NodeID, allocKey) {
 			log.Logger().Debug("app reservation check",
 				zap.String("allocationKey", allocKey),
 				zap.Time("createTime", ask.GetCreateTime()),
@@ -1061,7 +1486,7 @@ func (sa *Application) tryNodes(ask *AllocationAsk, iterator interfaces.NodeIter
 			zap.Int("reservations", len(reservedAsks)),
 			zap.Int32("pendingRepeats", ask.pendingRepeatAsk))
 		// skip the node if conditions can not be satisfied
-		if !nodeToReserve.preReserveConditions(allocKey) {
+		if !nodeToReserve.preReserveConditions(ask) {
 			return nil
 		}
 		// return reservation allocation and mark it as a reservation
@@ -1072,9 +1497,176 @@ func (sa *Application) tryNodes(ask *AllocationAsk, iterator interfaces.NodeIter
 	return nil
 }
 
+// Set the spread constraint from the application tags.
+// An invalid or non positive maximum means the application has no spread constraint.
+// Lock free call, must only be called on create.
+func (sa *Application) setSpreadConstraint() {
+	var value, key string
+	for tag, tagValue := range sa.tags {
+		if strings.EqualFold(tag, SpreadMaxTag) {
+			value = tagValue
+		}
+		if strings.EqualFold(tag, SpreadKeyTag) {
+			key = tagValue
+		}
+	}
+	if value == "" {
+		return
+	}
+	max, err := strconv.Atoi(value)
+	if err != nil || max <= 0 {
+		log.Logger().Warn("application spread constraint ignored, maximum must be a positive number",
+			zap.String("appID", sa.ApplicationID),
+			zap.String("spreadMax", value))
+		return
+	}
+	sa.spreadMax = max
+	sa.spreadKey = key
+}
+
+// Return the spread domain of the node for this application: the node ID or the value of the spread key attribute.
+// An empty string is returned if the node does not have the spread key attribute.
+func (sa *Application) spreadDomain(node *Node) string {
+	if sa.spreadKey == "" {
+		return node.NodeID
+	}
+	return node.GetAttribute(sa.spreadKey)
+}
+
+// Set the spread domain of the allocation from the node it is placed on, if not set yet.
+// NOTE: this is a lock free call. It should only be called holding the Application lock or on create.
+func (sa *Application) setSpreadDomain(alloc *Allocation, node *Node) {
+	if sa.spreadMax != 0 && sa.spreadKey != "" && alloc.spreadDomain == "" && node != nil {
+		alloc.spreadDomain = sa.spreadDomain(node)
+	}
+}
+
+// Update the allocation count of the task group of the allocation in its spread domain.
+// NOTE: this is a lock free call. It should only be called holding the Application lock.
+func (sa *Application) updateSpreadCount(alloc *Allocation, delta int) {
+	if sa.spreadMax == 0 {
+		return
+	}
+	domain := alloc.NodeID
+	if sa.spreadKey != "" {
+		domain = alloc.spreadDomain
+	}
+	if domain == "" {
+		return
+	}
+	if sa.spreadCounters == nil {
+		sa.spreadCounters = make(map[string]map[string]int)
+	}
+	counts := sa.spreadCounters[alloc.taskGroupName]
+	if counts == nil {
+		counts = make(map[string]int)
+		sa.spreadCounters[alloc.taskGroupName] = counts
+	}
+	counts[domain] += delta
+	if counts[domain] <= 0 {
+		delete(counts, domain)
+	}
+}
+
+// Return the allocations of the task group of the ask per spread domain.
+// Returns nil if the application has no spread constraint. The returned map must not be changed.
+// NOTE: this is a lock free call. It should only be called holding the Application lock.
+func (sa *Application) spreadCounts(ask *AllocationAsk) map[string]int {
+	if sa.spreadMax == 0 {
+		return nil
+	}
+	if counts := sa.spreadCounters[ask.taskGroupName]; counts != nil {
+		return counts
+	}
+	return map[string]int{}
+}
+
+// Check if the spread constraint allows another allocation of the task group on the node.
+// A nil counts map means there is no constraint. Nodes without the spread key attribute are not allowed.
+func (sa *Application) spreadAllowed(counts map[string]int, node *Node) bool {
+	if counts == nil {
+		return true
+	}
+	domain := sa.spreadDomain(node)
+	return domain != "" && counts[domain] < sa.spreadMax
+}
+
+// Return the node taints tolerated by the queue the application runs in.
+func (sa *Application) getQueueTolerations() map[string]string {
+	if sa.queue == nil {
+		return nil
+	}
+	return sa.queue.getTolerations()
+}
+
+// The details of a reservation that can be stolen: the ask that steals the reservation on the node and the
+// reservation that is removed.
+type ReservationSteal struct {
+	Ask    *AllocationAsk
+	Node   *Node
+	Stolen *ReservationInfo
+}
+
+// Find a reservation the application can steal for its highest priority ask. An ask is only considered if it
+// waited longer than the reservation delay, fits in the queue headroom and has reservations left to make.
+// Stealing is only possible if none of the nodes that could hold the ask is free to be reserved.
+// The reservation with the lowest priority, and the newest if equal, that is at least the steal delta lower in
+// priority than the ask is picked. Returns nil if stealing is disabled or nothing can be stolen.
+func (sa *Application) FindReservationToSteal(nodes []*Node) *ReservationSteal {
+	if reservationStealDelta <= 0 {
+		return nil
+	}
+	sa.RLock()
+	defer sa.RUnlock()
+	var ask *AllocationAsk
+	var headRoom *resources.Resource
+	if sa.queue != nil {
+		headRoom = sa.queue.getHeadRoom()
+	}
+	for _, request := range sa.requests {
+		if request.GetPendingAskRepeat() == 0 || request.placeholder || time.Since(request.GetCreateTime()) <= reservationDelay {
+			continue
+		}
+		if len(sa.GetAskReservations(request.AllocationKey)) >= int(request.GetPendingAskRepeat()) {
+			continue
+		}
+		if !headRoom.FitInMaxUndef(request.AllocatedResource) {
+			continue
+		}
+		if ask == nil || request.GetPriority() > ask.GetPriority() {
+			ask = request
+		}
+	}
+	if ask == nil {
+		return nil
+	}
+	counts := sa.spreadCounts(ask)
+	queueTolerations := sa.getQueueTolerations()
+	var steal *ReservationSteal
+	for _, node := range nodes {
+		if !node.FitInNode(ask.AllocatedResource) || !sa.spreadAllowed(counts, node) ||
+			!node.MatchAttributes(ask.GetRequiredNodeAttributes()) || !node.IsTolerated(ask.GetTolerations(), queueTolerations) {
+			continue
+		}
+		res := node.getReservationToSteal(ask.GetPriority() - reservationStealDelta)
+		// a node that could hold the ask is not reserved: a normal reservation is possible
+		if res == nil && !node.IsReserved() {
+			return nil
+		}
+		if res == nil || res.appID == sa.ApplicationID {
+			continue
+		}
+		info := res.getInfo()
+		if steal == nil || info.Priority < steal.Stolen.Priority ||
+			(info.Priority == steal.Stolen.Priority && info.Created.After(steal.Stolen.Created)) {
+			steal = &ReservationSteal{Ask: ask, Node: node, Stolen: info}
+		}
+	}
+	return steal
+}
+
 // Try allocating on one specific node
 func (sa *Application) tryNode(node *Node, ask *AllocationAsk) *Allocation {
-	allocKey := ask.AllocationKey
 	toAllocate := ask.AllocatedResource
 	// create the key for the reservation
 	if err := node.preAllocateCheck(toAllocate, reservationKey(nil, sa, ask), false); err != nil {
@@ -1082,11 +1674,14 @@ func (sa *Application) tryNode(node *Node, ask *AllocationAsk) *Allocation {
 		return nil
 	}
 	// skip the node if conditions can not be satisfied
-	if !node.preAllocateConditions(allocKey) {
+	if !node.preAllocateConditions(ask) {
 		return nil
 	}
 	// everything OK really allocate
 	alloc := NewAllocation(common.GetNewUUID(), node.NodeID, ask)
+	alloc.queueWait = time.Since(ask.GetCreateTime())
+	alloc.proposalTime = time.Now()
+	sa.setSpreadDomain(alloc, node)
 	if node.AddAllocation(alloc) {
 		if err := sa.queue.IncAllocatedResource(alloc.AllocatedResource, false); err != nil {
 			log.Logger().Warn("queue update failed unexpectedly",
@@ -1103,6 +1698,7 @@ func (sa *Application) tryNode(node *Node, ask *AllocationAsk) *Allocation {
 		}
 		// all is OK, last update for the app
 		sa.addAllocationInternal(alloc)
+		metrics.GetSchedulerMetrics().ObserveAllocationLatency(ask.GetCreateTime())
 		// return allocation
 		return alloc
 	}
@@ -1121,6 +1717,27 @@ func (sa *Application) GetQueue() *Queue {
 	return sa.queue
 }
 
+// Return the queue requested when the application was submitted.
+func (sa *Application) GetRequestedQueue() string {
+	sa.RLock()
+	defer sa.RUnlock()
+	return sa.requestedQueue
+}
+
+// Mark the queue of the application as created while placing the application.
+func (sa *Application) SetQueueCreated() {
+	sa.Lock()
+	defer sa.Unlock()
+	sa.queueCreated = true
+}
+
+// Return true if the queue was created while placing the application.
+func (sa *Application) IsQueueCreated() bool {
+	sa.RLock()
+	defer sa.RUnlock()
+	return sa.queueCreated
+}
+
 // Set the leaf queue the application runs in. The queue will be created when the app is added to the partition.
 // The queue name is set to what the placement rule returned.
 func (sa *Application) SetQueueName(queuePath string) {
@@ -1129,6 +1746,21 @@ func (sa *Application) SetQueueName(queuePath string) {
 	sa.QueueName = queuePath
 }
 
+// Set the name of the placement rule that placed the application, used to debug the placement.
+func (sa *Application) SetPlacementRule(ruleName string) {
+	sa.Lock()
+	defer sa.Unlock()
+	sa.placementRule = ruleName
+}
+
+// Return the name of the placement rule that placed the application.
+// Returns an empty string if the application was not placed by a placement rule.
+func (sa *Application) GetPlacementRule() string {
+	sa.RLock()
+	defer sa.RUnlock()
+	return sa.placementRule
+}
+
 // Set the leaf queue the application runs in.
 func (sa *Application) SetQueue(queue *Queue) {
 	sa.Lock()
@@ -1137,6 +1769,22 @@ func (sa *Application) SetQueue(queue *Queue) {
 	sa.queue = queue
 }
 
+// Refresh the queue name of the application, its requests and its allocations after the queue was moved.
+func (sa *Application) RefreshQueueName() {
+	sa.Lock()
+	defer sa.Unlock()
+	if sa.queue == nil {
+		return
+	}
+	sa.QueueName = sa.queue.GetQueuePath()
+	for _, ask := range sa.requests {
+		ask.setQueue(sa.QueueName)
+	}
+	for _, alloc := range sa.allocations {
+		alloc.QueueName = sa.QueueName
+	}
+}
+
 // remove the leaf queue the application runs in, used when completing the app
 func (sa *Application) UnSetQueue() {
 	if sa.queue != nil {
@@ -1159,6 +1807,13 @@ func (sa *Application) GetAllAllocations() []*Allocation {
 	return allocations
 }
 
+// get the allocation with the UUID, nil if the application does not have the allocation
+func (sa *Application) GetAllocation(uuid string) *Allocation {
+	sa.RLock()
+	defer sa.RUnlock()
+	return sa.allocations[uuid]
+}
+
 // get a copy of all placeholder allocations of the application
 // No locking must be called while holding the lock
 func (sa *Application) getPlaceholderAllocations() []*Allocation {
@@ -1189,9 +1844,20 @@ func (sa *Application) AddAllocation(info *Allocation) {
 	sa.addAllocationInternal(info)
 }
 
+// Add an existing Allocation, like a recovered allocation, to the application.
+// The spread domain of the allocation is resolved from the node it runs on.
+func (sa *Application) AddAllocationOnNode(info *Allocation, node *Node) {
+	sa.Lock()
+	defer sa.Unlock()
+	sa.setSpreadDomain(info, node)
+	sa.addAllocationInternal(info)
+}
+
 // Add the Allocation to the application
 // No locking must be called while holding the lock
 func (sa *Application) addAllocationInternal(info *Allocation) {
+	// the runtime is counted from the first allocation of the application
+	sa.initRuntimeTimer()
 	// placeholder allocations do not progress the state of the app and are tracked in a separate total
 	if info.placeholder {
 		// when we have the first placeholder allocation start the placeholder timer.
@@ -1225,9 +1891,20 @@ func (sa *Application) addAllocationInternal(info *Allocation) {
 		}
 		sa.allocatedResource = resources.Add(sa.allocatedResource, info.AllocatedResource)
 	}
+	if sa.queue != nil {
+		sa.queue.incPriorityAllocated(info.Priority, info.AllocatedResource)
+	}
+	sa.initLifetimeTimer(info)
+	sa.updateSpreadCount(info, 1)
 	sa.allocations[info.UUID] = info
 }
 
+// Check if the request fits in the priority quota of the queue of the application.
+// NOTE: this is a lock free call. It must only be called holding the application lock.
+func (sa *Application) fitsPriorityQuota(request *AllocationAsk) bool {
+	return sa.queue == nil || sa.queue.fitsPriorityQuota(request.priority, request.AllocatedResource)
+}
+
 func (sa *Application) ReplaceAllocation(uuid string) *Allocation {
 	sa.Lock()
 	defer sa.Unlock()
@@ -1299,8 +1976,17 @@ func (sa *Application) removeAllocationInternal(uuid string) *Allocation {
 		}
 	} else {
 		sa.allocatedResource = resources.Sub(sa.allocatedResource, alloc.AllocatedResource)
-		// When the resource trackers are zero we should not expect anything to come in later.
-		if resources.IsZero(sa.pending) && resources.IsZero(sa.allocatedResource) {
+		// A failing application is failed when the last allocation has been released
+		if sa.IsFailing() {
+			if resources.IsZero(sa.allocatedResource) && resources.IsZero(sa.allocatedPlaceholder) {
+				if err := sa.HandleApplicationEvent(FailApplication); err != nil {
+					log.Logger().Warn("Application state not changed to Failed while removing an allocation",
+						zap.String("currentState", sa.CurrentState()),
+						zap.Error(err))
+				}
+			}
+		} else if resources.IsZero(sa.pending) && resources.IsZero(sa.allocatedResource) {
+			// When the resource trackers are zero we should not expect anything to come in later.
 			if err := sa.HandleApplicationEvent(CompleteApplication); err != nil {
 				log.Logger().Warn("Application state not changed to Waiting while removing an allocation",
 					zap.String("currentState", sa.CurrentState()),
@@ -1308,7 +1994,13 @@ func (sa *Application) removeAllocationInternal(uuid string) *Allocation {
 			}
 		}
 	}
+	if sa.queue != nil {
+		sa.queue.decPriorityAllocated(alloc.Priority, alloc.AllocatedResource)
+	}
+	clearLifetimeTimer(alloc)
+	sa.updateSpreadCount(alloc, -1)
 	delete(sa.allocations, uuid)
+	sa.markActive()
 	return alloc
 }
 
@@ -1321,13 +2013,26 @@ func (sa *Application) RemoveAllAllocations() []*Allocation {
 	allocationsToRelease := make([]*Allocation, 0)
 	for _, alloc := range sa.allocations {
 		allocationsToRelease = append(allocationsToRelease, alloc)
+		if sa.queue != nil {
+			sa.queue.decPriorityAllocated(alloc.Priority, alloc.AllocatedResource)
+		}
+		clearLifetimeTimer(alloc)
 	}
 	// cleanup allocated resource for app (placeholders and normal)
 	sa.allocatedResource = resources.NewResource()
 	sa.allocatedPlaceholder = resources.NewResource()
 	sa.allocations = make(map[string]*Allocation)
-	// When the resource trackers are zero we should not expect anything to come in later.
-	if resources.IsZero(sa.pending) {
+	sa.spreadCounters = nil
+	sa.markActive()
+	// A failing application has nothing left to clean up: move it to the failed state
+	if sa.IsFailing() {
+		if err := sa.HandleApplicationEvent(FailApplication); err != nil {
+			log.Logger().Warn("Application state not changed to Failed while removing all allocations",
+				zap.String("currentState", sa.CurrentState()),
+				zap.Error(err))
+		}
+	} else if resources.IsZero(sa.pending) {
+		// When the resource trackers are zero we should not expect anything to come in later.
 		if err := sa.HandleApplicationEvent(CompleteApplication); err != nil {
 			log.Logger().Warn("Application state not changed to Waiting while removing all allocations",
 				zap.String("currentState", sa.CurrentState()),
@@ -1335,6 +2040,7 @@ func (sa *Application) RemoveAllAllocations() []*Allocation {
 		}
 	}
 	sa.clearPlaceholderTimer()
+	sa.clearRuntimeTimer()
 	sa.clearStateTimer()
 	return allocationsToRelease
 }
@@ -1363,6 +2069,142 @@ func (sa *Application) GetTag(tag string) string {
 	return tagVal
 }
 
+// Get a copy of all tags of the application
+func (sa *Application) GetTags() map[string]string {
+	sa.RLock()
+	defer sa.RUnlock()
+
+	tags := make(map[string]string, len(sa.tags))
+	for key, val := range sa.tags {
+		tags[key] = val
+	}
+	return tags
+}
+
+// Mark the application as restored from a scheduler state snapshot.
+func (sa *Application) SetRestored() {
+	sa.Lock()
+	defer sa.Unlock()
+	sa.restored = true
+	if sa.recoveredTime.IsZero() {
+		sa.recoveredTime = time.Now()
+	}
+}
+
+// Return true if the application was restored from a snapshot and the RM has not added it again.
+func (sa *Application) IsRestored() bool {
+	sa.RLock()
+	defer sa.RUnlock()
+	return sa.restored
+}
+
+// Confirm a restored application when the RM adds it again. The RM is the source of truth: the user of the
+// application added by the RM replaces the user from the snapshot and its tags are merged into the tags from
+// the snapshot, replacing the tags with the same key.
+// Returns true if the application was restored and not confirmed before.
+func (sa *Application) ConfirmRestored(added *Application) bool {
+	if added == nil {
+		return false
+	}
+	user := added.GetUser()
+	tags := added.GetTags()
+	sa.Lock()
+	defer sa.Unlock()
+	if !sa.restored {
+		return false
+	}
+	sa.restored = false
+	sa.user = user
+	if sa.tags == nil {
+		sa.tags = make(map[string]string, len(tags))
+	}
+	for key, value := range tags {
+		sa.tags[key] = value
+	}
+	return true
+}
+
+// Return true if the application was recovered, from the RM or a snapshot, less than the window ago.
+func (sa *Application) IsRecoveredWithin(window time.Duration) bool {
+	sa.RLock()
+	defer sa.RUnlock()
+	return !sa.recoveredTime.IsZero() && time.Since(sa.recoveredTime) < window
+}
+
+// Record an ask change or allocation release: the application is no longer idle.
+// NOTE: this is a lock free call. It must only be called holding the application lock.
+func (sa *Application) markActive() {
+	sa.lastActivity = time.Now()
+	sa.idle = false
+}
+
+// Return true if the application holds allocations, has had no ask changes or allocation releases for at least
+// the timeout and has not been flagged idle since its last activity.
+func (sa *Application) IsInactive(timeout time.Duration) bool {
+	sa.RLock()
+	defer sa.RUnlock()
+	return !sa.idle && len(sa.allocations) != 0 && time.Since(sa.lastActivity) >= timeout
+}
+
+// Flag the application as idle. The flag is reset on the next ask change or allocation release.
+func (sa *Application) SetIdle() {
+	sa.Lock()
+	defer sa.Unlock()
+	sa.idle = true
+	sa.executeStateChangedCallback()
+}
+
+// Return true if the application is flagged as idle.
+func (sa *Application) IsIdle() bool {
+	sa.RLock()
+	defer sa.RUnlock()
+	return sa.idle
+}
+
+// Return the allocations to release to reclaim the fraction of the allocated resources of the application, the
+// fraction is rounded up.
+// The lowest priority allocations are selected first, the newest first for the same priority. Placeholders are
+// never selected, they are released by the placeholder timeout.
+func (sa *Application) GetReclaimAllocations(fraction float64) []*Allocation {
+	sa.RLock()
+	defer sa.RUnlock()
+	// round up: any fraction of an allocation requires a release
+	target := resources.NewResource()
+	for name, quantity := range sa.allocatedResource.Resources {
+		target.Resources[name] = resources.Quantity(math.Ceil(float64(quantity) * fraction))
+	}
+	if resources.IsZero(target) {
+		return nil
+	}
+	candidates := make([]*Allocation, 0, len(sa.allocations))
+	for _, alloc := range sa.allocations {
+		if !alloc.IsPlaceholder() {
+			candidates = append(candidates, alloc)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		l := candidates[i]
+		r := candidates[j]
+		if l.Priority != r.Priority {
+			return l.Priority < r.Priority
+		}
+		if !l.proposalTime.Equal(r.proposalTime) {
+			return l.proposalTime.After(r.proposalTime)
+		}
+		return l.UUID < r.UUID
+	})
+	reclaimed := resources.NewResource()
+	selected := make([]*Allocation, 0)
+	for _, alloc := range candidates {
+		if resources.FitIn(reclaimed, target) {
+			break
+		}
+		selected = append(selected, alloc)
+		reclaimed.AddTo(alloc.AllocatedResource)
+	}
+	return selected
+}
+
 func (sa *Application) SetTerminatedCallback(callback func(appID string)) {
 	sa.Lock()
 	defer sa.Unlock()
@@ -1375,6 +2217,19 @@ func (sa *Application) executeTerminatedCallback() {
 	}
 }
 
+func (sa *Application) SetStateChangedCallback(callback func()) {
+	sa.Lock()
+	defer sa.Unlock()
+	sa.stateChangedCallback = callback
+}
+
+// The callback must not lock the application: it is called holding the application lock.
+func (sa *Application) executeStateChangedCallback() {
+	if sa.stateChangedCallback != nil {
+		sa.stateChangedCallback()
+	}
+}
+
 func (sa *Application) notifyRMAllocationReleased(rmID string, released []*Allocation, terminationType si.TerminationType, message string) {
 	// only generate event if needed
 	if len(released) == 0 {
@@ -1392,7 +2247,10 @@ func (sa *Application) notifyRMAllocationReleased(rmID string, released []*Alloc
 			TerminationType: terminationType,
 			Message:         message,
 		})
+		alloc.ExportReleased(terminationType, message)
 	}
+	// releases are triggered by timers: the allocation lifetime or a placeholder timeout
+	sa.executeStateChangedCallback()
 	sa.rmEventHandler.HandleEvent(releaseEvent)
 }
 
@@ -168,10 +168,12 @@ func NewAppState() *fsm.FSM {
 				app := setTimer(terminatedTimeout, event, ExpireApplication)
 				app.executeTerminatedCallback()
 				app.clearPlaceholderTimer()
+				app.clearRuntimeTimer()
 			},
 			fmt.Sprintf("enter_%s", Failed.String()): func(event *fsm.Event) {
 				app := setTimer(terminatedTimeout, event, ExpireApplication)
 				app.executeTerminatedCallback()
+				app.clearRuntimeTimer()
 			},
 		},
 	)
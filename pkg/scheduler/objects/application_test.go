@@ -27,9 +27,11 @@ import (
 	"gotest.tools/assert"
 
 	"github.com/apache/incubator-yunikorn-core/pkg/common"
+	"github.com/apache/incubator-yunikorn-core/pkg/common/configs"
 	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
 	"github.com/apache/incubator-yunikorn-core/pkg/common/security"
 	"github.com/apache/incubator-yunikorn-core/pkg/handler"
+	"github.com/apache/incubator-yunikorn-core/pkg/interfaces"
 	"github.com/apache/incubator-yunikorn-core/pkg/rmproxy/rmevent"
 	"github.com/apache/incubator-yunikorn-scheduler-interface/lib/go/si"
 )
@@ -139,6 +141,11 @@ func TestAppReservation(t *testing.T) {
 	if app.hasReserved() && !app.IsReservedOnNode(nodeID1) {
 		t.Errorf("app should have reservations for node %s", nodeID1)
 	}
+	infos := app.GetReservationInfos()
+	assert.Equal(t, len(infos), 1, "expected one reservation info")
+	assert.Equal(t, infos[0].NodeID, nodeID1, "unexpected node in reservation info")
+	assert.Equal(t, infos[0].AllocationKey, aKey, "unexpected ask in reservation info")
+	assert.Assert(t, resources.Equals(infos[0].Reserved, res), "unexpected reserved resource in reservation info")
 
 	// reserve the same reservation
 	err = app.Reserve(node, ask)
@@ -245,6 +252,104 @@ func TestAppAllocReservation(t *testing.T) {
 	}
 }
 
+func TestReservationTimeout(t *testing.T) {
+	defer SetReservationTimeout(0, 30*time.Second)
+	app := newApplication(appID1, "default", "root.unknown")
+	queue, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	app.queue = queue
+
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 5})
+	ask := newAllocationAsk(aKey, appID1, res)
+	err = app.AddAllocationAsk(ask)
+	assert.NilError(t, err, "ask should have been added to app")
+	// node with the ask occupying resources: only a reservation is possible
+	node := newNodeInternal(nodeID1, resources.NewResourceFromMap(map[string]resources.Quantity{"first": 10}),
+		resources.NewResourceFromMap(map[string]resources.Quantity{"first": 8}))
+	err = app.Reserve(node, ask)
+	assert.NilError(t, err, "reservation should not have failed")
+	reserve := app.reservations[nodeID1+"|"+aKey]
+	assert.Assert(t, reserve != nil, "reservation not found")
+
+	// no timeout set: reservation never times out
+	reserve.created = time.Now().Add(-time.Hour)
+	assert.Assert(t, !app.reservationTimedOut(reserve), "reservation should not time out without timeout set")
+	assert.Assert(t, !app.isReserveBlacklisted(nodeID1, aKey), "node should not be blacklisted")
+
+	// timeout set, reservation not yet expired
+	SetReservationTimeout(time.Minute, time.Minute)
+	reserve.created = time.Now()
+	assert.Assert(t, !app.reservationTimedOut(reserve), "reservation should not have timed out")
+	alloc := app.tryReservedAllocate(res, func() interfaces.NodeIterator { return nil })
+	assert.Assert(t, alloc == nil, "unexpected allocation returned: %v", alloc)
+
+	// reservation expired: unreserve proposed and node blacklisted
+	reserve.created = time.Now().Add(-2 * time.Minute)
+	alloc = app.tryReservedAllocate(res, func() interfaces.NodeIterator { return nil })
+	assert.Assert(t, alloc != nil, "expected unreserve proposal")
+	assert.Equal(t, alloc.Result, Unreserved, "expected unreserve result")
+	assert.Equal(t, alloc.NodeID, nodeID1, "unexpected node in unreserve result")
+	assert.Assert(t, app.isReserveBlacklisted(nodeID1, aKey), "node should be blacklisted for the ask")
+	assert.Assert(t, !app.isReserveBlacklisted(nodeID1, "other"), "node should not be blacklisted for other ask")
+
+	// expired blacklist entry is removed on check
+	app.reserveBlacklist[reserveBlacklistKey(nodeID1, aKey)] = time.Now().Add(-time.Second)
+	assert.Assert(t, !app.isReserveBlacklisted(nodeID1, aKey), "blacklist should have expired")
+	assert.Equal(t, len(app.reserveBlacklist), 0, "expired blacklist entry not removed")
+}
+
+func TestSpreadConstraint(t *testing.T) {
+	tests := []struct {
+		name string
+		tags map[string]string
+		max  int
+		key  string
+	}{
+		{"no tags", nil, 0, ""},
+		{"key only", map[string]string{SpreadKeyTag: "zone"}, 0, ""},
+		{"invalid max", map[string]string{SpreadMaxTag: "many"}, 0, ""},
+		{"zero max", map[string]string{SpreadMaxTag: "0"}, 0, ""},
+		{"per node", map[string]string{SpreadMaxTag: "2"}, 2, ""},
+		{"per zone", map[string]string{SpreadMaxTag: "1", SpreadKeyTag: "zone"}, 1, "zone"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newApplicationWithTags(appID1, "default", "root.unknown", tt.tags)
+			assert.Equal(t, app.spreadMax, tt.max, "unexpected spread maximum")
+			assert.Equal(t, app.spreadKey, tt.key, "unexpected spread key")
+		})
+	}
+
+	app := newApplicationWithTags(appID1, "default", "root.unknown", map[string]string{SpreadMaxTag: "1", SpreadKeyTag: "zone"})
+	node := newNode(nodeID1, map[string]resources.Quantity{"first": 10})
+	assert.Assert(t, app.spreadAllowed(nil, node), "nil counts should not constrain")
+	assert.Assert(t, !app.spreadAllowed(map[string]int{}, node), "node without spread key should not be allowed")
+	node.SetAttributes(map[string]string{"zone": "zone-a"})
+	assert.Assert(t, app.spreadAllowed(map[string]int{"zone-b": 1}, node), "empty zone should be allowed")
+	assert.Assert(t, !app.spreadAllowed(map[string]int{"zone-a": 1}, node), "full zone should not be allowed")
+}
+
+func TestSpreadCounts(t *testing.T) {
+	app := newApplicationWithTags(appID1, "default", "root.unknown", map[string]string{SpreadMaxTag: "1", SpreadKeyTag: "zone"})
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})
+	node := newNode(nodeID1, map[string]resources.Quantity{"first": 10})
+	node.SetAttributes(map[string]string{"zone": "zone-a"})
+	ask := newAllocationAsk(aKey, appID1, res)
+	assert.Equal(t, len(app.spreadCounts(ask)), 0, "no allocations should give empty counts")
+
+	// a recovered allocation has no spread domain: it must be resolved from the node
+	alloc := newAllocation(appID1, "uuid-1", nodeID1, "root.unknown", res)
+	app.AddAllocationOnNode(alloc, node)
+	assert.Equal(t, alloc.spreadDomain, "zone-a", "spread domain not resolved from the node")
+	assert.DeepEqual(t, app.spreadCounts(ask), map[string]int{"zone-a": 1})
+	assert.Assert(t, !app.spreadAllowed(app.spreadCounts(ask), node), "full zone should not be allowed")
+
+	// removal updates the counters
+	app.RemoveAllocation("uuid-1")
+	assert.Equal(t, len(app.spreadCounts(ask)), 0, "counts not updated on removal")
+	assert.Assert(t, app.spreadAllowed(app.spreadCounts(ask), node), "empty zone should be allowed")
+}
+
 // test update allocation repeat
 func TestUpdateRepeat(t *testing.T) {
 	app := newApplication(appID1, "default", "root.unknown")
@@ -291,6 +396,31 @@ func TestUpdateRepeat(t *testing.T) {
 	}
 }
 
+func TestGetPendingAsks(t *testing.T) {
+	app := newApplication(appID1, "default", "root.unknown")
+	queue, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	app.queue = queue
+	assert.Equal(t, len(app.GetPendingAsks()), 0, "new app should not have pending asks")
+
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 5})
+	err = app.AddAllocationAsk(newAllocationAskRepeat("alloc-2", appID1, res, 2))
+	assert.NilError(t, err, "ask alloc-2 should have been added to app")
+	err = app.AddAllocationAsk(newAllocationAskRepeat("alloc-1", appID1, res, 1))
+	assert.NilError(t, err, "ask alloc-1 should have been added to app")
+	asks := app.GetPendingAsks()
+	assert.Equal(t, len(asks), 2, "expected two pending asks")
+	assert.Equal(t, asks[0].AllocationKey, "alloc-1", "pending asks should be ordered by key")
+	assert.Equal(t, asks[1].GetPendingAskRepeat(), int32(2), "unexpected pending repeat")
+
+	// asks without an outstanding repeat are not pending
+	_, err = app.updateAskRepeat("alloc-1", -1)
+	assert.NilError(t, err, "ask repeat update failed")
+	asks = app.GetPendingAsks()
+	assert.Equal(t, len(asks), 1, "expected one pending ask")
+	assert.Equal(t, asks[0].AllocationKey, "alloc-2", "unexpected pending ask")
+}
+
 // test pending calculation and ask addition
 func TestAddAllocAsk(t *testing.T) {
 	app := newApplication(appID1, "default", "root.unknown")
@@ -358,6 +488,56 @@ func TestAddAllocAsk(t *testing.T) {
 	assert.Assert(t, app.IsAccepted(), "Application should have stayed in accepted state")
 }
 
+func TestUpdateAllocAsk(t *testing.T) {
+	app := newApplication(appID1, "default", "root.unknown")
+	queue, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	app.queue = queue
+
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 5})
+	ask := newAllocationAskRepeat(aKey, appID1, res, 2)
+	ask.createTime = time.Now().Add(-time.Hour)
+	err = app.AddAllocationAsk(ask)
+	assert.NilError(t, err, "ask should have been added to app")
+
+	// failure cases
+	_, err = app.UpdateAllocationAsk(nil)
+	assert.Assert(t, err != nil, "nil ask should not have been updated")
+	_, err = app.UpdateAllocationAsk(newAllocationAsk("unknown", appID1, res))
+	assert.Assert(t, err != nil, "unknown ask should not have been updated")
+	_, err = app.UpdateAllocationAsk(newAllocationAskRepeat(aKey, appID1, res, 0))
+	assert.Assert(t, err != nil, "ask with zero repeat should not have been updated")
+	placeholder := newAllocationAsk(aKey, appID1, res)
+	placeholder.placeholder = true
+	placeholder.taskGroupName = "tg-1"
+	_, err = app.UpdateAllocationAsk(placeholder)
+	assert.Assert(t, err != nil, "ask should not have been changed into a placeholder")
+	assert.Assert(t, resources.Equals(app.GetPendingResource(), resources.Multiply(res, 2)), "failed update should not change pending")
+
+	// reserve the ask on a small and a large node
+	small := newNode(nodeID1, map[string]resources.Quantity{"first": 10})
+	large := newNode("node-2", map[string]resources.Quantity{"first": 20})
+	err = app.Reserve(small, ask)
+	assert.NilError(t, err, "reservation on small node should not have failed")
+	err = app.Reserve(large, ask)
+	assert.NilError(t, err, "reservation on large node should not have failed")
+
+	// resize: the ask keeps its create time, the reservation that does not fit is removed
+	updated := newAllocationAskRepeat(aKey, appID1, resources.NewResourceFromMap(map[string]resources.Quantity{"first": 15}), 2)
+	var released int
+	released, err = app.UpdateAllocationAsk(updated)
+	assert.NilError(t, err, "ask should have been updated on app")
+	assert.Equal(t, released, 1, "reservation on small node should have been released")
+	assert.Assert(t, !small.IsReserved(), "small node should not be reserved")
+	assert.Assert(t, large.IsReserved(), "large node should still be reserved")
+	current := app.GetAllocationAsk(aKey)
+	assert.Equal(t, current, ask, "ask should have been updated in place")
+	assert.Assert(t, resources.Equals(current.AllocatedResource, updated.AllocatedResource), "ask resource not updated")
+	assert.Assert(t, current.GetCreateTime().Before(updated.GetCreateTime()), "ask create time should not have changed")
+	assert.Assert(t, resources.Equals(app.GetPendingResource(), resources.Multiply(updated.AllocatedResource, 2)), "pending not updated: %v", app.GetPendingResource())
+	assert.Assert(t, resources.Equals(queue.GetPendingResource(), app.GetPendingResource()), "queue pending not updated: %v", queue.GetPendingResource())
+}
+
 // test state change on add and remove ask
 func TestAllocAskStateChange(t *testing.T) {
 	app := newApplication(appID1, "default", "root.unknown")
@@ -1038,6 +1218,107 @@ func runTimeoutPlaceholderTest(t *testing.T, expectedState string, gangSchedulin
 	assert.Assert(t, resources.Equals(app.GetPlaceholderResource(), resources.Multiply(res, 2)), "Unexpected placeholder resources for the app")
 }
 
+func TestMaxRuntime(t *testing.T) {
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	leaf, err := createManagedQueueWithProps(root, "leaf", false, nil, map[string]string{configs.ApplicationMaxRuntime: "1h"})
+	assert.NilError(t, err, "leaf queue create failed")
+	assert.Equal(t, leaf.GetMaxRuntime(), time.Hour, "queue maximum runtime not set from property")
+
+	app := newApplication(appID1, "default", "root.leaf")
+	assert.Equal(t, app.getMaxRuntime(), time.Duration(0), "app without tag or queue should not be limited")
+	app.queue = leaf
+	assert.Equal(t, app.getMaxRuntime(), time.Hour, "queue limit should be used without tag")
+
+	app = newApplicationWithTags(appID1, "default", "root.leaf", map[string]string{MaxRuntimeTag: "10m"})
+	assert.Equal(t, app.getMaxRuntime(), 10*time.Minute, "tag limit should be used without queue")
+	app.queue = leaf
+	assert.Equal(t, app.getMaxRuntime(), 10*time.Minute, "lower tag limit should be used")
+	app = newApplicationWithTags(appID1, "default", "root.leaf", map[string]string{MaxRuntimeTag: "2h"})
+	app.queue = leaf
+	assert.Equal(t, app.getMaxRuntime(), time.Hour, "lower queue limit should be used")
+	app = newApplicationWithTags(appID1, "default", "root.leaf", map[string]string{MaxRuntimeTag: "-1h"})
+	assert.Equal(t, app.maxRuntime, time.Duration(0), "negative tag should be ignored")
+	app = newApplicationWithTags(appID1, "default", "root.leaf", map[string]string{MaxRuntimeTag: "forever"})
+	assert.Equal(t, app.maxRuntime, time.Duration(0), "invalid tag should be ignored")
+}
+
+func TestTimeoutRuntime(t *testing.T) {
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	leaf, err := createManagedQueueWithProps(root, "leaf", false, nil, map[string]string{configs.ApplicationMaxRuntime: "5ms"})
+	assert.NilError(t, err, "leaf queue create failed")
+
+	app, testHandler := newApplicationWithHandler(appID1, "default", "root.leaf")
+	app.queue = leaf
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 100})
+	err = app.AddAllocationAsk(newAllocationAsk("ask-1", appID1, res))
+	assert.NilError(t, err, "Application ask should have been added")
+	assert.Assert(t, app.runtimeTimer == nil, "runtime timer should not be started without allocations")
+	app.AddAllocation(newAllocation(appID1, "uuid-1", nodeID1, "root.leaf", res))
+	err = common.WaitFor(1*time.Millisecond, 100*time.Millisecond, func() bool {
+		return app.IsFailing()
+	})
+	assert.NilError(t, err, "application should have been failed after the maximum runtime")
+	var found int
+	for _, event := range testHandler.getEvents() {
+		if allocRelease, ok := event.(*rmevent.RMReleaseAllocationEvent); ok {
+			assert.Equal(t, len(allocRelease.ReleasedAllocations), 1, "one allocation should have been released")
+			assert.Equal(t, allocRelease.ReleasedAllocations[0].TerminationType, si.TerminationType_TIMEOUT)
+			found++
+		}
+		if askRelease, ok := event.(*rmevent.RMReleaseAllocationAskEvent); ok {
+			assert.Equal(t, len(askRelease.ReleasedAllocationAsks), 1, "one allocation ask should have been released")
+			found++
+		}
+	}
+	assert.Equal(t, found, 2, "release allocation or ask event not found in list")
+	assert.Assert(t, resources.IsZero(app.GetPendingResource()), "pending resources should be zero")
+	// the allocation is removed when the RM confirms the release
+	app.RemoveAllocation("uuid-1")
+	assert.Assert(t, app.IsFailed(), "application should be failed after the release: %s", app.CurrentState())
+	assert.Assert(t, app.runtimeTimer == nil, "runtime timer should be cleared")
+}
+
+func TestTimeoutAllocationLifetime(t *testing.T) {
+	app, testHandler := newApplicationWithHandler(appID1, "default", "root.a")
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 100})
+	siAsk := &si.AllocationAsk{
+		AllocationKey:  "ask-1",
+		ApplicationID:  appID1,
+		PartitionName:  "default",
+		ResourceAsk:    res.ToProto(),
+		MaxAllocations: 1,
+		Tags:           map[string]string{MaxLifetimeTag: "5ms"},
+	}
+	limited := NewAllocation("uuid-1", nodeID1, NewAllocationAsk(siAsk))
+	app.AddAllocation(limited)
+	assert.Assert(t, limited.lifetimeTimer != nil, "lifetime timer should be started for the allocation")
+	unlimited := newAllocation(appID1, "uuid-2", nodeID1, "root.a", res)
+	app.AddAllocation(unlimited)
+	assert.Assert(t, unlimited.lifetimeTimer == nil, "lifetime timer should not be started without a tag")
+
+	var allocRelease *rmevent.RMReleaseAllocationEvent
+	err := common.WaitFor(1*time.Millisecond, 100*time.Millisecond, func() bool {
+		for _, event := range testHandler.getEvents() {
+			if release, ok := event.(*rmevent.RMReleaseAllocationEvent); ok {
+				allocRelease = release
+				return true
+			}
+		}
+		return false
+	})
+	assert.NilError(t, err, "allocation release should have been requested")
+	assert.Equal(t, len(allocRelease.ReleasedAllocations), 1, "one allocation should have been released")
+	assert.Equal(t, allocRelease.ReleasedAllocations[0].UUID, "uuid-1", "unexpected allocation released")
+	assert.Equal(t, allocRelease.ReleasedAllocations[0].TerminationType, si.TerminationType_TIMEOUT)
+	assert.Assert(t, !app.IsFailing(), "application should not be failed: %s", app.CurrentState())
+	// the allocation is only removed when the RM confirms the release
+	assert.Equal(t, len(app.GetAllAllocations()), 2, "allocation should not have been removed")
+	app.RemoveAllocation("uuid-1")
+	assert.Equal(t, len(app.GetAllAllocations()), 1, "allocation should have been removed")
+}
+
 func TestTimeoutPlaceholderAllocReleased(t *testing.T) {
 	originalPhTimeout := defaultPlaceholderTimeout
 	defaultPlaceholderTimeout = 5 * time.Millisecond
@@ -1172,3 +1453,106 @@ func TestGetAllRequests(t *testing.T) {
 	assert.Assert(t, len(app.getAllRequests()) == 1, "App should have only one request")
 	assert.Equal(t, app.getAllRequests()[0], ask, "Unexpected request found in the app")
 }
+
+func TestFindReservationToSteal(t *testing.T) {
+	SetReservationDelay(10 * time.Nanosecond)
+	defer SetReservationDelay(2 * time.Second)
+	defer SetReservationStealDelta(0)
+
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	queue, err := createManagedQueue(root, "a", false, nil)
+	assert.NilError(t, err, "queue create failed")
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 5})
+	node1 := newNodeRes(nodeID1, res)
+	node2 := newNodeRes("node-2", res)
+	lowApp := newApplication("app-low", "default", "root.a")
+	lowApp.queue = queue
+	lowAsk := newAllocationAsk("alloc-low", "app-low", res)
+	lowAsk.priority = 1
+	err = lowApp.AddAllocationAsk(lowAsk)
+	assert.NilError(t, err, "ask should have been added to app")
+	err = lowApp.Reserve(node1, lowAsk)
+	assert.NilError(t, err, "reservation should not have failed")
+
+	app := newApplication(appID1, "default", "root.a")
+	app.queue = queue
+	ask := newAllocationAsk(aKey, appID1, res)
+	ask.priority = 10
+	err = app.AddAllocationAsk(ask)
+	assert.NilError(t, err, "ask should have been added to app")
+	time.Sleep(time.Millisecond)
+
+	assert.Assert(t, app.FindReservationToSteal([]*Node{node1}) == nil, "stealing should be disabled")
+	SetReservationStealDelta(5)
+	// a node that is not reserved can be reserved normally
+	assert.Assert(t, app.FindReservationToSteal([]*Node{node1, node2}) == nil, "free node should prevent stealing")
+	steal := app.FindReservationToSteal([]*Node{node1})
+	assert.Assert(t, steal != nil, "reservation should be stealable")
+	assert.Equal(t, steal.Ask, ask, "unexpected ask for the steal")
+	assert.Equal(t, steal.Node, node1, "unexpected node for the steal")
+	assert.Equal(t, steal.Stolen.ApplicationID, "app-low", "unexpected stolen reservation")
+	assert.Equal(t, steal.Stolen.Priority, int32(1), "unexpected stolen priority")
+
+	// the priority difference must be at least the steal delta
+	SetReservationStealDelta(10)
+	assert.Assert(t, app.FindReservationToSteal([]*Node{node1}) == nil, "priority difference below the delta")
+}
+
+func TestIdleApplication(t *testing.T) {
+	app := newApplication(appID1, "default", "root.unknown")
+	queue, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	app.queue = queue
+	assert.Assert(t, !app.IsInactive(0), "application without allocations should never be inactive")
+
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})
+	app.AddAllocation(newAllocation(appID1, "uuid-1", nodeID1, "root.unknown", res))
+	assert.Assert(t, app.IsInactive(0), "application with allocations should be inactive")
+	assert.Assert(t, !app.IsInactive(time.Hour), "application should not be inactive before the timeout")
+	app.SetIdle()
+	assert.Assert(t, app.IsIdle(), "application should have been flagged idle")
+	assert.Assert(t, !app.IsInactive(0), "idle application should only be reported once")
+
+	// an ask resets the idle flag
+	err = app.AddAllocationAsk(newAllocationAsk("alloc-1", appID1, res))
+	assert.NilError(t, err, "ask should have been added to app")
+	assert.Assert(t, !app.IsIdle(), "ask should have reset the idle flag")
+	assert.Assert(t, app.IsInactive(0), "application should be inactive again after the timeout")
+	app.SetIdle()
+	// a release resets the idle flag
+	app.RemoveAllocation("uuid-1")
+	assert.Assert(t, !app.IsIdle(), "release should have reset the idle flag")
+}
+
+func TestGetReclaimAllocations(t *testing.T) {
+	app := newApplication(appID1, "default", "root.unknown")
+	queue, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	app.queue = queue
+	assert.Equal(t, len(app.GetReclaimAllocations(0.5)), 0, "application without allocations should not reclaim")
+
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})
+	now := time.Now()
+	for i, prio := range []int32{10, 0, 0, 5} {
+		alloc := newAllocation(appID1, "uuid-"+strconv.Itoa(i), nodeID1, "root.unknown", res)
+		alloc.Priority = prio
+		alloc.proposalTime = now.Add(time.Duration(i) * time.Second)
+		app.AddAllocation(alloc)
+	}
+	app.AddAllocation(newPlaceholderAlloc(appID1, "uuid-ph", nodeID1, "root.unknown", res))
+
+	// lowest priority first, newest first for the same priority
+	assertUUIDs := func(allocs []*Allocation, expected []string) {
+		uuids := make([]string, 0, len(allocs))
+		for _, alloc := range allocs {
+			uuids = append(uuids, alloc.UUID)
+		}
+		assert.DeepEqual(t, uuids, expected)
+	}
+	assertUUIDs(app.GetReclaimAllocations(0.25), []string{"uuid-2"})
+	assertUUIDs(app.GetReclaimAllocations(0.5), []string{"uuid-2", "uuid-1"})
+	assertUUIDs(app.GetReclaimAllocations(0.6), []string{"uuid-2", "uuid-1", "uuid-3"})
+	// placeholders are never reclaimed
+	assertUUIDs(app.GetReclaimAllocations(1), []string{"uuid-2", "uuid-1", "uuid-3", "uuid-0"})
+}
@@ -20,8 +20,10 @@ package objects
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"go.uber.org/zap"
 
@@ -42,6 +44,7 @@ type Node struct {
 
 	// Private fields need protection
 	attributes        map[string]string
+	taints            map[string]string // taints on the node: only asks tolerating all taints can be placed on the node
 	totalResource     *resources.Resource
 	occupiedResource  *resources.Resource
 	allocatedResource *resources.Resource
@@ -49,12 +52,29 @@ type Node struct {
 	allocations       map[string]*Allocation
 	schedulable       bool
 
-	preempting   *resources.Resource     // resources considered for preemption
-	reservations map[string]*reservation // a map of reservations
+	preempting   *resources.Resource        // resources considered for preemption
+	reservations map[string]*reservation    // a map of reservations
+	generation   uint64                     // changes every time the node changes in a way that could affect predicates
+	predicates   map[string]predicateResult // cached predicate results for the current generation
+
+	drainStart       time.Time // time the drain of the node started, zero if the node is not draining
+	drainAllocations int       // number of allocations on the node when the drain started
 
 	sync.RWMutex
 }
 
+// The time a cached predicate result is used. The shim predicates depend on cluster state the core does not track,
+// like the pods on other nodes for inter pod affinity: a result is only reused for a short time.
+var predicateCacheTTL = time.Second
+
+// Cached result of a predicate plugin call for an ask on this node
+type predicateResult struct {
+	generation    uint64
+	askGeneration uint64
+	expires       time.Time
+	passed        bool
+}
+
 func NewNode(proto *si.NewNodeInfo) *Node {
 	// safe guard against panic
 	if proto == nil {
@@ -69,6 +89,7 @@ func NewNode(proto *si.NewNodeInfo) *Node {
 		occupiedResource:  resources.NewResourceFromProto(proto.OccupiedResource),
 		allocations:       make(map[string]*Allocation),
 		schedulable:       true,
+		predicates:        make(map[string]predicateResult),
 	}
 	// initialise available resources
 	var err error
@@ -99,15 +120,88 @@ func (sn *Node) initializeAttribute(newAttributes map[string]string) {
 	sn.Hostname = sn.attributes[common.HostName]
 	sn.Rackname = sn.attributes[common.RackName]
 	sn.Partition = sn.attributes[common.NodePartition]
+	sn.taints = parseTaints(sn.attributes[NodeTaintsAttribute])
 }
 
 // Get an attribute by name. The most used attributes can be directly accessed via the
 // fields: HostName, RackName and Partition.
-// This is a lock free call. All attributes are considered read only
 func (sn *Node) GetAttribute(key string) string {
+	sn.RLock()
+	defer sn.RUnlock()
 	return sn.attributes[key]
 }
 
+// Replace the attributes of the node with the attributes sent by the RM in a node update.
+// The fast access fields are not changed: the host, rack and partition of a node are fixed.
+func (sn *Node) SetAttributes(newAttributes map[string]string) {
+	sn.Lock()
+	defer sn.Unlock()
+	attributes := make(map[string]string, len(newAttributes))
+	for key, value := range newAttributes {
+		attributes[key] = value
+	}
+	sn.attributes = attributes
+	sn.taints = parseTaints(attributes[NodeTaintsAttribute])
+	sn.nodeChanged()
+}
+
+// Replace the taints of the node. Taints set via this call are replaced by the taints from the
+// attributes on the next node update from the RM that changes the attributes.
+func (sn *Node) SetTaints(taints map[string]string) {
+	sn.Lock()
+	defer sn.Unlock()
+	newTaints := make(map[string]string, len(taints))
+	for key, value := range taints {
+		if key == "" {
+			continue
+		}
+		newTaints[key] = value
+	}
+	sn.taints = newTaints
+	sn.nodeChanged()
+}
+
+// Return a copy of the taints of the node.
+func (sn *Node) GetTaints() map[string]string {
+	sn.RLock()
+	defer sn.RUnlock()
+	taints := make(map[string]string, len(sn.taints))
+	for key, value := range sn.taints {
+		taints[key] = value
+	}
+	return taints
+}
+
+// Check if all taints on the node are tolerated by either the ask or the queue tolerations.
+// A toleration with an empty value tolerates the taint independent of the taint value.
+func (sn *Node) IsTolerated(askTolerations, queueTolerations map[string]string) bool {
+	sn.RLock()
+	defer sn.RUnlock()
+	for key, value := range sn.taints {
+		if !tolerates(askTolerations, key, value) && !tolerates(queueTolerations, key, value) {
+			return false
+		}
+	}
+	return true
+}
+
+// Check if the node has all the required attributes with the required value.
+// An empty required value matches any value as long as the attribute is set on the node.
+func (sn *Node) MatchAttributes(required map[string]string) bool {
+	if len(required) == 0 {
+		return true
+	}
+	sn.RLock()
+	defer sn.RUnlock()
+	for key, value := range required {
+		nodeValue, ok := sn.attributes[key]
+		if !ok || (value != "" && nodeValue != value) {
+			return false
+		}
+	}
+	return true
+}
+
 // Return an array of all reservation keys for the node.
 // This will return an empty array if there are no reservations.
 // Visible for tests
@@ -121,6 +215,23 @@ func (sn *Node) GetReservations() []string {
 	return keys
 }
 
+// Return the details of all reservations on the node ordered by reservation key.
+// This will return an empty array if there are no reservations.
+func (sn *Node) GetReservationInfos() []*ReservationInfo {
+	sn.RLock()
+	defer sn.RUnlock()
+	keys := make([]string, 0, len(sn.reservations))
+	for key := range sn.reservations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	infos := make([]*ReservationInfo, 0, len(keys))
+	for _, key := range keys {
+		infos = append(infos, sn.reservations[key].getInfo())
+	}
+	return infos
+}
+
 func (sn *Node) GetCapacity() *resources.Resource {
 	sn.RLock()
 	defer sn.RUnlock()
@@ -137,6 +248,7 @@ func (sn *Node) SetCapacity(newCapacity *resources.Resource) *resources.Resource
 	delta := resources.Sub(newCapacity, sn.totalResource)
 	sn.totalResource = newCapacity
 	sn.refreshAvailableResource()
+	sn.nodeChanged()
 	return delta
 }
 
@@ -155,6 +267,7 @@ func (sn *Node) SetOccupiedResource(occupiedResource *resources.Resource) {
 	}
 	sn.occupiedResource = occupiedResource
 	sn.refreshAvailableResource()
+	sn.nodeChanged()
 }
 
 // refresh node available resource based on the latest total, allocated and occupied resources.
@@ -202,6 +315,34 @@ func (sn *Node) SetSchedulable(schedulable bool) {
 	sn.Lock()
 	defer sn.Unlock()
 	sn.schedulable = schedulable
+	// a schedulable node is not draining anymore
+	if schedulable {
+		sn.drainStart = time.Time{}
+		sn.drainAllocations = 0
+	}
+	sn.nodeChanged()
+}
+
+// Start draining the node: the node is set to unschedulable and the number of allocations on the node is tracked
+// to report the progress of the drain. Starting a drain on a draining node does not change the drain.
+func (sn *Node) StartDrain() {
+	sn.Lock()
+	defer sn.Unlock()
+	if !sn.drainStart.IsZero() {
+		return
+	}
+	sn.schedulable = false
+	sn.drainStart = time.Now()
+	sn.drainAllocations = len(sn.allocations)
+	sn.nodeChanged()
+}
+
+// Get the progress of the drain of the node: the start of the drain, the number of allocations on the node when the
+// drain started and the number of allocations left. The start time is zero if the node is not draining.
+func (sn *Node) GetDrainProgress() (time.Time, int, int) {
+	sn.RLock()
+	defer sn.RUnlock()
+	return sn.drainStart, sn.drainAllocations, len(sn.allocations)
 }
 
 // Can this node be used in scheduling.
@@ -232,11 +373,11 @@ func (sn *Node) FitInNode(resRequest *resources.Resource) bool {
 }
 
 // Get the number of resource tagged for preemption on this node
-func (sn *Node) getPreemptingResource() *resources.Resource {
+func (sn *Node) GetPreemptingResource() *resources.Resource {
 	sn.RLock()
 	defer sn.RUnlock()
 
-	return sn.preempting
+	return sn.preempting.Clone()
 }
 
 // Update the number of resource tagged for preemption on this node
@@ -272,6 +413,7 @@ func (sn *Node) RemoveAllocation(uuid string) *Allocation {
 		delete(sn.allocations, uuid)
 		sn.allocatedResource.SubFrom(alloc.AllocatedResource)
 		sn.availableResource.AddTo(alloc.AllocatedResource)
+		sn.nodeChanged()
 		return alloc
 	}
 
@@ -293,6 +435,7 @@ func (sn *Node) AddAllocation(alloc *Allocation) bool {
 		sn.allocations[alloc.UUID] = alloc
 		sn.allocatedResource.AddTo(res)
 		sn.availableResource.SubFrom(res)
+		sn.nodeChanged()
 		return true
 	}
 	return false
@@ -306,6 +449,7 @@ func (sn *Node) ReplaceAllocation(uuid string, replace *Allocation) {
 
 	delete(sn.allocations, uuid)
 	sn.allocations[replace.UUID] = replace
+	sn.nodeChanged()
 }
 
 // Check if the proposed allocation fits in the available resources.
@@ -318,30 +462,41 @@ func (sn *Node) CanAllocate(res *resources.Resource, preemptionPhase bool) bool
 }
 
 // Checking pre-conditions in the shim for an allocation.
-func (sn *Node) preAllocateConditions(allocID string) bool {
-	return sn.preConditions(allocID, true)
+func (sn *Node) preAllocateConditions(ask *AllocationAsk) bool {
+	return sn.preConditions(ask, true)
 }
 
 // Checking pre-conditions in the shim for a reservation.
-func (sn *Node) preReserveConditions(allocID string) bool {
-	return sn.preConditions(allocID, false)
+func (sn *Node) preReserveConditions(ask *AllocationAsk) bool {
+	return sn.preConditions(ask, false)
 }
 
 // The pre conditions are implemented via plugins in the shim. If no plugins are implemented then
 // the check will return true. If multiple plugins are implemented the first failure will stop the
 // checks.
 // The caller must thus not rely on all plugins being executed.
+// The result of the plugin call is cached for the ask until the node or the ask generation changes or the result
+// expires.
 // This is a lock free call as it does not change the node and multiple predicate checks could be
 // run at the same time.
-func (sn *Node) preConditions(allocID string, allocate bool) bool {
+func (sn *Node) preConditions(ask *AllocationAsk, allocate bool) bool {
 	// Check the predicates plugin (k8shim)
 	if plugin := plugins.GetPredicatesPlugin(); plugin != nil {
+		allocID := ask.AllocationKey
+		cacheKey := predicateKey(ask.ApplicationID, allocID, allocate)
+		generation := sn.getGeneration()
+		askGeneration := ask.getGeneration()
+		if passed, ok := sn.getCachedPredicate(cacheKey, generation, askGeneration); ok {
+			return passed
+		}
 		// checking predicates
-		if err := plugin.Predicates(&si.PredicatesArgs{
+		err := plugin.Predicates(&si.PredicatesArgs{
 			AllocationKey: allocID,
 			NodeID:        sn.NodeID,
 			Allocate:      allocate,
-		}); err != nil {
+		})
+		sn.setCachedPredicate(cacheKey, generation, askGeneration, err == nil)
+		if err != nil {
 			log.Logger().Debug("running predicates failed",
 				zap.String("allocationKey", allocID),
 				zap.String("nodeID", sn.NodeID),
@@ -355,6 +510,62 @@ func (sn *Node) preConditions(allocID string, allocate bool) bool {
 	return true
 }
 
+// Generate the key for the predicate cache: the allocation key is only unique within the application.
+// Allocate and reserve checks are cached separately.
+func predicateKey(appID, allocID string, allocate bool) string {
+	if allocate {
+		return appID + "|" + allocID + "|allocate"
+	}
+	return appID + "|" + allocID + "|reserve"
+}
+
+// Return the cached predicate result for the key if it was stored for the passed in node and ask generation and
+// has not expired.
+func (sn *Node) getCachedPredicate(key string, generation, askGeneration uint64) (bool, bool) {
+	sn.RLock()
+	defer sn.RUnlock()
+	result, ok := sn.predicates[key]
+	if !ok || result.generation != generation || result.askGeneration != askGeneration || time.Now().After(result.expires) {
+		return false, false
+	}
+	return result.passed, true
+}
+
+// Store the predicate result for the key. The result is dropped if the node changed while the
+// predicates were running: the result could be based on the old node state.
+func (sn *Node) setCachedPredicate(key string, generation, askGeneration uint64, passed bool) {
+	sn.Lock()
+	defer sn.Unlock()
+	if sn.generation != generation {
+		return
+	}
+	if sn.predicates == nil {
+		sn.predicates = make(map[string]predicateResult)
+	}
+	sn.predicates[key] = predicateResult{
+		generation:    generation,
+		askGeneration: askGeneration,
+		expires:       time.Now().Add(predicateCacheTTL),
+		passed:        passed,
+	}
+}
+
+// Return the current generation of the node.
+func (sn *Node) getGeneration() uint64 {
+	sn.RLock()
+	defer sn.RUnlock()
+	return sn.generation
+}
+
+// Bump the generation of the node and drop all cached predicate results.
+// NOTE: this is a lock free call. It should only be called holding the Node lock.
+func (sn *Node) nodeChanged() {
+	sn.generation++
+	if len(sn.predicates) > 0 {
+		sn.predicates = make(map[string]predicateResult)
+	}
+}
+
 // Check if the node should be considered as a possible node to allocate on.
 //
 // This is a lock free call. No updates are made this only performs a pre allocate checks
@@ -384,7 +595,7 @@ func (sn *Node) preAllocateCheck(res *resources.Resource, resKey string, preempt
 	// check if resources are available
 	available := sn.GetAvailableResource()
 	if preemptionPhase {
-		available.AddTo(sn.getPreemptingResource())
+		available.AddTo(sn.GetPreemptingResource())
 	}
 	// check the request fits in what we have calculated
 	if !resources.FitIn(available, res) {
@@ -421,6 +632,24 @@ func (sn *Node) isReservedForApp(key string) bool {
 	return false
 }
 
+// Return the reservation on the node with the lowest ask priority that is not higher than the priority passed in.
+// Returns nil if the node is not reserved or all reservations have a higher priority.
+func (sn *Node) getReservationToSteal(maxPriority int32) *reservation {
+	sn.RLock()
+	defer sn.RUnlock()
+	var lowest *reservation
+	for _, res := range sn.reservations {
+		priority := res.ask.GetPriority()
+		if priority > maxPriority {
+			continue
+		}
+		if lowest == nil || priority < lowest.ask.GetPriority() {
+			lowest = res
+		}
+	}
+	return lowest
+}
+
 // Reserve the node for this application and ask combination, if not reserved yet.
 // The reservation is checked against the node resources.
 // If the reservation fails the function returns false, if the reservation is made it returns true.
@@ -450,6 +679,7 @@ func (sn *Node) Reserve(app *Application, ask *AllocationAsk) error {
 		return fmt.Errorf("reservation does not fit on node %s, appID %s, ask %s", sn.NodeID, app.ApplicationID, ask.AllocatedResource.String())
 	}
 	sn.reservations[appReservation.getKey()] = appReservation
+	sn.nodeChanged()
 	// reservation added successfully
 	return nil
 }
@@ -470,6 +700,7 @@ func (sn *Node) unReserve(app *Application, ask *AllocationAsk) (int, error) {
 	}
 	if _, ok := sn.reservations[resKey]; ok {
 		delete(sn.reservations, resKey)
+		sn.nodeChanged()
 		return 1, nil
 	}
 	// reservation was not found
@@ -19,12 +19,16 @@
 package objects
 
 import (
+	"fmt"
 	"testing"
+	"time"
 
 	"gotest.tools/assert"
 
 	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+	"github.com/apache/incubator-yunikorn-core/pkg/plugins"
 	"github.com/apache/incubator-yunikorn-scheduler-interface/lib/go/common"
+	"github.com/apache/incubator-yunikorn-scheduler-interface/lib/go/si"
 )
 
 const testNode = "testnode"
@@ -96,13 +100,83 @@ func TestCheckConditions(t *testing.T) {
 	}
 
 	// Check if we can allocate on scheduling node (no plugins)
-	if !node.preAllocateConditions("test") {
+	if !node.preAllocateConditions(newAllocationAsk("test", appID1, resources.NewResource())) {
 		t.Error("node with scheduling set to true no plugins should allow allocation")
 	}
 
 	// TODO add mock for plugin to extend tests
 }
 
+// predicate plugin that counts the calls and fails when requested
+type countingPredicatePlugin struct {
+	calls    int
+	mustFail bool
+}
+
+func (c *countingPredicatePlugin) Predicates(args *si.PredicatesArgs) error {
+	c.calls++
+	if c.mustFail {
+		return fmt.Errorf("counting predicate plugin failed")
+	}
+	return nil
+}
+
+func TestPredicateCache(t *testing.T) {
+	plugin := &countingPredicatePlugin{mustFail: true}
+	plugins.RegisterSchedulerPlugin(plugin)
+	defer plugins.UnregisterPredicatesPlugin()
+
+	node := newNode(nodeID1, map[string]resources.Quantity{"first": 100})
+	askRes := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})
+	ask1 := newAllocationAsk("ask-1", appID1, askRes)
+	ask2 := newAllocationAsk("ask-2", appID1, askRes)
+	assert.Assert(t, !node.preAllocateConditions(ask1), "predicate should have failed")
+	assert.Equal(t, plugin.calls, 1, "plugin not called for first check")
+	assert.Assert(t, !node.preAllocateConditions(ask1), "cached predicate result should have failed")
+	assert.Equal(t, plugin.calls, 1, "plugin called while result was cached")
+	// reserve and allocate are cached separately as are other asks
+	assert.Assert(t, !node.preReserveConditions(ask1), "predicate should have failed")
+	assert.Assert(t, !node.preAllocateConditions(ask2), "predicate should have failed")
+	assert.Equal(t, plugin.calls, 3, "plugin not called for different ask or reserve")
+
+	// change the node: generation must change and cache must be dropped
+	generation := node.getGeneration()
+	plugin.mustFail = false
+	node.SetOccupiedResource(resources.NewResourceFromMap(map[string]resources.Quantity{"first": 10}))
+	assert.Assert(t, node.getGeneration() > generation, "generation not changed on node update")
+	assert.Assert(t, node.preAllocateConditions(ask1), "predicate should have passed after node change")
+	assert.Equal(t, plugin.calls, 4, "plugin not called after node change")
+
+	// an allocation changes the node
+	alloc := newAllocation(appID1, "uuid-1", nodeID1, "root.default", resources.NewResourceFromMap(map[string]resources.Quantity{"first": 10}))
+	assert.Assert(t, node.AddAllocation(alloc), "allocation should have been added")
+	assert.Assert(t, node.preAllocateConditions(ask1), "predicate should have passed")
+	assert.Equal(t, plugin.calls, 5, "plugin not called after allocation added")
+	assert.Assert(t, node.preAllocateConditions(ask1), "predicate should have passed")
+	assert.Equal(t, plugin.calls, 5, "plugin called while result was cached")
+
+	// an update of the ask drops the results cached for the ask
+	ask1.updateFrom(newAllocationAsk("ask-1", appID1, askRes))
+	assert.Assert(t, node.preAllocateConditions(ask1), "predicate should have passed")
+	assert.Equal(t, plugin.calls, 6, "plugin not called after ask update")
+	assert.Assert(t, node.preAllocateConditions(ask1), "predicate should have passed")
+	assert.Equal(t, plugin.calls, 6, "plugin called while result was cached")
+
+	// the same allocation key in a different application is a different ask
+	assert.Assert(t, node.preAllocateConditions(newAllocationAsk("ask-1", appID2, askRes)), "predicate should have passed")
+	assert.Equal(t, plugin.calls, 7, "plugin not called for ask of other application")
+
+	// an expired result is not used
+	ttl := predicateCacheTTL
+	defer func() { predicateCacheTTL = ttl }()
+	predicateCacheTTL = -time.Second
+	ask3 := newAllocationAsk("ask-3", appID1, askRes)
+	assert.Assert(t, node.preAllocateConditions(ask3), "predicate should have passed")
+	assert.Equal(t, plugin.calls, 8, "plugin not called for first check")
+	assert.Assert(t, node.preAllocateConditions(ask3), "predicate should have passed")
+	assert.Equal(t, plugin.calls, 9, "plugin not called for expired result")
+}
+
 func TestPreAllocateCheck(t *testing.T) {
 	nodeID := nodeID1
 	resNode := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 10, "second": 1})
@@ -210,25 +284,25 @@ func TestPreemptingResources(t *testing.T) {
 	node.IncPreemptingResource(preemptRes)
 	node.IncPreemptingResource(preemptRes)
 	expect := resources.Multiply(preemptRes, 2)
-	nodePreempt := node.getPreemptingResource()
+	nodePreempt := node.GetPreemptingResource()
 	if !resources.Equals(nodePreempt, expect) {
 		t.Errorf("preempting resources not set, expected %v got %v", expect, nodePreempt)
 	}
 	// release one preemption
 	node.decPreemptingResource(preemptRes)
-	nodePreempt = node.getPreemptingResource()
+	nodePreempt = node.GetPreemptingResource()
 	if !resources.Equals(nodePreempt, preemptRes) {
 		t.Errorf("preempting resources not decremented, expected %v got %v", preemptRes, nodePreempt)
 	}
 	// release preemption: should be back to zero
 	node.decPreemptingResource(preemptRes)
-	nodePreempt = node.getPreemptingResource()
+	nodePreempt = node.GetPreemptingResource()
 	if !resources.IsZero(nodePreempt) {
 		t.Errorf("preempting resources not zero but %v", nodePreempt)
 	}
 	// release preemption again: should be zero
 	node.decPreemptingResource(preemptRes)
-	nodePreempt = node.getPreemptingResource()
+	nodePreempt = node.GetPreemptingResource()
 	if !resources.IsZero(nodePreempt) {
 		t.Errorf("preempting resources not zero but %v", nodePreempt)
 	}
@@ -280,6 +354,12 @@ func TestNodeReservation(t *testing.T) {
 	if node.IsReserved() && !node.isReservedForApp(appID1) {
 		t.Errorf("node should have reservations for app-1")
 	}
+	infos := node.GetReservationInfos()
+	assert.Equal(t, len(infos), 1, "expected one reservation info")
+	assert.Equal(t, infos[0].ApplicationID, appID1, "unexpected application in reservation info")
+	assert.Equal(t, infos[0].AllocationKey, aKey, "unexpected ask in reservation info")
+	assert.Equal(t, infos[0].NodeID, nodeID1, "unexpected node in reservation info")
+	assert.Assert(t, resources.Equals(infos[0].Reserved, res), "unexpected reserved resource in reservation info")
 
 	// 2nd reservation on node
 	err = node.Reserve(nil, nil)
@@ -404,6 +484,53 @@ func TestAttributes(t *testing.T) {
 	assert.Equal(t, "just a text", value, "node attributes not set, expected 'just a text' got '%v'", value)
 }
 
+func TestMatchAttributes(t *testing.T) {
+	proto := newProto(testNode, nil, nil, map[string]string{
+		common.NodePartition: "partition1",
+		"zone":               "zone-a",
+	})
+	node := NewNode(proto)
+	assert.Assert(t, node.MatchAttributes(nil), "nil required attributes should match")
+	assert.Assert(t, node.MatchAttributes(map[string]string{"zone": "zone-a"}), "attribute value should match")
+	assert.Assert(t, node.MatchAttributes(map[string]string{"zone": ""}), "attribute without value should match")
+	assert.Assert(t, !node.MatchAttributes(map[string]string{"zone": "zone-b"}), "attribute value should not match")
+	assert.Assert(t, !node.MatchAttributes(map[string]string{"instance-type": ""}), "missing attribute should not match")
+
+	// update replaces all attributes but not the fast access fields
+	generation := node.getGeneration()
+	node.SetAttributes(map[string]string{"zone": "zone-b"})
+	assert.Assert(t, node.getGeneration() > generation, "attribute update should change the node generation")
+	assert.Assert(t, node.MatchAttributes(map[string]string{"zone": "zone-b"}), "updated attribute value should match")
+	assert.Equal(t, node.GetAttribute(common.NodePartition), "", "attributes should have been replaced")
+	assert.Equal(t, node.Partition, "partition1", "partition should not have changed")
+}
+
+func TestIsTolerated(t *testing.T) {
+	proto := newProto(testNode, nil, nil, map[string]string{
+		NodeTaintsAttribute: "dedicated=gpu, maintenance",
+	})
+	node := NewNode(proto)
+	assert.Equal(t, len(node.GetTaints()), 2, "taints not set from the attributes")
+	assert.Assert(t, !node.IsTolerated(nil, nil), "tainted node should not be tolerated without tolerations")
+	assert.Assert(t, !node.IsTolerated(map[string]string{"dedicated": "gpu"}, nil), "all taints must be tolerated")
+	assert.Assert(t, !node.IsTolerated(map[string]string{"dedicated": "batch", "maintenance": ""}, nil), "taint value should not be tolerated")
+	assert.Assert(t, node.IsTolerated(map[string]string{"dedicated": "gpu", "maintenance": ""}, nil), "ask should tolerate all taints")
+	assert.Assert(t, node.IsTolerated(map[string]string{"dedicated": ""}, map[string]string{"maintenance": ""}), "ask and queue should tolerate all taints")
+
+	// taints set directly replace the taints and change the generation
+	generation := node.getGeneration()
+	node.SetTaints(map[string]string{"maintenance": "", "": "skipped"})
+	assert.Assert(t, node.getGeneration() > generation, "taint update should change the node generation")
+	assert.DeepEqual(t, node.GetTaints(), map[string]string{"maintenance": ""})
+	assert.Assert(t, !node.IsTolerated(nil, map[string]string{"maintenance": "any"}), "taint without value is only tolerated by a toleration without value")
+	assert.Assert(t, node.IsTolerated(nil, map[string]string{"maintenance": ""}), "queue should tolerate the taint")
+
+	// attribute update replaces the taints
+	node.SetAttributes(map[string]string{})
+	assert.Equal(t, len(node.GetTaints()), 0, "taints should have been removed by the attribute update")
+	assert.Assert(t, node.IsTolerated(nil, nil), "untainted node should always be tolerated")
+}
+
 func TestAddAllocation(t *testing.T) {
 	node := newNode("node-123", map[string]resources.Quantity{"first": 100, "second": 200})
 	if !resources.IsZero(node.GetAllocatedResource()) {
@@ -541,6 +668,35 @@ func TestSchedulingState(t *testing.T) {
 	}
 }
 
+func TestNodeDrain(t *testing.T) {
+	node := newNode("node-123", nil)
+	node.AddAllocation(newAllocation(appID1, "1", "node-123", "queue-1", nil))
+	start, _, _ := node.GetDrainProgress()
+	assert.Assert(t, start.IsZero(), "new node should not be draining")
+
+	node.StartDrain()
+	assert.Assert(t, !node.IsSchedulable(), "draining node should not be schedulable")
+	start, initial, remaining := node.GetDrainProgress()
+	assert.Assert(t, !start.IsZero(), "drain start should have been set")
+	assert.Equal(t, initial, 1, "unexpected initial allocations")
+	assert.Equal(t, remaining, 1, "unexpected remaining allocations")
+
+	// a second drain does not restart the drain
+	node.AddAllocation(newAllocation(appID1, "2", "node-123", "queue-1", nil))
+	node.StartDrain()
+	var restart time.Time
+	restart, initial, remaining = node.GetDrainProgress()
+	assert.Equal(t, restart, start, "drain should not have been restarted")
+	assert.Equal(t, initial, 1, "initial allocations should not change")
+	assert.Equal(t, remaining, 2, "unexpected remaining allocations")
+
+	// schedulable stops the drain
+	node.SetSchedulable(true)
+	start, initial, _ = node.GetDrainProgress()
+	assert.Assert(t, start.IsZero(), "schedulable node should not be draining")
+	assert.Equal(t, initial, 0, "initial allocations should have been reset")
+}
+
 func TestUpdateResources(t *testing.T) {
 	total := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 10, "second": 10})
 	node := newNodeRes("node-123", total)
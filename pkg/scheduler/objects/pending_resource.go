@@ -0,0 +1,140 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objects
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+)
+
+// The pending resources of a queue.
+// Asks are added and removed at a high rate, updating the pending resources of all the parent queues under
+// their locks for each change causes contention. The pending resources are therefore tracked in two parts:
+// - the pending resources of the applications in the queue itself, kept as atomic counters per resource type.
+// - a cached total that includes the queues below this queue, recalculated on read after a change.
+// A change only updates the counters of the queue and bumps the version of the queue and its parents, no locks
+// are taken. The totals are aggregated lazily when the pending resources are requested.
+type pendingResource struct {
+	// version is first to guarantee 64-bit alignment for the atomic operations
+	version      int64               // updated on each change in this queue or the queues below it
+	cacheVersion int64               // version the cached total was calculated for
+	cache        *resources.Resource // cached total of this queue and the queues below it
+	quantities   sync.Map            // resource type to *int64, pending resources of the apps in this queue
+
+	sync.Mutex // protects the cache
+}
+
+func newPendingResource() *pendingResource {
+	return &pendingResource{
+		cacheVersion: -1,
+	}
+}
+
+// Return the counter for the resource type, creating it if it does not exist.
+func (pr *pendingResource) counter(name string) *int64 {
+	if value, ok := pr.quantities.Load(name); ok {
+		return value.(*int64)
+	}
+	value, _ := pr.quantities.LoadOrStore(name, new(int64))
+	return value.(*int64)
+}
+
+// Add the delta to the pending resources of the queue.
+func (pr *pendingResource) add(delta *resources.Resource) {
+	if delta == nil {
+		return
+	}
+	for name, quantity := range delta.Resources {
+		atomic.AddInt64(pr.counter(name), int64(quantity))
+	}
+}
+
+// Remove the delta from the pending resources of the queue.
+// The pending resources are not allowed to go negative, negative quantities are reset to zero and an error
+// is returned listing the affected resource types.
+func (pr *pendingResource) sub(delta *resources.Resource) error {
+	if delta == nil {
+		return nil
+	}
+	var negative []string
+	for name, quantity := range delta.Resources {
+		counter := pr.counter(name)
+		if atomic.AddInt64(counter, -int64(quantity)) >= 0 {
+			continue
+		}
+		negative = append(negative, name)
+		// reset to zero, unless a concurrent update already brought it back up
+		for {
+			current := atomic.LoadInt64(counter)
+			if current >= 0 || atomic.CompareAndSwapInt64(counter, current, 0) {
+				break
+			}
+		}
+	}
+	if len(negative) == 0 {
+		return nil
+	}
+	sort.Strings(negative)
+	return fmt.Errorf("resource quantity less than zero for: %v", negative)
+}
+
+// Return the pending resources of the apps in the queue, excluding the queues below it.
+func (pr *pendingResource) getOwn() *resources.Resource {
+	own := resources.NewResource()
+	pr.quantities.Range(func(key, value interface{}) bool {
+		own.Resources[key.(string)] = resources.Quantity(atomic.LoadInt64(value.(*int64)))
+		return true
+	})
+	return own
+}
+
+// Mark the cached total as outdated.
+func (pr *pendingResource) invalidate() {
+	atomic.AddInt64(&pr.version, 1)
+}
+
+// Return the current version of the pending resources.
+func (pr *pendingResource) getVersion() int64 {
+	return atomic.LoadInt64(&pr.version)
+}
+
+// Return the cached total if it was calculated for the version, nil otherwise.
+func (pr *pendingResource) getCached(version int64) *resources.Resource {
+	pr.Lock()
+	defer pr.Unlock()
+	if pr.cacheVersion == version {
+		return pr.cache
+	}
+	return nil
+}
+
+// Cache the total calculated for the version. An older version never replaces a newer one.
+// The cached total is shared with the callers and must not be modified after it is set.
+func (pr *pendingResource) setCached(total *resources.Resource, version int64) {
+	pr.Lock()
+	defer pr.Unlock()
+	if version >= pr.cacheVersion {
+		pr.cache = total
+		pr.cacheVersion = version
+	}
+}
@@ -0,0 +1,129 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objects
+
+import (
+	"sync"
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+)
+
+func TestPendingResourceAddSub(t *testing.T) {
+	pr := newPendingResource()
+	assert.Assert(t, resources.IsZero(pr.getOwn()), "new pending resource should be zero")
+	pr.add(nil)
+	assert.NilError(t, pr.sub(nil), "nil delta should not fail")
+
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 10, "vcore": 1})
+	pr.add(res)
+	pr.add(res)
+	assert.Assert(t, resources.Equals(pr.getOwn(), resources.Multiply(res, 2)), "unexpected pending after add")
+	assert.NilError(t, pr.sub(res), "sub should not fail")
+	assert.Assert(t, resources.Equals(pr.getOwn(), res), "unexpected pending after sub")
+
+	// negative quantities are reset to zero
+	err := pr.sub(resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 20, "vcore": 1}))
+	assert.ErrorContains(t, err, "resource quantity less than zero for: [memory]")
+	assert.Assert(t, resources.IsZero(pr.getOwn()), "pending should be zero after going negative")
+}
+
+func TestPendingResourceCache(t *testing.T) {
+	pr := newPendingResource()
+	version := pr.getVersion()
+	assert.Assert(t, pr.getCached(version) == nil, "new pending resource should not have a cached total")
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 10})
+	pr.setCached(res, version)
+	assert.Equal(t, pr.getCached(version), res, "cached total not returned")
+
+	pr.invalidate()
+	assert.Assert(t, pr.getCached(pr.getVersion()) == nil, "cached total should be outdated")
+	// an older version does not replace the newer one
+	newer := resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 20})
+	pr.setCached(newer, pr.getVersion())
+	pr.setCached(res, version)
+	assert.Equal(t, pr.getCached(pr.getVersion()), newer, "cached total replaced by an older version")
+}
+
+func TestQueuePendingAggregation(t *testing.T) {
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	var parent, leaf1, leaf2 *Queue
+	parent, err = createManagedQueue(root, "parent", true, nil)
+	assert.NilError(t, err, "failed to create parent queue")
+	leaf1, err = createManagedQueue(parent, "leaf1", false, nil)
+	assert.NilError(t, err, "failed to create leaf queue")
+	leaf2, err = createManagedQueue(root, "leaf2", false, nil)
+	assert.NilError(t, err, "failed to create leaf queue")
+
+	// concurrent updates on both leafs
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 1})
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				leaf1.incPendingResource(res)
+				root.GetPendingResource()
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				leaf2.incPendingResource(res)
+				leaf2.decPendingResource(res)
+			}
+		}()
+	}
+	wg.Wait()
+	expected := resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 1000})
+	assert.Assert(t, resources.Equals(leaf1.GetPendingResource(), expected), "unexpected leaf1 pending: %v", leaf1.GetPendingResource())
+	assert.Assert(t, resources.IsZero(leaf2.GetPendingResource()), "unexpected leaf2 pending: %v", leaf2.GetPendingResource())
+	assert.Assert(t, resources.Equals(parent.GetPendingResource(), expected), "unexpected parent pending: %v", parent.GetPendingResource())
+	assert.Assert(t, resources.Equals(root.GetPendingResource(), expected), "unexpected root pending: %v", root.GetPendingResource())
+	// without changes the cached total is returned
+	assert.Equal(t, root.GetPendingResource(), root.GetPendingResource(), "cached total not reused")
+
+	// moving the leaf moves the pending resources with it
+	_, err = leaf1.MoveQueue(root, "moved")
+	assert.NilError(t, err, "failed to move queue")
+	assert.Assert(t, resources.IsZero(parent.GetPendingResource()), "unexpected parent pending after move: %v", parent.GetPendingResource())
+	assert.Assert(t, resources.Equals(root.GetPendingResource(), expected), "unexpected root pending after move: %v", root.GetPendingResource())
+
+	// pending updates while the queue moves: the parent is read under the queue lock
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for j := 0; j < 100; j++ {
+			leaf1.incPendingResource(res)
+			leaf1.decPendingResource(res)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		_, err := leaf1.MoveQueue(parent, "moved-back")
+		assert.NilError(t, err, "failed to move queue back")
+	}()
+	wg.Wait()
+	assert.Assert(t, resources.Equals(parent.GetPendingResource(), expected), "unexpected parent pending after move back: %v", parent.GetPendingResource())
+	assert.Assert(t, resources.Equals(root.GetPendingResource(), expected), "unexpected root pending after move back: %v", root.GetPendingResource())
+}
@@ -0,0 +1,200 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objects
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/configs"
+	"github.com/apache/incubator-yunikorn-core/pkg/events"
+	"github.com/apache/incubator-yunikorn-core/pkg/log"
+	"github.com/apache/incubator-yunikorn-core/pkg/metrics/history"
+)
+
+// The window the placement attempts are tracked over: 12 buckets of 5 seconds
+const (
+	placementBucketSize = 5 * time.Second
+	placementBuckets    = 12
+	placementWindow     = placementBucketSize * placementBuckets
+)
+
+// The minimum number of attempts in the window before the failure ratio is checked
+const placementMinAttempts = 10
+
+// The time a queue is backed off if no back off is configured
+const defaultPlacementBackoff = 30 * time.Second
+
+// The error budget for the placement attempts of a leaf queue.
+// When the ratio of failed attempts in the window exceeds the threshold the queue is backed off:
+// it is skipped by the allocation attempts, and sorted after its siblings for the other scheduling steps, until
+// the back off expires. This prevents a queue with asks that can never be placed from taking up most of the
+// scheduling cycles.
+type placementBudget struct {
+	threshold    float64
+	backoff      time.Duration
+	failures     *history.WindowCounter
+	successes    *history.WindowCounter
+	backoffUntil time.Time
+
+	sync.RWMutex
+}
+
+func newPlacementBudget(threshold float64, backoff time.Duration) *placementBudget {
+	return &placementBudget{
+		threshold: threshold,
+		backoff:   backoff,
+		failures:  history.NewWindowCounter(placementBucketSize, placementBuckets),
+		successes: history.NewWindowCounter(placementBucketSize, placementBuckets),
+	}
+}
+
+// Update the threshold and back off, the tracked attempts are kept.
+func (pb *placementBudget) update(threshold float64, backoff time.Duration) {
+	pb.Lock()
+	defer pb.Unlock()
+	pb.threshold = threshold
+	pb.backoff = backoff
+}
+
+// Record a placement attempt for the queue.
+// Returns the back off if the attempt started a new back off for the queue, zero otherwise.
+func (pb *placementBudget) record(success bool) time.Duration {
+	if success {
+		pb.successes.Inc()
+		return 0
+	}
+	pb.failures.Inc()
+	failed := pb.failures.Count(placementWindow)
+	total := failed + pb.successes.Count(placementWindow)
+	pb.Lock()
+	defer pb.Unlock()
+	if total < placementMinAttempts || float64(failed)/float64(total) <= pb.threshold {
+		return 0
+	}
+	now := time.Now()
+	if now.Before(pb.backoffUntil) {
+		return 0
+	}
+	pb.backoffUntil = now.Add(pb.backoff)
+	return pb.backoff
+}
+
+// Return the ratio of the failed placement attempts in the window, zero if there were no attempts.
+func (pb *placementBudget) getFailureRatio() float64 {
+	failed := pb.failures.Count(placementWindow)
+	total := failed + pb.successes.Count(placementWindow)
+	if total == 0 {
+		return 0
+	}
+	return float64(failed) / float64(total)
+}
+
+// Return true if the queue is backed off.
+func (pb *placementBudget) isBackedOff() bool {
+	pb.RLock()
+	defer pb.RUnlock()
+	return time.Now().Before(pb.backoffUntil)
+}
+
+// Set the placement error budget of the queue based on the threshold and back off properties.
+// An unset or invalid threshold removes the budget.
+// NOTE: this is a lock free call. It must only be called holding the queue lock.
+func (sq *Queue) setPlacementBudget(thresholdValue, backoffValue string) {
+	threshold, backoff, err := parsePlacementBudget(thresholdValue, backoffValue)
+	if err != nil {
+		log.Logger().Debug("placement failure property configuration error",
+			zap.String("queue", sq.QueuePath),
+			zap.Error(err))
+	}
+	if threshold == 0 {
+		sq.placementBudget = nil
+		return
+	}
+	if sq.placementBudget == nil {
+		sq.placementBudget = newPlacementBudget(threshold, backoff)
+		return
+	}
+	sq.placementBudget.update(threshold, backoff)
+}
+
+// Parse the threshold and back off properties: a zero threshold means no budget.
+func parsePlacementBudget(thresholdValue, backoffValue string) (float64, time.Duration, error) {
+	if thresholdValue == "" {
+		return 0, 0, nil
+	}
+	threshold, err := configs.ParsePlacementFailureThreshold(thresholdValue)
+	if err != nil {
+		return 0, 0, err
+	}
+	backoff := defaultPlacementBackoff
+	if backoffValue != "" {
+		var value time.Duration
+		value, err = configs.ParsePlacementFailureBackoff(backoffValue)
+		if err != nil {
+			return threshold, backoff, fmt.Errorf("%v, using default", err)
+		}
+		backoff = value
+	}
+	return threshold, backoff, nil
+}
+
+// Return the placement error budget of the queue, nil if not configured.
+func (sq *Queue) getPlacementBudget() *placementBudget {
+	sq.RLock()
+	defer sq.RUnlock()
+	return sq.placementBudget
+}
+
+// Record the result of a placement attempt on the leaf queue.
+// A diagnostic event is sent when the attempt triggers a back off of the queue.
+// Lock free call this all locks are taken when needed in called functions
+func (sq *Queue) recordPlacement(success bool) {
+	budget := sq.getPlacementBudget()
+	if budget == nil {
+		return
+	}
+	backoff := budget.record(success)
+	if backoff == 0 {
+		return
+	}
+	message := fmt.Sprintf("Queue %s failed %.0f%% of the placement attempts in the last %s, sorting it last for %s",
+		sq.QueuePath, budget.getFailureRatio()*100, placementWindow, backoff)
+	log.Logger().Info("queue placement failure threshold exceeded",
+		zap.String("queue", sq.QueuePath),
+		zap.Duration("backoff", backoff))
+	if eventCache := events.GetEventCache(); eventCache != nil {
+		if event, err := events.CreateQueueEventRecord(sq.QueuePath, "", "PlacementFailureBackoff", message); err != nil {
+			log.Logger().Warn("Event creation failed",
+				zap.String("event message", message),
+				zap.Error(err))
+		} else {
+			eventCache.AddEvent(event)
+		}
+	}
+}
+
+// Return true if the queue is backed off due to its placement failures.
+func (sq *Queue) IsPlacementBackedOff() bool {
+	budget := sq.getPlacementBudget()
+	return budget != nil && budget.isBackedOff()
+}
@@ -0,0 +1,111 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objects
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/configs"
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+	"github.com/apache/incubator-yunikorn-core/pkg/interfaces"
+)
+
+func TestParsePlacementBudget(t *testing.T) {
+	threshold, _, err := parsePlacementBudget("", "")
+	assert.NilError(t, err, "unset threshold should not fail")
+	assert.Equal(t, threshold, 0.0, "unset threshold should disable the budget")
+	threshold, backoff, err := parsePlacementBudget("0.5", "")
+	assert.NilError(t, err, "valid threshold should not fail")
+	assert.Equal(t, threshold, 0.5)
+	assert.Equal(t, backoff, defaultPlacementBackoff, "default back off expected")
+	_, backoff, err = parsePlacementBudget("0.5", "10s")
+	assert.NilError(t, err, "valid back off should not fail")
+	assert.Equal(t, backoff, 10*time.Second)
+	threshold, _, err = parsePlacementBudget("1.5", "")
+	assert.ErrorContains(t, err, "invalid placement failure threshold")
+	assert.Equal(t, threshold, 0.0, "invalid threshold should disable the budget")
+	threshold, _, err = parsePlacementBudget("NaN", "")
+	assert.ErrorContains(t, err, "invalid placement failure threshold")
+	assert.Equal(t, threshold, 0.0, "NaN threshold should disable the budget")
+	_, backoff, err = parsePlacementBudget("0.5", "never")
+	assert.ErrorContains(t, err, "invalid placement failure backoff")
+	assert.Equal(t, backoff, defaultPlacementBackoff, "invalid back off should use the default")
+}
+
+func TestPlacementBudgetRecord(t *testing.T) {
+	pb := newPlacementBudget(0.8, time.Minute)
+	// below the minimum attempts nothing happens
+	for i := 0; i < placementMinAttempts-1; i++ {
+		assert.Equal(t, pb.record(false), time.Duration(0), "back off before minimum attempts")
+	}
+	assert.Assert(t, !pb.isBackedOff(), "queue should not be backed off")
+	// a success never starts a back off, the next failure does
+	pb.record(true)
+	assert.Equal(t, pb.getFailureRatio(), 0.9)
+	assert.Equal(t, pb.record(false), time.Minute, "back off should have started")
+	assert.Assert(t, pb.isBackedOff(), "queue should be backed off")
+	// a back off is not restarted while active
+	assert.Equal(t, pb.record(false), time.Duration(0), "back off should not restart")
+}
+
+func TestQueuePlacementBackoff(t *testing.T) {
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "failed to create root queue")
+	props := map[string]string{configs.PlacementFailureThreshold: "0.5", configs.PlacementFailureBackoff: "1m"}
+	var leaf1, leaf2 *Queue
+	leaf1, err = createManagedQueueWithProps(root, "leaf1", false, nil, props)
+	assert.NilError(t, err, "failed to create leaf1 queue")
+	leaf2, err = createManagedQueue(root, "leaf2", false, nil)
+	assert.NilError(t, err, "failed to create leaf2 queue")
+	assert.Assert(t, leaf1.getPlacementBudget() != nil, "leaf1 should have a placement budget")
+	assert.Assert(t, leaf2.getPlacementBudget() == nil, "leaf2 should not have a placement budget")
+
+	for i := 0; i < placementMinAttempts; i++ {
+		leaf1.recordPlacement(false)
+		leaf2.recordPlacement(false)
+	}
+	assert.Assert(t, leaf1.IsPlacementBackedOff(), "leaf1 should be backed off")
+	assert.Assert(t, !leaf2.IsPlacementBackedOff(), "leaf2 without budget should never be backed off")
+	sorted := orderSystemAndBackedOff([]*Queue{leaf1, leaf2})
+	assert.Equal(t, sorted[0], leaf2, "backed off queue should be sorted last")
+	assert.Equal(t, sorted[1], leaf1, "backed off queue should be sorted last")
+
+	// a backed off queue is not tried by the allocation attempts of its parent
+	app := newApplication(appID1, "default", "root.leaf1")
+	app.queue = leaf1
+	leaf1.AddApplication(app)
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})
+	err = app.AddAllocationAsk(newAllocationAsk(aKey, appID1, res))
+	assert.NilError(t, err, "failed to add ask")
+	failures := leaf1.getPlacementBudget().failures.Count(placementWindow)
+	alloc := root.TryAllocate(func() interfaces.NodeIterator { return nil })
+	assert.Assert(t, alloc == nil, "unexpected allocation returned")
+	assert.Equal(t, leaf1.getPlacementBudget().failures.Count(placementWindow), failures, "backed off queue should not have been tried")
+	leafs := make([]*Queue, 0)
+	root.GetPendingLeafQueues(&leafs)
+	assert.Equal(t, len(leafs), 0, "backed off queue should not be a pending leaf")
+
+	// removing the property removes the budget and the back off
+	leaf1.properties = map[string]string{}
+	leaf1.UpdateSortType()
+	assert.Assert(t, !leaf1.IsPlacementBackedOff(), "leaf1 should not be backed off without a budget")
+}
@@ -20,6 +20,9 @@ package objects
 
 import (
 	"fmt"
+	"math"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -33,39 +36,93 @@ import (
 	"github.com/apache/incubator-yunikorn-core/pkg/interfaces"
 	"github.com/apache/incubator-yunikorn-core/pkg/log"
 	"github.com/apache/incubator-yunikorn-core/pkg/metrics"
+	"github.com/apache/incubator-yunikorn-core/pkg/plugins"
 	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/policies"
 	"github.com/apache/incubator-yunikorn-core/pkg/webservice/dao"
 )
 
 const AppTagNamespaceResourceQuota = "namespace.resourcequota"
 
+// weight of a queue that does not have a weight configured
+const defaultQueueWeight = 1.0
+
+// time a submit access check result is cached in the root queue
+const accessCacheTTL = 30 * time.Second
+
+// names of the ACLs reported in an access rule, same as the queue configuration keys
+const (
+	SubmitACLName = "submitacl"
+	AdminACLName  = "adminacl"
+)
+
+// Retention of the completed applications of a leaf queue.
+// A zero count or age means that completed applications are not removed based on that limit.
+type AppRetention struct {
+	MaxCount int
+	MaxAge   time.Duration
+	Export   bool
+}
+
+// The head application of a strict fifo queue and the last time it made progress.
+// The applications behind the head are only considered after the head has not been allocated for the timeout.
+type headOfLine struct {
+	timeout       time.Duration
+	applicationID string
+	progress      time.Time
+}
+
 // Represents Queue inside Scheduler
 type Queue struct {
 	QueuePath string // Fully qualified path for the queue
 	Name      string // Queue name as in the config etc.
 
 	// Private fields need protection
-	sortType     policies.SortPolicy     // How applications (leaf) or queues (parents) are sorted
-	children     map[string]*Queue       // Only for direct children, parent queue only
-	applications map[string]*Application // only for leaf queue
-	reservedApps map[string]int          // applications reserved within this queue, with reservation count
-	parent       *Queue                  // link back to the parent in the scheduler
-	preempting   *resources.Resource     // resource considered for preemption in the queue
-	pending      *resources.Resource     // pending resource for the apps in the queue
+	sortType        policies.SortPolicy     // How applications (leaf) or queues (parents) are sorted
+	boostTag        *appTag                 // applications with this tag are sorted first (leaf only)
+	demoteTag       *appTag                 // applications with this tag are sorted last (leaf only)
+	tolerations     map[string]string       // node taints tolerated by all asks in the queue (leaf only)
+	retention       AppRetention            // retention of the completed applications of the queue (leaf only)
+	placementBudget *placementBudget        // error budget for the placement attempts of the queue (leaf only)
+	headOfLine      headOfLine              // tracking of the head application of a strict fifo queue (leaf only)
+	maxRuntime      time.Duration           // maximum runtime of the applications in the queue, 0 is unlimited (leaf only)
+	idleReclaim     bool                    // resources of idle applications in the queue can be reclaimed (leaf only)
+	preemptionFence bool                    // only demand from inside the subtree can preempt allocations in the subtree
+	paused          bool                    // the queue and the queues below it are skipped when scheduling
+	children        map[string]*Queue       // Only for direct children, parent queue only
+	applications    map[string]*Application // only for leaf queue
+	reservedApps    map[string]int          // applications reserved within this queue, with reservation count
+	parent          *Queue                  // link back to the parent in the scheduler
+	preempting      *resources.Resource     // resource considered for preemption in the queue
+	pending         *pendingResource        // pending resource for the apps in the queue and the queues below it
 
 	// The queue properties should be treated as immutable the value is a merge of the
 	// parent properties with the config for this queue only manipulated during creation
 	// of the queue or via a queue configuration update.
 	properties         map[string]string
-	adminACL           security.ACL        // admin ACL
-	submitACL          security.ACL        // submit ACL
-	maxResource        *resources.Resource // When not set, max = nil
-	guaranteedResource *resources.Resource // When not set, Guaranteed == 0
-	allocatedResource  *resources.Resource // set based on allocation
-	isLeaf             bool                // this is a leaf queue or not (i.e. parent)
-	isManaged          bool                // queue is part of the config, not auto created
-	stateMachine       *fsm.FSM            // the state of the queue for scheduling
-	stateTime          time.Time           // last time the state was updated (needed for cleanup)
+	adminACL           security.ACL                  // admin ACL
+	submitACL          security.ACL                  // submit ACL
+	maxResource        *resources.Resource           // When not set, max = nil
+	guaranteedResource *resources.Resource           // When not set, Guaranteed == 0
+	resourceConf       configs.Resources             // configured max and guaranteed, quantities can be relative to the parent
+	resourceExpr       bool                          // the configured resources contain expressions relative to the parent
+	weight             float64                       // share of the queue relative to its siblings, defaults to 1
+	template           *template                     // applied to leaf queues created dynamically below this queue
+	accessCache        *security.AccessCache         // cached submit access results for the hierarchy (root queue only)
+	recoveryWindow     time.Duration                 // recovered applications are prioritized for this time (root queue only)
+	recoveryBoost      int32                         // priority boost for recovered applications (root queue only)
+	allocatedResource  *resources.Resource           // set based on allocation
+	priorityThreshold  int32                         // asks above this priority are limited by the priority quota
+	priorityQuota      *resources.Resource           // When not set, priority quota = nil
+	priorityAllocated  map[int32]*resources.Resource // allocated resources by ask priority
+	allowedTypes       map[string]bool               // resource types asks may request, nil allows all types
+	contentionShare    float64                       // share of the partition the queue is capped at during contention, 0 is no cap
+	contentionPending  float64                       // ratio of partition pending over partition resources that starts the contention
+	isLeaf             bool                          // this is a leaf queue or not (i.e. parent)
+	isManaged          bool                          // queue is part of the config, not auto created
+	isSystem           bool                          // queue is the system queue created by the core
+	stateMachine       *fsm.FSM                      // the state of the queue for scheduling
+	stateTime          time.Time                     // last time the state was updated (needed for cleanup)
+	lastActive         time.Time                     // last time an application was added or removed (needed for cleanup)
 
 	sync.RWMutex
 }
@@ -78,8 +135,12 @@ func newBlankQueue() *Queue {
 		properties:        make(map[string]string),
 		stateMachine:      NewObjectState(),
 		allocatedResource: resources.NewResource(),
+		priorityAllocated: make(map[int32]*resources.Resource),
 		preempting:        resources.NewResource(),
-		pending:           resources.NewResource(),
+		pending:           newPendingResource(),
+		weight:            defaultQueueWeight,
+		idleReclaim:       true,
+		lastActive:        time.Now(),
 	}
 }
 
@@ -91,9 +152,12 @@ func NewConfiguredQueue(conf configs.QueueConfig, parent *Queue) (*Queue, error)
 	sq.QueuePath = strings.ToLower(conf.Name)
 	sq.parent = parent
 	sq.isManaged = true
+	if parent == nil {
+		sq.accessCache = security.NewAccessCache(accessCacheTTL)
+	}
 
 	// update the properties
-	if err := sq.setQueueConfig(conf); err != nil {
+	if err := sq.setQueueConfig(conf, sq.getParentMaxResource()); err != nil {
 		return nil, fmt.Errorf("configured queue creation failed: %s", err)
 	}
 
@@ -142,8 +206,20 @@ func NewDynamicQueue(name string, leaf bool, parent *Queue) (*Queue, error) {
 	if err != nil {
 		return nil, fmt.Errorf("dynamic queue creation failed: %s", err)
 	}
+	// get the properties and the template from the parent before locking the queue
+	parentProps := parent.getProperties()
+	tmpl := parent.getTemplate()
+	// the queue is linked to the parent and can be seen by the scheduler: update it holding the lock
+	sq.Lock()
 	// pull the properties from the parent that should be set on the child
-	sq.setTemplateProperties(parent.getProperties())
+	sq.setTemplateProperties(parentProps)
+	// a leaf queue gets the limits from the template, a parent passes the template on to its children
+	if leaf {
+		tmpl.apply(sq)
+	} else {
+		sq.template = tmpl
+	}
+	sq.Unlock()
 	sq.UpdateSortType()
 	log.Logger().Info("dynamic queue added to scheduler",
 		zap.String("queueName", sq.QueuePath))
@@ -163,6 +239,13 @@ func (sq *Queue) getProperties() map[string]string {
 	return props
 }
 
+// Return the template for the dynamic queues created below this queue, can return nil.
+func (sq *Queue) getTemplate() *template {
+	sq.RLock()
+	defer sq.RUnlock()
+	return sq.template
+}
+
 // Merge the properties from the parent queue and the config in the set from new queue
 // lock free call
 func (sq *Queue) mergeProperties(parent, config map[string]string) {
@@ -186,7 +269,7 @@ func (sq *Queue) mergeProperties(parent, config map[string]string) {
 // The properties list for the parent must be retrieved using getProperties()
 // This currently only sets the sort policy as it is set on the parent
 // Further implementation is part of YUNIKORN-193
-// lock free call
+// lock free call, must be called holding the queue lock or during create only
 func (sq *Queue) setTemplateProperties(parent map[string]string) {
 	if len(parent) == 0 {
 		return
@@ -194,8 +277,12 @@ func (sq *Queue) setTemplateProperties(parent map[string]string) {
 	// for a leaf queue pull out all values from the template and set each of them
 	// See YUNIKORN-193: for now just copy one attr from parent
 	if sq.isLeaf {
-		if parent[configs.ApplicationSortPolicy] != "" {
-			sq.properties[configs.ApplicationSortPolicy] = parent[configs.ApplicationSortPolicy]
+		for _, key := range []string{configs.ApplicationSortPolicy, configs.ApplicationBoostTag, configs.ApplicationDemoteTag, configs.QueueTolerations,
+			configs.ApplicationRetentionCount, configs.ApplicationRetentionAge, configs.ApplicationRetentionExport,
+			configs.ApplicationHeadOfLineTimeout, configs.ApplicationMaxRuntime, configs.ApplicationIdleReclaim} {
+			if parent[key] != "" {
+				sq.properties[key] = parent[key]
+			}
 		}
 	}
 	// for a parent queue we just copy the template from its parent (no need to be recursive)
@@ -204,14 +291,16 @@ func (sq *Queue) setTemplateProperties(parent map[string]string) {
 }
 
 func (sq *Queue) SetQueueConfig(conf configs.QueueConfig) error {
+	// the parent limit must be retrieved before locking the queue
+	parentMax := sq.getParentMaxResource()
 	sq.Lock()
 	defer sq.Unlock()
-	return sq.setQueueConfig(conf)
+	return sq.setQueueConfig(conf, parentMax)
 }
 
-// Apply all the properties to the queue from the config
+// Apply all the properties to the queue from the config, resource expressions are resolved using the parent limit
 // lock free call, must be called holding the queue lock or during create only
-func (sq *Queue) setQueueConfig(conf configs.QueueConfig) error {
+func (sq *Queue) setQueueConfig(conf configs.QueueConfig, parentMax *resources.Resource) error {
 	// Set the ACLs
 	var err error
 	sq.submitACL, err = security.NewACL(conf.SubmitACL)
@@ -226,6 +315,8 @@ func (sq *Queue) setQueueConfig(conf configs.QueueConfig) error {
 			zap.Error(err))
 		return err
 	}
+	// cached access results could be based on the old ACLs
+	sq.getRoot().accessCache.Clear()
 	// Change from unmanaged to managed
 	if !sq.isManaged {
 		log.Logger().Info("changed dynamic queue to managed",
@@ -241,30 +332,50 @@ func (sq *Queue) setQueueConfig(conf configs.QueueConfig) error {
 
 	// Load the max & guaranteed resources for all but the root queue
 	if sq.Name != configs.RootQueue {
-		sq.maxResource, err = resources.NewResourceFromConf(conf.Resources.Max)
-		if err != nil {
-			log.Logger().Error("parsing failed on max resources this should not happen",
-				zap.Error(err))
+		sq.resourceConf = conf.Resources
+		sq.resourceExpr = hasQuantityExpression(conf.Resources.Max) || hasQuantityExpression(conf.Resources.Guaranteed)
+		if err = sq.resolveResources(parentMax); err != nil {
 			return err
 		}
-		if len(sq.maxResource.Resources) == 0 || resources.IsZero(sq.maxResource) {
-			log.Logger().Debug("max resources config setting ignored: cannot set zero max resources")
-			sq.maxResource = nil
-		}
+	}
 
-		// Load the guaranteed resources
-		sq.guaranteedResource, err = resources.NewResourceFromConf(conf.Resources.Guaranteed)
-		if err != nil {
-			log.Logger().Error("parsing failed on guaranteed resources this should not happen",
-				zap.Error(err))
-			return err
-		}
-		if len(sq.guaranteedResource.Resources) == 0 || resources.IsZero(sq.guaranteedResource) {
-			log.Logger().Debug("guaranteed resources config setting ignored: guaranteed must be non-zero to take effect")
-			sq.guaranteedResource = nil
+	// Load the priority quota
+	sq.priorityThreshold = conf.PriorityQuota.Threshold
+	sq.priorityQuota, err = resources.NewResourceFromConf(conf.PriorityQuota.Max)
+	if err != nil {
+		log.Logger().Error("parsing failed on priority quota this should not happen",
+			zap.Error(err))
+		return err
+	}
+	if len(sq.priorityQuota.Resources) == 0 {
+		sq.priorityQuota = nil
+	}
+
+	// Load the allowed resource types: the types allowed by the parents are checked separately
+	sq.allowedTypes = nil
+	if len(conf.AllowedResourceTypes) > 0 {
+		sq.allowedTypes = make(map[string]bool)
+		for _, name := range conf.AllowedResourceTypes {
+			sq.allowedTypes[name] = true
 		}
 	}
 
+	// Load the contention cap
+	sq.contentionShare = conf.ContentionCap.Share
+	sq.contentionPending = conf.ContentionCap.PendingThreshold
+
+	sq.weight = defaultQueueWeight
+	if conf.Weight > 0 && !math.IsInf(conf.Weight, 0) {
+		sq.weight = conf.Weight
+	}
+
+	sq.template, err = newTemplate(conf.ChildTemplate)
+	if err != nil {
+		log.Logger().Error("parsing failed on child template this should not happen",
+			zap.Error(err))
+		return err
+	}
+
 	sq.properties = conf.Properties
 	return nil
 }
@@ -273,19 +384,81 @@ func (sq *Queue) setQueueConfig(conf configs.QueueConfig) error {
 func (sq *Queue) UpdateSortType() {
 	sq.Lock()
 	defer sq.Unlock()
+	// the fence and the paused state are set on leaf and parent queues
+	sq.preemptionFence = strings.EqualFold(sq.properties[configs.PreemptionFence], "true")
+	sq.paused = strings.EqualFold(sq.properties[configs.SchedulingPaused], "true")
 	// set the defaults, override with what is in the configured properties
 	if sq.isLeaf {
 		// walk over all properties and process
 		var err error
 		sq.sortType = policies.Undefined
+		sq.boostTag = nil
+		sq.demoteTag = nil
+		sq.tolerations = nil
+		sq.retention = AppRetention{}
+		sq.headOfLine.timeout = 0
+		sq.maxRuntime = 0
+		sq.idleReclaim = true
+		sq.setPlacementBudget(sq.properties[configs.PlacementFailureThreshold], sq.properties[configs.PlacementFailureBackoff])
 		for key, value := range sq.properties {
-			if key == configs.ApplicationSortPolicy {
+			switch key {
+			case configs.ApplicationSortPolicy:
 				sq.sortType, err = policies.SortPolicyFromString(value)
 				if err != nil {
 					log.Logger().Debug("application sort property configuration error",
 						zap.Error(err))
 				}
-			} else {
+			case configs.ApplicationBoostTag:
+				sq.boostTag = newAppTag(value)
+			case configs.ApplicationDemoteTag:
+				sq.demoteTag = newAppTag(value)
+			case configs.QueueTolerations:
+				sq.tolerations = parseTaints(value)
+			case configs.ApplicationRetentionCount:
+				var count int
+				if count, err = strconv.Atoi(value); err == nil && count >= 0 {
+					sq.retention.MaxCount = count
+				} else {
+					log.Logger().Debug("application retention count property configuration error",
+						zap.String("queue", sq.QueuePath),
+						zap.String("value", value))
+				}
+			case configs.ApplicationRetentionAge:
+				var age time.Duration
+				if age, err = time.ParseDuration(value); err == nil && age >= 0 {
+					sq.retention.MaxAge = age
+				} else {
+					log.Logger().Debug("application retention age property configuration error",
+						zap.String("queue", sq.QueuePath),
+						zap.String("value", value))
+				}
+			case configs.ApplicationRetentionExport:
+				sq.retention.Export = strings.EqualFold(value, "true")
+			case configs.ApplicationHeadOfLineTimeout:
+				var timeout time.Duration
+				if timeout, err = time.ParseDuration(value); err == nil && timeout >= 0 {
+					sq.headOfLine.timeout = timeout
+				} else {
+					log.Logger().Debug("head of line timeout property configuration error",
+						zap.String("queue", sq.QueuePath),
+						zap.String("value", value))
+				}
+			case configs.ApplicationMaxRuntime:
+				var limit time.Duration
+				if limit, err = time.ParseDuration(value); err == nil && limit >= 0 {
+					sq.maxRuntime = limit
+				} else {
+					log.Logger().Debug("application maximum runtime property configuration error",
+						zap.String("queue", sq.QueuePath),
+						zap.String("value", value))
+				}
+			case configs.ApplicationIdleReclaim:
+				sq.idleReclaim = !strings.EqualFold(value, "false")
+			case configs.PlacementFailureThreshold, configs.PlacementFailureBackoff:
+				// handled as a pair above
+			case configs.PreemptionFence, configs.SchedulingPaused:
+				// handled for all queues above
+			default:
 				// skip unknown properties just log them
 				log.Logger().Debug("queue property skipped",
 					zap.String("key", key),
@@ -298,8 +471,78 @@ func (sq *Queue) UpdateSortType() {
 		}
 		return
 	}
-	// set the sorting type for parent queues
+	// set the sorting type for parent queues: fair unless drf is configured
 	sq.sortType = policies.FairSortPolicy
+	if value := sq.properties[configs.QueueSortPolicy]; value != "" {
+		sortType, err := policies.SortPolicyFromString(value)
+		if err == nil && (sortType == policies.FairSortPolicy || sortType == policies.DRFSortPolicy) {
+			sq.sortType = sortType
+		} else {
+			log.Logger().Debug("queue sort property configuration error, using fair",
+				zap.String("queue", sq.QueuePath),
+				zap.String("value", value))
+		}
+	}
+}
+
+// Return the retention of the completed applications of the queue.
+func (sq *Queue) GetAppRetention() AppRetention {
+	sq.RLock()
+	defer sq.RUnlock()
+	return sq.retention
+}
+
+// Return the maximum runtime of the applications in the queue, 0 means unlimited.
+func (sq *Queue) GetMaxRuntime() time.Duration {
+	sq.RLock()
+	defer sq.RUnlock()
+	return sq.maxRuntime
+}
+
+// Return true if the resources of the idle applications in the queue can be reclaimed.
+func (sq *Queue) IsIdleReclaimAllowed() bool {
+	sq.RLock()
+	defer sq.RUnlock()
+	return sq.idleReclaim
+}
+
+// Pause or resume scheduling for the queue and the queues below it.
+// The paused state is replaced on the next configuration update.
+func (sq *Queue) SetSchedulingPaused(paused bool) {
+	sq.Lock()
+	defer sq.Unlock()
+	sq.paused = paused
+}
+
+// Return true if scheduling is paused for the queue. The queues below a paused queue are not scheduled either.
+func (sq *Queue) IsSchedulingPaused() bool {
+	sq.RLock()
+	defer sq.RUnlock()
+	return sq.paused
+}
+
+// Return the outermost queue with the preemption fence set on the path from the root to this queue, nil if the queue
+// is not fenced. Allocations in the queue can only be preempted by demand from inside the subtree of that queue.
+// A queue inside a fenced subtree cannot remove the fence by unsetting the property.
+func (sq *Queue) GetPreemptionFence() *Queue {
+	var fence *Queue
+	for queue := sq; queue != nil; queue = queue.parent {
+		queue.RLock()
+		if queue.preemptionFence {
+			fence = queue
+		}
+		queue.RUnlock()
+	}
+	return fence
+}
+
+// Return true if demand from the queue with the path can preempt allocations in this queue.
+func (sq *Queue) CanBePreemptedFrom(queuePath string) bool {
+	fence := sq.GetPreemptionFence()
+	if fence == nil {
+		return true
+	}
+	return queuePath == fence.QueuePath || strings.HasPrefix(queuePath, fence.QueuePath+configs.DOT)
 }
 
 func (sq *Queue) GetQueuePath() string {
@@ -360,35 +603,113 @@ func (sq *Queue) GetGuaranteedResource() *resources.Resource {
 }
 
 // Check if the user has access to the queue to submit an application recursively.
-// This will check the submit ACL and the admin ACL.
+// This will check the submit ACL and the admin ACL. The result is cached in the root queue for the user and groups.
 func (sq *Queue) CheckSubmitAccess(user security.UserGroup) bool {
+	cache := sq.getRoot().accessCache
+	key := sq.QueuePath + "|" + user.User + "|" + strings.Join(user.Groups, ",")
+	if allow, ok := cache.Get(key); ok {
+		return allow
+	}
+	allow := sq.checkSubmitAccess(expandGroups(user))
+	cache.Set(key, allow)
+	return allow
+}
+
+// Check the submit access recursively, the groups of the user must have been expanded.
+func (sq *Queue) checkSubmitAccess(user security.UserGroup) bool {
 	sq.RLock()
 	allow := sq.submitACL.CheckAccess(user) || sq.adminACL.CheckAccess(user)
 	sq.RUnlock()
 	if !allow && sq.parent != nil {
-		allow = sq.parent.CheckSubmitAccess(user)
+		allow = sq.parent.checkSubmitAccess(user)
 	}
 	return allow
 }
 
 // Check if the user has access to the queue for admin actions recursively.
 func (sq *Queue) CheckAdminAccess(user security.UserGroup) bool {
+	return sq.checkAdminAccess(expandGroups(user))
+}
+
+// Check the admin access recursively, the groups of the user must have been expanded.
+func (sq *Queue) checkAdminAccess(user security.UserGroup) bool {
 	sq.RLock()
 	allow := sq.adminACL.CheckAccess(user)
 	sq.RUnlock()
 	if !allow && sq.parent != nil {
-		allow = sq.parent.CheckAdminAccess(user)
+		allow = sq.parent.checkAdminAccess(user)
 	}
 	return allow
 }
 
+// The ACL entry on a queue that gave a user access.
+type AccessRule struct {
+	QueuePath string
+	ACL       string
+	Entry     string
+}
+
+// Return the rule that gives the user submit access to the queue, nil if the user has no access.
+// Evaluates the ACLs in the same order as the submit access check without using the access cache.
+func (sq *Queue) GetSubmitAccessRule(user security.UserGroup) *AccessRule {
+	return sq.getAccessRule(expandGroups(user), true)
+}
+
+// Return the rule that gives the user admin access to the queue, nil if the user has no access.
+func (sq *Queue) GetAdminAccessRule(user security.UserGroup) *AccessRule {
+	return sq.getAccessRule(expandGroups(user), false)
+}
+
+// Find the matching ACL entry recursively, the groups of the user must have been expanded.
+func (sq *Queue) getAccessRule(user security.UserGroup, submit bool) *AccessRule {
+	var rule *AccessRule
+	sq.RLock()
+	if submit {
+		if entry := sq.submitACL.GetMatch(user); entry != "" {
+			rule = &AccessRule{QueuePath: sq.QueuePath, ACL: SubmitACLName, Entry: entry}
+		}
+	}
+	if rule == nil {
+		if entry := sq.adminACL.GetMatch(user); entry != "" {
+			rule = &AccessRule{QueuePath: sq.QueuePath, ACL: AdminACLName, Entry: entry}
+		}
+	}
+	sq.RUnlock()
+	if rule == nil && sq.parent != nil {
+		rule = sq.parent.getAccessRule(user, submit)
+	}
+	return rule
+}
+
+// Add the parent groups from the group hierarchy plugin, if registered, to the groups of the user.
+func expandGroups(user security.UserGroup) security.UserGroup {
+	if plugin := plugins.GetGroupHierarchyPlugin(); plugin != nil {
+		user.Groups = security.ExpandGroups(user.Groups, plugin.GetParentGroups)
+	}
+	return user
+}
+
+// Return the root of the queue hierarchy.
+// Lock free call: the parent of a queue does not change.
+func (sq *Queue) getRoot() *Queue {
+	root := sq
+	for root.parent != nil {
+		root = root.parent
+	}
+	return root
+}
+
 // Convert the queue hierarchy into an object for the webservice
 
 func (sq *Queue) GetQueueInfos() dao.QueueDAOInfo {
 	queueInfo := dao.QueueDAOInfo{}
 	for _, child := range sq.GetCopyOfChildren() {
-		queueInfo.ChildQueues = append(queueInfo.ChildQueues, child.GetQueueInfos())
+		childInfo := child.GetQueueInfos()
+		queueInfo.ReservedApps += childInfo.ReservedApps
+		queueInfo.ChildQueues = append(queueInfo.ChildQueues, childInfo)
 	}
+	// the pending resources include the children and are not protected by the queue lock
+	pending := sq.GetPendingResource()
 
 	// children are done we can now lock just this queue.
 	sq.RLock()
@@ -401,7 +722,9 @@ func (sq *Queue) GetQueueInfos() dao.QueueDAOInfo {
 		UsedCapacity: sq.allocatedResource.DAOString(),
 		AbsUsedCapacity: resources.CalculateAbsUsedCapacity(
 			sq.maxResource, sq.allocatedResource).DAOString(),
+		PendingCapacity: pending.DAOString(),
 	}
+	queueInfo.ReservedApps += len(sq.reservedApps)
 	queueInfo.Properties = make(map[string]string)
 	for k, v := range sq.properties {
 		queueInfo.Properties[k] = v
@@ -413,9 +736,19 @@ func (sq *Queue) GetPartitionQueues() dao.PartitionQueueDAOInfo {
 	queueInfo := dao.PartitionQueueDAOInfo{}
 	if len(sq.children) > 0 {
 		for _, child := range sq.GetCopyOfChildren() {
-			queueInfo.Children = append(queueInfo.Children, child.GetPartitionQueues())
+			childInfo := child.GetPartitionQueues()
+			queueInfo.RunningApps += childInfo.RunningApps
+			queueInfo.Children = append(queueInfo.Children, childInfo)
 		}
 	}
+	for _, app := range sq.GetCopyOfApps() {
+		if app.IsRunning() {
+			queueInfo.RunningApps++
+		}
+	}
+	queueInfo.PendingResource = sq.GetPendingResource().DAOString()
+	// the contention state is based on the root queue: get it before locking this queue
+	contentionCap, clamped := sq.getContentionCap()
 	sq.RLock()
 	defer sq.RUnlock()
 	queueInfo.QueueName = sq.GetQueuePath()
@@ -423,6 +756,29 @@ func (sq *Queue) GetPartitionQueues() dao.PartitionQueueDAOInfo {
 	queueInfo.MaxResource = sq.maxResource.DAOString()
 	queueInfo.GuaranteedResource = sq.guaranteedResource.DAOString()
 	queueInfo.AllocatedResource = sq.allocatedResource.DAOString()
+	queueInfo.Weight = sq.weight
+	if sq.priorityQuota != nil {
+		queueInfo.PriorityQuota = &dao.PriorityQuotaDAOInfo{
+			Threshold:    sq.priorityThreshold,
+			MaxResource:  sq.priorityQuota.DAOString(),
+			UsedResource: sq.getPriorityQuotaUsage().DAOString(),
+		}
+	}
+	if sq.allowedTypes != nil {
+		for name := range sq.allowedTypes {
+			queueInfo.AllowedResourceTypes = append(queueInfo.AllowedResourceTypes, name)
+		}
+		sort.Strings(queueInfo.AllowedResourceTypes)
+	}
+	if sq.contentionShare > 0 {
+		queueInfo.ContentionCap = &dao.ContentionCapDAOInfo{
+			Share:            sq.contentionShare,
+			PendingThreshold: sq.contentionPending,
+			MaxResource:      contentionCap.DAOString(),
+			Clamped:          clamped,
+		}
+	}
+	queueInfo.SchedulingPaused = sq.paused
 	queueInfo.IsLeaf = sq.IsLeafQueue()
 	queueInfo.IsManaged = sq.IsManaged()
 	if sq.parent == nil {
@@ -433,44 +789,56 @@ func (sq *Queue) GetPartitionQueues() dao.PartitionQueueDAOInfo {
 	return queueInfo
 }
 
-// Return the pending resources for this queue
+// Return the pending resources for this queue, including the queues below it.
+// The total is only recalculated if the pending resources of the queue, or the queues below it, changed since
+// the last call. The returned resource is shared and must not be modified.
 func (sq *Queue) GetPendingResource() *resources.Resource {
-	sq.RLock()
-	defer sq.RUnlock()
-	return sq.pending
+	version := sq.pending.getVersion()
+	if total := sq.pending.getCached(version); total != nil {
+		return total
+	}
+	total := sq.pending.getOwn()
+	for _, child := range sq.GetCopyOfChildren() {
+		total.AddTo(child.GetPendingResource())
+	}
+	sq.pending.setCached(total, version)
+	return total
 }
 
 // Update pending resource of this queue
+// This does not lock the queue or its parents: the totals of the parents are recalculated when requested.
 func (sq *Queue) incPendingResource(delta *resources.Resource) {
-	// update the parent
-	if sq.parent != nil {
-		sq.parent.incPendingResource(delta)
-	}
-	// update this queue
-	sq.Lock()
-	defer sq.Unlock()
-	sq.pending = resources.Add(sq.pending, delta)
+	sq.pending.add(delta)
+	sq.invalidatePending()
 }
 
 // Remove pending resource of this queue
+// This does not lock the queue or its parents: the totals of the parents are recalculated when requested.
 func (sq *Queue) decPendingResource(delta *resources.Resource) {
 	if sq == nil {
 		return
 	}
-	// update the parent
-	if sq.parent != nil {
-		sq.parent.decPendingResource(delta)
-	}
-	// update this queue
-	sq.Lock()
-	defer sq.Unlock()
-	var err error
-	sq.pending, err = resources.SubErrorNegative(sq.pending, delta)
-	if err != nil {
+	if err := sq.pending.sub(delta); err != nil {
 		log.Logger().Warn("Pending resources went negative",
 			zap.String("queueName", sq.QueuePath),
 			zap.Error(err))
 	}
+	sq.invalidatePending()
+}
+
+// Mark the pending resources of this queue and all its parents as changed.
+// The parent is read under the lock of each queue as a queue move can change it.
+func (sq *Queue) invalidatePending() {
+	for queue := sq; queue != nil; queue = queue.getParent() {
+		queue.pending.invalidate()
+	}
+}
+
+// Return the parent of the queue, nil for the root queue.
+func (sq *Queue) getParent() *Queue {
+	sq.RLock()
+	defer sq.RUnlock()
+	return sq.parent
 }
 
 // Add  app to the queue. All checks are assumed to have passed before we get here.
@@ -480,6 +848,7 @@ func (sq *Queue) AddApplication(app *Application) {
 	sq.Lock()
 	defer sq.Unlock()
 	sq.applications[app.ApplicationID] = app
+	sq.lastActive = time.Now()
 	// YUNIKORN-199: update the quota from the namespace
 	// get the tag with the quota
 	quota := app.GetTag(AppTagNamespaceResourceQuota)
@@ -539,6 +908,7 @@ func (sq *Queue) RemoveApplication(app *Application) {
 	defer sq.Unlock()
 
 	delete(sq.applications, appID)
+	sq.lastActive = time.Now()
 }
 
 // Get a copy of all apps holding the lock
@@ -578,15 +948,27 @@ func (sq *Queue) IsEmpty() bool {
 	return len(sq.children) == 0
 }
 
+// Return true if the leaf queue has been without applications for at least the timeout.
+// A parent queue is never idle: it is removed when all its children are removed.
+func (sq *Queue) IsIdle(timeout time.Duration) bool {
+	sq.RLock()
+	defer sq.RUnlock()
+	if !sq.isLeaf || len(sq.applications) != 0 {
+		return false
+	}
+	return time.Since(sq.lastActive) >= timeout
+}
+
 // Remove a child queue from this queue.
 // No checks are performed: if the child has been removed already it is a noop.
 // This may only be called by the queue removal itself on the registered parent.
 // Queue removal is always a bottom up action: leaves first then the parent.
 func (sq *Queue) removeChildQueue(name string) {
 	sq.Lock()
-	defer sq.Unlock()
-
 	delete(sq.children, name)
+	sq.Unlock()
+	// the removed queue no longer counts towards the pending resources
+	sq.invalidatePending()
 }
 
 // Add a child queue to this queue.
@@ -605,6 +987,81 @@ func (sq *Queue) addChildQueue(child *Queue) error {
 	return nil
 }
 
+// Move the queue, and the queues below it, to a new parent under a new name.
+// The usage of the queue is removed from the old parents and added to the new parents, the paths of the
+// queue and all queues below it are updated. All checks are assumed to have passed before we get here.
+// Returns the applications in the moved queues, the caller must refresh their queue names.
+func (sq *Queue) MoveQueue(newParent *Queue, newName string) ([]*Application, error) {
+	sq.RLock()
+	oldParent := sq.parent
+	oldName := sq.Name
+	allocated := sq.allocatedResource.Clone()
+	priorityAllocated := make(map[int32]*resources.Resource, len(sq.priorityAllocated))
+	for priority, res := range sq.priorityAllocated {
+		priorityAllocated[priority] = res.Clone()
+	}
+	sq.RUnlock()
+	if oldParent == nil {
+		return nil, fmt.Errorf("the root queue cannot be moved")
+	}
+	oldParent.removeChildQueue(oldName)
+	sq.Lock()
+	sq.Name = newName
+	sq.parent = newParent
+	sq.Unlock()
+	if err := newParent.addChildQueue(sq); err != nil {
+		// revert to the old location
+		sq.Lock()
+		sq.Name = oldName
+		sq.parent = oldParent
+		sq.Unlock()
+		oldParent.Lock()
+		oldParent.children[oldName] = sq
+		oldParent.Unlock()
+		return nil, err
+	}
+	// move the usage: the queue is already linked to the new parent
+	if err := oldParent.DecAllocatedResource(allocated); err != nil {
+		log.Logger().Warn("failed to remove allocated resources from the old parent",
+			zap.String("queue", sq.QueuePath),
+			zap.Error(err))
+	}
+	for priority, res := range priorityAllocated {
+		oldParent.decPriorityAllocated(priority, res)
+	}
+	if err := newParent.IncAllocatedResource(allocated, true); err != nil {
+		log.Logger().Warn("failed to add allocated resources to the new parent",
+			zap.String("queue", sq.QueuePath),
+			zap.Error(err))
+	}
+	// the pending resources of the parents include the queues below them: mark both hierarchies as changed
+	oldParent.invalidatePending()
+	newParent.invalidatePending()
+	for priority, res := range priorityAllocated {
+		newParent.incPriorityAllocated(priority, res)
+	}
+	// cached access results are based on the queue path
+	sq.getRoot().accessCache.Clear()
+	return sq.updateQueuePath(newParent.QueuePath), nil
+}
+
+// Update the path of the queue and all queues below it after a move.
+// Returns the applications in the queues.
+func (sq *Queue) updateQueuePath(parentPath string) []*Application {
+	sq.Lock()
+	sq.QueuePath = parentPath + configs.DOT + sq.Name
+	queuePath := sq.QueuePath
+	apps := make([]*Application, 0, len(sq.applications))
+	for _, app := range sq.applications {
+		apps = append(apps, app)
+	}
+	sq.Unlock()
+	for _, child := range sq.GetCopyOfChildren() {
+		apps = append(apps, child.updateQueuePath(queuePath)...)
+	}
+	return apps
+}
+
 // Mark the managed queue for removal from the system.
 // This can be executed multiple times and is only effective the first time.
 // This is a noop on an unmanaged queue
@@ -742,6 +1199,97 @@ func (sq *Queue) IncAllocatedResource(alloc *resources.Resource, nodeReported bo
 	return nil
 }
 
+// Increment the allocated resources for the priority in this queue (recursively)
+func (sq *Queue) incPriorityAllocated(priority int32, alloc *resources.Resource) {
+	if sq.parent != nil {
+		sq.parent.incPriorityAllocated(priority, alloc)
+	}
+	sq.Lock()
+	defer sq.Unlock()
+	sq.priorityAllocated[priority] = resources.Add(sq.priorityAllocated[priority], alloc)
+}
+
+// Decrement the allocated resources for the priority in this queue (recursively)
+// Guard against going below zero resources.
+func (sq *Queue) decPriorityAllocated(priority int32, alloc *resources.Resource) {
+	if sq.parent != nil {
+		sq.parent.decPriorityAllocated(priority, alloc)
+	}
+	sq.Lock()
+	defer sq.Unlock()
+	allocated, err := resources.SubErrorNegative(sq.priorityAllocated[priority], alloc)
+	if err != nil {
+		log.Logger().Warn("Priority allocated resources went negative",
+			zap.String("queueName", sq.QueuePath),
+			zap.Int32("priority", priority),
+			zap.Error(err))
+	}
+	if resources.IsZero(allocated) {
+		delete(sq.priorityAllocated, priority)
+		return
+	}
+	sq.priorityAllocated[priority] = allocated
+}
+
+// Return the resources allocated to asks above the priority threshold of the queue.
+// NOTE: this is a lock free call. It must only be called holding the queue lock.
+func (sq *Queue) getPriorityQuotaUsage() *resources.Resource {
+	usage := resources.NewResource()
+	for priority, allocated := range sq.priorityAllocated {
+		if priority > sq.priorityThreshold {
+			usage.AddTo(allocated)
+		}
+	}
+	return usage
+}
+
+// Check if an ask with the priority and resource fits in the priority quota of the queue and all its parents.
+// Queues without a priority quota, or with a threshold at or above the priority, do not limit the ask.
+func (sq *Queue) fitsPriorityQuota(priority int32, alloc *resources.Resource) bool {
+	sq.RLock()
+	fits := sq.priorityQuota == nil || priority <= sq.priorityThreshold ||
+		sq.priorityQuota.FitInMaxUndef(resources.Add(sq.getPriorityQuotaUsage(), alloc))
+	sq.RUnlock()
+	if fits && sq.parent != nil {
+		fits = sq.parent.fitsPriorityQuota(priority, alloc)
+	}
+	return fits
+}
+
+// Return the resources allocated to asks above the priority threshold of the queue.
+func (sq *Queue) GetPriorityQuotaUsage() *resources.Resource {
+	sq.RLock()
+	defer sq.RUnlock()
+	return sq.getPriorityQuotaUsage()
+}
+
+// Check that the resource only requests resource types allowed in this queue and all its parents.
+// A resource type with a zero quantity is not requested. Returns an error for the first type that is not allowed.
+func (sq *Queue) CheckAllowedResourceTypes(res *resources.Resource) error {
+	if res == nil {
+		return nil
+	}
+	sq.RLock()
+	allowed := sq.allowedTypes
+	sq.RUnlock()
+	if allowed != nil {
+		names := make([]string, 0, len(res.Resources))
+		for name, quantity := range res.Resources {
+			if quantity != 0 && !allowed[name] {
+				names = append(names, name)
+			}
+		}
+		if len(names) > 0 {
+			sort.Strings(names)
+			return fmt.Errorf("resource type %s is not allowed in queue %s", names[0], sq.QueuePath)
+		}
+	}
+	if sq.parent != nil {
+		return sq.parent.CheckAllowedResourceTypes(res)
+	}
+	return nil
+}
+
 // Decrement the allocated resources for this queue (recursively)
 // Guard against going below zero resources.
 func (sq *Queue) DecAllocatedResource(alloc *resources.Resource) error {
@@ -790,7 +1338,18 @@ func (sq *Queue) sortApplications(filterApps bool) []*Application {
 		// this is to skip the app filtering in the StateAware policy sorting
 		queueSortType = policies.FifoSortPolicy
 	}
-	return sortApplications(sq.GetCopyOfApps(), queueSortType, sq.GetGuaranteedResource())
+	// fair compares usage against the guaranteed resources, drf against the partition resources
+	globalResource := sq.GetGuaranteedResource()
+	if queueSortType == policies.DRFSortPolicy {
+		globalResource = sq.getPartitionResource()
+	}
+	sortedApps := sortApplications(sq.GetCopyOfApps(), queueSortType, globalResource)
+	if window, priority := sq.getRoot().getRecoveryPriority(); window > 0 {
+		sortRecoveredApplications(sortedApps, queueSortType, window, priority)
+	}
+	boost, demote := sq.getTagPriority()
+	sortApplicationsByTag(sortedApps, boost, demote)
+	return sortedApps
 }
 
 // Return a sorted copy of the queues for this parent queue.
@@ -798,14 +1357,15 @@ func (sq *Queue) sortApplications(filterApps bool) []*Application {
 // sorting type for the parent queue.
 // Lock free call all locks are taken when needed in called functions
 func (sq *Queue) sortQueues() []*Queue {
-	if sq.IsLeafQueue() {
+	// nothing below a paused queue is scheduled
+	if sq.IsLeafQueue() || sq.IsSchedulingPaused() {
 		return nil
 	}
 	// Create a list of the queues with pending resources
 	sortedQueues := make([]*Queue, 0)
 	for _, child := range sq.GetCopyOfChildren() {
-		// a stopped queue cannot be scheduled
-		if child.IsStopped() {
+		// a stopped or paused queue cannot be scheduled
+		if child.IsStopped() || child.IsSchedulingPaused() {
 			continue
 		}
 		// queue must have pending resources to be considered for scheduling
@@ -814,9 +1374,68 @@ func (sq *Queue) sortQueues() []*Queue {
 		}
 	}
 	// Sort the queues
-	sortQueue(sortedQueues, sq.getSortType())
+	sortQueue(sortedQueues, sq.getSortType(), sq.getPartitionResource())
+
+	return orderSystemAndBackedOff(sortedQueues)
+}
+
+// Mark the queue as the system queue, or remove the mark.
+func (sq *Queue) SetSystemQueue(system bool) {
+	sq.Lock()
+	defer sq.Unlock()
+	sq.isSystem = system
+}
 
-	return sortedQueues
+// Set the prioritization of recovered applications for the partition, must be called on the root queue.
+func (sq *Queue) SetRecoveryPriority(window time.Duration, boost int32) {
+	sq.Lock()
+	defer sq.Unlock()
+	sq.recoveryWindow = window
+	sq.recoveryBoost = boost
+}
+
+// Return the time recovered applications are prioritized and the priority boost, only set on the root queue.
+func (sq *Queue) getRecoveryPriority() (time.Duration, int32) {
+	sq.RLock()
+	defer sq.RUnlock()
+	return sq.recoveryWindow, sq.recoveryBoost
+}
+
+// Is the queue the system queue created by the core.
+func (sq *Queue) IsSystemQueue() bool {
+	sq.RLock()
+	defer sq.RUnlock()
+	return sq.isSystem
+}
+
+// Move the queues that are backed off due to placement failures to the end of the list and the system queue to
+// the front of the list, the system queue is never backed off.
+// The order within the groups of queues is not changed.
+func orderSystemAndBackedOff(queues []*Queue) []*Queue {
+	system := make([]*Queue, 0)
+	backedOff := make([]*Queue, 0)
+	sorted := make([]*Queue, 0, len(queues))
+	for _, queue := range queues {
+		switch {
+		case queue.IsSystemQueue():
+			system = append(system, queue)
+		case queue.IsPlacementBackedOff():
+			backedOff = append(backedOff, queue)
+		default:
+			sorted = append(sorted, queue)
+		}
+	}
+	return append(append(system, sorted...), backedOff...)
+}
+
+// Return the total resources of the partition, which is set as the maximum resource of the root queue.
+// Lock free call: the parent link is set on create only
+func (sq *Queue) getPartitionResource() *resources.Resource {
+	root := sq
+	for root.parent != nil {
+		root = root.parent
+	}
+	return root.GetMaxResource()
 }
 
 // Get the headroom for the queue this should never be more than the headroom for the parent.
@@ -824,12 +1443,17 @@ func (sq *Queue) sortQueues() []*Queue {
 // will return nil.
 // NOTE: if a resource quantity is missing and a limit is defined the missing quantity will be seen as a limit of 0.
 // When defining a limit you therefore should define all resource quantities.
+// While the partition is under contention the headroom is also limited by the contention cap of the queue.
 func (sq *Queue) getHeadRoom() *resources.Resource {
 	var parentHeadRoom *resources.Resource
 	if sq.parent != nil {
 		parentHeadRoom = sq.parent.getHeadRoom()
 	}
-	return sq.internalHeadRoom(parentHeadRoom)
+	var limit *resources.Resource
+	if contentionCap, clamped := sq.getContentionCap(); clamped {
+		limit = contentionCap
+	}
+	return sq.internalHeadRoom(parentHeadRoom, limit)
 }
 
 // This function returns the max headRoom of a queue.
@@ -842,13 +1466,22 @@ func (sq *Queue) getMaxHeadRoom() *resources.Resource {
 	} else {
 		return nil
 	}
-	return sq.internalHeadRoom(parentHeadRoom)
+	return sq.internalHeadRoom(parentHeadRoom, nil)
 }
 
-func (sq *Queue) internalHeadRoom(parentHeadRoom *resources.Resource) *resources.Resource {
+// Calculate the headroom of the queue based on the max resource of the queue, limited by the contention cap if
+// not nil, and the headroom of the parent.
+func (sq *Queue) internalHeadRoom(parentHeadRoom, contentionCap *resources.Resource) *resources.Resource {
 	sq.RLock()
 	defer sq.RUnlock()
 	headRoom := sq.maxResource.Clone()
+	if contentionCap != nil {
+		if headRoom == nil {
+			headRoom = contentionCap.Clone()
+		} else {
+			headRoom = resources.ComponentWiseMin(headRoom, contentionCap)
+		}
+	}
 
 	// if we have no max set headroom is always the same as the parent
 	if headRoom == nil {
@@ -891,6 +1524,52 @@ func (sq *Queue) GetMaxQueueSet() *resources.Resource {
 	return sq.internalGetMax(limit)
 }
 
+// Get the queue that limits the allocations of this queue together with other queues: the highest queue below the
+// root with a maximum resource, a priority quota or a contention cap set. Returns the queue itself if none of its parents below the
+// root has a limit set. Allocations in queues with the same limiting queue compete for the same headroom.
+func (sq *Queue) GetLimitingQueue() *Queue {
+	limiting := sq
+	for parent := sq.parent; parent != nil && parent.parent != nil; parent = parent.parent {
+		if parent.hasSharedLimit() {
+			limiting = parent
+		}
+	}
+	return limiting
+}
+
+func (sq *Queue) hasSharedLimit() bool {
+	sq.RLock()
+	defer sq.RUnlock()
+	return sq.maxResource != nil || sq.priorityQuota != nil || sq.contentionShare > 0
+}
+
+// Return the contention cap of the queue: the configured share of the partition resources. The second return
+// value is true if the partition is under contention and the cap applies. The partition is under contention if
+// the pending resources of the root queue, as a ratio of the partition resources, exceed the threshold for any
+// resource type. Returns nil and false if the queue has no contention cap or the partition has no resources.
+func (sq *Queue) getContentionCap() (*resources.Resource, bool) {
+	sq.RLock()
+	share := sq.contentionShare
+	threshold := sq.contentionPending
+	sq.RUnlock()
+	if share == 0 {
+		return nil, false
+	}
+	root := sq.getRoot()
+	partition := root.GetMaxResource()
+	if partition == nil {
+		return nil, false
+	}
+	contended := false
+	for name, quantity := range root.GetPendingResource().Resources {
+		if total := partition.Resources[name]; total > 0 && float64(quantity)/float64(total) > threshold {
+			contended = true
+			break
+		}
+	}
+	return resources.MultiplyBy(partition, share), contended
+}
+
 func (sq *Queue) internalGetMax(parentLimit *resources.Resource) *resources.Resource {
 	sq.RLock()
 	defer sq.RUnlock()
@@ -909,18 +1588,134 @@ func (sq *Queue) internalGetMax(parentLimit *resources.Resource) *resources.Reso
 	return resources.ComponentWiseMin(parentLimit, sq.maxResource)
 }
 
+// Return the max and guaranteed resources set on this queue only, the parent limits are not taken into account.
+// Either can be nil if not set.
+func (sq *Queue) GetLimits() (*resources.Resource, *resources.Resource) {
+	sq.RLock()
+	defer sq.RUnlock()
+	return sq.maxResource, sq.guaranteedResource
+}
+
+// Set the max and guaranteed resources for a queue that is not the root.
+// A nil or zero resource removes the limit. The values are replaced on the next configuration update.
+func (sq *Queue) SetLimits(max, guaranteed *resources.Resource) {
+	sq.Lock()
+	if sq.parent == nil {
+		sq.Unlock()
+		log.Logger().Warn("Limits set on the root queue",
+			zap.String("queueName", sq.QueuePath))
+		return
+	}
+	// fixed limits replace the resource expressions from the configuration
+	sq.resourceExpr = false
+	sq.maxResource = nil
+	if max != nil && len(max.Resources) != 0 && !resources.IsZero(max) {
+		sq.maxResource = max.Clone()
+	}
+	sq.guaranteedResource = nil
+	if guaranteed != nil && len(guaranteed.Resources) != 0 && !resources.IsZero(guaranteed) {
+		sq.guaranteedResource = guaranteed.Clone()
+	}
+	log.Logger().Info("queue limits updated",
+		zap.String("queueName", sq.QueuePath),
+		zap.Stringer("maxResource", sq.maxResource),
+		zap.Stringer("guaranteedResource", sq.guaranteedResource))
+	sq.Unlock()
+	sq.updateChildResources()
+}
+
 // Set the max resource for root the queue.
 // Should only happen on the root, all other queues get it from the config via properties.
 func (sq *Queue) SetMaxResource(max *resources.Resource) {
 	sq.Lock()
-	defer sq.Unlock()
-
 	if sq.parent != nil {
+		sq.Unlock()
 		log.Logger().Warn("Max resources set on a queue that is not the root",
 			zap.String("queueName", sq.QueuePath))
 		return
 	}
 	sq.maxResource = max.Clone()
+	sq.Unlock()
+	// resource expressions in the hierarchy are relative to the root which is the partition total
+	sq.updateChildResources()
+}
+
+// Resolve the configured max & guaranteed resources, expressions are resolved against the parent limit.
+// lock free call, must be called holding the queue lock or during create only
+func (sq *Queue) resolveResources(parentMax *resources.Resource) error {
+	maxResource, err := resources.NewResourceFromConfWithParent(sq.resourceConf.Max, parentMax)
+	if err != nil {
+		log.Logger().Error("parsing failed on max resources this should not happen",
+			zap.Error(err))
+		return err
+	}
+	if len(maxResource.Resources) == 0 || resources.IsZero(maxResource) {
+		log.Logger().Debug("max resources config setting ignored: cannot set zero max resources")
+		maxResource = nil
+	}
+
+	// Load the guaranteed resources
+	guaranteedResource, err := resources.NewResourceFromConfWithParent(sq.resourceConf.Guaranteed, parentMax)
+	if err != nil {
+		log.Logger().Error("parsing failed on guaranteed resources this should not happen",
+			zap.Error(err))
+		return err
+	}
+	if len(guaranteedResource.Resources) == 0 || resources.IsZero(guaranteedResource) {
+		log.Logger().Debug("guaranteed resources config setting ignored: guaranteed must be non-zero to take effect")
+		guaranteedResource = nil
+	}
+	sq.maxResource = maxResource
+	sq.guaranteedResource = guaranteedResource
+	return nil
+}
+
+// Return the limit of the parent queue, nil for the root queue.
+// Lock free call: must not be called holding the queue lock, the parent locks are taken
+func (sq *Queue) getParentMaxResource() *resources.Resource {
+	if sq.parent == nil {
+		return nil
+	}
+	return sq.parent.GetMaxResource()
+}
+
+// Resolve the resource expressions of all queues below this queue again after the limit of the queue changed.
+// Lock free call: the locks of the children are taken when needed
+func (sq *Queue) updateChildResources() {
+	parentMax := sq.GetMaxResource()
+	for _, child := range sq.GetCopyOfChildren() {
+		child.updateResources(parentMax)
+		child.updateChildResources()
+	}
+}
+
+// Resolve the resource expressions of the queue against the changed parent limit.
+func (sq *Queue) updateResources(parentMax *resources.Resource) {
+	sq.Lock()
+	defer sq.Unlock()
+	if !sq.resourceExpr {
+		return
+	}
+	if err := sq.resolveResources(parentMax); err != nil {
+		log.Logger().Warn("failed to resolve the resource expressions of the queue",
+			zap.String("queueName", sq.QueuePath),
+			zap.Error(err))
+		return
+	}
+	log.Logger().Debug("queue resources updated from expressions",
+		zap.String("queueName", sq.QueuePath),
+		zap.String("maxResource", sq.maxResource.String()),
+		zap.String("guaranteedResource", sq.guaranteedResource.String()))
+}
+
+// Check if any of the configured quantities is an expression relative to the parent.
+func hasQuantityExpression(conf map[string]string) bool {
+	for _, value := range conf {
+		if resources.IsQuantityExpression(value) {
+			return true
+		}
+	}
+	return false
 }
 
 // Try allocate pending requests. This only gets called if there is a pending request on this queue or its children.
@@ -933,19 +1728,30 @@ func (sq *Queue) TryAllocate(iterator func() interfaces.NodeIterator) *Allocatio
 		// get the headroom
 		headRoom := sq.getHeadRoom()
 		// process the apps (filters out app without pending requests)
-		for _, app := range sq.sortApplications(true) {
+		apps := sq.sortApplications(true)
+		for _, app := range sq.filterHeadOfLine(apps) {
 			alloc := app.tryAllocate(headRoom, iterator)
 			if alloc != nil {
 				log.Logger().Debug("allocation found on queue",
 					zap.String("queueName", sq.QueuePath),
 					zap.String("appID", app.ApplicationID),
 					zap.String("allocation", alloc.String()))
+				sq.recordPlacement(true)
+				sq.headOfLineProgress(app.ApplicationID)
 				return alloc
 			}
 		}
+		// only an attempt with pending applications is a failed placement
+		if len(apps) != 0 {
+			sq.recordPlacement(false)
+		}
 	} else {
 		// process the child queues (filters out queues without pending requests)
 		for _, child := range sq.sortQueues() {
+			// a queue backed off due to placement failures is not tried until the back off expires
+			if child.IsPlacementBackedOff() {
+				continue
+			}
 			alloc := child.TryAllocate(iterator)
 			if alloc != nil {
 				return alloc
@@ -955,6 +1761,39 @@ func (sq *Queue) TryAllocate(iterator func() interfaces.NodeIterator) *Allocatio
 	return nil
 }
 
+// Return the applications that can be allocated in the order they are sorted.
+// A strict fifo queue only allocates the head application, unless the head application has not made progress for
+// longer than the head of line timeout. All applications are returned for other sort policies.
+func (sq *Queue) filterHeadOfLine(apps []*Application) []*Application {
+	sq.Lock()
+	defer sq.Unlock()
+	if sq.sortType != policies.StrictFifoPolicy || len(apps) == 0 {
+		return apps
+	}
+	head := apps[0].ApplicationID
+	if sq.headOfLine.applicationID != head {
+		sq.headOfLine.applicationID = head
+		sq.headOfLine.progress = time.Now()
+	}
+	if sq.headOfLine.timeout > 0 && time.Since(sq.headOfLine.progress) >= sq.headOfLine.timeout {
+		log.Logger().Debug("head of line application blocked, considering all applications",
+			zap.String("queueName", sq.QueuePath),
+			zap.String("appID", head))
+		return apps
+	}
+	return apps[:1]
+}
+
+// Record an allocation for the application: an allocation for the head application of a strict fifo queue resets
+// the head of line timeout.
+func (sq *Queue) headOfLineProgress(appID string) {
+	sq.Lock()
+	defer sq.Unlock()
+	if sq.headOfLine.applicationID == appID {
+		sq.headOfLine.progress = time.Now()
+	}
+}
+
 // Try replace placeholder allocations. This only gets called if there is a pending request on this queue or its children.
 // This is a depth first algorithm: descend into the depth of the queue tree first. Child queues are sorted based on
 // the configured queue sortPolicy. Queues without pending resources are skipped.
@@ -1002,6 +1841,23 @@ func (sq *Queue) GetQueueOutstandingRequests(total *[]*AllocationAsk) {
 	}
 }
 
+// Append the leaf queues below this queue that a serial TryAllocate would visit to the passed slice, in the
+// order of that visit. Queues without pending resources and queues backed off due to placement failures are
+// skipped. When called on a leaf queue the queue itself is appended without checking its pending resources.
+// Lock free call all locks are taken when needed in called functions
+func (sq *Queue) GetPendingLeafQueues(leafs *[]*Queue) {
+	if sq.IsLeafQueue() {
+		*leafs = append(*leafs, sq)
+		return
+	}
+	for _, child := range sq.sortQueues() {
+		if child.IsPlacementBackedOff() {
+			continue
+		}
+		child.GetPendingLeafQueues(leafs)
+	}
+}
+
 // Try allocate reserved requests. This only gets called if there is a pending request on this queue or its children.
 // This is a depth first algorithm: descend into the depth of the queue tree first. Child queues are sorted based on
 // the configured queue sortPolicy. Queues without pending resources are skipped.
@@ -1096,6 +1952,13 @@ func (sq *Queue) getApplication(appID string) *Application {
 	return sq.applications[appID]
 }
 
+// Return the weight of the queue used when sorting the queue against its siblings.
+func (sq *Queue) GetWeight() float64 {
+	sq.RLock()
+	defer sq.RUnlock()
+	return sq.weight
+}
+
 // get the queue sort type holding a lock
 func (sq *Queue) getSortType() policies.SortPolicy {
 	sq.RLock()
@@ -1103,8 +1966,23 @@ func (sq *Queue) getSortType() policies.SortPolicy {
 	return sq.sortType
 }
 
+// Return the tags used to boost and demote applications in the queue.
+func (sq *Queue) getTagPriority() (*appTag, *appTag) {
+	sq.RLock()
+	defer sq.RUnlock()
+	return sq.boostTag, sq.demoteTag
+}
+
+// Return the node taints tolerated by all asks in the queue.
+// Should be treated as read only not to be modified
+func (sq *Queue) getTolerations() map[string]string {
+	sq.RLock()
+	defer sq.RUnlock()
+	return sq.tolerations
+}
+
 // Can the queue support task groups based on the sorting policy
-// FIFO and StateAware can support this
+// FIFO, StateAware and StrictFIFO can support this
 // NOTE: this call does not make sense for a parent queue, and always returns false
 func (sq *Queue) SupportTaskGroup() bool {
 	sq.RLock()
@@ -1112,7 +1990,7 @@ func (sq *Queue) SupportTaskGroup() bool {
 	if !sq.isLeaf {
 		return false
 	}
-	return sq.sortType == policies.FifoSortPolicy || sq.sortType == policies.StateAwarePolicy
+	return sq.sortType == policies.FifoSortPolicy || sq.sortType == policies.StateAwarePolicy || sq.sortType == policies.StrictFifoPolicy
 }
 
 // update queue metrics when this is a leaf queue
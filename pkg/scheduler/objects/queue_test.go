@@ -23,11 +23,15 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"gotest.tools/assert"
 
 	"github.com/apache/incubator-yunikorn-core/pkg/common/configs"
 	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+	"github.com/apache/incubator-yunikorn-core/pkg/common/security"
+	"github.com/apache/incubator-yunikorn-core/pkg/plugins"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/policies"
 	"github.com/apache/incubator-yunikorn-core/pkg/webservice/dao"
 )
 
@@ -41,7 +45,7 @@ func TestQueueBasics(t *testing.T) {
 		t.Error("root queue status is incorrect")
 	}
 	// allocations should be nil
-	if !resources.IsZero(root.preempting) && !resources.IsZero(root.pending) {
+	if !resources.IsZero(root.preempting) && !resources.IsZero(root.GetPendingResource()) {
 		t.Error("root queue must not have allocations set on create")
 	}
 }
@@ -153,6 +157,74 @@ func TestDynamicSubQueues(t *testing.T) {
 	}
 }
 
+func TestDynamicQueueTemplate(t *testing.T) {
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	conf := configs.QueueConfig{
+		Name:   "users",
+		Parent: true,
+		ChildTemplate: configs.ChildTemplate{
+			Resources: configs.Resources{
+				Max:        map[string]string{"memory": "100"},
+				Guaranteed: map[string]string{"memory": "10"},
+			},
+			Properties: map[string]string{configs.ApplicationSortPolicy: "fair"},
+			SubmitACL:  "*",
+		},
+	}
+	var users *Queue
+	users, err = NewConfiguredQueue(conf, root)
+	assert.NilError(t, err, "failed to create parent queue with template")
+	assert.Assert(t, users.template != nil, "template should be set on the configured parent")
+	assert.Assert(t, users.GetMaxResource() == nil, "template should not be applied to the configured parent")
+
+	// dynamic leaf gets the template applied
+	var leaf *Queue
+	leaf, err = createDynamicQueue(users, "alice", false)
+	assert.NilError(t, err, "failed to create dynamic leaf queue")
+	max := resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 100})
+	assert.Assert(t, resources.Equals(leaf.GetMaxResource(), max), "max not set from template: %s", leaf.GetMaxResource())
+	guaranteed := resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 10})
+	assert.Assert(t, resources.Equals(leaf.GetGuaranteedResource(), guaranteed), "guaranteed not set from template: %s", leaf.GetGuaranteedResource())
+	assert.Equal(t, leaf.getSortType(), policies.FairSortPolicy, "sort policy not set from template properties")
+	assert.Assert(t, leaf.submitACL.CheckAccess(security.UserGroup{User: "bob"}), "submit ACL not set from template")
+
+	// dynamic parent passes the template on without applying it
+	var parent *Queue
+	parent, err = createDynamicQueue(users, "group", true)
+	assert.NilError(t, err, "failed to create dynamic parent queue")
+	assert.Assert(t, parent.GetMaxResource() == nil, "template should not be applied to a dynamic parent")
+	assert.Equal(t, parent.template, users.template, "template not inherited by dynamic parent")
+	leaf, err = createDynamicQueue(parent, "bob", false)
+	assert.NilError(t, err, "failed to create dynamic leaf queue")
+	assert.Assert(t, resources.Equals(leaf.GetMaxResource(), max), "max not set from inherited template: %s", leaf.GetMaxResource())
+
+	// no template: no limits on the dynamic leaf
+	leaf, err = createDynamicQueue(root, "nolimit", false)
+	assert.NilError(t, err, "failed to create dynamic leaf queue")
+	assert.Assert(t, leaf.GetMaxResource() == nil, "max should not be set without a template")
+	assert.Assert(t, leaf.GetGuaranteedResource() == nil, "guaranteed should not be set without a template")
+}
+
+func TestIsIdle(t *testing.T) {
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	assert.Assert(t, !root.IsIdle(0), "parent queue should never be idle")
+	var leaf *Queue
+	leaf, err = createDynamicQueue(root, "leaf", false)
+	assert.NilError(t, err, "failed to create leaf queue")
+	assert.Assert(t, leaf.IsIdle(0), "empty leaf should be idle without timeout")
+	assert.Assert(t, !leaf.IsIdle(time.Hour), "new leaf should not be idle before the timeout")
+
+	app := newApplication(appID1, "default", "root.leaf")
+	leaf.AddApplication(app)
+	assert.Assert(t, !leaf.IsIdle(0), "leaf with an application should not be idle")
+	leaf.RemoveApplication(app)
+	assert.Assert(t, leaf.IsIdle(0), "leaf should be idle after removing the application")
+	leaf.lastActive = time.Now().Add(-2 * time.Hour)
+	assert.Assert(t, leaf.IsIdle(time.Hour), "leaf should be idle after the timeout")
+}
+
 func TestPendingCalc(t *testing.T) {
 	// create the root
 	root, err := createRootQueue(nil)
@@ -166,29 +238,29 @@ func TestPendingCalc(t *testing.T) {
 	allocRes, err = resources.NewResourceFromConf(res)
 	assert.NilError(t, err, "failed to create basic resource")
 	leaf.incPendingResource(allocRes)
-	if !resources.Equals(root.pending, allocRes) {
-		t.Errorf("root queue pending allocation failed to increment expected %v, got %v", allocRes, root.pending)
+	if !resources.Equals(root.GetPendingResource(), allocRes) {
+		t.Errorf("root queue pending allocation failed to increment expected %v, got %v", allocRes, root.GetPendingResource())
 	}
-	if !resources.Equals(leaf.pending, allocRes) {
-		t.Errorf("leaf queue pending allocation failed to increment expected %v, got %v", allocRes, leaf.pending)
+	if !resources.Equals(leaf.GetPendingResource(), allocRes) {
+		t.Errorf("leaf queue pending allocation failed to increment expected %v, got %v", allocRes, leaf.GetPendingResource())
 	}
 	leaf.decPendingResource(allocRes)
-	if !resources.IsZero(root.pending) {
-		t.Errorf("root queue pending allocation failed to decrement expected 0, got %v", root.pending)
+	if !resources.IsZero(root.GetPendingResource()) {
+		t.Errorf("root queue pending allocation failed to decrement expected 0, got %v", root.GetPendingResource())
 	}
-	if !resources.IsZero(leaf.pending) {
-		t.Errorf("leaf queue pending allocation failed to decrement expected 0, got %v", leaf.pending)
+	if !resources.IsZero(leaf.GetPendingResource()) {
+		t.Errorf("leaf queue pending allocation failed to decrement expected 0, got %v", leaf.GetPendingResource())
 	}
 	// Not allowed to go negative: both will be zero after this
 	newRes := resources.Multiply(allocRes, 2)
-	root.pending = newRes
+	leaf.incPendingResource(allocRes)
 	leaf.decPendingResource(newRes)
 	// using the get function to access the value
 	if !resources.IsZero(root.GetPendingResource()) {
-		t.Errorf("root queue pending allocation failed to decrement expected zero, got %v", root.pending)
+		t.Errorf("root queue pending allocation failed to decrement expected zero, got %v", root.GetPendingResource())
 	}
 	if !resources.IsZero(leaf.GetPendingResource()) {
-		t.Errorf("leaf queue pending allocation should have failed to decrement expected zero, got %v", leaf.pending)
+		t.Errorf("leaf queue pending allocation should have failed to decrement expected zero, got %v", leaf.GetPendingResource())
 	}
 }
 
@@ -243,7 +315,7 @@ func TestAddApplication(t *testing.T) {
 	// adding the app must not update pending resources
 	leaf.AddApplication(app)
 	assert.Equal(t, len(leaf.applications), 1, "Application was not added to the queue as expected")
-	assert.Assert(t, resources.IsZero(leaf.pending), "leaf queue pending resource not zero")
+	assert.Assert(t, resources.IsZero(leaf.GetPendingResource()), "leaf queue pending resource not zero")
 
 	// add the same app again should not increase the number of apps
 	leaf.AddApplication(app)
@@ -321,7 +393,7 @@ func TestRemoveApplication(t *testing.T) {
 	app := newApplication("exists", "default", "root.leaf-man")
 	leaf.AddApplication(app)
 	assert.Equal(t, len(leaf.applications), 1, "Application was not added to the queue as expected")
-	assert.Assert(t, resources.IsZero(leaf.pending), "leaf queue pending resource not zero")
+	assert.Assert(t, resources.IsZero(leaf.GetPendingResource()), "leaf queue pending resource not zero")
 	leaf.RemoveApplication(nonExist)
 	assert.Equal(t, len(leaf.applications), 1, "Non existing application was removed from the queue")
 	leaf.RemoveApplication(app)
@@ -332,13 +404,13 @@ func TestRemoveApplication(t *testing.T) {
 	app.pending.AddTo(res)
 	leaf.AddApplication(app)
 	assert.Equal(t, len(leaf.applications), 1, "Application was not added to the queue as expected")
-	assert.Assert(t, resources.IsZero(leaf.pending), "leaf queue pending resource not zero")
+	assert.Assert(t, resources.IsZero(leaf.GetPendingResource()), "leaf queue pending resource not zero")
 	// update pending resources for the hierarchy
 	leaf.incPendingResource(res)
 	leaf.RemoveApplication(app)
 	assert.Equal(t, len(leaf.applications), 0, "Application was not removed from the queue as expected")
-	assert.Assert(t, resources.IsZero(leaf.pending), "leaf queue pending resource not updated correctly")
-	assert.Assert(t, resources.IsZero(root.pending), "root queue pending resource not updated correctly")
+	assert.Assert(t, resources.IsZero(leaf.GetPendingResource()), "leaf queue pending resource not updated correctly")
+	assert.Assert(t, resources.IsZero(root.GetPendingResource()), "root queue pending resource not updated correctly")
 
 	app.allocatedResource.AddTo(res)
 	app.pending = resources.NewResource()
@@ -477,6 +549,156 @@ func TestSortApplications(t *testing.T) {
 	}
 }
 
+func TestSortApplicationsTagPriority(t *testing.T) {
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	props := map[string]string{
+		configs.ApplicationSortPolicy: "fifo",
+		configs.ApplicationBoostTag:   "priority=critical",
+		configs.ApplicationDemoteTag:  "batch",
+	}
+	var leaf *Queue
+	leaf, err = createManagedQueueWithProps(root, "leaf", false, nil, props)
+	assert.NilError(t, err, "failed to create leaf queue")
+	boost, demote := leaf.getTagPriority()
+	assert.Assert(t, boost != nil && boost.key == "priority" && boost.value == "critical", "boost tag not set: %v", boost)
+	assert.Assert(t, demote != nil && demote.key == "batch" && demote.value == "", "demote tag not set: %v", demote)
+
+	res, err := resources.NewResourceFromConf(map[string]string{"first": "1"})
+	assert.NilError(t, err, "failed to create basic resource")
+	tags := []map[string]string{
+		{"batch": "true"},
+		nil,
+		{"priority": "critical"},
+		{"priority": "low"},
+	}
+	for i, tag := range tags {
+		appID := "app-" + strconv.Itoa(i)
+		app := newApplicationWithTags(appID, "default", leaf.QueuePath, tag)
+		app.queue = leaf
+		app.SubmissionTime = time.Now().Add(time.Duration(i-10) * time.Second)
+		leaf.AddApplication(app)
+		err = app.AddAllocationAsk(newAllocationAsk("alloc-"+appID, appID, res))
+		assert.NilError(t, err, "failed to add allocation ask")
+	}
+	// fifo would return 0, 1, 2, 3: boosted app-2 moves to the front, demoted app-0 to the back
+	apps := leaf.sortApplications(true)
+	assertAppList(t, apps, []int{3, 1, 0, 2}, "tag sorted apps")
+
+	// dynamic leaf queues inherit the tags from the parent
+	var parent, dynamic *Queue
+	parent, err = createManagedQueueWithProps(root, "parent", true, nil, props)
+	assert.NilError(t, err, "failed to create parent queue")
+	dynamic, err = createDynamicQueue(parent, "dynamic", false)
+	assert.NilError(t, err, "failed to create dynamic queue")
+	boost, demote = dynamic.getTagPriority()
+	assert.Assert(t, boost != nil && demote != nil, "dynamic queue should have inherited the tags")
+}
+
+func TestSortApplicationsRecovered(t *testing.T) {
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	var leaf *Queue
+	leaf, err = createManagedQueueWithProps(root, "leaf", false, nil, map[string]string{configs.ApplicationSortPolicy: "fifo"})
+	assert.NilError(t, err, "failed to create leaf queue")
+	res, err := resources.NewResourceFromConf(map[string]string{"first": "1"})
+	assert.NilError(t, err, "failed to create basic resource")
+	for i := 0; i < 4; i++ {
+		appID := "app-" + strconv.Itoa(i)
+		app := newApplication(appID, "default", leaf.QueuePath)
+		app.queue = leaf
+		app.SubmissionTime = time.Now().Add(time.Duration(i-10) * time.Second)
+		leaf.AddApplication(app)
+		if i == 3 {
+			app.RecoverAllocationAsk(newAllocationAsk("alloc-recovered", appID, res))
+		}
+		err = app.AddAllocationAsk(newAllocationAsk("alloc-"+appID, appID, res))
+		assert.NilError(t, err, "failed to add allocation ask")
+	}
+	// prioritization disabled: plain fifo order
+	assertAppList(t, leaf.sortApplications(true), []int{0, 1, 2, 3}, "recovery disabled")
+	// recovered app-3 moves to the front while the window is open
+	root.SetRecoveryPriority(time.Minute, 0)
+	assertAppList(t, leaf.sortApplications(true), []int{1, 2, 3, 0}, "recovered app first")
+}
+
+func TestUpdateSortType(t *testing.T) {
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	var leaf *Queue
+	leaf, err = createManagedQueueWithProps(root, "leaf", false, nil, map[string]string{configs.ApplicationSortPolicy: "priority"})
+	assert.NilError(t, err, "failed to create leaf queue")
+	assert.Equal(t, leaf.getSortType(), policies.PriorityPolicy, "leaf queue sort policy not set from property")
+	assert.Equal(t, root.getSortType(), policies.FairSortPolicy, "parent queue should always be fair sorted")
+
+	// config reload changes the policy, an unknown policy falls back to fifo
+	conf := configs.QueueConfig{Name: "leaf", Properties: map[string]string{configs.ApplicationSortPolicy: "fair"}}
+	err = leaf.SetQueueConfig(conf)
+	assert.NilError(t, err, "failed to update leaf queue config")
+	leaf.UpdateSortType()
+	assert.Equal(t, leaf.getSortType(), policies.FairSortPolicy, "leaf queue sort policy not updated")
+	conf.Properties[configs.ApplicationSortPolicy] = "unknown"
+	err = leaf.SetQueueConfig(conf)
+	assert.NilError(t, err, "failed to update leaf queue config")
+	leaf.UpdateSortType()
+	assert.Equal(t, leaf.getSortType(), policies.FifoSortPolicy, "unknown sort policy should fall back to fifo")
+}
+
+func TestResourceExpressions(t *testing.T) {
+	// root without a limit: the partition has no resources yet, expressions cannot be resolved
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	var parent, leaf *Queue
+	parent, err = createManagedQueue(root, "parent", true, map[string]string{"memory": "50%"})
+	assert.NilError(t, err, "failed to create parent queue")
+	assert.Assert(t, parent.GetMaxResource() == nil, "expression without a parent limit should not be resolved")
+	conf := configs.QueueConfig{Name: "leaf", Resources: configs.Resources{
+		Max:        map[string]string{"memory": "parent - 100", "vcore": "10"},
+		Guaranteed: map[string]string{"memory": "10% of parent"},
+	}}
+	leaf, err = NewConfiguredQueue(conf, parent)
+	assert.NilError(t, err, "failed to create leaf queue")
+	assert.Assert(t, resources.Equals(leaf.GetMaxResource(), resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 10})), "only the fixed quantity should be set")
+
+	// the partition resources change: expressions in the hierarchy are resolved
+	root.SetMaxResource(resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 2000, "vcore": 100}))
+	assert.Assert(t, resources.Equals(parent.GetMaxResource(), resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 1000})), "unexpected parent max")
+	max, _ := leaf.GetLimits()
+	assert.Assert(t, resources.Equals(max, resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 900, "vcore": 10})), "unexpected leaf max")
+	assert.Assert(t, resources.Equals(leaf.GetGuaranteedResource(), resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 100})), "unexpected leaf guaranteed")
+
+	// configuration update resolves against the current parent limit
+	conf.Resources.Max = map[string]string{"memory": "parent"}
+	err = leaf.SetQueueConfig(conf)
+	assert.NilError(t, err, "failed to update leaf queue config")
+	assert.Assert(t, resources.Equals(leaf.GetMaxResource(), resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 1000})), "unexpected leaf max after update")
+
+	// fixed limits replace the expressions of the queue, children are resolved against the new limit
+	parent.SetLimits(resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 500}), nil)
+	assert.Assert(t, resources.Equals(leaf.GetGuaranteedResource(), resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 50})), "leaf not resolved against the parent limit")
+	root.SetMaxResource(resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 4000}))
+	max, _ = parent.GetLimits()
+	assert.Assert(t, resources.Equals(max, resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 500})), "fixed limit should not be replaced by the expression")
+}
+
+func TestQueueTolerations(t *testing.T) {
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	props := map[string]string{configs.QueueTolerations: "dedicated=gpu,maintenance"}
+	var parent, leaf, dynamic *Queue
+	leaf, err = createManagedQueueWithProps(root, "leaf", false, nil, props)
+	assert.NilError(t, err, "failed to create leaf queue")
+	assert.DeepEqual(t, leaf.getTolerations(), map[string]string{"dedicated": "gpu", "maintenance": ""})
+	assert.Assert(t, root.getTolerations() == nil, "parent queue should not have tolerations")
+
+	// dynamic leaf queues inherit the tolerations from the parent
+	parent, err = createManagedQueueWithProps(root, "parent", true, nil, props)
+	assert.NilError(t, err, "failed to create parent queue")
+	dynamic, err = createDynamicQueue(parent, "dynamic", false)
+	assert.NilError(t, err, "failed to create dynamic queue")
+	assert.DeepEqual(t, dynamic.getTolerations(), map[string]string{"dedicated": "gpu", "maintenance": ""})
+}
+
 func TestSortApplicationsWithoutFiltering(t *testing.T) {
 	// create the root
 	root, err := createRootQueue(nil)
@@ -832,6 +1054,30 @@ func TestGetMaxUsage(t *testing.T) {
 	}
 }
 
+func TestGetLimitingQueue(t *testing.T) {
+	root, err := createRootQueue(map[string]string{"first": "10"})
+	assert.NilError(t, err, "queue create failed")
+	var parent, leaf *Queue
+	parent, err = createManagedQueue(root, "parent", true, nil)
+	assert.NilError(t, err, "failed to create parent queue")
+	leaf, err = createManagedQueue(parent, "leaf", false, nil)
+	assert.NilError(t, err, "failed to create leaf queue")
+	// root limit is ignored and the parent has no limit
+	assert.Equal(t, leaf.GetLimitingQueue(), leaf, "leaf should be its own limiting queue")
+
+	var limited, sub *Queue
+	limited, err = createManagedQueue(root, "limited", true, map[string]string{"first": "5"})
+	assert.NilError(t, err, "failed to create limited parent queue")
+	sub, err = createManagedQueue(limited, "sub", true, map[string]string{"first": "2"})
+	assert.NilError(t, err, "failed to create limited sub queue")
+	leaf, err = createManagedQueue(sub, "leaf", false, map[string]string{"first": "1"})
+	assert.NilError(t, err, "failed to create leaf queue")
+	// highest limited queue below the root is returned
+	assert.Equal(t, leaf.GetLimitingQueue(), limited, "limited parent should be the limiting queue")
+	assert.Equal(t, sub.GetLimitingQueue(), limited, "limited parent should be the limiting queue of the sub queue")
+	assert.Equal(t, root.GetLimitingQueue(), root, "root should be its own limiting queue")
+}
+
 func TestGetMaxQueueSet(t *testing.T) {
 	// create the root
 	root, err := createRootQueue(nil)
@@ -1282,6 +1528,34 @@ func TestGetQueueInfoPropertiesSet(t *testing.T) {
 	}
 }
 
+func TestGetQueueInfoPendingReserved(t *testing.T) {
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "failed to create basic root queue: %v", err)
+	var parent, leaf1, leaf2 *Queue
+	parent, err = createManagedQueue(root, "parent", true, nil)
+	assert.NilError(t, err, "failed to create queue: %v", err)
+	leaf1, err = createManagedQueue(parent, "leaf1", false, nil)
+	assert.NilError(t, err, "failed to create queue: %v", err)
+	leaf2, err = createManagedQueue(parent, "leaf2", false, nil)
+	assert.NilError(t, err, "failed to create queue: %v", err)
+
+	var pending *resources.Resource
+	pending, err = resources.NewResourceFromConf(map[string]string{"memory": "10"})
+	assert.NilError(t, err, "failed to create resource: %v", err)
+	leaf1.incPendingResource(pending)
+	leaf2.incPendingResource(pending)
+	leaf1.Reserve("app-1")
+	leaf1.Reserve("app-1")
+	leaf2.Reserve("app-2")
+
+	rootDaoInfo := root.GetQueueInfos()
+	assert.Equal(t, rootDaoInfo.Capacities.PendingCapacity, "[memory:20]")
+	assert.Equal(t, rootDaoInfo.ReservedApps, 2, "reserved applications of the children should be counted once")
+	leafDaoInfo := leaf1.GetQueueInfos()
+	assert.Equal(t, leafDaoInfo.Capacities.PendingCapacity, "[memory:10]")
+	assert.Equal(t, leafDaoInfo.ReservedApps, 1)
+}
+
 func compareQueueInfoWithDAO(t *testing.T, queue *Queue, dao dao.QueueDAOInfo) {
 	assert.Equal(t, queue.Name, dao.QueueName)
 	assert.Equal(t, len(queue.children), len(dao.ChildQueues))
@@ -1336,4 +1610,399 @@ func TestSupportTaskGroup(t *testing.T) {
 	leaf, err = createManagedQueueWithProps(parent, "leaf3", false, nil, properties)
 	assert.NilError(t, err, "failed to create queue: %v", err)
 	assert.Assert(t, !leaf.SupportTaskGroup(), "leaf queue (FAIR policy) should not support task group")
+
+	properties = map[string]string{configs.ApplicationSortPolicy: "strictfifo"}
+	leaf, err = createManagedQueueWithProps(parent, "leaf4", false, nil, properties)
+	assert.NilError(t, err, "failed to create queue: %v", err)
+	assert.Assert(t, leaf.SupportTaskGroup(), "leaf queue (StrictFIFO policy) should support task group")
+}
+
+func TestFilterHeadOfLine(t *testing.T) {
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	var leaf *Queue
+	leaf, err = createManagedQueueWithProps(root, "leaf", false, nil, map[string]string{configs.ApplicationSortPolicy: "strictfifo"})
+	assert.NilError(t, err, "failed to create leaf queue")
+	assert.Equal(t, leaf.getSortType(), policies.StrictFifoPolicy, "leaf queue sort policy not set from property")
+
+	app1 := newApplication(appID1, "default", "root.leaf")
+	app2 := newApplication(appID2, "default", "root.leaf")
+	apps := []*Application{app1, app2}
+	assert.Equal(t, len(leaf.filterHeadOfLine(nil)), 0, "empty list should not be changed")
+	filtered := leaf.filterHeadOfLine(apps)
+	assert.Equal(t, len(filtered), 1, "only the head application should be returned")
+	assert.Equal(t, filtered[0], app1, "unexpected head application")
+
+	// head of line timeout elapsed: all applications are returned until the head makes progress
+	leaf.headOfLine.timeout = time.Minute
+	leaf.headOfLine.progress = time.Now().Add(-2 * time.Minute)
+	assert.Equal(t, len(leaf.filterHeadOfLine(apps)), 2, "blocked head should allow all applications")
+	leaf.headOfLineProgress(appID2)
+	assert.Equal(t, len(leaf.filterHeadOfLine(apps)), 2, "progress of other application should not reset the timeout")
+	leaf.headOfLineProgress(appID1)
+	assert.Equal(t, len(leaf.filterHeadOfLine(apps)), 1, "progress of the head application should reset the timeout")
+
+	// a new head application starts a new timeout
+	leaf.headOfLine.progress = time.Now().Add(-2 * time.Minute)
+	filtered = leaf.filterHeadOfLine([]*Application{app2})
+	assert.Equal(t, len(filtered), 1, "new head application should be returned")
+	assert.Equal(t, leaf.headOfLine.applicationID, appID2, "head application not updated")
+	assert.Equal(t, len(leaf.filterHeadOfLine(apps)), 1, "new head application should reset the timeout")
+
+	// timeout is read from the properties
+	conf := configs.QueueConfig{Name: "leaf", Properties: map[string]string{
+		configs.ApplicationSortPolicy:        "strictfifo",
+		configs.ApplicationHeadOfLineTimeout: "30s",
+	}}
+	err = leaf.SetQueueConfig(conf)
+	assert.NilError(t, err, "failed to update leaf queue config")
+	leaf.UpdateSortType()
+	assert.Equal(t, leaf.headOfLine.timeout, 30*time.Second, "head of line timeout not set from property")
+	conf.Properties[configs.ApplicationHeadOfLineTimeout] = "-1s"
+	err = leaf.SetQueueConfig(conf)
+	assert.NilError(t, err, "failed to update leaf queue config")
+	leaf.UpdateSortType()
+	assert.Equal(t, leaf.headOfLine.timeout, time.Duration(0), "negative head of line timeout should be ignored")
+
+	// other policies are not filtered
+	conf.Properties[configs.ApplicationSortPolicy] = "fifo"
+	err = leaf.SetQueueConfig(conf)
+	assert.NilError(t, err, "failed to update leaf queue config")
+	leaf.UpdateSortType()
+	assert.Equal(t, len(leaf.filterHeadOfLine(apps)), 2, "fifo queue should not filter applications")
+}
+
+type fakeGroupHierarchy struct{}
+
+func (f *fakeGroupHierarchy) GetParentGroups(group string) []string {
+	if group == "dev" {
+		return []string{"eng"}
+	}
+	return nil
+}
+
+func TestCheckSubmitAccess(t *testing.T) {
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "failed to create basic root queue: %v", err)
+	var leaf *Queue
+	leaf, err = createManagedQueue(root, "leaf", false, nil)
+	assert.NilError(t, err, "failed to create leaf queue: %v", err)
+	err = leaf.SetQueueConfig(configs.QueueConfig{Name: "leaf", SubmitACL: "team-*"})
+	assert.NilError(t, err, "failed to set leaf queue config: %v", err)
+	bob := security.UserGroup{User: "team-bob"}
+	alice := security.UserGroup{User: "alice"}
+	assert.Assert(t, leaf.CheckSubmitAccess(bob), "wildcard user should have access")
+	assert.Assert(t, !leaf.CheckSubmitAccess(alice), "user should not have access")
+
+	// results are cached: a change of the ACL outside of the config is not seen
+	root.submitACL, err = security.NewACL("alice")
+	assert.NilError(t, err, "failed to create ACL: %v", err)
+	assert.Assert(t, !leaf.CheckSubmitAccess(alice), "cached result should have been returned")
+	// a config change clears the cache
+	err = root.SetQueueConfig(configs.QueueConfig{Name: "root", Parent: true, SubmitACL: "alice"})
+	assert.NilError(t, err, "failed to set root queue config: %v", err)
+	assert.Assert(t, leaf.CheckSubmitAccess(alice), "user should have access after the config change")
+
+	// nested groups from the hierarchy plugin
+	err = leaf.SetQueueConfig(configs.QueueConfig{Name: "leaf", SubmitACL: " eng"})
+	assert.NilError(t, err, "failed to set leaf queue config: %v", err)
+	dev := security.UserGroup{User: "carol", Groups: []string{"dev"}}
+	assert.Assert(t, !leaf.CheckSubmitAccess(dev), "user without the nested group should not have access")
+	assert.Assert(t, !leaf.CheckAdminAccess(dev), "user should not have admin access")
+	plugins.RegisterSchedulerPlugin(&fakeGroupHierarchy{})
+	root.accessCache.Clear()
+	assert.Assert(t, leaf.CheckSubmitAccess(dev), "user in a nested group should have access")
+}
+
+func TestAppRetention(t *testing.T) {
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "failed to create basic root queue")
+	props := map[string]string{
+		configs.ApplicationRetentionCount:  "5",
+		configs.ApplicationRetentionAge:    "10m",
+		configs.ApplicationRetentionExport: "True",
+	}
+	var leaf *Queue
+	leaf, err = createManagedQueueWithProps(root, "leaf", false, nil, props)
+	assert.NilError(t, err, "failed to create leaf queue")
+	retention := leaf.GetAppRetention()
+	assert.Equal(t, retention.MaxCount, 5, "unexpected retention count")
+	assert.Equal(t, retention.MaxAge, 10*time.Minute, "unexpected retention age")
+	assert.Assert(t, retention.Export, "export should have been set")
+
+	// invalid values are ignored
+	props = map[string]string{
+		configs.ApplicationRetentionCount:  "-1",
+		configs.ApplicationRetentionAge:    "unknown",
+		configs.ApplicationRetentionExport: "yes",
+	}
+	leaf, err = createManagedQueueWithProps(root, "invalid", false, nil, props)
+	assert.NilError(t, err, "failed to create leaf queue")
+	assert.Equal(t, leaf.GetAppRetention(), AppRetention{}, "invalid retention should not be set")
+}
+
+func TestGetAccessRule(t *testing.T) {
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "failed to create basic root queue: %v", err)
+	err = root.SetQueueConfig(configs.QueueConfig{Name: "root", Parent: true, AdminACL: "admin"})
+	assert.NilError(t, err, "failed to set root queue config: %v", err)
+	var leaf *Queue
+	leaf, err = createManagedQueue(root, "leaf", false, nil)
+	assert.NilError(t, err, "failed to create leaf queue: %v", err)
+	err = leaf.SetQueueConfig(configs.QueueConfig{Name: "leaf", SubmitACL: "bob dev", AdminACL: "alice"})
+	assert.NilError(t, err, "failed to set leaf queue config: %v", err)
+
+	rule := leaf.GetSubmitAccessRule(security.UserGroup{User: "bob"})
+	assert.Equal(t, *rule, AccessRule{QueuePath: "root.leaf", ACL: SubmitACLName, Entry: "user:bob"}, "unexpected submit rule")
+	rule = leaf.GetSubmitAccessRule(security.UserGroup{User: "carol", Groups: []string{"dev"}})
+	assert.Equal(t, *rule, AccessRule{QueuePath: "root.leaf", ACL: SubmitACLName, Entry: "group:dev"}, "unexpected group submit rule")
+	rule = leaf.GetSubmitAccessRule(security.UserGroup{User: "alice"})
+	assert.Equal(t, *rule, AccessRule{QueuePath: "root.leaf", ACL: AdminACLName, Entry: "user:alice"}, "admin should have submit access")
+	rule = leaf.GetSubmitAccessRule(security.UserGroup{User: "admin"})
+	assert.Equal(t, *rule, AccessRule{QueuePath: "root", ACL: AdminACLName, Entry: "user:admin"}, "parent admin should have submit access")
+	assert.Assert(t, leaf.GetSubmitAccessRule(security.UserGroup{User: "dave"}) == nil, "user should not have access")
+
+	assert.Assert(t, leaf.GetAdminAccessRule(security.UserGroup{User: "bob"}) == nil, "submit access should not give admin access")
+	rule = leaf.GetAdminAccessRule(security.UserGroup{User: "admin"})
+	assert.Equal(t, *rule, AccessRule{QueuePath: "root", ACL: AdminACLName, Entry: "user:admin"}, "unexpected admin rule")
+}
+
+func TestPriorityQuota(t *testing.T) {
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "failed to create basic root queue: %v", err)
+	var parent, leaf *Queue
+	parent, err = createManagedQueue(root, "parent", true, nil)
+	assert.NilError(t, err, "failed to create parent queue: %v", err)
+	err = parent.SetQueueConfig(configs.QueueConfig{
+		Name:          "parent",
+		Parent:        true,
+		PriorityQuota: configs.PriorityQuota{Threshold: 10, Max: map[string]string{"memory": "10"}},
+	})
+	assert.NilError(t, err, "failed to set parent queue config: %v", err)
+	leaf, err = createManagedQueue(parent, "leaf", false, nil)
+	assert.NilError(t, err, "failed to create leaf queue: %v", err)
+
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 6})
+	assert.Assert(t, leaf.fitsPriorityQuota(20, res), "empty quota should fit")
+	// usage is tracked by priority on the whole hierarchy
+	leaf.incPriorityAllocated(20, res)
+	leaf.incPriorityAllocated(5, res)
+	assert.Assert(t, resources.Equals(parent.GetPriorityQuotaUsage(), res), "only priorities above the threshold should be used")
+	assert.Equal(t, len(root.priorityAllocated), 2, "root should track both priorities")
+	assert.Assert(t, !leaf.fitsPriorityQuota(20, res), "ask should not fit in the parent priority quota")
+	assert.Assert(t, leaf.fitsPriorityQuota(10, res), "ask at the threshold should not be limited")
+
+	dao := parent.GetPartitionQueues()
+	assert.Assert(t, dao.PriorityQuota != nil, "priority quota should be exposed")
+	assert.Equal(t, dao.PriorityQuota.Threshold, int32(10), "unexpected threshold")
+	assert.Equal(t, dao.PriorityQuota.UsedResource, "[memory:6]", "unexpected usage")
+	assert.Assert(t, leaf.GetPartitionQueues().PriorityQuota == nil, "queue without quota should not expose it")
+
+	leaf.decPriorityAllocated(20, res)
+	assert.Assert(t, leaf.fitsPriorityQuota(20, res), "ask should fit after the release")
+	assert.Equal(t, len(leaf.priorityAllocated), 1, "released priority should have been removed")
+}
+
+func TestAllowedResourceTypes(t *testing.T) {
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "failed to create basic root queue: %v", err)
+	var parent, leaf *Queue
+	parent, err = createManagedQueue(root, "parent", true, nil)
+	assert.NilError(t, err, "failed to create parent queue: %v", err)
+	err = parent.SetQueueConfig(configs.QueueConfig{
+		Name:                 "parent",
+		Parent:               true,
+		AllowedResourceTypes: []string{"vcore", "memory"},
+	})
+	assert.NilError(t, err, "failed to set parent queue config: %v", err)
+	leaf, err = createManagedQueue(parent, "leaf", false, nil)
+	assert.NilError(t, err, "failed to create leaf queue: %v", err)
+
+	assert.NilError(t, leaf.CheckAllowedResourceTypes(nil), "nil resource should be allowed")
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 6, "vcore": 1, "gpu": 0})
+	assert.NilError(t, leaf.CheckAllowedResourceTypes(res), "allowed types and zero quantities should be allowed")
+	res.Resources["gpu"] = 1
+	err = leaf.CheckAllowedResourceTypes(res)
+	assert.ErrorContains(t, err, "resource type gpu is not allowed in queue root.parent")
+	assert.NilError(t, root.CheckAllowedResourceTypes(res), "root queue should allow all types")
+
+	dao := parent.GetPartitionQueues()
+	assert.DeepEqual(t, dao.AllowedResourceTypes, []string{"memory", "vcore"})
+	assert.Assert(t, leaf.GetPartitionQueues().AllowedResourceTypes == nil, "queue without restriction should not expose it")
+
+	// removing the restriction from the config allows all types again
+	err = parent.SetQueueConfig(configs.QueueConfig{Name: "parent", Parent: true})
+	assert.NilError(t, err, "failed to update parent queue config: %v", err)
+	assert.NilError(t, leaf.CheckAllowedResourceTypes(res), "all types should be allowed without restriction")
+}
+
+func TestSystemQueueSortedFirst(t *testing.T) {
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "failed to create root queue")
+	var leaf, system *Queue
+	leaf, err = createManagedQueue(root, "leaf", false, nil)
+	assert.NilError(t, err, "failed to create leaf queue")
+	system, err = createManagedQueue(root, "system", false, nil)
+	assert.NilError(t, err, "failed to create system queue")
+	sorted := orderSystemAndBackedOff([]*Queue{leaf, system})
+	assert.Equal(t, sorted[0], leaf, "order should not change without a system queue")
+	system.SetSystemQueue(true)
+	assert.Assert(t, system.IsSystemQueue(), "queue should be the system queue")
+	sorted = orderSystemAndBackedOff([]*Queue{leaf, system})
+	assert.Equal(t, sorted[0], system, "system queue should be sorted first")
+	assert.Equal(t, sorted[1], leaf, "leaf queue should be sorted after the system queue")
+}
+
+func TestContentionCap(t *testing.T) {
+	root, err := createRootQueue(map[string]string{"memory": "100"})
+	assert.NilError(t, err, "failed to create basic root queue: %v", err)
+	var capped, other *Queue
+	capped, err = createManagedQueue(root, "capped", false, nil)
+	assert.NilError(t, err, "failed to create leaf queue: %v", err)
+	err = capped.SetQueueConfig(configs.QueueConfig{
+		Name:          "capped",
+		Resources:     configs.Resources{Max: map[string]string{"memory": "80"}},
+		ContentionCap: configs.ContentionCap{Share: 0.25, PendingThreshold: 0.5},
+	})
+	assert.NilError(t, err, "failed to set leaf queue config: %v", err)
+	other, err = createManagedQueue(root, "other", false, nil)
+	assert.NilError(t, err, "failed to create leaf queue: %v", err)
+	capped.allocatedResource = resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 10})
+
+	// no contention: the max of the queue applies
+	contentionCap, clamped := capped.getContentionCap()
+	assert.Assert(t, !clamped, "partition should not be under contention")
+	assert.Assert(t, resources.Equals(contentionCap, resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 25})), "unexpected cap: %v", contentionCap)
+	assert.Assert(t, resources.Equals(capped.getHeadRoom(), resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 70})), "unexpected headroom: %v", capped.getHeadRoom())
+	assert.Assert(t, capped.GetLimitingQueue() == capped, "leaf queue should limit itself")
+
+	// pending at the threshold is not a contention
+	pending := resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 50})
+	other.incPendingResource(pending)
+	_, clamped = capped.getContentionCap()
+	assert.Assert(t, !clamped, "pending at the threshold should not be a contention")
+
+	// above the threshold the queue is clamped to its share
+	other.incPendingResource(resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 1}))
+	_, clamped = capped.getContentionCap()
+	assert.Assert(t, clamped, "partition should be under contention")
+	assert.Assert(t, resources.Equals(capped.getHeadRoom(), resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 15})), "unexpected headroom: %v", capped.getHeadRoom())
+	assert.Assert(t, resources.Equals(capped.getMaxHeadRoom(), resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 70})), "max headroom should ignore the cap: %v", capped.getMaxHeadRoom())
+	_, clamped = other.getContentionCap()
+	assert.Assert(t, !clamped, "queue without a cap should never be clamped")
+
+	dao := capped.GetPartitionQueues()
+	assert.Assert(t, dao.ContentionCap != nil, "contention cap should be exposed")
+	assert.Equal(t, dao.ContentionCap.Share, 0.25, "unexpected share")
+	assert.Equal(t, dao.ContentionCap.MaxResource, "[memory:25]", "unexpected cap")
+	assert.Assert(t, dao.ContentionCap.Clamped, "clamp state should be exposed")
+	assert.Assert(t, other.GetPartitionQueues().ContentionCap == nil, "queue without a cap should not expose it")
+
+	// the clamp is lifted when the pending resources drop
+	other.decPendingResource(pending)
+	_, clamped = capped.getContentionCap()
+	assert.Assert(t, !clamped, "partition should not be under contention after the pending resources dropped")
+	assert.Assert(t, !capped.GetPartitionQueues().ContentionCap.Clamped, "clamp state should be updated")
+}
+
+func TestIdleReclaimProperty(t *testing.T) {
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "failed to create root queue")
+	var leaf *Queue
+	leaf, err = createManagedQueue(root, "leaf", false, nil)
+	assert.NilError(t, err, "failed to create leaf queue")
+	assert.Assert(t, leaf.IsIdleReclaimAllowed(), "reclaim should be allowed by default")
+
+	props := map[string]string{configs.ApplicationIdleReclaim: "false"}
+	leaf, err = createManagedQueueWithProps(root, "optout", false, nil, props)
+	assert.NilError(t, err, "failed to create leaf queue")
+	assert.Assert(t, !leaf.IsIdleReclaimAllowed(), "reclaim should be disabled by the property")
+
+	// the property is removed on a config update
+	err = leaf.SetQueueConfig(configs.QueueConfig{Name: "optout"})
+	assert.NilError(t, err, "failed to update queue config")
+	leaf.UpdateSortType()
+	assert.Assert(t, leaf.IsIdleReclaimAllowed(), "reclaim should be allowed after removing the property")
+}
+
+func TestPreemptionFence(t *testing.T) {
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "failed to create root queue")
+	var tenant, inner, leafA, leafB, other *Queue
+	tenant, err = createManagedQueueWithProps(root, "tenant", true, nil, map[string]string{configs.PreemptionFence: "true"})
+	assert.NilError(t, err, "failed to create tenant queue")
+	leafA, err = createManagedQueue(tenant, "a", false, nil)
+	assert.NilError(t, err, "failed to create leaf queue")
+	// unsetting the fence inside a fenced subtree does not remove it
+	inner, err = createManagedQueueWithProps(tenant, "inner", true, nil, map[string]string{configs.PreemptionFence: "false"})
+	assert.NilError(t, err, "failed to create inner queue")
+	leafB, err = createManagedQueue(inner, "b", false, nil)
+	assert.NilError(t, err, "failed to create leaf queue")
+	other, err = createManagedQueue(root, "other", false, nil)
+	assert.NilError(t, err, "failed to create leaf queue")
+
+	assert.Assert(t, other.GetPreemptionFence() == nil, "unfenced queue should not return a fence")
+	assert.Equal(t, leafA.GetPreemptionFence(), tenant, "leaf should be fenced by the tenant queue")
+	assert.Equal(t, leafB.GetPreemptionFence(), tenant, "nested leaf should be fenced by the tenant queue")
+
+	assert.Assert(t, leafA.CanBePreemptedFrom("root.tenant.inner.b"), "demand inside the subtree should preempt")
+	assert.Assert(t, leafB.CanBePreemptedFrom("root.tenant.a"), "demand inside the subtree should preempt")
+	assert.Assert(t, leafA.CanBePreemptedFrom("root.tenant"), "demand of the fenced queue should preempt")
+	assert.Assert(t, !leafA.CanBePreemptedFrom("root.other"), "demand outside the subtree should not preempt")
+	assert.Assert(t, !leafA.CanBePreemptedFrom("root.tenantx"), "queue with the same prefix is outside the subtree")
+	assert.Assert(t, other.CanBePreemptedFrom("root.tenant.a"), "unfenced queue should be preempted by any demand")
+
+	// removing the property removes the fence, the config update is applied top down
+	err = tenant.SetQueueConfig(configs.QueueConfig{Name: "tenant", Parent: true})
+	assert.NilError(t, err, "failed to update queue config")
+	tenant.UpdateSortType()
+	err = leafA.SetQueueConfig(configs.QueueConfig{Name: "a"})
+	assert.NilError(t, err, "failed to update queue config")
+	leafA.UpdateSortType()
+	assert.Assert(t, leafA.CanBePreemptedFrom("root.other"), "fence should have been removed")
+}
+
+func TestSchedulingPaused(t *testing.T) {
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "failed to create root queue")
+	var parent, leaf, other *Queue
+	parent, err = createManagedQueueWithProps(root, "parent", true, nil, map[string]string{configs.SchedulingPaused: "true"})
+	assert.NilError(t, err, "failed to create parent queue")
+	leaf, err = createManagedQueue(parent, "leaf", false, nil)
+	assert.NilError(t, err, "failed to create leaf queue")
+	other, err = createManagedQueue(root, "other", false, nil)
+	assert.NilError(t, err, "failed to create leaf queue")
+	var res *resources.Resource
+	res, err = resources.NewResourceFromConf(map[string]string{"first": "1"})
+	assert.NilError(t, err, "failed to create basic resource")
+	leaf.incPendingResource(res)
+	other.incPendingResource(res)
+
+	assert.Assert(t, parent.IsSchedulingPaused(), "parent should be paused by the property")
+	assert.Assert(t, !other.IsSchedulingPaused(), "queue without the property should not be paused")
+	queues := root.sortQueues()
+	assert.Equal(t, len(queues), 1, "paused queue should be skipped")
+	assert.Equal(t, queues[0], other, "unexpected queue returned")
+	assert.Equal(t, len(parent.sortQueues()), 0, "nothing below a paused queue should be returned")
+	leafs := make([]*Queue, 0)
+	root.GetPendingLeafQueues(&leafs)
+	assert.Equal(t, len(leafs), 1, "paused leaf should not be pending")
+	assert.Equal(t, leafs[0], other, "unexpected leaf returned")
+
+	// pause and resume at runtime
+	parent.SetSchedulingPaused(false)
+	other.SetSchedulingPaused(true)
+	queues = root.sortQueues()
+	assert.Equal(t, len(queues), 1, "paused queue should be skipped")
+	assert.Equal(t, queues[0], parent, "unexpected queue returned")
+	assert.Assert(t, other.GetPartitionQueues().SchedulingPaused, "paused state should be reported")
+	assert.Assert(t, !parent.GetPartitionQueues().SchedulingPaused, "resumed state should be reported")
+
+	// the configuration update replaces the runtime state
+	err = other.SetQueueConfig(configs.QueueConfig{Name: "other"})
+	assert.NilError(t, err, "failed to update queue config")
+	other.UpdateSortType()
+	assert.Assert(t, !other.IsSchedulingPaused(), "config update should have resumed the queue")
+	root.SetSchedulingPaused(true)
+	assert.Equal(t, len(root.sortQueues()), 0, "nothing below a paused root should be returned")
 }
@@ -19,8 +19,11 @@
 package objects
 
 import (
+	"time"
+
 	"go.uber.org/zap"
 
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
 	"github.com/apache/incubator-yunikorn-core/pkg/log"
 )
 
@@ -33,6 +36,18 @@ type reservation struct {
 	app  *Application
 	node *Node
 	ask  *AllocationAsk
+	// time the reservation was created, used to time out reservations that do not convert
+	created time.Time
+}
+
+// The details of a reservation exposed outside the scheduler.
+type ReservationInfo struct {
+	ApplicationID string
+	AllocationKey string
+	NodeID        string
+	Reserved      *resources.Resource
+	Priority      int32
+	Created       time.Time
 }
 
 // The reservation inside the scheduler. A reservation object is never mutated and does not use locking.
@@ -47,10 +62,11 @@ func newReservation(node *Node, app *Application, ask *AllocationAsk, appBased b
 		return nil
 	}
 	res := &reservation{
-		askKey: ask.AllocationKey,
-		ask:    ask,
-		app:    app,
-		node:   node,
+		askKey:  ask.AllocationKey,
+		ask:     ask,
+		app:     app,
+		node:    node,
+		created: time.Now(),
 	}
 	if appBased {
 		res.nodeID = node.NodeID
@@ -88,3 +104,15 @@ func (r *reservation) String() string {
 	}
 	return r.app.ApplicationID + " -> " + r.nodeID + "|" + r.askKey
 }
+
+// Return the details of the reservation
+func (r *reservation) getInfo() *ReservationInfo {
+	return &ReservationInfo{
+		ApplicationID: r.app.ApplicationID,
+		AllocationKey: r.askKey,
+		NodeID:        r.node.NodeID,
+		Reserved:      r.ask.AllocatedResource.Clone(),
+		Priority:      r.ask.GetPriority(),
+		Created:       r.created,
+	}
+}
@@ -20,23 +20,45 @@ package objects
 
 import (
 	"sort"
+	"strings"
 	"time"
 
+	"go.uber.org/zap"
+
 	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+	"github.com/apache/incubator-yunikorn-core/pkg/log"
 	"github.com/apache/incubator-yunikorn-core/pkg/metrics"
 	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/policies"
 )
 
-func sortQueue(queues []*Queue, sortType policies.SortPolicy) {
+func sortQueue(queues []*Queue, sortType policies.SortPolicy, partitionResource *resources.Resource) {
 	sortingStart := time.Now()
-	if sortType == policies.FairSortPolicy {
+	// the pending resources are the tie breaker: calculate them once and not in each comparison
+	pending := make(map[*Queue]*resources.Resource, len(queues))
+	for _, queue := range queues {
+		pending[queue] = queue.GetPendingResource()
+	}
+	switch sortType {
+	case policies.FairSortPolicy:
+		sort.SliceStable(queues, func(i, j int) bool {
+			l := queues[i]
+			r := queues[j]
+			comp := resources.CompWeightedUsageRatioSeparately(l.GetAllocatedResource(), l.GetGuaranteedResource(), l.GetWeight(),
+				r.GetAllocatedResource(), r.GetGuaranteedResource(), r.GetWeight())
+			if comp == 0 {
+				return resources.StrictlyGreaterThan(resources.Sub(pending[l], pending[r]), resources.Zero)
+			}
+			return comp < 0
+		})
+	case policies.DRFSortPolicy:
+		// Sort by dominant share of the partition resources, pending as a tie breaker
 		sort.SliceStable(queues, func(i, j int) bool {
 			l := queues[i]
 			r := queues[j]
-			comp := resources.CompUsageRatioSeparately(l.GetAllocatedResource(), l.GetGuaranteedResource(),
-				r.GetAllocatedResource(), r.GetGuaranteedResource())
+			comp := resources.CompWeightedUsageRatioSeparately(l.GetAllocatedResource(), partitionResource, l.GetWeight(),
+				r.GetAllocatedResource(), partitionResource, r.GetWeight())
 			if comp == 0 {
-				return resources.StrictlyGreaterThan(resources.Sub(l.pending, r.pending), resources.Zero)
+				return resources.StrictlyGreaterThan(resources.Sub(pending[l], pending[r]), resources.Zero)
 			}
 			return comp < 0
 		})
@@ -48,15 +70,15 @@ func sortApplications(apps map[string]*Application, sortType policies.SortPolicy
 	sortingStart := time.Now()
 	var sortedApps []*Application
 	switch sortType {
-	case policies.FairSortPolicy:
+	case policies.FairSortPolicy, policies.DRFSortPolicy:
 		sortedApps = filterOnPendingResources(apps)
-		// Sort by usage
+		// Sort by usage: the global resource determines the shares compared
 		sort.SliceStable(sortedApps, func(i, j int) bool {
 			l := sortedApps[i]
 			r := sortedApps[j]
 			return resources.CompUsageRatio(l.GetAllocatedResource(), r.GetAllocatedResource(), globalResource) < 0
 		})
-	case policies.FifoSortPolicy:
+	case policies.FifoSortPolicy, policies.StrictFifoPolicy:
 		sortedApps = filterOnPendingResources(apps)
 		// Sort by submission time oldest first
 		sort.SliceStable(sortedApps, func(i, j int) bool {
@@ -72,11 +94,117 @@ func sortApplications(apps map[string]*Application, sortType policies.SortPolicy
 			r := sortedApps[j]
 			return l.SubmissionTime.Before(r.SubmissionTime)
 		})
+	case policies.PriorityPolicy:
+		sortedApps = filterOnPendingResources(apps)
+		// the pending priority is calculated from the requests: do it once and not in each comparison
+		priorities := make(map[string]int32, len(sortedApps))
+		for _, app := range sortedApps {
+			priorities[app.ApplicationID] = app.GetPendingPriority()
+		}
+		// Sort by priority highest first, submission time oldest first for the same priority
+		sort.SliceStable(sortedApps, func(i, j int) bool {
+			l := sortedApps[i]
+			r := sortedApps[j]
+			lp := priorities[l.ApplicationID]
+			rp := priorities[r.ApplicationID]
+			if lp != rp {
+				return lp > rp
+			}
+			return l.SubmissionTime.Before(r.SubmissionTime)
+		})
 	}
 	metrics.GetSchedulerMetrics().ObserveAppSortingLatency(sortingStart)
 	return sortedApps
 }
 
+// Prioritize the applications recovered less than the window ago over the applications that were not recovered.
+// For the priority policy the boost is added to the pending priority of the recovered applications and the
+// applications are sorted again on the boosted priority. All other policies move the recovered applications in
+// front of the other applications. A stable sort is used which keeps the order set by the sort policy otherwise.
+func sortRecoveredApplications(apps []*Application, sortType policies.SortPolicy, window time.Duration, boost int32) {
+	recovered := make(map[string]bool, len(apps))
+	for _, app := range apps {
+		recovered[app.ApplicationID] = app.IsRecoveredWithin(window)
+	}
+	if sortType == policies.PriorityPolicy {
+		priorities := make(map[string]int64, len(apps))
+		for _, app := range apps {
+			priority := int64(app.GetPendingPriority())
+			if recovered[app.ApplicationID] {
+				priority += int64(boost)
+			}
+			priorities[app.ApplicationID] = priority
+		}
+		sort.SliceStable(apps, func(i, j int) bool {
+			return priorities[apps[i].ApplicationID] > priorities[apps[j].ApplicationID]
+		})
+		return
+	}
+	sort.SliceStable(apps, func(i, j int) bool {
+		return recovered[apps[i].ApplicationID] && !recovered[apps[j].ApplicationID]
+	})
+}
+
+// An application tag used to change the order of the applications in a leaf queue.
+// An empty value matches any application that has the tag set.
+type appTag struct {
+	key   string
+	value string
+}
+
+// Parse the tag from a queue property value in the form key or key=value.
+// Returns nil if the property value is empty.
+func newAppTag(property string) *appTag {
+	property = strings.TrimSpace(property)
+	if property == "" {
+		return nil
+	}
+	tag := &appTag{key: property}
+	if idx := strings.Index(property, "="); idx >= 0 {
+		tag.key = strings.TrimSpace(property[:idx])
+		tag.value = strings.TrimSpace(property[idx+1:])
+	}
+	if tag.key == "" {
+		return nil
+	}
+	return tag
+}
+
+// Check if the application carries the tag. Tag keys and values are not case sensitive.
+func (at *appTag) matches(app *Application) bool {
+	if at == nil {
+		return false
+	}
+	value := app.GetTag(at.key)
+	if value == "" {
+		return false
+	}
+	return at.value == "" || strings.EqualFold(value, at.value)
+}
+
+// Move boosted applications to the front and demoted applications to the back of the sorted list.
+// A stable sort is used which keeps the order set by the sort policy within each group.
+// An application that is both boosted and demoted is left in the middle group.
+func sortApplicationsByTag(apps []*Application, boost, demote *appTag) {
+	if boost == nil && demote == nil {
+		return
+	}
+	rank := make(map[string]int, len(apps))
+	for _, app := range apps {
+		r := 0
+		if boost.matches(app) {
+			r--
+		}
+		if demote.matches(app) {
+			r++
+		}
+		rank[app.ApplicationID] = r
+	}
+	sort.SliceStable(apps, func(i, j int) bool {
+		return rank[apps[i].ApplicationID] < rank[apps[j].ApplicationID]
+	})
+}
+
 func filterOnPendingResources(apps map[string]*Application) []*Application {
 	filteredApps := make([]*Application, 0)
 	for _, app := range apps {
@@ -146,6 +274,52 @@ func SortNodes(nodes []*Node, sortType policies.SortingPolicy) {
 	metrics.GetSchedulerMetrics().ObserveNodeSortingLatency(sortingStart)
 }
 
+// Sort the nodes based on the node sorting policy.
+// Custom policies are looked up at the time of sorting: if the policy is no longer registered the fair policy is used.
+func SortNodesByPolicy(nodes []*Node, policy *policies.NodeSortingPolicy) {
+	if policy.PolicyType != policies.CustomPolicy {
+		SortNodes(nodes, policy.PolicyType)
+		return
+	}
+	less := policies.GetNodeSortingFunc(policy.Name)
+	if less == nil {
+		log.Logger().Debug("custom node sorting policy not registered, using fair policy",
+			zap.String("policyName", policy.Name))
+		SortNodes(nodes, policies.FairnessPolicy)
+		return
+	}
+	sortingStart := time.Now()
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return less(nodes[i], nodes[j])
+	})
+	metrics.GetSchedulerMetrics().ObserveNodeSortingLatency(sortingStart)
+}
+
+// Split the sorted nodes into groups of nodes that have an equal sorting score for the node sorting policy.
+// Returns the end index (exclusive) of each group in the slice, nodes must be sorted using the same policy.
+func GetEqualScoreGroups(nodes []*Node, policy *policies.NodeSortingPolicy) []int {
+	equal := func(l, r *Node) bool {
+		return resources.CompUsageShares(l.GetAvailableResource(), r.GetAvailableResource()) == 0
+	}
+	if policy.PolicyType == policies.CustomPolicy {
+		if less := policies.GetNodeSortingFunc(policy.Name); less != nil {
+			equal = func(l, r *Node) bool {
+				return !less(l, r) && !less(r, l)
+			}
+		}
+	}
+	groups := make([]int, 0)
+	for i := 1; i < len(nodes); i++ {
+		if !equal(nodes[i-1], nodes[i]) {
+			groups = append(groups, i)
+		}
+	}
+	if len(nodes) > 0 {
+		groups = append(groups, len(nodes))
+	}
+	return groups
+}
+
 func sortAskByPriority(requests []*AllocationAsk, ascending bool) {
 	sort.SliceStable(requests, func(i, j int) bool {
 		l := requests[i]
@@ -19,12 +19,14 @@
 package objects
 
 import (
+	"math"
 	"strconv"
 	"testing"
 	"time"
 
 	"gotest.tools/assert"
 
+	"github.com/apache/incubator-yunikorn-core/pkg/common/configs"
 	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
 	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/policies"
 )
@@ -52,25 +54,104 @@ func TestSortQueues(t *testing.T) {
 
 	// fairness ratios: q0:300/500=0.6, q1:200/300=0.67, q2:100/200=0.5
 	queues := []*Queue{q0, q1, q2}
-	sortQueue(queues, policies.FairSortPolicy)
+	sortQueue(queues, policies.FairSortPolicy, nil)
 	assert.Equal(t, len(queues), 3)
 	assertQueueList(t, queues, []int{1, 2, 0}, "fair first")
 
 	// fairness ratios: q0:200/500=0.4, q1:300/300=1, q2:100/200=0.5
 	q0.allocatedResource = resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 200, "vcore": 200})
 	q1.allocatedResource = resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 300, "vcore": 300})
-	sortQueue(queues, policies.FairSortPolicy)
+	sortQueue(queues, policies.FairSortPolicy, nil)
 	assert.Equal(t, len(queues), 3)
 	assertQueueList(t, queues, []int{0, 2, 1}, "fair second")
 
 	// fairness ratios: q0:150/500=0.3, q1:120/300=0.4, q2:100/200=0.5
 	q0.allocatedResource = resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 150, "vcore": 150})
 	q1.allocatedResource = resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 120, "vcore": 120})
-	sortQueue(queues, policies.FairSortPolicy)
+	sortQueue(queues, policies.FairSortPolicy, nil)
 	assert.Equal(t, len(queues), 3)
 	assertQueueList(t, queues, []int{0, 1, 2}, "fair third")
 }
 
+// verify dominant resource fairness queue ordering
+func TestSortQueuesDRF(t *testing.T) {
+	root, err := createRootQueue(map[string]string{"memory": "1000", "vcore": "10"})
+	assert.NilError(t, err, "queue create failed")
+
+	var q0, q1, q2 *Queue
+	q0, err = createManagedQueue(root, "q0", false, nil)
+	assert.NilError(t, err, "failed to create leaf queue")
+	q0.allocatedResource = resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 500, "vcore": 1})
+	q0.incPendingResource(resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 1}))
+
+	q1, err = createManagedQueue(root, "q1", false, nil)
+	assert.NilError(t, err, "failed to create leaf queue")
+	q1.allocatedResource = resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 100, "vcore": 4})
+	q1.incPendingResource(resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 1}))
+
+	q2, err = createManagedQueue(root, "q2", false, nil)
+	assert.NilError(t, err, "failed to create leaf queue")
+	q2.allocatedResource = resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 300, "vcore": 3})
+	q2.incPendingResource(resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 1}))
+
+	// dominant shares: q0:memory 0.5, q1:vcore 0.4, q2:memory and vcore 0.3
+	queues := []*Queue{q0, q1, q2}
+	sortQueue(queues, policies.DRFSortPolicy, root.GetMaxResource())
+	assertQueueList(t, queues, []int{2, 1, 0}, "drf")
+
+	// fair without guaranteed resources compares the raw usage: q1, q2, q0
+	queues = []*Queue{q0, q1, q2}
+	sortQueue(queues, policies.FairSortPolicy, root.GetMaxResource())
+	assertQueueList(t, queues, []int{2, 0, 1}, "fair")
+
+	// the parent queue sort policy is set from the queue property
+	root.properties = map[string]string{configs.QueueSortPolicy: "drf"}
+	root.UpdateSortType()
+	assert.Equal(t, root.getSortType(), policies.DRFSortPolicy, "parent queue sort policy not set from property")
+	queues = root.sortQueues()
+	assertQueueList(t, queues, []int{2, 1, 0}, "drf parent")
+	root.properties = map[string]string{configs.QueueSortPolicy: "fifo"}
+	root.UpdateSortType()
+	assert.Equal(t, root.getSortType(), policies.FairSortPolicy, "unsupported parent sort policy should fall back to fair")
+}
+
+// verify the queue weights are taken into account when sorting queues
+func TestSortQueuesWeighted(t *testing.T) {
+	root, err := createRootQueue(map[string]string{"memory": "1000"})
+	assert.NilError(t, err, "queue create failed")
+
+	var q0, q1, q2 *Queue
+	q0, err = createManagedQueue(root, "q0", false, nil)
+	assert.NilError(t, err, "failed to create leaf queue")
+	q0.allocatedResource = resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 300})
+	q1, err = createManagedQueue(root, "q1", false, nil)
+	assert.NilError(t, err, "failed to create leaf queue")
+	q1.allocatedResource = resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 200})
+	q2, err = createManagedQueue(root, "q2", false, nil)
+	assert.NilError(t, err, "failed to create leaf queue")
+	q2.allocatedResource = resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 100})
+	assert.Equal(t, q0.GetWeight(), defaultQueueWeight, "queue without weight should have the default weight")
+
+	// equal weights: q2, q1, q0
+	queues := []*Queue{q0, q1, q2}
+	sortQueue(queues, policies.FairSortPolicy, root.GetMaxResource())
+	assertQueueList(t, queues, []int{2, 1, 0}, "fair equal weights")
+	queues = []*Queue{q0, q1, q2}
+	sortQueue(queues, policies.DRFSortPolicy, root.GetMaxResource())
+	assertQueueList(t, queues, []int{2, 1, 0}, "drf equal weights")
+
+	// weighted usage: q0:300/4=75, q1:200/1=200, q2:100/1=100
+	err = q0.SetQueueConfig(configs.QueueConfig{Name: "q0", Weight: 4})
+	assert.NilError(t, err, "failed to set queue weight")
+	assert.Equal(t, q0.GetWeight(), 4.0, "queue weight not set from config")
+	queues = []*Queue{q0, q1, q2}
+	sortQueue(queues, policies.FairSortPolicy, root.GetMaxResource())
+	assertQueueList(t, queues, []int{0, 2, 1}, "fair weighted")
+	queues = []*Queue{q0, q1, q2}
+	sortQueue(queues, policies.DRFSortPolicy, root.GetMaxResource())
+	assertQueueList(t, queues, []int{0, 2, 1}, "drf weighted")
+}
+
 // queue guaranteed resource is not set (same as a zero resource)
 func TestNoQueueLimits(t *testing.T) {
 	root, err := createRootQueue(nil)
@@ -90,13 +171,13 @@ func TestNoQueueLimits(t *testing.T) {
 	q2.allocatedResource = resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 100, "vcore": 100})
 
 	queues := []*Queue{q0, q1, q2}
-	sortQueue(queues, policies.FairSortPolicy)
+	sortQueue(queues, policies.FairSortPolicy, nil)
 	assert.Equal(t, len(queues), 3)
 	assertQueueList(t, queues, []int{2, 1, 0}, "fair no limit first")
 
 	q0.allocatedResource = resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 200, "vcore": 200})
 	q1.allocatedResource = resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 300, "vcore": 300})
-	sortQueue(queues, policies.FairSortPolicy)
+	sortQueue(queues, policies.FairSortPolicy, nil)
 	assert.Equal(t, len(queues), 3)
 	assertQueueList(t, queues, []int{1, 2, 0}, "fair no limit second")
 }
@@ -184,6 +265,126 @@ func TestSortNodesFair(t *testing.T) {
 	assertNodeList(t, list, []int{1, 0, 2}, "fair node-2 negative")
 }
 
+func TestSortNodesByPolicy(t *testing.T) {
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{
+		"first": resources.Quantity(100)})
+	list := make([]*Node, 3)
+	for i := 0; i < 3; i++ {
+		num := strconv.Itoa(i)
+		list[i] = newNodeRes("node-"+num, resources.Multiply(res, int64(1+i)))
+	}
+	// built-in policy: nodes should come back in order 2 (300), 1 (200), 0 (100)
+	SortNodesByPolicy(list, policies.NewNodeSortingPolicy("fair"))
+	assertNodeList(t, list, []int{2, 1, 0}, "fair policy")
+
+	// custom policy sorting on capacity ascending
+	err := policies.RegisterNodeSortingPolicy("smallest", func(l, r policies.SortableNode) bool {
+		return resources.StrictlyGreaterThan(r.GetCapacity(), l.GetCapacity())
+	})
+	assert.NilError(t, err, "custom policy registration failed")
+	defer policies.UnregisterNodeSortingPolicy("smallest")
+	policy := policies.NewNodeSortingPolicy("smallest")
+	SortNodesByPolicy(list, policy)
+	assertNodeList(t, list, []int{0, 1, 2}, "custom policy")
+
+	// unregistered custom policy falls back to fair
+	policies.UnregisterNodeSortingPolicy("smallest")
+	SortNodesByPolicy(list, policy)
+	assertNodeList(t, list, []int{2, 1, 0}, "unregistered custom policy")
+}
+
+func TestGetEqualScoreGroups(t *testing.T) {
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{
+		"first": resources.Quantity(100)})
+	assert.Equal(t, len(GetEqualScoreGroups(nil, policies.NewNodeSortingPolicy("fair"))), 0, "empty list should have no groups")
+	// sizes 100, 100, 200, 300, 300, 300 sorted fair: 300 x3, 200, 100 x2
+	list := make([]*Node, 0)
+	for i, size := range []int64{1, 1, 2, 3, 3, 3} {
+		list = append(list, newNodeRes("node-"+strconv.Itoa(i), resources.Multiply(res, size)))
+	}
+	policy := policies.NewNodeSortingPolicy("fair")
+	SortNodesByPolicy(list, policy)
+	assert.DeepEqual(t, GetEqualScoreGroups(list, policy), []int{3, 4, 6})
+
+	// custom policy that sees all nodes as equal
+	err := policies.RegisterNodeSortingPolicy("equal", func(l, r policies.SortableNode) bool {
+		return false
+	})
+	assert.NilError(t, err, "custom policy registration failed")
+	defer policies.UnregisterNodeSortingPolicy("equal")
+	policy = policies.NewNodeSortingPolicy("equal")
+	assert.DeepEqual(t, GetEqualScoreGroups(list, policy), []int{6})
+}
+
+func TestNewAppTag(t *testing.T) {
+	tests := []struct {
+		name     string
+		property string
+		want     *appTag
+	}{
+		{"empty", "", nil},
+		{"spaces", "  ", nil},
+		{"no key", "=value", nil},
+		{"key only", "critical", &appTag{key: "critical"}},
+		{"key value", "priority=critical", &appTag{key: "priority", value: "critical"}},
+		{"key value spaces", " priority = critical ", &appTag{key: "priority", value: "critical"}},
+		{"key empty value", "priority=", &appTag{key: "priority"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := newAppTag(tt.property)
+			if tt.want == nil {
+				assert.Assert(t, got == nil, "expected nil tag got %v", got)
+				return
+			}
+			assert.Assert(t, got != nil, "expected tag got nil")
+			assert.Equal(t, *got, *tt.want, "unexpected tag")
+		})
+	}
+}
+
+func TestSortApplicationsByTag(t *testing.T) {
+	list := make([]*Application, 4)
+	for i := 0; i < 4; i++ {
+		list[i] = newApplication("app-"+strconv.Itoa(i), "partition", "queue")
+	}
+	// nothing set: order does not change
+	sortApplicationsByTag(list, nil, nil)
+	assertAppList(t, list, []int{0, 1, 2, 3}, "no tags")
+
+	list[1].tags = map[string]string{"Priority": "Critical"}
+	list[2].tags = map[string]string{"batch": "yes"}
+	list[3].tags = map[string]string{"priority": "critical", "batch": "yes"}
+	sortApplicationsByTag(list, newAppTag("priority=critical"), newAppTag("batch"))
+	// app-1 boosted, app-3 boosted and demoted stays with app-0, app-2 demoted
+	assertAppList(t, list, []int{1, 0, 3, 2}, "boost and demote")
+}
+
+func TestSortRecoveredApplications(t *testing.T) {
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})
+	list := make([]*Application, 4)
+	for i := 0; i < 4; i++ {
+		appID := "app-" + strconv.Itoa(i)
+		list[i] = newApplication(appID, "partition", "queue")
+		ask := newAllocationAsk("ask-"+strconv.Itoa(i), appID, res)
+		ask.priority = int32(10 * (4 - i))
+		list[i].requests[ask.AllocationKey] = ask
+	}
+	// nothing recovered: order does not change
+	sortRecoveredApplications(list, policies.FifoSortPolicy, time.Minute, 0)
+	assertAppList(t, list, []int{0, 1, 2, 3}, "nothing recovered")
+
+	// recovered applications move to the front, an expired recovery is ignored
+	list[2].recoveredTime = time.Now()
+	list[3].recoveredTime = time.Now().Add(-time.Hour)
+	sortRecoveredApplications(list, policies.FifoSortPolicy, time.Minute, 0)
+	assertAppList(t, list, []int{1, 2, 0, 3}, "recovered first")
+
+	// priority policy: app-2 (20) is boosted above app-1 (30) but not above app-0 (40)
+	sortRecoveredApplications(list, policies.PriorityPolicy, time.Minute, 15)
+	assertAppList(t, list, []int{0, 2, 1, 3}, "boosted priority")
+}
+
 func TestSortAppsNoPending(t *testing.T) {
 	// stable sort is used so equal values stay where they were
 	res := resources.NewResourceFromMap(map[string]resources.Quantity{
@@ -331,6 +532,54 @@ func TestSortAppsStateAware(t *testing.T) {
 	assertAppListLength(t, list, []string{appID0, appID1, appID3}, "state not app-2")
 }
 
+func TestSortAppsPriority(t *testing.T) {
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{
+		"first": resources.Quantity(100)})
+	// priority of the pending asks per app, app-3 has no outstanding ask
+	priorities := []int32{1, 10, 1, 20}
+	input := make(map[string]*Application, 4)
+	for i := 0; i < 4; i++ {
+		num := strconv.Itoa(i)
+		appID := "app-" + num
+		app := newApplication(appID, "partition", "queue")
+		app.pending = res
+		ask := newAllocationAsk("alloc-"+num, appID, res)
+		ask.setPriority(priorities[i])
+		if i == 3 {
+			ask.pendingRepeatAsk = 0
+		}
+		app.requests[ask.AllocationKey] = ask
+		input[appID] = app
+		// make sure the time stamps differ at least a bit (tracking in nano seconds)
+		time.Sleep(time.Nanosecond * 5)
+	}
+	assert.Equal(t, input["app-3"].GetPendingPriority(), int32(math.MinInt32), "app without outstanding asks should have the lowest priority")
+	// highest priority first, same priority in order created
+	list := sortApplications(input, policies.PriorityPolicy, nil)
+	assertAppList(t, list, []int{1, 0, 2, 3}, "priority")
+}
+
+func TestSortAppsDRF(t *testing.T) {
+	total := resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 1000, "vcore": 10})
+	allocated := []map[string]resources.Quantity{
+		{"memory": 500, "vcore": 1},
+		{"memory": 100, "vcore": 4},
+		{"memory": 300, "vcore": 3},
+		nil,
+	}
+	input := make(map[string]*Application, 4)
+	for i, alloc := range allocated {
+		appID := "app-" + strconv.Itoa(i)
+		app := newApplication(appID, "partition", "queue")
+		app.allocatedResource = resources.NewResourceFromMap(alloc)
+		app.pending = resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 1})
+		input[appID] = app
+	}
+	// dominant shares: app-0:memory 0.5, app-1:vcore 0.4, app-2:memory and vcore 0.3, app-3:nothing allocated
+	list := sortApplications(input, policies.DRFSortPolicy, total)
+	assertAppList(t, list, []int{3, 2, 1, 0}, "drf")
+}
+
 func TestSortAsks(t *testing.T) {
 	// stable sort is used so equal values stay where they were
 	res := resources.NewResourceFromMap(map[string]resources.Quantity{
@@ -0,0 +1,64 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objects
+
+import (
+	"strings"
+)
+
+// Node attribute that defines the taints of the node as set by the RM.
+// The value is a comma separated list of taints, format: key or key=value
+const NodeTaintsAttribute = "yunikorn.apache.org/taints"
+
+// Ask tags with this prefix define a taint the ask tolerates.
+// The remainder of the tag key is the taint key, the tag value the taint value.
+// An empty tag value tolerates the taint independent of the taint value.
+const NodeTolerationTagPrefix = "yunikorn.apache.org/toleration/"
+
+// Parse a comma separated list of taints or tolerations, format: key or key=value
+// Empty entries are skipped. Returns nil if the list does not contain any entries.
+func parseTaints(value string) map[string]string {
+	var taints map[string]string
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		key := strings.TrimSpace(parts[0])
+		if key == "" {
+			continue
+		}
+		if taints == nil {
+			taints = make(map[string]string)
+		}
+		if len(parts) == 2 {
+			taints[key] = strings.TrimSpace(parts[1])
+		} else {
+			taints[key] = ""
+		}
+	}
+	return taints
+}
+
+// Check if the tolerations contain a toleration for the taint.
+func tolerates(tolerations map[string]string, key, value string) bool {
+	tolerated, ok := tolerations[key]
+	return ok && (tolerated == "" || tolerated == value)
+}
@@ -0,0 +1,55 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objects
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestParseTaints(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  map[string]string
+	}{
+		{"empty", "", nil},
+		{"only separators", " , ,", nil},
+		{"key only", "maintenance", map[string]string{"maintenance": ""}},
+		{"key value", "dedicated=gpu", map[string]string{"dedicated": "gpu"}},
+		{"empty key", "=gpu", nil},
+		{"value with equals", "expr=a=b", map[string]string{"expr": "a=b"}},
+		{"list with spaces", " dedicated = gpu , maintenance ", map[string]string{"dedicated": "gpu", "maintenance": ""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.DeepEqual(t, parseTaints(tt.value), tt.want)
+		})
+	}
+}
+
+func TestTolerates(t *testing.T) {
+	tolerations := map[string]string{"dedicated": "gpu", "maintenance": ""}
+	assert.Assert(t, tolerates(tolerations, "dedicated", "gpu"), "matching value should be tolerated")
+	assert.Assert(t, !tolerates(tolerations, "dedicated", "batch"), "different value should not be tolerated")
+	assert.Assert(t, tolerates(tolerations, "maintenance", "any"), "empty toleration value should tolerate any value")
+	assert.Assert(t, !tolerates(tolerations, "unknown", ""), "missing key should not be tolerated")
+	assert.Assert(t, !tolerates(nil, "dedicated", "gpu"), "nil tolerations should not tolerate")
+}
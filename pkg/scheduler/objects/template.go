@@ -0,0 +1,95 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objects
+
+import (
+	"github.com/apache/incubator-yunikorn-core/pkg/common/configs"
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+	"github.com/apache/incubator-yunikorn-core/pkg/common/security"
+)
+
+// The template applied to the leaf queues created dynamically below a queue.
+// The template is immutable after creation and can be shared between queues.
+type template struct {
+	maxResource        *resources.Resource
+	guaranteedResource *resources.Resource
+	properties         map[string]string
+	adminACL           security.ACL
+	submitACL          security.ACL
+}
+
+// Create a new template from the child template configuration.
+// Returns a nil template without error if the configuration does not define anything.
+func newTemplate(conf configs.ChildTemplate) (*template, error) {
+	if len(conf.Resources.Max) == 0 && len(conf.Resources.Guaranteed) == 0 && len(conf.Properties) == 0 &&
+		conf.AdminACL == "" && conf.SubmitACL == "" {
+		return nil, nil
+	}
+	maxResource, err := resources.NewResourceFromConf(conf.Resources.Max)
+	if err != nil {
+		return nil, err
+	}
+	if len(maxResource.Resources) == 0 || resources.IsZero(maxResource) {
+		maxResource = nil
+	}
+	guaranteedResource, err := resources.NewResourceFromConf(conf.Resources.Guaranteed)
+	if err != nil {
+		return nil, err
+	}
+	if len(guaranteedResource.Resources) == 0 || resources.IsZero(guaranteedResource) {
+		guaranteedResource = nil
+	}
+	tmpl := &template{
+		maxResource:        maxResource,
+		guaranteedResource: guaranteedResource,
+		properties:         make(map[string]string),
+	}
+	for key, value := range conf.Properties {
+		tmpl.properties[key] = value
+	}
+	tmpl.adminACL, err = security.NewACL(conf.AdminACL)
+	if err != nil {
+		return nil, err
+	}
+	tmpl.submitACL, err = security.NewACL(conf.SubmitACL)
+	if err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+// Apply the template to a dynamically created leaf queue.
+// The template properties override the properties the queue inherited from its parent.
+// lock free call, must be called holding the queue lock
+func (t *template) apply(sq *Queue) {
+	if t == nil {
+		return
+	}
+	if t.maxResource != nil {
+		sq.maxResource = t.maxResource.Clone()
+	}
+	if t.guaranteedResource != nil {
+		sq.guaranteedResource = t.guaranteedResource.Clone()
+	}
+	for key, value := range t.properties {
+		sq.properties[key] = value
+	}
+	sq.adminACL = t.adminACL
+	sq.submitACL = t.submitACL
+}
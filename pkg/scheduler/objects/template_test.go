@@ -0,0 +1,66 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objects
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/configs"
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+)
+
+func TestNewTemplate(t *testing.T) {
+	tmpl, err := newTemplate(configs.ChildTemplate{})
+	assert.NilError(t, err, "empty template should not fail")
+	assert.Assert(t, tmpl == nil, "empty template should be nil")
+
+	tmpl, err = newTemplate(configs.ChildTemplate{
+		Resources: configs.Resources{Max: map[string]string{"memory": "0"}},
+	})
+	assert.NilError(t, err, "zero template should not fail")
+	assert.Assert(t, tmpl != nil && tmpl.maxResource == nil, "zero max should be ignored")
+
+	_, err = newTemplate(configs.ChildTemplate{
+		Resources: configs.Resources{Max: map[string]string{"memory": "x"}},
+	})
+	assert.Assert(t, err != nil, "unparsable max should fail")
+
+	props := map[string]string{"key": "value"}
+	tmpl, err = newTemplate(configs.ChildTemplate{
+		Resources:  configs.Resources{Max: map[string]string{"memory": "10"}},
+		Properties: props,
+	})
+	assert.NilError(t, err, "template should not fail")
+	// changing the config must not change the template
+	props["key"] = "changed"
+	assert.Equal(t, tmpl.properties["key"], "value", "template properties not copied")
+
+	// apply on a nil template is a noop
+	sq := newBlankQueue()
+	var nilTmpl *template
+	nilTmpl.apply(sq)
+	assert.Assert(t, sq.maxResource == nil, "nil template should not set max")
+	tmpl.apply(sq)
+	assert.Assert(t, resources.Equals(sq.maxResource, resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 10})), "max not applied")
+	assert.Equal(t, sq.properties["key"], "value", "properties not applied")
+	// the queue must own its resources
+	assert.Assert(t, sq.maxResource != tmpl.maxResource, "max resource should be a copy")
+}
@@ -21,20 +21,30 @@ package scheduler
 import (
 	"fmt"
 	"math"
+	"math/rand"
+	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/looplab/fsm"
 	"go.uber.org/zap"
 
 	"github.com/apache/incubator-yunikorn-core/pkg/common/configs"
+	"github.com/apache/incubator-yunikorn-core/pkg/common/features"
 	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
 	"github.com/apache/incubator-yunikorn-core/pkg/common/security"
+	"github.com/apache/incubator-yunikorn-core/pkg/events"
+	"github.com/apache/incubator-yunikorn-core/pkg/export"
 	"github.com/apache/incubator-yunikorn-core/pkg/interfaces"
 	"github.com/apache/incubator-yunikorn-core/pkg/log"
 	"github.com/apache/incubator-yunikorn-core/pkg/metrics"
+	"github.com/apache/incubator-yunikorn-core/pkg/metrics/history"
+	"github.com/apache/incubator-yunikorn-core/pkg/plugins"
+	"github.com/apache/incubator-yunikorn-core/pkg/rmproxy/rmevent"
 	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/objects"
 	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/placement"
 	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/policies"
@@ -42,6 +52,17 @@ import (
 	"github.com/apache/incubator-yunikorn-scheduler-interface/lib/go/si"
 )
 
+// The reasons an application submission is rejected, used in the rejection metrics
+const (
+	rejectedPartition   = "partition"
+	rejectedUser        = "user"
+	rejectedDuplicateID = "duplicate_id"
+	rejectedPlacement   = "placement"
+	rejectedACL         = "acl"
+	rejectedQuota       = "quota"
+	rejectedConfig      = "invalid_config"
+)
+
 type PartitionContext struct {
 	RmID string // the RM the partition belongs to
 	Name string // name of the partition (logging mainly)
@@ -50,18 +71,34 @@ type PartitionContext struct {
 	root                   *objects.Queue                  // start of the queue hierarchy
 	applications           map[string]*objects.Application // applications assigned to this partition
 	completedApplications  map[string]*objects.Application // completed applications from this partition
+	completedInfo          map[string]*completedAppInfo    // retention details of the completed applications, same keys
 	reservedApps           map[string]int                  // applications reserved within this partition, with reservation count
 	nodes                  map[string]*objects.Node        // nodes assigned to this partition
 	placementManager       *placement.AppPlacementManager  // placement manager for this partition
 	partitionManager       *partitionManager               // manager for this partition
 	stateMachine           *fsm.FSM                        // the state of the partition for scheduling
 	stateTime              time.Time                       // last time the state was updated (needed for cleanup)
+	configTime             time.Time                       // last time the configuration was loaded or reloaded
 	isPreemptable          bool                            // can allocations be preempted
 	rules                  *[]configs.PlacementRule        // placement rules to be loaded by the scheduler
 	userGroupCache         *security.UserGroupCache        // user cache per partition
 	totalPartitionResource *resources.Resource             // Total node resources
 	nodeSortingPolicy      *policies.NodeSortingPolicy     // Global Node Sorting Policies
+	nodeTieBreak           string                          // ordering of nodes with an equal sorting score
+	nodeTieRotation        uint32                          // rotation counter for the round robin tie break, atomic access only
 	allocations            int                             // Number of allocations on the partition
+	allocationCountDiff    int                             // difference between the allocation count and the node allocations in the last reconcile
+	nodeSnapshot           atomic.Value                    // immutable []*objects.Node copy of the nodes, replaced on change
+	parallelWorkers        int                             // number of leaf queues allocated in parallel, 0 means serial allocation
+	queueIdleTimeout       time.Duration                   // time a dynamic leaf queue must be without applications before removal
+	systemQueue            string                          // path of the core managed system queue, empty if not enabled
+	unresolvedUser         configs.UnresolvedUserConfig    // handling of applications with a user that cannot be resolved
+	dynamicQueues          configs.DynamicQueuesConfig     // limits on the queues created by the placement rules
+	idleApplications       configs.IdleApplicationsConfig  // detection and reclaim of idle applications
+	schedulingDisabled     bool                            // no allocation cycles are run for the partition
+	counters               *partitionCounters              // rolling window event counters
+	nodeGroups             *nodeGroups                     // nodes indexed by the configured node attributes
+	restored               *restoreState                   // snapshot state not recovered yet, nil if nothing is left
 
 	// The partition write lock must not be held while manipulating an application.
 	// Scheduling is running continuously as a lock free background task. Scheduling an application
@@ -75,6 +112,13 @@ type PartitionContext struct {
 	sync.RWMutex
 }
 
+// Retention details of a completed application: the queue and its retention when the application completed.
+type completedAppInfo struct {
+	queuePath string
+	completed time.Time
+	retention objects.AppRetention
+}
+
 func newPartitionContext(conf configs.PartitionConfig, rmID string, cc *ClusterContext) (*PartitionContext, error) {
 	if conf.Name == "" || rmID == "" {
 		log.Logger().Info("partition cannot be created",
@@ -88,11 +132,15 @@ func newPartitionContext(conf configs.PartitionConfig, rmID string, cc *ClusterC
 		RmID:                  rmID,
 		stateMachine:          objects.NewObjectState(),
 		stateTime:             time.Now(),
+		configTime:            time.Now(),
 		applications:          make(map[string]*objects.Application),
 		completedApplications: make(map[string]*objects.Application),
+		completedInfo:         make(map[string]*completedAppInfo),
 		reservedApps:          make(map[string]int),
 		nodes:                 make(map[string]*objects.Node),
+		counters:              newPartitionCounters(),
 	}
+	pc.nodeSnapshot.Store(make([]*objects.Node, 0))
 	pc.partitionManager = &partitionManager{
 		pc: pc,
 		cc: cc,
@@ -108,6 +156,7 @@ func (pc *PartitionContext) initialPartitionFromConfig(conf configs.PartitionCon
 	if len(conf.Queues) == 0 || conf.Queues[0].Name != configs.RootQueue {
 		return fmt.Errorf("partition cannot be created without root queue")
 	}
+	conf = withSystemQueue(conf)
 
 	// Setup the queue structure: root first it should be the only queue at this level
 	// Add the rest of the queue structure recursively
@@ -120,12 +169,21 @@ func (pc *PartitionContext) initialPartitionFromConfig(conf configs.PartitionCon
 	if err = pc.addQueue(queueConf.Queues, pc.root); err != nil {
 		return err
 	}
+	pc.setSystemQueue(conf.SystemQueue.Enabled)
 	log.Logger().Info("root queue added",
 		zap.String("partitionName", pc.Name),
 		zap.String("rmID", pc.RmID))
 
 	// set preemption needed flag
 	pc.isPreemptable = conf.Preemption.Enabled
+	pc.setParallelAllocation(conf.ParallelAllocation)
+	pc.queueIdleTimeout = conf.QueueCleanup.IdleTimeout
+	pc.root.SetRecoveryPriority(conf.Recovery.Window, conf.Recovery.Boost)
+	pc.setNodeGroups(conf.NodeGroups)
+	pc.unresolvedUser = conf.UnresolvedUser
+	pc.dynamicQueues = conf.DynamicQueues
+	pc.idleApplications = conf.IdleApplications
+	pc.schedulingDisabled = conf.Scheduling.Disabled
 
 	pc.rules = &conf.PlacementRules
 	// We need to pass in the locked version of the GetQueue function.
@@ -135,23 +193,28 @@ func (pc *PartitionContext) initialPartitionFromConfig(conf configs.PartitionCon
 	// TODO get the resolver from the config
 	pc.userGroupCache = security.GetUserGroupCache("")
 
-	// TODO Need some more cleaner interface here.
-	var configuredPolicy policies.SortingPolicy
-	configuredPolicy, err = policies.FromString(conf.NodeSortPolicy.Type)
+	pc.setNodeSortingPolicy(conf.NodeSortPolicy)
+	return nil
+}
+
+// Set the node sorting policy from the config. Unknown policies are replaced by the fair policy.
+// NOTE: this is a lock free call. It must only be called holding the PartitionContext lock.
+func (pc *PartitionContext) setNodeSortingPolicy(conf configs.NodeSortingPolicy) {
+	configuredPolicy, err := policies.FromString(conf.Type)
 	if err != nil {
 		log.Logger().Debug("NodeSorting policy incorrectly set or unknown",
 			zap.Error(err))
 	}
 	switch configuredPolicy {
-	case policies.BinPackingPolicy, policies.FairnessPolicy:
+	case policies.BinPackingPolicy, policies.FairnessPolicy, policies.CustomPolicy:
 		log.Logger().Info("NodeSorting policy set from config",
-			zap.String("policyName", configuredPolicy.String()))
-		pc.nodeSortingPolicy = policies.NewNodeSortingPolicy(conf.NodeSortPolicy.Type)
+			zap.String("policyName", conf.Type))
+		pc.nodeSortingPolicy = policies.NewNodeSortingPolicy(conf.Type)
 	case policies.Unknown:
 		log.Logger().Info("NodeSorting policy not set using 'fair' as default")
 		pc.nodeSortingPolicy = policies.NewNodeSortingPolicy("fair")
 	}
-	return nil
+	pc.nodeTieBreak = strings.ToLower(conf.TieBreak)
 }
 
 func (pc *PartitionContext) updatePartitionDetails(conf configs.PartitionConfig) error {
@@ -160,6 +223,7 @@ func (pc *PartitionContext) updatePartitionDetails(conf configs.PartitionConfig)
 	if len(conf.Queues) == 0 || conf.Queues[0].Name != configs.RootQueue {
 		return fmt.Errorf("partition cannot be created without root queue")
 	}
+	conf = withSystemQueue(conf)
 
 	if pc.placementManager.IsInitialised() {
 		log.Logger().Info("Updating placement manager rules on config reload")
@@ -176,6 +240,15 @@ func (pc *PartitionContext) updatePartitionDetails(conf configs.PartitionConfig)
 		// Placing an application will not have a lock on the partition context.
 		pc.placementManager = placement.NewPlacementManager(*pc.rules, pc.GetQueue)
 	}
+	pc.setParallelAllocation(conf.ParallelAllocation)
+	pc.queueIdleTimeout = conf.QueueCleanup.IdleTimeout
+	pc.root.SetRecoveryPriority(conf.Recovery.Window, conf.Recovery.Boost)
+	pc.setNodeGroups(conf.NodeGroups)
+	pc.unresolvedUser = conf.UnresolvedUser
+	pc.dynamicQueues = conf.DynamicQueues
+	pc.idleApplications = conf.IdleApplications
+	pc.schedulingDisabled = conf.Scheduling.Disabled
+	pc.setNodeSortingPolicy(conf.NodeSortPolicy)
 	// start at the root: there is only one queue
 	queueConf := conf.Queues[0]
 	root := pc.root
@@ -185,7 +258,110 @@ func (pc *PartitionContext) updatePartitionDetails(conf configs.PartitionConfig)
 	}
 	root.UpdateSortType()
 	// update the rest of the queues recursively
-	return pc.updateQueues(queueConf.Queues, root)
+	if err := pc.updateQueues(queueConf.Queues, root); err != nil {
+		return err
+	}
+	pc.setSystemQueue(conf.SystemQueue.Enabled)
+	pc.configTime = time.Now()
+	return nil
+}
+
+// Add the system queue to the root queue of the configuration if it is enabled.
+// The passed in configuration is not changed.
+func withSystemQueue(conf configs.PartitionConfig) configs.PartitionConfig {
+	if !conf.SystemQueue.Enabled {
+		return conf
+	}
+	root := conf.Queues[0]
+	root.Queues = append(root.Queues[:len(root.Queues):len(root.Queues)], configs.QueueConfig{
+		Name:      configs.SystemQueue,
+		SubmitACL: conf.SystemQueue.SubmitACL,
+		Resources: configs.Resources{
+			Guaranteed: conf.SystemQueue.Guaranteed,
+		},
+	})
+	conf.Queues = []configs.QueueConfig{root}
+	return conf
+}
+
+// Set the system queue of the partition, the queue must have been created or updated from the config.
+// NOTE: this is a lock free call. It must only be called holding the PartitionContext lock or during creation.
+func (pc *PartitionContext) setSystemQueue(enabled bool) {
+	if current := pc.root.GetChildQueue(configs.SystemQueue); current != nil {
+		current.SetSystemQueue(enabled)
+	}
+	pc.systemQueue = ""
+	if enabled {
+		pc.systemQueue = configs.RootQueue + configs.DOT + configs.SystemQueue
+	}
+}
+
+// Return the path of the system queue if the application is a system application and the system queue is enabled.
+// Returns an empty string in all other cases.
+func (pc *PartitionContext) getSystemQueue(app *objects.Application) string {
+	pc.RLock()
+	defer pc.RUnlock()
+	if pc.systemQueue == "" || !strings.EqualFold(app.GetTag(objects.SystemApplicationTag), "true") {
+		return ""
+	}
+	return pc.systemQueue
+}
+
+// Set the number of parallel allocation workers from the config.
+// NOTE: this is a lock free call. It should only be called holding the PartitionContext lock or on create.
+func (pc *PartitionContext) setParallelAllocation(conf configs.ParallelAllocationConfig) {
+	if !conf.Enabled {
+		pc.parallelWorkers = 0
+		return
+	}
+	pc.parallelWorkers = conf.Workers
+	if pc.parallelWorkers == 0 {
+		pc.parallelWorkers = runtime.NumCPU()
+	}
+	log.Logger().Info("parallel allocation enabled",
+		zap.String("partitionName", pc.Name),
+		zap.Int("workers", pc.parallelWorkers))
+}
+
+// Return the number of workers used for parallel allocation, 0 means parallel allocation is disabled.
+func (pc *PartitionContext) getParallelWorkers() int {
+	pc.RLock()
+	defer pc.RUnlock()
+	return pc.parallelWorkers
+}
+
+// Enable or disable the allocation cycles for the partition. Existing allocations are not changed and asks are
+// still accepted while scheduling is disabled. The state is replaced on the next configuration update.
+func (pc *PartitionContext) SetSchedulingEnabled(enabled bool) {
+	pc.Lock()
+	defer pc.Unlock()
+	if pc.schedulingDisabled == !enabled {
+		return
+	}
+	pc.schedulingDisabled = !enabled
+	log.Logger().Info("partition scheduling state changed",
+		zap.String("partitionName", pc.Name),
+		zap.Bool("enabled", enabled))
+}
+
+// Return true if the allocation cycles are run for the partition.
+func (pc *PartitionContext) IsSchedulingEnabled() bool {
+	pc.RLock()
+	defer pc.RUnlock()
+	return !pc.schedulingDisabled
+}
+
+// Return the time a dynamic leaf queue must be without applications before it is removed.
+func (pc *PartitionContext) getQueueIdleTimeout() time.Duration {
+	pc.RLock()
+	defer pc.RUnlock()
+	return pc.queueIdleTimeout
+}
+
+func (pc *PartitionContext) getIdleApplications() configs.IdleApplicationsConfig {
+	pc.RLock()
+	defer pc.RUnlock()
+	return pc.idleApplications
 }
 
 // Process the config structure and create a queue info tree for this partition
@@ -295,27 +471,51 @@ func (pc *PartitionContext) getPlacementManager() *placement.AppPlacementManager
 // Add a new application to the partition.
 // NOTE: this is a lock free call. It must NOT be called holding the PartitionContext lock.
 func (pc *PartitionContext) AddApplication(app *objects.Application) error {
+	return pc.addApplication(app, "")
+}
+
+// Add a new application to the partition. If the fixed queue is set the application is put in that queue without
+// using the placement rules or checking the submit ACL. System applications are always put in the system queue.
+// NOTE: this is a lock free call. It must NOT be called holding the PartitionContext lock.
+func (pc *PartitionContext) addApplication(app *objects.Application, fixedQueue string) error {
 	if pc.isDraining() || pc.isStopped() {
-		return fmt.Errorf("partition %s is stopped cannot add a new application %s", pc.Name, app.ApplicationID)
+		return rejectApplication(pc.Name, app.QueueName, rejectedPartition,
+			fmt.Errorf("partition %s is stopped cannot add a new application %s", pc.Name, app.ApplicationID))
 	}
 
 	// Check if the app exists
 	appID := app.ApplicationID
-	if pc.getApplication(appID) != nil {
-		return fmt.Errorf("adding application %s to partition %s, but application already existed", appID, pc.Name)
+	if existing := pc.getApplication(appID); existing != nil {
+		// an application restored from a snapshot is confirmed by the RM adding it again
+		if existing.ConfirmRestored(app) {
+			log.Logger().Info("restored application confirmed by the RM",
+				zap.String("applicationID", appID),
+				zap.String("queueName", existing.GetQueueName()))
+			return nil
+		}
+		return rejectApplication(pc.Name, app.QueueName, rejectedDuplicateID,
+			fmt.Errorf("adding application %s to partition %s, but application already existed", appID, pc.Name))
 	}
 
-	// Put app under the queue
+	// Put app under the queue: system applications and applications with a fixed queue bypass the placement rules
 	queueName := app.QueueName
+	systemQueue := pc.getSystemQueue(app)
+	if systemQueue != "" {
+		fixedQueue = systemQueue
+	}
 	pm := pc.getPlacementManager()
-	if pm.IsInitialised() {
+	if fixedQueue != "" {
+		queueName = fixedQueue
+	} else if pm.IsInitialised() {
 		err := pm.PlaceApplication(app)
 		if err != nil {
-			return fmt.Errorf("failed to place application %s: %v", appID, err)
+			return rejectApplication(pc.Name, queueName, rejectedPlacement,
+				fmt.Errorf("failed to place application %s: %v", appID, err))
 		}
 		queueName = app.QueueName
 		if queueName == "" {
-			return fmt.Errorf("application rejected by placement rules: %s", appID)
+			return rejectApplication(pc.Name, app.QueueName, rejectedPlacement,
+				fmt.Errorf("application rejected by placement rules: %s", appID))
 		}
 	}
 	// lock the partition and make the last change: we need to do this before creating the queues.
@@ -327,18 +527,32 @@ func (pc *PartitionContext) AddApplication(app *objects.Application) error {
 	if queue == nil {
 		// queue must exist if not using placement rules
 		if !pm.IsInitialised() {
-			return fmt.Errorf("application '%s' rejected, cannot create queue '%s' without placement rules", appID, queueName)
+			return rejectApplication(pc.Name, queueName, rejectedConfig,
+				fmt.Errorf("application '%s' rejected, cannot create queue '%s' without placement rules", appID, queueName))
 		}
 		// with placement rules the hierarchy might not exist so try and create it
 		var err error
 		queue, err = pc.createQueue(queueName, app.GetUser())
 		if err != nil {
-			return fmt.Errorf("failed to create rule based queue %s for application %s", queueName, appID)
+			return rejectApplication(pc.Name, queueName, rejectedPlacement,
+				fmt.Errorf("failed to create rule based queue %s for application %s: %v", queueName, appID, err))
 		}
+		app.SetQueueCreated()
+	}
+	// check the queue: is a leaf queue with submit access, applications with a fixed queue do not need access
+	// system applications must still have access to the system queue, the tag alone does not give access
+	if !queue.IsLeafQueue() {
+		return rejectApplication(pc.Name, queueName, rejectedConfig,
+			fmt.Errorf("failed to find queue %s for application %s", queueName, appID))
+	}
+	if (fixedQueue == "" || systemQueue != "") && !queue.CheckSubmitAccess(app.GetUser()) {
+		return rejectApplication(pc.Name, queueName, rejectedACL,
+			fmt.Errorf("failed to find queue %s for application %s", queueName, appID))
 	}
-	// check the queue: is a leaf queue with submit access
-	if !queue.IsLeafQueue() || !queue.CheckSubmitAccess(app.GetUser()) {
-		return fmt.Errorf("failed to find queue %s for application %s", queueName, appID)
+	// only system applications can run in the system queue
+	if systemQueue == "" && queue.IsSystemQueue() {
+		return rejectApplication(pc.Name, queueName, rejectedConfig,
+			fmt.Errorf("application %s rejected, queue %s only accepts system applications", appID, queueName))
 	}
 
 	// add the app to the queue to set the quota on the queue if needed
@@ -348,16 +562,23 @@ func (pc *PartitionContext) AddApplication(app *objects.Application) error {
 	// - task groups should only be used in FIFO or StateAware queues
 	// if the check fails remove the app from the queue again
 	if placeHolder := app.GetPlaceholderAsk(); !resources.IsZero(placeHolder) {
+		if !features.Enabled(features.GangScheduling) {
+			queue.RemoveApplication(app)
+			return rejectApplication(pc.Name, queueName, rejectedConfig,
+				fmt.Errorf("queue %s cannot run application %s with task group request: gang scheduling is disabled", queueName, appID))
+		}
 		// check the queue sorting
 		if !queue.SupportTaskGroup() {
 			queue.RemoveApplication(app)
-			return fmt.Errorf("queue %s cannot run application %s with task group request: unsupported sort type", queueName, appID)
+			return rejectApplication(pc.Name, queueName, rejectedConfig,
+				fmt.Errorf("queue %s cannot run application %s with task group request: unsupported sort type", queueName, appID))
 		}
 		// retrieve the max set
 		if maxQueue := queue.GetMaxQueueSet(); maxQueue != nil {
 			if !maxQueue.FitInMaxUndef(placeHolder) {
 				queue.RemoveApplication(app)
-				return fmt.Errorf("queue %s cannot fit application %s: task group request %s larger than max queue allocation %s", queueName, appID, placeHolder.String(), maxQueue.String())
+				return rejectApplication(pc.Name, queueName, rejectedQuota,
+					fmt.Errorf("queue %s cannot fit application %s: task group request %s larger than max queue allocation %s", queueName, appID, placeHolder.String(), maxQueue.String()))
 			}
 		}
 	}
@@ -365,11 +586,39 @@ func (pc *PartitionContext) AddApplication(app *objects.Application) error {
 	// all is OK update the app and add it to the partition
 	app.SetQueue(queue)
 	app.SetTerminatedCallback(pc.moveTerminatedApp)
+	app.SetStateChangedCallback(pc.markStateChanged)
 	pc.applications[appID] = app
+	sendPlacementEvent(app)
 
 	return nil
 }
 
+// Send an event with the result of the placement rules for the application.
+// Applications that are not placed by a placement rule do not generate an event.
+func sendPlacementEvent(app *objects.Application) {
+	ruleName := app.GetPlacementRule()
+	if ruleName == "" {
+		return
+	}
+	if eventCache := events.GetEventCache(); eventCache != nil {
+		message := fmt.Sprintf("Application placed in queue %s by rule %s, requested queue '%s', queue created: %t",
+			app.GetQueueName(), ruleName, app.GetRequestedQueue(), app.IsQueueCreated())
+		if event, eventErr := events.CreateAppEventRecord(app.ApplicationID, "ApplicationPlaced", message); eventErr != nil {
+			log.Logger().Warn("Event creation failed",
+				zap.String("event message", message),
+				zap.Error(eventErr))
+		} else {
+			eventCache.AddEvent(event)
+		}
+	}
+}
+
+// Track the rejected application submission in the metrics and return the rejection error.
+func rejectApplication(partitionName, queueName, reason string, err error) error {
+	metrics.GetSchedulerMetrics().IncApplicationRejected(partitionName, queueName, reason)
+	return err
+}
+
 // Remove the application from the partition.
 // This does not fail and handles missing app/queue/node/allocations internally
 func (pc *PartitionContext) removeApplication(appID string) []*objects.Allocation {
@@ -386,31 +635,66 @@ func (pc *PartitionContext) removeApplication(appID string) []*objects.Allocatio
 	}
 	// Remove all allocations
 	allocations := app.RemoveAllAllocations()
-	// Remove all allocations from node(s) (queues have been updated already)
-	if len(allocations) != 0 {
-		// track the number of allocations
-		pc.updateAllocationCount(-len(allocations))
-		for _, alloc := range allocations {
-			currentUUID := alloc.UUID
-			node := pc.GetNode(alloc.NodeID)
-			if node == nil {
-				log.Logger().Warn("unknown node: not found in active node list",
-					zap.String("appID", appID),
-					zap.String("nodeID", alloc.NodeID))
-				continue
-			}
-			if nodeAlloc := node.RemoveAllocation(currentUUID); nodeAlloc == nil {
-				log.Logger().Warn("unknown allocation: not found on the node",
-					zap.String("appID", appID),
-					zap.String("allocationId", currentUUID),
-					zap.String("nodeID", alloc.NodeID))
-			}
-		}
-	}
+	pc.removeAppAllocationsFromNodes(appID, allocations)
 
 	return allocations
 }
 
+// Kill an application: the application is failed, all asks and thus all reservations are removed and all allocations
+// are removed. The killed application and the removed allocations are returned to allow the RM to release them.
+// The application moves to the Failed state and is kept as a terminated application in the partition.
+// NOTE: this is a lock free call. It must NOT be called holding the PartitionContext lock.
+func (pc *PartitionContext) killApplication(appID, message string) (*objects.Application, []*objects.Allocation, error) {
+	app := pc.getApplication(appID)
+	if app == nil {
+		return nil, nil, fmt.Errorf("application %s not found in partition %s", appID, pc.Name)
+	}
+	if err := app.HandleApplicationEventWithInfo(objects.FailApplication, message); err != nil {
+		return nil, nil, fmt.Errorf("application %s cannot be killed in state %s", appID, app.CurrentState())
+	}
+	log.Logger().Info("killing application",
+		zap.String("partition", pc.Name),
+		zap.String("appID", appID),
+		zap.String("message", message))
+	pc.Lock()
+	delete(pc.reservedApps, appID)
+	pc.Unlock()
+	// Remove all asks and thus all reservations and pending resources (queue included)
+	_ = app.RemoveAllocationAsk("")
+	// Remove all allocations, this moves the application to the Failed state
+	allocations := app.RemoveAllAllocations()
+	pc.removeAppAllocationsFromNodes(appID, allocations)
+	return app, allocations, nil
+}
+
+// Remove the allocations of an application from the node(s) they are assigned to, the queues and the application have
+// been updated already.
+// NOTE: this is a lock free call. It must NOT be called holding the PartitionContext lock.
+func (pc *PartitionContext) removeAppAllocationsFromNodes(appID string, allocations []*objects.Allocation) {
+	if len(allocations) == 0 {
+		return
+	}
+	// track the number of allocations
+	pc.updateAllocationCount(-len(allocations))
+	pc.countEvents(counterRelease, len(allocations))
+	for _, alloc := range allocations {
+		currentUUID := alloc.UUID
+		node := pc.GetNode(alloc.NodeID)
+		if node == nil {
+			log.Logger().Warn("unknown node: not found in active node list",
+				zap.String("appID", appID),
+				zap.String("nodeID", alloc.NodeID))
+			continue
+		}
+		if nodeAlloc := node.RemoveAllocation(currentUUID); nodeAlloc == nil {
+			log.Logger().Warn("unknown allocation: not found on the node",
+				zap.String("appID", appID),
+				zap.String("allocationId", currentUUID),
+				zap.String("nodeID", alloc.NodeID))
+		}
+	}
+}
+
 // Locked updates of the partition tracking info
 func (pc *PartitionContext) removeAppInternal(appID string) *objects.Application {
 	pc.Lock()
@@ -484,6 +768,42 @@ func (pc *PartitionContext) GetQueueInfos() dao.QueueDAOInfo {
 	return pc.root.GetQueueInfos()
 }
 
+// Get the current usage of all queues in the partition for the metrics history
+func (pc *PartitionContext) GetQueueUsage() []*history.QueueUsage {
+	usage, _ := pc.appendQueueUsage(nil, pc.root)
+	return usage
+}
+
+// Append the usage of the queue and its children, the parent is added before its children.
+// Returns the usage and the allocated resources per user of the queue.
+func (pc *PartitionContext) appendQueueUsage(usage []*history.QueueUsage, queue *objects.Queue) ([]*history.QueueUsage, map[string]*resources.Resource) {
+	current := &history.QueueUsage{
+		Partition:   pc.Name,
+		QueuePath:   queue.GetQueuePath(),
+		Allocated:   queue.GetAllocatedResource(),
+		Pending:     queue.GetPendingResource(),
+		MaxResource: queue.GetMaxResource(),
+	}
+	usage = append(usage, current)
+	users := make(map[string]*resources.Resource)
+	if queue.IsLeafQueue() {
+		for _, app := range queue.GetCopyOfApps() {
+			user := app.GetUser().User
+			users[user] = resources.Add(users[user], app.GetAllocatedResource())
+		}
+	} else {
+		for _, child := range queue.GetCopyOfChildren() {
+			var childUsers map[string]*resources.Resource
+			usage, childUsers = pc.appendQueueUsage(usage, child)
+			for user, allocated := range childUsers {
+				users[user] = resources.Add(users[user], allocated)
+			}
+		}
+	}
+	current.Users = users
+	return usage, users
+}
+
 // Get the queue info for the whole queue structure to pass to the webservice
 func (pc *PartitionContext) GetPartitionQueues() dao.PartitionQueueDAOInfo {
 	var PartitionQueueDAOInfo = dao.PartitionQueueDAOInfo{}
@@ -492,6 +812,218 @@ func (pc *PartitionContext) GetPartitionQueues() dao.PartitionQueueDAOInfo {
 	return PartitionQueueDAOInfo
 }
 
+// A limit update for a single queue. A nil resource leaves the limit unchanged, an empty resource removes the limit.
+type QueueLimitUpdate struct {
+	QueuePath          string
+	MaxResource        *resources.Resource
+	GuaranteedResource *resources.Resource
+}
+
+// The limits for a queue after the updates have been applied
+type queueLimits struct {
+	max        *resources.Resource
+	guaranteed *resources.Resource
+}
+
+// Update the limits for a list of queues.
+// All updates are validated against the current usage, the parent and the children of the queue before any
+// update is applied. If any of the updates fails validation none of the updates are applied.
+// The limits are only updated in the scheduler: the limits of a managed queue must also be updated in the stored
+// configuration to keep them on the next configuration update.
+func (pc *PartitionContext) UpdateQueueLimits(updates []QueueLimitUpdate) error {
+	pc.Lock()
+	defer pc.Unlock()
+	// resolve the queues and the limits after the update
+	queues := make([]*objects.Queue, 0, len(updates))
+	proposed := make(map[string]*queueLimits)
+	for _, update := range updates {
+		queuePath := strings.ToLower(update.QueuePath)
+		if _, ok := proposed[queuePath]; ok {
+			return fmt.Errorf("duplicate limit update for queue %s", queuePath)
+		}
+		queue := pc.getQueueInternal(queuePath)
+		if queue == nil {
+			return fmt.Errorf("queue %s not found", queuePath)
+		}
+		if queuePath == configs.RootQueue {
+			return fmt.Errorf("limits cannot be updated on the root queue")
+		}
+		limits := pc.getQueueLimits(queue, nil)
+		if update.MaxResource != nil {
+			limits.max = update.MaxResource
+		}
+		if update.GuaranteedResource != nil {
+			limits.guaranteed = update.GuaranteedResource
+		}
+		queues = append(queues, queue)
+		proposed[queuePath] = limits
+	}
+	// validate the updated queues and the parents of the updated queues, in the order of the updates
+	for _, queue := range queues {
+		if err := pc.checkQueueLimits(queue, proposed); err != nil {
+			return err
+		}
+		parentPath := queue.QueuePath[:strings.LastIndex(queue.QueuePath, configs.DOT)]
+		if parentPath == configs.RootQueue {
+			continue
+		}
+		if err := pc.checkQueueLimits(pc.getQueueInternal(parentPath), proposed); err != nil {
+			return err
+		}
+	}
+	// all checks passed: apply
+	for _, queue := range queues {
+		limits := proposed[queue.QueuePath]
+		queue.SetLimits(limits.max, limits.guaranteed)
+	}
+	return nil
+}
+
+// Return the limits for the queue taking the proposed updates into account.
+// NOTE: this is a lock free call. It must only be called holding the PartitionContext lock.
+func (pc *PartitionContext) getQueueLimits(queue *objects.Queue, proposed map[string]*queueLimits) *queueLimits {
+	if limits, ok := proposed[queue.QueuePath]; ok {
+		return limits
+	}
+	max, guaranteed := queue.GetLimits()
+	return &queueLimits{max: max, guaranteed: guaranteed}
+}
+
+// Check the limits of the queue, with the proposed updates applied, for consistency:
+// - the guaranteed resource must fit in the max resource
+// - the max resource must not be lower than the current usage
+// - the max resource of the parent must fit the max resource (parent is not the root)
+// - the max resource of the children must fit in the max resource
+// - the sum of the guaranteed resources of the children must fit in the max and guaranteed resource
+// NOTE: this is a lock free call. It must only be called holding the PartitionContext lock.
+func (pc *PartitionContext) checkQueueLimits(queue *objects.Queue, proposed map[string]*queueLimits) error {
+	limits := pc.getQueueLimits(queue, proposed)
+	max := limits.max
+	if resources.IsZero(max) {
+		max = nil
+	}
+	if !max.FitInMaxUndef(limits.guaranteed) {
+		return fmt.Errorf("guaranteed resource %s is larger than maximum resource %s for queue %s", limits.guaranteed, max, queue.QueuePath)
+	}
+	if allocated := queue.GetAllocatedResource(); !max.FitInMaxUndef(allocated) {
+		return fmt.Errorf("maximum resource %s is smaller than the allocated resource %s for queue %s", max, allocated, queue.QueuePath)
+	}
+	parentPath := queue.QueuePath[:strings.LastIndex(queue.QueuePath, configs.DOT)]
+	if parentPath != configs.RootQueue {
+		parentMax := pc.getQueueLimits(pc.getQueueInternal(parentPath), proposed).max
+		if !resources.IsZero(parentMax) && !parentMax.FitInMaxUndef(max) {
+			return fmt.Errorf("max resource of parent %s is smaller than maximum resource %s for queue %s", parentMax, max, queue.QueuePath)
+		}
+	}
+	sumGuaranteed := resources.NewResource()
+	for _, child := range queue.GetCopyOfChildren() {
+		childLimits := pc.getQueueLimits(child, proposed)
+		if !resources.IsZero(childLimits.max) && !max.FitInMaxUndef(childLimits.max) {
+			return fmt.Errorf("max resource %s is smaller than maximum resource %s for child queue %s", max, childLimits.max, child.QueuePath)
+		}
+		sumGuaranteed.AddTo(childLimits.guaranteed)
+	}
+	if !max.FitInMaxUndef(sumGuaranteed) {
+		return fmt.Errorf("max resource %s is smaller than sum of guaranteed resources %s of the children for queue %s", max, sumGuaranteed, queue.QueuePath)
+	}
+	if !resources.IsZero(limits.guaranteed) && !resources.FitIn(limits.guaranteed, sumGuaranteed) {
+		return fmt.Errorf("guaranteed resource %s is smaller than sum of guaranteed resources %s of the children for queue %s", limits.guaranteed, sumGuaranteed, queue.QueuePath)
+	}
+	return nil
+}
+
+// Move a queue, and all queues below it, to a new path. The new path can rename the queue, move it below a
+// different parent or both. The applications in the moved queues stay assigned to the queues, their usage is
+// moved from the old parents to the new parents.
+// The move is validated before any change is made: the new parent must exist and be a parent queue, the new
+// path must not exist and the usage of the queue must fit in the maximum resources of the new parents.
+// The move is only made in the scheduler: a managed queue must also be moved in the stored configuration to keep
+// the move on the next configuration update.
+func (pc *PartitionContext) MoveQueue(queuePath, newPath string) error {
+	queuePath = strings.ToLower(queuePath)
+	newPath = strings.ToLower(newPath)
+	pc.Lock()
+	queue, err := pc.checkQueueMove(queuePath, newPath)
+	if err != nil {
+		pc.Unlock()
+		return err
+	}
+	split := strings.LastIndex(newPath, configs.DOT)
+	var apps []*objects.Application
+	apps, err = queue.MoveQueue(pc.getQueueInternal(newPath[:split]), newPath[split+1:])
+	if err != nil {
+		pc.Unlock()
+		return err
+	}
+	// update the applications before releasing the lock: no lookup may see an application with the old queue name
+	for _, app := range apps {
+		app.RefreshQueueName()
+	}
+	pc.Unlock()
+	log.Logger().Info("queue moved by administrative request",
+		zap.String("partition", pc.Name),
+		zap.String("queue", queuePath),
+		zap.String("newQueue", newPath),
+		zap.Int("applications", len(apps)))
+	if eventCache := events.GetEventCache(); eventCache != nil {
+		message := fmt.Sprintf("Queue %s moved to %s", queuePath, newPath)
+		if event, eventErr := events.CreateQueueEventRecord(newPath, queuePath, "QueueMoved", message); eventErr != nil {
+			log.Logger().Warn("Event creation failed",
+				zap.String("event message", message),
+				zap.Error(eventErr))
+		} else {
+			eventCache.AddEvent(event)
+		}
+	}
+	return nil
+}
+
+// Check that a queue can be moved to the new path, returns the queue to move.
+// NOTE: this is a lock free call. It must only be called holding the PartitionContext lock.
+func (pc *PartitionContext) checkQueueMove(queuePath, newPath string) (*objects.Queue, error) {
+	queue := pc.getQueueInternal(queuePath)
+	if queue == nil {
+		return nil, fmt.Errorf("queue %s not found", queuePath)
+	}
+	if queuePath == configs.RootQueue {
+		return nil, fmt.Errorf("the root queue cannot be moved")
+	}
+	if newPath == queuePath || strings.HasPrefix(newPath, queuePath+configs.DOT) {
+		return nil, fmt.Errorf("queue %s cannot be moved to itself or below itself: %s", queuePath, newPath)
+	}
+	if pc.getQueueInternal(newPath) != nil {
+		return nil, fmt.Errorf("queue %s already exists", newPath)
+	}
+	split := strings.LastIndex(newPath, configs.DOT)
+	if split == -1 {
+		return nil, fmt.Errorf("new queue path %s must be fully qualified", newPath)
+	}
+	if newName := newPath[split+1:]; !configs.QueueNameRegExp.MatchString(newName) {
+		return nil, fmt.Errorf("invalid queue name %s", newName)
+	}
+	newParent := pc.getQueueInternal(newPath[:split])
+	if newParent == nil {
+		return nil, fmt.Errorf("parent queue %s not found", newPath[:split])
+	}
+	if newParent.IsLeafQueue() {
+		return nil, fmt.Errorf("queue %s cannot be moved below leaf queue %s", queuePath, newParent.QueuePath)
+	}
+	parentMax, _ := newParent.GetLimits()
+	if max, _ := queue.GetLimits(); !parentMax.FitInMaxUndef(max) {
+		return nil, fmt.Errorf("max resource of parent %s is smaller than maximum resource %s for queue %s", parentMax, max, queuePath)
+	}
+	// the usage moves to the new parents that are not already a parent of the queue
+	allocated := queue.GetAllocatedResource()
+	for parentPath := newParent.QueuePath; !strings.HasPrefix(queuePath, parentPath+configs.DOT); {
+		parent := pc.getQueueInternal(parentPath)
+		if max, _ := parent.GetLimits(); !max.FitInMaxUndef(resources.Add(parent.GetAllocatedResource(), allocated)) {
+			return nil, fmt.Errorf("allocated resource %s of queue %s does not fit in the maximum resource %s of queue %s", allocated, queuePath, max, parentPath)
+		}
+		parentPath = parentPath[:strings.LastIndex(parentPath, configs.DOT)]
+	}
+	return queue, nil
+}
+
 // Create a queue with full hierarchy. This is called when a new queue is created from a placement rule.
 // The final leaf queue does not exist otherwise we would not get here.
 // This means that at least 1 queue (a leaf queue) will be created
@@ -517,6 +1049,11 @@ func (pc *PartitionContext) createQueue(name string, user security.UserGroup) (*
 	if queue.IsLeafQueue() {
 		return nil, fmt.Errorf("creation of queue %s failed parent is already a leaf: %s", name, current)
 	}
+	if err := pc.checkDynamicQueueLimits(name, len(toCreate)); err != nil {
+		metrics.GetSchedulerMetrics().IncDynamicQueuesLimited(pc.Name)
+		sendQueueLimitedEvent(name, err)
+		return nil, err
+	}
 	log.Logger().Debug("Creating queue(s)",
 		zap.String("parent", current),
 		zap.String("fullPath", name))
@@ -531,9 +1068,60 @@ func (pc *PartitionContext) createQueue(name string, user security.UserGroup) (*
 			return nil, err
 		}
 	}
+	metrics.GetSchedulerMetrics().AddDynamicQueuesCreated(pc.Name, len(toCreate))
+	pc.updateQueueTreeMetrics()
 	return queue, nil
 }
 
+// Check that creating the queues for the queue path stays within the dynamic queue limits of the partition.
+// NOTE: this is a lock free call. It must only be called holding the PartitionContext lock.
+func (pc *PartitionContext) checkDynamicQueueLimits(name string, count int) error {
+	limits := pc.dynamicQueues
+	if depth := strings.Count(name, configs.DOT); limits.MaxDepth > 0 && depth > limits.MaxDepth {
+		return fmt.Errorf("creation of queue %s denied: depth %d exceeds the maximum depth %d", name, depth, limits.MaxDepth)
+	}
+	if limits.MaxQueues > 0 {
+		if _, size := getQueueTreeStats(pc.root); size+count > limits.MaxQueues {
+			return fmt.Errorf("creation of queue %s denied: partition would exceed the maximum of %d queues", name, limits.MaxQueues)
+		}
+	}
+	return nil
+}
+
+// Send an event for a queue that was not created because of the dynamic queue limits.
+func sendQueueLimitedEvent(name string, limitErr error) {
+	if eventCache := events.GetEventCache(); eventCache != nil {
+		message := limitErr.Error()
+		if event, eventErr := events.CreateQueueEventRecord(name, "", "QueueCreationLimited", message); eventErr != nil {
+			log.Logger().Warn("Event creation failed",
+				zap.String("event message", message),
+				zap.Error(eventErr))
+		} else {
+			eventCache.AddEvent(event)
+		}
+	}
+}
+
+// Update the queue hierarchy metrics of the partition.
+func (pc *PartitionContext) updateQueueTreeMetrics() {
+	depth, size := getQueueTreeStats(pc.root)
+	metrics.GetSchedulerMetrics().SetQueueTree(pc.Name, depth, size)
+}
+
+// Return the number of levels below the queue and the number of queues in the hierarchy including the queue.
+func getQueueTreeStats(queue *objects.Queue) (int, int) {
+	depth := 0
+	size := 1
+	for _, child := range queue.GetCopyOfChildren() {
+		childDepth, childSize := getQueueTreeStats(child)
+		if childDepth+1 > depth {
+			depth = childDepth + 1
+		}
+		size += childSize
+	}
+	return depth, size
+}
+
 // Get a node from the partition by nodeID.
 func (pc *PartitionContext) GetNode(nodeID string) *objects.Node {
 	pc.RLock()
@@ -550,12 +1138,11 @@ func (pc *PartitionContext) getSchedulableNodes() []*objects.Node {
 
 // Get a copy of the nodes from the partition.
 // Excludes unschedulable nodes only, reserved node inclusion depends on the parameter passed in.
+// This uses the node snapshot and does not take the partition lock.
 func (pc *PartitionContext) getNodes(excludeReserved bool) []*objects.Node {
-	pc.RLock()
-	defer pc.RUnlock()
-
-	nodes := make([]*objects.Node, 0)
-	for _, node := range pc.nodes {
+	snapshot := pc.getNodeSnapshot()
+	nodes := make([]*objects.Node, 0, len(snapshot))
+	for _, node := range snapshot {
 		// filter out the nodes that are not scheduling
 		if !node.IsSchedulable() || (excludeReserved && node.IsReserved()) {
 			continue
@@ -565,6 +1152,26 @@ func (pc *PartitionContext) getNodes(excludeReserved bool) []*objects.Node {
 	return nodes
 }
 
+// Get the current immutable snapshot of all nodes in the partition.
+// The returned slice is shared and must not be modified by the caller.
+// This is a lock free call: the snapshot is replaced, never changed, when a node is added or removed.
+func (pc *PartitionContext) getNodeSnapshot() []*objects.Node {
+	if snapshot, ok := pc.nodeSnapshot.Load().([]*objects.Node); ok {
+		return snapshot
+	}
+	return nil
+}
+
+// Rebuild the node snapshot from the node map.
+// NOTE: this is a lock free call. It should only be called holding the PartitionContext lock.
+func (pc *PartitionContext) refreshNodeSnapshot() {
+	snapshot := make([]*objects.Node, 0, len(pc.nodes))
+	for _, node := range pc.nodes {
+		snapshot = append(snapshot, node)
+	}
+	pc.nodeSnapshot.Store(snapshot)
+}
+
 // Add the node to the partition and process the allocations that are reported by the node.
 // NOTE: this is a lock free call. It must NOT be called holding the PartitionContext lock.
 func (pc *PartitionContext) AddNode(node *objects.Node, existingAllocations []*objects.Allocation) error {
@@ -599,6 +1206,7 @@ func (pc *PartitionContext) AddNode(node *objects.Node, existingAllocations []*o
 			}
 		}
 	}
+	pc.recoverNodeState(node, existingAllocations)
 	return nil
 }
 
@@ -606,6 +1214,12 @@ func (pc *PartitionContext) AddNode(node *objects.Node, existingAllocations []*o
 func (pc *PartitionContext) updatePartitionResource(delta *resources.Resource) {
 	pc.Lock()
 	defer pc.Unlock()
+	pc.addPartitionResource(delta)
+}
+
+// Add the delta to the partition resource and update the maximum of the root queue, a nil delta is ignored.
+// NOTE: this is a lock free call. It must only be called holding the PartitionContext lock.
+func (pc *PartitionContext) addPartitionResource(delta *resources.Resource) {
 	if delta != nil {
 		if pc.totalPartitionResource == nil {
 			pc.totalPartitionResource = delta.Clone()
@@ -616,6 +1230,37 @@ func (pc *PartitionContext) updatePartitionResource(delta *resources.Resource) {
 	}
 }
 
+// Apply the plain node updates from one request in one go: attributes, capacity and occupied resources.
+// All updates are applied holding the partition lock once, the partition resource and the root queue maximum
+// are updated once for all capacity changes.
+// Returns the IDs of the nodes that are not part of the partition.
+func (pc *PartitionContext) updateNodes(updates []*si.UpdateNodeInfo) []string {
+	pc.Lock()
+	defer pc.Unlock()
+	var missing []string
+	var delta *resources.Resource
+	for _, update := range updates {
+		node := pc.nodes[update.NodeID]
+		if node == nil {
+			missing = append(missing, update.NodeID)
+			continue
+		}
+		if len(update.Attributes) != 0 {
+			pc.updateNodeAttributes(node, update.Attributes)
+		}
+		if sr := update.SchedulableResource; sr != nil {
+			if changed := node.SetCapacity(resources.NewResourceFromProto(sr)); changed != nil {
+				delta = resources.Add(delta, changed)
+			}
+		}
+		if or := update.OccupiedResource; or != nil {
+			node.SetOccupiedResource(resources.NewResourceFromProto(or))
+		}
+	}
+	pc.addPartitionResource(delta)
+	return missing
+}
+
 // Update the partition details when removing a node.
 // This locks the partition. The partition may not be locked when we process the allocation
 // additions to the node as that takes further app, queue or node locks
@@ -627,6 +1272,8 @@ func (pc *PartitionContext) addNodeToList(node *objects.Node) error {
 	}
 	// Node can be added to the system to allow processing of the allocations
 	pc.nodes[node.NodeID] = node
+	pc.nodeGroups.addNode(node)
+	pc.refreshNodeSnapshot()
 	metrics.GetSchedulerMetrics().IncActiveNodes()
 
 	// update/set the resources available in the cluster
@@ -659,6 +1306,8 @@ func (pc *PartitionContext) removeNodeFromList(nodeID string) *objects.Node {
 
 	// Remove node from list of tracked nodes
 	delete(pc.nodes, nodeID)
+	pc.nodeGroups.removeNode(nodeID)
+	pc.refreshNodeSnapshot()
 	metrics.GetSchedulerMetrics().DecActiveNodes()
 
 	// found the node cleanup the available resources, partition resources cannot be nil at this point
@@ -686,12 +1335,40 @@ func (pc *PartitionContext) removeNode(nodeID string) []*objects.Allocation {
 	released := pc.removeNodeAllocations(node)
 
 	// unreserve all the apps that were reserved on the node
+	pc.unReserveNode(node)
+	return released
+}
+
+// Remove all reservations on the node and update the partition reservations.
+func (pc *PartitionContext) unReserveNode(node *objects.Node) {
 	reservedKeys, releasedAsks := node.UnReserveApps()
-	// update the partition reservations based on the node clean up
 	for i, appID := range reservedKeys {
 		pc.unReserveCount(appID, releasedAsks[i])
 	}
-	return released
+}
+
+// Drain a node: the node is set to unschedulable and all reservations on the node are removed.
+// If preempt is set all allocations on the node are removed, the removed allocations are returned to allow the RM
+// to relocate them. The node stays in the partition.
+func (pc *PartitionContext) drainNode(nodeID string, preempt bool) ([]*objects.Allocation, error) {
+	node := pc.GetNode(nodeID)
+	if node == nil {
+		return nil, fmt.Errorf("node %s not found in partition %s", nodeID, pc.Name)
+	}
+	log.Logger().Info("draining node",
+		zap.String("partition", pc.Name),
+		zap.String("nodeID", nodeID),
+		zap.Bool("preempt", preempt))
+	node.StartDrain()
+	pc.unReserveNode(node)
+	if !preempt {
+		return nil, nil
+	}
+	released := pc.removeNodeAllocations(node)
+	for _, alloc := range released {
+		node.RemoveAllocation(alloc.UUID)
+	}
+	return released, nil
 }
 
 // Remove all allocations that are assigned to a node as part of the node removal. This is not part of the node object
@@ -735,6 +1412,7 @@ func (pc *PartitionContext) removeNodeAllocations(node *objects.Node) []*objects
 	}
 	// track the number of allocations
 	pc.updateAllocationCount(-len(released))
+	pc.countEvents(counterRelease, len(released))
 	return released
 }
 
@@ -762,6 +1440,74 @@ func (pc *PartitionContext) tryAllocate() *objects.Allocation {
 	return nil
 }
 
+// Try regular allocation for the partition evaluating the leaf queues with pending resources in parallel.
+// Each leaf queue proposes at most one allocation. The node and queue updates in the proposal are checked
+// under the node and queue locks so concurrent proposals cannot over allocate a node or a queue.
+// Only disjoint leaf queues are evaluated concurrently: leaf queues that share a limiting parent queue are
+// evaluated one after the other in sort order, so they claim the headroom of the parent as a serial allocation would.
+// The proposals are then processed one by one in the order a serial allocation would have found them.
+// Lock free call this all locks are taken when needed in called functions
+func (pc *PartitionContext) tryAllocateParallel() []*objects.Allocation {
+	if !resources.StrictlyGreaterThanZero(pc.root.GetPendingResource()) {
+		// nothing to do just return
+		return nil
+	}
+	leafs := make([]*objects.Queue, 0)
+	pc.root.GetPendingLeafQueues(&leafs)
+	groups := groupDisjointLeafs(leafs)
+	workers := pc.getParallelWorkers()
+	if workers > len(groups) {
+		workers = len(groups)
+	}
+	proposals := make([]*objects.Allocation, len(leafs))
+	work := make(chan []int, len(groups))
+	for _, group := range groups {
+		work <- group
+	}
+	close(work)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for group := range work {
+				for _, i := range group {
+					proposals[i] = leafs[i].TryAllocate(pc.GetNodeIterator)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	// commit the proposals serially
+	allocs := make([]*objects.Allocation, 0)
+	for _, proposal := range proposals {
+		if proposal == nil {
+			continue
+		}
+		if alloc := pc.allocate(proposal); alloc != nil {
+			allocs = append(allocs, alloc)
+		}
+	}
+	return allocs
+}
+
+// Group the leaf queues by their limiting queue. Returns the indexes of the leaf queues in each group, the order
+// of the leaf queues is kept within a group and the groups are ordered by their first leaf queue.
+func groupDisjointLeafs(leafs []*objects.Queue) [][]int {
+	groups := make([][]int, 0, len(leafs))
+	index := make(map[*objects.Queue]int)
+	for i, leaf := range leafs {
+		limiting := leaf.GetLimitingQueue()
+		if g, ok := index[limiting]; ok {
+			groups[g] = append(groups[g], i)
+			continue
+		}
+		index[limiting] = len(groups)
+		groups = append(groups, []int{i})
+	}
+	return groups
+}
+
 // Try process reservations for the partition
 // Lock free call this all locks are taken when needed in called functions
 func (pc *PartitionContext) tryReservedAllocate() *objects.Allocation {
@@ -777,6 +1523,64 @@ func (pc *PartitionContext) tryReservedAllocate() *objects.Allocation {
 	return nil
 }
 
+// Try to steal a reservation for a higher priority ask that cannot be reserved because all nodes it could use are
+// reserved. Of all applications the ask with the highest priority steals. The stolen reservation is removed, the
+// ask it was made for is pending again, and the node is reserved for the higher priority ask.
+// Returns true if a reservation was stolen.
+// Lock free call this all locks are taken when needed in called functions
+func (pc *PartitionContext) tryStealReservation() bool {
+	if !resources.StrictlyGreaterThanZero(pc.root.GetPendingResource()) {
+		return false
+	}
+	nodes := pc.getNodes(false)
+	var app *objects.Application
+	var steal *objects.ReservationSteal
+	for _, candidate := range pc.GetApplications() {
+		if resources.IsZero(candidate.GetPendingResource()) {
+			continue
+		}
+		if found := candidate.FindReservationToSteal(nodes); found != nil {
+			if steal == nil || found.Ask.GetPriority() > steal.Ask.GetPriority() {
+				app = candidate
+				steal = found
+			}
+		}
+	}
+	if steal == nil {
+		return false
+	}
+	stolenApp := pc.getApplication(steal.Stolen.ApplicationID)
+	if stolenApp == nil {
+		return false
+	}
+	stolenAsk := stolenApp.GetAllocationAsk(steal.Stolen.AllocationKey)
+	if stolenAsk == nil {
+		return false
+	}
+	pc.unReserve(stolenApp, steal.Node, stolenAsk)
+	pc.reserve(app, steal.Node, steal.Ask)
+	log.Logger().Info("reservation stolen by higher priority ask",
+		zap.String("nodeID", steal.Node.NodeID),
+		zap.String("appID", app.ApplicationID),
+		zap.String("allocationKey", steal.Ask.AllocationKey),
+		zap.Int32("priority", steal.Ask.GetPriority()),
+		zap.String("stolenAppID", stolenApp.ApplicationID),
+		zap.String("stolenAllocationKey", stolenAsk.AllocationKey),
+		zap.Int32("stolenPriority", stolenAsk.GetPriority()))
+	if eventCache := events.GetEventCache(); eventCache != nil {
+		message := fmt.Sprintf("Reservation on node %s released for ask %s of application %s with priority %d",
+			steal.Node.NodeID, steal.Ask.AllocationKey, app.ApplicationID, steal.Ask.GetPriority())
+		if event, eventErr := events.CreateRequestEventRecord(stolenAsk.AllocationKey, stolenApp.ApplicationID, "ReservationStolen", message); eventErr != nil {
+			log.Logger().Warn("Event creation failed",
+				zap.String("event message", message),
+				zap.Error(eventErr))
+		} else {
+			eventCache.AddEvent(event)
+		}
+	}
+	return true
+}
+
 // Try process placeholder for the partition
 // Lock free call this all locks are taken when needed in called functions
 func (pc *PartitionContext) tryPlaceholderAllocate() *objects.Allocation {
@@ -792,6 +1596,7 @@ func (pc *PartitionContext) tryPlaceholderAllocate() *objects.Allocation {
 			zap.String("allocationKey", alloc.AllocationKey),
 			zap.String("UUID", alloc.UUID),
 			zap.String("placeholder released UUID", alloc.Releases[0].UUID))
+		pc.countEvents(counterAllocation, 1)
 		// pass the release back to the RM via the cluster context
 		return alloc
 	}
@@ -846,6 +1651,7 @@ func (pc *PartitionContext) allocate(alloc *objects.Allocation) *objects.Allocat
 
 	// track the number of allocations
 	pc.updateAllocationCount(1)
+	pc.countEvents(counterAllocation, 1)
 
 	log.Logger().Info("scheduler allocation processed",
 		zap.String("appID", alloc.ApplicationID),
@@ -921,12 +1727,19 @@ func (pc *PartitionContext) unReserve(app *objects.Application, node *objects.No
 // Get the iterator for the sorted nodes list from the partition.
 // Sorting should use a copy of the node list not the main list.
 func (pc *PartitionContext) getNodeIteratorForPolicy(nodes []*objects.Node) interfaces.NodeIterator {
-	configuredPolicy := pc.GetNodeSortingPolicy()
-	if configuredPolicy == policies.Unknown {
+	configuredPolicy := pc.getNodeSortingPolicy()
+	if configuredPolicy.PolicyType == policies.Unknown {
 		return nil
 	}
 	// Sort Nodes based on the policy configured.
-	objects.SortNodes(nodes, configuredPolicy)
+	objects.SortNodesByPolicy(nodes, configuredPolicy)
+	// rotate the start within groups of nodes with an equal score if configured
+	switch pc.getNodeTieBreak() {
+	case configs.NodeTieBreakRandom:
+		return newTieRotatingNodeIterator(nodes, objects.GetEqualScoreGroups(nodes, configuredPolicy), rand.Uint32())
+	case configs.NodeTieBreakRoundRobin:
+		return newTieRotatingNodeIterator(nodes, objects.GetEqualScoreGroups(nodes, configuredPolicy), atomic.AddUint32(&pc.nodeTieRotation, 1))
+	}
 	return newDefaultNodeIterator(nodes)
 }
 
@@ -994,6 +1807,23 @@ func (pc *PartitionContext) GetTotalNodeCount() int {
 	return len(pc.nodes)
 }
 
+// Get the current totals for the partition to log after a node update.
+func (pc *PartitionContext) getPartitionTotals() *rmevent.PartitionTotals {
+	askCount := 0
+	for _, app := range pc.GetApplications() {
+		askCount += app.GetPendingAskCount()
+	}
+	pc.RLock()
+	defer pc.RUnlock()
+	return &rmevent.PartitionTotals{
+		Partition:       pc.Name,
+		Nodes:           len(pc.nodes),
+		Capacity:        pc.totalPartitionResource.Clone(),
+		AllocationCount: pc.allocations,
+		AskCount:        askCount,
+	}
+}
+
 func (pc *PartitionContext) GetApplications() []*objects.Application {
 	pc.RLock()
 	defer pc.RUnlock()
@@ -1110,7 +1940,7 @@ func (pc *PartitionContext) addAllocation(alloc *objects.Allocation) error {
 
 	node.AddAllocation(alloc)
 	app.RecoverAllocationAsk(alloc.Ask)
-	app.AddAllocation(alloc)
+	app.AddAllocationOnNode(alloc, node)
 
 	// track the number of allocations
 	pc.updateAllocationCount(1)
@@ -1130,6 +1960,32 @@ func (pc *PartitionContext) convertUGI(ugi *si.UserGroupInformation) (security.U
 	return pc.userGroupCache.ConvertUGI(ugi)
 }
 
+// Apply the unresolved user policy of the partition to an application with a user that could not be resolved.
+// Returns the identity for the application and the queue to put a quarantined application in. The resolution
+// error is returned if the policy rejects the application.
+func (pc *PartitionContext) applyUnresolvedUserPolicy(resolveErr error) (security.UserGroup, string, error) {
+	pc.RLock()
+	unresolved := pc.unresolvedUser
+	pc.RUnlock()
+	policy := strings.ToLower(unresolved.Policy)
+	if policy == "" {
+		policy = configs.UnresolvedUserReject
+	}
+	metrics.GetSchedulerMetrics().IncUnresolvedUser(pc.Name, policy)
+	user := unresolved.User
+	if user == "" {
+		user = configs.DefaultAnonymousUser
+	}
+	switch policy {
+	case configs.UnresolvedUserQuarantine:
+		return security.UserGroup{User: user}, unresolved.Queue, nil
+	case configs.UnresolvedUserAnonymous:
+		return security.UserGroup{User: user}, "", nil
+	default:
+		return security.UserGroup{}, "", resolveErr
+	}
+}
+
 // calculate overall nodes resource usage and returns a map as the result,
 // where the key is the resource name, e.g memory, and the value is a []int,
 // which is a slice with 10 elements,
@@ -1263,6 +2119,10 @@ func (pc *PartitionContext) removeAllocation(release *si.AllocationRelease) ([]*
 	}
 	// track the number of allocations, when we replace the result is no change
 	pc.updateAllocationCount(-len(released))
+	if release.TerminationType == si.TerminationType_PREEMPTED_BY_SCHEDULER {
+		pc.countEvents(counterPreemption, len(released))
+	}
+	pc.countEvents(counterRelease, len(released))
 	return released, confirmed
 }
 
@@ -1305,18 +2165,104 @@ func (pc *PartitionContext) addAllocationAsk(siAsk *si.AllocationAsk) error {
 	}
 	app := pc.getApplication(siAsk.ApplicationID)
 	if app == nil {
-		return fmt.Errorf("failed to find application %s, for allocation ask %s", siAsk.ApplicationID, siAsk.AllocationKey)
+		return objects.NewAskRejectedError(objects.AskRejectedInvalid, "failed to find application %s, for allocation ask %s", siAsk.ApplicationID, siAsk.AllocationKey)
+	}
+	ask := objects.NewAllocationAsk(siAsk)
+	// the queue can restrict the resource types an ask may request
+	if ask != nil && app.GetQueue() != nil {
+		if err := app.GetQueue().CheckAllowedResourceTypes(ask.AllocatedResource); err != nil {
+			return objects.NewAskRejectedError(objects.AskRejectedQuota, "allocation ask %s rejected for application %s: %v", siAsk.AllocationKey, siAsk.ApplicationID, err)
+		}
+	}
+	// an ask that already exists is updated in place: it keeps its position
+	if ask != nil && app.GetAllocationAsk(ask.AllocationKey) != nil {
+		reservedAsks, err := app.UpdateAllocationAsk(ask)
+		// update the partition if the asks were reserved (clean up)
+		if reservedAsks != 0 {
+			pc.unReserveCount(app.ApplicationID, reservedAsks)
+		}
+		return err
 	}
 	// add the allocation asks to the app
-	return app.AddAllocationAsk(objects.NewAllocationAsk(siAsk))
+	if err := app.AddAllocationAsk(ask); err != nil {
+		return err
+	}
+	pc.restoreReservations()
+	return nil
+}
+
+// Detect the idle applications in the partition: applications that hold allocations, have had no ask changes or
+// allocation releases for the idle timeout and are flagged idle by the RM through the application usage plugin.
+// An idle application is reported once per idle period. If reclaim is configured, and the queue of the application
+// allows it, the allocations covering the reclaim fraction of the application resources are released.
+// Returns the released allocations, the RM must be notified of the release.
+// NOTE: this is a lock free call. It must NOT be called holding the PartitionContext lock.
+func (pc *PartitionContext) reclaimIdleApplications() []*objects.Allocation {
+	idle := pc.getIdleApplications()
+	if idle.Timeout == 0 {
+		return nil
+	}
+	plugin := plugins.GetApplicationUsagePlugin()
+	if plugin == nil {
+		return nil
+	}
+	released := make([]*objects.Allocation, 0)
+	for _, app := range pc.GetApplications() {
+		if !app.IsInactive(idle.Timeout) || !plugin.IsApplicationIdle(app.ApplicationID) {
+			continue
+		}
+		app.SetIdle()
+		queue := app.GetQueue()
+		reclaim := idle.Reclaim > 0 && queue != nil && queue.IsIdleReclaimAllowed()
+		log.Logger().Info("application is idle",
+			zap.String("partition", pc.Name),
+			zap.String("appID", app.ApplicationID),
+			zap.Duration("inactive", idle.Timeout),
+			zap.Bool("reclaim", reclaim))
+		if eventCache := events.GetEventCache(); eventCache != nil {
+			message := fmt.Sprintf("Application %s has been idle for at least %s", app.ApplicationID, idle.Timeout)
+			if event, err := events.CreateAppEventRecord(app.ApplicationID, "ApplicationIdle", message); err != nil {
+				log.Logger().Warn("Event creation failed",
+					zap.String("event message", message),
+					zap.Error(err))
+			} else {
+				eventCache.AddEvent(event)
+			}
+		}
+		if !reclaim {
+			continue
+		}
+		for _, alloc := range app.GetReclaimAllocations(idle.Reclaim) {
+			allocs, _ := pc.removeAllocation(&si.AllocationRelease{
+				PartitionName:   pc.Name,
+				ApplicationID:   app.ApplicationID,
+				UUID:            alloc.UUID,
+				TerminationType: si.TerminationType_PREEMPTED_BY_SCHEDULER,
+			})
+			released = append(released, allocs...)
+		}
+	}
+	return released
 }
 
 func (pc *PartitionContext) cleanupExpiredApps() {
-	for _, app := range pc.GetAppsByState(objects.Expired.String()) {
+	expired := pc.GetAppsByState(objects.Expired.String())
+	for _, app := range expired {
 		pc.Lock()
 		delete(pc.applications, app.ApplicationID)
 		pc.Unlock()
 	}
+	if len(expired) != 0 {
+		pc.markStateChanged()
+	}
+}
+
+// Record a change of the partition that is not the direct result of an RM event or scheduling, like a change
+// triggered by a timer.
+func (pc *PartitionContext) markStateChanged() {
+	if pc.partitionManager != nil && pc.partitionManager.cc != nil {
+		pc.partitionManager.cc.MarkStateChanged()
+	}
 }
 
 func (pc *PartitionContext) GetCurrentState() string {
@@ -1329,12 +2275,40 @@ func (pc *PartitionContext) GetStateTime() time.Time {
 	return pc.stateTime
 }
 
+// Return the last time the configuration of the partition was loaded or reloaded.
+func (pc *PartitionContext) GetConfigTime() time.Time {
+	pc.RLock()
+	defer pc.RUnlock()
+	return pc.configTime
+}
+
 func (pc *PartitionContext) GetNodeSortingPolicy() policies.SortingPolicy {
 	pc.RLock()
 	defer pc.RUnlock()
 	return pc.nodeSortingPolicy.PolicyType
 }
 
+// Return the name of the node sorting policy, for custom policies this is the registered name.
+func (pc *PartitionContext) GetNodeSortingPolicyName() string {
+	pc.RLock()
+	defer pc.RUnlock()
+	return pc.nodeSortingPolicy.Name
+}
+
+// Return the node sorting policy. The policy is replaced, not changed, on config reload.
+func (pc *PartitionContext) getNodeSortingPolicy() *policies.NodeSortingPolicy {
+	pc.RLock()
+	defer pc.RUnlock()
+	return pc.nodeSortingPolicy
+}
+
+// Return the ordering of nodes with an equal sorting score, empty if not set.
+func (pc *PartitionContext) getNodeTieBreak() string {
+	pc.RLock()
+	defer pc.RUnlock()
+	return pc.nodeTieBreak
+}
+
 func (pc *PartitionContext) moveTerminatedApp(appID string) {
 	app := pc.getApplication(appID)
 	// nothing to do if the app is not found on the partition
@@ -1343,9 +2317,17 @@ func (pc *PartitionContext) moveTerminatedApp(appID string) {
 			zap.String("appID", appID))
 		return
 	}
+	// the retention is based on the queue the application was in
+	info := &completedAppInfo{
+		queuePath: app.GetQueueName(),
+		completed: time.Now(),
+	}
+	if queue := app.GetQueue(); queue != nil {
+		info.retention = queue.GetAppRetention()
+	}
 	app.UnSetQueue()
 	// new ID as completedApplications map key, use negative value to get a divider
-	newID := appID + strconv.FormatInt(-(time.Now()).Unix(), 10)
+	newID := appID + strconv.FormatInt(-info.completed.Unix(), 10)
 	log.Logger().Info("Removing terminated application from the application list",
 		zap.String("appID", appID),
 		zap.String("app status", app.CurrentState()))
@@ -1353,4 +2335,64 @@ func (pc *PartitionContext) moveTerminatedApp(appID string) {
 	defer pc.Unlock()
 	delete(pc.applications, appID)
 	pc.completedApplications[newID] = app
+	pc.completedInfo[newID] = info
+}
+
+// Remove the completed applications that are no longer retained based on the retention of the queue the application
+// was in when it completed. An application is removed when it is older than the maximum age, or when there are more
+// newer completed applications for the queue than the maximum count. Removed applications are exported to the
+// decision export sink if the retention of the queue requests it.
+func (pc *PartitionContext) cleanupCompletedApps() {
+	now := time.Now()
+	var records []*export.DecisionRecord
+	removed := false
+	pc.Lock()
+	byQueue := make(map[string][]string)
+	for key, info := range pc.completedInfo {
+		byQueue[info.queuePath] = append(byQueue[info.queuePath], key)
+	}
+	for _, keys := range byQueue {
+		// newest first
+		sort.Slice(keys, func(i, j int) bool {
+			return pc.completedInfo[keys[i]].completed.After(pc.completedInfo[keys[j]].completed)
+		})
+		for i, key := range keys {
+			info := pc.completedInfo[key]
+			expired := info.retention.MaxAge > 0 && now.Sub(info.completed) > info.retention.MaxAge
+			overLimit := info.retention.MaxCount > 0 && i >= info.retention.MaxCount
+			if !expired && !overLimit {
+				continue
+			}
+			app := pc.completedApplications[key]
+			delete(pc.completedApplications, key)
+			delete(pc.completedInfo, key)
+			removed = true
+			if app == nil {
+				continue
+			}
+			log.Logger().Debug("Removing completed application from the partition",
+				zap.String("appID", app.ApplicationID),
+				zap.String("queue", info.queuePath),
+				zap.Bool("expired", expired))
+			if info.retention.Export {
+				records = append(records, &export.DecisionRecord{
+					Type:          export.ApplicationRemoved,
+					Partition:     pc.Name,
+					ApplicationID: app.ApplicationID,
+					QueueName:     info.queuePath,
+					Message:       app.CurrentState(),
+				})
+			}
+		}
+	}
+	pc.Unlock()
+	if removed {
+		pc.markStateChanged()
+	}
+	// export outside of the lock
+	if exporter := export.GetDecisionExporter(); exporter != nil {
+		for _, record := range records {
+			exporter.AddRecord(record)
+		}
+	}
 }
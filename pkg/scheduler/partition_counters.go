@@ -0,0 +1,122 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"time"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/metrics"
+	"github.com/apache/incubator-yunikorn-core/pkg/metrics/history"
+	"github.com/apache/incubator-yunikorn-core/pkg/webservice/dao"
+)
+
+// The events tracked in the rolling window counters of a partition
+const (
+	counterAllocation = "allocation"
+	counterRelease    = "release"
+	counterRejection  = "rejection"
+	counterPreemption = "preemption"
+)
+
+// The buckets used for the window counters: 10 second buckets covering the last hour
+const (
+	counterBucketSize = 10 * time.Second
+	counterBuckets    = 360
+)
+
+var counterEvents = []string{counterAllocation, counterRelease, counterRejection, counterPreemption}
+
+// The windows reported on, the name is used in the REST response and in the metrics
+var counterWindows = []struct {
+	name   string
+	window time.Duration
+}{
+	{"1m", time.Minute},
+	{"5m", 5 * time.Minute},
+	{"1h", time.Hour},
+}
+
+// Rolling window counters for a partition.
+// The map is created once and never changed, the counters handle their own locking.
+type partitionCounters struct {
+	counters map[string]*history.WindowCounter
+}
+
+func newPartitionCounters() *partitionCounters {
+	pcs := &partitionCounters{
+		counters: make(map[string]*history.WindowCounter),
+	}
+	for _, event := range counterEvents {
+		pcs.counters[event] = history.NewWindowCounter(counterBucketSize, counterBuckets)
+	}
+	return pcs
+}
+
+// Add the number of events to the counter, unknown events and zero values are ignored.
+func (pcs *partitionCounters) add(event string, value int) {
+	if value <= 0 {
+		return
+	}
+	if counter, ok := pcs.counters[event]; ok {
+		counter.Add(value)
+	}
+}
+
+// Get the current counts for all events and windows
+func (pcs *partitionCounters) getCounts() []dao.PartitionCounterDAOInfo {
+	counts := make([]dao.PartitionCounterDAOInfo, 0, len(counterEvents))
+	for _, event := range counterEvents {
+		windows := make(map[string]int)
+		for _, w := range counterWindows {
+			windows[w.name] = pcs.counters[event].Count(w.window)
+		}
+		counts = append(counts, dao.PartitionCounterDAOInfo{
+			Event:   event,
+			Windows: windows,
+		})
+	}
+	return counts
+}
+
+// Update the metrics with the current counts
+func (pcs *partitionCounters) updateMetrics(partition string) {
+	for _, event := range counterEvents {
+		for _, w := range counterWindows {
+			metrics.GetSchedulerMetrics().SetPartitionWindowCount(partition, event, w.name, float64(pcs.counters[event].Count(w.window)))
+		}
+	}
+}
+
+// Track the number of events of the given type in the partition
+func (pc *PartitionContext) countEvents(event string, value int) {
+	pc.counters.add(event, value)
+}
+
+// Get the rolling window counters for the partition to pass to the webservice
+func (pc *PartitionContext) GetPartitionCounters() *dao.PartitionCountersDAOInfo {
+	return &dao.PartitionCountersDAOInfo{
+		Partition: pc.Name,
+		Counters:  pc.counters.getCounts(),
+	}
+}
+
+// Push the current rolling window counts for the partition into the metrics
+func (pc *PartitionContext) updateCounterMetrics() {
+	pc.counters.updateMetrics(pc.Name)
+}
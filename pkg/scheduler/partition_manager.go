@@ -33,6 +33,9 @@ const (
 
 var appRemovalInterval = 24 * time.Hour
 
+// time between two runs of the partition reconciler
+var reconcileInterval = 5 * time.Minute
+
 type partitionManager struct {
 	pc       *PartitionContext
 	cc       *ClusterContext
@@ -41,10 +44,14 @@ type partitionManager struct {
 }
 
 // Run the manager for the partition.
-// The manager has three tasks:
+// The manager has the following tasks:
 // - clean up the managed queues that are empty and removed from the configuration
 // - remove empty unmanaged queues
+// - update the queue hierarchy metrics
 // - remove completed applications from the partition
+// - remove completed applications that are no longer retained by the queue retention
+// - reconcile the partition state with the nodes, applications and queues
+// - detect idle applications and reclaim their resources
 // When the manager exits the partition is removed from the system and must be cleaned up
 func (manager partitionManager) Run() {
 	if manager.interval == 0 {
@@ -55,11 +62,17 @@ func (manager partitionManager) Run() {
 		zap.String("partition", manager.pc.Name),
 		zap.String("interval", manager.interval.String()))
 	go manager.cleanupExpiredApps()
+	go manager.reconcile()
 	// exit only when the partition this manager belongs to exits
 	for {
 		time.Sleep(manager.interval)
 		runStart := time.Now()
 		manager.cleanQueues(manager.pc.root)
+		manager.pc.updateQueueTreeMetrics()
+		manager.pc.cleanupCompletedApps()
+		if manager.cc != nil {
+			manager.cc.reclaimIdleApplications(manager.pc)
+		}
 		if manager.stop {
 			break
 		}
@@ -76,19 +89,32 @@ func (manager partitionManager) Stop() {
 }
 
 // Remove drained managed and empty unmanaged queues. Perform the action recursively.
+// Unmanaged leaf queues are only removed after they have been idle for the configured idle timeout.
 // Only called internally and recursive, no locking
 func (manager partitionManager) cleanQueues(queue *objects.Queue) {
+	manager.cleanQueuesIdle(queue, manager.pc.getQueueIdleTimeout())
+}
+
+func (manager partitionManager) cleanQueuesIdle(queue *objects.Queue, idleTimeout time.Duration) {
 	if queue == nil {
 		return
 	}
 	// check the children first: call recursive
 	if children := queue.GetCopyOfChildren(); len(children) != 0 {
 		for _, child := range children {
-			manager.cleanQueues(child)
+			manager.cleanQueuesIdle(child, idleTimeout)
 		}
 	}
 	// when we have done the children (or have none) this queue might be removable
 	if queue.IsDraining() || !queue.IsManaged() {
+		// an empty unmanaged leaf must be idle long enough before it is removed
+		if !queue.IsManaged() && queue.IsLeafQueue() && !queue.IsIdle(idleTimeout) {
+			log.Logger().Debug("skip removing the queue",
+				zap.String("reason", "queue has not been idle for the idle timeout"),
+				zap.String("queue", queue.QueuePath),
+				zap.String("partitionName", manager.pc.Name))
+			return
+		}
 		log.Logger().Debug("removing queue",
 			zap.String("queueName", queue.QueuePath),
 			zap.String("partitionName", manager.pc.Name))
@@ -99,6 +125,8 @@ func (manager partitionManager) cleanQueues(queue *objects.Queue) {
 				log.Logger().Debug("unexpected failure removing the queue",
 					zap.String("partitionName", manager.pc.Name),
 					zap.String("queue", queue.QueuePath))
+			} else {
+				manager.pc.markStateChanged()
 			}
 		} else {
 			// TODO time out waiting for draining and removal
@@ -155,3 +183,13 @@ func (manager partitionManager) cleanupExpiredApps() {
 		time.Sleep(appRemovalInterval)
 	}
 }
+
+func (manager partitionManager) reconcile() {
+	for {
+		time.Sleep(reconcileInterval)
+		if manager.stop {
+			break
+		}
+		manager.pc.reconcile()
+	}
+}
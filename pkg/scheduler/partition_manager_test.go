@@ -0,0 +1,55 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+func TestCleanQueuesIdle(t *testing.T) {
+	partition, err := newPlacementPartition()
+	assert.NilError(t, err, "partition create failed")
+	manager := partitionManager{pc: partition}
+
+	// add an app to create the dynamic queue
+	app := newApplicationTGTags(appID1, "default", "unknown", nil, map[string]string{"taskqueue": "dynamic"})
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "app-1 should have been added to the partition")
+	queue := partition.GetQueue("root.dynamic")
+	assert.Assert(t, queue != nil, "dynamic queue should have been created")
+
+	// queue with an app is never removed
+	manager.cleanQueues(partition.root)
+	assert.Assert(t, partition.GetQueue("root.dynamic") != nil, "dynamic queue with app should not be removed")
+
+	// empty queue is kept until it has been idle for the timeout
+	partition.removeApplication(appID1)
+	partition.queueIdleTimeout = time.Hour
+	manager.cleanQueues(partition.root)
+	assert.Assert(t, partition.GetQueue("root.dynamic") != nil, "dynamic queue should not be removed before the idle timeout")
+
+	// no timeout removes the empty queue
+	partition.queueIdleTimeout = 0
+	manager.cleanQueues(partition.root)
+	assert.Assert(t, partition.GetQueue("root.dynamic") == nil, "empty dynamic queue should have been removed")
+	assert.Assert(t, partition.GetQueue("root") != nil, "managed root queue should not be removed")
+}
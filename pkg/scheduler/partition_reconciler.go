@@ -0,0 +1,167 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"go.uber.org/zap"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+	"github.com/apache/incubator-yunikorn-core/pkg/log"
+	"github.com/apache/incubator-yunikorn-core/pkg/metrics"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/objects"
+)
+
+// Discrepancy types found by the reconciler
+const (
+	reconcileNodeSnapshot    = "nodeSnapshot"    // node snapshot does not match the nodes of the partition
+	reconcileAllocationCount = "allocationCount" // allocation counter does not match the allocations on the nodes
+	reconcileNodeAllocation  = "nodeAllocation"  // allocation on a node that is not tracked by an application
+	reconcileAppAllocation   = "appAllocation"   // allocation of an application that is not tracked by its node
+	reconcileQueueAllocated  = "queueAllocated"  // leaf queue allocated resource does not match its applications
+)
+
+// Compare the state kept in the partition with the state of the nodes, applications and queues.
+// State that is derived by the partition is repaired:
+// - the node snapshot is rebuilt when it does not match the nodes
+// - the allocation counter is reset when it differs from the node allocations in two consecutive runs
+// All other discrepancies are reported only: they could be caused by scheduling that runs concurrently.
+// Returns the number of discrepancies found by type, all discrepancies are also added to the metrics.
+func (pc *PartitionContext) reconcile() map[string]int {
+	found := make(map[string]int)
+	pc.Lock()
+	if !pc.nodeSnapshotInSync() {
+		found[reconcileNodeSnapshot]++
+		pc.refreshNodeSnapshot()
+	}
+	nodes := make([]*objects.Node, 0, len(pc.nodes))
+	for _, node := range pc.nodes {
+		nodes = append(nodes, node)
+	}
+	apps := make(map[string]*objects.Application, len(pc.applications))
+	for appID, app := range pc.applications {
+		apps[appID] = app
+	}
+	pc.Unlock()
+
+	// node and application allocations must match
+	nodeAllocs := make(map[string]*objects.Allocation)
+	for _, node := range nodes {
+		for _, alloc := range node.GetAllAllocations() {
+			nodeAllocs[alloc.UUID] = alloc
+		}
+	}
+	appAllocs := make(map[string]bool)
+	for _, app := range apps {
+		for _, alloc := range app.GetAllAllocations() {
+			appAllocs[alloc.UUID] = true
+			if _, ok := nodeAllocs[alloc.UUID]; !ok {
+				found[reconcileAppAllocation]++
+				log.Logger().Warn("application allocation not found on the node",
+					zap.String("partition", pc.Name),
+					zap.String("appID", app.ApplicationID),
+					zap.String("nodeID", alloc.NodeID),
+					zap.String("allocationId", alloc.UUID))
+			}
+		}
+	}
+	for uuid, alloc := range nodeAllocs {
+		if !appAllocs[uuid] {
+			found[reconcileNodeAllocation]++
+			log.Logger().Warn("node allocation not tracked by an application",
+				zap.String("partition", pc.Name),
+				zap.String("appID", alloc.ApplicationID),
+				zap.String("nodeID", alloc.NodeID),
+				zap.String("allocationId", uuid))
+		}
+	}
+	found[reconcileQueueAllocated] += pc.reconcileQueue(pc.root)
+
+	// only reset the counter if the difference is stable between runs
+	pc.Lock()
+	if diff := len(nodeAllocs) - pc.allocations; diff != 0 {
+		found[reconcileAllocationCount]++
+		if diff == pc.allocationCountDiff {
+			log.Logger().Warn("resetting partition allocation count",
+				zap.String("partition", pc.Name),
+				zap.Int("count", pc.allocations),
+				zap.Int("nodeAllocations", len(nodeAllocs)))
+			pc.allocations = len(nodeAllocs)
+			diff = 0
+		}
+		pc.allocationCountDiff = diff
+	} else {
+		pc.allocationCountDiff = 0
+	}
+	pc.Unlock()
+
+	for kind, count := range found {
+		if count == 0 {
+			delete(found, kind)
+			continue
+		}
+		metrics.GetSchedulerMetrics().AddReconcileDiscrepancies(pc.Name, kind, count)
+	}
+	if len(found) != 0 {
+		log.Logger().Info("partition reconciler found discrepancies",
+			zap.String("partition", pc.Name),
+			zap.Any("discrepancies", found))
+	}
+	return found
+}
+
+// Check the leaf queues below the queue: the allocated resource of a leaf must be the sum of the allocated
+// and placeholder resources of its applications. Returns the number of leaf queues that do not match.
+func (pc *PartitionContext) reconcileQueue(queue *objects.Queue) int {
+	if !queue.IsLeafQueue() {
+		mismatch := 0
+		for _, child := range queue.GetCopyOfChildren() {
+			mismatch += pc.reconcileQueue(child)
+		}
+		return mismatch
+	}
+	expected := resources.NewResource()
+	for _, app := range queue.GetCopyOfApps() {
+		expected.AddTo(app.GetAllocatedResource())
+		expected.AddTo(app.GetPlaceholderResource())
+	}
+	if allocated := queue.GetAllocatedResource(); !resources.EqualsOrEmpty(allocated, expected) {
+		log.Logger().Warn("queue allocated resource does not match its applications",
+			zap.String("partition", pc.Name),
+			zap.String("queue", queue.QueuePath),
+			zap.Stringer("allocated", allocated),
+			zap.Stringer("applications", expected))
+		return 1
+	}
+	return 0
+}
+
+// Check that the node snapshot contains exactly the nodes of the partition.
+// NOTE: this is a lock free call. It must only be called holding the PartitionContext lock.
+func (pc *PartitionContext) nodeSnapshotInSync() bool {
+	snapshot := pc.getNodeSnapshot()
+	if len(snapshot) != len(pc.nodes) {
+		return false
+	}
+	for _, node := range snapshot {
+		if pc.nodes[node.NodeID] != node {
+			return false
+		}
+	}
+	return true
+}
@@ -19,6 +19,7 @@
 package scheduler
 
 import (
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -29,10 +30,13 @@ import (
 
 	"github.com/apache/incubator-yunikorn-core/pkg/common"
 	"github.com/apache/incubator-yunikorn-core/pkg/common/configs"
+	"github.com/apache/incubator-yunikorn-core/pkg/common/features"
 	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
 	"github.com/apache/incubator-yunikorn-core/pkg/common/security"
+	"github.com/apache/incubator-yunikorn-core/pkg/metrics"
 	"github.com/apache/incubator-yunikorn-core/pkg/plugins"
 	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/objects"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/policies"
 	"github.com/apache/incubator-yunikorn-scheduler-interface/lib/go/si"
 )
 
@@ -161,6 +165,92 @@ func TestNewWithPlacement(t *testing.T) {
 	assert.Equal(t, len(*partition.rules), 2, "Placement rules not updated as expected ")
 }
 
+func TestUpdateNodeSortingPolicy(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "test partition create failed with error")
+	assert.Equal(t, partition.GetNodeSortingPolicy(), policies.FairnessPolicy, "default policy should be fair")
+	assert.Equal(t, partition.GetNodeSortingPolicyName(), "fair", "unexpected policy name")
+
+	err = policies.RegisterNodeSortingPolicy("custom-test", func(l, r policies.SortableNode) bool { return false })
+	assert.NilError(t, err, "custom policy registration failed")
+	defer policies.UnregisterNodeSortingPolicy("custom-test")
+	conf := configs.PartitionConfig{
+		Name: "test",
+		Queues: []configs.QueueConfig{
+			{
+				Name:      "root",
+				Parent:    true,
+				SubmitACL: "*",
+				Queues:    nil,
+			},
+		},
+		NodeSortPolicy: configs.NodeSortingPolicy{Type: "custom-test"},
+	}
+	err = partition.updatePartitionDetails(conf)
+	assert.NilError(t, err, "update partition failed unexpected with error")
+	assert.Equal(t, partition.GetNodeSortingPolicy(), policies.CustomPolicy, "policy should be custom after reload")
+	assert.Equal(t, partition.GetNodeSortingPolicyName(), "custom-test", "unexpected policy name after reload")
+
+	conf.NodeSortPolicy = configs.NodeSortingPolicy{Type: "binpacking"}
+	err = partition.updatePartitionDetails(conf)
+	assert.NilError(t, err, "update partition failed unexpected with error")
+	assert.Equal(t, partition.GetNodeSortingPolicy(), policies.BinPackingPolicy, "policy should be binpacking after reload")
+}
+
+func TestPartitionConfigTime(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "test partition create failed with error")
+	loaded := partition.GetConfigTime()
+	assert.Assert(t, !loaded.IsZero(), "config time should be set on create")
+
+	conf := configs.PartitionConfig{
+		Name: "test",
+		Queues: []configs.QueueConfig{
+			{
+				Name:   "root",
+				Parent: true,
+			},
+		},
+	}
+	time.Sleep(time.Millisecond)
+	err = partition.updatePartitionDetails(conf)
+	assert.NilError(t, err, "update partition failed unexpected with error")
+	reloaded := partition.GetConfigTime()
+	assert.Assert(t, reloaded.After(loaded), "config time should be updated on reload")
+
+	// a failed reload does not change the time
+	err = partition.updatePartitionDetails(configs.PartitionConfig{Name: "test"})
+	assert.ErrorContains(t, err, "without root queue")
+	assert.Equal(t, partition.GetConfigTime(), reloaded, "config time should not change on a failed reload")
+}
+
+func TestNodeIteratorTieBreak(t *testing.T) {
+	partition := createQueuesNodes(t)
+	firstNode := func() string {
+		it := partition.GetNodeIterator()
+		assert.Assert(t, it != nil, "node iterator should have been returned")
+		node, ok := it.Next().(*objects.Node)
+		assert.Assert(t, ok, "iterator should return a node")
+		return node.NodeID
+	}
+	// default: equal nodes keep the same order
+	first := firstNode()
+	assert.Equal(t, firstNode(), first, "without tie break the first node should not change")
+
+	// round robin: the start rotates over the equal nodes
+	partition.setNodeSortingPolicy(configs.NodeSortingPolicy{Type: "fair", TieBreak: "RoundRobin"})
+	first = firstNode()
+	assert.Assert(t, firstNode() != first, "round robin tie break should rotate the first node")
+	assert.Equal(t, firstNode(), first, "round robin tie break should wrap around the equal nodes")
+
+	// unequal nodes are not rotated: allocate on node-1 to make node-2 the only best node
+	res, err := resources.NewResourceFromConf(map[string]string{"first": "5"})
+	assert.NilError(t, err, "failed to create resource")
+	partition.GetNode("node-1").AddAllocation(objects.NewAllocation("alloc-uuid", "node-1", newAllocationAsk("alloc-1", "app-1", res)))
+	assert.Equal(t, firstNode(), "node-2", "node with more available resources should be first")
+	assert.Equal(t, firstNode(), "node-2", "node with more available resources should be first")
+}
+
 func TestAddNode(t *testing.T) {
 	partition, err := newBasePartition()
 	assert.NilError(t, err, "test partition create failed with error")
@@ -266,6 +356,32 @@ func TestRemoveNode(t *testing.T) {
 	assert.Equal(t, 0, len(partition.nodes), "node was not removed")
 }
 
+func TestNodeSnapshot(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "test partition create failed with error")
+	assert.Equal(t, 0, len(partition.getNodeSnapshot()), "new partition should have an empty snapshot")
+	err = partition.AddNode(newNodeMaxResource("node-1", resources.NewResource()), nil)
+	assert.NilError(t, err, "test node add failed unexpected")
+	before := partition.getNodeSnapshot()
+	assert.Equal(t, 1, len(before), "snapshot not updated on node add")
+	err = partition.AddNode(newNodeMaxResource("node-2", resources.NewResource()), nil)
+	assert.NilError(t, err, "test node add failed unexpected")
+	assert.Equal(t, 2, len(partition.getNodeSnapshot()), "snapshot not updated on node add")
+	// the old snapshot must not have changed
+	assert.Equal(t, 1, len(before), "existing snapshot changed on node add")
+	assert.Equal(t, "node-1", before[0].NodeID, "existing snapshot content changed")
+
+	_ = partition.removeNode("node-1")
+	after := partition.getNodeSnapshot()
+	assert.Equal(t, 1, len(after), "snapshot not updated on node remove")
+	assert.Equal(t, "node-2", after[0].NodeID, "wrong node left in snapshot")
+
+	// unschedulable nodes are in the snapshot but filtered from the schedulable list
+	after[0].SetSchedulable(false)
+	assert.Equal(t, 1, len(partition.getNodeSnapshot()), "snapshot should contain all nodes")
+	assert.Equal(t, 0, len(partition.getSchedulableNodes()), "unschedulable node returned")
+}
+
 func TestRemoveNodeWithAllocations(t *testing.T) {
 	partition, err := newBasePartition()
 	assert.NilError(t, err, "partition create failed")
@@ -304,6 +420,162 @@ func TestRemoveNodeWithAllocations(t *testing.T) {
 	assert.Equal(t, released[0].UUID, allocUUID, "UUID returned by release not the same as on allocation")
 }
 
+func TestDrainNode(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	app := newApplication(appID1, "default", defQueue)
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "add application to partition should not have failed")
+
+	nodeRes := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1000})
+	node := newNodeMaxResource(nodeID1, nodeRes)
+	appRes := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})
+	ask := newAllocationAsk("alloc-1", appID1, appRes)
+	alloc := objects.NewAllocation("alloc-1-uuid", nodeID1, ask)
+	err = partition.AddNode(node, []*objects.Allocation{alloc})
+	assert.NilError(t, err, "add node to partition should not have failed")
+	// reserve the node for a second ask
+	ask = newAllocationAsk("alloc-2", appID1, appRes)
+	err = app.AddAllocationAsk(ask)
+	assert.NilError(t, err, "ask should have been added to app")
+	partition.reserve(app, node, ask)
+	assert.Assert(t, node.IsReserved(), "node should have been reserved")
+
+	_, err = partition.drainNode("unknown", false)
+	assert.ErrorContains(t, err, "not found")
+
+	// drain without preemption keeps the allocations
+	var released []*objects.Allocation
+	released, err = partition.drainNode(nodeID1, false)
+	assert.NilError(t, err, "drain should not have failed")
+	assert.Equal(t, len(released), 0, "drain without preemption should not release allocations")
+	assert.Assert(t, !node.IsSchedulable(), "drained node should be unschedulable")
+	assert.Assert(t, !node.IsReserved(), "reservations should have been removed")
+	assert.Equal(t, len(partition.reservedApps), 0, "partition reservations should have been removed")
+	start, initial, remaining := node.GetDrainProgress()
+	assert.Assert(t, !start.IsZero(), "drain start should have been set")
+	assert.Equal(t, initial, 1, "unexpected initial allocations")
+	assert.Equal(t, remaining, 1, "unexpected remaining allocations")
+
+	// drain with preemption releases the allocations, the node stays
+	released, err = partition.drainNode(nodeID1, true)
+	assert.NilError(t, err, "drain should not have failed")
+	assert.Equal(t, len(released), 1, "allocation should have been released")
+	assert.Equal(t, partition.GetTotalNodeCount(), 1, "drained node should not have been removed")
+	assert.Assert(t, resources.IsZero(app.GetAllocatedResource()), "app allocation should have been removed")
+	_, initial, remaining = node.GetDrainProgress()
+	assert.Equal(t, initial, 1, "second drain should not reset the progress")
+	assert.Equal(t, remaining, 0, "allocations should have been removed from the node")
+}
+
+func TestGetQueueUsage(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	siApp := &si.AddApplicationRequest{
+		ApplicationID: appID1,
+		QueueName:     defQueue,
+		PartitionName: "default",
+	}
+	app := objects.NewApplication(siApp, security.UserGroup{User: "user1"}, nil, rmID)
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "add application to partition should not have failed")
+	nodeRes := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1000})
+	appRes := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})
+	alloc := objects.NewAllocation("alloc-1-uuid", nodeID1, newAllocationAsk("alloc-1", appID1, appRes))
+	err = partition.AddNode(newNodeMaxResource(nodeID1, nodeRes), []*objects.Allocation{alloc})
+	assert.NilError(t, err, "add node to partition should not have failed")
+
+	usage := partition.GetQueueUsage()
+	assert.Assert(t, len(usage) > 1, "expected the usage of the root and its children")
+	assert.Equal(t, usage[0].QueuePath, "root", "root should be first")
+	for _, queue := range usage {
+		if queue.QueuePath != "root" && queue.QueuePath != defQueue {
+			assert.Equal(t, len(queue.Users), 0, "queue %s should not have users", queue.QueuePath)
+			continue
+		}
+		assert.Equal(t, len(queue.Users), 1, "queue %s should have one user", queue.QueuePath)
+		assert.Assert(t, resources.Equals(queue.Users["user1"], appRes), "unexpected user usage for %s", queue.QueuePath)
+	}
+}
+
+func TestKillApplication(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	app := newApplication(appID1, "default", defQueue)
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "add application to partition should not have failed")
+
+	nodeRes := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1000})
+	node := newNodeMaxResource(nodeID1, nodeRes)
+	appRes := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})
+	ask := newAllocationAsk("alloc-1", appID1, appRes)
+	alloc := objects.NewAllocation("alloc-1-uuid", nodeID1, ask)
+	err = partition.AddNode(node, []*objects.Allocation{alloc})
+	assert.NilError(t, err, "add node to partition should not have failed")
+	// reserve the node for a second ask
+	ask = newAllocationAsk("alloc-2", appID1, appRes)
+	err = app.AddAllocationAsk(ask)
+	assert.NilError(t, err, "ask should have been added to app")
+	partition.reserve(app, node, ask)
+	assert.Assert(t, node.IsReserved(), "node should have been reserved")
+
+	_, _, err = partition.killApplication("unknown", "test")
+	assert.ErrorContains(t, err, "not found")
+
+	var killed *objects.Application
+	var released []*objects.Allocation
+	killed, released, err = partition.killApplication(appID1, "test")
+	assert.NilError(t, err, "kill should not have failed")
+	assert.Equal(t, killed, app, "unexpected application returned")
+	assert.Equal(t, len(released), 1, "allocation should have been released")
+	assert.Assert(t, app.IsFailed(), "killed application should be failed: %s", app.CurrentState())
+	assert.Assert(t, resources.IsZero(app.GetAllocatedResource()), "app allocation should have been removed")
+	assert.Assert(t, resources.IsZero(app.GetPendingResource()), "app asks should have been removed")
+	assert.Assert(t, !node.IsReserved(), "reservations should have been removed")
+	assert.Equal(t, len(partition.getReservations()), 0, "partition reservations should have been removed")
+	assert.Equal(t, len(node.GetAllAllocations()), 0, "allocations should have been removed from the node")
+	assert.Equal(t, partition.GetTotalAllocationCount(), 0, "allocation count should have been updated")
+	err = common.WaitFor(10*time.Millisecond, time.Second, func() bool {
+		return len(partition.GetCompletedApplications()) == 1
+	})
+	assert.NilError(t, err, "killed application should have been moved to the completed applications")
+
+	// a terminated application is no longer tracked and cannot be killed again
+	_, _, err = partition.killApplication(appID1, "test")
+	assert.ErrorContains(t, err, "not found")
+}
+
+// changes that are not the result of an RM event or scheduling must change the scheduler state version
+func TestStateChangedOutsideEvents(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	cc := &ClusterContext{
+		partitions: map[string]*PartitionContext{partition.Name: partition},
+	}
+	partition.partitionManager.cc = cc
+	app := newApplication(appID1, "default", defQueue)
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "add application to partition should not have failed")
+
+	version := cc.GetStateVersion()
+	app.SetIdle()
+	assert.Assert(t, cc.GetStateVersion() > version, "idle application should have changed the state")
+	version = cc.GetStateVersion()
+	err = app.HandleApplicationEvent(objects.RunApplication)
+	assert.NilError(t, err, "application state change should not have failed")
+	assert.Assert(t, cc.GetStateVersion() > version, "application state change should have changed the state")
+
+	// nothing to clean up is not a change
+	version = cc.GetStateVersion()
+	partition.cleanupExpiredApps()
+	partition.cleanupCompletedApps()
+	assert.Equal(t, cc.GetStateVersion(), version, "clean up without removals should not have changed the state")
+
+	_, err = cc.KillApplication(partition, appID1)
+	assert.NilError(t, err, "kill should not have failed")
+	assert.Assert(t, cc.GetStateVersion() > version, "killed application should have changed the state")
+}
+
 func TestGetNodes(t *testing.T) {
 	partition, err := newBasePartition()
 	assert.NilError(t, err, "test partition create failed with error")
@@ -398,6 +670,45 @@ func TestAddApp(t *testing.T) {
 	}
 }
 
+func TestAddAppRejectionMetrics(t *testing.T) {
+	conf := configs.PartitionConfig{
+		Name: "rejections",
+		Queues: []configs.QueueConfig{
+			{
+				Name:   "root",
+				Parent: true,
+				Queues: []configs.QueueConfig{
+					{Name: "open", SubmitACL: "*"},
+					{Name: "closed", SubmitACL: "admin"},
+				},
+			},
+		},
+	}
+	partition, err := newPartitionContext(conf, rmID, nil)
+	assert.NilError(t, err, "partition create failed")
+	assertRejected := func(queue, reason string, expected int) {
+		count, getErr := metrics.GetSchedulerMetrics().GetApplicationRejected(partition.Name, queue, reason)
+		assert.NilError(t, getErr, "failed to read rejection metric")
+		assert.Equal(t, count, expected, "unexpected rejection count for queue %s and reason %s", queue, reason)
+	}
+
+	err = partition.AddApplication(newApplication(appID1, "rejections", "root.open"))
+	assert.NilError(t, err, "add application to partition should not have failed")
+	err = partition.AddApplication(newApplication(appID1, "rejections", "root.open"))
+	assert.Assert(t, err != nil, "duplicate application should have been rejected")
+	assertRejected("root.open", rejectedDuplicateID, 1)
+	err = partition.AddApplication(newApplication(appID2, "rejections", "root.closed"))
+	assert.Assert(t, err != nil, "application without access should have been rejected")
+	assertRejected("root.closed", rejectedACL, 1)
+	err = partition.AddApplication(newApplication(appID2, "rejections", "root"))
+	assert.Assert(t, err != nil, "application in a parent queue should have been rejected")
+	assertRejected("root", rejectedConfig, 1)
+	err = partition.AddApplication(newApplication(appID2, "rejections", "root.unknown"))
+	assert.Assert(t, err != nil, "application in an unknown queue should have been rejected")
+	assertRejected("root.unknown", rejectedConfig, 1)
+	assertRejected("root.open", rejectedACL, 0)
+}
+
 func TestAddAppTaskGroup(t *testing.T) {
 	partition, err := newBasePartition()
 	assert.NilError(t, err, "partition create failed")
@@ -760,6 +1071,150 @@ func TestGetQueue(t *testing.T) {
 	assert.Equal(t, queue, parent, "partition returned nil for existing queue name request")
 }
 
+func TestTryAllocateNodeAttributes(t *testing.T) {
+	partition := createQueuesNodes(t)
+	if partition == nil {
+		t.Fatal("partition create failed")
+	}
+	partition.GetNode(nodeID2).SetAttributes(map[string]string{"zone": "zone-b"})
+
+	app := newApplication(appID1, "default", "root.leaf")
+	err := partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app-1 to partition")
+	res, err := resources.NewResourceFromConf(map[string]string{"first": "1"})
+	assert.NilError(t, err, "failed to create resource")
+
+	// ask that cannot be placed on any node
+	ask := objects.NewAllocationAsk(&si.AllocationAsk{
+		AllocationKey:  "alloc-1",
+		ApplicationID:  appID1,
+		ResourceAsk:    res.ToProto(),
+		MaxAllocations: 1,
+		Tags:           map[string]string{objects.NodeAttributeTagPrefix + "zone": "zone-c"},
+	})
+	err = app.AddAllocationAsk(ask)
+	assert.NilError(t, err, "failed to add ask alloc-1 to app-1")
+	if alloc := partition.tryAllocate(); alloc != nil {
+		t.Fatalf("ask without matching node should not have been allocated: %v", alloc)
+	}
+	app.RemoveAllocationAsk("alloc-1")
+
+	// ask that can only be placed on node-2
+	ask = objects.NewAllocationAsk(&si.AllocationAsk{
+		AllocationKey:  "alloc-2",
+		ApplicationID:  appID1,
+		ResourceAsk:    res.ToProto(),
+		MaxAllocations: 1,
+		Tags:           map[string]string{objects.NodeAttributeTagPrefix + "zone": "zone-b"},
+	})
+	err = app.AddAllocationAsk(ask)
+	assert.NilError(t, err, "failed to add ask alloc-2 to app-1")
+	alloc := partition.tryAllocate()
+	if alloc == nil {
+		t.Fatal("allocation did not return any allocation")
+	}
+	assert.Equal(t, alloc.Result, objects.Allocated, "result is not the expected allocated")
+	assert.Equal(t, alloc.NodeID, nodeID2, "ask should have been allocated on the node with the attribute")
+	// both nodes are evaluated when node-1 is sorted first, only node-2 if it is sorted first
+	alloc.SetSchedulingCycle(1)
+	tags := alloc.NewSIFromAllocation().AllocationTags
+	evaluated := tags[objects.TelemetryNodesEvaluated]
+	assert.Assert(t, evaluated == "1" || evaluated == "2", "unexpected number of nodes evaluated: %s", evaluated)
+	assert.Equal(t, tags[objects.TelemetryCycleID], "1", "unexpected scheduling cycle")
+}
+
+func TestTryAllocateTaints(t *testing.T) {
+	partition := createQueuesNodes(t)
+	if partition == nil {
+		t.Fatal("partition create failed")
+	}
+	partition.GetNode(nodeID1).SetTaints(map[string]string{"dedicated": "gpu"})
+	partition.GetNode(nodeID2).SetAttributes(map[string]string{objects.NodeTaintsAttribute: "dedicated=batch"})
+
+	app := newApplication(appID1, "default", "root.leaf")
+	err := partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app-1 to partition")
+	res, err := resources.NewResourceFromConf(map[string]string{"first": "1"})
+	assert.NilError(t, err, "failed to create resource")
+
+	// ask without tolerations cannot be placed on a tainted node
+	ask := objects.NewAllocationAsk(&si.AllocationAsk{
+		AllocationKey:  "alloc-1",
+		ApplicationID:  appID1,
+		ResourceAsk:    res.ToProto(),
+		MaxAllocations: 1,
+	})
+	err = app.AddAllocationAsk(ask)
+	assert.NilError(t, err, "failed to add ask alloc-1 to app-1")
+	if alloc := partition.tryAllocate(); alloc != nil {
+		t.Fatalf("ask without tolerations should not have been allocated: %v", alloc)
+	}
+	app.RemoveAllocationAsk("alloc-1")
+
+	// ask that only tolerates the taint on node-2
+	ask = objects.NewAllocationAsk(&si.AllocationAsk{
+		AllocationKey:  "alloc-2",
+		ApplicationID:  appID1,
+		ResourceAsk:    res.ToProto(),
+		MaxAllocations: 1,
+		Tags:           map[string]string{objects.NodeTolerationTagPrefix + "dedicated": "batch"},
+	})
+	err = app.AddAllocationAsk(ask)
+	assert.NilError(t, err, "failed to add ask alloc-2 to app-1")
+	alloc := partition.tryAllocate()
+	if alloc == nil {
+		t.Fatal("allocation did not return any allocation")
+	}
+	assert.Equal(t, alloc.Result, objects.Allocated, "result is not the expected allocated")
+	assert.Equal(t, alloc.NodeID, nodeID2, "ask should have been allocated on the tolerated node")
+}
+
+func TestTryAllocateSpread(t *testing.T) {
+	partition := createQueuesNodes(t)
+	if partition == nil {
+		t.Fatal("partition create failed")
+	}
+	res, err := resources.NewResourceFromConf(map[string]string{"first": "1"})
+	assert.NilError(t, err, "failed to create resource")
+
+	// max one allocation per node: 2 nodes means 2 out of 3 asks are allocated
+	app := newApplicationTGTags(appID1, "default", "root.leaf", nil, map[string]string{objects.SpreadMaxTag: "1"})
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app-1 to partition")
+	err = app.AddAllocationAsk(newAllocationAskRepeat("alloc-1", appID1, res, 3))
+	assert.NilError(t, err, "failed to add ask alloc-1 to app-1")
+	nodes := make(map[string]bool)
+	for i := 0; i < 2; i++ {
+		alloc := partition.tryAllocate()
+		if alloc == nil {
+			t.Fatalf("allocation %d did not return any allocation", i)
+		}
+		nodes[alloc.NodeID] = true
+	}
+	assert.Equal(t, len(nodes), 2, "allocations should have been spread over the nodes")
+	if alloc := partition.tryAllocate(); alloc != nil {
+		t.Fatalf("spread constraint should have prevented allocation: %v", alloc)
+	}
+
+	// max two allocations per zone: both nodes in the same zone, other task group is not limited by app-1
+	partition.GetNode(nodeID1).SetAttributes(map[string]string{"zone": "zone-a"})
+	partition.GetNode(nodeID2).SetAttributes(map[string]string{"zone": "zone-a"})
+	app = newApplicationTGTags(appID2, "default", "root.leaf", nil, map[string]string{objects.SpreadMaxTag: "2", objects.SpreadKeyTag: "zone"})
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app-2 to partition")
+	err = app.AddAllocationAsk(newAllocationAskRepeat("alloc-1", appID2, res, 3))
+	assert.NilError(t, err, "failed to add ask alloc-1 to app-2")
+	for i := 0; i < 2; i++ {
+		alloc := partition.tryAllocate()
+		if alloc == nil || alloc.ApplicationID != appID2 {
+			t.Fatalf("allocation %d did not return app-2 allocation: %v", i, alloc)
+		}
+	}
+	if alloc := partition.tryAllocate(); alloc != nil {
+		t.Fatalf("spread constraint should have prevented allocation in the zone: %v", alloc)
+	}
+}
+
 func TestTryAllocate(t *testing.T) {
 	partition := createQueuesNodes(t)
 	if partition == nil {
@@ -825,48 +1280,166 @@ func TestTryAllocate(t *testing.T) {
 	assert.Assert(t, resources.IsZero(partition.root.GetPendingResource()), "pending resources should be set to zero")
 }
 
-func TestTryAllocateLarge(t *testing.T) {
+func TestTryAllocateParallel(t *testing.T) {
 	partition := createQueuesNodes(t)
 	if partition == nil {
 		t.Fatal("partition create failed")
 	}
-	if alloc := partition.tryAllocate(); alloc != nil {
-		t.Fatalf("empty cluster allocate returned allocation: %v", alloc.String())
+	assert.Equal(t, partition.getParallelWorkers(), 0, "parallel allocation should be off by default")
+	partition.setParallelAllocation(configs.ParallelAllocationConfig{Enabled: true, Workers: 2})
+	assert.Equal(t, partition.getParallelWorkers(), 2, "parallel workers not set from config")
+	if allocs := partition.tryAllocateParallel(); len(allocs) != 0 {
+		t.Fatalf("empty cluster allocate returned allocations: %v", allocs)
 	}
 
-	// override the reservation delay, and cleanup when done
-	objects.SetReservationDelay(10 * time.Nanosecond)
-	defer objects.SetReservationDelay(2 * time.Second)
-
-	res, err := resources.NewResourceFromConf(map[string]string{"first": "100"})
+	res, err := resources.NewResourceFromConf(map[string]string{"first": "6"})
 	assert.NilError(t, err, "failed to create resource")
-
+	// two apps in two different leaf queues
 	app := newApplication(appID1, "default", "root.parent.sub-leaf")
-	// add to the partition
 	err = partition.AddApplication(app)
 	assert.NilError(t, err, "failed to add app-1 to partition")
+	err = app.AddAllocationAsk(newAllocationAskRepeat("alloc-1", appID1, res, 2))
+	assert.NilError(t, err, "failed to add ask alloc-1 to app-1")
+	app = newApplication(appID2, "default", "root.leaf")
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app-2 to partition")
+	err = app.AddAllocationAsk(newAllocationAskRepeat("alloc-1", appID2, res, 2))
+	assert.NilError(t, err, "failed to add ask alloc-1 to app-2")
 
-	err = app.AddAllocationAsk(newAllocationAsk("alloc-1", appID1, res))
-	assert.NilError(t, err, "failed to add large ask to app")
-	assert.Assert(t, resources.Equals(res, app.GetPendingResource()), "pending resource not set as expected")
-	alloc := partition.tryAllocate()
-	if alloc != nil {
-		t.Fatalf("allocation did return allocation which does not fit: %s", alloc)
+	// both leaf queues get an allocation in one cycle and the node capacity is respected
+	allocs := partition.tryAllocateParallel()
+	assert.Equal(t, len(allocs), 2, "expected an allocation for each leaf queue")
+	assert.Assert(t, allocs[0].ApplicationID != allocs[1].ApplicationID, "both allocations for the same app")
+	assert.Assert(t, allocs[0].NodeID != allocs[1].NodeID, "both allocations on the same node")
+	assert.Equal(t, partition.GetTotalAllocationCount(), 2, "allocation count not updated")
+	// no more space on the nodes
+	allocs = partition.tryAllocateParallel()
+	assert.Equal(t, len(allocs), 0, "nodes are full no allocations expected")
+
+	partition.setParallelAllocation(configs.ParallelAllocationConfig{Enabled: false, Workers: 2})
+	assert.Equal(t, partition.getParallelWorkers(), 0, "parallel allocation should be off when disabled")
+}
+
+func TestGroupDisjointLeafs(t *testing.T) {
+	conf := configs.PartitionConfig{
+		Name: "test",
+		Queues: []configs.QueueConfig{
+			{
+				Name:      "root",
+				Parent:    true,
+				SubmitACL: "*",
+				Queues: []configs.QueueConfig{
+					{
+						Name:   "limited",
+						Parent: true,
+						Resources: configs.Resources{
+							Max: map[string]string{"first": "5"},
+						},
+						Queues: []configs.QueueConfig{{Name: "leaf1"}, {Name: "leaf2"}},
+					},
+					{
+						Name:   "open",
+						Parent: true,
+						Queues: []configs.QueueConfig{{Name: "leaf3"}, {Name: "leaf4"}},
+					},
+				},
+			},
+		},
 	}
-	assert.Equal(t, 0, len(app.GetReservations()), "ask should not have been reserved")
+	partition, err := newPartitionContext(conf, "test", nil)
+	assert.NilError(t, err, "partition create failed")
+	leafs := make([]*objects.Queue, 0)
+	for _, name := range []string{"root.limited.leaf1", "root.open.leaf3", "root.limited.leaf2", "root.open.leaf4"} {
+		queue := partition.GetQueue(name)
+		assert.Assert(t, queue != nil, "queue %s not found", name)
+		leafs = append(leafs, queue)
+	}
+	// leafs of the limited parent share a group, the others are independent
+	groups := groupDisjointLeafs(leafs)
+	assert.DeepEqual(t, groups, [][]int{{0, 2}, {1}, {3}})
+	assert.Equal(t, len(groupDisjointLeafs(nil)), 0, "no leafs should give no groups")
 }
 
-func TestAllocReserveNewNode(t *testing.T) {
+func TestPartitionCounters(t *testing.T) {
 	partition := createQueuesNodes(t)
 	if partition == nil {
 		t.Fatal("partition create failed")
 	}
-	if alloc := partition.tryAllocate(); alloc != nil {
-		t.Fatalf("empty cluster allocate returned allocation: %s", alloc)
-	}
-
-	// override the reservation delay, and cleanup when done
-	objects.SetReservationDelay(10 * time.Nanosecond)
+	res, err := resources.NewResourceFromConf(map[string]string{"first": "1"})
+	assert.NilError(t, err, "failed to create resource")
+	app := newApplication(appID1, "default", "root.leaf")
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app-1 to partition")
+	err = app.AddAllocationAsk(newAllocationAskRepeat("alloc-1", appID1, res, 2))
+	assert.NilError(t, err, "failed to add ask alloc-1 to app-1")
+	alloc := partition.tryAllocate()
+	if alloc == nil {
+		t.Fatal("allocation did not return any allocation")
+	}
+	if partition.tryAllocate() == nil {
+		t.Fatal("allocation did not return any allocation")
+	}
+	release := &si.AllocationRelease{
+		PartitionName:   "default",
+		ApplicationID:   appID1,
+		UUID:            alloc.UUID,
+		TerminationType: si.TerminationType_PREEMPTED_BY_SCHEDULER,
+	}
+	released, _ := partition.removeAllocation(release)
+	assert.Equal(t, len(released), 1, "allocation not released")
+
+	expected := map[string]int{counterAllocation: 2, counterRelease: 1, counterRejection: 0, counterPreemption: 1}
+	counters := partition.GetPartitionCounters()
+	assert.Equal(t, counters.Partition, partition.Name, "wrong partition name")
+	for _, counter := range counters.Counters {
+		for window, value := range counter.Windows {
+			assert.Equal(t, value, expected[counter.Event], "unexpected count for %s in window %s", counter.Event, window)
+		}
+	}
+}
+
+func TestTryAllocateLarge(t *testing.T) {
+	partition := createQueuesNodes(t)
+	if partition == nil {
+		t.Fatal("partition create failed")
+	}
+	if alloc := partition.tryAllocate(); alloc != nil {
+		t.Fatalf("empty cluster allocate returned allocation: %v", alloc.String())
+	}
+
+	// override the reservation delay, and cleanup when done
+	objects.SetReservationDelay(10 * time.Nanosecond)
+	defer objects.SetReservationDelay(2 * time.Second)
+
+	res, err := resources.NewResourceFromConf(map[string]string{"first": "100"})
+	assert.NilError(t, err, "failed to create resource")
+
+	app := newApplication(appID1, "default", "root.parent.sub-leaf")
+	// add to the partition
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app-1 to partition")
+
+	err = app.AddAllocationAsk(newAllocationAsk("alloc-1", appID1, res))
+	assert.NilError(t, err, "failed to add large ask to app")
+	assert.Assert(t, resources.Equals(res, app.GetPendingResource()), "pending resource not set as expected")
+	alloc := partition.tryAllocate()
+	if alloc != nil {
+		t.Fatalf("allocation did return allocation which does not fit: %s", alloc)
+	}
+	assert.Equal(t, 0, len(app.GetReservations()), "ask should not have been reserved")
+}
+
+func TestAllocReserveNewNode(t *testing.T) {
+	partition := createQueuesNodes(t)
+	if partition == nil {
+		t.Fatal("partition create failed")
+	}
+	if alloc := partition.tryAllocate(); alloc != nil {
+		t.Fatalf("empty cluster allocate returned allocation: %s", alloc)
+	}
+
+	// override the reservation delay, and cleanup when done
+	objects.SetReservationDelay(10 * time.Nanosecond)
 	defer objects.SetReservationDelay(2 * time.Second)
 
 	// turn off the second node
@@ -1249,6 +1822,37 @@ func TestUpdateNode(t *testing.T) {
 	}
 }
 
+func TestUpdateNodes(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "test partition create failed with error")
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 100, "vcore": 10})
+	for _, nodeID := range []string{nodeID1, nodeID2} {
+		err = partition.AddNode(newNodeMaxResource(nodeID, res), nil)
+		assert.NilError(t, err, "node add failed unexpected")
+	}
+	capacity := resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 150, "vcore": 20})
+	occupied := resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 10})
+	missing := partition.updateNodes([]*si.UpdateNodeInfo{
+		{NodeID: nodeID1, SchedulableResource: capacity.ToProto(), Action: si.UpdateNodeInfo_UPDATE},
+		{NodeID: nodeID2, SchedulableResource: capacity.ToProto(), OccupiedResource: occupied.ToProto(), Action: si.UpdateNodeInfo_UPDATE},
+		{NodeID: "unknown", SchedulableResource: capacity.ToProto(), Action: si.UpdateNodeInfo_UPDATE},
+		{NodeID: nodeID1, Attributes: map[string]string{"zone": "a"}, Action: si.UpdateNodeInfo_UPDATE},
+	})
+	assert.DeepEqual(t, missing, []string{"unknown"})
+	expected := resources.Multiply(capacity, 2)
+	assert.Assert(t, resources.Equals(partition.GetTotalPartitionResource(), expected), "partition resource not updated: %s", partition.GetTotalPartitionResource())
+	assert.Assert(t, resources.Equals(partition.root.GetMaxResource(), expected), "root max not updated: %s", partition.root.GetMaxResource())
+	assert.Assert(t, resources.Equals(partition.GetNode(nodeID2).GetOccupiedResource(), occupied), "occupied resource not updated")
+	assert.Equal(t, partition.GetNode(nodeID1).GetAttribute("zone"), "a", "attributes not updated")
+
+	// no changes: nothing updated
+	missing = partition.updateNodes([]*si.UpdateNodeInfo{
+		{NodeID: nodeID1, SchedulableResource: capacity.ToProto(), Action: si.UpdateNodeInfo_UPDATE},
+	})
+	assert.Equal(t, len(missing), 0, "no missing nodes expected")
+	assert.Assert(t, resources.Equals(partition.GetTotalPartitionResource(), expected), "partition resource should not change")
+}
+
 func TestAddTGApplication(t *testing.T) {
 	limit := map[string]string{"first": "1"}
 	partition, err := newLimitedPartition(limit)
@@ -1280,6 +1884,27 @@ func TestAddTGApplication(t *testing.T) {
 	assert.Equal(t, partition.getApplication(appID1), app, "partition failed to add app incorrect app returned")
 }
 
+func TestAddTGApplicationGateDisabled(t *testing.T) {
+	assert.NilError(t, features.SetGates("GangScheduling=false"), "failed to disable gang scheduling")
+	defer func() {
+		assert.NilError(t, features.SetGates(""), "failed to reset feature gates")
+	}()
+	partition, err := newLimitedPartition(map[string]string{"first": "100"})
+	assert.NilError(t, err, "partition create failed")
+	var tgRes *resources.Resource
+	tgRes, err = resources.NewResourceFromConf(map[string]string{"first": "10"})
+	assert.NilError(t, err, "failed to create resource")
+	app := newApplicationTG(appID1, "default", "root.limited", tgRes)
+	err = partition.AddApplication(app)
+	assert.ErrorContains(t, err, "gang scheduling is disabled")
+	assert.Assert(t, partition.getApplication(appID1) == nil, "app-1 should not have been added to the partition")
+
+	// an application without a task group is not affected
+	app = newApplication(appID2, "default", "root.limited")
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "app-2 should have been added to the partition")
+}
+
 func TestAddTGAppDynamic(t *testing.T) {
 	partition, err := newPlacementPartition()
 	assert.NilError(t, err, "partition create failed")
@@ -1317,6 +1942,81 @@ func TestAddTGAppDynamic(t *testing.T) {
 	}
 }
 
+func TestAddAppPlacementResult(t *testing.T) {
+	events.CreateAndSetEventCache()
+	cache := events.GetEventCache()
+	cache.StartService()
+
+	partition, err := newPlacementPartition()
+	assert.NilError(t, err, "partition create failed")
+	app := newApplicationTGTags(appID1, "default", "unknown", nil, map[string]string{"taskqueue": "placed"})
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "app-1 should have been added to the partition")
+	assert.Equal(t, app.GetQueueName(), "root.placed", "app-1 not placed in expected queue")
+	assert.Equal(t, app.GetPlacementRule(), "tag", "app-1 placed by unexpected rule")
+	assert.Equal(t, app.GetRequestedQueue(), "unknown", "requested queue of app-1 not kept")
+	assert.Assert(t, app.IsQueueCreated(), "queue should have been created for app-1")
+
+	// second app in the same queue does not create the queue
+	app = newApplicationTGTags(appID2, "default", "unknown", nil, map[string]string{"taskqueue": "placed"})
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "app-2 should have been added to the partition")
+	assert.Equal(t, app.GetQueueName(), "root.placed", "app-2 not placed in expected queue")
+	assert.Assert(t, !app.IsQueueCreated(), "queue should not have been created for app-2")
+
+	// both placements generate an event
+	placed := 0
+	for i := 0; i < 100 && placed < 2; i++ {
+		time.Sleep(10 * time.Millisecond)
+		for _, event := range cache.Store.CollectEvents() {
+			if event.Reason == "ApplicationPlaced" {
+				placed++
+			}
+		}
+	}
+	assert.Equal(t, placed, 2, "expected a placement event for each application")
+}
+
+func TestDynamicQueueLimits(t *testing.T) {
+	conf := configs.PartitionConfig{
+		Name: "dynamic",
+		Queues: []configs.QueueConfig{
+			{
+				Name:      "root",
+				Parent:    true,
+				SubmitACL: "*",
+			},
+		},
+		PlacementRules: []configs.PlacementRule{{Name: "provided", Create: true}},
+		DynamicQueues:  configs.DynamicQueuesConfig{MaxDepth: 2, MaxQueues: 5},
+	}
+	partition, err := newPartitionContext(conf, rmID, nil)
+	assert.NilError(t, err, "partition create failed")
+	assertLimited := func(expected int) {
+		count, getErr := metrics.GetSchedulerMetrics().GetDynamicQueuesLimited(partition.Name)
+		assert.NilError(t, getErr, "failed to read dynamic queue metric")
+		assert.Equal(t, count, expected, "unexpected number of limited queue creations")
+	}
+
+	err = partition.AddApplication(newApplication(appID1, "dynamic", "root.a.b"))
+	assert.NilError(t, err, "app-1 should have been added to the partition")
+	// too deep
+	err = partition.AddApplication(newApplication(appID2, "dynamic", "root.p.q.r"))
+	assert.ErrorContains(t, err, "exceeds the maximum depth")
+	assert.Assert(t, partition.GetQueue("root.p") == nil, "queue should not have been created")
+	assertLimited(1)
+	// fits exactly in the maximum number of queues
+	err = partition.AddApplication(newApplication(appID2, "dynamic", "root.x.y"))
+	assert.NilError(t, err, "app-2 should have been added to the partition")
+	err = partition.AddApplication(newApplication(appID3, "dynamic", "root.z"))
+	assert.ErrorContains(t, err, "maximum of 5 queues")
+	assertLimited(2)
+
+	depth, size := getQueueTreeStats(partition.root)
+	assert.Equal(t, depth, 2, "unexpected queue tree depth")
+	assert.Equal(t, size, 5, "unexpected number of queues")
+}
+
 func TestPlaceholderAndRealAllocationResMismatch(t *testing.T) {
 	events.CreateAndSetEventCache()
 	cache := events.GetEventCache()
@@ -1653,6 +2353,100 @@ func TestAddAllocationAsk(t *testing.T) {
 	}
 }
 
+func TestAddAllocationAskAllowedResourceTypes(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	queue := partition.GetQueue(defQueue)
+	assert.Assert(t, queue != nil, "default queue not found")
+	err = queue.SetQueueConfig(configs.QueueConfig{Name: "default", AllowedResourceTypes: []string{"first"}})
+	assert.NilError(t, err, "failed to restrict the resource types")
+	app := newApplication(appID1, "default", defQueue)
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "app-1 should have been added to the partition")
+
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1, "gpu": 1})
+	ask := &si.AllocationAsk{
+		AllocationKey:  "ask-key-1",
+		ApplicationID:  appID1,
+		ResourceAsk:    res.ToProto(),
+		MaxAllocations: 1,
+	}
+	err = partition.addAllocationAsk(ask)
+	assert.ErrorContains(t, err, "resource type gpu is not allowed in queue root.default")
+	assert.Assert(t, app.GetAllocationAsk("ask-key-1") == nil, "rejected ask should not have been added")
+
+	ask.ResourceAsk = resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1}).ToProto()
+	err = partition.addAllocationAsk(ask)
+	assert.NilError(t, err, "ask with allowed types should have been added")
+}
+
+func TestUpdateAllocationAsk(t *testing.T) {
+	partition := createQueuesNodes(t)
+	if partition == nil {
+		t.Fatal("partition create failed")
+	}
+	// replace any predicate plugin left behind by other tests: the reservation must not be blocked
+	plugins.RegisterSchedulerPlugin(newFakePredicatePlugin(false, nil))
+	// override the reservation delay, and cleanup when done
+	objects.SetReservationDelay(10 * time.Nanosecond)
+	defer objects.SetReservationDelay(2 * time.Second)
+
+	res, err := resources.NewResourceFromConf(map[string]string{"first": "4"})
+	assert.NilError(t, err, "resource creation failed")
+	app := newApplication(appID1, "default", "root.parent.sub-leaf")
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app app-1 to partition")
+	err = app.AddAllocationAsk(newAllocationAskRepeat("alloc-1", appID1, res, 4))
+	assert.NilError(t, err, "failed to add ask alloc-1 to app")
+	for i := 1; i <= 4; i++ {
+		alloc := partition.tryAllocate()
+		if alloc == nil || alloc.Result != objects.Allocated {
+			t.Fatalf("expected allocated allocation to be returned (step %d) %s", i, alloc)
+		}
+	}
+
+	// the ask does not fit in the remaining space and gets reserved
+	siAsk := &si.AllocationAsk{
+		AllocationKey:  "alloc-2",
+		ApplicationID:  appID1,
+		ResourceAsk:    res.ToProto(),
+		MaxAllocations: 1,
+	}
+	err = partition.addAllocationAsk(siAsk)
+	assert.NilError(t, err, "failed to add ask alloc-2 to app")
+	if alloc := partition.tryAllocate(); alloc != nil {
+		t.Fatalf("expected reservation to be created not allocation to be returned %s", alloc)
+	}
+	assert.Equal(t, len(partition.reservedApps), 1, "partition should have reserved app")
+	ask := app.GetAllocationAsk("alloc-2")
+
+	// resize the ask beyond the node size: the reservation is removed
+	var large *resources.Resource
+	large, err = resources.NewResourceFromConf(map[string]string{"first": "12"})
+	assert.NilError(t, err, "resource creation failed")
+	siAsk.ResourceAsk = large.ToProto()
+	err = partition.addAllocationAsk(siAsk)
+	assert.NilError(t, err, "failed to update ask alloc-2")
+	assert.Equal(t, len(partition.reservedApps), 0, "partition should not have reserved app")
+	assert.Equal(t, len(app.GetReservations()), 0, "application should not have reservations")
+	assert.Equal(t, app.GetAllocationAsk("alloc-2"), ask, "ask should have been updated in place")
+	assert.Assert(t, resources.Equals(app.GetPendingResource(), large), "pending resource not updated: %v", app.GetPendingResource())
+
+	// resize the ask down to fit in the remaining space
+	var small *resources.Resource
+	small, err = resources.NewResourceFromConf(map[string]string{"first": "2"})
+	assert.NilError(t, err, "resource creation failed")
+	siAsk.ResourceAsk = small.ToProto()
+	err = partition.addAllocationAsk(siAsk)
+	assert.NilError(t, err, "failed to update ask alloc-2")
+	assert.Assert(t, resources.Equals(app.GetPendingResource(), small), "pending resource not updated: %v", app.GetPendingResource())
+	alloc := partition.tryAllocate()
+	if alloc == nil || alloc.Result != objects.Allocated {
+		t.Fatalf("expected resized ask to be allocated %s", alloc)
+	}
+	assert.Assert(t, resources.Equals(alloc.AllocatedResource, small), "allocation should use the updated resource")
+}
+
 func TestRemoveAllocationAsk(t *testing.T) {
 	partition, err := newBasePartition()
 	assert.NilError(t, err, "partition create failed")
@@ -1703,3 +2497,519 @@ func TestRemoveAllocationAsk(t *testing.T) {
 	partition.removeAllocationAsk(release)
 	assert.Assert(t, resources.IsZero(app.GetPendingResource()), "app should not have pending asks")
 }
+
+func TestUpdateQueueLimits(t *testing.T) {
+	partition, err := newConfiguredPartition()
+	assert.NilError(t, err, "partition create failed")
+	res := func(value resources.Quantity) *resources.Resource {
+		return resources.NewResourceFromMap(map[string]resources.Quantity{"first": value})
+	}
+	parent := partition.GetQueue("root.parent")
+	subLeaf := partition.GetQueue("root.parent.sub-leaf")
+
+	// all failures leave the limits untouched
+	failures := []struct {
+		name    string
+		updates []QueueLimitUpdate
+		errMsg  string
+	}{
+		{"unknown queue", []QueueLimitUpdate{{QueuePath: "root.unknown", MaxResource: res(10)}}, "not found"},
+		{"root queue", []QueueLimitUpdate{{QueuePath: "root", MaxResource: res(10)}}, "root queue"},
+		{"duplicate", []QueueLimitUpdate{{QueuePath: "root.leaf", MaxResource: res(10)}, {QueuePath: "root.LEAF", MaxResource: res(20)}}, "duplicate"},
+		{"guaranteed over max", []QueueLimitUpdate{{QueuePath: "root.leaf", MaxResource: res(10), GuaranteedResource: res(20)}}, "is larger than maximum resource"},
+		{"child over parent", []QueueLimitUpdate{{QueuePath: "root.parent.sub-leaf", MaxResource: res(20)}, {QueuePath: "root.parent", MaxResource: res(10)}}, "max resource of parent"},
+		{"children guaranteed over parent", []QueueLimitUpdate{{QueuePath: "root.parent", GuaranteedResource: res(5)}, {QueuePath: "root.parent.sub-leaf", GuaranteedResource: res(10)}}, "sum of guaranteed resources"},
+	}
+	for _, tt := range failures {
+		t.Run(tt.name, func(t *testing.T) {
+			err = partition.UpdateQueueLimits(tt.updates)
+			assert.ErrorContains(t, err, tt.errMsg)
+			max, guaranteed := partition.GetQueue("root.leaf").GetLimits()
+			assert.Assert(t, max == nil && guaranteed == nil, "failed update should not change limits")
+			max, guaranteed = subLeaf.GetLimits()
+			assert.Assert(t, max == nil && guaranteed == nil, "failed update should not change limits")
+		})
+	}
+
+	// valid update of a parent and a child
+	err = partition.UpdateQueueLimits([]QueueLimitUpdate{
+		{QueuePath: "root.parent", MaxResource: res(20), GuaranteedResource: res(10)},
+		{QueuePath: "root.parent.sub-leaf", MaxResource: res(15), GuaranteedResource: res(5)},
+	})
+	assert.NilError(t, err, "valid limit update failed")
+	max, guaranteed := parent.GetLimits()
+	assert.Assert(t, resources.Equals(max, res(20)) && resources.Equals(guaranteed, res(10)), "parent limits not updated")
+	max, guaranteed = subLeaf.GetLimits()
+	assert.Assert(t, resources.Equals(max, res(15)) && resources.Equals(guaranteed, res(5)), "child limits not updated")
+
+	// child guaranteed must fit in the existing parent guaranteed
+	err = partition.UpdateQueueLimits([]QueueLimitUpdate{{QueuePath: "root.parent.sub-leaf", GuaranteedResource: res(11)}})
+	assert.ErrorContains(t, err, "sum of guaranteed resources")
+
+	// max cannot be lowered below the current usage
+	err = subLeaf.IncAllocatedResource(res(8), false)
+	assert.NilError(t, err, "failed to set allocated resource")
+	err = partition.UpdateQueueLimits([]QueueLimitUpdate{{QueuePath: "root.parent.sub-leaf", MaxResource: res(6), GuaranteedResource: res(5)}})
+	assert.ErrorContains(t, err, "smaller than the allocated resource")
+
+	// an empty resource removes the limit, a nil resource leaves it unchanged
+	err = partition.UpdateQueueLimits([]QueueLimitUpdate{{QueuePath: "root.parent.sub-leaf", MaxResource: resources.NewResource()}})
+	assert.NilError(t, err, "removing the max limit failed")
+	max, guaranteed = subLeaf.GetLimits()
+	assert.Assert(t, max == nil, "max limit should have been removed")
+	assert.Assert(t, resources.Equals(guaranteed, res(5)), "guaranteed limit should not have changed")
+}
+
+func TestCompletedAppRetention(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	now := time.Now()
+	addCompleted := func(appID string, completed time.Time, retention objects.AppRetention) {
+		partition.completedApplications[appID] = newApplication(appID, "default", defQueue)
+		partition.completedInfo[appID] = &completedAppInfo{
+			queuePath: defQueue,
+			completed: completed,
+			retention: retention,
+		}
+	}
+	// no retention set: nothing is removed
+	addCompleted("app-0", now.Add(-time.Hour), objects.AppRetention{})
+	partition.cleanupCompletedApps()
+	assert.Equal(t, len(partition.completedApplications), 1, "app without retention should not be removed")
+
+	// count limit: the oldest apps are removed
+	partition.completedApplications = make(map[string]*objects.Application)
+	partition.completedInfo = make(map[string]*completedAppInfo)
+	retention := objects.AppRetention{MaxCount: 2}
+	addCompleted("app-1", now.Add(-3*time.Minute), retention)
+	addCompleted("app-2", now.Add(-2*time.Minute), retention)
+	addCompleted("app-3", now.Add(-1*time.Minute), retention)
+	partition.cleanupCompletedApps()
+	assert.Equal(t, len(partition.completedApplications), 2, "unexpected number of completed apps after count cleanup")
+	assert.Assert(t, partition.completedApplications["app-3"] != nil, "newest app should have been kept")
+	assert.Equal(t, len(partition.completedInfo), 2, "completed info not cleaned up")
+
+	// age limit: the expired app is removed
+	partition.completedApplications = make(map[string]*objects.Application)
+	partition.completedInfo = make(map[string]*completedAppInfo)
+	retention = objects.AppRetention{MaxAge: 10 * time.Minute}
+	addCompleted("app-4", now.Add(-time.Hour), retention)
+	addCompleted("app-5", now.Add(-time.Minute), retention)
+	partition.cleanupCompletedApps()
+	assert.Equal(t, len(partition.completedApplications), 1, "unexpected number of completed apps after age cleanup")
+	assert.Assert(t, partition.completedApplications["app-5"] != nil, "app within the retention age should have been kept")
+}
+
+func TestTryAllocatePriorityQuota(t *testing.T) {
+	partition := createQueuesNodes(t)
+	if partition == nil {
+		t.Fatal("partition create failed")
+	}
+	// quota on the parent: asks with a priority above 1 can use at most 2 in total
+	parent := partition.GetQueue("root.parent")
+	err := parent.SetQueueConfig(configs.QueueConfig{
+		Name:          "parent",
+		Parent:        true,
+		PriorityQuota: configs.PriorityQuota{Threshold: 1, Max: map[string]string{"first": "2"}},
+	})
+	assert.NilError(t, err, "failed to set priority quota on the parent queue")
+
+	app := newApplication(appID1, "default", "root.parent.sub-leaf")
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app-1 to partition")
+	var res *resources.Resource
+	res, err = resources.NewResourceFromConf(map[string]string{"first": "1"})
+	assert.NilError(t, err, "failed to create resource")
+	err = app.AddAllocationAsk(newAllocationAskPriority("alloc-high", appID1, res, 3, 2))
+	assert.NilError(t, err, "failed to add high priority ask to app-1")
+	err = app.AddAllocationAsk(newAllocationAskPriority("alloc-low", appID1, res, 1, 1))
+	assert.NilError(t, err, "failed to add low priority ask to app-1")
+
+	// the high priority ask is allocated until the quota is reached
+	for i := 0; i < 2; i++ {
+		alloc := partition.tryAllocate()
+		if alloc == nil {
+			t.Fatal("allocation did not return any allocation")
+		}
+		assert.Equal(t, alloc.AllocationKey, "alloc-high", "expected the high priority ask to be allocated")
+	}
+	assert.Assert(t, resources.Equals(parent.GetPriorityQuotaUsage(), resources.Multiply(res, 2)), "unexpected priority quota usage")
+	// the low priority ask is not limited by the quota
+	alloc := partition.tryAllocate()
+	if alloc == nil {
+		t.Fatal("allocation did not return any allocation")
+	}
+	assert.Equal(t, alloc.AllocationKey, "alloc-low", "expected the low priority ask to be allocated")
+	alloc = partition.tryAllocate()
+	assert.Assert(t, alloc == nil, "high priority ask should have been limited by the quota")
+	assert.Assert(t, resources.Equals(app.GetPendingResource(), res), "high priority ask should still be pending")
+}
+
+func TestMoveQueue(t *testing.T) {
+	partition := createQueuesNodes(t)
+	if partition == nil {
+		t.Fatal("partition create failed")
+	}
+	app := newApplication(appID1, "default", "root.parent.sub-leaf")
+	err := partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app-1 to partition")
+	var res *resources.Resource
+	res, err = resources.NewResourceFromConf(map[string]string{"first": "1"})
+	assert.NilError(t, err, "failed to create resource")
+	err = app.AddAllocationAsk(newAllocationAskRepeat("alloc-1", appID1, res, 2))
+	assert.NilError(t, err, "failed to add ask to app-1")
+	alloc := partition.tryAllocate()
+	if alloc == nil {
+		t.Fatal("allocation did not return any allocation")
+	}
+
+	// invalid moves
+	var tests = []struct {
+		from, to, err string
+	}{
+		{"root", "root.other", "root queue cannot be moved"},
+		{"root.unknown", "root.other", "not found"},
+		{"root.parent", "root.parent.sub-leaf.other", "below itself"},
+		{"root.parent.sub-leaf", "root.leaf", "already exists"},
+		{"root.parent.sub-leaf", "root.leaf.other", "below leaf queue"},
+		{"root.parent.sub-leaf", "root.unknown.other", "parent queue root.unknown not found"},
+		{"root.parent.sub-leaf", "root.in valid", "invalid queue name"},
+	}
+	for _, tt := range tests {
+		assert.ErrorContains(t, partition.MoveQueue(tt.from, tt.to), tt.err, "unexpected error moving %s to %s", tt.from, tt.to)
+	}
+
+	// move the leaf to the root: usage is moved from the old parent
+	parent := partition.GetQueue("root.parent")
+	err = partition.MoveQueue("root.parent.sub-leaf", "root.moved")
+	assert.NilError(t, err, "move of the leaf queue failed")
+	assert.Assert(t, partition.GetQueue("root.parent.sub-leaf") == nil, "old queue path should not exist")
+	moved := partition.GetQueue("root.moved")
+	assert.Assert(t, moved != nil, "moved queue should exist")
+	assert.Equal(t, moved.Name, "moved", "queue should have been renamed")
+	assert.Assert(t, resources.IsZero(parent.GetAllocatedResource()), "allocated resource should be removed from the old parent")
+	assert.Assert(t, resources.IsZero(parent.GetPendingResource()), "pending resource should be removed from the old parent")
+	assert.Assert(t, resources.Equals(moved.GetAllocatedResource(), res), "allocated resource should stay on the moved queue")
+	assert.Assert(t, resources.Equals(partition.root.GetAllocatedResource(), res), "root allocated resource should not change")
+	assert.Equal(t, app.GetQueueName(), "root.moved", "application queue name not updated")
+	assert.Equal(t, app.QueueName, "root.moved", "application queue name not updated")
+	assert.Equal(t, alloc.QueueName, "root.moved", "allocation queue name not updated")
+
+	// the usage must fit in the new parent
+	parent.SetLimits(resources.NewResourceFromMap(map[string]resources.Quantity{"first": 0, "second": 1}), nil)
+	assert.ErrorContains(t, partition.MoveQueue("root.moved", "root.parent.sub-leaf"), "does not fit in the maximum resource")
+	parent.SetLimits(nil, nil)
+	err = partition.MoveQueue("root.moved", "root.parent.sub-leaf")
+	assert.NilError(t, err, "move back of the leaf queue failed")
+	assert.Assert(t, resources.Equals(parent.GetAllocatedResource(), res), "allocated resource should be added to the new parent")
+	assert.Equal(t, app.GetQueueName(), "root.parent.sub-leaf", "application queue name not updated")
+}
+
+func TestReconcile(t *testing.T) {
+	partition := createQueuesNodes(t)
+	if partition == nil {
+		t.Fatal("partition create failed")
+	}
+	app := newApplication(appID1, "default", "root.leaf")
+	err := partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app-1 to partition")
+	var res *resources.Resource
+	res, err = resources.NewResourceFromConf(map[string]string{"first": "1"})
+	assert.NilError(t, err, "failed to create resource")
+	err = app.AddAllocationAsk(newAllocationAsk("alloc-1", appID1, res))
+	assert.NilError(t, err, "failed to add ask to app-1")
+	alloc := partition.tryAllocate()
+	if alloc == nil {
+		t.Fatal("allocation did not return any allocation")
+	}
+	assert.Equal(t, len(partition.reconcile()), 0, "consistent partition should have no discrepancies")
+
+	// the node snapshot is rebuilt
+	partition.nodeSnapshot.Store([]*objects.Node{})
+	found := partition.reconcile()
+	assert.DeepEqual(t, found, map[string]int{reconcileNodeSnapshot: 1})
+	assert.Equal(t, len(partition.getNodeSnapshot()), 2, "node snapshot should have been rebuilt")
+
+	// the allocation count is only reset after two runs with the same difference
+	partition.allocations = 5
+	found = partition.reconcile()
+	assert.DeepEqual(t, found, map[string]int{reconcileAllocationCount: 1})
+	assert.Equal(t, partition.GetTotalAllocationCount(), 5, "allocation count should not be reset on the first run")
+	partition.reconcile()
+	assert.Equal(t, partition.GetTotalAllocationCount(), 1, "allocation count should have been reset")
+
+	// allocation and queue mismatches are reported only
+	queue := partition.GetQueue("root.leaf")
+	err = queue.IncAllocatedResource(res, true)
+	assert.NilError(t, err, "failed to increase queue allocated resource")
+	partition.GetNode(alloc.NodeID).RemoveAllocation(alloc.UUID)
+	found = partition.reconcile()
+	assert.Equal(t, found[reconcileQueueAllocated], 1, "queue mismatch should have been found")
+	assert.Equal(t, found[reconcileAppAllocation], 1, "application allocation mismatch should have been found")
+	assert.Assert(t, resources.Equals(queue.GetAllocatedResource(), resources.Multiply(res, 2)), "queue should not have been repaired")
+}
+
+func TestGetPartitionTotals(t *testing.T) {
+	partition := createQueuesNodes(t)
+	if partition == nil {
+		t.Fatal("partition create failed")
+	}
+	totals := partition.getPartitionTotals()
+	assert.Equal(t, totals.Partition, partition.Name, "unexpected partition name")
+	assert.Equal(t, totals.Nodes, 2, "unexpected node count")
+	assert.Assert(t, resources.Equals(totals.Capacity, partition.GetTotalPartitionResource()), "unexpected capacity")
+	assert.Equal(t, totals.AllocationCount, 0, "unexpected allocation count")
+	assert.Equal(t, totals.AskCount, 0, "unexpected ask count")
+
+	app := newApplication(appID1, "default", "root.leaf")
+	err := partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app-1 to partition")
+	var res *resources.Resource
+	res, err = resources.NewResourceFromConf(map[string]string{"first": "1"})
+	assert.NilError(t, err, "failed to create resource")
+	err = app.AddAllocationAsk(newAllocationAskRepeat("alloc-1", appID1, res, 3))
+	assert.NilError(t, err, "failed to add ask to app-1")
+	if alloc := partition.tryAllocate(); alloc == nil {
+		t.Fatal("allocation did not return any allocation")
+	}
+	totals = partition.getPartitionTotals()
+	assert.Equal(t, totals.AllocationCount, 1, "unexpected allocation count")
+	assert.Equal(t, totals.AskCount, 2, "unexpected ask count")
+}
+
+func TestAllocationProposalTime(t *testing.T) {
+	partition := createQueuesNodes(t)
+	if partition == nil {
+		t.Fatal("partition create failed")
+	}
+	app := newApplication(appID1, "default", "root.leaf")
+	err := partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app-1 to partition")
+	var res *resources.Resource
+	res, err = resources.NewResourceFromConf(map[string]string{"first": "1"})
+	assert.NilError(t, err, "failed to create resource")
+	err = app.AddAllocationAsk(newAllocationAsk("alloc-1", appID1, res))
+	assert.NilError(t, err, "failed to add ask to app-1")
+	before := time.Now()
+	alloc := partition.tryAllocate()
+	if alloc == nil {
+		t.Fatal("allocation did not return any allocation")
+	}
+	proposed := alloc.GetProposalTime()
+	assert.Assert(t, !proposed.Before(before) && !proposed.After(time.Now()), "proposal time not set on allocation")
+
+	// a recovered allocation has no proposal time
+	recovered := objects.NewAllocation("uuid-1", nodeID1, newAllocationAsk("alloc-2", appID1, res))
+	assert.Assert(t, recovered.GetProposalTime().IsZero(), "recovered allocation should not have a proposal time")
+}
+
+func TestSystemQueue(t *testing.T) {
+	conf := configs.PartitionConfig{
+		Name: "test",
+		Queues: []configs.QueueConfig{
+			{
+				Name:      "root",
+				Parent:    true,
+				SubmitACL: "nobody",
+				Queues: []configs.QueueConfig{
+					{Name: "default"},
+				},
+			},
+		},
+		PlacementRules: []configs.PlacementRule{{Name: "fixed", Value: "root.default"}},
+		SystemQueue:    configs.SystemQueueConfig{Enabled: true, Guaranteed: map[string]string{"first": "1"}, SubmitACL: "rm"},
+	}
+	partition, err := newPartitionContext(conf, "test", nil)
+	assert.NilError(t, err, "partition create failed")
+	system := partition.GetQueue("root.system")
+	if system == nil {
+		t.Fatal("system queue should have been created")
+	}
+	assert.Assert(t, system.IsSystemQueue(), "root.system should be marked as the system queue")
+	assert.Assert(t, resources.Equals(system.GetGuaranteedResource(), resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})), "unexpected guaranteed resource")
+
+	// a system application from a user without access to the system queue is rejected
+	tags := map[string]string{objects.SystemApplicationTag: "true"}
+	app := newApplicationTGTags(appID1, "default", "root.default", nil, tags)
+	err = partition.AddApplication(app)
+	assert.Assert(t, err != nil, "system application without system queue access should have been rejected")
+
+	// a system application bypasses placement but not the submit ACL of the system queue
+	siApp := &si.AddApplicationRequest{ApplicationID: appID1, QueueName: "root.default", PartitionName: "default", Tags: tags}
+	app = objects.NewApplication(siApp, security.UserGroup{User: "rm"}, nil, rmID)
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "system application should have been added")
+	assert.Equal(t, app.GetQueueName(), "root.system", "system application not placed in the system queue")
+
+	// a normal application is rejected by the ACL and cannot use the system queue
+	app = newApplication(appID2, "default", "root.system")
+	err = partition.AddApplication(app)
+	assert.Assert(t, err != nil, "normal application should have been rejected")
+
+	// disabling the system queue marks it for removal
+	conf.SystemQueue.Enabled = false
+	err = partition.updatePartitionDetails(conf)
+	assert.NilError(t, err, "partition update failed")
+	assert.Assert(t, system.IsDraining(), "system queue should have been marked for removal")
+	assert.Assert(t, !system.IsSystemQueue(), "removed queue should not be the system queue")
+}
+
+func TestUnresolvedUserPolicy(t *testing.T) {
+	conf := configs.PartitionConfig{
+		Name: "test",
+		Queues: []configs.QueueConfig{
+			{
+				Name:      "root",
+				Parent:    true,
+				SubmitACL: "nobody",
+				Queues: []configs.QueueConfig{
+					{Name: "default"},
+					{Name: "quarantine"},
+				},
+			},
+		},
+		PlacementRules: []configs.PlacementRule{{Name: "fixed", Value: "root.default"}},
+	}
+	partition, err := newPartitionContext(conf, "test", nil)
+	assert.NilError(t, err, "partition create failed")
+	_, resolveErr := partition.convertUGI(&si.UserGroupInformation{})
+	assert.Assert(t, resolveErr != nil, "empty user should not resolve")
+
+	// reject is the default
+	_, queue, err := partition.applyUnresolvedUserPolicy(resolveErr)
+	assert.Equal(t, err, resolveErr, "default policy should reject")
+	assert.Equal(t, queue, "", "rejected application should not have a queue")
+
+	// anonymous uses the configured identity and the normal placement
+	conf.UnresolvedUser = configs.UnresolvedUserConfig{Policy: configs.UnresolvedUserAnonymous}
+	err = partition.updatePartitionDetails(conf)
+	assert.NilError(t, err, "partition update failed")
+	var ugi security.UserGroup
+	ugi, queue, err = partition.applyUnresolvedUserPolicy(resolveErr)
+	assert.NilError(t, err, "anonymous policy should not reject")
+	assert.Equal(t, ugi.User, configs.DefaultAnonymousUser, "unexpected anonymous user")
+	assert.Equal(t, queue, "", "anonymous application should use placement")
+	app := objects.NewApplication(&si.AddApplicationRequest{ApplicationID: appID1, QueueName: "root.quarantine"}, ugi, nil, rmID)
+	err = partition.addApplication(app, queue)
+	assert.NilError(t, err, "anonymous application should have been added")
+	assert.Equal(t, app.GetQueueName(), "root.default", "anonymous application should have been placed by the rules")
+
+	// quarantine bypasses placement and the submit ACL
+	conf.UnresolvedUser = configs.UnresolvedUserConfig{Policy: configs.UnresolvedUserQuarantine, Queue: "root.quarantine", User: "guest"}
+	err = partition.updatePartitionDetails(conf)
+	assert.NilError(t, err, "partition update failed")
+	ugi, queue, err = partition.applyUnresolvedUserPolicy(resolveErr)
+	assert.NilError(t, err, "quarantine policy should not reject")
+	assert.Equal(t, ugi.User, "guest", "unexpected quarantine user")
+	app = objects.NewApplication(&si.AddApplicationRequest{ApplicationID: appID2, QueueName: "root.default"}, ugi, nil, rmID)
+	err = partition.addApplication(app, queue)
+	assert.NilError(t, err, "quarantined application should have been added")
+	assert.Equal(t, app.GetQueueName(), "root.quarantine", "application should have been quarantined")
+}
+
+func TestTryStealReservation(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	// override the reservation delay, and cleanup when done
+	objects.SetReservationDelay(10 * time.Nanosecond)
+	defer objects.SetReservationDelay(2 * time.Second)
+	defer objects.SetReservationStealDelta(0)
+
+	nodeRes := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 10})
+	occupied := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 5})
+	node := newNodeWithResources(nodeID1, nodeRes, occupied)
+	err = partition.AddNode(node, nil)
+	assert.NilError(t, err, "add node to partition should not have failed")
+	// a node that cannot hold the ask is ignored
+	small := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})
+	err = partition.AddNode(newNodeMaxResource(nodeID2, small), nil)
+	assert.NilError(t, err, "add small node to partition should not have failed")
+
+	askRes := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 8})
+	lowApp := newApplication(appID1, "default", defQueue)
+	err = partition.AddApplication(lowApp)
+	assert.NilError(t, err, "add application to partition should not have failed")
+	lowAsk := newAllocationAskPriority("alloc-low", appID1, askRes, 1, 1)
+	err = lowApp.AddAllocationAsk(lowAsk)
+	assert.NilError(t, err, "ask should have been added to app")
+	partition.reserve(lowApp, node, lowAsk)
+	assert.Assert(t, node.IsReserved(), "node should have been reserved")
+
+	highApp := newApplication(appID2, "default", defQueue)
+	err = partition.AddApplication(highApp)
+	assert.NilError(t, err, "add application to partition should not have failed")
+	highAsk := newAllocationAskPriority("alloc-high", appID2, askRes, 1, 10)
+	err = highApp.AddAllocationAsk(highAsk)
+	assert.NilError(t, err, "ask should have been added to app")
+	time.Sleep(time.Millisecond)
+
+	assert.Assert(t, !partition.tryStealReservation(), "stealing is disabled by default")
+	objects.SetReservationStealDelta(20)
+	assert.Assert(t, !partition.tryStealReservation(), "priority difference is below the steal delta")
+
+	objects.SetReservationStealDelta(5)
+	assert.Assert(t, partition.tryStealReservation(), "reservation should have been stolen")
+	assert.Assert(t, highApp.IsReservedOnNode(nodeID1), "node should be reserved for the high priority ask")
+	assert.Assert(t, !lowApp.IsReservedOnNode(nodeID1), "low priority reservation should have been removed")
+	assert.DeepEqual(t, partition.getReservations(), map[string]int{appID2: 1})
+	assert.Assert(t, resources.Equals(lowApp.GetPendingResource(), askRes), "stolen ask should still be pending")
+
+	// a reservation of the same or higher priority is never stolen
+	assert.Assert(t, !partition.tryStealReservation(), "high priority reservation should not be stolen")
+}
+
+func TestReclaimIdleApplications(t *testing.T) {
+	conf := configs.PartitionConfig{
+		Name: "test",
+		Queues: []configs.QueueConfig{
+			{
+				Name:      "root",
+				Parent:    true,
+				SubmitACL: "*",
+				Queues: []configs.QueueConfig{
+					{Name: "reclaim"},
+					{Name: "keep", Properties: map[string]string{configs.ApplicationIdleReclaim: "false"}},
+				},
+			},
+		},
+		IdleApplications: configs.IdleApplicationsConfig{Timeout: time.Nanosecond, Reclaim: 0.5},
+	}
+	partition, err := newPartitionContext(conf, rmID, nil)
+	assert.NilError(t, err, "partition create failed")
+	assert.Equal(t, len(partition.reclaimIdleApplications()), 0, "nothing should be reclaimed without the usage plugin")
+	plugins.RegisterSchedulerPlugin(&fakeAppUsagePlugin{idle: map[string]bool{appID1: true, appID2: true}})
+	defer plugins.UnregisterApplicationUsagePlugin()
+
+	// app-1 and app-2 are flagged idle by the RM, app-3 is not
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})
+	allocs := make([]*objects.Allocation, 0)
+	for appID, queueName := range map[string]string{appID1: "root.reclaim", appID2: "root.keep", appID3: "root.reclaim"} {
+		app := newApplication(appID, "default", queueName)
+		err = partition.AddApplication(app)
+		assert.NilError(t, err, "failed to add application %s", appID)
+		for i := 0; i < 2; i++ {
+			ask := newAllocationAsk(appID+"-alloc-"+strconv.Itoa(i), appID, res)
+			allocs = append(allocs, objects.NewAllocation(appID+"-uuid-"+strconv.Itoa(i), nodeID1, ask))
+		}
+	}
+	err = partition.AddNode(newNodeMaxResource(nodeID1, resources.NewResourceFromMap(map[string]resources.Quantity{"first": 10})), allocs)
+	assert.NilError(t, err, "add node to partition should not have failed")
+	time.Sleep(time.Millisecond)
+
+	// half of the allocations of app-1 are released, app-2 is in a queue that opted out
+	released := partition.reclaimIdleApplications()
+	assert.Equal(t, len(released), 1, "expected one released allocation")
+	assert.Equal(t, released[0].ApplicationID, appID1, "allocation released from the wrong application")
+	assert.Equal(t, len(partition.getApplication(appID1).GetAllAllocations()), 1, "allocation not removed from app-1")
+	assert.Equal(t, len(partition.GetNode(nodeID1).GetAllAllocations()), 5, "allocation not removed from the node")
+	assert.Assert(t, partition.getApplication(appID2).IsIdle(), "app-2 should have been flagged idle")
+	assert.Equal(t, len(partition.getApplication(appID2).GetAllAllocations()), 2, "app-2 should keep its allocations")
+	assert.Assert(t, !partition.getApplication(appID3).IsIdle(), "app-3 should not have been flagged idle")
+
+	// app-2 is only reported once per idle period
+	time.Sleep(time.Millisecond)
+	released = partition.reclaimIdleApplications()
+	assert.Equal(t, len(released), 1, "expected one released allocation")
+	assert.Equal(t, released[0].ApplicationID, appID1, "allocation released from the wrong application")
+	assert.Equal(t, len(partition.getApplication(appID2).GetAllAllocations()), 2, "app-2 should keep its allocations")
+}
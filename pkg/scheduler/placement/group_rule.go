@@ -0,0 +1,169 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package placement
+
+import (
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/configs"
+	"github.com/apache/incubator-yunikorn-core/pkg/common/security"
+	"github.com/apache/incubator-yunikorn-core/pkg/log"
+	"github.com/apache/incubator-yunikorn-core/pkg/plugins"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/objects"
+)
+
+// A rule to place an application based on the groups of the submitting user.
+// The groups are resolved using the configured resolver:
+// - application: the groups of the user as provided on submission (default)
+// - plugin: the groups returned by the group resolver plugin, results are cached in the user group cache
+// - static: the groups defined for the user in the rule configuration
+// The application is placed in the queue of the first group that exists. If none exists and the rule can create the
+// queue, the queue for the primary group (first group in the list) is used.
+type groupRule struct {
+	basicRule
+	resolver string
+	static   map[string][]string
+}
+
+func (gr *groupRule) getName() string {
+	return "group"
+}
+
+func (gr *groupRule) initialise(conf configs.PlacementRule) error {
+	gr.resolver = normalise(conf.GroupResolver.Type)
+	switch gr.resolver {
+	case "":
+		gr.resolver = configs.GroupResolverApplication
+	case configs.GroupResolverApplication, configs.GroupResolverPlugin:
+	case configs.GroupResolverStatic:
+		gr.static = make(map[string][]string, len(conf.GroupResolver.Users))
+		for userName, groups := range conf.GroupResolver.Users {
+			gr.static[userName] = append([]string{}, groups...)
+		}
+	default:
+		return fmt.Errorf("unknown group resolver %s for group rule", conf.GroupResolver.Type)
+	}
+	gr.create = conf.Create
+	gr.filter = newFilter(conf.Filter)
+	gr.setSanitize(conf)
+	var err = error(nil)
+	if conf.Parent != nil {
+		gr.parent, err = newRule(*conf.Parent)
+	}
+	return err
+}
+
+// Return the groups of the user using the configured resolver.
+func (gr *groupRule) resolveGroups(user security.UserGroup) []string {
+	switch gr.resolver {
+	case configs.GroupResolverPlugin:
+		plugin := plugins.GetGroupResolverPlugin()
+		if plugin == nil {
+			log.Logger().Warn("Group rule uses the plugin resolver but no group resolver plugin is registered")
+			return nil
+		}
+		groups, err := security.GetUserGroupCache("").ResolveGroups(user.User, plugin.ResolveGroups)
+		if err != nil {
+			log.Logger().Debug("Group rule failed to resolve groups",
+				zap.String("user", user.User),
+				zap.Error(err))
+			return nil
+		}
+		return groups
+	case configs.GroupResolverStatic:
+		return gr.static[user.User]
+	default:
+		return user.Groups
+	}
+}
+
+func (gr *groupRule) placeApplication(app *objects.Application, queueFn func(string) *objects.Queue) (string, error) {
+	// before anything run the filter
+	if !gr.filter.allowUser(app.GetUser()) {
+		log.Logger().Debug("Group rule filtered",
+			zap.String("application", app.ApplicationID),
+			zap.Any("user", app.GetUser()))
+		return "", nil
+	}
+	// no groups nothing to place in
+	groups := gr.resolveGroups(app.GetUser())
+	if len(groups) == 0 {
+		return "", nil
+	}
+	var parentName string
+	var err error
+	// run the parent rule if set
+	if gr.parent != nil {
+		parentName, err = gr.parent.placeApplication(app, queueFn)
+		// failed parent rule, fail this rule
+		if err != nil {
+			return "", err
+		}
+		// rule did not match: this could be filter or create flag related
+		if parentName == "" {
+			return "", nil
+		}
+		// check if this is a parent queue and qualify it
+		if !strings.HasPrefix(parentName, configs.RootQueue+configs.DOT) {
+			parentName = configs.RootQueue + configs.DOT + parentName
+		}
+		// if the parent queue exists it cannot be a leaf
+		parentQueue := queueFn(parentName)
+		if parentQueue != nil && parentQueue.IsLeafQueue() {
+			return "", fmt.Errorf("parent rule returned a leaf queue: %s", parentName)
+		}
+	}
+	// the parent is set from the rule otherwise set it to the root
+	if parentName == "" {
+		parentName = configs.RootQueue
+	}
+	// use the first group that has a queue, remember the primary group in case we can create
+	var primaryName string
+	for i, group := range groups {
+		var childName string
+		childName, err = gr.cleanQueueName(group)
+		if err != nil {
+			log.Logger().Debug("Group rule generated invalid queue name",
+				zap.String("application", app.ApplicationID),
+				zap.Error(err))
+			continue
+		}
+		queueName := parentName + configs.DOT + childName
+		if i == 0 {
+			primaryName = queueName
+		}
+		if queueFn(queueName) != nil {
+			log.Logger().Info("Group rule application placed",
+				zap.String("application", app.ApplicationID),
+				zap.String("queue", queueName))
+			return queueName, nil
+		}
+	}
+	// if we cannot create the queue it must exist, rule does not match otherwise
+	if !gr.create || primaryName == "" {
+		return "", nil
+	}
+	log.Logger().Info("Group rule application placed",
+		zap.String("application", app.ApplicationID),
+		zap.String("queue", primaryName))
+	return primaryName, nil
+}
@@ -0,0 +1,139 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package placement
+
+import (
+	"fmt"
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/configs"
+	"github.com/apache/incubator-yunikorn-core/pkg/common/security"
+	"github.com/apache/incubator-yunikorn-core/pkg/plugins"
+)
+
+type fakeGroupResolver struct {
+	groups map[string][]string
+}
+
+func (f *fakeGroupResolver) ResolveGroups(userName string) ([]string, error) {
+	if groups, ok := f.groups[userName]; ok {
+		return groups, nil
+	}
+	return nil, fmt.Errorf("unknown user %s", userName)
+}
+
+func TestGroupRule(t *testing.T) {
+	conf := configs.PlacementRule{
+		Name:          "group",
+		GroupResolver: configs.GroupResolver{Type: "unknown"},
+	}
+	_, err := newRule(conf)
+	assert.ErrorContains(t, err, "unknown group resolver")
+	conf.GroupResolver.Type = "Static"
+	var gr rule
+	gr, err = newRule(conf)
+	assert.NilError(t, err, "group rule create failed")
+	assert.Equal(t, gr.getName(), "group")
+	conf.GroupResolver.Type = ""
+	gr, err = newRule(conf)
+	assert.NilError(t, err, "group rule create failed")
+	assert.Equal(t, gr.(*groupRule).resolver, configs.GroupResolverApplication, "default resolver not set")
+}
+
+func TestGroupRulePlace(t *testing.T) {
+	data := `
+partitions:
+  - name: default
+    queues:
+      - name: group2
+      - name: teams
+        parent: true
+        queues:
+          - name: group1
+`
+	err := initQueueStructure([]byte(data))
+	assert.NilError(t, err, "setting up the queue config failed")
+
+	user := security.UserGroup{
+		User:   "testuser",
+		Groups: []string{"group1", "group2"},
+	}
+	tags := make(map[string]string)
+	app := newApplication("app1", "default", "ignored", user, tags, nil, "")
+
+	// application groups: first existing queue
+	conf := configs.PlacementRule{Name: "group"}
+	var gr rule
+	gr, err = newRule(conf)
+	assert.NilError(t, err, "group rule create failed")
+	var queue string
+	queue, err = gr.placeApplication(app, queueFunc)
+	assert.NilError(t, err, "group rule place failed")
+	assert.Equal(t, queue, "root.group2", "group rule placed in the wrong queue")
+
+	// with a parent rule the primary group is used
+	conf.Parent = &configs.PlacementRule{Name: "fixed", Value: "teams"}
+	gr, err = newRule(conf)
+	assert.NilError(t, err, "group rule create failed")
+	queue, err = gr.placeApplication(app, queueFunc)
+	assert.NilError(t, err, "group rule place failed")
+	assert.Equal(t, queue, "root.teams.group1", "group rule placed in the wrong queue")
+
+	// static groups: no queue exists, create the primary group queue
+	conf = configs.PlacementRule{
+		Name:          "group",
+		GroupResolver: configs.GroupResolver{Type: "static", Users: map[string][]string{"testuser": {"group3", "group4"}}},
+	}
+	gr, err = newRule(conf)
+	assert.NilError(t, err, "group rule create failed")
+	queue, err = gr.placeApplication(app, queueFunc)
+	assert.NilError(t, err, "group rule place failed")
+	assert.Equal(t, queue, "", "group rule should not create the queue")
+	conf.Create = true
+	gr, err = newRule(conf)
+	assert.NilError(t, err, "group rule create failed")
+	queue, err = gr.placeApplication(app, queueFunc)
+	assert.NilError(t, err, "group rule place failed")
+	assert.Equal(t, queue, "root.group3", "group rule should create the primary group queue")
+	// unknown user in the static config
+	other := newApplication("app2", "default", "ignored", security.UserGroup{User: "other"}, tags, nil, "")
+	queue, err = gr.placeApplication(other, queueFunc)
+	assert.NilError(t, err, "group rule place failed")
+	assert.Equal(t, queue, "", "user without groups should not be placed")
+
+	// plugin groups: no plugin registered
+	conf = configs.PlacementRule{
+		Name:          "group",
+		GroupResolver: configs.GroupResolver{Type: "plugin"},
+	}
+	gr, err = newRule(conf)
+	assert.NilError(t, err, "group rule create failed")
+	queue, err = gr.placeApplication(app, queueFunc)
+	assert.NilError(t, err, "group rule place failed")
+	assert.Equal(t, queue, "", "group rule without plugin should not place")
+	plugins.RegisterSchedulerPlugin(&fakeGroupResolver{groups: map[string][]string{"testuser": {"unknown", "group2"}}})
+	queue, err = gr.placeApplication(app, queueFunc)
+	assert.NilError(t, err, "group rule place failed")
+	assert.Equal(t, queue, "root.group2", "group rule placed in the wrong queue")
+	queue, err = gr.placeApplication(other, queueFunc)
+	assert.NilError(t, err, "group rule place failed")
+	assert.Equal(t, queue, "", "failed resolution should not place")
+}
@@ -141,6 +141,8 @@ func (m *AppPlacementManager) PlaceApplication(app *objects.Application) error {
 	}
 	var queueName string
 	var err error
+	// reset the rule that placed the application, a rule that chains rules sets the rule it matched
+	app.SetPlacementRule("")
 	for _, checkRule := range m.rules {
 		log.Logger().Debug("Executing rule for placing application",
 			zap.String("ruleName", checkRule.getName()),
@@ -151,59 +153,28 @@ func (m *AppPlacementManager) PlaceApplication(app *objects.Application) error {
 				zap.String("ruleName", checkRule.getName()),
 				zap.Error(err))
 			app.QueueName = ""
+			app.SetPlacementRule("")
 			return err
 		}
 		// queueName returned make sure ACL allows access and create the queueName if not exist
 		if queueName != "" {
-			// get the queue object
-			queue := m.queueFn(queueName)
-			// walk up the tree if the queue does not exist
-			if queue == nil {
-				current := queueName
-				for queue == nil {
-					current = current[0:strings.LastIndex(current, configs.DOT)]
-					// check if the queue exist
-					queue = m.queueFn(current)
-				}
-				// Check if the user is allowed to submit to this queueName, if not next rule
-				if !queue.CheckSubmitAccess(app.GetUser()) {
-					log.Logger().Debug("Submit access denied on queue",
-						zap.String("queueName", queue.GetQueuePath()),
-						zap.String("ruleName", checkRule.getName()),
-						zap.String("application", app.ApplicationID))
-					// reset the queue name for the last rule in the chain
-					queueName = ""
-					continue
-				}
-			} else {
-				// Check if this final queue is a leaf queue, if not next rule
-				if !queue.IsLeafQueue() {
-					log.Logger().Debug("Rule returned parent queue",
-						zap.String("queueName", queueName),
-						zap.String("ruleName", checkRule.getName()),
-						zap.String("application", app.ApplicationID))
-					// reset the queue name for the last rule in the chain
-					queueName = ""
-					continue
-				}
-				// Check if the user is allowed to submit to this queueName, if not next rule
-				if !queue.CheckSubmitAccess(app.GetUser()) {
-					log.Logger().Debug("Submit access denied on queue",
-						zap.String("queueName", queueName),
-						zap.String("ruleName", checkRule.getName()),
-						zap.String("application", app.ApplicationID))
-					// reset the queue name for the last rule in the chain
-					queueName = ""
-					continue
-				}
+			if !checkQueue(queueName, checkRule.getName(), app, m.queueFn) {
+				// reset the queue name for the last rule in the chain
+				queueName = ""
+				app.SetPlacementRule("")
+				continue
 			}
 			// we have a queue that allows submitting and can be created: app placed
+			if app.GetPlacementRule() == "" {
+				app.SetPlacementRule(checkRule.getName())
+			}
 			break
 		}
 	}
 	log.Logger().Debug("Rule result for placing application",
 		zap.String("application", app.ApplicationID),
-		zap.String("queueName", queueName))
+		zap.String("queueName", queueName),
+		zap.String("ruleName", app.GetPlacementRule()))
 	// no more rules to check no queueName found reject placement
 	if queueName == "" {
 		app.QueueName = ""
@@ -213,3 +184,36 @@ func (m *AppPlacementManager) PlaceApplication(app *objects.Application) error {
 	app.SetQueueName(queueName)
 	return nil
 }
+
+// Check if the application can be placed in the queue returned by a rule:
+// - an existing queue must be a leaf queue
+// - the user must have submit access to the queue, or to the lowest existing parent if the queue does not exist
+func checkQueue(queueName, ruleName string, app *objects.Application, queueFn func(string) *objects.Queue) bool {
+	// get the queue object
+	queue := queueFn(queueName)
+	// walk up the tree if the queue does not exist
+	if queue == nil {
+		current := queueName
+		for queue == nil {
+			current = current[0:strings.LastIndex(current, configs.DOT)]
+			// check if the queue exist
+			queue = queueFn(current)
+		}
+	} else if !queue.IsLeafQueue() {
+		// Check if this final queue is a leaf queue, if not next rule
+		log.Logger().Debug("Rule returned parent queue",
+			zap.String("queueName", queueName),
+			zap.String("ruleName", ruleName),
+			zap.String("application", app.ApplicationID))
+		return false
+	}
+	// Check if the user is allowed to submit to this queueName, if not next rule
+	if !queue.CheckSubmitAccess(app.GetUser()) {
+		log.Logger().Debug("Submit access denied on queue",
+			zap.String("queueName", queue.GetQueuePath()),
+			zap.String("ruleName", ruleName),
+			zap.String("application", app.ApplicationID))
+		return false
+	}
+	return true
+}
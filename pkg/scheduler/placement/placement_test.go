@@ -223,6 +223,7 @@ partitions:
 	if err != nil || queueName != "root.testparent.testchild" {
 		t.Errorf("leaf exist: app should have been placed in user queue, queue: '%s', error: %v", queueName, err)
 	}
+	assert.Equal(t, app.GetPlacementRule(), "user", "placement rule not recorded")
 	user = security.UserGroup{
 		User:   "other-user",
 		Groups: []string{},
@@ -243,6 +244,7 @@ partitions:
 	if err != nil || queueName != "root.fixed.leaf" {
 		t.Errorf("leave create, acl allow: app should have been placed, queue: '%s', error: %v", queueName, err)
 	}
+	assert.Equal(t, app.GetPlacementRule(), "provided", "placement rule not recorded")
 
 	// provided rule (2rd): queue acl deny, queue does not exist
 	user = security.UserGroup{
@@ -276,6 +278,7 @@ partitions:
 	if err != nil || queueName != "root.fixed.leaf" {
 		t.Errorf("existing leaf, acl allow: app should have been placed, queue: '%s', error: %v", queueName, err)
 	}
+	assert.Equal(t, app.GetPlacementRule(), "tag", "placement rule not recorded")
 
 	// provided rule (2nd): submit to parent
 	app = newApplication("app1", "default", "root.fixed", user, nil, nil, "")
@@ -284,4 +287,5 @@ partitions:
 	if err == nil || queueName != "" {
 		t.Errorf("parent queue: app should not have been placed, queue: '%s', error: %v", queueName, err)
 	}
+	assert.Equal(t, app.GetPlacementRule(), "", "placement rule should not be set for a rejected app")
 }
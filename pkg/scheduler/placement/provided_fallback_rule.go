@@ -0,0 +1,86 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package placement
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/configs"
+	"github.com/apache/incubator-yunikorn-core/pkg/log"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/objects"
+)
+
+// A rule to place an application in the queue provided by the user on submission with a fallback chain.
+// The provided queue is handled as in the provided rule. If the provided queue cannot be used, the queue is not set,
+// cannot be created or the user has no access, the fallback rules are tried in order.
+// The rule that placed the application is recorded in the application as: providedfallback/<rule name>
+type providedFallbackRule struct {
+	provided providedRule
+	fallback []rule
+}
+
+func (pfr *providedFallbackRule) getName() string {
+	return "providedfallback"
+}
+
+// The parent is part of the provided rule, used in testing only.
+func (pfr *providedFallbackRule) getParent() rule {
+	return pfr.provided.getParent()
+}
+
+func (pfr *providedFallbackRule) initialise(conf configs.PlacementRule) error {
+	if err := pfr.provided.initialise(conf); err != nil {
+		return err
+	}
+	pfr.fallback = nil
+	for _, fallbackConf := range conf.Fallback {
+		if normalise(fallbackConf.Name) == pfr.getName() {
+			return fmt.Errorf("a %s rule cannot be used as a fallback rule", pfr.getName())
+		}
+		fallbackRule, err := newRule(fallbackConf)
+		if err != nil {
+			return err
+		}
+		pfr.fallback = append(pfr.fallback, fallbackRule)
+	}
+	return nil
+}
+
+func (pfr *providedFallbackRule) placeApplication(app *objects.Application, queueFn func(string) *objects.Queue) (string, error) {
+	chain := append([]rule{&pfr.provided}, pfr.fallback...)
+	for _, checkRule := range chain {
+		queueName, err := checkRule.placeApplication(app, queueFn)
+		if err != nil {
+			return "", err
+		}
+		if queueName == "" || !checkQueue(queueName, checkRule.getName(), app, queueFn) {
+			continue
+		}
+		ruleName := pfr.getName() + "/" + checkRule.getName()
+		log.Logger().Debug("Provided fallback rule application placed",
+			zap.String("application", app.ApplicationID),
+			zap.String("queue", queueName),
+			zap.String("ruleName", ruleName))
+		app.SetPlacementRule(ruleName)
+		return queueName, nil
+	}
+	return "", nil
+}
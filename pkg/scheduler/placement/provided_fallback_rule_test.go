@@ -0,0 +1,118 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package placement
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/configs"
+	"github.com/apache/incubator-yunikorn-core/pkg/common/security"
+)
+
+func TestProvidedFallbackRuleInitialise(t *testing.T) {
+	conf := configs.PlacementRule{
+		Name: "providedfallback",
+		Fallback: []configs.PlacementRule{
+			{Name: "tag", Value: "namespace"},
+			{Name: "fixed", Value: "root.default"},
+		},
+	}
+	pfr, err := newRule(conf)
+	assert.NilError(t, err, "provided fallback rule create failed")
+	assert.Equal(t, len(pfr.(*providedFallbackRule).fallback), 2, "expected two fallback rules")
+
+	// broken fallback rule fails the rule
+	conf.Fallback = []configs.PlacementRule{{Name: "fixed"}}
+	_, err = newRule(conf)
+	assert.ErrorContains(t, err, "fixed queue rule must have a queue name set")
+
+	// no nesting of the rule
+	conf.Fallback = []configs.PlacementRule{{Name: "providedfallback"}}
+	_, err = newRule(conf)
+	assert.ErrorContains(t, err, "cannot be used as a fallback rule")
+}
+
+func TestProvidedFallbackRulePlace(t *testing.T) {
+	data := `
+partitions:
+  - name: default
+    queues:
+      - name: root
+        queues:
+          - name: default
+            submitacl: "*"
+          - name: restricted
+          - name: tenants
+            parent: true
+            submitacl: "*"
+`
+	err := initQueueStructure([]byte(data))
+	assert.NilError(t, err, "setting up the queue config failed")
+
+	conf := configs.PlacementRule{
+		Name: "providedfallback",
+		Fallback: []configs.PlacementRule{
+			{Name: "tag", Value: "namespace", Create: true, Parent: &configs.PlacementRule{Name: "fixed", Value: "tenants"}},
+			{Name: "fixed", Value: "root.default"},
+		},
+	}
+	var pfr rule
+	pfr, err = newRule(conf)
+	assert.NilError(t, err, "provided fallback rule create failed")
+	user := security.UserGroup{User: "test"}
+
+	// provided queue exists
+	app := newApplication("app1", "default", "root.default", user, nil, nil, "")
+	queue, err := pfr.placeApplication(app, queueFunc)
+	assert.NilError(t, err, "rule execution failed")
+	assert.Equal(t, queue, "root.default", "provided queue not used")
+	assert.Equal(t, app.GetPlacementRule(), "providedfallback/provided", "matched rule not recorded")
+
+	// provided queue does not exist: tag rule
+	app = newApplication("app1", "default", "root.unknown", user, map[string]string{"namespace": "ns1"}, nil, "")
+	queue, err = pfr.placeApplication(app, queueFunc)
+	assert.NilError(t, err, "rule execution failed")
+	assert.Equal(t, queue, "root.tenants.ns1", "tag fallback not used")
+	assert.Equal(t, app.GetPlacementRule(), "providedfallback/tag", "matched rule not recorded")
+
+	// provided queue is a parent and no tag: fixed rule
+	app = newApplication("app1", "default", "root.tenants", user, nil, nil, "")
+	queue, err = pfr.placeApplication(app, queueFunc)
+	assert.NilError(t, err, "rule execution failed")
+	assert.Equal(t, queue, "root.default", "fixed fallback not used")
+	assert.Equal(t, app.GetPlacementRule(), "providedfallback/fixed", "matched rule not recorded")
+
+	// provided queue denies access: fixed rule
+	app = newApplication("app1", "default", "root.restricted", user, nil, nil, "")
+	queue, err = pfr.placeApplication(app, queueFunc)
+	assert.NilError(t, err, "rule execution failed")
+	assert.Equal(t, queue, "root.default", "fixed fallback not used for denied queue")
+
+	// nothing matches: no queue
+	conf.Fallback = nil
+	pfr, err = newRule(conf)
+	assert.NilError(t, err, "provided fallback rule create failed")
+	app = newApplication("app1", "default", "root.unknown", user, nil, nil, "")
+	queue, err = pfr.placeApplication(app, queueFunc)
+	assert.NilError(t, err, "rule execution failed")
+	assert.Equal(t, queue, "", "no queue expected without fallback")
+	assert.Equal(t, app.GetPlacementRule(), "", "no rule expected without a match")
+}
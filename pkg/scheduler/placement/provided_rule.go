@@ -44,6 +44,7 @@ func (pr *providedRule) getName() string {
 func (pr *providedRule) initialise(conf configs.PlacementRule) error {
 	pr.create = conf.Create
 	pr.filter = newFilter(conf.Filter)
+	pr.setSanitize(conf)
 	var err = error(nil)
 	if conf.Parent != nil {
 		pr.parent, err = newRule(*conf.Parent)
@@ -94,7 +95,15 @@ func (pr *providedRule) placeApplication(app *objects.Application, queueFn func(
 			parentName = configs.RootQueue
 		}
 		// Make it a fully qualified queue
-		queueName = parentName + configs.DOT + replaceDot(queueName)
+		var childName string
+		childName, err = pr.cleanQueueName(queueName)
+		if err != nil {
+			log.Logger().Info("Provided rule generated invalid queue name",
+				zap.String("application", app.ApplicationID),
+				zap.Error(err))
+			return "", nil
+		}
+		queueName = parentName + configs.DOT + childName
 	}
 	log.Logger().Debug("Provided rule intermediate result",
 		zap.String("application", app.ApplicationID),
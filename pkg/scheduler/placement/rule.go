@@ -21,8 +21,11 @@ package placement
 import (
 	"fmt"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"go.uber.org/zap"
+	"golang.org/x/text/unicode/norm"
 
 	"github.com/apache/incubator-yunikorn-core/pkg/common/configs"
 	"github.com/apache/incubator-yunikorn-core/pkg/log"
@@ -54,9 +57,10 @@ type rule interface {
 // Linter does not pick up on the usage in the implementation(s).
 //nolint:structcheck
 type basicRule struct {
-	create bool
-	parent rule
-	filter Filter
+	create         bool
+	parent         rule
+	filter         Filter
+	replaceInvalid bool
 }
 
 // Get the parent rule used in testing only.
@@ -83,15 +87,24 @@ func newRule(conf configs.PlacementRule) (rule, error) {
 	// rule that uses the user's name as the queue
 	case "user":
 		newRule = &userRule{}
+	// rule that uses the groups of the user as the queue
+	case "group":
+		newRule = &groupRule{}
 	// rule that uses a fixed queue name
 	case "fixed":
 		newRule = &fixedRule{}
 	// rule that uses the queue provided on submit
 	case "provided":
 		newRule = &providedRule{}
+	// rule that uses the queue provided on submit with a chain of fallback rules
+	case "providedfallback":
+		newRule = &providedFallbackRule{}
 	// rule that uses a tag from the application (like namespace)
 	case "tag":
 		newRule = &tagRule{}
+	// rule that maps a tag from the application through a list of expressions
+	case "tagmapping":
+		newRule = &tagMappingRule{}
 	// test rule not to be used outside of testing code
 	case "test":
 		newRule = &testRule{}
@@ -119,3 +132,38 @@ func normalise(name string) string {
 func replaceDot(name string) string {
 	return strings.Replace(name, configs.DOT, configs.DotReplace, -1)
 }
+
+// Set the handling of invalid generated queue names from the rule config.
+func (r *basicRule) setSanitize(conf configs.PlacementRule) {
+	r.replaceInvalid = strings.EqualFold(conf.Sanitize, configs.SanitizeReplace)
+}
+
+// Turn a value from the application (user name, tag value etc.) into a valid queue name.
+// The value is normalised (unicode NFKC) and the dots are replaced before the name is checked.
+// If the rule is configured to replace invalid names, accents are removed, all disallowed characters are replaced with
+// an underscore and the name is truncated to the maximum length. Otherwise an invalid name is rejected.
+func (r *basicRule) cleanQueueName(value string) (string, error) {
+	name := replaceDot(norm.NFKC.String(value))
+	if r.replaceInvalid {
+		var b strings.Builder
+		for _, char := range norm.NFKD.String(name) {
+			switch {
+			case unicode.Is(unicode.Mn, char):
+				continue
+			case char < unicode.MaxASCII && (unicode.IsLetter(char) || unicode.IsDigit(char) || char == '_' || char == '-'):
+				b.WriteRune(char)
+			default:
+				b.WriteRune('_')
+			}
+		}
+		name = b.String()
+		if len(name) > configs.MaxQueueNameLength {
+			name = name[:configs.MaxQueueNameLength]
+		}
+	}
+	if !utf8.ValidString(name) || !configs.QueueNameRegExp.MatchString(name) {
+		return "", fmt.Errorf("invalid queue name '%s' generated from '%s', a name must only have alphanumeric characters,"+
+			" - or _, and be no longer than %d characters", name, value, configs.MaxQueueNameLength)
+	}
+	return name, nil
+}
@@ -19,6 +19,7 @@
 package placement
 
 import (
+	"strings"
 	"testing"
 
 	"gotest.tools/assert"
@@ -84,6 +85,43 @@ func TestPlaceApp(t *testing.T) {
 	}
 }
 
+func TestCleanQueueName(t *testing.T) {
+	long := strings.Repeat("a", 70)
+	tests := []struct {
+		name    string
+		value   string
+		replace bool
+		want    string
+		wantErr bool
+	}{
+		{"valid", "user", false, "user", false},
+		{"dots", "first.last", false, "first_dot_last", false},
+		{"full width", "ｕｓｅｒ", false, "user", false},
+		{"accent reject", "josé", false, "", true},
+		{"accent replace", "josé", true, "jose", false},
+		{"at sign reject", "user@example", false, "", true},
+		{"at sign replace", "user@example", true, "user_example", false},
+		{"invalid utf8 reject", "user\xff", false, "", true},
+		{"invalid utf8 replace", "user\xff", true, "user_", false},
+		{"too long reject", long, false, "", true},
+		{"too long replace", long, true, long[:64], false},
+		{"non latin replace", "用户", true, "__", false},
+		{"empty", "", true, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &basicRule{replaceInvalid: tt.replace}
+			got, err := r.cleanQueueName(tt.value)
+			if tt.wantErr {
+				assert.Assert(t, err != nil, "expected error for value %s got name %s", tt.value, got)
+				return
+			}
+			assert.NilError(t, err, "unexpected error")
+			assert.Equal(t, got, tt.want, "unexpected queue name")
+		})
+	}
+}
+
 func TestReplaceDot(t *testing.T) {
 	name := replaceDot("name.name")
 	if name != "name_dot_name" {
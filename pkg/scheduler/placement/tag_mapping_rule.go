@@ -0,0 +1,178 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package placement
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/configs"
+	"github.com/apache/incubator-yunikorn-core/pkg/log"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/objects"
+)
+
+// A rule to place an application based on a tag on the application mapped through a list of expressions.
+// The first expression that matches the whole tag value is used, the queue is generated from the mapping and can
+// reference the groups of the expression. A generated queue that is not fully qualified is placed below the parent
+// generated by the parent rule or the root. Each part of the generated queue name must be a valid queue name.
+// Example: tag value "team-a" mapped with "team-(.*)" to "root.teams.$1" generates queue "root.teams.a"
+// NOTE: tags are normalised and only use lower case (not case sensitive)
+type tagMappingRule struct {
+	basicRule
+	tagName  string
+	mappings []tagMapping
+}
+
+type tagMapping struct {
+	match *regexp.Regexp
+	queue string
+}
+
+func (tmr *tagMappingRule) getName() string {
+	return "tagmapping"
+}
+
+func (tmr *tagMappingRule) initialise(conf configs.PlacementRule) error {
+	tmr.tagName = normalise(conf.Value)
+	if tmr.tagName == "" {
+		return fmt.Errorf("a tag mapping rule must have a tag name set")
+	}
+	if len(conf.Mappings) == 0 {
+		return fmt.Errorf("a tag mapping rule must have at least one mapping set")
+	}
+	tmr.mappings = make([]tagMapping, 0, len(conf.Mappings))
+	for _, mapping := range conf.Mappings {
+		// anchor the expression: it must match the whole value
+		match, err := regexp.Compile("^(?:" + mapping.Match + ")$")
+		if err != nil {
+			return fmt.Errorf("invalid tag mapping expression %s: %v", mapping.Match, err)
+		}
+		if mapping.Queue == "" {
+			return fmt.Errorf("tag mapping for expression %s has no queue set", mapping.Match)
+		}
+		tmr.mappings = append(tmr.mappings, tagMapping{match: match, queue: mapping.Queue})
+	}
+	tmr.create = conf.Create
+	tmr.filter = newFilter(conf.Filter)
+	tmr.setSanitize(conf)
+	var err = error(nil)
+	if conf.Parent != nil {
+		tmr.parent, err = newRule(*conf.Parent)
+	}
+	return err
+}
+
+// Return the queue name generated by the first mapping that matches the value or an empty string if none match.
+func (tmr *tagMappingRule) mapValue(value string) string {
+	for _, mapping := range tmr.mappings {
+		submatches := mapping.match.FindStringSubmatchIndex(value)
+		if submatches == nil {
+			continue
+		}
+		return string(mapping.match.ExpandString(nil, mapping.queue, value, submatches))
+	}
+	return ""
+}
+
+func (tmr *tagMappingRule) placeApplication(app *objects.Application, queueFn func(string) *objects.Queue) (string, error) {
+	// if the tag is not present we can skip all other processing
+	tagVal := app.GetTag(tmr.tagName)
+	if tagVal == "" {
+		return "", nil
+	}
+	// before anything run the filter
+	if !tmr.filter.allowUser(app.GetUser()) {
+		log.Logger().Debug("Tag mapping rule filtered",
+			zap.String("application", app.ApplicationID),
+			zap.Any("user", app.GetUser()),
+			zap.String("tagName", tmr.tagName))
+		return "", nil
+	}
+	mapped := tmr.mapValue(tagVal)
+	if mapped == "" {
+		log.Logger().Debug("Tag mapping rule no mapping matched",
+			zap.String("application", app.ApplicationID),
+			zap.String("tagName", tmr.tagName),
+			zap.String("tagValue", tagVal))
+		return "", nil
+	}
+	// check each part of the generated name, the root is not checked
+	parts := strings.Split(mapped, configs.DOT)
+	qualified := parts[0] == configs.RootQueue && len(parts) > 1
+	if qualified {
+		parts = parts[1:]
+	}
+	for i, part := range parts {
+		name, err := tmr.cleanQueueName(part)
+		if err != nil {
+			log.Logger().Info("Tag mapping rule generated invalid queue name",
+				zap.String("application", app.ApplicationID),
+				zap.String("tagName", tmr.tagName),
+				zap.String("queue", mapped),
+				zap.Error(err))
+			return "", nil
+		}
+		parts[i] = name
+	}
+	childName := strings.Join(parts, configs.DOT)
+	var parentName string
+	// if we have a fully qualified queue do not run the parent rule
+	if qualified {
+		parentName = configs.RootQueue
+	} else if tmr.parent != nil {
+		var err error
+		parentName, err = tmr.parent.placeApplication(app, queueFn)
+		// failed parent rule, fail this rule
+		if err != nil {
+			return "", err
+		}
+		// rule did not match: this could be filter or create flag related
+		if parentName == "" {
+			return "", nil
+		}
+		// check if this is a parent queue and qualify it
+		if !strings.HasPrefix(parentName, configs.RootQueue+configs.DOT) {
+			parentName = configs.RootQueue + configs.DOT + parentName
+		}
+		// if the parent queue exists it cannot be a leaf
+		parentQueue := queueFn(parentName)
+		if parentQueue != nil && parentQueue.IsLeafQueue() {
+			return "", fmt.Errorf("parent rule returned a leaf queue: %s", parentName)
+		}
+	} else {
+		parentName = configs.RootQueue
+	}
+	queueName := parentName + configs.DOT + childName
+	log.Logger().Debug("Tag mapping rule intermediate result",
+		zap.String("application", app.ApplicationID),
+		zap.String("queue", queueName))
+	// get the queue object
+	queue := queueFn(queueName)
+	// if we cannot create the queue it must exist, rule does not match otherwise
+	if !tmr.create && queue == nil {
+		return "", nil
+	}
+	log.Logger().Info("Tag mapping rule application placed",
+		zap.String("application", app.ApplicationID),
+		zap.String("queue", queueName))
+	return queueName, nil
+}
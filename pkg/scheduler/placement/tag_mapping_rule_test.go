@@ -0,0 +1,144 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package placement
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/configs"
+	"github.com/apache/incubator-yunikorn-core/pkg/common/security"
+)
+
+func TestTagMappingRule(t *testing.T) {
+	conf := configs.PlacementRule{
+		Name:     "tagmapping",
+		Mappings: []configs.TagMapping{{Match: "team-(.*)", Queue: "root.teams.$1"}},
+	}
+	_, err := newRule(conf)
+	assert.ErrorContains(t, err, "must have a tag name set")
+	conf.Value = "namespace"
+	conf.Mappings = nil
+	_, err = newRule(conf)
+	assert.ErrorContains(t, err, "at least one mapping")
+	conf.Mappings = []configs.TagMapping{{Match: "(", Queue: "root.default"}}
+	_, err = newRule(conf)
+	assert.ErrorContains(t, err, "invalid tag mapping expression")
+	conf.Mappings = []configs.TagMapping{{Match: ".*"}}
+	_, err = newRule(conf)
+	assert.ErrorContains(t, err, "has no queue set")
+	conf.Mappings = []configs.TagMapping{{Match: "team-(.*)", Queue: "root.teams.$1"}}
+	var tmr rule
+	tmr, err = newRule(conf)
+	assert.NilError(t, err, "tag mapping rule create failed")
+	assert.Equal(t, tmr.getName(), "tagmapping")
+}
+
+func TestTagMappingRulePlace(t *testing.T) {
+	data := `
+partitions:
+  - name: default
+    queues:
+      - name: testqueue
+      - name: teams
+        parent: true
+        queues:
+          - name: a
+`
+	err := initQueueStructure([]byte(data))
+	assert.NilError(t, err, "setting up the queue config failed")
+
+	user := security.UserGroup{
+		User:   "testuser",
+		Groups: []string{},
+	}
+	conf := configs.PlacementRule{
+		Name:  "tagmapping",
+		Value: "namespace",
+		Mappings: []configs.TagMapping{
+			{Match: "team-(?P<team>[a-z]+)", Queue: "root.teams.${team}"},
+			{Match: "dev-(.*)-(.*)", Queue: "$2.$1"},
+			{Match: "sys-.*", Queue: "testqueue"},
+		},
+	}
+	var tmr rule
+	tmr, err = newRule(conf)
+	assert.NilError(t, err, "tag mapping rule create failed")
+
+	tests := []struct {
+		name  string
+		tags  map[string]string
+		queue string
+	}{
+		{"no tag", map[string]string{}, ""},
+		{"no mapping", map[string]string{"namespace": "other"}, ""},
+		{"partial match", map[string]string{"namespace": "my-team-a"}, ""},
+		{"named group", map[string]string{"namespace": "team-a"}, "root.teams.a"},
+		{"queue does not exist", map[string]string{"namespace": "team-b"}, ""},
+		{"unqualified", map[string]string{"namespace": "sys-kube"}, "root.testqueue"},
+		{"empty part", map[string]string{"namespace": "dev--teams"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newApplication("app1", "default", "ignored", user, tt.tags, nil, "")
+			var queue string
+			queue, err = tmr.placeApplication(app, queueFunc)
+			assert.NilError(t, err, "tag mapping rule place failed")
+			assert.Equal(t, queue, tt.queue, "tag mapping rule placed in the wrong queue")
+		})
+	}
+
+	// create the queue, with a parent rule
+	conf.Create = true
+	conf.Parent = &configs.PlacementRule{Name: "fixed", Value: "teams"}
+	tmr, err = newRule(conf)
+	assert.NilError(t, err, "tag mapping rule create failed")
+	app := newApplication("app1", "default", "ignored", user, map[string]string{"namespace": "team-b"}, nil, "")
+	var queue string
+	queue, err = tmr.placeApplication(app, queueFunc)
+	assert.NilError(t, err, "tag mapping rule place failed")
+	assert.Equal(t, queue, "root.teams.b", "qualified queue should not use the parent rule")
+	app = newApplication("app1", "default", "ignored", user, map[string]string{"namespace": "dev-x-y"}, nil, "")
+	queue, err = tmr.placeApplication(app, queueFunc)
+	assert.NilError(t, err, "tag mapping rule place failed")
+	assert.Equal(t, queue, "root.teams.y.x", "unqualified queue should use the parent rule")
+	// parent rule returns a leaf
+	conf.Parent = &configs.PlacementRule{Name: "fixed", Value: "testqueue"}
+	tmr, err = newRule(conf)
+	assert.NilError(t, err, "tag mapping rule create failed")
+	_, err = tmr.placeApplication(app, queueFunc)
+	assert.ErrorContains(t, err, "parent rule returned a leaf queue")
+
+	// invalid names are replaced when configured
+	conf.Parent = nil
+	conf.Mappings = []configs.TagMapping{{Match: "(.*)", Queue: "root.teams.$1"}}
+	tmr, err = newRule(conf)
+	assert.NilError(t, err, "tag mapping rule create failed")
+	app = newApplication("app1", "default", "ignored", user, map[string]string{"namespace": "a:b"}, nil, "")
+	queue, err = tmr.placeApplication(app, queueFunc)
+	assert.NilError(t, err, "tag mapping rule place failed")
+	assert.Equal(t, queue, "", "invalid name should be rejected")
+	conf.Sanitize = configs.SanitizeReplace
+	tmr, err = newRule(conf)
+	assert.NilError(t, err, "tag mapping rule create failed")
+	queue, err = tmr.placeApplication(app, queueFunc)
+	assert.NilError(t, err, "tag mapping rule place failed")
+	assert.Equal(t, queue, "root.teams.a_b", "invalid name should be replaced")
+}
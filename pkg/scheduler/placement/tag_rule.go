@@ -49,6 +49,7 @@ func (tr *tagRule) initialise(conf configs.PlacementRule) error {
 	}
 	tr.create = conf.Create
 	tr.filter = newFilter(conf.Filter)
+	tr.setSanitize(conf)
 	var err = error(nil)
 	if conf.Parent != nil {
 		tr.parent, err = newRule(*conf.Parent)
@@ -100,7 +101,16 @@ func (tr *tagRule) placeApplication(app *objects.Application, queueFn func(strin
 		if parentName == "" {
 			parentName = configs.RootQueue
 		}
-		queueName = parentName + configs.DOT + replaceDot(tagVal)
+		var childName string
+		childName, err = tr.cleanQueueName(tagVal)
+		if err != nil {
+			log.Logger().Info("Tag rule generated invalid queue name",
+				zap.String("application", app.ApplicationID),
+				zap.String("tagName", tr.tagName),
+				zap.Error(err))
+			return "", nil
+		}
+		queueName = parentName + configs.DOT + childName
 	}
 	log.Logger().Debug("Tag rule intermediate result",
 		zap.String("application", app.ApplicationID),
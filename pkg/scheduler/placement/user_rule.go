@@ -41,6 +41,7 @@ func (ur *userRule) getName() string {
 func (ur *userRule) initialise(conf configs.PlacementRule) error {
 	ur.create = conf.Create
 	ur.filter = newFilter(conf.Filter)
+	ur.setSanitize(conf)
 	var err = error(nil)
 	if conf.Parent != nil {
 		ur.parent, err = newRule(*conf.Parent)
@@ -84,7 +85,14 @@ func (ur *userRule) placeApplication(app *objects.Application, queueFn func(stri
 	if parentName == "" {
 		parentName = configs.RootQueue
 	}
-	queueName := parentName + configs.DOT + replaceDot(userName)
+	childName, err := ur.cleanQueueName(userName)
+	if err != nil {
+		log.Logger().Info("User rule generated invalid queue name",
+			zap.String("application", app.ApplicationID),
+			zap.Error(err))
+		return "", nil
+	}
+	queueName := parentName + configs.DOT + childName
 	log.Logger().Debug("User rule intermediate result",
 		zap.String("application", app.ApplicationID),
 		zap.String("queue", queueName))
@@ -127,6 +127,46 @@ partitions:
 	}
 }
 
+func TestUserRuleSanitize(t *testing.T) {
+	// Create the structure for the test
+	data := `
+partitions:
+  - name: default
+    queues:
+      - name: testqueue
+`
+	err := initQueueStructure([]byte(data))
+	assert.NilError(t, err, "setting up the queue config failed")
+
+	tags := make(map[string]string)
+	user := security.UserGroup{
+		User:   "user@example.com",
+		Groups: []string{},
+	}
+	appInfo := newApplication("app1", "default", "ignored", user, tags, nil, "")
+
+	// default rejects the invalid name: rule does not match
+	conf := configs.PlacementRule{
+		Name:   "user",
+		Create: true,
+	}
+	var ur rule
+	ur, err = newRule(conf)
+	assert.NilError(t, err, "user rule create failed")
+	var queue string
+	queue, err = ur.placeApplication(appInfo, queueFunc)
+	assert.NilError(t, err, "invalid name should not fail the rule")
+	assert.Equal(t, queue, "", "invalid name should not have been placed")
+
+	// replace the invalid characters
+	conf.Sanitize = configs.SanitizeReplace
+	ur, err = newRule(conf)
+	assert.NilError(t, err, "user rule create failed")
+	queue, err = ur.placeApplication(appInfo, queueFunc)
+	assert.NilError(t, err, "sanitized name should not fail the rule")
+	assert.Equal(t, queue, "root.user_example_dot_com", "unexpected sanitized queue name")
+}
+
 func TestUserRuleParent(t *testing.T) {
 	err := initQueueStructure([]byte(confParentChild))
 	assert.NilError(t, err, "setting up the queue config failed")
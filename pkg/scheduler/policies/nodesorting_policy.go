@@ -28,6 +28,7 @@ import (
 
 type NodeSortingPolicy struct {
 	PolicyType SortingPolicy
+	Name       string
 }
 
 type SortingPolicy int
@@ -35,11 +36,12 @@ type SortingPolicy int
 const (
 	BinPackingPolicy SortingPolicy = iota
 	FairnessPolicy
+	CustomPolicy
 	Unknown
 )
 
 func (nsp SortingPolicy) String() string {
-	return [...]string{"binpacking", "fair", "undefined"}[nsp]
+	return [...]string{"binpacking", "fair", "custom", "undefined"}[nsp]
 }
 
 func FromString(str string) (SortingPolicy, error) {
@@ -50,6 +52,9 @@ func FromString(str string) (SortingPolicy, error) {
 	case BinPackingPolicy.String():
 		return BinPackingPolicy, nil
 	default:
+		if isRegisteredPolicy(str) {
+			return CustomPolicy, nil
+		}
 		return Unknown, fmt.Errorf("undefined policy: %s", str)
 	}
 }
@@ -60,11 +65,17 @@ func NewNodeSortingPolicy(policyType string) *NodeSortingPolicy {
 		log.Logger().Debug("node sorting policy defaulted to 'undefined'",
 			zap.Error(err))
 	}
+	name := pType.String()
+	if pType == CustomPolicy {
+		name = policyType
+	}
 	sp := &NodeSortingPolicy{
 		PolicyType: pType,
+		Name:       name,
 	}
 
 	log.Logger().Debug("new node sorting policy added",
-		zap.String("type", pType.String()))
+		zap.String("type", pType.String()),
+		zap.String("name", name))
 	return sp
 }
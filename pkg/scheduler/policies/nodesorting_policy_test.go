@@ -55,6 +55,7 @@ func TestToString(t *testing.T) {
 	}{
 		{"FairString", FairnessPolicy, "fair"},
 		{"BinString", BinPackingPolicy, "binpacking"},
+		{"CustomString", CustomPolicy, "custom"},
 		{"DefaultString", Unknown, "undefined"},
 		{"NoneString", someSP, "binpacking"},
 	}
@@ -0,0 +1,95 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package policies
+
+import (
+	"fmt"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+	"github.com/apache/incubator-yunikorn-core/pkg/log"
+)
+
+// SortableNode is the view of a node that is exposed to a custom node sorting policy.
+type SortableNode interface {
+	GetAttribute(key string) string
+	GetCapacity() *resources.Resource
+	GetAllocatedResource() *resources.Resource
+	GetAvailableResource() *resources.Resource
+}
+
+// NodeLessFunc returns true if node l must be sorted before node r.
+type NodeLessFunc func(l, r SortableNode) bool
+
+var customNodeSorting = &nodeSortingRegistry{
+	policies: make(map[string]NodeLessFunc),
+}
+
+type nodeSortingRegistry struct {
+	policies map[string]NodeLessFunc
+
+	sync.RWMutex
+}
+
+// Register a custom node sorting policy under the name used in the partition configuration.
+// Registering a policy with a name that is already registered replaces the existing policy.
+// The built-in policy names cannot be used for custom policies.
+func RegisterNodeSortingPolicy(name string, less NodeLessFunc) error {
+	if name == "" || less == nil {
+		return fmt.Errorf("node sorting policy must have a name and a sort function")
+	}
+	if isBuiltinPolicy(name) {
+		return fmt.Errorf("node sorting policy name is reserved for a built-in policy: %s", name)
+	}
+	customNodeSorting.Lock()
+	defer customNodeSorting.Unlock()
+	if _, ok := customNodeSorting.policies[name]; ok {
+		log.Logger().Info("replacing registered node sorting policy",
+			zap.String("name", name))
+	}
+	customNodeSorting.policies[name] = less
+	return nil
+}
+
+// Remove a custom node sorting policy from the registry.
+// Partitions that use the policy fall back to the fair policy until the policy is registered again.
+func UnregisterNodeSortingPolicy(name string) {
+	customNodeSorting.Lock()
+	defer customNodeSorting.Unlock()
+	delete(customNodeSorting.policies, name)
+}
+
+// Return the sort function for the registered custom node sorting policy or nil if not registered.
+func GetNodeSortingFunc(name string) NodeLessFunc {
+	customNodeSorting.RLock()
+	defer customNodeSorting.RUnlock()
+	return customNodeSorting.policies[name]
+}
+
+// Return true if the name is registered as a custom node sorting policy.
+func isRegisteredPolicy(name string) bool {
+	return GetNodeSortingFunc(name) != nil
+}
+
+func isBuiltinPolicy(name string) bool {
+	return name == FairnessPolicy.String() || name == BinPackingPolicy.String() ||
+		name == CustomPolicy.String() || name == Unknown.String()
+}
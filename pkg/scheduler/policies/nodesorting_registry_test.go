@@ -0,0 +1,60 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package policies
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestRegisterNodeSortingPolicy(t *testing.T) {
+	less := func(l, r SortableNode) bool { return false }
+	defer UnregisterNodeSortingPolicy("test")
+
+	err := RegisterNodeSortingPolicy("", less)
+	assert.Assert(t, err != nil, "empty name should not have been registered")
+	err = RegisterNodeSortingPolicy("test", nil)
+	assert.Assert(t, err != nil, "nil sort function should not have been registered")
+	for _, name := range []string{"fair", "binpacking", "custom", "undefined"} {
+		err = RegisterNodeSortingPolicy(name, less)
+		assert.Assert(t, err != nil, "built-in name %s should not have been registered", name)
+	}
+	assert.Assert(t, GetNodeSortingFunc("test") == nil, "policy should not be registered")
+	_, err = FromString("test")
+	assert.Assert(t, err != nil, "unregistered policy should not be known")
+
+	err = RegisterNodeSortingPolicy("test", less)
+	assert.NilError(t, err, "policy should have been registered")
+	assert.Assert(t, GetNodeSortingFunc("test") != nil, "policy should be registered")
+	policy, err := FromString("test")
+	assert.NilError(t, err, "registered policy should be known")
+	assert.Equal(t, policy, CustomPolicy, "registered policy should be a custom policy")
+	nsp := NewNodeSortingPolicy("test")
+	assert.Equal(t, nsp.PolicyType, CustomPolicy, "unexpected policy type")
+	assert.Equal(t, nsp.Name, "test", "unexpected policy name")
+
+	// replace the registered policy
+	err = RegisterNodeSortingPolicy("test", func(l, r SortableNode) bool { return true })
+	assert.NilError(t, err, "policy should have been replaced")
+	assert.Assert(t, GetNodeSortingFunc("test")(nil, nil), "policy was not replaced")
+
+	UnregisterNodeSortingPolicy("test")
+	assert.Assert(t, GetNodeSortingFunc("test") == nil, "policy should have been removed")
+}
@@ -29,11 +29,14 @@ const (
 	FifoSortPolicy   SortPolicy = iota // first in first out, submit time
 	FairSortPolicy                     // fair based on usage
 	StateAwarePolicy                   // only 1 app in starting state
+	PriorityPolicy                     // highest priority of the pending asks first, submit time
+	DRFSortPolicy                      // dominant resource fairness based on the partition resources
+	StrictFifoPolicy                   // first in first out, submit time: only the head application is allocated
 	Undefined                          // not initialised or parsing failed
 )
 
 func (s SortPolicy) String() string {
-	return [...]string{"fifo", "fair", "stateaware", "undefined"}[s]
+	return [...]string{"fifo", "fair", "stateaware", "priority", "drf", "strictfifo", "undefined"}[s]
 }
 
 func SortPolicyFromString(str string) (SortPolicy, error) {
@@ -45,6 +48,12 @@ func SortPolicyFromString(str string) (SortPolicy, error) {
 		return FairSortPolicy, nil
 	case StateAwarePolicy.String():
 		return StateAwarePolicy, nil
+	case PriorityPolicy.String():
+		return PriorityPolicy, nil
+	case DRFSortPolicy.String():
+		return DRFSortPolicy, nil
+	case StrictFifoPolicy.String():
+		return StrictFifoPolicy, nil
 	default:
 		return Undefined, fmt.Errorf("undefined policy: %s", str)
 	}
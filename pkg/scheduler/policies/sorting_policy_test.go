@@ -33,6 +33,9 @@ func TestAppFromString(t *testing.T) {
 		{"FifoString", "fifo", FifoSortPolicy, false},
 		{"FairString", "fair", FairSortPolicy, false},
 		{"StatusString", "stateaware", StateAwarePolicy, false},
+		{"PriorityString", "priority", PriorityPolicy, false},
+		{"DRFString", "drf", DRFSortPolicy, false},
+		{"StrictFifoString", "strictfifo", StrictFifoPolicy, false},
 		{"UnknownString", "unknown", Undefined, true},
 	}
 	for _, tt := range tests {
@@ -57,6 +60,9 @@ func TestAppToString(t *testing.T) {
 		{"FifoString", FifoSortPolicy, "fifo"},
 		{"FairString", FairSortPolicy, "fair"},
 		{"StatusString", StateAwarePolicy, "stateaware"},
+		{"PriorityString", PriorityPolicy, "priority"},
+		{"DRFString", DRFSortPolicy, "drf"},
+		{"StrictFifoString", StrictFifoPolicy, "strictfifo"},
 		{"DefaultString", Undefined, "undefined"},
 		{"NoneString", someSP, "fifo"},
 	}
@@ -19,6 +19,7 @@
 package scheduler
 
 import (
+	"github.com/apache/incubator-yunikorn-core/pkg/common/features"
 	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
 	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/objects"
 )
@@ -86,8 +87,8 @@ func getPreemptionPolicies() []PreemptionPolicy {
 
 // Visible by tests
 func (s *Scheduler) SingleStepPreemption() {
-	// Skip if no preemption needed.
-	if !s.clusterContext.NeedPreemption() {
+	// Skip if no preemption needed or preemption is disabled.
+	if !features.Enabled(features.Preemption) || !s.clusterContext.NeedPreemption() {
 		return
 	}
 
@@ -97,6 +98,7 @@ func (s *Scheduler) SingleStepPreemption() {
 	for _, policy := range getPreemptionPolicies() {
 		policy.DoPreemption(s)
 	}
+	s.clusterContext.MarkStateChanged()
 }
 
 // Copy & Reset PreemptionContext
@@ -0,0 +1,156 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"sort"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/rmproxy/rmevent"
+)
+
+// The maximum number of events read from the scheduler queue into the RM queues before one is processed
+const maxBufferedRMEvents = 1000
+
+// The queued events of one RM and the virtual time at which the RM is served next.
+type rmEvents struct {
+	events []interface{}
+	served float64
+}
+
+// Split the scheduler events per RM and process them fairly between the RMs.
+// Each RM is served in turn based on its weight: an RM with a weight of 2 gets two events processed for each
+// event of an RM with a weight of 1 as long as both have events queued. Events of the same RM are never reordered.
+// An event that is not linked to an RM is a barrier: it is returned after all events queued before it.
+// Not locked: must only be used by the routine that processes the scheduler events.
+type rmEventQueue struct {
+	rms     map[string]*rmEvents
+	barrier interface{}
+	size    int
+	vtime   float64
+	weight  func(rmID string) float64
+}
+
+func newRMEventQueue(weight func(rmID string) float64) *rmEventQueue {
+	return &rmEventQueue{
+		rms:    make(map[string]*rmEvents),
+		weight: weight,
+	}
+}
+
+// Return the RM the event belongs to, and false if the event does not belong to an RM.
+func getEventRMID(ev interface{}) (string, bool) {
+	switch v := ev.(type) {
+	case *rmevent.RMUpdateRequestEvent:
+		if v.Request != nil {
+			return v.Request.RmID, true
+		}
+	case *rmevent.RMRegistrationEvent:
+		if v.Registration != nil {
+			return v.Registration.RmID, true
+		}
+	case *rmevent.RMConfigUpdateEvent:
+		return v.RmID, true
+	case *rmevent.RMPartitionsRemoveEvent:
+		return v.RmID, true
+	}
+	return "", false
+}
+
+// Check if events can be added: the queue is not full and there is no barrier waiting.
+func (q *rmEventQueue) accepting() bool {
+	return q.barrier == nil && q.size < maxBufferedRMEvents
+}
+
+func (q *rmEventQueue) isEmpty() bool {
+	return q.size == 0 && q.barrier == nil
+}
+
+// Add the event to the queue of its RM. An RM that had no events queued starts at the current virtual time
+// so it cannot use the time it was idle to starve the other RMs.
+func (q *rmEventQueue) add(ev interface{}) {
+	rmID, ok := getEventRMID(ev)
+	if !ok {
+		q.barrier = ev
+		return
+	}
+	rm, ok := q.rms[rmID]
+	if !ok {
+		rm = &rmEvents{}
+		q.rms[rmID] = rm
+	}
+	if len(rm.events) == 0 && rm.served < q.vtime {
+		rm.served = q.vtime
+	}
+	rm.events = append(rm.events, ev)
+	q.size++
+}
+
+// Return the RM that must be served next: the RM with events queued and the lowest virtual time.
+// Returns an empty string if there is no RM with events queued.
+func (q *rmEventQueue) nextRM() string {
+	ids := make([]string, 0, len(q.rms))
+	for rmID, rm := range q.rms {
+		if len(rm.events) != 0 {
+			ids = append(ids, rmID)
+		}
+	}
+	// sort to get a predictable order for RMs with the same virtual time
+	sort.Strings(ids)
+	next := ""
+	for _, rmID := range ids {
+		if next == "" || q.rms[rmID].served < q.rms[next].served {
+			next = rmID
+		}
+	}
+	return next
+}
+
+// Return the next event to process, nil if the queue is empty.
+// The barrier is only returned after all RM events are processed.
+func (q *rmEventQueue) next() interface{} {
+	rmID := q.nextRM()
+	if rmID == "" {
+		ev := q.barrier
+		q.barrier = nil
+		return ev
+	}
+	rm := q.rms[rmID]
+	ev := rm.events[0]
+	rm.events = rm.events[1:]
+	q.size--
+	q.vtime = rm.served
+	rm.served += 1 / q.weight(rmID)
+	return ev
+}
+
+// Return the next queued event for the RM if it is a node status update, without changing the RM's turn.
+// Returns nil if the next event for the RM is not a node status update or the RM has no events queued.
+func (q *rmEventQueue) nextNodeStatusUpdate(rmID string) *rmevent.RMUpdateRequestEvent {
+	rm, ok := q.rms[rmID]
+	if !ok || len(rm.events) == 0 {
+		return nil
+	}
+	update, ok := rm.events[0].(*rmevent.RMUpdateRequestEvent)
+	if !ok || !rmevent.IsNodeStatusUpdate(update.Request) {
+		return nil
+	}
+	rm.events = rm.events[1:]
+	q.size--
+	return update
+}
@@ -0,0 +1,123 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/rmproxy/rmevent"
+	"github.com/apache/incubator-yunikorn-scheduler-interface/lib/go/si"
+)
+
+func updateEvent(rmID string) *rmevent.RMUpdateRequestEvent {
+	return &rmevent.RMUpdateRequestEvent{Request: &si.UpdateRequest{RmID: rmID}}
+}
+
+func TestRMEventQueueFairness(t *testing.T) {
+	weights := map[string]float64{"rm-a": 1, "rm-b": 2}
+	queue := newRMEventQueue(func(rmID string) float64 { return weights[rmID] })
+	assert.Assert(t, queue.isEmpty(), "new queue should be empty")
+	assert.Assert(t, queue.next() == nil, "empty queue should not return an event")
+
+	// rm-a floods the queue before rm-b sends anything
+	var events []*rmevent.RMUpdateRequestEvent
+	for i := 0; i < 6; i++ {
+		ev := updateEvent("rm-a")
+		events = append(events, ev)
+		queue.add(ev)
+	}
+	for i := 0; i < 4; i++ {
+		queue.add(updateEvent("rm-b"))
+	}
+	// rm-b has twice the weight: two events for rm-b for each event of rm-a, rm-a events stay in order
+	var order []string
+	for !queue.isEmpty() {
+		ev, ok := queue.next().(*rmevent.RMUpdateRequestEvent)
+		assert.Assert(t, ok, "unexpected event type")
+		if ev.Request.RmID == "rm-a" {
+			assert.Equal(t, ev, events[0], "events of an RM should not be reordered")
+			events = events[1:]
+		}
+		order = append(order, ev.Request.RmID)
+	}
+	assert.DeepEqual(t, order, []string{"rm-a", "rm-b", "rm-b", "rm-a", "rm-b", "rm-b", "rm-a", "rm-a", "rm-a", "rm-a"})
+
+	// an RM that was idle does not get credit for the time it was idle
+	for i := 0; i < 2; i++ {
+		queue.add(updateEvent("rm-a"))
+	}
+	queue.next()
+	queue.add(updateEvent("rm-b"))
+	queue.add(updateEvent("rm-b"))
+	rmID, _ := getEventRMID(queue.next())
+	assert.Equal(t, rmID, "rm-b", "idle RM should be served at the current virtual time")
+}
+
+func TestRMEventQueueBarrier(t *testing.T) {
+	queue := newRMEventQueue(func(string) float64 { return 1 })
+	first := updateEvent("rm-a")
+	queue.add(first)
+	marker := &drainEvent{done: make(chan bool)}
+	queue.add(marker)
+	assert.Assert(t, !queue.accepting(), "queue should not accept events after a barrier")
+	assert.Equal(t, queue.next(), first, "barrier should wait for the queued events")
+	assert.Equal(t, queue.next(), marker, "barrier should be returned once the RM events are processed")
+	assert.Assert(t, queue.isEmpty(), "queue should be empty")
+	assert.Assert(t, queue.accepting(), "queue should accept events after the barrier")
+
+	for rmID, ev := range map[string]interface{}{
+		"rm-update":   updateEvent("rm-update"),
+		"rm-register": &rmevent.RMRegistrationEvent{Registration: &si.RegisterResourceManagerRequest{RmID: "rm-register"}},
+		"rm-config":   &rmevent.RMConfigUpdateEvent{RmID: "rm-config"},
+		"rm-remove":   &rmevent.RMPartitionsRemoveEvent{RmID: "rm-remove"},
+	} {
+		id, ok := getEventRMID(ev)
+		assert.Assert(t, ok, "event should belong to an RM")
+		assert.Equal(t, id, rmID, "unexpected RM for event")
+	}
+}
+
+func TestWaitForBacklogBelow(t *testing.T) {
+	sched := NewScheduler()
+	sched.pendingEvents <- updateEvent("rm-a")
+	sched.pendingEvents <- updateEvent("rm-a")
+	// backlog is already below the limit: no wait
+	sched.WaitForBacklogBelow(3)
+
+	done := make(chan struct{})
+	go func() {
+		sched.WaitForBacklogBelow(2)
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatal("wait returned while the backlog was at the limit")
+	case <-time.After(50 * time.Millisecond):
+	}
+	<-sched.pendingEvents
+	sched.backlogReduced()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("wait did not return after the backlog was reduced")
+	}
+}
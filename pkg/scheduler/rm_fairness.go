@@ -0,0 +1,241 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+	"github.com/apache/incubator-yunikorn-core/pkg/log"
+	"github.com/apache/incubator-yunikorn-core/pkg/webservice/dao"
+)
+
+const (
+	// weight of an RM that does not have a weight configured
+	defaultRMWeight = 1.0
+	// maximum number of scheduling attempts for one RM in one cycle, limits the cycle time for large weight ratios
+	maxRMAttempts = 10
+)
+
+// The scheduling accounting for all partitions registered by one RM.
+type rmSchedulingInfo struct {
+	rmID       string
+	weight     float64
+	attempts   int
+	partitions []*PartitionContext
+	capacity   *resources.Resource
+	allocated  *resources.Resource
+	pending    *resources.Resource
+	quota      *resources.Resource
+}
+
+// Check if the RM has reached its resource quota: the allocated resources have reached the quota for at least one
+// of the resource types in the quota. An RM without a quota is never over quota.
+func (rm *rmSchedulingInfo) overQuota() bool {
+	if rm.quota == nil {
+		return false
+	}
+	for name, limit := range rm.quota.Resources {
+		if rm.allocated.Resources[name] >= limit {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse the RM weights from a string with the format: rmID=weight[,rmID=weight]
+// Entries that cannot be parsed or have a weight that is not a positive finite number are logged and skipped.
+func parseRMWeights(value string) map[string]float64 {
+	weights := make(map[string]float64)
+	if value == "" {
+		return weights
+	}
+	for _, entry := range strings.Split(value, ",") {
+		parts := strings.SplitN(strings.TrimSpace(entry), "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			log.Logger().Warn("RM scheduling weight entry skipped: expected rmID=weight",
+				zap.String("entry", entry))
+			continue
+		}
+		weight, err := strconv.ParseFloat(parts[1], 64)
+		if err != nil || weight <= 0 || math.IsNaN(weight) || math.IsInf(weight, 0) {
+			log.Logger().Warn("RM scheduling weight entry skipped: weight must be a positive finite number",
+				zap.String("entry", entry))
+			continue
+		}
+		weights[parts[0]] = weight
+	}
+	return weights
+}
+
+// Parse the RM resource quotas from a string with the format: rmID=name:value[;name:value][,rmID=...]
+// Entries that cannot be parsed or have a negative value are logged and skipped.
+func parseRMQuotas(value string) map[string]*resources.Resource {
+	quotas := make(map[string]*resources.Resource)
+	if value == "" {
+		return quotas
+	}
+	for _, entry := range strings.Split(value, ",") {
+		parts := strings.SplitN(strings.TrimSpace(entry), "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			log.Logger().Warn("RM resource quota entry skipped: expected rmID=name:value[;name:value]",
+				zap.String("entry", entry))
+			continue
+		}
+		quota := resources.NewResource()
+		valid := true
+		for _, res := range strings.Split(parts[1], ";") {
+			pair := strings.SplitN(strings.TrimSpace(res), ":", 2)
+			if len(pair) != 2 || pair[0] == "" {
+				valid = false
+				break
+			}
+			limit, err := resources.ParseQuantity(pair[0], pair[1])
+			if err != nil || limit < 0 {
+				valid = false
+				break
+			}
+			quota.Resources[pair[0]] = limit
+		}
+		if !valid {
+			log.Logger().Warn("RM resource quota entry skipped: resource values must be non negative quantities",
+				zap.String("entry", entry))
+			continue
+		}
+		quotas[parts[0]] = quota
+	}
+	return quotas
+}
+
+// Return the configured resource quota for the RM, nil if the RM does not have a quota.
+func (cc *ClusterContext) getRMQuota(rmID string) *resources.Resource {
+	return cc.rmQuotas[rmID]
+}
+
+// Return the configured scheduling weight for the RM, defaults to 1 when not configured.
+func (cc *ClusterContext) getRMWeight(rmID string) float64 {
+	if weight, ok := cc.rmWeights[rmID]; ok {
+		return weight
+	}
+	return defaultRMWeight
+}
+
+// Collect the pending, allocated and total resources of the partitions per RM.
+// The list is sorted on the rmID, partitions for each RM are sorted on the partition name.
+func (cc *ClusterContext) getRMSchedulingInfo() []*rmSchedulingInfo {
+	rms := make(map[string]*rmSchedulingInfo)
+	for _, psc := range cc.GetPartitionMapClone() {
+		rm, ok := rms[psc.RmID]
+		if !ok {
+			rm = &rmSchedulingInfo{
+				rmID:      psc.RmID,
+				weight:    cc.getRMWeight(psc.RmID),
+				capacity:  resources.NewResource(),
+				allocated: resources.NewResource(),
+				pending:   resources.NewResource(),
+				quota:     cc.getRMQuota(psc.RmID),
+			}
+			rms[psc.RmID] = rm
+		}
+		rm.partitions = append(rm.partitions, psc)
+		rm.capacity.AddTo(psc.GetTotalPartitionResource())
+		rm.allocated.AddTo(psc.GetAllocatedResource())
+		rm.pending.AddTo(psc.root.GetPendingResource())
+	}
+	list := make([]*rmSchedulingInfo, 0, len(rms))
+	for _, rm := range rms {
+		sort.Slice(rm.partitions, func(i, j int) bool {
+			return rm.partitions[i].Name < rm.partitions[j].Name
+		})
+		list = append(list, rm)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].rmID < list[j].rmID
+	})
+	return list
+}
+
+// Return the RMs in the order they should be scheduled in this cycle.
+// The RM with pending resources and the lowest share of its resources in use, after applying the RM weight, is
+// scheduled first. This makes sure that an RM with a large number of asks cannot delay the scheduling for the
+// other RMs.
+// The weight also sets the number of scheduling attempts the partitions of the RM get in the cycle: the weight
+// relative to the lowest weight of the scheduled RMs, rounded and limited to maxRMAttempts.
+// An RM that has reached its resource quota is not scheduled. The quota is checked at the start of the cycle: the
+// allocations made in one cycle can take an RM over its quota.
+func (cc *ClusterContext) getSchedulingOrder() []*rmSchedulingInfo {
+	rms := make([]*rmSchedulingInfo, 0)
+	minWeight := math.MaxFloat64
+	for _, rm := range cc.getRMSchedulingInfo() {
+		if rm.overQuota() {
+			log.Logger().Debug("RM has reached its resource quota, partitions skipped",
+				zap.String("rmID", rm.rmID),
+				zap.String("quota", rm.quota.String()),
+				zap.String("allocated", rm.allocated.String()))
+			continue
+		}
+		rms = append(rms, rm)
+		minWeight = math.Min(minWeight, rm.weight)
+	}
+	for _, rm := range rms {
+		rm.attempts = int(math.Round(rm.weight / minWeight))
+		if rm.attempts > maxRMAttempts {
+			rm.attempts = maxRMAttempts
+		}
+	}
+	sort.SliceStable(rms, func(i, j int) bool {
+		leftPending := resources.StrictlyGreaterThanZero(rms[i].pending)
+		rightPending := resources.StrictlyGreaterThanZero(rms[j].pending)
+		if leftPending != rightPending {
+			return leftPending
+		}
+		return resources.CompWeightedUsageRatioSeparately(rms[i].allocated, rms[i].capacity, rms[i].weight,
+			rms[j].allocated, rms[j].capacity, rms[j].weight) < 0
+	})
+	return rms
+}
+
+// Get the pending and allocated resources per RM to pass to the webservice
+func (cc *ClusterContext) GetRMInfos() []dao.RMDAOInfo {
+	rms := cc.getRMSchedulingInfo()
+	infos := make([]dao.RMDAOInfo, 0, len(rms))
+	for _, rm := range rms {
+		info := dao.RMDAOInfo{
+			RmID:              rm.rmID,
+			Weight:            rm.weight,
+			Capacity:          rm.capacity.DAOString(),
+			PendingResource:   rm.pending.DAOString(),
+			AllocatedResource: rm.allocated.DAOString(),
+			OverQuota:         rm.overQuota(),
+		}
+		if rm.quota != nil {
+			info.Quota = rm.quota.DAOString()
+		}
+		for _, psc := range rm.partitions {
+			info.Partitions = append(info.Partitions, psc.Name)
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
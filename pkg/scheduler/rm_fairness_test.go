@@ -0,0 +1,149 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+)
+
+func TestParseRMWeights(t *testing.T) {
+	weights := parseRMWeights("")
+	assert.Equal(t, len(weights), 0, "empty value should not set weights")
+	weights = parseRMWeights("rm-a=2, rm-b=0.5,rm-c,rm-d=x,rm-e=-1,=3,rm-f=NaN,rm-g=Inf,rm-h=-inf")
+	assert.Equal(t, len(weights), 2, "unexpected number of weights parsed: %v", weights)
+	assert.Equal(t, weights["rm-a"], 2.0, "weight for rm-a not parsed")
+	assert.Equal(t, weights["rm-b"], 0.5, "weight for rm-b not parsed")
+
+	cc := &ClusterContext{rmWeights: weights}
+	assert.Equal(t, cc.getRMWeight("rm-a"), 2.0, "configured weight not returned")
+	assert.Equal(t, cc.getRMWeight("rm-c"), defaultRMWeight, "unconfigured RM should have the default weight")
+}
+
+func TestSchedulingOrder(t *testing.T) {
+	partA := createQueuesNodes(t)
+	partA.RmID = "rm-a"
+	partA.Name = "[rm-a]default"
+	partB := createQueuesNodes(t)
+	partB.RmID = "rm-b"
+	partB.Name = "[rm-b]default"
+	cc := &ClusterContext{
+		partitions: map[string]*PartitionContext{partA.Name: partA, partB.Name: partB},
+		rmWeights:  map[string]float64{},
+	}
+
+	// nothing pending: sorted on the RM ID
+	assertSchedulingOrder(t, cc, []string{"rm-a", "rm-b"})
+	assertSchedulingAttempts(t, cc, map[string]int{"rm-a": 1, "rm-b": 1})
+
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 5})
+	for _, part := range []*PartitionContext{partA, partB} {
+		app := newApplication(appID1, "default", "root.leaf")
+		err := part.AddApplication(app)
+		assert.NilError(t, err, "failed to add app to partition")
+		err = app.AddAllocationAsk(newAllocationAskRepeat("alloc-1", appID1, res, 2))
+		assert.NilError(t, err, "failed to add ask to app")
+	}
+	// both pending with nothing allocated: sorted on the RM ID
+	assertSchedulingOrder(t, cc, []string{"rm-a", "rm-b"})
+
+	// rm-a has allocated resources, rm-b should be scheduled first
+	alloc := partA.tryAllocate()
+	assert.Assert(t, alloc != nil, "expected allocation for rm-a")
+	assertSchedulingOrder(t, cc, []string{"rm-b", "rm-a"})
+
+	// a higher weight for rm-a gives it a larger share of the attention
+	cc.rmWeights["rm-a"] = 4
+	alloc = partB.tryAllocate()
+	assert.Assert(t, alloc != nil, "expected allocation for rm-b")
+	assertSchedulingOrder(t, cc, []string{"rm-a", "rm-b"})
+	assertSchedulingAttempts(t, cc, map[string]int{"rm-a": 4, "rm-b": 1})
+	// the attempts are relative to the lowest weight and limited
+	cc.rmWeights["rm-b"] = 0.5
+	assertSchedulingAttempts(t, cc, map[string]int{"rm-a": 8, "rm-b": 1})
+	cc.rmWeights["rm-b"] = 0.1
+	assertSchedulingAttempts(t, cc, map[string]int{"rm-a": maxRMAttempts, "rm-b": 1})
+	delete(cc.rmWeights, "rm-b")
+
+	// an RM without pending resources goes last
+	alloc = partA.tryAllocate()
+	assert.Assert(t, alloc != nil, "expected allocation for rm-a")
+	assertSchedulingOrder(t, cc, []string{"rm-b", "rm-a"})
+
+	infos := cc.GetRMInfos()
+	assert.Equal(t, len(infos), 2, "expected info for 2 RMs")
+	assert.Equal(t, infos[0].RmID, "rm-a", "RM info not sorted on RM ID")
+	assert.Equal(t, infos[0].Weight, 4.0, "unexpected weight for rm-a")
+	assert.DeepEqual(t, infos[0].Partitions, []string{"[rm-a]default"})
+	assert.Equal(t, infos[0].AllocatedResource, "[first:10]", "unexpected allocated resource for rm-a")
+	assert.Equal(t, infos[0].PendingResource, "[first:0]", "unexpected pending resource for rm-a")
+	assert.Equal(t, infos[1].Weight, defaultRMWeight, "unexpected weight for rm-b")
+	assert.Equal(t, infos[1].PendingResource, "[first:5]", "unexpected pending resource for rm-b")
+	assert.Equal(t, infos[1].Quota, "", "no quota expected for rm-b")
+
+	// rm-a has reached its quota and is not scheduled
+	cc.rmQuotas = map[string]*resources.Resource{
+		"rm-a": resources.NewResourceFromMap(map[string]resources.Quantity{"first": 10}),
+		"rm-b": resources.NewResourceFromMap(map[string]resources.Quantity{"first": 20}),
+	}
+	assertSchedulingOrder(t, cc, []string{"rm-b"})
+	infos = cc.GetRMInfos()
+	assert.Equal(t, infos[0].Quota, "[first:10]", "unexpected quota for rm-a")
+	assert.Assert(t, infos[0].OverQuota, "rm-a should be over quota")
+	assert.Assert(t, !infos[1].OverQuota, "rm-b should not be over quota")
+}
+
+func TestParseRMQuotas(t *testing.T) {
+	quotas := parseRMQuotas("")
+	assert.Equal(t, len(quotas), 0, "empty value should not set quotas")
+	quotas = parseRMQuotas("rm-a=memory:100;vcore:10, rm-b=vcore:5,rm-c,rm-d=vcore,rm-e=vcore:x,rm-f=vcore:-1,=vcore:1")
+	assert.Equal(t, len(quotas), 2, "unexpected number of quotas parsed: %v", quotas)
+	assert.Assert(t, resources.Equals(quotas["rm-a"],
+		resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 100, "vcore": 10})), "quota for rm-a not parsed")
+	assert.Assert(t, resources.Equals(quotas["rm-b"],
+		resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 5})), "quota for rm-b not parsed")
+
+	cc := &ClusterContext{rmQuotas: quotas}
+	assert.Assert(t, cc.getRMQuota("rm-c") == nil, "unconfigured RM should not have a quota")
+	rm := &rmSchedulingInfo{
+		quota:     cc.getRMQuota("rm-a"),
+		allocated: resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 50, "other": 1000}),
+	}
+	assert.Assert(t, !rm.overQuota(), "RM below quota for all types should not be over quota")
+	rm.allocated.Resources["vcore"] = 10
+	assert.Assert(t, rm.overQuota(), "RM at quota for one type should be over quota")
+}
+
+func assertSchedulingOrder(t *testing.T, cc *ClusterContext, expected []string) {
+	rms := cc.getSchedulingOrder()
+	assert.Equal(t, len(rms), len(expected), "unexpected number of RMs")
+	for i, rmID := range expected {
+		assert.Equal(t, rms[i].rmID, rmID, "unexpected RM at position %d", i)
+		assert.Equal(t, rms[i].partitions[0].RmID, rmID, "unexpected partition for RM at position %d", i)
+	}
+}
+
+func assertSchedulingAttempts(t *testing.T, cc *ClusterContext, expected map[string]int) {
+	for _, rm := range cc.getSchedulingOrder() {
+		assert.Equal(t, rm.attempts, expected[rm.rmID], "unexpected scheduling attempts for %s", rm.rmID)
+	}
+}
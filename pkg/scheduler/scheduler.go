@@ -20,10 +20,13 @@ package scheduler
 
 import (
 	"reflect"
+	"sync"
 	"time"
 
 	"go.uber.org/zap"
 
+	"github.com/apache/incubator-yunikorn-core/pkg/checkpoint"
+	"github.com/apache/incubator-yunikorn-core/pkg/common/features"
 	"github.com/apache/incubator-yunikorn-core/pkg/handler"
 	"github.com/apache/incubator-yunikorn-core/pkg/log"
 	"github.com/apache/incubator-yunikorn-core/pkg/plugins"
@@ -31,20 +34,52 @@ import (
 	"github.com/apache/incubator-yunikorn-scheduler-interface/lib/go/si"
 )
 
+// The delay between scheduling cycles that do not change anything, used with the AdaptiveCycles feature gate.
+const (
+	minIdleDelay = time.Millisecond
+	maxIdleDelay = 100 * time.Millisecond
+)
+
 // Main Scheduler service that starts the needed sub services
 type Scheduler struct {
 	clusterContext    *ClusterContext    // main context
 	preemptionContext *preemptionContext // Preemption context
 	pendingEvents     chan interface{}   // queue for events
+	stateStore        checkpoint.Store   // scheduler state store, nil if checkpointing is disabled
+	stateInterval     time.Duration      // interval between two snapshots
+	checkpointer      *checkpointService // stores the snapshots, nil if checkpointing is disabled or not started
+	stopping          int32              // set to 1 when the scheduling routines must stop: must be accessed atomically
+	scheduleDone      chan bool          // closed when the scheduling routine stopped, nil with manual scheduling
+	backlog           *sync.Cond         // broadcast when pending events are taken off the queue
 }
 
 func NewScheduler() *Scheduler {
 	m := &Scheduler{}
 	m.clusterContext = newClusterContext()
 	m.pendingEvents = make(chan interface{}, 1024*1024)
+	m.backlog = sync.NewCond(&sync.Mutex{})
 	return m
 }
 
+// Set the state store before starting the service. The last snapshot is loaded from the store and restored when
+// the RM registers. A snapshot that cannot be loaded is logged and ignored, new snapshots are still stored.
+func (s *Scheduler) SetStateStore(store checkpoint.Store, interval time.Duration) {
+	s.stateStore = store
+	s.stateInterval = interval
+	snapshot, err := store.Load()
+	if err != nil {
+		log.Logger().Warn("failed to load scheduler state snapshot, state not restored",
+			zap.Error(err))
+		return
+	}
+	if snapshot != nil {
+		log.Logger().Info("scheduler state snapshot loaded",
+			zap.Time("created", snapshot.Created),
+			zap.Int("partitions", len(snapshot.Partitions)))
+		s.clusterContext.setRestoreSnapshot(snapshot)
+	}
+}
+
 // Start service
 func (s *Scheduler) StartService(handlers handler.EventHandlers, manualSchedule bool) {
 	// set the proxy handler in the context
@@ -57,7 +92,14 @@ func (s *Scheduler) StartService(handlers handler.EventHandlers, manualSchedule
 	monitor := newNodesResourceUsageMonitor(s.clusterContext)
 	monitor.start()
 
+	// Start the checkpoint service if a state store is set
+	if s.stateStore != nil {
+		s.checkpointer = newCheckpointService(s.clusterContext, s.stateStore, s.stateInterval)
+		s.checkpointer.start()
+	}
+
 	if !manualSchedule {
+		s.scheduleDone = make(chan bool)
 		go s.internalSchedule()
 		go s.internalInspectOutstandingRequests()
 		go s.internalPreemption()
@@ -66,21 +108,41 @@ func (s *Scheduler) StartService(handlers handler.EventHandlers, manualSchedule
 
 // Internal start scheduling service
 func (s *Scheduler) internalSchedule() {
-	for {
-		s.clusterContext.schedule()
+	defer close(s.scheduleDone)
+	var idle time.Duration
+	for !s.isStopping() {
+		// with adaptive cycles the loop backs off while the cycles do not change anything
+		if s.clusterContext.schedule() || !features.Enabled(features.AdaptiveCycles) {
+			idle = 0
+			continue
+		}
+		idle = nextIdleDelay(idle)
+		time.Sleep(idle)
+	}
+}
+
+// Return the delay before the next scheduling cycle after a cycle without changes.
+// The delay doubles after each idle cycle, starting at minIdleDelay up to maxIdleDelay.
+func nextIdleDelay(current time.Duration) time.Duration {
+	if current < minIdleDelay {
+		return minIdleDelay
+	}
+	if next := 2 * current; next < maxIdleDelay {
+		return next
 	}
+	return maxIdleDelay
 }
 
 // Internal start preemption service
 func (s *Scheduler) internalPreemption() {
-	for {
+	for !s.isStopping() {
 		s.SingleStepPreemption()
 		time.Sleep(1000 * time.Millisecond)
 	}
 }
 
 func (s *Scheduler) internalInspectOutstandingRequests() {
-	for {
+	for !s.isStopping() {
 		time.Sleep(1000 * time.Millisecond)
 		s.inspectOutstandingRequests()
 	}
@@ -91,6 +153,23 @@ func (s *Scheduler) HandleEvent(ev interface{}) {
 	enqueueAndCheckFull(s.pendingEvents, ev)
 }
 
+// Block until less than the limit of events are waiting to be processed.
+func (s *Scheduler) WaitForBacklogBelow(limit int) {
+	s.backlog.L.Lock()
+	defer s.backlog.L.Unlock()
+	for len(s.pendingEvents) >= limit {
+		s.backlog.Wait()
+	}
+}
+
+// Wake up the callers waiting for the backlog after events were taken off the queue.
+// The lock is taken to make sure a caller that just checked the backlog is waiting.
+func (s *Scheduler) backlogReduced() {
+	s.backlog.L.Lock()
+	s.backlog.Broadcast()
+	s.backlog.L.Unlock()
+}
+
 func enqueueAndCheckFull(queue chan interface{}, ev interface{}) {
 	select {
 	case queue <- ev:
@@ -104,25 +183,58 @@ func enqueueAndCheckFull(queue chan interface{}, ev interface{}) {
 	}
 }
 
+// Process the RM events. The events are split per RM and processed fairly between the RMs based on the RM weight,
+// a shim that sends a large number of updates cannot delay the processing of the updates of the other RMs.
 func (s *Scheduler) handleRMEvent() {
+	queue := newRMEventQueue(s.clusterContext.getRMWeight)
 	for {
-		ev := <-s.pendingEvents
-		switch v := ev.(type) {
-		case *rmevent.RMUpdateRequestEvent:
-			s.clusterContext.processRMUpdateEvent(v)
-		case *rmevent.RMPartitionsRemoveEvent:
-			s.clusterContext.removePartitionsByRMID(v)
-		case *rmevent.RMRegistrationEvent:
-			s.clusterContext.processRMRegistrationEvent(v)
-		case *rmevent.RMConfigUpdateEvent:
-			s.clusterContext.processRMConfigUpdateEvent(v)
+		if queue.isEmpty() {
+			queue.add(<-s.pendingEvents)
+		}
+		s.fillEventQueue(queue)
+		s.backlogReduced()
+		ev := queue.next()
+		// node status updates from chatty shims are merged to reduce the lock churn
+		if update, ok := ev.(*rmevent.RMUpdateRequestEvent); ok && rmevent.IsNodeStatusUpdate(update.Request) {
+			ev = s.mergeNodeUpdates(update, queue)
+		}
+		s.processRMEvent(ev)
+	}
+}
+
+// Move the pending events into the RM event queue without blocking until the queue stops accepting events.
+func (s *Scheduler) fillEventQueue(queue *rmEventQueue) {
+	for queue.accepting() {
+		select {
+		case ev := <-s.pendingEvents:
+			queue.add(ev)
 		default:
-			log.Logger().Error("Received type is not an acceptable type for RM event.",
-				zap.String("received type", reflect.TypeOf(v).String()))
+			return
 		}
 	}
 }
 
+func (s *Scheduler) processRMEvent(ev interface{}) {
+	switch v := ev.(type) {
+	case *rmevent.RMUpdateRequestEvent:
+		s.clusterContext.processRMUpdateEvent(v)
+	case *rmevent.RMPartitionsRemoveEvent:
+		s.clusterContext.removePartitionsByRMID(v)
+	case *rmevent.RMRegistrationEvent:
+		s.clusterContext.processRMRegistrationEvent(v)
+	case *rmevent.RMConfigUpdateEvent:
+		s.clusterContext.processRMConfigUpdateEvent(v)
+	case *drainEvent:
+		close(v.done)
+		return
+	default:
+		log.Logger().Error("Received type is not an acceptable type for RM event.",
+			zap.String("received type", reflect.TypeOf(v).String()))
+		return
+	}
+	s.clusterContext.MarkStateChanged()
+}
+
 // inspect on the outstanding requests for each of the queues,
 // update request state accordingly to shim if needed.
 // this function filters out all outstanding requests that being
@@ -159,6 +271,11 @@ func (s *Scheduler) GetClusterContext() *ClusterContext {
 	return s.clusterContext
 }
 
+// Run one scheduling cycle, used by resource managers that embed the core with manual scheduling.
+func (s *Scheduler) RunSchedulingCycle() {
+	s.clusterContext.schedule()
+}
+
 // The scheduler for testing which runs nAlloc times the normal schedule routine.
 // Visible by tests
 func (s *Scheduler) MultiStepSchedule(nAlloc int) {
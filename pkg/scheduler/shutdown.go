@@ -0,0 +1,98 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// Shutdown phases reported in the status while the scheduler shuts down, in the order they are executed
+const (
+	ShutdownRejectingUpdates   = "RejectingUpdates"
+	ShutdownDrainingEvents     = "DrainingEvents"
+	ShutdownStoppingScheduling = "StoppingScheduling"
+	ShutdownFlushingEvents     = "FlushingEvents"
+	ShutdownFlushingMetrics    = "FlushingMetrics"
+	ShutdownStoringState       = "StoringState"
+	ShutdownCompleted          = "Completed"
+)
+
+// Marker event passed through the RM event queue: all events queued before the marker have been processed when
+// the done channel is closed.
+type drainEvent struct {
+	done chan bool
+}
+
+// Set the shutdown phase reported in the status.
+func (cc *ClusterContext) SetShutdownPhase(phase string) {
+	cc.shutdownPhase.Store(phase)
+}
+
+// Get the shutdown phase, returns an empty string if the scheduler is not shutting down.
+func (cc *ClusterContext) getShutdownPhase() string {
+	if phase, ok := cc.shutdownPhase.Load().(string); ok {
+		return phase
+	}
+	return ""
+}
+
+// Wait until all RM events queued before the call have been processed.
+// New events must be blocked before this call otherwise the wait is not final.
+func (s *Scheduler) DrainEvents(timeout time.Duration) error {
+	marker := &drainEvent{done: make(chan bool)}
+	s.HandleEvent(marker)
+	select {
+	case <-marker.done:
+		return nil
+	case <-time.After(timeout):
+		return fmt.Errorf("RM events not drained within %s, %d events left", timeout, len(s.pendingEvents))
+	}
+}
+
+// Stop the scheduling routines. The running scheduling cycle is finished, and the allocations proposed in the
+// cycle are processed, before the routine stops. No new cycle is started.
+func (s *Scheduler) StopScheduling(timeout time.Duration) error {
+	atomic.StoreInt32(&s.stopping, 1)
+	if s.scheduleDone == nil {
+		return nil
+	}
+	select {
+	case <-s.scheduleDone:
+		return nil
+	case <-time.After(timeout):
+		return fmt.Errorf("scheduling cycle did not finish within %s", timeout)
+	}
+}
+
+func (s *Scheduler) isStopping() bool {
+	return atomic.LoadInt32(&s.stopping) == 1
+}
+
+// Stop the checkpoint service after storing a final snapshot of the scheduler state.
+// Scheduling must be stopped before the call for the snapshot to be final.
+func (s *Scheduler) StopCheckpointing(timeout time.Duration) error {
+	if s.checkpointer == nil {
+		return nil
+	}
+	checkpointer := s.checkpointer
+	s.checkpointer = nil
+	return checkpointer.stop(timeout)
+}
@@ -0,0 +1,66 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/handler"
+)
+
+func TestShutdownScheduler(t *testing.T) {
+	sched := NewScheduler()
+	sched.StartService(handler.EventHandlers{}, false)
+
+	err := sched.DrainEvents(time.Second)
+	assert.NilError(t, err, "empty event queue should drain")
+	err = sched.StopScheduling(time.Second)
+	assert.NilError(t, err, "scheduling routine should have stopped")
+	assert.Assert(t, sched.isStopping(), "scheduler should be marked as stopping")
+	cycle := sched.clusterContext.cycle
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, sched.clusterContext.cycle, cycle, "no scheduling cycle should run after the stop")
+
+	// manual scheduling has no routine to wait for
+	sched = NewScheduler()
+	sched.StartService(handler.EventHandlers{}, true)
+	err = sched.StopScheduling(time.Second)
+	assert.NilError(t, err, "manual scheduling should stop immediately")
+}
+
+func TestShutdownStatus(t *testing.T) {
+	cc := &ClusterContext{
+		startTime: time.Now(),
+	}
+	cc.setCycleStatus(true)
+	status := cc.GetStatus()
+	assert.Equal(t, status.State, SchedulingActive, "unexpected state before the shutdown")
+	assert.Equal(t, status.ShutdownPhase, "", "no shutdown phase expected")
+
+	cc.SetShutdownPhase(ShutdownDrainingEvents)
+	status = cc.GetStatus()
+	assert.Equal(t, status.State, SchedulingShutdown, "unexpected state during the shutdown")
+	assert.Equal(t, status.ShutdownPhase, ShutdownDrainingEvents, "unexpected shutdown phase")
+
+	cc.SetShutdownPhase(ShutdownCompleted)
+	assert.Equal(t, cc.GetStatus().State, SchedulingStopped, "unexpected state after the shutdown")
+}
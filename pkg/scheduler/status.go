@@ -0,0 +1,133 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/plugins"
+	"github.com/apache/incubator-yunikorn-core/pkg/readiness"
+	"github.com/apache/incubator-yunikorn-core/pkg/webservice/dao"
+)
+
+// Scheduling states reported in the status
+const (
+	SchedulingStarting = "Starting"
+	SchedulingActive   = "Active"
+	SchedulingPaused   = "Paused"
+	SchedulingShutdown = "ShuttingDown"
+	SchedulingStopped  = "Stopped"
+)
+
+// Record the end of a scheduling cycle, active is false if all partitions were stopped.
+func (cc *ClusterContext) setCycleStatus(active bool) {
+	var paused int32
+	if !active {
+		paused = 1
+	}
+	atomic.StoreInt32(&cc.schedulingPaused, paused)
+	atomic.StoreInt64(&cc.lastCycle, time.Now().UnixNano())
+}
+
+// Update the number of registered RMs: the number of distinct RMs of the partitions.
+// NOTE: this is a lock free call. It must only be called holding the context lock.
+func (cc *ClusterContext) updateRMStatus() {
+	rms := make(map[string]bool)
+	for _, psc := range cc.partitions {
+		rms[psc.RmID] = true
+	}
+	atomic.StoreInt32(&cc.registeredRMs, int32(len(rms)))
+}
+
+// Get the status of the scheduler to pass to the webservice.
+// This call does not lock any scheduling object and is cheap enough for high frequency probes.
+func (cc *ClusterContext) GetStatus() *dao.StatusDAOInfo {
+	now := time.Now()
+	status := &dao.StatusDAOInfo{
+		StartTime:     cc.startTime.UnixNano(),
+		Uptime:        now.Sub(cc.startTime).Round(time.Second).String(),
+		RegisteredRMs: int(atomic.LoadInt32(&cc.registeredRMs)),
+		State:         SchedulingStarting,
+		LastCycleTime: atomic.LoadInt64(&cc.lastCycle),
+	}
+	if status.LastCycleTime != 0 {
+		status.State = SchedulingActive
+		if atomic.LoadInt32(&cc.schedulingPaused) == 1 {
+			status.State = SchedulingPaused
+		}
+	}
+	if phase := cc.getShutdownPhase(); phase != "" {
+		status.State = SchedulingShutdown
+		if phase == ShutdownCompleted {
+			status.State = SchedulingStopped
+		}
+		status.ShutdownPhase = phase
+	}
+	if checksum, ok := cc.configChecksum.Load().(string); ok {
+		status.ConfigChecksum = checksum
+	}
+	return status
+}
+
+// Readiness check for the scheduling service: ready until the scheduler is shut down.
+func (cc *ClusterContext) GetSchedulerReadiness() (string, string) {
+	state := cc.GetStatus().State
+	if state == SchedulingShutdown || state == SchedulingStopped {
+		return readiness.StatusNotReady, "scheduler state: " + state
+	}
+	return readiness.StatusReady, "scheduler state: " + state
+}
+
+// Readiness check for the configuration: the configuration is loaded and validated when the first RM registers.
+func (cc *ClusterContext) GetConfigReadiness() (string, string) {
+	partitions := cc.GetPartitionMapClone()
+	if len(partitions) == 0 {
+		return readiness.StatusNotReady, "no configuration loaded: waiting for a resource manager to register"
+	}
+	message := fmt.Sprintf("%d partition(s) loaded", len(partitions))
+	if checksum, ok := cc.configChecksum.Load().(string); ok && checksum != "" {
+		message += ", checksum " + checksum
+	}
+	return readiness.StatusReady, message
+}
+
+// Readiness check for the plugins: the plugins the loaded configuration depends on must be registered.
+func (cc *ClusterContext) GetPluginReadiness() (string, string) {
+	registered := plugins.GetRegisteredPlugins()
+	var missing []string
+	if plugins.GetApplicationUsagePlugin() == nil {
+		for name, partition := range cc.GetPartitionMapClone() {
+			if partition.getIdleApplications().Timeout > 0 {
+				missing = append(missing, "ApplicationUsagePlugin required by idle application detection in partition "+name)
+			}
+		}
+	}
+	if len(missing) != 0 {
+		sort.Strings(missing)
+		return readiness.StatusNotReady, "missing plugins: " + strings.Join(missing, "; ")
+	}
+	if len(registered) == 0 {
+		return readiness.StatusReady, "no plugins registered"
+	}
+	return readiness.StatusReady, "registered: " + strings.Join(registered, ", ")
+}
@@ -0,0 +1,95 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/configs"
+	"github.com/apache/incubator-yunikorn-core/pkg/plugins"
+	"github.com/apache/incubator-yunikorn-core/pkg/readiness"
+)
+
+func TestGetStatus(t *testing.T) {
+	partA := createQueuesNodes(t)
+	partA.RmID = "rm-a"
+	partA.Name = "[rm-a]default"
+	partB := createQueuesNodes(t)
+	partB.RmID = "rm-a"
+	partB.Name = "[rm-a]gpu"
+	cc := &ClusterContext{
+		partitions: map[string]*PartitionContext{partA.Name: partA, partB.Name: partB},
+		startTime:  time.Now().Add(-time.Minute),
+	}
+
+	status := cc.GetStatus()
+	assert.Equal(t, status.State, SchedulingStarting, "no cycle should be reported as starting")
+	assert.Equal(t, status.LastCycleTime, int64(0), "no cycle should have been recorded")
+	assert.Equal(t, status.Uptime, "1m0s", "unexpected uptime")
+	assert.Equal(t, status.RegisteredRMs, 0, "RMs should not be counted before an update")
+	assert.Equal(t, status.ConfigChecksum, "", "no config should have been applied")
+
+	cc.updateRMStatus()
+	cc.configChecksum.Store("ABC")
+	cc.setCycleStatus(true)
+	status = cc.GetStatus()
+	assert.Equal(t, status.State, SchedulingActive, "unexpected state after an active cycle")
+	assert.Assert(t, status.LastCycleTime != 0, "cycle time should have been recorded")
+	assert.Equal(t, status.RegisteredRMs, 1, "partitions of one RM should count once")
+	assert.Equal(t, status.ConfigChecksum, "ABC", "unexpected config checksum")
+
+	cc.setCycleStatus(false)
+	assert.Equal(t, cc.GetStatus().State, SchedulingPaused, "unexpected state after a cycle without partitions")
+}
+
+func TestReadinessChecks(t *testing.T) {
+	cc := &ClusterContext{
+		partitions: map[string]*PartitionContext{},
+		startTime:  time.Now(),
+	}
+	status, message := cc.GetSchedulerReadiness()
+	assert.Equal(t, status, readiness.StatusReady, "starting scheduler should be ready: %s", message)
+	status, message = cc.GetConfigReadiness()
+	assert.Equal(t, status, readiness.StatusNotReady, "no configuration should not be ready")
+	assert.Assert(t, strings.Contains(message, "waiting for a resource manager"), "unexpected message: %s", message)
+
+	partition := createQueuesNodes(t)
+	cc.partitions[partition.Name] = partition
+	cc.configChecksum.Store("ABC")
+	status, message = cc.GetConfigReadiness()
+	assert.Equal(t, status, readiness.StatusReady, "loaded configuration should be ready")
+	assert.Equal(t, message, "1 partition(s) loaded, checksum ABC", "unexpected message")
+	status, message = cc.GetPluginReadiness()
+	assert.Equal(t, status, readiness.StatusReady, "plugins should be ready: %s", message)
+
+	// idle application detection needs the usage plugin
+	partition.idleApplications = configs.IdleApplicationsConfig{Timeout: time.Minute}
+	status, message = cc.GetPluginReadiness()
+	assert.Equal(t, status, readiness.StatusNotReady, "missing usage plugin should not be ready")
+	assert.Assert(t, strings.Contains(message, "ApplicationUsagePlugin"), "unexpected message: %s", message)
+	plugins.RegisterSchedulerPlugin(&fakeAppUsagePlugin{})
+	defer plugins.UnregisterApplicationUsagePlugin()
+	status, message = cc.GetPluginReadiness()
+	assert.Equal(t, status, readiness.StatusReady, "plugins should be ready: %s", message)
+	assert.Assert(t, strings.Contains(message, "registered: ") && strings.Contains(message, "ApplicationUsagePlugin"), "unexpected message: %s", message)
+}
@@ -0,0 +1,111 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common"
+	"github.com/apache/incubator-yunikorn-core/pkg/common/configs"
+	"github.com/apache/incubator-yunikorn-core/pkg/entrypoint"
+	"github.com/apache/incubator-yunikorn-scheduler-interface/lib/go/si"
+)
+
+func TestEmbeddedCore(t *testing.T) {
+	configData := `
+partitions:
+  - name: default
+    queues:
+      - name: root
+        submitacl: "*"
+        queues:
+          - name: a
+`
+	core := entrypoint.StartEmbedded(entrypoint.EmbeddedOptions{ManualSchedule: true})
+	defer core.Stop()
+	configs.MockSchedulerConfigByData([]byte(configData))
+	mockRM := newMockRMCallbackHandler()
+	_, err := core.RegisterResourceManager(&si.RegisterResourceManagerRequest{
+		RmID:        "rm:embedded",
+		PolicyGroup: "policygroup",
+		Version:     "0.0.2",
+	}, mockRM)
+	assert.NilError(t, err, "RegisterResourceManager failed")
+
+	partitions, err := core.GetPartitions("rm:embedded")
+	assert.NilError(t, err, "failed to get partitions")
+	assert.DeepEqual(t, partitions, []string{"default"})
+	queues, err := core.GetQueues("rm:embedded", "default")
+	assert.NilError(t, err, "failed to get queues")
+	assert.Equal(t, queues.QueueName, "root", "unexpected root queue")
+	assert.Equal(t, len(queues.Children), 1, "expected one child queue")
+	_, err = core.GetQueues("rm:embedded", "unknown")
+	assert.ErrorContains(t, err, "partition unknown not found")
+
+	// add a node and an application, then schedule an ask
+	err = core.Update(&si.UpdateRequest{
+		NewSchedulableNodes: []*si.NewNodeInfo{{
+			NodeID:              "node-1:1234",
+			Attributes:          map[string]string{},
+			SchedulableResource: &si.Resource{Resources: map[string]*si.Quantity{"memory": {Value: 100}}},
+		}},
+		NewApplications: newAddAppRequest(map[string]string{appID1: "root.a"}),
+		RmID:            "rm:embedded",
+	})
+	assert.NilError(t, err, "UpdateRequest failed")
+	mockRM.waitForAcceptedNode(t, "node-1:1234", 1000)
+	mockRM.waitForAcceptedApplication(t, appID1, 1000)
+	err = core.Update(&si.UpdateRequest{
+		Asks: []*si.AllocationAsk{{
+			AllocationKey:  "alloc-1",
+			ResourceAsk:    &si.Resource{Resources: map[string]*si.Quantity{"memory": {Value: 10}}},
+			MaxAllocations: 1,
+			ApplicationID:  appID1,
+		}},
+		RmID: "rm:embedded",
+	})
+	assert.NilError(t, err, "UpdateRequest failed")
+	// the ask is processed asynchronously: keep scheduling until it is allocated
+	err = common.WaitFor(10*time.Millisecond, time.Second, func() bool {
+		assert.NilError(t, core.Schedule(1), "manual scheduling failed")
+		return len(mockRM.getAllocations()) == 1
+	})
+	assert.NilError(t, err, "ask was not allocated")
+
+	snapshot, err := core.GetStateSnapshot()
+	assert.NilError(t, err, "failed to get the state snapshot")
+	partition := snapshot.GetPartition("[rm:embedded]default")
+	assert.Assert(t, partition != nil, "partition missing from snapshot")
+	assert.Equal(t, len(partition.Applications), 1, "expected one application in the snapshot")
+	assert.Equal(t, len(partition.Applications[0].Allocations), 1, "expected one allocation in the snapshot")
+	status, err := core.GetStatus()
+	assert.NilError(t, err, "failed to get the status")
+	assert.Equal(t, status.RegisteredRMs, 1, "expected one registered RM")
+
+	// all calls fail after the core is stopped, stopping again is a no-op
+	core.Stop()
+	core.Stop()
+	err = core.Update(&si.UpdateRequest{RmID: "rm:embedded"})
+	assert.ErrorContains(t, err, "embedded scheduler core is stopped")
+	_, err = core.GetStatus()
+	assert.ErrorContains(t, err, "embedded scheduler core is stopped")
+}
@@ -22,7 +22,10 @@ import (
 	"fmt"
 
 	"github.com/opentracing/opentracing-go"
+	"go.uber.org/zap"
 
+	"github.com/apache/incubator-yunikorn-core/pkg/log"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/objects"
 	"github.com/apache/incubator-yunikorn-core/pkg/trace"
 )
 
@@ -89,3 +92,29 @@ func finishActiveSpanWrapper(ctx trace.SchedulerTraceContext, state, info string
 	}
 	return err
 }
+
+// startTrace starts a span for a scheduling phase, tracing failures are logged and never stop scheduling.
+func startTrace(ctx trace.SchedulerTraceContext, level, phase, name string) {
+	if _, err := startSpanWrapper(ctx, level, phase, name); err != nil {
+		log.Logger().Debug("failed to start scheduling span",
+			zap.String("level", level),
+			zap.String("phase", phase),
+			zap.Error(err))
+	}
+}
+
+// finishTrace finishes the active span with the state, tracing failures are logged and never stop scheduling.
+func finishTrace(ctx trace.SchedulerTraceContext, state string) {
+	if err := finishActiveSpanWrapper(ctx, state, ""); err != nil {
+		log.Logger().Debug("failed to finish scheduling span",
+			zap.Error(err))
+	}
+}
+
+// allocationState returns the state tag for the result of an allocation attempt.
+func allocationState(alloc *objects.Allocation) string {
+	if alloc == nil {
+		return "skip"
+	}
+	return alloc.Result.String()
+}
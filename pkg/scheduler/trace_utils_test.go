@@ -24,6 +24,8 @@ import (
 	"github.com/opentracing/opentracing-go"
 	"gotest.tools/assert"
 
+	"github.com/apache/incubator-yunikorn-core/pkg/common/configs"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/objects"
 	"github.com/apache/incubator-yunikorn-core/pkg/trace"
 )
 
@@ -123,3 +125,35 @@ func Test_finishActiveSpanWrapper(t *testing.T) {
 		})
 	}
 }
+
+func TestAllocationState(t *testing.T) {
+	assert.Equal(t, allocationState(nil), "skip", "nil allocation should be skipped")
+	alloc := &objects.Allocation{Result: objects.Allocated}
+	assert.Equal(t, allocationState(alloc), "Allocated", "unexpected allocation state")
+}
+
+func TestScheduleTracing(t *testing.T) {
+	partition := createQueuesNodes(t)
+	if partition == nil {
+		t.Fatal("partition create failed")
+	}
+	cc := &ClusterContext{partitions: map[string]*PartitionContext{partition.Name: partition}}
+	assert.Assert(t, cc.newTraceContext() == nil, "tracing should be disabled by default")
+
+	cc.updateTracer(configs.TracingConfig{Enabled: true, Mode: trace.Debug})
+	assert.Assert(t, cc.tracer != nil, "tracer should have been created")
+	ctx := cc.newTraceContext()
+	assert.Assert(t, ctx != nil, "tracing context should have been created")
+
+	// all spans of a scheduling attempt must be finished
+	cc.schedulePartition(ctx, partition)
+	_, err := ctx.ActiveSpan()
+	assert.ErrorContains(t, err, "active span is not found")
+
+	// the same config does not replace the tracer
+	tracer := cc.tracer
+	cc.updateTracer(configs.TracingConfig{Enabled: true, Mode: trace.Debug})
+	assert.Equal(t, cc.tracer, tracer, "tracer should not have been replaced")
+	cc.updateTracer(configs.TracingConfig{})
+	assert.Assert(t, cc.tracer == nil, "tracer should have been removed")
+}
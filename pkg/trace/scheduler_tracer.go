@@ -43,8 +43,9 @@ type SchedulerTracerImpl struct {
 }
 
 type SchedulerTracerImplParams struct {
-	Mode       string
-	FilterTags map[string]interface{}
+	Mode              string
+	FilterTags        map[string]interface{}
+	CollectorEndpoint string
 }
 
 const (
@@ -114,7 +115,7 @@ func NewSchedulerTracer(params *SchedulerTracerImplParams) (SchedulerTracer, err
 		params = DefaultSchedulerTracerImplParams
 	}
 
-	tracer, closer, err := NewTracerFromEnv("yunikorn-core-scheduler")
+	tracer, closer, err := NewTracerWithCollector("yunikorn-core-scheduler", params.CollectorEndpoint)
 	if err != nil {
 		return nil, err
 	}
@@ -61,6 +61,12 @@ func NewConstTracer(serviceName string, collect bool) (opentracing.Tracer, io.Cl
 
 // NewTracerFromEnv returns an instance of Jaeger Tracer that get sampling strategy from env settings.
 func NewTracerFromEnv(serviceName string) (opentracing.Tracer, io.Closer, error) {
+	return NewTracerWithCollector(serviceName, "")
+}
+
+// NewTracerWithCollector returns an instance of Jaeger Tracer configured from env settings that reports
+// the spans to the collector endpoint. The endpoint from the env settings is used if the endpoint is empty.
+func NewTracerWithCollector(serviceName, endpoint string) (opentracing.Tracer, io.Closer, error) {
 	cfg, err := jaegercfg.FromEnv()
 	if err != nil {
 		return nil, nil, err
@@ -68,6 +74,9 @@ func NewTracerFromEnv(serviceName string) (opentracing.Tracer, io.Closer, error)
 	if serviceName != "" {
 		cfg.ServiceName = serviceName
 	}
+	if endpoint != "" {
+		cfg.Reporter.CollectorEndpoint = endpoint
+	}
 	// Example logger and metrics factory. Use github.com/uber/jaeger-client-go/log
 	// and github.com/uber/jaeger-lib/metrics respectively to bind to real logging and metrics
 	// frameworks.
@@ -0,0 +1,162 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package webservice
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/configs"
+	"github.com/apache/incubator-yunikorn-core/pkg/common/security"
+	"github.com/apache/incubator-yunikorn-core/pkg/log"
+	"github.com/apache/incubator-yunikorn-core/pkg/plugins"
+)
+
+const bearerPrefix = "Bearer "
+
+// Endpoints that do not use the GET method but do not change the scheduler state: no authentication needed.
+var readOnlyRoutes = map[string]bool{
+	http.MethodPost + " /ws/v1/config":        true,
+	http.MethodPost + " /ws/v1/validate-conf": true,
+}
+
+// GET endpoints that expose the full scheduler state at once: only authenticated admins are allowed.
+var adminOnlyRoutes = map[string]bool{
+	http.MethodGet + " " + replicaSnapshotPath: true,
+}
+
+// Only allow authenticated admins to call an endpoint that changes the scheduler state or exposes the full state.
+// The other GET endpoints and the endpoints that do not change the state are not authenticated.
+func authHandler(inner http.Handler, method, pattern string) http.Handler {
+	route := method + " " + pattern
+	if (method == http.MethodGet || readOnlyRoutes[route]) && !adminOnlyRoutes[route] {
+		return inner
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if status, err := authorize(r); err != nil {
+			log.Logger().Info("REST API request rejected",
+				zap.String("method", r.Method),
+				zap.String("request", r.RequestURI),
+				zap.Int("status", status),
+				zap.Error(err))
+			writeHeaders(w)
+			if status == http.StatusUnauthorized {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+			}
+			buildJSONErrorResponse(w, err.Error(), status)
+			return
+		}
+		inner.ServeHTTP(w, r)
+	})
+}
+
+// Check if the caller is allowed to change the scheduler state based on the authentication configuration.
+// Returns the HTTP status and an error if the caller is not authenticated or not an admin.
+func authorize(r *http.Request) (int, error) {
+	if schedulerContext == nil {
+		return http.StatusOK, nil
+	}
+	conf := configs.ConfigContext.Get(schedulerContext.GetPolicyGroup())
+	if conf == nil || !conf.Authentication.Enabled {
+		return http.StatusOK, nil
+	}
+	caller, err := authenticate(r, conf.Authentication.Tokens)
+	if err != nil {
+		return http.StatusUnauthorized, err
+	}
+	// the config is validated: the ACL should never fail
+	acl, err := security.NewACL(conf.Authentication.AdminACL)
+	if err != nil {
+		log.Logger().Warn("authentication admin ACL parsing failed, rejecting all changes",
+			zap.Error(err))
+	}
+	if !acl.CheckAccess(caller) {
+		return http.StatusForbidden, fmt.Errorf("user %s is not allowed to change the scheduler state", caller.User)
+	}
+	return http.StatusOK, nil
+}
+
+// Check if the caller is an admin of the REST API. The caller must be authenticated and allowed by the admin ACL of
+// the authentication or the redaction configuration. A caller that cannot be identified is never an admin.
+func isAdmin(r *http.Request, conf *configs.SchedulerConfig) bool {
+	caller, err := authenticate(r, conf.Authentication.Tokens)
+	if err != nil {
+		return false
+	}
+	if acl, err := security.NewACL(conf.Authentication.AdminACL); err == nil && acl.CheckAccess(caller) {
+		return true
+	}
+	if !conf.Redaction.Enabled {
+		return false
+	}
+	acl, _ := redactionRules.get(conf)
+	return acl.CheckAccess(caller)
+}
+
+// Return the configuration as it can be shown to the caller. The tokens and the admin ACLs that control the access
+// to the REST API and the inline TLS key are removed for a caller that is not an admin, whether authentication or
+// redaction are enabled or not. The returned copy shares all other fields with the configuration.
+func getConfigForCaller(r *http.Request, conf *configs.SchedulerConfig) *configs.SchedulerConfig {
+	if conf == nil || isAdmin(r, conf) {
+		return conf
+	}
+	stripped := *conf
+	if stripped.Redaction.AdminACL != "" {
+		stripped.Redaction.AdminACL = redactedValue
+	}
+	if stripped.Authentication.AdminACL != "" {
+		stripped.Authentication.AdminACL = redactedValue
+	}
+	stripped.Authentication.Tokens = nil
+	if stripped.WebService.TLS.Key != "" {
+		stripped.WebService.TLS.Key = redactedValue
+	}
+	return &stripped
+}
+
+// Identify the caller using the bearer token from the Authorization header.
+// The token is validated by the authentication plugin if registered, otherwise against the static tokens.
+func authenticate(r *http.Request, tokens []configs.TokenConfig) (security.UserGroup, error) {
+	header := r.Header.Get("Authorization")
+	if len(header) <= len(bearerPrefix) || !strings.EqualFold(header[:len(bearerPrefix)], bearerPrefix) {
+		return security.UserGroup{}, fmt.Errorf("bearer token missing from the request")
+	}
+	token := strings.TrimSpace(header[len(bearerPrefix):])
+	if plugin := plugins.GetAuthenticationPlugin(); plugin != nil {
+		user, groups, err := plugin.Authenticate(token)
+		if err != nil {
+			return security.UserGroup{}, fmt.Errorf("bearer token rejected: %v", err)
+		}
+		return security.UserGroup{User: user, Groups: groups}, nil
+	}
+	sum := sha256.Sum256([]byte(token))
+	hash := []byte(hex.EncodeToString(sum[:]))
+	for _, static := range tokens {
+		if subtle.ConstantTimeCompare([]byte(strings.ToLower(static.SHA256)), hash) == 1 {
+			return security.UserGroup{User: static.User, Groups: static.Groups}, nil
+		}
+	}
+	return security.UserGroup{}, fmt.Errorf("bearer token rejected: unknown token")
+}
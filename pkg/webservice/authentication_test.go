@@ -0,0 +1,186 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package webservice
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/configs"
+	"github.com/apache/incubator-yunikorn-core/pkg/plugins"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler"
+)
+
+const configAuthentication = `
+authentication:
+  enabled: true
+  adminacl: "admin admins"
+  tokens:
+    - sha256: 10a4c7c9fc5206d6f36dc6944a81bb6f4a3cb0e25014ae3b12e6c3e52712292a
+      user: admin
+    - sha256: 92458BFFC9B190FEEA4BFD93611060A8E768FF3A5DB84B4C387682E29A70436F
+      user: tenant
+      groups:
+        - users
+partitions:
+  - name: default
+    queues:
+      - name: root
+        submitacl: "*"
+`
+
+type fakeAuthenticationPlugin struct{}
+
+func (f *fakeAuthenticationPlugin) Authenticate(token string) (string, []string, error) {
+	if token == "plugin-token" {
+		return "tenant", []string{"admins"}, nil
+	}
+	return "", nil, fmt.Errorf("unknown token")
+}
+
+func newAuthRequest(method, token string) *http.Request {
+	req, _ := http.NewRequest(method, "/ws/v1/partition/default/queues", nil)
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+	return req
+}
+
+func TestAuthorize(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(configDefault))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+	var status int
+	status, err = authorize(newAuthRequest("POST", ""))
+	assert.NilError(t, err, "authentication not configured should allow all callers")
+	assert.Equal(t, status, http.StatusOK)
+
+	configs.MockSchedulerConfigByData([]byte(configAuthentication))
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+	tests := map[string]int{
+		"":                       http.StatusUnauthorized,
+		"Basic YWRtaW46YWRtaW4=": http.StatusUnauthorized,
+		"Bearer ":                http.StatusUnauthorized,
+		"Bearer unknown":         http.StatusUnauthorized,
+		"Bearer user-token":      http.StatusForbidden,
+		"Bearer admin-token":     http.StatusOK,
+		"bearer admin-token":     http.StatusOK,
+	}
+	for header, expected := range tests {
+		status, err = authorize(newAuthRequest("POST", header))
+		assert.Equal(t, status, expected, "unexpected status for header '%s'", header)
+		assert.Equal(t, err == nil, expected == http.StatusOK, "unexpected error for header '%s': %v", header, err)
+	}
+
+	// the plugin replaces the static tokens
+	plugins.RegisterSchedulerPlugin(&fakeAuthenticationPlugin{})
+	defer plugins.UnregisterAuthenticationPlugin()
+	status, err = authorize(newAuthRequest("POST", "Bearer admin-token"))
+	assert.ErrorContains(t, err, "unknown token", "static token should not be used with a plugin")
+	assert.Equal(t, status, http.StatusUnauthorized)
+	status, err = authorize(newAuthRequest("POST", "Bearer plugin-token"))
+	assert.NilError(t, err, "plugin token for admin group member should be allowed")
+	assert.Equal(t, status, http.StatusOK)
+}
+
+func TestAuthHandler(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(configAuthentication))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+
+	calls := 0
+	inner := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	})
+	// GET and read only endpoints are not authenticated
+	resp := httptest.NewRecorder()
+	authHandler(inner, http.MethodGet, "/ws/v1/apps").ServeHTTP(resp, newAuthRequest("GET", ""))
+	assert.Equal(t, calls, 1, "GET endpoint should not be authenticated")
+	authHandler(inner, http.MethodPost, "/ws/v1/validate-conf").ServeHTTP(resp, newAuthRequest("POST", ""))
+	assert.Equal(t, calls, 2, "read only endpoint should not be authenticated")
+
+	handler := authHandler(inner, http.MethodPut, "/ws/v1/partition/{partition}/queues")
+	resp = httptest.NewRecorder()
+	handler.ServeHTTP(resp, newAuthRequest("PUT", ""))
+	assert.Equal(t, calls, 2, "unauthenticated request should not be processed")
+	assert.Equal(t, resp.Code, http.StatusUnauthorized, "unauthenticated request should be rejected")
+	assert.Equal(t, resp.Header().Get("WWW-Authenticate"), "Bearer", "authentication challenge not set")
+	resp = httptest.NewRecorder()
+	handler.ServeHTTP(resp, newAuthRequest("PUT", "Bearer user-token"))
+	assert.Equal(t, calls, 2, "non admin request should not be processed")
+	assert.Equal(t, resp.Code, http.StatusForbidden, "non admin request should be rejected")
+	resp = httptest.NewRecorder()
+	handler.ServeHTTP(resp, newAuthRequest("PUT", "Bearer admin-token"))
+	assert.Equal(t, calls, 3, "admin request should be processed")
+	assert.Equal(t, resp.Code, http.StatusOK, "admin request should be allowed")
+
+	// the snapshot exposes the full state: only for admins
+	handler = authHandler(inner, http.MethodGet, replicaSnapshotPath)
+	resp = httptest.NewRecorder()
+	handler.ServeHTTP(resp, newAuthRequest("GET", ""))
+	assert.Equal(t, resp.Code, http.StatusUnauthorized, "unauthenticated snapshot request should be rejected")
+	resp = httptest.NewRecorder()
+	handler.ServeHTTP(resp, newAuthRequest("GET", "Bearer user-token"))
+	assert.Equal(t, resp.Code, http.StatusForbidden, "non admin snapshot request should be rejected")
+	assert.Equal(t, calls, 3, "rejected snapshot requests should not be processed")
+	resp = httptest.NewRecorder()
+	handler.ServeHTTP(resp, newAuthRequest("GET", "Bearer admin-token"))
+	assert.Equal(t, calls, 4, "admin snapshot request should be processed")
+}
+
+func TestGetConfigForCaller(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(configAuthentication))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+	current := configs.ConfigContext.Get(schedulerContext.GetPolicyGroup())
+	assert.Assert(t, !current.Redaction.Enabled, "redaction should not be enabled")
+
+	// the access config is removed for a caller that is not an admin without redaction
+	for _, header := range []string{"", "Bearer unknown", "Bearer user-token"} {
+		req := newAuthRequest("GET", header)
+		req.Header.Set("Accept", "application/json")
+		resp := &MockResponseWriter{}
+		getClusterConfig(resp, req)
+		var conf configs.SchedulerConfig
+		err = json.Unmarshal(resp.outputBytes, &conf)
+		assert.NilError(t, err, "failed to unmarshal config from response body: %s", string(resp.outputBytes))
+		assert.Equal(t, conf.Authentication.AdminACL, redactedValue, "admin ACL should be removed for header '%s'", header)
+		assert.Equal(t, len(conf.Authentication.Tokens), 0, "tokens should be removed for header '%s'", header)
+		assert.Equal(t, len(conf.Partitions), 1, "partitions should be returned for header '%s'", header)
+	}
+	assert.Equal(t, len(current.Authentication.Tokens), 2, "current config should not be changed")
+
+	conf := getConfigForCaller(newAuthRequest("GET", "Bearer admin-token"), current)
+	assert.Equal(t, conf, current, "admin should get the config unchanged")
+
+	// the inline TLS key is removed for a caller that is not an admin
+	withKey := *current
+	withKey.WebService.TLS.Key = "private key"
+	conf = getConfigForCaller(newAuthRequest("GET", ""), &withKey)
+	assert.Equal(t, conf.WebService.TLS.Key, redactedValue, "inline TLS key should be removed")
+}
@@ -0,0 +1,77 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package webservice
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/configs"
+)
+
+// Add the CORS headers to the response of the handler.
+// The allowed origins are read from the configuration on every request: a config reload is applied immediately.
+func corsHandler(inner http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		setCORSHeaders(w, r.Header.Get("Origin"), getAllowedOrigins())
+		inner.ServeHTTP(w, r)
+	})
+}
+
+// Return the configured allowed origins, nil if not configured or there is no configuration.
+func getAllowedOrigins() []string {
+	if schedulerContext == nil {
+		return nil
+	}
+	conf := configs.ConfigContext.Get(schedulerContext.GetPolicyGroup())
+	if conf == nil {
+		return nil
+	}
+	return conf.WebService.AllowedOrigins
+}
+
+// Set the CORS headers for the origin of the request. All origins are allowed if no origins are configured.
+// The headers are not set if the origin is not allowed, the browser then blocks the cross-origin request.
+func setCORSHeaders(w http.ResponseWriter, origin string, allowed []string) {
+	header := w.Header()
+	allowOrigin := ""
+	if len(allowed) == 0 {
+		allowOrigin = "*"
+	} else {
+		// the response depends on the origin of the request
+		header.Add("Vary", "Origin")
+		for _, entry := range allowed {
+			if entry == "*" {
+				allowOrigin = "*"
+				break
+			}
+			if origin != "" && strings.EqualFold(strings.TrimSuffix(entry, "/"), origin) {
+				allowOrigin = origin
+				break
+			}
+		}
+	}
+	if allowOrigin == "" {
+		return
+	}
+	header.Set("Access-Control-Allow-Origin", allowOrigin)
+	header.Set("Access-Control-Allow-Credentials", "true")
+	header.Set("Access-Control-Allow-Methods", "GET,POST,HEAD,OPTIONS")
+	header.Set("Access-Control-Allow-Headers", "X-Requested-With,Content-Type,Accept,Origin")
+}
@@ -0,0 +1,103 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package webservice
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/configs"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler"
+)
+
+const configCORS = `
+webservice:
+  allowedorigins:
+    - https://dashboard.example.com
+    - http://localhost:8080/
+partitions:
+  - name: default
+    queues:
+      - name: root
+        submitacl: "*"
+        queues:
+          - name: default
+`
+
+func TestSetCORSHeaders(t *testing.T) {
+	resp := httptest.NewRecorder()
+	setCORSHeaders(resp, "https://other.example.com", nil)
+	assert.Equal(t, resp.Header().Get("Access-Control-Allow-Origin"), "*", "all origins should be allowed by default")
+	assert.Equal(t, resp.Header().Get("Vary"), "", "response should not depend on the origin")
+
+	allowed := []string{"https://dashboard.example.com", "http://localhost:8080/"}
+	resp = httptest.NewRecorder()
+	setCORSHeaders(resp, "https://dashboard.example.com", allowed)
+	assert.Equal(t, resp.Header().Get("Access-Control-Allow-Origin"), "https://dashboard.example.com", "origin should be allowed")
+	assert.Equal(t, resp.Header().Get("Access-Control-Allow-Credentials"), "true")
+	assert.Equal(t, resp.Header().Get("Vary"), "Origin", "response should depend on the origin")
+
+	resp = httptest.NewRecorder()
+	setCORSHeaders(resp, "http://localhost:8080", allowed)
+	assert.Equal(t, resp.Header().Get("Access-Control-Allow-Origin"), "http://localhost:8080", "origin with trailing slash in config should be allowed")
+
+	resp = httptest.NewRecorder()
+	setCORSHeaders(resp, "https://other.example.com", allowed)
+	assert.Equal(t, resp.Header().Get("Access-Control-Allow-Origin"), "", "origin should not be allowed")
+	assert.Equal(t, resp.Header().Get("Access-Control-Allow-Methods"), "", "no CORS headers should be set")
+
+	resp = httptest.NewRecorder()
+	setCORSHeaders(resp, "", allowed)
+	assert.Equal(t, resp.Header().Get("Access-Control-Allow-Origin"), "", "request without origin should not be allowed")
+
+	resp = httptest.NewRecorder()
+	setCORSHeaders(resp, "https://other.example.com", []string{"https://dashboard.example.com", "*"})
+	assert.Equal(t, resp.Header().Get("Access-Control-Allow-Origin"), "*", "wildcard should allow all origins")
+}
+
+func TestCORSHandler(t *testing.T) {
+	handler := corsHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeHeaders(w)
+	}))
+	request := func(origin string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest("GET", "/ws/v1/apps", nil)
+		assert.NilError(t, err, "failed to create request")
+		req.Header.Set("Origin", origin)
+		resp := httptest.NewRecorder()
+		handler.ServeHTTP(resp, req)
+		return resp
+	}
+
+	configs.MockSchedulerConfigByData([]byte(configDefault))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+	assert.Equal(t, request("https://other.example.com").Header().Get("Access-Control-Allow-Origin"), "*", "all origins should be allowed without config")
+
+	configs.MockSchedulerConfigByData([]byte(configCORS))
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+	assert.Equal(t, request("https://other.example.com").Header().Get("Access-Control-Allow-Origin"), "", "origin should not be allowed")
+	resp := request("https://dashboard.example.com")
+	assert.Equal(t, resp.Header().Get("Access-Control-Allow-Origin"), "https://dashboard.example.com", "origin should be allowed")
+	assert.Equal(t, resp.Header().Get("Content-Type"), "application/json; charset=UTF-8", "handler headers should be set")
+}
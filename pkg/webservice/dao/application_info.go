@@ -23,13 +23,19 @@ type ApplicationsDAOInfo struct {
 }
 
 type ApplicationDAOInfo struct {
-	ApplicationID  string              `json:"applicationID"`
-	UsedResource   string              `json:"usedResource"`
-	Partition      string              `json:"partition"`
-	QueueName      string              `json:"queueName"`
-	SubmissionTime int64               `json:"submissionTime"`
-	Allocations    []AllocationDAOInfo `json:"allocations"`
-	State          string              `json:"applicationState"`
+	ApplicationID  string                 `json:"applicationID"`
+	UsedResource   string                 `json:"usedResource"`
+	Partition      string                 `json:"partition"`
+	QueueName      string                 `json:"queueName"`
+	User           string                 `json:"user"`
+	PlacementRule  string                 `json:"placementRule"`
+	RequestedQueue string                 `json:"requestedQueue"`
+	QueueCreated   bool                   `json:"queueCreated"`
+	SubmissionTime int64                  `json:"submissionTime"`
+	Allocations    []AllocationDAOInfo    `json:"allocations"`
+	State          string                 `json:"applicationState"`
+	PendingAsks    []AllocationAskDAOInfo `json:"pendingAsks"`
+	Reservations   []*ReservationDAOInfo  `json:"reservations"`
 }
 
 type AllocationDAOInfo struct {
@@ -42,4 +48,24 @@ type AllocationDAOInfo struct {
 	NodeID           string            `json:"nodeId"`
 	ApplicationID    string            `json:"applicationId"`
 	Partition        string            `json:"partition"`
+	ProposalTime     int64             `json:"proposalTime,omitempty"`
+}
+
+// An outstanding ask of an application: the pending count is the number of allocations still requested.
+type AllocationAskDAOInfo struct {
+	AllocationKey    string            `json:"allocationKey"`
+	AllocationTags   map[string]string `json:"allocationTags"`
+	ResourcePerAlloc string            `json:"resource"`
+	PendingCount     int32             `json:"pendingCount"`
+	Priority         string            `json:"priority"`
+	CreateTime       int64             `json:"createTime"`
+}
+
+// A reservation of a node for an ask: the resources are held for the ask until the reservation converts into an allocation.
+type ReservationDAOInfo struct {
+	ApplicationID string `json:"applicationId"`
+	AllocationKey string `json:"allocationKey"`
+	NodeID        string `json:"nodeId"`
+	Reserved      string `json:"reserved"`
+	CreateTime    int64  `json:"createTime"`
 }
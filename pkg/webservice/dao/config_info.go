@@ -19,6 +19,25 @@
 package dao
 
 type ValidateConfResponse struct {
-	Allowed bool   `json:"allowed"`
-	Reason  string `json:"reason"`
+	Allowed  bool                      `json:"allowed"`
+	Reason   string                    `json:"reason"`
+	Errors   []ConfigDiagnosticDAOInfo `json:"errors,omitempty"`
+	Warnings []ConfigDiagnosticDAOInfo `json:"warnings,omitempty"`
+}
+
+type ConfigDiagnosticDAOInfo struct {
+	Line    int    `json:"line,omitempty"`
+	Path    string `json:"path,omitempty"`
+	Message string `json:"message"`
+}
+
+type ConfigVersionDAOInfo struct {
+	Version  uint64 `json:"version"`
+	Checksum string `json:"checksum"`
+	LoadTime int64  `json:"loadTime"`
+	Current  bool   `json:"current"`
+}
+
+type ConfigRollbackDAOInfo struct {
+	Version uint64 `json:"version"`
 }
@@ -33,4 +33,60 @@ type NodeDAOInfo struct {
 	Available   string               `json:"available"`
 	Allocations []*AllocationDAOInfo `json:"allocations"`
 	Schedulable bool                 `json:"schedulable"`
+	Taints      map[string]string    `json:"taints,omitempty"`
+	Drain       *NodeDrainDAOInfo    `json:"drain,omitempty"`
+	// allocated resources as a percentage of the capacity per resource type
+	Utilization  map[string]int64      `json:"utilization,omitempty"`
+	Preempting   string                `json:"preempting"`
+	Reservations []*ReservationDAOInfo `json:"reservations,omitempty"`
+}
+
+// Progress of the drain of a node: the allocations on the node when the drain started and the allocations left.
+type NodeDrainDAOInfo struct {
+	StartTime            int64 `json:"startTime"`
+	InitialAllocations   int   `json:"initialAllocations"`
+	RemainingAllocations int   `json:"remainingAllocations"`
+}
+
+type NodeSchedulableDAOInfo struct {
+	Schedulable bool `json:"schedulable"`
+}
+
+// Drain request for a node: preempt releases all allocations on the node to allow them to be relocated.
+type NodeDrainRequestDAOInfo struct {
+	Preempt bool `json:"preempt"`
+}
+
+type NodeTaintsDAOInfo struct {
+	Taints map[string]string `json:"taints"`
+}
+
+// Impact of the removal of a node: the applications that lose allocations or reservations if the node is removed.
+type NodeRemovalImpactDAOInfo struct {
+	NodeID       string                             `json:"nodeID"`
+	Partition    string                             `json:"partition"`
+	Allocated    string                             `json:"allocated"`
+	Allocations  int                                `json:"allocations"`
+	Placeholders int                                `json:"placeholders"`
+	Reservations int                                `json:"reservations"`
+	Applications []*ApplicationRemovalImpactDAOInfo `json:"applications"`
+}
+
+// Impact of the removal of a node on one application, the allocated resources are the resources on the node.
+type ApplicationRemovalImpactDAOInfo struct {
+	ApplicationID string                           `json:"applicationID"`
+	QueueName     string                           `json:"queueName"`
+	State         string                           `json:"applicationState"`
+	Allocated     string                           `json:"allocated"`
+	Allocations   []string                         `json:"allocations,omitempty"`
+	Placeholders  []string                         `json:"placeholders,omitempty"`
+	Reservations  []string                         `json:"reservations,omitempty"`
+	TaskGroups    []*TaskGroupRemovalImpactDAOInfo `json:"taskGroups,omitempty"`
+}
+
+// Gang members of a task group of an application: allocations, including placeholders, on the node and in total.
+type TaskGroupRemovalImpactDAOInfo struct {
+	Name   string `json:"name"`
+	OnNode int    `json:"onNode"`
+	Total  int    `json:"total"`
 }
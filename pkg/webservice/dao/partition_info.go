@@ -31,6 +31,13 @@ type PartitionInfo struct {
 	Applications            map[string]int    `json:"applications"`
 	State                   string            `json:"state"`
 	LastStateTransitionTime string            `json:"lastStateTransitionTime"`
+	LastConfigReloadTime    string            `json:"lastConfigReloadTime"`
+	SchedulingEnabled       bool              `json:"schedulingEnabled"`
+}
+
+// Scheduling state of a partition: no allocations are made for a partition with scheduling disabled.
+type PartitionSchedulingDAOInfo struct {
+	Enabled bool `json:"enabled"`
 }
 
 type PartitionCapacity struct {
@@ -42,3 +49,26 @@ type NodeInfo struct {
 	NodeID     string `json:"nodeId"`
 	Capability string `json:"capability"`
 }
+
+type PartitionCountersDAOInfo struct {
+	Partition string                    `json:"partition"`
+	Counters  []PartitionCounterDAOInfo `json:"counters"`
+}
+
+type PartitionCounterDAOInfo struct {
+	Event   string         `json:"event"`
+	Windows map[string]int `json:"windows"`
+}
+
+type PartitionResourcesDAOInfo struct {
+	Partition string                      `json:"partition"`
+	GroupBy   string                      `json:"groupBy"`
+	Groups    []NodeGroupResourcesDAOInfo `json:"groups"`
+}
+
+type NodeGroupResourcesDAOInfo struct {
+	Value     string `json:"value"`
+	Nodes     int    `json:"nodes"`
+	Capacity  string `json:"capacity"`
+	Allocated string `json:"allocated"`
+}
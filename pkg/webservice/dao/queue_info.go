@@ -18,11 +18,12 @@
 package dao
 
 type QueueDAOInfo struct {
-	QueueName   string            `json:"queuename"`
-	Status      string            `json:"status"`
-	Capacities  QueueCapacity     `json:"capacities"`
-	ChildQueues []QueueDAOInfo    `json:"queues"`
-	Properties  map[string]string `json:"properties"`
+	QueueName    string            `json:"queuename"`
+	Status       string            `json:"status"`
+	Capacities   QueueCapacity     `json:"capacities"`
+	ChildQueues  []QueueDAOInfo    `json:"queues"`
+	Properties   map[string]string `json:"properties"`
+	ReservedApps int               `json:"reservedapps"`
 }
 
 type QueueCapacity struct {
@@ -30,17 +31,85 @@ type QueueCapacity struct {
 	MaxCapacity     string `json:"maxcapacity"`
 	UsedCapacity    string `json:"usedcapacity"`
 	AbsUsedCapacity string `json:"absusedcapacity"`
+	PendingCapacity string `json:"pendingcapacity"`
 }
 
 type PartitionQueueDAOInfo struct {
-	QueueName          string                  `json:"queuename"`
-	Status             string                  `json:"status"`
-	Partition          string                  `json:"partition"`
-	MaxResource        string                  `json:"maxResource"`
-	GuaranteedResource string                  `json:"guaranteedResource"`
-	AllocatedResource  string                  `json:"allocatedResource"`
-	IsLeaf             bool                    `json:"isLeaf"`
-	IsManaged          bool                    `json:"isManaged"`
-	Parent             string                  `json:"parent"`
-	Children           []PartitionQueueDAOInfo `json:"children"`
+	QueueName            string                  `json:"queuename"`
+	Status               string                  `json:"status"`
+	Partition            string                  `json:"partition"`
+	MaxResource          string                  `json:"maxResource"`
+	GuaranteedResource   string                  `json:"guaranteedResource"`
+	AllocatedResource    string                  `json:"allocatedResource"`
+	PendingResource      string                  `json:"pendingResource"`
+	RunningApps          int                     `json:"runningApps"`
+	Weight               float64                 `json:"weight"`
+	PriorityQuota        *PriorityQuotaDAOInfo   `json:"priorityQuota,omitempty"`
+	AllowedResourceTypes []string                `json:"allowedResourceTypes,omitempty"`
+	ContentionCap        *ContentionCapDAOInfo   `json:"contentionCap,omitempty"`
+	SchedulingPaused     bool                    `json:"schedulingPaused"`
+	IsLeaf               bool                    `json:"isLeaf"`
+	IsManaged            bool                    `json:"isManaged"`
+	Parent               string                  `json:"parent"`
+	Children             []PartitionQueueDAOInfo `json:"children"`
+}
+
+// Quota on the resources of asks above the priority threshold and the current usage against it.
+type PriorityQuotaDAOInfo struct {
+	Threshold    int32  `json:"threshold"`
+	MaxResource  string `json:"maxResource"`
+	UsedResource string `json:"usedResource"`
+}
+
+// Contention cap of the queue: the maximum resource the queue is capped at while the partition is under contention.
+type ContentionCapDAOInfo struct {
+	Share            float64 `json:"share"`
+	PendingThreshold float64 `json:"pendingThreshold"`
+	MaxResource      string  `json:"maxResource"`
+	Clamped          bool    `json:"clamped"`
+}
+
+// Limit update for a queue: an omitted resource is not changed, an empty resource removes the limit.
+type QueueLimitsDAOInfo struct {
+	QueuePath          string            `json:"queuePath"`
+	MaxResource        map[string]string `json:"maxResource,omitempty"`
+	GuaranteedResource map[string]string `json:"guaranteedResource,omitempty"`
+}
+
+// Queue change merged into the stored configuration. The parent flag is only used when the queue is created.
+// On an update an omitted resource or property map is not changed, an empty map removes the setting.
+type QueueConfigDAOInfo struct {
+	QueuePath          string            `json:"queuePath"`
+	Parent             bool              `json:"parent,omitempty"`
+	MaxResource        map[string]string `json:"maxResource,omitempty"`
+	GuaranteedResource map[string]string `json:"guaranteedResource,omitempty"`
+	Properties         map[string]string `json:"properties,omitempty"`
+}
+
+// Move or rename of a queue: the new fully qualified path of the queue.
+type QueueMoveDAOInfo struct {
+	NewQueuePath string `json:"newQueuePath"`
+}
+
+// Scheduling state of a queue: a paused queue accepts applications but is skipped when scheduling.
+type QueueSchedulingDAOInfo struct {
+	Paused bool `json:"paused"`
+}
+
+// Result of the submit and admin access checks for a user on a queue.
+// The rules are the ACL entries that gave access, not set if access is denied.
+type QueueAccessDAOInfo struct {
+	QueuePath    string                  `json:"queuePath"`
+	User         string                  `json:"user"`
+	Groups       []string                `json:"groups"`
+	Allowed      bool                    `json:"allowed"`
+	Rule         *QueueAccessRuleDAOInfo `json:"rule,omitempty"`
+	AdminAllowed bool                    `json:"adminAllowed"`
+	AdminRule    *QueueAccessRuleDAOInfo `json:"adminRule,omitempty"`
+}
+
+type QueueAccessRuleDAOInfo struct {
+	QueuePath string `json:"queuePath"`
+	ACL       string `json:"acl"`
+	Entry     string `json:"entry"`
 }
@@ -0,0 +1,130 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package webservice
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/events"
+	"github.com/apache/incubator-yunikorn-core/pkg/webservice/dao"
+	"github.com/apache/incubator-yunikorn-scheduler-interface/lib/go/si"
+)
+
+// The time a request to the event stream waits for new events if the client does not set it, and the maximum.
+// The request limits do not apply to the event stream, the maximum wait bounds the request instead.
+var (
+	defaultStreamWait = 30 * time.Second
+	maxStreamWait     = 5 * time.Minute
+)
+
+type eventStreamQuery struct {
+	cursor uint64
+	types  map[si.EventRecord_Type]bool // nil for all types
+	wait   time.Duration
+}
+
+// Parse the event stream query parameters:
+// cursor=<last seen>, types=app,node,queue,request and wait=<duration>
+// Without a cursor the client subscribes from the last event in the feed and only gets new events.
+func parseEventStreamQuery(values url.Values, feed *events.EventFeed) (*eventStreamQuery, error) {
+	query := &eventStreamQuery{
+		wait: defaultStreamWait,
+	}
+	var err error
+	if value := values.Get("cursor"); value != "" {
+		if query.cursor, err = strconv.ParseUint(value, 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid cursor value: %s", value)
+		}
+	} else {
+		query.cursor = feed.GetCursor()
+	}
+	if value := values.Get("types"); value != "" {
+		query.types = make(map[si.EventRecord_Type]bool)
+		for _, name := range strings.Split(value, ",") {
+			eventType, ok := si.EventRecord_Type_value[strings.ToUpper(strings.TrimSpace(name))]
+			if !ok {
+				return nil, fmt.Errorf("invalid event type: %s", name)
+			}
+			query.types[si.EventRecord_Type(eventType)] = true
+		}
+	}
+	if value := values.Get("wait"); value != "" {
+		if query.wait, err = time.ParseDuration(value); err != nil || query.wait < 0 {
+			return nil, fmt.Errorf("invalid wait value: %s", value)
+		}
+		if query.wait > maxStreamWait {
+			query.wait = maxStreamWait
+		}
+	}
+	return query, nil
+}
+
+// Check if the event has one of the requested types.
+func (q *eventStreamQuery) filter(event *si.EventRecord) bool {
+	return q.types == nil || q.types[event.Type]
+}
+
+// Long poll for application, queue, node and request events: the request returns as soon as there are events after
+// the cursor or when the wait is over. The client passes the returned cursor in the next request. If the response
+// is marked as missed the client was too slow and must reload the full state.
+func getEventStream(w http.ResponseWriter, r *http.Request) {
+	writeHeaders(w)
+	eventCache := events.GetEventCache()
+	if eventCache == nil {
+		buildJSONErrorResponse(w, "Event system is not enabled.", http.StatusNotImplemented)
+		return
+	}
+	query, err := parseEventStreamQuery(r.URL.Query(), eventCache.Feed)
+	if err != nil {
+		buildJSONErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	records, cursor, missed := eventCache.Feed.Poll(query.cursor, query.filter, query.wait, r.Context().Done())
+	result := dao.EventStreamDAOInfo{
+		Cursor: cursor,
+		Missed: missed,
+		Events: make([]dao.EventDAOInfo, 0, len(records)),
+	}
+	rd := getRedactor(r)
+	for _, record := range records {
+		event := getEventJSON(record)
+		rd.redactEvent(&event)
+		result.Events = append(result.Events, event)
+	}
+	if err = json.NewEncoder(w).Encode(result); err != nil {
+		buildJSONErrorResponse(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func getEventJSON(record *si.EventRecord) dao.EventDAOInfo {
+	return dao.EventDAOInfo{
+		Type:          strings.ToLower(record.Type.String()),
+		ObjectID:      record.ObjectID,
+		GroupID:       record.GroupID,
+		Reason:        record.Reason,
+		Message:       record.Message,
+		TimestampNano: record.TimestampNano,
+	}
+}
@@ -0,0 +1,111 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package webservice
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common"
+	"github.com/apache/incubator-yunikorn-core/pkg/events"
+	"github.com/apache/incubator-yunikorn-core/pkg/webservice/dao"
+	"github.com/apache/incubator-yunikorn-scheduler-interface/lib/go/si"
+)
+
+func TestParseEventStreamQuery(t *testing.T) {
+	events.CreateAndSetEventCache()
+	feed := events.GetEventCache().Feed
+	query, err := parseEventStreamQuery(url.Values{}, feed)
+	assert.NilError(t, err, "empty query failed")
+	assert.Equal(t, query.cursor, feed.GetCursor())
+	assert.Assert(t, query.types == nil, "all types expected")
+	assert.Equal(t, query.wait, defaultStreamWait)
+
+	query, err = parseEventStreamQuery(url.Values{"cursor": {"12"}, "types": {"app, Node"}, "wait": {"1h"}}, feed)
+	assert.NilError(t, err, "query failed")
+	assert.Equal(t, query.cursor, uint64(12))
+	assert.Equal(t, len(query.types), 2)
+	assert.Assert(t, query.filter(&si.EventRecord{Type: si.EventRecord_NODE}), "node event filtered")
+	assert.Assert(t, !query.filter(&si.EventRecord{Type: si.EventRecord_QUEUE}), "queue event not filtered")
+	assert.Equal(t, query.wait, maxStreamWait)
+
+	for _, values := range []url.Values{
+		{"cursor": {"-1"}},
+		{"types": {"app,pod"}},
+		{"wait": {"-1s"}},
+		{"wait": {"soon"}},
+	} {
+		_, err = parseEventStreamQuery(values, feed)
+		assert.Assert(t, err != nil, "invalid query accepted: %v", values)
+	}
+}
+
+func TestGetEventStream(t *testing.T) {
+	streamRequest := func(query string) *MockResponseWriter {
+		req, err := http.NewRequest("GET", "/ws/v1/stream?"+query, strings.NewReader(""))
+		assert.NilError(t, err, "stream request failed")
+		resp := &MockResponseWriter{}
+		getEventStream(resp, req)
+		return resp
+	}
+
+	events.CreateAndSetEventCache()
+	cache := events.GetEventCache()
+	cache.StartService()
+	defer cache.Stop()
+	for _, record := range []*si.EventRecord{
+		{Type: si.EventRecord_APP, ObjectID: "app-1", Reason: "ApplicationPlaced"},
+		{Type: si.EventRecord_NODE, ObjectID: "node-1", Reason: "NodeAdded"},
+	} {
+		cache.AddEvent(record)
+	}
+	err := common.WaitFor(time.Millisecond, time.Second, func() bool {
+		return cache.Feed.GetCursor() == 2
+	})
+	assert.NilError(t, err, "events not processed")
+
+	resp := streamRequest("cursor=0&types=app&wait=0s")
+	assert.Equal(t, resp.statusCode, 0, "unexpected status code")
+	var result dao.EventStreamDAOInfo
+	err = json.Unmarshal(resp.outputBytes, &result)
+	assert.NilError(t, err, "response unmarshal failed")
+	assert.Equal(t, result.Cursor, uint64(2))
+	assert.Assert(t, !result.Missed, "no events missed")
+	assert.Equal(t, len(result.Events), 1)
+	assert.Equal(t, result.Events[0].Type, "app")
+	assert.Equal(t, result.Events[0].ObjectID, "app-1")
+	assert.Equal(t, result.Events[0].Reason, "ApplicationPlaced")
+
+	// a new subscriber only gets new events: nothing within the wait
+	resp = streamRequest("wait=10ms")
+	result = dao.EventStreamDAOInfo{}
+	err = json.Unmarshal(resp.outputBytes, &result)
+	assert.NilError(t, err, "response unmarshal failed")
+	assert.Equal(t, result.Cursor, uint64(2))
+	assert.Equal(t, len(result.Events), 0)
+
+	resp = streamRequest("types=pod")
+	assert.Equal(t, resp.statusCode, http.StatusBadRequest, "invalid type accepted")
+}
@@ -27,15 +27,19 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"time"
 
 	"go.uber.org/zap"
 	"gopkg.in/yaml.v2"
 
 	"github.com/apache/incubator-yunikorn-core/pkg/common/configs"
+	"github.com/apache/incubator-yunikorn-core/pkg/common/features"
 	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+	"github.com/apache/incubator-yunikorn-core/pkg/common/security"
 	"github.com/apache/incubator-yunikorn-core/pkg/log"
 	metrics2 "github.com/apache/incubator-yunikorn-core/pkg/metrics"
 	"github.com/apache/incubator-yunikorn-core/pkg/plugins"
+	"github.com/apache/incubator-yunikorn-core/pkg/readiness"
 	"github.com/apache/incubator-yunikorn-core/pkg/scheduler"
 	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/objects"
 	"github.com/apache/incubator-yunikorn-core/pkg/webservice/dao"
@@ -44,6 +48,12 @@ import (
 	"github.com/gorilla/mux"
 )
 
+// forecast horizon used when the request does not specify one
+const defaultForecastHours = 1
+
+// user fairness window used when the request does not specify one
+const defaultFairnessWindow = time.Hour
+
 func getStackInfo(w http.ResponseWriter, r *http.Request) {
 	writeHeaders(w)
 	var stack = func() []byte {
@@ -65,9 +75,11 @@ func getStackInfo(w http.ResponseWriter, r *http.Request) {
 func getQueueInfo(w http.ResponseWriter, r *http.Request) {
 	writeHeaders(w)
 
+	rd := getRedactor(r)
 	lists := schedulerContext.GetPartitionMapClone()
 	for _, partition := range lists {
 		partitionInfo := getPartitionJSON(partition)
+		rd.redactQueue(&partitionInfo.Queues, "")
 
 		if err := json.NewEncoder(w).Encode(partitionInfo); err != nil {
 			buildJSONErrorResponse(w, err.Error(), http.StatusInternalServerError)
@@ -118,21 +130,22 @@ func getApplicationsInfo(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var appsDao []*dao.ApplicationDAOInfo
+	// the applications are streamed, only one DAO object exists at a time
+	rd := getRedactor(r)
+	stream := newJSONArrayStream(w, r)
 	lists := schedulerContext.GetPartitionMapClone()
 	for _, partition := range lists {
 		appList := partition.GetApplications()
 		appList = append(appList, partition.GetCompletedApplications()...)
 		for _, app := range appList {
 			if len(queueName) == 0 || strings.EqualFold(queueName, app.GetQueueName()) {
-				appsDao = append(appsDao, getApplicationJSON(app))
+				appDao := getApplicationJSON(app)
+				rd.redactApplication(appDao)
+				stream.add(appDao)
 			}
 		}
 	}
-
-	if err := json.NewEncoder(w).Encode(appsDao); err != nil {
-		buildJSONErrorResponse(w, err.Error(), http.StatusInternalServerError)
-	}
+	logStreamError(r, stream.close(true))
 }
 
 func validateQueue(queueName string) error {
@@ -152,23 +165,38 @@ func validateQueue(queueName string) error {
 func getNodesInfo(w http.ResponseWriter, r *http.Request) {
 	writeHeaders(w)
 
-	var result []*dao.NodesDAOInfo
+	query, err := parseNodeQuery(r.URL.Query())
+	if err != nil {
+		buildJSONErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	// the nodes are streamed, only one DAO object exists at a time:
+	// the partition objects are written by hand to stream the nodes nested in them
+	rd := getRedactor(r)
+	stream := newJSONArrayStream(w, r)
 	lists := schedulerContext.GetPartitionMapClone()
 	for _, partition := range lists {
-		var nodesDao []*dao.NodeDAOInfo
-		for _, node := range partition.GetNodes() {
-			nodeDao := getNodeJSON(node)
-			nodesDao = append(nodesDao, nodeDao)
+		if !query.matchPartition(partition.Name) {
+			continue
 		}
-		result = append(result, &dao.NodesDAOInfo{
-			PartitionName: partition.Name,
-			Nodes:         nodesDao,
-		})
-	}
-
-	if err := json.NewEncoder(w).Encode(result); err != nil {
-		buildJSONErrorResponse(w, err.Error(), http.StatusInternalServerError)
+		name, nameErr := json.Marshal(partition.Name)
+		if nameErr != nil {
+			stream.err = nameErr
+			break
+		}
+		stream.next()
+		stream.write([]byte(`{"partitionName":`))
+		stream.write(name)
+		stream.write([]byte(`,"nodesInfo":`))
+		// pagination is applied per partition
+		nodeStream := stream.nested()
+		for _, node := range query.paginate(query.filter(partition.GetNodes())) {
+			nodeStream.add(query.getNodeJSON(node, rd))
+		}
+		stream.err = nodeStream.close(false)
+		stream.write([]byte("}"))
 	}
+	logStreamError(r, stream.close(true))
 }
 
 func getNodesUtilization(w http.ResponseWriter, r *http.Request) {
@@ -190,27 +218,43 @@ func getNodesUtilization(w http.ResponseWriter, r *http.Request) {
 func validateConf(w http.ResponseWriter, r *http.Request) {
 	writeHeaders(w)
 	requestBytes, err := ioutil.ReadAll(r.Body)
-	if err == nil {
-		_, err = configs.LoadSchedulerConfigFromByteArray(requestBytes)
-	}
 	var result dao.ValidateConfResponse
 	if err != nil {
-		result.Allowed = false
 		result.Reason = err.Error()
 	} else {
-		result.Allowed = true
+		result = getValidateConfResponse(requestBytes)
 	}
 	if err = json.NewEncoder(w).Encode(result); err != nil {
 		buildJSONErrorResponse(w, err.Error(), http.StatusInternalServerError)
 	}
 }
 
+// Validate the configuration: the allowed flag and reason are set based on the normal config loading,
+// the errors and warnings list all issues found in the configuration.
+func getValidateConfResponse(content []byte) dao.ValidateConfResponse {
+	var result dao.ValidateConfResponse
+	if _, err := configs.LoadSchedulerConfigFromByteArray(content); err != nil {
+		result.Reason = err.Error()
+	} else {
+		result.Allowed = true
+	}
+	for _, diagnostic := range configs.DiagnoseConfig(content) {
+		info := dao.ConfigDiagnosticDAOInfo{
+			Line:    diagnostic.Line,
+			Path:    diagnostic.Path,
+			Message: diagnostic.Message,
+		}
+		if diagnostic.Level == configs.DiagnosticWarning {
+			result.Warnings = append(result.Warnings, info)
+		} else {
+			result.Errors = append(result.Errors, info)
+		}
+	}
+	return result
+}
+
 func writeHeaders(w http.ResponseWriter) {
 	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Credentials", "true")
-	w.Header().Set("Access-Control-Allow-Methods", "GET,POST,HEAD,OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "X-Requested-With,Content-Type,Accept,Origin")
 }
 
 func buildJSONErrorResponse(w http.ResponseWriter, detail string, code int) {
@@ -288,18 +332,23 @@ func getApplicationJSON(app *objects.Application) *dao.ApplicationDAOInfo {
 	var allocationInfos []dao.AllocationDAOInfo
 	allocations := app.GetAllAllocations()
 	for _, alloc := range allocations {
-		allocInfo := dao.AllocationDAOInfo{
-			AllocationKey:    alloc.AllocationKey,
-			AllocationTags:   alloc.Tags,
-			UUID:             alloc.UUID,
-			ResourcePerAlloc: alloc.AllocatedResource.DAOString(),
-			Priority:         strconv.Itoa(int(alloc.Priority)),
-			QueueName:        alloc.QueueName,
-			NodeID:           alloc.NodeID,
-			ApplicationID:    alloc.ApplicationID,
-			Partition:        alloc.PartitionName,
-		}
-		allocationInfos = append(allocationInfos, allocInfo)
+		allocationInfos = append(allocationInfos, *getAllocationJSON(alloc))
+	}
+
+	var pendingAsks []dao.AllocationAskDAOInfo
+	for _, ask := range app.GetPendingAsks() {
+		pendingAsks = append(pendingAsks, dao.AllocationAskDAOInfo{
+			AllocationKey:    ask.AllocationKey,
+			AllocationTags:   ask.Tags,
+			ResourcePerAlloc: ask.AllocatedResource.DAOString(),
+			PendingCount:     ask.GetPendingAskRepeat(),
+			Priority:         strconv.Itoa(int(ask.GetPriority())),
+			CreateTime:       ask.GetCreateTime().UnixNano(),
+		})
+	}
+	var reservations []*dao.ReservationDAOInfo
+	for _, info := range app.GetReservationInfos() {
+		reservations = append(reservations, getReservationJSON(info))
 	}
 
 	return &dao.ApplicationDAOInfo{
@@ -307,39 +356,86 @@ func getApplicationJSON(app *objects.Application) *dao.ApplicationDAOInfo {
 		UsedResource:   app.GetAllocatedResource().DAOString(),
 		Partition:      app.Partition,
 		QueueName:      app.QueueName,
+		User:           app.GetUser().User,
+		PlacementRule:  app.GetPlacementRule(),
+		RequestedQueue: app.GetRequestedQueue(),
+		QueueCreated:   app.IsQueueCreated(),
 		SubmissionTime: app.SubmissionTime.UnixNano(),
 		Allocations:    allocationInfos,
 		State:          app.CurrentState(),
+		PendingAsks:    pendingAsks,
+		Reservations:   reservations,
+	}
+}
+
+func getReservationJSON(info *objects.ReservationInfo) *dao.ReservationDAOInfo {
+	return &dao.ReservationDAOInfo{
+		ApplicationID: info.ApplicationID,
+		AllocationKey: info.AllocationKey,
+		NodeID:        info.NodeID,
+		Reserved:      info.Reserved.DAOString(),
+		CreateTime:    info.Created.UnixNano(),
+	}
+}
+
+// The proposal time is only set for allocations made by the scheduler, recovered allocations do not have one.
+func getAllocationJSON(alloc *objects.Allocation) *dao.AllocationDAOInfo {
+	var proposalTime int64
+	if proposed := alloc.GetProposalTime(); !proposed.IsZero() {
+		proposalTime = proposed.UnixNano()
+	}
+	return &dao.AllocationDAOInfo{
+		AllocationKey:    alloc.AllocationKey,
+		AllocationTags:   alloc.Tags,
+		UUID:             alloc.UUID,
+		ResourcePerAlloc: alloc.AllocatedResource.DAOString(),
+		Priority:         strconv.Itoa(int(alloc.Priority)),
+		QueueName:        alloc.QueueName,
+		NodeID:           alloc.NodeID,
+		ApplicationID:    alloc.ApplicationID,
+		Partition:        alloc.PartitionName,
+		ProposalTime:     proposalTime,
 	}
 }
 
 func getNodeJSON(node *objects.Node) *dao.NodeDAOInfo {
 	var allocations []*dao.AllocationDAOInfo
 	for _, alloc := range node.GetAllAllocations() {
-		allocInfo := &dao.AllocationDAOInfo{
-			AllocationKey:    alloc.AllocationKey,
-			AllocationTags:   alloc.Tags,
-			UUID:             alloc.UUID,
-			ResourcePerAlloc: alloc.AllocatedResource.DAOString(),
-			Priority:         strconv.Itoa(int(alloc.Priority)),
-			QueueName:        alloc.QueueName,
-			NodeID:           alloc.NodeID,
-			ApplicationID:    alloc.ApplicationID,
-			Partition:        alloc.PartitionName,
-		}
-		allocations = append(allocations, allocInfo)
+		allocations = append(allocations, getAllocationJSON(alloc))
 	}
 
+	var drain *dao.NodeDrainDAOInfo
+	if start, initial, remaining := node.GetDrainProgress(); !start.IsZero() {
+		drain = &dao.NodeDrainDAOInfo{
+			StartTime:            start.UnixNano(),
+			InitialAllocations:   initial,
+			RemainingAllocations: remaining,
+		}
+	}
+	capacity := node.GetCapacity()
+	utilization := make(map[string]int64)
+	for name, value := range resources.CalculateAbsUsedCapacity(capacity, node.GetAllocatedResource()).Resources {
+		utilization[name] = int64(value)
+	}
+	var reservations []*dao.ReservationDAOInfo
+	for _, info := range node.GetReservationInfos() {
+		reservations = append(reservations, getReservationJSON(info))
+	}
 	return &dao.NodeDAOInfo{
-		NodeID:      node.NodeID,
-		HostName:    node.Hostname,
-		RackName:    node.Rackname,
-		Capacity:    node.GetCapacity().DAOString(),
-		Occupied:    node.GetOccupiedResource().DAOString(),
-		Allocated:   node.GetAllocatedResource().DAOString(),
-		Available:   node.GetAvailableResource().DAOString(),
-		Allocations: allocations,
-		Schedulable: node.IsSchedulable(),
+		NodeID:       node.NodeID,
+		HostName:     node.Hostname,
+		RackName:     node.Rackname,
+		Capacity:     capacity.DAOString(),
+		Occupied:     node.GetOccupiedResource().DAOString(),
+		Allocated:    node.GetAllocatedResource().DAOString(),
+		Available:    node.GetAvailableResource().DAOString(),
+		Allocations:  allocations,
+		Schedulable:  node.IsSchedulable(),
+		Taints:       node.GetTaints(),
+		Drain:        drain,
+		Utilization:  utilization,
+		Preempting:   node.GetPreemptingResource().DAOString(),
+		Reservations: reservations,
 	}
 }
 
@@ -444,10 +540,93 @@ func getContainerHistory(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func getQueueForecast(w http.ResponseWriter, r *http.Request) {
+	writeHeaders(w)
+
+	// There is nothing to return but we did not really encounter a problem
+	if imHistory == nil {
+		buildJSONErrorResponse(w, "Internal metrics collection is not enabled.", http.StatusNotImplemented)
+		return
+	}
+	hours := defaultForecastHours
+	if value := r.URL.Query().Get("hours"); value != "" {
+		var err error
+		if hours, err = strconv.Atoi(value); err != nil || hours <= 0 {
+			buildJSONErrorResponse(w, "Hours must be a positive integer", http.StatusBadRequest)
+			return
+		}
+	}
+	result := &dao.QueuesForecastDAOInfo{
+		Hours:  hours,
+		Queues: make([]*dao.QueueForecastDAOInfo, 0),
+	}
+	for _, forecast := range imHistory.GetQueueForecast(time.Duration(hours) * time.Hour) {
+		result.Queues = append(result.Queues, &dao.QueueForecastDAOInfo{
+			Partition:         forecast.Partition,
+			QueueName:         forecast.QueuePath,
+			AllocatedResource: forecast.Allocated.DAOString(),
+			PendingResource:   forecast.Pending.DAOString(),
+			MaxResource:       forecast.MaxResource.DAOString(),
+			ExceedsMax:        forecast.ExceedsMax(),
+			Samples:           forecast.Samples,
+		})
+	}
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		buildJSONErrorResponse(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Report the share of the allocated resources of each user per queue against the fair share over the window.
+func getUserFairness(w http.ResponseWriter, r *http.Request) {
+	writeHeaders(w)
+
+	// There is nothing to return but we did not really encounter a problem
+	if imHistory == nil {
+		buildJSONErrorResponse(w, "Internal metrics collection is not enabled.", http.StatusNotImplemented)
+		return
+	}
+	window := defaultFairnessWindow
+	if value := r.URL.Query().Get("window"); value != "" {
+		var err error
+		if window, err = time.ParseDuration(value); err != nil || window <= 0 {
+			buildJSONErrorResponse(w, "Window must be a positive duration", http.StatusBadRequest)
+			return
+		}
+	}
+	rd := getRedactor(r)
+	result := &dao.UserFairnessReportDAOInfo{
+		Window: window.String(),
+		Queues: make([]*dao.QueueUserFairnessDAOInfo, 0),
+	}
+	for _, fairness := range imHistory.GetUserFairness(window) {
+		queue := &dao.QueueUserFairnessDAOInfo{
+			Partition:       fairness.Partition,
+			QueueName:       fairness.QueuePath,
+			Users:           make([]*dao.UserFairnessDAOInfo, 0, len(fairness.Users)),
+			MostOverServed:  fairness.MostOverServed,
+			MostUnderServed: fairness.MostUnderServed,
+		}
+		for _, user := range fairness.Users {
+			queue.Users = append(queue.Users, &dao.UserFairnessDAOInfo{
+				User:      user.User,
+				Share:     user.Share,
+				FairShare: user.FairShare,
+				Ratio:     user.Ratio(),
+				Samples:   user.Samples,
+			})
+		}
+		rd.redactUserFairness(queue)
+		result.Queues = append(result.Queues, queue)
+	}
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		buildJSONErrorResponse(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
 func getClusterConfig(w http.ResponseWriter, r *http.Request) {
 	writeHeaders(w)
 
-	conf := configs.ConfigContext.Get(schedulerContext.GetPolicyGroup())
+	conf := getConfigForCaller(r, configs.ConfigContext.Get(schedulerContext.GetPolicyGroup()))
 	var marshalledConf []byte
 	var err error
 	// check if we have a request for json output
@@ -482,15 +661,11 @@ func createClusterConfig(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	requestBytes, err := ioutil.ReadAll(r.Body)
-	if err == nil {
-		_, err = configs.LoadSchedulerConfigFromByteArray(requestBytes)
-	}
 	var result dao.ValidateConfResponse
 	if err != nil {
-		result.Allowed = false
 		result.Reason = err.Error()
 	} else {
-		result.Allowed = true
+		result = getValidateConfResponse(requestBytes)
 	}
 	if err = json.NewEncoder(w).Encode(result); err != nil {
 		buildJSONErrorResponse(w, err.Error(), http.StatusInternalServerError)
@@ -516,14 +691,18 @@ func updateClusterConfig(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	configs.SetChecksum(requestBytes, newConf)
-	newConfStr := configs.GetConfigurationString(requestBytes)
+	buildUpdateResponse(applyClusterConfig(requestBytes, newConf), w)
+}
+
+// Store the configuration using the config plugin and update the scheduler with the new configuration.
+// The stored configuration is reverted if the scheduler update fails.
+func applyClusterConfig(content []byte, newConf *configs.SchedulerConfig) error {
+	newConfStr := configs.GetConfigurationString(content)
 	// This fails if we have more than 1 RM
 	// Do not think the plugins will even work with multiple RMs
-	var oldConf string
-	oldConf, err = updateConfiguration(newConfStr)
+	oldConf, err := updateConfiguration(newConfStr)
 	if err != nil {
-		buildUpdateResponse(err, w)
-		return
+		return err
 	}
 	// This fails if we have no RM registered or more than 1 RM
 	err = schedulerContext.UpdateSchedulerConfig(newConf)
@@ -533,10 +712,65 @@ func updateClusterConfig(w http.ResponseWriter, r *http.Request) {
 		if err2 != nil {
 			err = fmt.Errorf("update failed: %s\nupdate rollback failed: %s", err.Error(), err2.Error())
 		}
+		return err
+	}
+	return nil
+}
+
+// List the configurations that were loaded for the policy group of the scheduler, oldest version first.
+func getClusterConfigHistory(w http.ResponseWriter, r *http.Request) {
+	writeHeaders(w)
+	policyGroup := schedulerContext.GetPolicyGroup()
+	var checksum string
+	if current := configs.ConfigContext.Get(policyGroup); current != nil {
+		checksum = current.Checksum
+	}
+	history := configs.ConfigContext.GetHistory(policyGroup)
+	versions := make([]dao.ConfigVersionDAOInfo, 0, len(history))
+	for _, entry := range history {
+		versions = append(versions, dao.ConfigVersionDAOInfo{
+			Version:  entry.Version,
+			Checksum: entry.Checksum,
+			LoadTime: entry.LoadTime.UnixNano(),
+			Current:  entry.Checksum == checksum,
+		})
+	}
+	if err := json.NewEncoder(w).Encode(versions); err != nil {
+		buildJSONErrorResponse(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Roll back to a configuration from the history. The configuration is applied as a new configuration update and
+// follows the same path as a normal update: the stored configuration is replaced and the scheduler is updated.
+func rollbackClusterConfig(w http.ResponseWriter, r *http.Request) {
+	lock.Lock()
+	defer lock.Unlock()
+	writeHeaders(w)
+	var rollback dao.ConfigRollbackDAOInfo
+	if err := json.NewDecoder(r.Body).Decode(&rollback); err != nil {
+		buildJSONErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	entry := configs.ConfigContext.GetVersion(schedulerContext.GetPolicyGroup(), rollback.Version)
+	if entry == nil {
+		buildJSONErrorResponse(w, fmt.Sprintf("configuration version %d not found in history", rollback.Version), http.StatusBadRequest)
+		return
+	}
+	// the configuration is restored as it was stored, not as the validated config
+	if entry.Content == nil {
+		buildJSONErrorResponse(w, fmt.Sprintf("configuration version %d has no stored content", rollback.Version), http.StatusBadRequest)
+		return
+	}
+	content := entry.Content
+	newConf, err := configs.LoadSchedulerConfigFromByteArray(content)
+	if err != nil {
 		buildUpdateResponse(err, w)
 		return
 	}
-	buildUpdateResponse(nil, w)
+	log.Logger().Info("Rolling back configuration",
+		zap.Uint64("version", entry.Version),
+		zap.String("checksum", entry.Checksum))
+	buildUpdateResponse(applyClusterConfig(content, newConf), w)
 }
 
 func isChecksumEqual(checksum string) bool {
@@ -579,40 +813,124 @@ func updateConfiguration(conf string) (string, error) {
 	return "", fmt.Errorf("config plugin not found")
 }
 
+func getRMInfo(w http.ResponseWriter, r *http.Request) {
+	writeHeaders(w)
+
+	rmInfos := schedulerContext.GetRMInfos()
+	if err := json.NewEncoder(w).Encode(rmInfos); err != nil {
+		buildJSONErrorResponse(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Lightweight status of the scheduler for high frequency probes, no scheduling objects are locked.
+func getStatus(w http.ResponseWriter, r *http.Request) {
+	writeHeaders(w)
+
+	if err := json.NewEncoder(w).Encode(schedulerContext.GetStatus()); err != nil {
+		buildJSONErrorResponse(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Get the readiness report of the subsystems, the status code is 503 if not all subsystems are ready.
+func getReadiness(w http.ResponseWriter, r *http.Request) {
+	writeHeaders(w)
+
+	report := readiness.GetReport()
+	if !report.Ready {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		buildJSONErrorResponse(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func getFeatureGates(w http.ResponseWriter, r *http.Request) {
+	writeHeaders(w)
+
+	gates := features.GetGates()
+	gatesInfo := make([]dao.FeatureGateDAOInfo, 0, len(gates))
+	for _, gate := range gates {
+		gatesInfo = append(gatesInfo, dao.FeatureGateDAOInfo{
+			Name:    gate.Name,
+			Enabled: gate.Enabled,
+			Default: gate.Default,
+		})
+	}
+	if err := json.NewEncoder(w).Encode(gatesInfo); err != nil {
+		buildJSONErrorResponse(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
 func getPartitions(w http.ResponseWriter, r *http.Request) {
 	writeHeaders(w)
 
 	var partitionsInfo []*dao.PartitionInfo
 	lists := schedulerContext.GetPartitionMapClone()
 	for _, partitionContext := range lists {
-		partitionInfo := &dao.PartitionInfo{}
-		partitionInfo.Name = partitionContext.Name
-		partitionInfo.State = partitionContext.GetCurrentState()
-		partitionInfo.LastStateTransitionTime = partitionContext.GetStateTime().String()
-
-		capacityInfo := dao.PartitionCapacity{}
-		capacityInfo.Capacity = partitionContext.GetTotalPartitionResource().DAOString()
-		capacityInfo.UsedCapacity = partitionContext.GetAllocatedResource().DAOString()
-		partitionInfo.Capacity = capacityInfo
-		partitionInfo.NodeSortingPolicy = partitionContext.GetNodeSortingPolicy().String()
-
-		appList := partitionContext.GetApplications()
-		appList = append(appList, partitionContext.GetCompletedApplications()...)
-		applicationsState := make(map[string]int)
-		totalApplications := 0
-		for _, app := range appList {
-			applicationsState[app.CurrentState()]++
-			totalApplications++
-		}
-		applicationsState["total"] = totalApplications
-		partitionInfo.Applications = applicationsState
-		partitionsInfo = append(partitionsInfo, partitionInfo)
+		partitionsInfo = append(partitionsInfo, getPartitionInfo(partitionContext))
 	}
 	if err := json.NewEncoder(w).Encode(partitionsInfo); err != nil {
 		buildJSONErrorResponse(w, err.Error(), http.StatusInternalServerError)
 	}
 }
 
+func getPartitionInfo(partitionContext *scheduler.PartitionContext) *dao.PartitionInfo {
+	partitionInfo := &dao.PartitionInfo{}
+	partitionInfo.Name = partitionContext.Name
+	partitionInfo.State = partitionContext.GetCurrentState()
+	partitionInfo.LastStateTransitionTime = partitionContext.GetStateTime().String()
+	partitionInfo.LastConfigReloadTime = partitionContext.GetConfigTime().String()
+	partitionInfo.SchedulingEnabled = partitionContext.IsSchedulingEnabled()
+
+	capacityInfo := dao.PartitionCapacity{}
+	capacityInfo.Capacity = partitionContext.GetTotalPartitionResource().DAOString()
+	capacityInfo.UsedCapacity = partitionContext.GetAllocatedResource().DAOString()
+	partitionInfo.Capacity = capacityInfo
+	partitionInfo.NodeSortingPolicy = partitionContext.GetNodeSortingPolicyName()
+
+	appList := partitionContext.GetApplications()
+	appList = append(appList, partitionContext.GetCompletedApplications()...)
+	applicationsState := make(map[string]int)
+	totalApplications := 0
+	for _, app := range appList {
+		applicationsState[app.CurrentState()]++
+		totalApplications++
+	}
+	applicationsState["total"] = totalApplications
+	partitionInfo.Applications = applicationsState
+	return partitionInfo
+}
+
+// Enable or disable scheduling for a partition. Existing allocations are not changed and new asks are queued while
+// scheduling is disabled. The change is replaced on the next configuration update.
+func updatePartitionScheduling(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	writeHeaders(w)
+	partitionName, partitionExists := vars["partition"]
+	if !partitionExists {
+		buildJSONErrorResponse(w, "Partition is missing in URL path. Please check the usage documentation", http.StatusBadRequest)
+		return
+	}
+	if len(vars) != 1 {
+		buildJSONErrorResponse(w, "Incorrect URL path. Please check the usage documentation", http.StatusBadRequest)
+		return
+	}
+	partition := schedulerContext.GetPartitionWithoutClusterID(partitionName)
+	if partition == nil {
+		buildJSONErrorResponse(w, "Partition not found", http.StatusBadRequest)
+		return
+	}
+	var scheduling dao.PartitionSchedulingDAOInfo
+	if err := json.NewDecoder(r.Body).Decode(&scheduling); err != nil {
+		buildJSONErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	partition.SetSchedulingEnabled(scheduling.Enabled)
+	if err := json.NewEncoder(w).Encode(getPartitionInfo(partition)); err != nil {
+		buildJSONErrorResponse(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
 func getPartitionQueues(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	writeHeaders(w)
@@ -629,6 +947,7 @@ func getPartitionQueues(w http.ResponseWriter, r *http.Request) {
 	var partition = schedulerContext.GetPartitionWithoutClusterID(partitionName)
 	if partition != nil {
 		partitionQueuesDAOInfo = partition.GetPartitionQueues()
+		getRedactor(r).redactPartitionQueue(&partitionQueuesDAOInfo)
 	} else {
 		buildJSONErrorResponse(w, "Partition not found", http.StatusBadRequest)
 		return
@@ -638,6 +957,37 @@ func getPartitionQueues(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// Get a single queue of the partition with the queues below it.
+func getPartitionQueue(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	writeHeaders(w)
+	partitionName, partitionExists := vars["partition"]
+	if !partitionExists {
+		buildJSONErrorResponse(w, "Partition is missing in URL path. Please check the usage documentation", http.StatusBadRequest)
+		return
+	}
+	queueName, queueNameExists := vars["queue"]
+	if !queueNameExists {
+		buildJSONErrorResponse(w, "Queue is missing in URL path. Please check the usage documentation", http.StatusBadRequest)
+		return
+	}
+	partition := schedulerContext.GetPartitionWithoutClusterID(partitionName)
+	if partition == nil {
+		buildJSONErrorResponse(w, "Partition not found", http.StatusBadRequest)
+		return
+	}
+	queue := partition.GetQueue(strings.ToLower(queueName))
+	if queue == nil {
+		buildJSONErrorResponse(w, "Queue not found", http.StatusBadRequest)
+		return
+	}
+	queueDao := queue.GetPartitionQueues()
+	getRedactor(r).redactPartitionQueue(&queueDao)
+	if err := json.NewEncoder(w).Encode(queueDao); err != nil {
+		buildJSONErrorResponse(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
 func getPartitionNodes(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	writeHeaders(w)
@@ -650,21 +1000,573 @@ func getPartitionNodes(w http.ResponseWriter, r *http.Request) {
 		buildJSONErrorResponse(w, "Incorrect URL path. Please check the usage documentation", http.StatusBadRequest)
 		return
 	}
+	query, err := parseNodeQuery(r.URL.Query())
+	if err != nil {
+		buildJSONErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	partitionContext := schedulerContext.GetPartitionWithoutClusterID(partition)
 	if partitionContext != nil {
-		var nodesDao []*dao.NodeDAOInfo
-		for _, node := range partitionContext.GetNodes() {
-			nodeDao := getNodeJSON(node)
-			nodesDao = append(nodesDao, nodeDao)
-		}
-		if err := json.NewEncoder(w).Encode(nodesDao); err != nil {
-			buildJSONErrorResponse(w, err.Error(), http.StatusInternalServerError)
+		// the nodes are streamed, only one DAO object exists at a time
+		rd := getRedactor(r)
+		stream := newJSONArrayStream(w, r)
+		for _, node := range query.paginate(query.filter(partitionContext.GetNodes())) {
+			stream.add(query.getNodeJSON(node, rd))
 		}
+		logStreamError(r, stream.close(true))
 	} else {
 		buildJSONErrorResponse(w, "Partition not found", http.StatusBadRequest)
 	}
 }
 
+func getPartitionCounters(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	writeHeaders(w)
+	partitionName, partitionExists := vars["partition"]
+	if !partitionExists {
+		buildJSONErrorResponse(w, "Partition is missing in URL path. Please check the usage documentation", http.StatusBadRequest)
+		return
+	}
+	if len(vars) != 1 {
+		buildJSONErrorResponse(w, "Incorrect URL path. Please check the usage documentation", http.StatusBadRequest)
+		return
+	}
+	partition := schedulerContext.GetPartitionWithoutClusterID(partitionName)
+	if partition == nil {
+		buildJSONErrorResponse(w, "Partition not found", http.StatusBadRequest)
+		return
+	}
+	if err := json.NewEncoder(w).Encode(partition.GetPartitionCounters()); err != nil {
+		buildJSONErrorResponse(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Get the partition resources grouped by a node attribute: ?groupBy=attribute
+// The attribute must be configured as a node group for the partition.
+func getPartitionResources(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	writeHeaders(w)
+	partitionName, partitionExists := vars["partition"]
+	if !partitionExists {
+		buildJSONErrorResponse(w, "Partition is missing in URL path. Please check the usage documentation", http.StatusBadRequest)
+		return
+	}
+	if len(vars) != 1 {
+		buildJSONErrorResponse(w, "Incorrect URL path. Please check the usage documentation", http.StatusBadRequest)
+		return
+	}
+	groupBy := r.URL.Query().Get("groupBy")
+	if groupBy == "" {
+		buildJSONErrorResponse(w, "groupBy query param is missing. Please check the usage documentation", http.StatusBadRequest)
+		return
+	}
+	partition := schedulerContext.GetPartitionWithoutClusterID(partitionName)
+	if partition == nil {
+		buildJSONErrorResponse(w, "Partition not found", http.StatusBadRequest)
+		return
+	}
+	result, err := partition.GetNodeGroupResources(groupBy)
+	if err != nil {
+		buildJSONErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err = json.NewEncoder(w).Encode(result); err != nil {
+		buildJSONErrorResponse(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func updateNodeTaints(w http.ResponseWriter, r *http.Request) {
+	writeHeaders(w)
+	_, node := getRequestNode(w, r)
+	if node == nil {
+		return
+	}
+	var taints dao.NodeTaintsDAOInfo
+	if err := json.NewDecoder(r.Body).Decode(&taints); err != nil {
+		buildJSONErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	node.SetTaints(taints.Taints)
+	writeNodeJSON(w, r, node)
+}
+
+// Set a node to schedulable or unschedulable, setting a draining node to schedulable stops the drain.
+func updateNodeSchedulable(w http.ResponseWriter, r *http.Request) {
+	writeHeaders(w)
+	_, node := getRequestNode(w, r)
+	if node == nil {
+		return
+	}
+	var schedulable dao.NodeSchedulableDAOInfo
+	if err := json.NewDecoder(r.Body).Decode(&schedulable); err != nil {
+		buildJSONErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	node.SetSchedulable(schedulable.Schedulable)
+	writeNodeJSON(w, r, node)
+}
+
+// Drain a node: the node is set to unschedulable and the reservations on the node are removed.
+// With preempt set the allocations on the node are released to allow the RM to relocate them.
+// The progress of the drain is part of the returned node.
+func drainNode(w http.ResponseWriter, r *http.Request) {
+	writeHeaders(w)
+	partition, node := getRequestNode(w, r)
+	if node == nil {
+		return
+	}
+	var drain dao.NodeDrainRequestDAOInfo
+	if err := json.NewDecoder(r.Body).Decode(&drain); err != nil {
+		buildJSONErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := schedulerContext.DrainNode(partition, node.NodeID, drain.Preempt); err != nil {
+		buildJSONErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeNodeJSON(w, r, node)
+}
+
+// Get the partition and node from the URL path of the request.
+// The node is nil if the request is not valid, the error response has been written in that case.
+func getRequestNode(w http.ResponseWriter, r *http.Request) (*scheduler.PartitionContext, *objects.Node) {
+	vars := mux.Vars(r)
+	partitionName, partitionExists := vars["partition"]
+	if !partitionExists {
+		buildJSONErrorResponse(w, "Partition is missing in URL path. Please check the usage documentation", http.StatusBadRequest)
+		return nil, nil
+	}
+	nodeID, nodeExists := vars["node"]
+	if !nodeExists {
+		buildJSONErrorResponse(w, "Node is missing in URL path. Please check the usage documentation", http.StatusBadRequest)
+		return nil, nil
+	}
+	if len(vars) != 2 {
+		buildJSONErrorResponse(w, "Incorrect URL path. Please check the usage documentation", http.StatusBadRequest)
+		return nil, nil
+	}
+	partition := schedulerContext.GetPartitionWithoutClusterID(partitionName)
+	if partition == nil {
+		buildJSONErrorResponse(w, "Partition not found", http.StatusBadRequest)
+		return nil, nil
+	}
+	node := partition.GetNode(nodeID)
+	if node == nil {
+		buildJSONErrorResponse(w, "Node not found", http.StatusBadRequest)
+		return nil, nil
+	}
+	return partition, node
+}
+
+func writeNodeJSON(w http.ResponseWriter, r *http.Request, node *objects.Node) {
+	nodeDao := getNodeJSON(node)
+	getRedactor(r).redactNode(nodeDao)
+	if err := json.NewEncoder(w).Encode(nodeDao); err != nil {
+		buildJSONErrorResponse(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Update the limits of queues in the partition.
+// The limits of managed queues are also updated in the stored configuration and kept on the next configuration update.
+func updateQueueLimits(w http.ResponseWriter, r *http.Request) {
+	lock.Lock()
+	defer lock.Unlock()
+	vars := mux.Vars(r)
+	writeHeaders(w)
+	partitionName, partitionExists := vars["partition"]
+	if !partitionExists {
+		buildJSONErrorResponse(w, "Partition is missing in URL path. Please check the usage documentation", http.StatusBadRequest)
+		return
+	}
+	if len(vars) != 1 {
+		buildJSONErrorResponse(w, "Incorrect URL path. Please check the usage documentation", http.StatusBadRequest)
+		return
+	}
+	partition := schedulerContext.GetPartitionWithoutClusterID(partitionName)
+	if partition == nil {
+		buildJSONErrorResponse(w, "Partition not found", http.StatusBadRequest)
+		return
+	}
+	var limits []dao.QueueLimitsDAOInfo
+	if err := json.NewDecoder(r.Body).Decode(&limits); err != nil {
+		buildJSONErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	updates := make([]scheduler.QueueLimitUpdate, 0, len(limits))
+	for _, limit := range limits {
+		update := scheduler.QueueLimitUpdate{QueuePath: limit.QueuePath}
+		var err error
+		if limit.MaxResource != nil {
+			if update.MaxResource, err = resources.NewResourceFromConf(limit.MaxResource); err != nil {
+				buildJSONErrorResponse(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		if limit.GuaranteedResource != nil {
+			if update.GuaranteedResource, err = resources.NewResourceFromConf(limit.GuaranteedResource); err != nil {
+				buildJSONErrorResponse(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		updates = append(updates, update)
+	}
+	// only managed queues are part of the configuration, the limits of unmanaged queues are only set in the scheduler
+	managed := make([]dao.QueueLimitsDAOInfo, 0, len(limits))
+	previous := make(map[*objects.Queue]scheduler.QueueLimitUpdate)
+	for _, limit := range limits {
+		if queue := partition.GetQueue(strings.ToLower(limit.QueuePath)); queue != nil {
+			max, guaranteed := queue.GetLimits()
+			previous[queue] = scheduler.QueueLimitUpdate{MaxResource: max, GuaranteedResource: guaranteed}
+			if queue.IsManaged() {
+				managed = append(managed, limit)
+			}
+		}
+	}
+	var content []byte
+	var newConf *configs.SchedulerConfig
+	if len(managed) != 0 {
+		content, newConf = changeStoredConfig(w, partitionName, func(partition *configs.PartitionConfig) error {
+			for _, limit := range managed {
+				queue := partition.GetQueueConfig(limit.QueuePath)
+				if queue == nil {
+					return fmt.Errorf("queue %s not found in the configuration", limit.QueuePath)
+				}
+				if limit.MaxResource != nil {
+					queue.Resources.Max = getConfigLimit(limit.MaxResource)
+				}
+				if limit.GuaranteedResource != nil {
+					queue.Resources.Guaranteed = getConfigLimit(limit.GuaranteedResource)
+				}
+			}
+			return nil
+		})
+		if content == nil {
+			return
+		}
+	}
+	// the limits are checked against the usage and set before the configuration is applied
+	if err := partition.UpdateQueueLimits(updates); err != nil {
+		buildJSONErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if content != nil {
+		if err := applyClusterConfig(content, newConf); err != nil {
+			for queue, limits := range previous {
+				queue.SetLimits(limits.MaxResource, limits.GuaranteedResource)
+			}
+			buildJSONErrorResponse(w, err.Error(), http.StatusConflict)
+			return
+		}
+	}
+	if err := json.NewEncoder(w).Encode(partition.GetPartitionQueues()); err != nil {
+		buildJSONErrorResponse(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Convert a limit from a request into the configuration form: an empty limit removes the limit.
+func getConfigLimit(limit map[string]string) map[string]string {
+	if len(limit) == 0 {
+		return nil
+	}
+	return limit
+}
+
+// Move or rename a queue, and all queues below it, in the partition.
+// A managed queue is also moved in the stored configuration, the move is kept on the next configuration update.
+func moveQueue(w http.ResponseWriter, r *http.Request) {
+	lock.Lock()
+	defer lock.Unlock()
+	vars := mux.Vars(r)
+	writeHeaders(w)
+	partitionName, partitionExists := vars["partition"]
+	if !partitionExists {
+		buildJSONErrorResponse(w, "Partition is missing in URL path. Please check the usage documentation", http.StatusBadRequest)
+		return
+	}
+	queueName, queueNameExists := vars["queue"]
+	if !queueNameExists {
+		buildJSONErrorResponse(w, "Queue is missing in URL path. Please check the usage documentation", http.StatusBadRequest)
+		return
+	}
+	partition := schedulerContext.GetPartitionWithoutClusterID(partitionName)
+	if partition == nil {
+		buildJSONErrorResponse(w, "Partition not found", http.StatusBadRequest)
+		return
+	}
+	var move dao.QueueMoveDAOInfo
+	if err := json.NewDecoder(r.Body).Decode(&move); err != nil {
+		buildJSONErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	// an unmanaged queue is not part of the configuration: only the scheduler is changed
+	var content []byte
+	var newConf *configs.SchedulerConfig
+	if queue := partition.GetQueue(strings.ToLower(queueName)); queue != nil && queue.IsManaged() {
+		content, newConf = changeStoredConfig(w, partitionName, func(partition *configs.PartitionConfig) error {
+			return partition.MoveQueueConfig(queueName, move.NewQueuePath)
+		})
+		if content == nil {
+			return
+		}
+	}
+	if err := partition.MoveQueue(queueName, move.NewQueuePath); err != nil {
+		buildJSONErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	// the queue is moved before the configuration is applied: the configuration update finds the queue in place
+	if content != nil {
+		if err := applyClusterConfig(content, newConf); err != nil {
+			if revertErr := partition.MoveQueue(move.NewQueuePath, queueName); revertErr != nil {
+				err = fmt.Errorf("move failed: %s\nmove rollback failed: %s", err.Error(), revertErr.Error())
+			}
+			buildJSONErrorResponse(w, err.Error(), http.StatusConflict)
+			return
+		}
+	}
+	if err := json.NewEncoder(w).Encode(partition.GetPartitionQueues()); err != nil {
+		buildJSONErrorResponse(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Pause or resume scheduling for a queue, and all queues below it, in the partition.
+// The queue keeps accepting applications while paused. The change is replaced on the next configuration update.
+func updateQueueScheduling(w http.ResponseWriter, r *http.Request) {
+	lock.Lock()
+	defer lock.Unlock()
+	vars := mux.Vars(r)
+	writeHeaders(w)
+	partitionName, partitionExists := vars["partition"]
+	if !partitionExists {
+		buildJSONErrorResponse(w, "Partition is missing in URL path. Please check the usage documentation", http.StatusBadRequest)
+		return
+	}
+	queueName, queueNameExists := vars["queue"]
+	if !queueNameExists {
+		buildJSONErrorResponse(w, "Queue is missing in URL path. Please check the usage documentation", http.StatusBadRequest)
+		return
+	}
+	partition := schedulerContext.GetPartitionWithoutClusterID(partitionName)
+	if partition == nil {
+		buildJSONErrorResponse(w, "Partition not found", http.StatusBadRequest)
+		return
+	}
+	queue := partition.GetQueue(strings.ToLower(queueName))
+	if queue == nil {
+		buildJSONErrorResponse(w, "Queue not found", http.StatusBadRequest)
+		return
+	}
+	var scheduling dao.QueueSchedulingDAOInfo
+	if err := json.NewDecoder(r.Body).Decode(&scheduling); err != nil {
+		buildJSONErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	queue.SetSchedulingPaused(scheduling.Paused)
+	if err := json.NewEncoder(w).Encode(partition.GetPartitionQueues()); err != nil {
+		buildJSONErrorResponse(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Add a managed queue to the partition: the queue is added to the stored configuration.
+func createQueue(w http.ResponseWriter, r *http.Request) {
+	updateQueueConfig(w, r, func(partition *configs.PartitionConfig) error {
+		var change dao.QueueConfigDAOInfo
+		if err := json.NewDecoder(r.Body).Decode(&change); err != nil {
+			return err
+		}
+		return partition.AddQueueConfig(change.QueuePath, configs.QueueConfig{
+			Parent: change.Parent,
+			Resources: configs.Resources{
+				Guaranteed: change.GuaranteedResource,
+				Max:        change.MaxResource,
+			},
+			Properties: change.Properties,
+		})
+	})
+}
+
+// Change the resources or properties of a managed queue in the stored configuration.
+func updateQueue(w http.ResponseWriter, r *http.Request) {
+	updateQueueConfig(w, r, func(partition *configs.PartitionConfig) error {
+		var change dao.QueueConfigDAOInfo
+		if err := json.NewDecoder(r.Body).Decode(&change); err != nil {
+			return err
+		}
+		queue := partition.GetQueueConfig(change.QueuePath)
+		if queue == nil {
+			return fmt.Errorf("queue %s not found in the configuration", change.QueuePath)
+		}
+		if change.MaxResource != nil {
+			queue.Resources.Max = change.MaxResource
+		}
+		if change.GuaranteedResource != nil {
+			queue.Resources.Guaranteed = change.GuaranteedResource
+		}
+		if change.Properties != nil {
+			queue.Properties = change.Properties
+		}
+		return nil
+	})
+}
+
+// Remove a managed queue from the stored configuration: ?queue=root.parent.leaf
+// The queue is marked for removal and removed when it is empty, like a queue removed from the config file.
+func deleteQueue(w http.ResponseWriter, r *http.Request) {
+	updateQueueConfig(w, r, func(partition *configs.PartitionConfig) error {
+		queuePath := r.URL.Query().Get("queue")
+		if queuePath == "" {
+			return fmt.Errorf("queue query param is missing. Please check the usage documentation")
+		}
+		return partition.RemoveQueueConfig(queuePath)
+	})
+}
+
+// Merge a queue change into a copy of the stored configuration and apply it as a configuration update.
+func updateQueueConfig(w http.ResponseWriter, r *http.Request, change func(partition *configs.PartitionConfig) error) {
+	lock.Lock()
+	defer lock.Unlock()
+	vars := mux.Vars(r)
+	writeHeaders(w)
+	partitionName, partitionExists := vars["partition"]
+	if !partitionExists {
+		buildJSONErrorResponse(w, "Partition is missing in URL path. Please check the usage documentation", http.StatusBadRequest)
+		return
+	}
+	if len(vars) != 1 {
+		buildJSONErrorResponse(w, "Incorrect URL path. Please check the usage documentation", http.StatusBadRequest)
+		return
+	}
+	partition := schedulerContext.GetPartitionWithoutClusterID(partitionName)
+	if partition == nil {
+		buildJSONErrorResponse(w, "Partition not found", http.StatusBadRequest)
+		return
+	}
+	content, newConf := changeStoredConfig(w, partitionName, change)
+	if content == nil {
+		return
+	}
+	if err := applyClusterConfig(content, newConf); err != nil {
+		buildJSONErrorResponse(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err := json.NewEncoder(w).Encode(partition.GetPartitionQueues()); err != nil {
+		buildJSONErrorResponse(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Make a change to the partition in a copy of the stored configuration.
+// Returns the changed content to store and the validated configuration for the scheduler.
+// Returns nil values after writing the error response if the change or the validation fails.
+func changeStoredConfig(w http.ResponseWriter, partitionName string, change func(partition *configs.PartitionConfig) error) ([]byte, *configs.SchedulerConfig) {
+	conf, err := copyCurrentConfig()
+	if err != nil {
+		buildJSONErrorResponse(w, err.Error(), http.StatusInternalServerError)
+		return nil, nil
+	}
+	partitionConf := conf.GetPartitionConfig(partitionName)
+	if partitionConf == nil {
+		buildJSONErrorResponse(w, "Partition not found in the configuration", http.StatusBadRequest)
+		return nil, nil
+	}
+	if err = change(partitionConf); err != nil {
+		buildJSONErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return nil, nil
+	}
+	var content []byte
+	content, err = yaml.Marshal(conf)
+	if err != nil {
+		buildJSONErrorResponse(w, err.Error(), http.StatusInternalServerError)
+		return nil, nil
+	}
+	// the changed configuration must pass the same validation as a configuration update: the validation is
+	// done on a copy, the content stored is the changed configuration without the validation changes
+	var newConf *configs.SchedulerConfig
+	newConf, err = configs.LoadSchedulerConfigFromByteArray(content)
+	if err != nil {
+		buildJSONErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return nil, nil
+	}
+	return content, newConf
+}
+
+// Get a copy of the stored configuration without the checksum. Changes are made to the configuration as stored,
+// not to the current configuration which has the validation defaults and name changes applied.
+func copyCurrentConfig() (*configs.SchedulerConfig, error) {
+	current := configs.ConfigContext.Get(schedulerContext.GetPolicyGroup())
+	if current == nil || current.GetContent() == nil {
+		return nil, fmt.Errorf("no configuration loaded")
+	}
+	return configs.ParseStoredConfig(current.GetContent())
+}
+
+// Check if a user can submit an application to a queue.
+// The user is required, the groups are optional and resolved if not provided: ?user=name&groups=group1,group2
+func checkQueueAccess(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	writeHeaders(w)
+	partitionName, partitionExists := vars["partition"]
+	if !partitionExists {
+		buildJSONErrorResponse(w, "Partition is missing in URL path. Please check the usage documentation", http.StatusBadRequest)
+		return
+	}
+	queueName, queueNameExists := vars["queue"]
+	if !queueNameExists {
+		buildJSONErrorResponse(w, "Queue is missing in URL path. Please check the usage documentation", http.StatusBadRequest)
+		return
+	}
+	if len(vars) != 2 {
+		buildJSONErrorResponse(w, "Incorrect URL path. Please check the usage documentation", http.StatusBadRequest)
+		return
+	}
+	userName := r.URL.Query().Get("user")
+	if userName == "" {
+		buildJSONErrorResponse(w, "User is missing in the query. Please check the usage documentation", http.StatusBadRequest)
+		return
+	}
+	partition := schedulerContext.GetPartitionWithoutClusterID(partitionName)
+	if partition == nil {
+		buildJSONErrorResponse(w, "Partition not found", http.StatusBadRequest)
+		return
+	}
+	queue := partition.GetQueue(queueName)
+	if queue == nil {
+		buildJSONErrorResponse(w, "Queue not found", http.StatusBadRequest)
+		return
+	}
+	var user security.UserGroup
+	var err error
+	if groups := r.URL.Query().Get("groups"); groups != "" {
+		user = security.UserGroup{User: userName, Groups: strings.Split(groups, ",")}
+	} else if user, err = security.GetUserGroupCache("").GetUserGroup(userName); err != nil {
+		// a failed resolution still returns the user, check access without groups
+		log.Logger().Debug("group resolution failed for access check",
+			zap.String("user", userName),
+			zap.Error(err))
+	}
+	accessDao := dao.QueueAccessDAOInfo{
+		QueuePath:    queue.QueuePath,
+		User:         user.User,
+		Groups:       user.Groups,
+		Allowed:      queue.CheckSubmitAccess(user),
+		Rule:         getAccessRuleDAO(queue.GetSubmitAccessRule(user)),
+		AdminAllowed: queue.CheckAdminAccess(user),
+		AdminRule:    getAccessRuleDAO(queue.GetAdminAccessRule(user)),
+	}
+	if err = json.NewEncoder(w).Encode(accessDao); err != nil {
+		buildJSONErrorResponse(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func getAccessRuleDAO(rule *objects.AccessRule) *dao.QueueAccessRuleDAOInfo {
+	if rule == nil {
+		return nil
+	}
+	return &dao.QueueAccessRuleDAOInfo{
+		QueuePath: rule.QueuePath,
+		ACL:       rule.ACL,
+		Entry:     rule.Entry,
+	}
+}
+
 func getQueueApplications(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	writeHeaders(w)
@@ -687,29 +1589,64 @@ func getQueueApplications(w http.ResponseWriter, r *http.Request) {
 		buildJSONErrorResponse(w, "Incorrect URL path. Please check the usage documentation", http.StatusBadRequest)
 		return
 	}
-	var appExistsInPartition = false
-	var appExistsInQueue = false
-	var appsDao []*dao.ApplicationDAOInfo
 	partitionContext := schedulerContext.GetPartitionWithoutClusterID(partition)
-	if partitionContext != nil {
-		appExistsInPartition = true
-		appList := partitionContext.GetApplications()
-		for _, app := range appList {
-			if strings.EqualFold(queueName, app.GetQueueName()) {
-				appExistsInQueue = true
-				appsDao = append(appsDao, getApplicationJSON(app))
-			}
+	if partitionContext == nil {
+		buildJSONErrorResponse(w, "Partition not found", http.StatusBadRequest)
+		return
+	}
+	var apps []*objects.Application
+	for _, app := range partitionContext.GetApplications() {
+		if strings.EqualFold(queueName, app.GetQueueName()) {
+			apps = append(apps, app)
 		}
 	}
-	if !appExistsInPartition {
+	if len(apps) == 0 {
+		buildJSONErrorResponse(w, "Queue not found", http.StatusBadRequest)
+		return
+	}
+	// the applications are streamed, only one DAO object exists at a time
+	rd := getRedactor(r)
+	stream := newJSONArrayStream(w, r)
+	for _, app := range apps {
+		appDao := getApplicationJSON(app)
+		rd.redactApplication(appDao)
+		stream.add(appDao)
+	}
+	logStreamError(r, stream.close(true))
+}
+
+// Kill an application: the application is failed, its asks and reservations are removed and all its allocations are
+// released back to the RM. The killed application is returned.
+func killApplication(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	writeHeaders(w)
+	partitionName, partitionExists := vars["partition"]
+	if !partitionExists {
+		buildJSONErrorResponse(w, "Partition is missing in URL path. Please check the usage documentation", http.StatusBadRequest)
+		return
+	}
+	appID, appIDExists := vars["appID"]
+	if !appIDExists {
+		buildJSONErrorResponse(w, "Application is missing in URL path. Please check the usage documentation", http.StatusBadRequest)
+		return
+	}
+	if len(vars) != 2 {
+		buildJSONErrorResponse(w, "Incorrect URL path. Please check the usage documentation", http.StatusBadRequest)
+		return
+	}
+	partition := schedulerContext.GetPartitionWithoutClusterID(partitionName)
+	if partition == nil {
 		buildJSONErrorResponse(w, "Partition not found", http.StatusBadRequest)
 		return
 	}
-	if !appExistsInQueue {
-		buildJSONErrorResponse(w, "Queue not found", http.StatusBadRequest)
+	app, err := schedulerContext.KillApplication(partition, appID)
+	if err != nil {
+		buildJSONErrorResponse(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	if err := json.NewEncoder(w).Encode(appsDao); err != nil {
+	appDao := getApplicationJSON(app)
+	getRedactor(r).redactApplication(appDao)
+	if err = json.NewEncoder(w).Encode(appDao); err != nil {
 		buildJSONErrorResponse(w, err.Error(), http.StatusInternalServerError)
 	}
 }
@@ -27,6 +27,7 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -35,10 +36,12 @@ import (
 
 	"github.com/apache/incubator-yunikorn-core/pkg/common"
 	"github.com/apache/incubator-yunikorn-core/pkg/common/configs"
+	"github.com/apache/incubator-yunikorn-core/pkg/common/features"
 	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
 	"github.com/apache/incubator-yunikorn-core/pkg/common/security"
 	"github.com/apache/incubator-yunikorn-core/pkg/metrics/history"
 	"github.com/apache/incubator-yunikorn-core/pkg/plugins"
+	"github.com/apache/incubator-yunikorn-core/pkg/readiness"
 	"github.com/apache/incubator-yunikorn-core/pkg/scheduler"
 	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/objects"
 	"github.com/apache/incubator-yunikorn-core/pkg/webservice/dao"
@@ -218,6 +221,45 @@ func TestValidateConf(t *testing.T) {
 	}
 }
 
+func TestValidateConfDiagnostics(t *testing.T) {
+	content := `
+partitions:
+  - name: default
+    queues:
+      - name: root
+        unknown: value
+        queues:
+          - name: acl
+            submitacl: "user1 group1 other"
+          - name: limited
+            resources:
+              max:
+                memory: 10
+            limits:
+              - limit: large limit
+                users:
+                  - user1
+                maxresources:
+                  memory: 20
+`
+	// No err check: new request always returns correctly
+	//nolint: errcheck
+	req, _ := http.NewRequest("POST", "", strings.NewReader(content))
+	resp := &MockResponseWriter{}
+	validateConf(resp, req)
+	var vcr dao.ValidateConfResponse
+	err := json.Unmarshal(resp.outputBytes, &vcr)
+	assert.NilError(t, err, "failed to unmarshal ValidateConfResponse from response body")
+	assert.Assert(t, !vcr.Allowed, "config should not be allowed")
+	assert.Assert(t, vcr.Reason != "", "reason should be set")
+	assert.Equal(t, len(vcr.Errors), 2, "unexpected errors: %v", vcr.Errors)
+	assert.Equal(t, vcr.Errors[0].Line, 6, "unknown field should be reported first")
+	assert.Equal(t, vcr.Errors[1].Path, "default.root.acl", "ACL error should have the queue path")
+	assert.Equal(t, vcr.Errors[1].Line, 9, "ACL error should have the line")
+	assert.Equal(t, len(vcr.Warnings), 1, "unexpected warnings: %v", vcr.Warnings)
+	assert.Equal(t, vcr.Warnings[0].Path, "default.root.limited", "limit warning should have the queue path")
+}
+
 func TestApplicationHistory(t *testing.T) {
 	// make sure the history is nil when we finish this test
 	defer ResetIMHistory()
@@ -328,6 +370,106 @@ func TestContainerHistory(t *testing.T) {
 	assert.Equal(t, contHist[4].TotalContainers, "300", "metric 5 should be 300 apps and was not")
 }
 
+func TestQueueForecast(t *testing.T) {
+	// make sure the history is nil when we finish this test
+	defer ResetIMHistory()
+	// No err check: new request always returns correctly
+	//nolint: errcheck
+	req, _ := http.NewRequest("GET", "/ws/v1/reports/forecast", strings.NewReader(""))
+	resp := &MockResponseWriter{}
+	// no init should return nothing
+	getQueueForecast(resp, req)
+	var errInfo dao.YAPIError
+	err := json.Unmarshal(resp.outputBytes, &errInfo)
+	assert.NilError(t, err, "failed to unmarshal forecast response from response body: %s", string(resp.outputBytes))
+	assert.Equal(t, http.StatusNotImplemented, resp.statusCode, "forecast handler returned wrong status")
+
+	// empty history returns no queues
+	imHistory = history.NewInternalMetricsHistory(5)
+	resp = &MockResponseWriter{}
+	getQueueForecast(resp, req)
+	var forecast dao.QueuesForecastDAOInfo
+	err = json.Unmarshal(resp.outputBytes, &forecast)
+	assert.NilError(t, err, "failed to unmarshal forecast response from response body: %s", string(resp.outputBytes))
+	assert.Equal(t, forecast.Hours, defaultForecastHours, "unexpected default horizon")
+	assert.Equal(t, len(forecast.Queues), 0, "empty history must have no queues")
+
+	// invalid horizon
+	//nolint: errcheck
+	req, _ = http.NewRequest("GET", "/ws/v1/reports/forecast?hours=-1", strings.NewReader(""))
+	resp = &MockResponseWriter{}
+	getQueueForecast(resp, req)
+	assert.Equal(t, http.StatusBadRequest, resp.statusCode, "invalid hours should be rejected")
+
+	// stored usage is returned
+	usage := &history.QueueUsage{
+		Partition:   "default",
+		QueuePath:   "root.default",
+		Allocated:   resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 10}),
+		MaxResource: resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 5}),
+	}
+	imHistory.StoreQueues([]*history.QueueUsage{usage})
+	//nolint: errcheck
+	req, _ = http.NewRequest("GET", "/ws/v1/reports/forecast?hours=4", strings.NewReader(""))
+	resp = &MockResponseWriter{}
+	getQueueForecast(resp, req)
+	err = json.Unmarshal(resp.outputBytes, &forecast)
+	assert.NilError(t, err, "failed to unmarshal forecast response from response body: %s", string(resp.outputBytes))
+	assert.Equal(t, forecast.Hours, 4, "unexpected horizon")
+	assert.Equal(t, len(forecast.Queues), 1, "expected one queue in the forecast")
+	assert.Equal(t, forecast.Queues[0].QueueName, "root.default", "unexpected queue")
+	assert.Equal(t, forecast.Queues[0].Samples, 1, "unexpected number of samples")
+	assert.Assert(t, forecast.Queues[0].ExceedsMax, "queue over the max should be flagged")
+}
+
+func TestUserFairness(t *testing.T) {
+	// make sure the history is nil when we finish this test
+	defer ResetIMHistory()
+	// No err check: new request always returns correctly
+	//nolint: errcheck
+	req, _ := http.NewRequest("GET", "/ws/v1/reports/user-fairness", strings.NewReader(""))
+	resp := &MockResponseWriter{}
+	// no init should return nothing
+	getUserFairness(resp, req)
+	assert.Equal(t, http.StatusNotImplemented, resp.statusCode, "fairness handler returned wrong status")
+
+	imHistory = history.NewInternalMetricsHistory(5)
+	// invalid window
+	//nolint: errcheck
+	req, _ = http.NewRequest("GET", "/ws/v1/reports/user-fairness?window=hour", strings.NewReader(""))
+	resp = &MockResponseWriter{}
+	getUserFairness(resp, req)
+	assert.Equal(t, http.StatusBadRequest, resp.statusCode, "invalid window should be rejected")
+
+	imHistory.StoreQueues([]*history.QueueUsage{
+		{
+			Partition: "default",
+			QueuePath: "root.default",
+			Users: map[string]*resources.Resource{
+				"user1": resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 30}),
+				"user2": resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 10}),
+			},
+		},
+	})
+	//nolint: errcheck
+	req, _ = http.NewRequest("GET", "/ws/v1/reports/user-fairness?window=30m", strings.NewReader(""))
+	resp = &MockResponseWriter{}
+	getUserFairness(resp, req)
+	var report dao.UserFairnessReportDAOInfo
+	err := json.Unmarshal(resp.outputBytes, &report)
+	assert.NilError(t, err, "failed to unmarshal fairness response from response body: %s", string(resp.outputBytes))
+	assert.Equal(t, report.Window, "30m0s", "unexpected window")
+	assert.Equal(t, len(report.Queues), 1, "expected one queue in the report")
+	queue := report.Queues[0]
+	assert.Equal(t, queue.QueueName, "root.default", "unexpected queue")
+	assert.Equal(t, len(queue.Users), 2, "expected both users in the report")
+	assert.Equal(t, queue.Users[0].User, "user1", "most over served user should be first")
+	assert.Equal(t, queue.Users[0].Share, 0.75, "unexpected share")
+	assert.Equal(t, queue.Users[0].Ratio, 1.5, "unexpected ratio")
+	assert.Equal(t, queue.MostOverServed, "user1")
+	assert.Equal(t, queue.MostUnderServed, "user2")
+}
+
 func TestGetConfigYAML(t *testing.T) {
 	configs.MockSchedulerConfigByData([]byte(startConf))
 	var err error
@@ -383,6 +525,7 @@ func TestGetConfigJSON(t *testing.T) {
 	err = schedulerContext.UpdateRMSchedulerConfig(rmID)
 	assert.NilError(t, err, "Error when updating clusterInfo from config")
 
+	resp = &MockResponseWriter{}
 	getClusterConfig(resp, req)
 	err = json.Unmarshal(resp.outputBytes, conf)
 	assert.NilError(t, err, "failed to unmarshal config from response body (json, updated config)")
@@ -558,6 +701,133 @@ func TestUpdateConfigWrongChecksum(t *testing.T) {
 	assert.Equal(t, errInfo.StatusCode, http.StatusConflict)
 }
 
+func TestConfigHistoryRollback(t *testing.T) {
+	prepareSchedulerForConfigChange(t)
+	startChecksum := configs.ConfigContext.Get(schedulerContext.GetPolicyGroup()).Checksum
+	resp := &MockResponseWriter{}
+	req, err := http.NewRequest("PUT", "", strings.NewReader(appendChecksum(updatedConf, startChecksum)))
+	assert.NilError(t, err, "Failed to create the request")
+	updateClusterConfig(resp, req)
+	assert.Equal(t, http.StatusOK, resp.statusCode, "No error expected")
+
+	resp = &MockResponseWriter{}
+	getClusterConfigHistory(resp, req)
+	var versions []dao.ConfigVersionDAOInfo
+	err = json.Unmarshal(resp.outputBytes, &versions)
+	assert.NilError(t, err, "failed to unmarshal config history from response body: %s", string(resp.outputBytes))
+	assert.Assert(t, len(versions) >= 2, "history should contain the start and updated config")
+	last := versions[len(versions)-1]
+	assert.Assert(t, last.Current, "last version should be the current config")
+	assert.Assert(t, last.Checksum != startChecksum, "last version should be the updated config")
+	var startVersion uint64
+	for _, version := range versions {
+		if version.Checksum == startChecksum {
+			startVersion = version.Version
+		}
+	}
+	assert.Assert(t, startVersion != 0, "start config should be in the history")
+
+	// roll back to the start config
+	resp = &MockResponseWriter{}
+	req, err = http.NewRequest("POST", "", strings.NewReader(fmt.Sprintf("{\"version\": %d}", startVersion)))
+	assert.NilError(t, err, "Failed to create the request")
+	rollbackClusterConfig(resp, req)
+	assert.Equal(t, http.StatusOK, resp.statusCode, "rollback should have succeeded: %s", string(resp.outputBytes))
+	current := configs.ConfigContext.Get(schedulerContext.GetPolicyGroup())
+	assert.Equal(t, current.Partitions[0].NodeSortPolicy.Type, "fair", "node sort policy not rolled back")
+	assert.Equal(t, current.Partitions[0].Queues[0].Properties["second"], "somethingElse", "queue properties not rolled back")
+	assert.Equal(t, string(current.GetContent()), startConf, "stored content not rolled back")
+	partition := schedulerContext.GetPartition(common.GetNormalizedPartitionName("default", rmID))
+	assert.Equal(t, partition.GetNodeSortingPolicyName(), "fair", "scheduler not updated on rollback")
+
+	// unknown version
+	resp = &MockResponseWriter{}
+	req, err = http.NewRequest("POST", "", strings.NewReader("{\"version\": 0}"))
+	assert.NilError(t, err, "Failed to create the request")
+	rollbackClusterConfig(resp, req)
+	assert.Equal(t, http.StatusBadRequest, resp.statusCode, "unknown version should fail")
+}
+
+func queueConfigRequest(t *testing.T, handler http.HandlerFunc, method, url, body string) *MockResponseWriter {
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	assert.NilError(t, err, "Failed to create the request")
+	req = mux.SetURLVars(req, map[string]string{"partition": partitionNameWithoutClusterID})
+	resp := &MockResponseWriter{}
+	handler(resp, req)
+	return resp
+}
+
+// a successful change returns the queues of the partition
+func assertQueueConfigChanged(t *testing.T, resp *MockResponseWriter) {
+	var queues dao.PartitionQueueDAOInfo
+	err := json.Unmarshal(resp.outputBytes, &queues)
+	assert.NilError(t, err, "failed to unmarshal queues from response body: %s", string(resp.outputBytes))
+	assert.Equal(t, queues.QueueName, "root", "change should have returned the partition queues: %s", string(resp.outputBytes))
+}
+
+func TestQueueConfigManagement(t *testing.T) {
+	prepareSchedulerForConfigChange(t)
+	partition := schedulerContext.GetPartition(common.GetNormalizedPartitionName("default", rmID))
+
+	resp := queueConfigRequest(t, createQueue, "POST", "/ws/v1/partition/default/queues", `{"queuePath": "root.a", "maxResource": {"memory": "100"}}`)
+	assertQueueConfigChanged(t, resp)
+	queue := partition.GetQueue("root.a")
+	assert.Assert(t, queue != nil, "queue should have been created")
+	assert.Assert(t, resources.Equals(queue.GetMaxResource(), resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 100})), "unexpected max resource")
+	conf := configs.ConfigContext.Get(schedulerContext.GetPolicyGroup())
+	assert.Assert(t, conf.GetPartitionConfig("default").GetQueueConfig("root.a") != nil, "queue should have been merged into the stored config")
+	assert.Equal(t, conf.Partitions[0].Queues[0].Properties["second"], "somethingElse", "existing config should be kept")
+
+	resp = queueConfigRequest(t, createQueue, "POST", "/ws/v1/partition/default/queues", `{"queuePath": "root.a"}`)
+	assert.Equal(t, http.StatusBadRequest, resp.statusCode, "duplicate create should fail")
+	resp = queueConfigRequest(t, createQueue, "POST", "/ws/v1/partition/default/queues", `{"queuePath": "root.b", "maxResource": {"memory": "10"}, "guaranteedResource": {"memory": "20"}}`)
+	assert.Equal(t, http.StatusBadRequest, resp.statusCode, "invalid config should fail validation")
+	assert.Assert(t, partition.GetQueue("root.b") == nil, "invalid queue should not have been created")
+
+	resp = queueConfigRequest(t, updateQueue, "PUT", "/ws/v1/partition/default/queues", `{"queuePath": "root.a", "properties": {"application.sort.policy": "fifo"}}`)
+	assertQueueConfigChanged(t, resp)
+	assert.Equal(t, queue.GetQueueInfos().Properties["application.sort.policy"], "fifo", "property should have been applied")
+	assert.Assert(t, resources.Equals(queue.GetMaxResource(), resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 100})), "omitted max resource should not change")
+	resp = queueConfigRequest(t, updateQueue, "PUT", "/ws/v1/partition/default/queues", `{"queuePath": "root.unknown"}`)
+	assert.Equal(t, http.StatusBadRequest, resp.statusCode, "update of unknown queue should fail")
+
+	resp = queueConfigRequest(t, deleteQueue, "DELETE", "/ws/v1/partition/default/queues", "")
+	assert.Equal(t, http.StatusBadRequest, resp.statusCode, "delete without queue should fail")
+	resp = queueConfigRequest(t, deleteQueue, "DELETE", "/ws/v1/partition/default/queues?queue=root.a", "")
+	assertQueueConfigChanged(t, resp)
+	assert.Assert(t, queue.IsDraining(), "queue should have been marked for removal")
+	conf = configs.ConfigContext.Get(schedulerContext.GetPolicyGroup())
+	assert.Assert(t, conf.GetPartitionConfig("default").GetQueueConfig("root.a") == nil, "queue should have been removed from the stored config")
+}
+
+func TestQueueConfigStoredContent(t *testing.T) {
+	plugins.RegisterSchedulerPlugin(&FakeConfigPlugin{generateError: false})
+	configs.MockSchedulerConfigByData([]byte(`
+partitions:
+  - name: Default
+    queues:
+      - name: Parent
+        parent: true
+`))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load clusterInfo from config")
+
+	resp := queueConfigRequest(t, createQueue, "POST", "/ws/v1/partition/default/queues", `{"queuePath": "root.parent.leaf"}`)
+	assertQueueConfigChanged(t, resp)
+	partition := schedulerContext.GetPartition(common.GetNormalizedPartitionName("default", rmID))
+	assert.Assert(t, partition.GetQueue("root.parent.leaf") != nil, "queue should have been created")
+	// the change is made to the configuration as stored, not to the validated configuration
+	conf := configs.ConfigContext.Get(schedulerContext.GetPolicyGroup())
+	var stored *configs.SchedulerConfig
+	stored, err = configs.ParseStoredConfig(conf.GetContent())
+	assert.NilError(t, err, "stored content should parse")
+	assert.Equal(t, stored.Partitions[0].Name, "Default", "stored partition name should not be normalised")
+	assert.Equal(t, stored.Partitions[0].Queues[0].Queues[0].Name, "Parent", "stored queue name should not be normalised")
+	assert.Assert(t, stored.Partitions[0].GetQueueConfig("root.parent.leaf") != nil, "queue should have been added to the stored content")
+	assert.Equal(t, conf.Partitions[0].Name, "default", "current config should be validated")
+}
+
 func appendChecksum(conf string, checksum string) string {
 	conf += "checksum: " + checksum
 	return conf
@@ -802,6 +1072,8 @@ func TestPartitions(t *testing.T) {
 			assert.Equal(t, part.Applications[objects.Completed.String()], 1)
 			assert.Equal(t, part.Applications[objects.Failed.String()], 1)
 			assert.Equal(t, part.State, "Active")
+			assert.Assert(t, part.SchedulingEnabled, "scheduling should be enabled")
+			assert.Assert(t, part.LastConfigReloadTime != "", "config reload time should be set")
 		} else {
 			assert.Equal(t, part.Name, "[rm-123]gpu")
 			assert.Equal(t, part.NodeSortingPolicy, "fair")
@@ -810,6 +1082,139 @@ func TestPartitions(t *testing.T) {
 	}
 }
 
+func TestUpdatePartitionScheduling(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(configDefault))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+	partition := schedulerContext.GetPartition(common.GetNormalizedPartitionName("default", rmID))
+	NewWebApp(schedulerContext, nil)
+
+	schedulingRequest := func(partitionName, body string) *MockResponseWriter {
+		req, reqErr := http.NewRequest("PUT", "/ws/v1/partition/"+partitionName+"/scheduling", strings.NewReader(body))
+		assert.NilError(t, reqErr, "Partition scheduling request failed")
+		req = mux.SetURLVars(req, map[string]string{"partition": partitionName})
+		resp := &MockResponseWriter{}
+		updatePartitionScheduling(resp, req)
+		return resp
+	}
+
+	resp := schedulingRequest(partitionNameWithoutClusterID, `{"enabled": false}`)
+	var partitionInfo dao.PartitionInfo
+	err = json.Unmarshal(resp.outputBytes, &partitionInfo)
+	assert.NilError(t, err, "failed to unmarshal partition dao response from response body: %s", string(resp.outputBytes))
+	assert.Assert(t, !partitionInfo.SchedulingEnabled, "scheduling should be disabled in the response")
+	assert.Assert(t, !partition.IsSchedulingEnabled(), "scheduling should be disabled")
+
+	resp = schedulingRequest(partitionNameWithoutClusterID, `{"enabled": true}`)
+	err = json.Unmarshal(resp.outputBytes, &partitionInfo)
+	assert.NilError(t, err, "failed to unmarshal partition dao response from response body: %s", string(resp.outputBytes))
+	assert.Assert(t, partitionInfo.SchedulingEnabled, "scheduling should be enabled in the response")
+	assert.Assert(t, partition.IsSchedulingEnabled(), "scheduling should be enabled")
+
+	// unknown partition
+	resp = schedulingRequest("unknown", `{"enabled": false}`)
+	var errInfo dao.YAPIError
+	err = json.Unmarshal(resp.outputBytes, &errInfo)
+	assert.NilError(t, err, "failed to unmarshal error response from response body")
+	assert.Equal(t, resp.statusCode, http.StatusBadRequest, "Incorrect Status code")
+	assert.Equal(t, errInfo.Message, "Partition not found", "JSON error message is incorrect")
+
+	// invalid body
+	resp = schedulingRequest(partitionNameWithoutClusterID, `{`)
+	assert.Equal(t, resp.statusCode, http.StatusBadRequest, "Incorrect Status code")
+	assert.Assert(t, partition.IsSchedulingEnabled(), "scheduling should not have changed")
+}
+
+func TestGetRMInfo(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(configMultiPartitions))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load clusterInfo from config")
+	NewWebApp(schedulerContext, nil)
+
+	var req *http.Request
+	req, err = http.NewRequest("GET", "/ws/v1/rms", strings.NewReader(""))
+	assert.NilError(t, err, "RM info request failed")
+	resp := &MockResponseWriter{}
+	var rmInfo []dao.RMDAOInfo
+	getRMInfo(resp, req)
+	err = json.Unmarshal(resp.outputBytes, &rmInfo)
+	assert.NilError(t, err, "failed to unmarshal RM info dao response from response body: %s", string(resp.outputBytes))
+	assert.Equal(t, len(rmInfo), 1, "expected one RM")
+	assert.Equal(t, rmInfo[0].RmID, rmID, "unexpected RM ID")
+	assert.Equal(t, rmInfo[0].Weight, 1.0, "unexpected RM weight")
+	assert.DeepEqual(t, rmInfo[0].Partitions, []string{"[rm-123]default", "[rm-123]gpu"})
+}
+
+func TestGetStatus(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(configMultiPartitions))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load clusterInfo from config")
+	NewWebApp(schedulerContext, nil)
+
+	var req *http.Request
+	req, err = http.NewRequest("GET", "/ws/v1/status", strings.NewReader(""))
+	assert.NilError(t, err, "status request failed")
+	resp := &MockResponseWriter{}
+	var status dao.StatusDAOInfo
+	getStatus(resp, req)
+	err = json.Unmarshal(resp.outputBytes, &status)
+	assert.NilError(t, err, "failed to unmarshal status dao response from response body: %s", string(resp.outputBytes))
+	assert.Equal(t, status.RegisteredRMs, 1, "expected one RM")
+	assert.Equal(t, status.State, scheduler.SchedulingStarting, "unexpected scheduling state")
+	assert.Assert(t, status.StartTime != 0, "start time should be set")
+	assert.Equal(t, status.ConfigChecksum, configs.ConfigContext.Get(policyGroup).Checksum, "unexpected config checksum")
+}
+
+func TestGetReadiness(t *testing.T) {
+	readiness.Reset()
+	defer readiness.Reset()
+	status := readiness.StatusNotReady
+	readiness.Register("test", func() (string, string) { return status, "test message" })
+
+	req, err := http.NewRequest("GET", "/ws/v1/readiness", strings.NewReader(""))
+	assert.NilError(t, err, "readiness request failed")
+	resp := &MockResponseWriter{}
+	getReadiness(resp, req)
+	assert.Equal(t, resp.statusCode, http.StatusServiceUnavailable, "not ready should return unavailable")
+	var report dao.ReadinessDAOInfo
+	err = json.Unmarshal(resp.outputBytes, &report)
+	assert.NilError(t, err, "failed to unmarshal readiness dao response from response body: %s", string(resp.outputBytes))
+	assert.Assert(t, !report.Ready, "report should not be ready")
+	assert.Equal(t, len(report.Subsystems), 1, "unexpected number of subsystems")
+	assert.Equal(t, report.Subsystems[0].Message, "test message", "unexpected message")
+
+	status = readiness.StatusReady
+	resp = &MockResponseWriter{}
+	getReadiness(resp, req)
+	assert.Equal(t, resp.statusCode, 0, "ready should not set an error status")
+	err = json.Unmarshal(resp.outputBytes, &report)
+	assert.NilError(t, err, "failed to unmarshal readiness dao response from response body: %s", string(resp.outputBytes))
+	assert.Assert(t, report.Ready, "report should be ready")
+}
+
+func TestGetFeatureGates(t *testing.T) {
+	assert.NilError(t, features.SetGates("Preemption=false"), "failed to set feature gates")
+	defer func() {
+		assert.NilError(t, features.SetGates(""), "failed to reset feature gates")
+	}()
+	req, err := http.NewRequest("GET", "/ws/v1/featuregates", strings.NewReader(""))
+	assert.NilError(t, err, "feature gates request failed")
+	resp := &MockResponseWriter{}
+	var gatesInfo []dao.FeatureGateDAOInfo
+	getFeatureGates(resp, req)
+	err = json.Unmarshal(resp.outputBytes, &gatesInfo)
+	assert.NilError(t, err, "failed to unmarshal feature gates dao response from response body: %s", string(resp.outputBytes))
+	expected := []dao.FeatureGateDAOInfo{
+		{Name: features.AdaptiveCycles, Enabled: false, Default: false},
+		{Name: features.GangScheduling, Enabled: true, Default: true},
+		{Name: features.Preemption, Enabled: false, Default: true},
+	}
+	assert.DeepEqual(t, gatesInfo, expected)
+}
+
 func TestCreateClusterConfig(t *testing.T) {
 	confTests := []struct {
 		content          string
@@ -948,6 +1353,61 @@ func TestGetPartitionQueuesHandler(t *testing.T) {
 	assertPartitionExists(t, resp)
 }
 
+func TestGetPartitionQueue(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(configDefault))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load clusterInfo from config")
+	partitionName := common.GetNormalizedPartitionName("default", rmID)
+	part := schedulerContext.GetPartition(partitionName)
+	app := newApplication("app-1", partitionName, queueName, rmID)
+	err = part.AddApplication(app)
+	assert.NilError(t, err, "failed to add application")
+	app.SetState(objects.Running.String())
+	err = app.AddAllocationAsk(objects.NewAllocationAsk(&si.AllocationAsk{
+		AllocationKey:  "alloc-1",
+		ApplicationID:  "app-1",
+		ResourceAsk:    &si.Resource{Resources: map[string]*si.Quantity{"memory": {Value: 100}}},
+		MaxAllocations: 1,
+	}))
+	assert.NilError(t, err, "failed to add ask")
+	NewWebApp(schedulerContext, nil)
+
+	queueRequest := func(partition, queue string) *MockResponseWriter {
+		req, reqErr := http.NewRequest("GET", "/ws/v1/partition/"+partition+"/queue/"+queue, strings.NewReader(""))
+		assert.NilError(t, reqErr, "Get queue request failed")
+		req = mux.SetURLVars(req, map[string]string{"partition": partition, "queue": queue})
+		resp := &MockResponseWriter{}
+		getPartitionQueue(resp, req)
+		return resp
+	}
+
+	resp := queueRequest(partitionNameWithoutClusterID, "root")
+	var queueDao dao.PartitionQueueDAOInfo
+	err = json.Unmarshal(resp.outputBytes, &queueDao)
+	assert.NilError(t, err, "failed to unmarshal queue dao response from response body: %s", string(resp.outputBytes))
+	assert.Equal(t, queueDao.QueueName, "root")
+	assert.Equal(t, queueDao.Parent, "")
+	assert.Equal(t, len(queueDao.Children), 1, "expected the child queue in the response")
+	assert.Equal(t, queueDao.Children[0].QueueName, queueName)
+	assert.Equal(t, queueDao.RunningApps, 1, "running application of the child should be counted")
+	assert.Equal(t, queueDao.PendingResource, "[memory:100]")
+	assert.Equal(t, queueDao.Children[0].RunningApps, 1)
+	assert.Equal(t, queueDao.Children[0].PendingResource, "[memory:100]")
+
+	// unknown queue
+	resp = queueRequest(partitionNameWithoutClusterID, "root.unknown")
+	var errInfo dao.YAPIError
+	err = json.Unmarshal(resp.outputBytes, &errInfo)
+	assert.NilError(t, err, "failed to unmarshal error response from response body")
+	assert.Equal(t, resp.statusCode, http.StatusBadRequest, "Incorrect Status code")
+	assert.Equal(t, errInfo.Message, "Queue not found", "JSON error message is incorrect")
+
+	// unknown partition
+	resp = queueRequest("notexists", "root")
+	assertPartitionExists(t, resp)
+}
+
 func TestGetPartitionNodes(t *testing.T) {
 	configs.MockSchedulerConfigByData([]byte(configDefault))
 	var err error
@@ -1016,12 +1476,64 @@ func TestGetPartitionNodes(t *testing.T) {
 			assert.Equal(t, node.NodeID, node1ID)
 			assert.Equal(t, "alloc-1", node.Allocations[0].AllocationKey)
 			assert.Equal(t, "alloc-1-uuid", node.Allocations[0].UUID)
+			assert.DeepEqual(t, map[string]int64{resources.MEMORY: 50, resources.VCORE: 30}, node.Utilization)
 		} else {
 			assert.Equal(t, node.NodeID, node2ID)
 			assert.Equal(t, "alloc-2", node.Allocations[0].AllocationKey)
 			assert.Equal(t, "alloc-2-uuid", node.Allocations[0].UUID)
+			assert.DeepEqual(t, map[string]int64{resources.MEMORY: 30, resources.VCORE: 50}, node.Utilization)
+		}
+		assert.Equal(t, 0, len(node.Reservations))
+		// recovered allocations were never proposed by the scheduler
+		assert.Equal(t, int64(0), node.Allocations[0].ProposalTime)
+	}
+	assert.Assert(t, !strings.Contains(string(resp.outputBytes), "proposalTime"), "unset proposal time should be omitted")
+
+	// an allocation made by the scheduler exposes the proposal time
+	err = app.AddAllocationAsk(objects.NewAllocationAsk(&si.AllocationAsk{
+		AllocationKey:  "alloc-3",
+		ApplicationID:  appID,
+		ResourceAsk:    &si.Resource{Resources: map[string]*si.Quantity{resources.MEMORY: {Value: 100}}},
+		MaxAllocations: 1,
+	}))
+	assert.NilError(t, err, "failed to add ask")
+	before := time.Now().UnixNano()
+	alloc := partition.GetQueue(queueName).TryAllocate(partition.GetNodeIterator)
+	assert.Assert(t, alloc != nil, "scheduler should have allocated the ask")
+	resp = &MockResponseWriter{}
+	getPartitionNodes(resp, req)
+	partitionNodesDao = nil
+	err = json.Unmarshal(resp.outputBytes, &partitionNodesDao)
+	assert.NilError(t, err, "failed to unmarshal PartitionNodes dao response from response body: %s", string(resp.outputBytes))
+	var proposalTime int64
+	for _, node := range partitionNodesDao {
+		for _, info := range node.Allocations {
+			if info.AllocationKey == "alloc-3" {
+				proposalTime = info.ProposalTime
+			}
 		}
 	}
+	assert.Assert(t, proposalTime >= before && proposalTime <= time.Now().UnixNano(), "proposal time not returned for scheduled allocation: %d", proposalTime)
+
+	// filtered and paginated request without allocations
+	req, err = http.NewRequest("GET", "/ws/v1/partition/default/nodes?limit=1&offset=1&allocations=false", strings.NewReader(""))
+	assert.NilError(t, err, "Get Nodes for PartitionNodes Handler request failed")
+	req = mux.SetURLVars(req, vars)
+	resp = &MockResponseWriter{}
+	getPartitionNodes(resp, req)
+	partitionNodesDao = nil
+	err = json.Unmarshal(resp.outputBytes, &partitionNodesDao)
+	assert.NilError(t, err, "failed to unmarshal PartitionNodes dao response from response body: %s", string(resp.outputBytes))
+	assert.Equal(t, 1, len(partitionNodesDao))
+	assert.Equal(t, node2ID, partitionNodesDao[0].NodeID)
+	assert.Equal(t, 0, len(partitionNodesDao[0].Allocations))
+
+	req, err = http.NewRequest("GET", "/ws/v1/partition/default/nodes?sortBy=name", strings.NewReader(""))
+	assert.NilError(t, err, "Get Nodes for PartitionNodes Handler request failed")
+	req = mux.SetURLVars(req, vars)
+	resp = &MockResponseWriter{}
+	getPartitionNodes(resp, req)
+	assert.Equal(t, http.StatusBadRequest, resp.statusCode, "invalid sort should fail")
 
 	var req1 *http.Request
 	req1, err = http.NewRequest("GET", "/ws/v1/partition/default/nodes", strings.NewReader(""))
@@ -1035,6 +1547,416 @@ func TestGetPartitionNodes(t *testing.T) {
 	assertPartitionExists(t, resp1)
 }
 
+func TestUpdateNodeTaints(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(configDefault))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+	partition := schedulerContext.GetPartition(common.GetNormalizedPartitionName("default", rmID))
+	nodeRes := resources.NewResourceFromMap(map[string]resources.Quantity{resources.MEMORY: 1000}).ToProto()
+	node := objects.NewNode(&si.NewNodeInfo{NodeID: "node-1", SchedulableResource: nodeRes})
+	err = partition.AddNode(node, nil)
+	assert.NilError(t, err, "add node to partition should not have failed")
+	NewWebApp(schedulerContext, nil)
+
+	var req *http.Request
+	req, err = http.NewRequest("PUT", "/ws/v1/partition/default/node/node-1/taints", strings.NewReader(`{"taints": {"dedicated": "gpu"}}`))
+	assert.NilError(t, err, "Update taints request failed")
+	req = mux.SetURLVars(req, map[string]string{"partition": partitionNameWithoutClusterID, "node": "node-1"})
+	resp := &MockResponseWriter{}
+	var nodeDao dao.NodeDAOInfo
+	updateNodeTaints(resp, req)
+	err = json.Unmarshal(resp.outputBytes, &nodeDao)
+	assert.NilError(t, err, "failed to unmarshal node dao response from response body: %s", string(resp.outputBytes))
+	assert.DeepEqual(t, nodeDao.Taints, map[string]string{"dedicated": "gpu"})
+	assert.DeepEqual(t, node.GetTaints(), map[string]string{"dedicated": "gpu"})
+
+	// unknown node
+	req, err = http.NewRequest("PUT", "/ws/v1/partition/default/node/unknown/taints", strings.NewReader(`{"taints": {}}`))
+	assert.NilError(t, err, "Update taints request failed")
+	req = mux.SetURLVars(req, map[string]string{"partition": partitionNameWithoutClusterID, "node": "unknown"})
+	resp = &MockResponseWriter{}
+	updateNodeTaints(resp, req)
+	var errInfo dao.YAPIError
+	err = json.Unmarshal(resp.outputBytes, &errInfo)
+	assert.NilError(t, err, "failed to unmarshal error response from response body")
+	assert.Equal(t, resp.statusCode, http.StatusBadRequest, "Incorrect Status code")
+	assert.Equal(t, errInfo.Message, "Node not found", "JSON error message is incorrect")
+
+	// invalid body
+	req, err = http.NewRequest("PUT", "/ws/v1/partition/default/node/node-1/taints", strings.NewReader("dedicated=gpu"))
+	assert.NilError(t, err, "Update taints request failed")
+	req = mux.SetURLVars(req, map[string]string{"partition": partitionNameWithoutClusterID, "node": "node-1"})
+	resp = &MockResponseWriter{}
+	updateNodeTaints(resp, req)
+	assert.Equal(t, resp.statusCode, http.StatusBadRequest, "Incorrect Status code")
+	assert.DeepEqual(t, node.GetTaints(), map[string]string{"dedicated": "gpu"})
+
+	// unknown partition
+	req, err = http.NewRequest("PUT", "/ws/v1/partition/default/node/node-1/taints", strings.NewReader(`{"taints": {}}`))
+	assert.NilError(t, err, "Update taints request failed")
+	req = mux.SetURLVars(req, map[string]string{"partition": "notexists", "node": "node-1"})
+	resp = &MockResponseWriter{}
+	updateNodeTaints(resp, req)
+	assertPartitionExists(t, resp)
+}
+
+func TestNodeSchedulableAndDrain(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(configDefault))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+	partition := schedulerContext.GetPartition(common.GetNormalizedPartitionName("default", rmID))
+	nodeRes := resources.NewResourceFromMap(map[string]resources.Quantity{resources.MEMORY: 1000}).ToProto()
+	node := objects.NewNode(&si.NewNodeInfo{NodeID: "node-1", SchedulableResource: nodeRes})
+	err = partition.AddNode(node, nil)
+	assert.NilError(t, err, "add node to partition should not have failed")
+	NewWebApp(schedulerContext, nil)
+
+	nodeRequest := func(handler http.HandlerFunc, url, body string) (*MockResponseWriter, dao.NodeDAOInfo) {
+		req, reqErr := http.NewRequest("PUT", url, strings.NewReader(body))
+		assert.NilError(t, reqErr, "node request failed")
+		req = mux.SetURLVars(req, map[string]string{"partition": partitionNameWithoutClusterID, "node": "node-1"})
+		resp := &MockResponseWriter{}
+		handler(resp, req)
+		var nodeDao dao.NodeDAOInfo
+		if resp.statusCode == 0 {
+			reqErr = json.Unmarshal(resp.outputBytes, &nodeDao)
+			assert.NilError(t, reqErr, "failed to unmarshal node dao response from response body: %s", string(resp.outputBytes))
+		}
+		return resp, nodeDao
+	}
+
+	_, nodeDao := nodeRequest(updateNodeSchedulable, "/ws/v1/partition/default/node/node-1/schedulable", `{"schedulable": false}`)
+	assert.Assert(t, !nodeDao.Schedulable, "node should be unschedulable")
+	assert.Assert(t, nodeDao.Drain == nil, "unschedulable node should not be draining")
+
+	_, nodeDao = nodeRequest(drainNode, "/ws/v1/partition/default/node/node-1/drain", `{"preempt": true}`)
+	assert.Assert(t, !nodeDao.Schedulable, "drained node should be unschedulable")
+	assert.Assert(t, nodeDao.Drain != nil, "drain progress should be reported")
+	assert.Equal(t, nodeDao.Drain.RemainingAllocations, 0, "empty node should have no allocations left")
+
+	_, nodeDao = nodeRequest(updateNodeSchedulable, "/ws/v1/partition/default/node/node-1/schedulable", `{"schedulable": true}`)
+	assert.Assert(t, nodeDao.Schedulable, "node should be schedulable")
+	assert.Assert(t, nodeDao.Drain == nil, "schedulable node should not be draining")
+
+	resp, _ := nodeRequest(drainNode, "/ws/v1/partition/default/node/node-1/drain", "preempt")
+	assert.Equal(t, resp.statusCode, http.StatusBadRequest, "invalid body should fail")
+	assert.Assert(t, node.IsSchedulable(), "invalid drain should not change the node")
+}
+
+func TestGetNodeRemovalImpact(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(configDefault))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+	partitionName := common.GetNormalizedPartitionName("default", rmID)
+	partition := schedulerContext.GetPartition(partitionName)
+	for _, appID := range []string{"app1", "app2"} {
+		err = partition.AddApplication(newApplication(appID, partitionName, queueName, rmID))
+		assert.NilError(t, err, "add application to partition should not have failed")
+	}
+
+	// gang application with members on both nodes, a placeholder on the node that is removed
+	allocRes := resources.NewResourceFromMap(map[string]resources.Quantity{resources.MEMORY: 100}).ToProto()
+	newAlloc := func(key, appID, nodeID, taskGroup string, placeholder bool) *objects.Allocation {
+		return objects.NewAllocationFromSI(&si.Allocation{
+			AllocationKey:    key,
+			ApplicationID:    appID,
+			PartitionName:    partitionName,
+			NodeID:           nodeID,
+			UUID:             key + "-uuid",
+			ResourcePerAlloc: allocRes,
+			TaskGroupName:    taskGroup,
+			Placeholder:      placeholder,
+		})
+	}
+	nodeRes := resources.NewResourceFromMap(map[string]resources.Quantity{resources.MEMORY: 1000}).ToProto()
+	node1 := objects.NewNode(&si.NewNodeInfo{NodeID: "node-1", SchedulableResource: nodeRes})
+	err = partition.AddNode(node1, []*objects.Allocation{
+		newAlloc("alloc-1", "app1", "node-1", "tg", true),
+		newAlloc("alloc-2", "app1", "node-1", "", false),
+	})
+	assert.NilError(t, err, "add node to partition should not have failed")
+	node2 := objects.NewNode(&si.NewNodeInfo{NodeID: "node-2", SchedulableResource: nodeRes})
+	err = partition.AddNode(node2, []*objects.Allocation{
+		newAlloc("alloc-3", "app1", "node-2", "tg", false),
+		newAlloc("alloc-4", "app2", "node-2", "", false),
+	})
+	assert.NilError(t, err, "add node to partition should not have failed")
+	NewWebApp(schedulerContext, nil)
+
+	var req *http.Request
+	req, err = http.NewRequest("GET", "/ws/v1/partition/default/node/node-1/removal-impact", strings.NewReader(""))
+	assert.NilError(t, err, "removal impact request failed")
+	req = mux.SetURLVars(req, map[string]string{"partition": partitionNameWithoutClusterID, "node": "node-1"})
+	resp := &MockResponseWriter{}
+	getNodeRemovalImpact(resp, req)
+	var impact dao.NodeRemovalImpactDAOInfo
+	err = json.Unmarshal(resp.outputBytes, &impact)
+	assert.NilError(t, err, "failed to unmarshal removal impact dao response from response body: %s", string(resp.outputBytes))
+	assert.Equal(t, impact.NodeID, "node-1")
+	assert.Equal(t, impact.Allocations, 1, "unexpected allocation count")
+	assert.Equal(t, impact.Placeholders, 1, "unexpected placeholder count")
+	assert.Equal(t, impact.Reservations, 0, "unexpected reservation count")
+	assert.Equal(t, len(impact.Applications), 1, "only the application on the node should be affected")
+	app := impact.Applications[0]
+	assert.Equal(t, app.ApplicationID, "app1")
+	assert.Equal(t, app.QueueName, queueName)
+	assert.Equal(t, app.Allocated, "[memory:200]")
+	assert.DeepEqual(t, app.Allocations, []string{"alloc-2"})
+	assert.DeepEqual(t, app.Placeholders, []string{"alloc-1"})
+	assert.DeepEqual(t, app.TaskGroups, []*dao.TaskGroupRemovalImpactDAOInfo{{Name: "tg", OnNode: 1, Total: 2}})
+
+	// unknown node
+	req = mux.SetURLVars(req, map[string]string{"partition": partitionNameWithoutClusterID, "node": "node-3"})
+	resp = &MockResponseWriter{}
+	getNodeRemovalImpact(resp, req)
+	assert.Equal(t, resp.statusCode, http.StatusBadRequest, "unknown node should fail")
+}
+
+func TestUpdateQueueLimits(t *testing.T) {
+	plugins.RegisterSchedulerPlugin(&FakeConfigPlugin{generateError: false})
+	configs.MockSchedulerConfigByData([]byte(configDefault))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+	partition := schedulerContext.GetPartition(common.GetNormalizedPartitionName("default", rmID))
+	NewWebApp(schedulerContext, nil)
+
+	var req *http.Request
+	req, err = http.NewRequest("PATCH", "/ws/v1/partition/default/queues/limits", strings.NewReader(`[{"queuePath": "root.default", "maxResource": {"memory": "100"}, "guaranteedResource": {"memory": "10"}}]`))
+	assert.NilError(t, err, "Update queue limits request failed")
+	req = mux.SetURLVars(req, map[string]string{"partition": partitionNameWithoutClusterID})
+	resp := &MockResponseWriter{}
+	var queueDao dao.PartitionQueueDAOInfo
+	updateQueueLimits(resp, req)
+	err = json.Unmarshal(resp.outputBytes, &queueDao)
+	assert.NilError(t, err, "failed to unmarshal queue dao response from response body: %s", string(resp.outputBytes))
+	assert.Equal(t, len(queueDao.Children), 1, "expected the default queue in the response")
+	assert.Equal(t, queueDao.Children[0].MaxResource, "[memory:100]")
+	assert.Equal(t, queueDao.Children[0].GuaranteedResource, "[memory:10]")
+	max, guaranteed := partition.GetQueue("root.default").GetLimits()
+	assert.Assert(t, resources.Equals(max, resources.NewResourceFromMap(map[string]resources.Quantity{resources.MEMORY: 100})), "max resource not updated")
+	assert.Assert(t, resources.Equals(guaranteed, resources.NewResourceFromMap(map[string]resources.Quantity{resources.MEMORY: 10})), "guaranteed resource not updated")
+	// the limits are set in the stored configuration
+	queueConf := configs.ConfigContext.Get(schedulerContext.GetPolicyGroup()).GetPartitionConfig("default").GetQueueConfig("root.default")
+	assert.DeepEqual(t, queueConf.Resources.Max, map[string]string{"memory": "100"})
+	assert.DeepEqual(t, queueConf.Resources.Guaranteed, map[string]string{"memory": "10"})
+
+	// failure to store the configuration reverts the limits
+	plugins.RegisterSchedulerPlugin(&FakeConfigPlugin{generateError: true})
+	req, err = http.NewRequest("PATCH", "/ws/v1/partition/default/queues/limits", strings.NewReader(`[{"queuePath": "root.default", "maxResource": {"memory": "200"}}]`))
+	assert.NilError(t, err, "Update queue limits request failed")
+	req = mux.SetURLVars(req, map[string]string{"partition": partitionNameWithoutClusterID})
+	resp = &MockResponseWriter{}
+	updateQueueLimits(resp, req)
+	assert.Equal(t, resp.statusCode, http.StatusConflict, "failed config update should fail the limit update")
+	max, _ = partition.GetQueue("root.default").GetLimits()
+	assert.Assert(t, resources.Equals(max, resources.NewResourceFromMap(map[string]resources.Quantity{resources.MEMORY: 100})), "max resource not reverted")
+	plugins.RegisterSchedulerPlugin(&FakeConfigPlugin{generateError: false})
+
+	// unknown queue: nothing is changed
+	req, err = http.NewRequest("PATCH", "/ws/v1/partition/default/queues/limits", strings.NewReader(`[{"queuePath": "root.default", "maxResource": {}}, {"queuePath": "root.unknown", "maxResource": {"memory": "100"}}]`))
+	assert.NilError(t, err, "Update queue limits request failed")
+	req = mux.SetURLVars(req, map[string]string{"partition": partitionNameWithoutClusterID})
+	resp = &MockResponseWriter{}
+	updateQueueLimits(resp, req)
+	var errInfo dao.YAPIError
+	err = json.Unmarshal(resp.outputBytes, &errInfo)
+	assert.NilError(t, err, "failed to unmarshal error response from response body")
+	assert.Equal(t, resp.statusCode, http.StatusBadRequest, "Incorrect Status code")
+	assert.Equal(t, errInfo.Message, "queue root.unknown not found", "JSON error message is incorrect")
+	max, _ = partition.GetQueue("root.default").GetLimits()
+	assert.Assert(t, max != nil, "failed update should not change limits")
+
+	// invalid resource
+	req, err = http.NewRequest("PATCH", "/ws/v1/partition/default/queues/limits", strings.NewReader(`[{"queuePath": "root.default", "maxResource": {"memory": "ten"}}]`))
+	assert.NilError(t, err, "Update queue limits request failed")
+	req = mux.SetURLVars(req, map[string]string{"partition": partitionNameWithoutClusterID})
+	resp = &MockResponseWriter{}
+	updateQueueLimits(resp, req)
+	assert.Equal(t, resp.statusCode, http.StatusBadRequest, "Incorrect Status code")
+
+	// unknown partition
+	req, err = http.NewRequest("PATCH", "/ws/v1/partition/default/queues/limits", strings.NewReader(`[]`))
+	assert.NilError(t, err, "Update queue limits request failed")
+	req = mux.SetURLVars(req, map[string]string{"partition": "notexists"})
+	resp = &MockResponseWriter{}
+	updateQueueLimits(resp, req)
+	assertPartitionExists(t, resp)
+}
+
+func TestCheckQueueAccess(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(`
+partitions:
+  - name: default
+    queues:
+      - name: root
+        adminacl: "admin"
+        queues:
+          - name: default
+            submitacl: "team-* devs"
+`))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+	NewWebApp(schedulerContext, nil)
+
+	tests := []struct {
+		name    string
+		query   string
+		groups  []string
+		allowed bool
+		entry   string
+		admin   bool
+	}{
+		{"wildcard user", "user=team-a", []string{"team-a"}, true, "user:team-*", false},
+		{"no access", "user=bob", []string{"bob"}, false, "", false},
+		{"group access", "user=bob&groups=ops,devs", []string{"ops", "devs"}, true, "group:devs", false},
+		{"admin access", "user=admin", []string{"admin"}, true, "user:admin", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", "/ws/v1/partition/default/queue/root.default/access?"+tt.query, strings.NewReader(""))
+			assert.NilError(t, err, "queue access request failed")
+			req = mux.SetURLVars(req, map[string]string{"partition": partitionNameWithoutClusterID, "queue": queueName})
+			resp := &MockResponseWriter{}
+			checkQueueAccess(resp, req)
+			var accessDao dao.QueueAccessDAOInfo
+			err = json.Unmarshal(resp.outputBytes, &accessDao)
+			assert.NilError(t, err, "failed to unmarshal access dao response from response body: %s", string(resp.outputBytes))
+			assert.Equal(t, accessDao.QueuePath, queueName)
+			assert.DeepEqual(t, accessDao.Groups, tt.groups)
+			assert.Equal(t, accessDao.Allowed, tt.allowed, "unexpected access result")
+			assert.Equal(t, accessDao.AdminAllowed, tt.admin, "unexpected admin access result")
+			if tt.entry == "" {
+				assert.Assert(t, accessDao.Rule == nil, "denied access should not have a rule")
+			} else {
+				assert.Equal(t, accessDao.Rule.Entry, tt.entry, "unexpected matching rule")
+			}
+			assert.Equal(t, accessDao.AdminRule != nil, tt.admin, "admin rule should only be set for admin access")
+		})
+	}
+
+	// missing user
+	req, err := http.NewRequest("GET", "/ws/v1/partition/default/queue/root.default/access", strings.NewReader(""))
+	assert.NilError(t, err, "queue access request failed")
+	req = mux.SetURLVars(req, map[string]string{"partition": partitionNameWithoutClusterID, "queue": queueName})
+	resp := &MockResponseWriter{}
+	checkQueueAccess(resp, req)
+	assert.Equal(t, resp.statusCode, http.StatusBadRequest, "Incorrect Status code")
+
+	// unknown queue
+	req, err = http.NewRequest("GET", "/ws/v1/partition/default/queue/root.unknown/access?user=bob", strings.NewReader(""))
+	assert.NilError(t, err, "queue access request failed")
+	req = mux.SetURLVars(req, map[string]string{"partition": partitionNameWithoutClusterID, "queue": "root.unknown"})
+	resp = &MockResponseWriter{}
+	checkQueueAccess(resp, req)
+	var errInfo dao.YAPIError
+	err = json.Unmarshal(resp.outputBytes, &errInfo)
+	assert.NilError(t, err, "failed to unmarshal error response from response body")
+	assert.Equal(t, resp.statusCode, http.StatusBadRequest, "Incorrect Status code")
+	assert.Equal(t, errInfo.Message, "Queue not found", "JSON error message is incorrect")
+
+	// unknown partition
+	req, err = http.NewRequest("GET", "/ws/v1/partition/default/queue/root.default/access?user=bob", strings.NewReader(""))
+	assert.NilError(t, err, "queue access request failed")
+	req = mux.SetURLVars(req, map[string]string{"partition": "notexists", "queue": queueName})
+	resp = &MockResponseWriter{}
+	checkQueueAccess(resp, req)
+	assertPartitionExists(t, resp)
+}
+
+func TestGetPartitionCounters(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(configDefault))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+	NewWebApp(schedulerContext, nil)
+
+	var req *http.Request
+	req, err = http.NewRequest("GET", "/ws/v1/partition/default/counters", strings.NewReader(""))
+	assert.NilError(t, err, "Get counters request failed")
+	req = mux.SetURLVars(req, map[string]string{"partition": partitionNameWithoutClusterID})
+	resp := &MockResponseWriter{}
+	var countersDao dao.PartitionCountersDAOInfo
+	getPartitionCounters(resp, req)
+	err = json.Unmarshal(resp.outputBytes, &countersDao)
+	assert.NilError(t, err, "failed to unmarshal counters dao response from response body: %s", string(resp.outputBytes))
+	assert.Equal(t, countersDao.Partition, common.GetNormalizedPartitionName("default", rmID))
+	assert.Equal(t, len(countersDao.Counters), 4, "expected counters for all events")
+	for _, counter := range countersDao.Counters {
+		assert.Equal(t, len(counter.Windows), 3, "expected all windows for event %s", counter.Event)
+		for window, value := range counter.Windows {
+			assert.Equal(t, value, 0, "new partition should have no events for %s in window %s", counter.Event, window)
+		}
+	}
+
+	req, err = http.NewRequest("GET", "/ws/v1/partition/default/counters", strings.NewReader(""))
+	assert.NilError(t, err, "Get counters request failed")
+	req = mux.SetURLVars(req, map[string]string{"partition": "notexists"})
+	resp = &MockResponseWriter{}
+	getPartitionCounters(resp, req)
+	assertPartitionExists(t, resp)
+}
+
+func TestGetPartitionResources(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(`
+partitions:
+  - name: default
+    nodegroups:
+      - zone
+    queues:
+      - name: root
+`))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+	partition := schedulerContext.GetPartition(common.GetNormalizedPartitionName("default", rmID))
+	nodeRes := resources.NewResourceFromMap(map[string]resources.Quantity{resources.MEMORY: 1000}).ToProto()
+	node := objects.NewNode(&si.NewNodeInfo{NodeID: nodeID, Attributes: map[string]string{"zone": "zone-a"}, SchedulableResource: nodeRes})
+	err = partition.AddNode(node, nil)
+	assert.NilError(t, err, "add node to partition should not have failed")
+
+	var req *http.Request
+	req, err = http.NewRequest("GET", "/ws/v1/partition/default/resources?groupBy=zone", strings.NewReader(""))
+	assert.NilError(t, err, "Get resources request failed")
+	req = mux.SetURLVars(req, map[string]string{"partition": partitionNameWithoutClusterID})
+	resp := &MockResponseWriter{}
+	getPartitionResources(resp, req)
+	var resourcesDao dao.PartitionResourcesDAOInfo
+	err = json.Unmarshal(resp.outputBytes, &resourcesDao)
+	assert.NilError(t, err, "failed to unmarshal resources dao response from response body: %s", string(resp.outputBytes))
+	assert.Equal(t, resourcesDao.GroupBy, "zone", "unexpected group by attribute")
+	assert.Equal(t, len(resourcesDao.Groups), 1, "expected one zone")
+	assert.Equal(t, resourcesDao.Groups[0].Value, "zone-a", "unexpected zone")
+	assert.Equal(t, resourcesDao.Groups[0].Nodes, 1, "unexpected node count")
+
+	// attribute not configured as a node group
+	req, err = http.NewRequest("GET", "/ws/v1/partition/default/resources?groupBy=rack", strings.NewReader(""))
+	assert.NilError(t, err, "Get resources request failed")
+	req = mux.SetURLVars(req, map[string]string{"partition": partitionNameWithoutClusterID})
+	resp = &MockResponseWriter{}
+	getPartitionResources(resp, req)
+	assert.Equal(t, resp.statusCode, http.StatusBadRequest, "unknown attribute should fail")
+
+	// missing group by
+	req, err = http.NewRequest("GET", "/ws/v1/partition/default/resources", strings.NewReader(""))
+	assert.NilError(t, err, "Get resources request failed")
+	req = mux.SetURLVars(req, map[string]string{"partition": partitionNameWithoutClusterID})
+	resp = &MockResponseWriter{}
+	getPartitionResources(resp, req)
+	assert.Equal(t, resp.statusCode, http.StatusBadRequest, "missing group by should fail")
+
+	req, err = http.NewRequest("GET", "/ws/v1/partition/default/resources?groupBy=zone", strings.NewReader(""))
+	assert.NilError(t, err, "Get resources request failed")
+	req = mux.SetURLVars(req, map[string]string{"partition": "notexists"})
+	resp = &MockResponseWriter{}
+	getPartitionResources(resp, req)
+	assertPartitionExists(t, resp)
+}
+
 func TestGetQueueApplicationsHandler(t *testing.T) {
 	configs.MockSchedulerConfigByData([]byte(configDefault))
 	var err error
@@ -1127,3 +2049,142 @@ func TestValidateQueue(t *testing.T) {
 	err2 := validateQueue("root")
 	assert.NilError(t, err2, "Queue path is correct but stil throwing error.")
 }
+
+func TestMoveQueue(t *testing.T) {
+	plugins.RegisterSchedulerPlugin(&FakeConfigPlugin{generateError: false})
+	configs.MockSchedulerConfigByData([]byte(configDefault))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+	partition := schedulerContext.GetPartition(common.GetNormalizedPartitionName("default", rmID))
+	NewWebApp(schedulerContext, nil)
+
+	var req *http.Request
+	req, err = http.NewRequest("PUT", "/ws/v1/partition/default/queue/root.default/move", strings.NewReader(`{"newQueuePath": "root.renamed"}`))
+	assert.NilError(t, err, "Move queue request failed")
+	req = mux.SetURLVars(req, map[string]string{"partition": partitionNameWithoutClusterID, "queue": queueName})
+	resp := &MockResponseWriter{}
+	moveQueue(resp, req)
+	var queueDao dao.PartitionQueueDAOInfo
+	err = json.Unmarshal(resp.outputBytes, &queueDao)
+	assert.NilError(t, err, "failed to unmarshal queue dao response from response body: %s", string(resp.outputBytes))
+	assert.Equal(t, len(queueDao.Children), 1, "expected the moved queue in the response")
+	assert.Equal(t, queueDao.Children[0].QueueName, "root.renamed")
+	assert.Assert(t, partition.GetQueue(queueName) == nil, "old queue should not exist")
+	// the move is made in the stored configuration
+	conf := configs.ConfigContext.Get(schedulerContext.GetPolicyGroup())
+	assert.Assert(t, conf.GetPartitionConfig("default").GetQueueConfig("root.renamed") != nil, "queue should have been moved in the stored config")
+	assert.Assert(t, conf.GetPartitionConfig("default").GetQueueConfig(queueName) == nil, "queue should have been removed from the stored config")
+	assert.Assert(t, partition.GetQueue("root.renamed") != nil, "moved queue should exist after the config update")
+
+	// failure to store the configuration reverts the move
+	plugins.RegisterSchedulerPlugin(&FakeConfigPlugin{generateError: true})
+	req, err = http.NewRequest("PUT", "/ws/v1/partition/default/queue/root.renamed/move", strings.NewReader(`{"newQueuePath": "root.other"}`))
+	assert.NilError(t, err, "Move queue request failed")
+	req = mux.SetURLVars(req, map[string]string{"partition": partitionNameWithoutClusterID, "queue": "root.renamed"})
+	resp = &MockResponseWriter{}
+	moveQueue(resp, req)
+	assert.Equal(t, resp.statusCode, http.StatusConflict, "failed config update should fail the move")
+	assert.Assert(t, partition.GetQueue("root.renamed") != nil, "queue should have been moved back")
+	assert.Assert(t, partition.GetQueue("root.other") == nil, "queue should not exist at the new path")
+	plugins.RegisterSchedulerPlugin(&FakeConfigPlugin{generateError: false})
+
+	// unknown queue
+	req, err = http.NewRequest("PUT", "/ws/v1/partition/default/queue/root.default/move", strings.NewReader(`{"newQueuePath": "root.other"}`))
+	assert.NilError(t, err, "Move queue request failed")
+	req = mux.SetURLVars(req, map[string]string{"partition": partitionNameWithoutClusterID, "queue": queueName})
+	resp = &MockResponseWriter{}
+	moveQueue(resp, req)
+	var errInfo dao.YAPIError
+	err = json.Unmarshal(resp.outputBytes, &errInfo)
+	assert.NilError(t, err, "failed to unmarshal error response from response body")
+	assert.Equal(t, resp.statusCode, http.StatusBadRequest, "Incorrect Status code")
+	assert.Equal(t, errInfo.Message, "queue root.default not found", "JSON error message is incorrect")
+
+	// invalid body
+	req, err = http.NewRequest("PUT", "/ws/v1/partition/default/queue/root.renamed/move", strings.NewReader(`{`))
+	assert.NilError(t, err, "Move queue request failed")
+	req = mux.SetURLVars(req, map[string]string{"partition": partitionNameWithoutClusterID, "queue": "root.renamed"})
+	resp = &MockResponseWriter{}
+	moveQueue(resp, req)
+	assert.Equal(t, resp.statusCode, http.StatusBadRequest, "Incorrect Status code")
+}
+
+func TestUpdateQueueScheduling(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(configDefault))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+	partition := schedulerContext.GetPartition(common.GetNormalizedPartitionName("default", rmID))
+	NewWebApp(schedulerContext, nil)
+
+	schedulingRequest := func(queue, body string) *MockResponseWriter {
+		req, reqErr := http.NewRequest("PUT", "/ws/v1/partition/default/queue/"+queue+"/scheduling", strings.NewReader(body))
+		assert.NilError(t, reqErr, "Queue scheduling request failed")
+		req = mux.SetURLVars(req, map[string]string{"partition": partitionNameWithoutClusterID, "queue": queue})
+		resp := &MockResponseWriter{}
+		updateQueueScheduling(resp, req)
+		return resp
+	}
+
+	resp := schedulingRequest(queueName, `{"paused": true}`)
+	var queueDao dao.PartitionQueueDAOInfo
+	err = json.Unmarshal(resp.outputBytes, &queueDao)
+	assert.NilError(t, err, "failed to unmarshal queue dao response from response body: %s", string(resp.outputBytes))
+	assert.Equal(t, len(queueDao.Children), 1, "expected the queue in the response")
+	assert.Assert(t, queueDao.Children[0].SchedulingPaused, "queue should be paused in the response")
+	assert.Assert(t, partition.GetQueue(queueName).IsSchedulingPaused(), "queue should be paused")
+
+	resp = schedulingRequest(queueName, `{"paused": false}`)
+	assert.Assert(t, resp.statusCode != http.StatusBadRequest, "resume should not have failed")
+	assert.Assert(t, !partition.GetQueue(queueName).IsSchedulingPaused(), "queue should be resumed")
+
+	// unknown queue
+	resp = schedulingRequest("root.unknown", `{"paused": true}`)
+	var errInfo dao.YAPIError
+	err = json.Unmarshal(resp.outputBytes, &errInfo)
+	assert.NilError(t, err, "failed to unmarshal error response from response body")
+	assert.Equal(t, resp.statusCode, http.StatusBadRequest, "Incorrect Status code")
+	assert.Equal(t, errInfo.Message, "Queue not found", "JSON error message is incorrect")
+
+	// invalid body
+	resp = schedulingRequest(queueName, `{`)
+	assert.Equal(t, resp.statusCode, http.StatusBadRequest, "Incorrect Status code")
+}
+
+func TestKillApplication(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(configDefault))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+	partitionName := common.GetNormalizedPartitionName("default", rmID)
+	partition := schedulerContext.GetPartition(partitionName)
+	app := newApplication("app1", partitionName, queueName, rmID)
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "add application to partition should not have failed")
+	NewWebApp(schedulerContext, nil)
+
+	killRequest := func(partName, appID string) *MockResponseWriter {
+		req, reqErr := http.NewRequest("DELETE", "/ws/v1/partition/default/application/"+appID, strings.NewReader(""))
+		assert.NilError(t, reqErr, "kill application request failed")
+		req = mux.SetURLVars(req, map[string]string{"partition": partName, "appID": appID})
+		resp := &MockResponseWriter{}
+		killApplication(resp, req)
+		return resp
+	}
+
+	resp := killRequest("notexists", "app1")
+	assertPartitionExists(t, resp)
+	resp = killRequest(partitionNameWithoutClusterID, "app2")
+	assert.Equal(t, resp.statusCode, http.StatusBadRequest, "unknown application should fail")
+	assert.Assert(t, strings.Contains(string(resp.outputBytes), "not found"), "unexpected error: %s", string(resp.outputBytes))
+
+	resp = killRequest(partitionNameWithoutClusterID, "app1")
+	assert.Equal(t, resp.statusCode, 0, "kill should not have failed: %s", string(resp.outputBytes))
+	var appDao dao.ApplicationDAOInfo
+	err = json.Unmarshal(resp.outputBytes, &appDao)
+	assert.NilError(t, err, "failed to unmarshal application dao response from response body: %s", string(resp.outputBytes))
+	assert.Equal(t, appDao.ApplicationID, "app1")
+	assert.Equal(t, appDao.State, "Failed")
+	assert.Assert(t, app.IsFailed(), "killed application should be failed")
+}
@@ -0,0 +1,174 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package webservice
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common"
+	"github.com/apache/incubator-yunikorn-core/pkg/log"
+	"github.com/apache/incubator-yunikorn-core/pkg/webservice/dao"
+)
+
+// Environment variables used to limit the load the REST API can put on the scheduler.
+// The maximum number of concurrent requests is unlimited if not set or zero, requests above the limit are rejected.
+// The request timeout applies to all endpoints and is disabled if not set or zero. The endpoint timeouts override
+// the request timeout per endpoint, the value is a comma separated list of pattern=duration entries,
+// for example: "/ws/v1/apps=10s,/ws/v1/partition/{partition}/nodes=5s". A timed out request is rejected.
+const (
+	EnvMaxConcurrentRequests = "WEBSERVICE_MAX_CONCURRENT_REQUESTS"
+	EnvRequestTimeout        = "WEBSERVICE_REQUEST_TIMEOUT"
+	EnvEndpointTimeouts      = "WEBSERVICE_ENDPOINT_TIMEOUTS"
+)
+
+// Endpoints that are never limited: a health or readiness check must not fail because the REST API is busy.
+// The event stream is a long poll: it holds the request for the wait set by the client, and limits its own wait.
+var unlimitedRoutes = map[string]bool{
+	"/ws/v1/scheduler/healthcheck": true,
+	"/ws/v1/readiness":             true,
+	"/ws/v1/stream":                true,
+}
+
+// Endpoints that stream the response: a timeout handler would buffer the complete response before sending it.
+// The timeout is set as the deadline of the request context instead, the stream stops when it expires.
+var streamedRoutes = map[string]bool{
+	"/ws/v1/apps":                        true,
+	"/ws/v1/nodes":                       true,
+	"/ws/v1/partition/{partition}/nodes": true,
+	"/ws/v1/partition/{partition}/queue/{queue}/applications": true,
+}
+
+// The limits shared by all endpoints of a router.
+type requestLimits struct {
+	slots            chan struct{} // one entry per request in progress, nil if unlimited
+	timeout          time.Duration
+	endpointTimeouts map[string]time.Duration
+}
+
+func newRequestLimits(maxConcurrent int, timeout time.Duration, endpointTimeouts map[string]time.Duration) *requestLimits {
+	limits := &requestLimits{
+		timeout:          timeout,
+		endpointTimeouts: endpointTimeouts,
+	}
+	if maxConcurrent > 0 {
+		limits.slots = make(chan struct{}, maxConcurrent)
+	}
+	return limits
+}
+
+// Create the limits based on the environment.
+func newRequestLimitsFromEnv() *requestLimits {
+	return newRequestLimits(common.GetIntEnvVar(EnvMaxConcurrentRequests, 0),
+		common.GetDurationEnvVar(EnvRequestTimeout, 0),
+		parseEndpointTimeouts(os.Getenv(EnvEndpointTimeouts)))
+}
+
+// Parse the endpoint timeouts, entries that cannot be parsed are logged and skipped.
+func parseEndpointTimeouts(value string) map[string]time.Duration {
+	timeouts := make(map[string]time.Duration)
+	if value == "" {
+		return timeouts
+	}
+	for _, entry := range strings.Split(value, ",") {
+		parts := strings.SplitN(strings.TrimSpace(entry), "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			log.Logger().Warn("REST API endpoint timeout skipped: expected pattern=duration",
+				zap.String("entry", entry))
+			continue
+		}
+		timeout, err := time.ParseDuration(strings.TrimSpace(parts[1]))
+		if err != nil || timeout < 0 {
+			log.Logger().Warn("REST API endpoint timeout skipped: invalid duration",
+				zap.String("entry", entry))
+			continue
+		}
+		timeouts[strings.TrimSpace(parts[0])] = timeout
+	}
+	return timeouts
+}
+
+// Get the timeout for the endpoint, zero if the endpoint has no timeout.
+func (l *requestLimits) getTimeout(pattern string) time.Duration {
+	if timeout, ok := l.endpointTimeouts[pattern]; ok {
+		return timeout
+	}
+	return l.timeout
+}
+
+// Apply the limits to the handler of the endpoint.
+// The concurrency slot is held until the handler finishes, even if the request timed out already. A handler that
+// keeps running after the timeout still loads the scheduler and must not make room for new requests.
+// A response with a timeout is buffered completely before it is sent, except for a streamed response.
+func (l *requestLimits) handler(inner http.Handler, pattern string) http.Handler {
+	if unlimitedRoutes[pattern] {
+		return inner
+	}
+	handler := inner
+	if l.slots != nil {
+		handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case l.slots <- struct{}{}:
+				defer func() { <-l.slots }()
+				inner.ServeHTTP(w, r)
+			default:
+				log.Logger().Info("REST API request rejected: too many concurrent requests",
+					zap.String("method", r.Method),
+					zap.String("request", r.RequestURI))
+				writeHeaders(w)
+				w.Header().Set("Retry-After", "1")
+				buildJSONErrorResponse(w, "too many concurrent requests", http.StatusTooManyRequests)
+			}
+		})
+	}
+	if timeout := l.getTimeout(pattern); timeout > 0 {
+		if streamedRoutes[pattern] {
+			handler = deadlineHandler(handler, timeout)
+		} else {
+			handler = http.TimeoutHandler(handler, timeout, timeoutMessage(timeout))
+		}
+	}
+	return handler
+}
+
+// Set the timeout as the deadline of the request context, the handler must check the context.
+func deadlineHandler(inner http.Handler, timeout time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+		inner.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// The JSON error returned for a timed out request.
+func timeoutMessage(timeout time.Duration) string {
+	errorInfo := dao.NewYAPIError(nil, http.StatusServiceUnavailable, fmt.Sprintf("request timed out after %s", timeout))
+	message, err := json.Marshal(errorInfo)
+	if err != nil {
+		return errorInfo.Description
+	}
+	return string(message)
+}
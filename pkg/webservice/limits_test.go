@@ -0,0 +1,134 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package webservice
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/webservice/dao"
+)
+
+func TestParseEndpointTimeouts(t *testing.T) {
+	assert.Equal(t, len(parseEndpointTimeouts("")), 0, "empty value should not set timeouts")
+	timeouts := parseEndpointTimeouts("/ws/v1/apps=10s, /ws/v1/nodes = 5s,/ws/v1/queues,=1s,/ws/v1/rms=-1s,/ws/v1/status=x")
+	assert.Equal(t, len(timeouts), 2, "invalid entries should have been skipped: %v", timeouts)
+	assert.Equal(t, timeouts["/ws/v1/apps"], 10*time.Second, "unexpected apps timeout")
+	assert.Equal(t, timeouts["/ws/v1/nodes"], 5*time.Second, "unexpected nodes timeout")
+
+	assert.NilError(t, os.Setenv(EnvRequestTimeout, "2s"))
+	defer os.Unsetenv(EnvRequestTimeout)
+	assert.NilError(t, os.Setenv(EnvEndpointTimeouts, "/ws/v1/apps=10s,/ws/v1/nodes=0s"))
+	defer os.Unsetenv(EnvEndpointTimeouts)
+	limits := newRequestLimitsFromEnv()
+	assert.Assert(t, limits.slots == nil, "concurrent requests should be unlimited by default")
+	assert.Equal(t, limits.getTimeout("/ws/v1/apps"), 10*time.Second, "endpoint timeout not used")
+	assert.Equal(t, limits.getTimeout("/ws/v1/nodes"), time.Duration(0), "endpoint should be able to disable the timeout")
+	assert.Equal(t, limits.getTimeout("/ws/v1/queues"), 2*time.Second, "request timeout not used")
+}
+
+func TestConcurrentRequestLimit(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	blocking := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+	})
+	limits := newRequestLimits(1, 0, nil)
+	handler := limits.handler(blocking, "/ws/v1/apps")
+	done := make(chan int)
+	go func() {
+		resp := httptest.NewRecorder()
+		handler.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/ws/v1/apps", nil))
+		done <- resp.Code
+	}()
+	<-started
+
+	// the only slot is taken: rejected
+	resp := httptest.NewRecorder()
+	handler.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/ws/v1/apps", nil))
+	assert.Equal(t, resp.Code, http.StatusTooManyRequests, "request above the limit should be rejected")
+	assert.Equal(t, resp.Header().Get("Retry-After"), "1", "rejected request should have a retry header")
+	var errInfo dao.YAPIError
+	assert.NilError(t, json.Unmarshal(resp.Body.Bytes(), &errInfo), "failed to unmarshal error response")
+	assert.Equal(t, errInfo.StatusCode, http.StatusTooManyRequests, "unexpected status in error response")
+
+	// the health check is never limited
+	health := limits.handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), "/ws/v1/scheduler/healthcheck")
+	resp = httptest.NewRecorder()
+	health.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/ws/v1/scheduler/healthcheck", nil))
+	assert.Equal(t, resp.Code, http.StatusOK, "health check should not be limited")
+
+	// slot is freed after the first request finishes
+	release <- struct{}{}
+	assert.Equal(t, <-done, http.StatusOK, "first request should have succeeded")
+	go func() {
+		<-started
+		release <- struct{}{}
+	}()
+	resp = httptest.NewRecorder()
+	handler.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/ws/v1/apps", nil))
+	assert.Equal(t, resp.Code, http.StatusOK, "request should be allowed after the slot is freed")
+}
+
+func TestRequestTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	slow := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	})
+	limits := newRequestLimits(1, 10*time.Millisecond, map[string]time.Duration{"/ws/v1/nodes": 0})
+	resp := httptest.NewRecorder()
+	limits.handler(slow, "/ws/v1/queues").ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/ws/v1/queues", nil))
+	assert.Equal(t, resp.Code, http.StatusServiceUnavailable, "slow request should have timed out")
+	var errInfo dao.YAPIError
+	assert.NilError(t, json.Unmarshal(resp.Body.Bytes(), &errInfo), "failed to unmarshal error response")
+	assert.Equal(t, errInfo.Message, "request timed out after 10ms", "unexpected error message")
+
+	// the timed out handler still runs and holds the slot
+	resp = httptest.NewRecorder()
+	limits.handler(slow, "/ws/v1/nodes").ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/ws/v1/nodes", nil))
+	assert.Equal(t, resp.Code, http.StatusTooManyRequests, "slot should be held by the timed out request")
+
+	// a streamed response is not buffered: the timeout is the deadline of the request context
+	resp = httptest.NewRecorder()
+	streamed := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+		assert.Equal(t, r.Context().Err(), context.DeadlineExceeded, "request context should have expired")
+	})
+	limits = newRequestLimits(0, 10*time.Millisecond, nil)
+	limits.handler(streamed, "/ws/v1/apps").ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/ws/v1/apps", nil))
+	assert.Assert(t, resp.Flushed, "streamed response should have been flushed")
+
+	// the event stream long poll has no timeout and does not need a slot
+	resp = httptest.NewRecorder()
+	stream := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+	})
+	limits.handler(stream, "/ws/v1/stream").ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/ws/v1/stream", nil))
+	assert.Equal(t, resp.Code, http.StatusOK, "event stream should not be limited")
+}
@@ -0,0 +1,72 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package webservice
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/metrics"
+)
+
+// Response writer that records the status code and the number of bytes written for the request metrics.
+type metricsResponseWriter struct {
+	http.ResponseWriter
+	code int
+	size int
+}
+
+func (w *metricsResponseWriter) WriteHeader(code int) {
+	if w.code == 0 {
+		w.code = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *metricsResponseWriter) Write(b []byte) (int, error) {
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.size += n
+	return n, err
+}
+
+// Flush the data written so far to the client, used when streaming a response.
+func (w *metricsResponseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Record the count, latency and response size of the requests for the endpoint.
+// The route pattern is used as the endpoint to keep the number of label values bounded.
+func metricsHandler(inner http.Handler, method, pattern string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &metricsResponseWriter{ResponseWriter: w}
+		inner.ServeHTTP(recorder, r)
+		code := recorder.code
+		// nothing written means the handler returned an empty 200 response
+		if code == 0 {
+			code = http.StatusOK
+		}
+		metrics.GetWebServiceMetrics().ObserveRequest(pattern, method, code, recorder.size, start)
+	})
+}
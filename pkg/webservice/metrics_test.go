@@ -0,0 +1,81 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package webservice
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/metrics"
+)
+
+func TestMetricsHandler(t *testing.T) {
+	const pattern = "/ws/v1/test/metrics"
+	wm := metrics.GetWebServiceMetrics()
+	count := func(code int) int {
+		value, err := wm.GetRequests(pattern, http.MethodGet, code)
+		assert.NilError(t, err, "failed to read request counter")
+		return value
+	}
+	okBefore := count(http.StatusOK)
+	badBefore := count(http.StatusBadRequest)
+
+	handler := metricsHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("fail") != "" {
+			buildJSONErrorResponse(w, "failed", http.StatusBadRequest)
+			return
+		}
+		_, err := w.Write([]byte("{}"))
+		assert.NilError(t, err, "write failed")
+	}), http.MethodGet, pattern)
+
+	req, err := http.NewRequest(http.MethodGet, "/ws/v1/test/metrics", nil)
+	assert.NilError(t, err, "failed to create request")
+	resp := httptest.NewRecorder()
+	handler.ServeHTTP(resp, req)
+	assert.Equal(t, resp.Code, http.StatusOK, "unexpected response code")
+	assert.Equal(t, resp.Body.String(), "{}", "response should be passed through")
+	assert.Equal(t, count(http.StatusOK), okBefore+1, "successful request not counted")
+
+	req, err = http.NewRequest(http.MethodGet, "/ws/v1/test/metrics?fail=true", nil)
+	assert.NilError(t, err, "failed to create request")
+	resp = httptest.NewRecorder()
+	handler.ServeHTTP(resp, req)
+	assert.Equal(t, resp.Code, http.StatusBadRequest, "unexpected response code")
+	assert.Equal(t, count(http.StatusBadRequest), badBefore+1, "failed request not counted")
+	assert.Equal(t, count(http.StatusOK), okBefore+1, "failed request counted as successful")
+}
+
+func TestMetricsResponseWriter(t *testing.T) {
+	resp := httptest.NewRecorder()
+	recorder := &metricsResponseWriter{ResponseWriter: resp}
+	n, err := recorder.Write([]byte("abc"))
+	assert.NilError(t, err, "write failed")
+	assert.Equal(t, n, 3)
+	_, err = recorder.Write([]byte("de"))
+	assert.NilError(t, err, "write failed")
+	recorder.WriteHeader(http.StatusInternalServerError)
+	assert.Equal(t, recorder.code, http.StatusOK, "code should be set by the first write")
+	assert.Equal(t, recorder.size, 5, "unexpected response size")
+	recorder.Flush()
+	assert.Assert(t, resp.Flushed, "flush should be passed to the response writer")
+}
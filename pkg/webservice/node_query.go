@@ -0,0 +1,165 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package webservice
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common"
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/objects"
+	"github.com/apache/incubator-yunikorn-core/pkg/webservice/dao"
+)
+
+// Supported values for the node sortBy query parameter
+const sortByUtilization = "utilization"
+
+// The filter, sort and pagination options for the node REST calls.
+// Nodes are returned in node ID order unless sorted by utilization.
+type nodeQuery struct {
+	partition    string              // partition name without the cluster ID, empty for all partitions
+	schedulable  *bool               // nil returns schedulable and unschedulable nodes
+	minAvailable *resources.Resource // nil returns nodes with any available resource
+	sortBy       string
+	limit        int // zero means no limit
+	offset       int
+	allocations  bool // include the allocations of each node
+}
+
+// Parse the node query parameters:
+// partition, schedulable=true|false, minAvailable=<name>:<quantity>,...,
+// sortBy=utilization, limit, offset and allocations=true|false
+func parseNodeQuery(values url.Values) (*nodeQuery, error) {
+	query := &nodeQuery{
+		partition:   values.Get("partition"),
+		allocations: true,
+	}
+	var err error
+	if value := values.Get("schedulable"); value != "" {
+		var schedulable bool
+		if schedulable, err = strconv.ParseBool(value); err != nil {
+			return nil, fmt.Errorf("invalid schedulable value: %s", value)
+		}
+		query.schedulable = &schedulable
+	}
+	if value := values.Get("minAvailable"); value != "" {
+		if query.minAvailable, err = parseResourceQuery(value); err != nil {
+			return nil, fmt.Errorf("invalid minAvailable value: %s", value)
+		}
+	}
+	switch value := values.Get("sortBy"); value {
+	case "", sortByUtilization:
+		query.sortBy = value
+	default:
+		return nil, fmt.Errorf("invalid sortBy value: %s", value)
+	}
+	if query.limit, err = parseNonNegative(values, "limit"); err != nil {
+		return nil, err
+	}
+	if query.offset, err = parseNonNegative(values, "offset"); err != nil {
+		return nil, err
+	}
+	if value := values.Get("allocations"); value != "" {
+		if query.allocations, err = strconv.ParseBool(value); err != nil {
+			return nil, fmt.Errorf("invalid allocations value: %s", value)
+		}
+	}
+	return query, nil
+}
+
+// Parse a resource in the form name:quantity,name:quantity
+func parseResourceQuery(value string) (*resources.Resource, error) {
+	conf := make(map[string]string)
+	for _, entry := range strings.Split(value, ",") {
+		parts := strings.SplitN(entry, ":", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid resource entry: %s", entry)
+		}
+		conf[parts[0]] = parts[1]
+	}
+	return resources.NewResourceFromConf(conf)
+}
+
+func parseNonNegative(values url.Values, name string) (int, error) {
+	value := values.Get(name)
+	if value == "" {
+		return 0, nil
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil || number < 0 {
+		return 0, fmt.Errorf("%s must be a non negative integer", name)
+	}
+	return number, nil
+}
+
+// Check if the partition matches the partition filter of the query.
+func (q *nodeQuery) matchPartition(partitionName string) bool {
+	return q.partition == "" || q.partition == common.GetPartitionNameWithoutClusterID(partitionName)
+}
+
+// Filter and sort the nodes, no pagination is applied.
+func (q *nodeQuery) filter(nodes []*objects.Node) []*objects.Node {
+	result := make([]*objects.Node, 0, len(nodes))
+	for _, node := range nodes {
+		if q.schedulable != nil && node.IsSchedulable() != *q.schedulable {
+			continue
+		}
+		if q.minAvailable != nil && !resources.FitIn(node.GetAvailableResource(), q.minAvailable) {
+			continue
+		}
+		result = append(result, node)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].NodeID < result[j].NodeID
+	})
+	if q.sortBy == sortByUtilization {
+		// most utilised nodes first
+		sort.SliceStable(result, func(i, j int) bool {
+			return resources.CompUsageRatioSeparately(result[i].GetAllocatedResource(), result[i].GetCapacity(),
+				result[j].GetAllocatedResource(), result[j].GetCapacity()) > 0
+		})
+	}
+	return result
+}
+
+// Return the page of nodes selected by the offset and limit.
+func (q *nodeQuery) paginate(nodes []*objects.Node) []*objects.Node {
+	if q.offset >= len(nodes) {
+		return nil
+	}
+	nodes = nodes[q.offset:]
+	if q.limit > 0 && q.limit < len(nodes) {
+		nodes = nodes[:q.limit]
+	}
+	return nodes
+}
+
+// Convert the node to the redacted DAO object, dropping the allocations if not requested.
+func (q *nodeQuery) getNodeJSON(node *objects.Node, rd *redactor) *dao.NodeDAOInfo {
+	nodeDao := getNodeJSON(node)
+	if !q.allocations {
+		nodeDao.Allocations = nil
+	}
+	rd.redactNode(nodeDao)
+	return nodeDao
+}
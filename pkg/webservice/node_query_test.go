@@ -0,0 +1,119 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package webservice
+
+import (
+	"net/url"
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/objects"
+	"github.com/apache/incubator-yunikorn-scheduler-interface/lib/go/si"
+)
+
+func TestParseNodeQuery(t *testing.T) {
+	query, err := parseNodeQuery(url.Values{})
+	assert.NilError(t, err, "empty query should not fail")
+	assert.Assert(t, query.schedulable == nil && query.minAvailable == nil, "no filters expected")
+	assert.Equal(t, query.limit, 0, "no limit expected")
+	assert.Assert(t, query.allocations, "allocations should be included by default")
+
+	query, err = parseNodeQuery(url.Values{
+		"partition":    {"default"},
+		"schedulable":  {"false"},
+		"minAvailable": {"memory:100,vcore:10"},
+		"sortBy":       {"utilization"},
+		"limit":        {"5"},
+		"offset":       {"10"},
+		"allocations":  {"false"},
+	})
+	assert.NilError(t, err, "valid query should not fail")
+	assert.Equal(t, query.partition, "default")
+	assert.Assert(t, query.schedulable != nil && !*query.schedulable, "schedulable filter not set")
+	expected := resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 100, "vcore": 10})
+	assert.Assert(t, resources.Equals(query.minAvailable, expected), "minAvailable not parsed: %v", query.minAvailable)
+	assert.Equal(t, query.sortBy, sortByUtilization)
+	assert.Equal(t, query.limit, 5)
+	assert.Equal(t, query.offset, 10)
+	assert.Assert(t, !query.allocations, "allocations should be omitted")
+
+	invalid := []url.Values{
+		{"schedulable": {"maybe"}},
+		{"minAvailable": {"memory"}},
+		{"minAvailable": {"memory:-1"}},
+		{"sortBy": {"name"}},
+		{"limit": {"-1"}},
+		{"offset": {"x"}},
+		{"allocations": {"none"}},
+	}
+	for _, values := range invalid {
+		_, err = parseNodeQuery(values)
+		assert.Assert(t, err != nil, "query should have failed: %v", values)
+	}
+}
+
+func TestNodeQueryFilter(t *testing.T) {
+	capacity := resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 100}).ToProto()
+	nodes := make([]*objects.Node, 0)
+	for _, nodeID := range []string{"node-3", "node-1", "node-2"} {
+		nodes = append(nodes, objects.NewNode(&si.NewNodeInfo{NodeID: nodeID, SchedulableResource: capacity}))
+	}
+	ask := &objects.AllocationAsk{
+		AllocationKey:     "alloc-1",
+		ApplicationID:     "app-1",
+		AllocatedResource: resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 60}),
+	}
+	assert.Assert(t, nodes[0].AddAllocation(objects.NewAllocation("alloc-1-uuid", "node-3", ask)), "allocation not added")
+	nodes[2].SetSchedulable(false)
+
+	// default sort on node ID
+	query := &nodeQuery{}
+	assertNodeIDs(t, query.filter(nodes), "node-1", "node-2", "node-3")
+	query.sortBy = sortByUtilization
+	assertNodeIDs(t, query.filter(nodes), "node-3", "node-1", "node-2")
+	schedulable := true
+	query = &nodeQuery{schedulable: &schedulable}
+	assertNodeIDs(t, query.filter(nodes), "node-1", "node-3")
+	query = &nodeQuery{minAvailable: resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 50})}
+	assertNodeIDs(t, query.filter(nodes), "node-1", "node-2")
+
+	// pagination
+	query = &nodeQuery{offset: 1, limit: 1}
+	assertNodeIDs(t, query.paginate(query.filter(nodes)), "node-2")
+	query = &nodeQuery{offset: 1}
+	assertNodeIDs(t, query.paginate(query.filter(nodes)), "node-2", "node-3")
+	query = &nodeQuery{offset: 3}
+	assertNodeIDs(t, query.paginate(query.filter(nodes)))
+
+	// allocations are dropped from the DAO if not requested
+	query = &nodeQuery{allocations: true}
+	assert.Equal(t, len(query.getNodeJSON(nodes[0], nil).Allocations), 1, "allocations should be included")
+	query.allocations = false
+	assert.Equal(t, len(query.getNodeJSON(nodes[0], nil).Allocations), 0, "allocations should be omitted")
+}
+
+func assertNodeIDs(t *testing.T, nodes []*objects.Node, expected ...string) {
+	t.Helper()
+	assert.Equal(t, len(nodes), len(expected), "unexpected number of nodes")
+	for i, node := range nodes {
+		assert.Equal(t, node.NodeID, expected[i], "unexpected node at position %d", i)
+	}
+}
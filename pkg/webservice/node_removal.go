@@ -0,0 +1,128 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package webservice
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/objects"
+	"github.com/apache/incubator-yunikorn-core/pkg/webservice/dao"
+)
+
+// List the applications and resources that are affected if the node is removed now.
+// Nothing is changed: the response allows an operator to assess the impact before decommissioning a node.
+func getNodeRemovalImpact(w http.ResponseWriter, r *http.Request) {
+	writeHeaders(w)
+	partition, node := getRequestNode(w, r)
+	if node == nil {
+		return
+	}
+	if err := json.NewEncoder(w).Encode(getNodeRemovalImpactJSON(partition, node)); err != nil {
+		buildJSONErrorResponse(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Collect the allocations and reservations on the node per application. For the applications that use task groups
+// (gang scheduling) the members of each affected task group are counted on the node and for the whole application.
+func getNodeRemovalImpactJSON(partition *scheduler.PartitionContext, node *objects.Node) *dao.NodeRemovalImpactDAOInfo {
+	impact := &dao.NodeRemovalImpactDAOInfo{
+		NodeID:    node.NodeID,
+		Partition: partition.Name,
+		Allocated: node.GetAllocatedResource().DAOString(),
+	}
+	apps := make(map[string]*dao.ApplicationRemovalImpactDAOInfo)
+	allocated := make(map[string]*resources.Resource)
+	taskGroups := make(map[string]map[string]int)
+	getApp := func(appID string) *dao.ApplicationRemovalImpactDAOInfo {
+		if info, ok := apps[appID]; ok {
+			return info
+		}
+		info := &dao.ApplicationRemovalImpactDAOInfo{ApplicationID: appID}
+		apps[appID] = info
+		allocated[appID] = resources.NewResource()
+		taskGroups[appID] = make(map[string]int)
+		return info
+	}
+	for _, alloc := range node.GetAllAllocations() {
+		info := getApp(alloc.ApplicationID)
+		allocated[alloc.ApplicationID].AddTo(alloc.AllocatedResource)
+		if alloc.IsPlaceholder() {
+			impact.Placeholders++
+			info.Placeholders = append(info.Placeholders, alloc.AllocationKey)
+		} else {
+			impact.Allocations++
+			info.Allocations = append(info.Allocations, alloc.AllocationKey)
+		}
+		if taskGroup := alloc.GetTaskGroup(); taskGroup != "" {
+			taskGroups[alloc.ApplicationID][taskGroup]++
+		}
+	}
+	for _, reservation := range node.GetReservationInfos() {
+		impact.Reservations++
+		info := getApp(reservation.ApplicationID)
+		info.Reservations = append(info.Reservations, reservation.AllocationKey)
+	}
+	for appID, info := range apps {
+		info.Allocated = allocated[appID].DAOString()
+		sort.Strings(info.Allocations)
+		sort.Strings(info.Placeholders)
+		app := schedulerContext.GetApplication(appID, partition.Name)
+		if app == nil {
+			impact.Applications = append(impact.Applications, info)
+			continue
+		}
+		info.QueueName = app.QueueName
+		info.State = app.CurrentState()
+		info.TaskGroups = getTaskGroupRemovalImpact(app, taskGroups[appID])
+		impact.Applications = append(impact.Applications, info)
+	}
+	sort.Slice(impact.Applications, func(i, j int) bool {
+		return impact.Applications[i].ApplicationID < impact.Applications[j].ApplicationID
+	})
+	return impact
+}
+
+// Count the members of the task groups of the application that have members on the node.
+func getTaskGroupRemovalImpact(app *objects.Application, onNode map[string]int) []*dao.TaskGroupRemovalImpactDAOInfo {
+	if len(onNode) == 0 {
+		return nil
+	}
+	total := make(map[string]int)
+	for _, alloc := range app.GetAllAllocations() {
+		if _, ok := onNode[alloc.GetTaskGroup()]; ok {
+			total[alloc.GetTaskGroup()]++
+		}
+	}
+	taskGroups := make([]*dao.TaskGroupRemovalImpactDAOInfo, 0, len(onNode))
+	for name, count := range onNode {
+		taskGroups = append(taskGroups, &dao.TaskGroupRemovalImpactDAOInfo{
+			Name:   name,
+			OnNode: count,
+			Total:  total[name],
+		})
+	}
+	sort.Slice(taskGroups, func(i, j int) bool {
+		return taskGroups[i].Name < taskGroups[j].Name
+	})
+	return taskGroups
+}
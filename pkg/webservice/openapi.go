@@ -0,0 +1,500 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package webservice
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/webservice/dao"
+)
+
+const openAPIVersion = "3.0.3"
+
+// A JSON object in the OpenAPI document
+type apiObject map[string]interface{}
+
+// Description of an endpoint in the OpenAPI document.
+// The request and response are DAO values: their schemas are generated from the json struct tags of the type,
+// which keeps the document in sync with the encoded JSON. A slice value describes a JSON array of the element type.
+type apiOperation struct {
+	id           string
+	summary      string
+	query        []string    // supported query parameters
+	request      interface{} // JSON request body, nil if the request has no JSON body
+	requestType  string      // content type of a request body that is not JSON
+	response     interface{} // JSON response body, nil if the response is not JSON
+	responseType string      // content type of a response body that is not JSON
+}
+
+// All endpoints described in the OpenAPI document, keyed on method and pattern of the route.
+// The System routes are not part of the REST API and are not described.
+var apiOperations = map[string]apiOperation{
+	http.MethodGet + " /ws/v1/queues": {
+		id:       "getQueueInfo",
+		summary:  "Queue hierarchy of all partitions: one object per partition",
+		response: dao.PartitionDAOInfo{},
+	},
+	http.MethodGet + " /ws/v1/clusters": {
+		id:       "getClusterInfo",
+		summary:  "Cluster information: one object per partition",
+		response: dao.ClusterDAOInfo{},
+	},
+	http.MethodGet + " /ws/v1/clusters/utilization": {
+		id:       "getClusterUtilization",
+		summary:  "Resource utilization of all partitions",
+		response: []dao.ClustersUtilDAOInfo{},
+	},
+	http.MethodGet + " /ws/v1/apps": {
+		id:       "getApplicationsInfo",
+		summary:  "Applications of all partitions, optionally filtered on the queue",
+		query:    []string{"queue"},
+		response: []dao.ApplicationDAOInfo{},
+	},
+	http.MethodGet + " /ws/v1/nodes": {
+		id:       "getNodesInfo",
+		summary:  "Nodes of all partitions",
+		query:    []string{"partition", "schedulable", "minAvailable", "sortBy", "limit", "offset", "allocations"},
+		response: []dao.NodesDAOInfo{},
+	},
+	http.MethodGet + " /ws/v1/nodes/utilization": {
+		id:       "getNodesUtilization",
+		summary:  "Distribution of the node utilization per resource type",
+		response: []dao.NodesUtilDAOInfo{},
+	},
+	http.MethodGet + " /ws/v1/stack": {
+		id:           "getStackInfo",
+		summary:      "Stack traces of all goroutines",
+		responseType: "text/plain",
+	},
+	http.MethodGet + " /ws/v1/metrics": {
+		id:           "getMetrics",
+		summary:      "Scheduler metrics in the Prometheus text format",
+		responseType: "text/plain",
+	},
+	http.MethodGet + " /ws/v1/config": {
+		id:           "getClusterConfig",
+		summary:      "Current scheduler configuration, returned as JSON if requested in the Accept header. The tokens and admin ACLs are only returned to admins",
+		responseType: "application/x-yaml",
+	},
+	http.MethodPut + " /ws/v1/config": {
+		id:           "updateClusterConfig",
+		summary:      "Replace the scheduler configuration",
+		requestType:  "application/x-yaml",
+		responseType: "text/plain",
+	},
+	http.MethodPost + " /ws/v1/config": {
+		id:          "createClusterConfig",
+		summary:     "Validate a scheduler configuration without applying it",
+		query:       []string{"dry_run"},
+		requestType: "application/x-yaml",
+		response:    dao.ValidateConfResponse{},
+	},
+	http.MethodGet + " /ws/v1/configs": {
+		id:       "getClusterConfigHistory",
+		summary:  "Stored versions of the scheduler configuration",
+		response: []dao.ConfigVersionDAOInfo{},
+	},
+	http.MethodPost + " /ws/v1/configs": {
+		id:           "rollbackClusterConfig",
+		summary:      "Roll back to a stored version of the scheduler configuration",
+		request:      dao.ConfigRollbackDAOInfo{},
+		responseType: "text/plain",
+	},
+	http.MethodPost + " /ws/v1/validate-conf": {
+		id:          "validateConf",
+		summary:     "Validate a scheduler configuration",
+		requestType: "application/x-yaml",
+		response:    dao.ValidateConfResponse{},
+	},
+	http.MethodGet + " /ws/v1/history/apps": {
+		id:       "getApplicationHistory",
+		summary:  "History of the total number of applications",
+		response: []dao.ApplicationHistoryDAOInfo{},
+	},
+	http.MethodGet + " /ws/v1/history/containers": {
+		id:       "getContainerHistory",
+		summary:  "History of the total number of containers",
+		response: []dao.ContainerHistoryDAOInfo{},
+	},
+	http.MethodGet + " /ws/v1/reports/forecast": {
+		id:       "getQueueForecast",
+		summary:  "Forecast of the queue usage based on the usage history",
+		query:    []string{"hours"},
+		response: dao.QueuesForecastDAOInfo{},
+	},
+	http.MethodGet + " /ws/v1/reports/user-fairness": {
+		id:       "getUserFairness",
+		summary:  "Fairness of the resource usage between the users of each queue",
+		query:    []string{"window"},
+		response: dao.UserFairnessReportDAOInfo{},
+	},
+	http.MethodGet + " /ws/v1/partitions": {
+		id:       "getPartitions",
+		summary:  "All partitions",
+		response: []dao.PartitionInfo{},
+	},
+	http.MethodGet + " /ws/v1/rms": {
+		id:       "getRMInfo",
+		summary:  "Registered resource managers",
+		response: []dao.RMDAOInfo{},
+	},
+	http.MethodGet + " /ws/v1/featuregates": {
+		id:       "getFeatureGates",
+		summary:  "Feature gates and their state",
+		response: []dao.FeatureGateDAOInfo{},
+	},
+	http.MethodPut + " /ws/v1/partition/{partition}/scheduling": {
+		id:       "updatePartitionScheduling",
+		summary:  "Enable or disable scheduling for the partition",
+		request:  dao.PartitionSchedulingDAOInfo{},
+		response: dao.PartitionInfo{},
+	},
+	http.MethodGet + " /ws/v1/partition/{partition}/queues": {
+		id:       "getPartitionQueues",
+		summary:  "Queue hierarchy of the partition",
+		response: dao.PartitionQueueDAOInfo{},
+	},
+	http.MethodPatch + " /ws/v1/partition/{partition}/queues/limits": {
+		id:       "updateQueueLimits",
+		summary:  "Update the resource limits of queues, managed queues are also updated in the stored configuration",
+		request:  []dao.QueueLimitsDAOInfo{},
+		response: dao.PartitionQueueDAOInfo{},
+	},
+	http.MethodPost + " /ws/v1/partition/{partition}/queues": {
+		id:       "createQueue",
+		summary:  "Add a queue to the stored configuration",
+		request:  dao.QueueConfigDAOInfo{},
+		response: dao.PartitionQueueDAOInfo{},
+	},
+	http.MethodPut + " /ws/v1/partition/{partition}/queues": {
+		id:       "updateQueue",
+		summary:  "Update a queue in the stored configuration",
+		request:  dao.QueueConfigDAOInfo{},
+		response: dao.PartitionQueueDAOInfo{},
+	},
+	http.MethodDelete + " /ws/v1/partition/{partition}/queues": {
+		id:       "deleteQueue",
+		summary:  "Remove a queue from the stored configuration",
+		query:    []string{"queue"},
+		response: dao.PartitionQueueDAOInfo{},
+	},
+	http.MethodGet + " /ws/v1/partition/{partition}/nodes": {
+		id:       "getPartitionNodes",
+		summary:  "Nodes of the partition",
+		query:    []string{"schedulable", "minAvailable", "sortBy", "limit", "offset", "allocations"},
+		response: []dao.NodeDAOInfo{},
+	},
+	http.MethodPut + " /ws/v1/partition/{partition}/node/{node}/taints": {
+		id:       "updateNodeTaints",
+		summary:  "Replace the taints of the node",
+		request:  dao.NodeTaintsDAOInfo{},
+		response: dao.NodeDAOInfo{},
+	},
+	http.MethodPut + " /ws/v1/partition/{partition}/node/{node}/schedulable": {
+		id:       "updateNodeSchedulable",
+		summary:  "Change the schedulable state of the node",
+		request:  dao.NodeSchedulableDAOInfo{},
+		response: dao.NodeDAOInfo{},
+	},
+	http.MethodPut + " /ws/v1/partition/{partition}/node/{node}/drain": {
+		id:       "drainNode",
+		summary:  "Drain the node",
+		request:  dao.NodeDrainRequestDAOInfo{},
+		response: dao.NodeDAOInfo{},
+	},
+	http.MethodGet + " /ws/v1/partition/{partition}/node/{node}/removal-impact": {
+		id:       "getNodeRemovalImpact",
+		summary:  "Impact of removing the node on the running applications",
+		response: dao.NodeRemovalImpactDAOInfo{},
+	},
+	http.MethodGet + " /ws/v1/partition/{partition}/queue/{queue}": {
+		id:       "getPartitionQueue",
+		summary:  "Queue and its children",
+		response: dao.PartitionQueueDAOInfo{},
+	},
+	http.MethodGet + " /ws/v1/partition/{partition}/queue/{queue}/applications": {
+		id:       "getQueueApplications",
+		summary:  "Applications of the queue",
+		response: []dao.ApplicationDAOInfo{},
+	},
+	http.MethodDelete + " /ws/v1/partition/{partition}/application/{appID}": {
+		id:       "killApplication",
+		summary:  "Kill the application",
+		response: dao.ApplicationDAOInfo{},
+	},
+	http.MethodGet + " /ws/v1/partition/{partition}/queue/{queue}/access": {
+		id:       "checkQueueAccess",
+		summary:  "Check the submit and administer access of a user to the queue",
+		query:    []string{"user", "groups"},
+		response: dao.QueueAccessDAOInfo{},
+	},
+	http.MethodPut + " /ws/v1/partition/{partition}/queue/{queue}/move": {
+		id:       "moveQueue",
+		summary:  "Move or rename the queue, a managed queue is also moved in the stored configuration",
+		request:  dao.QueueMoveDAOInfo{},
+		response: dao.PartitionQueueDAOInfo{},
+	},
+	http.MethodPut + " /ws/v1/partition/{partition}/queue/{queue}/scheduling": {
+		id:       "updateQueueScheduling",
+		summary:  "Pause or resume scheduling for the queue until the next configuration update",
+		request:  dao.QueueSchedulingDAOInfo{},
+		response: dao.PartitionQueueDAOInfo{},
+	},
+	http.MethodGet + " /ws/v1/partition/{partition}/counters": {
+		id:       "getPartitionCounters",
+		summary:  "Lifetime counters of the partition",
+		response: dao.PartitionCountersDAOInfo{},
+	},
+	http.MethodGet + " /ws/v1/partition/{partition}/resources": {
+		id:       "getPartitionResources",
+		summary:  "Resources of the partition grouped on a node attribute",
+		query:    []string{"groupBy"},
+		response: dao.PartitionResourcesDAOInfo{},
+	},
+	http.MethodGet + " /ws/v1/scheduler/healthcheck": {
+		id:       "checkHealthStatus",
+		summary:  "Health checks of the scheduler",
+		response: dao.SchedulerHealthDAOInfo{},
+	},
+	http.MethodGet + " /ws/v1/status": {
+		id:       "getStatus",
+		summary:  "Status of the scheduler",
+		response: dao.StatusDAOInfo{},
+	},
+	http.MethodGet + " /ws/v1/readiness": {
+		id:       "getReadiness",
+		summary:  "Readiness of the scheduler subsystems",
+		response: dao.ReadinessDAOInfo{},
+	},
+	http.MethodGet + " /ws/v1/replica/snapshot": {
+		id:       "getRESTSnapshot",
+		summary:  "Responses of the scheduler GET endpoints that list or read the state, used by a read-only replica. Only available to admins",
+		response: dao.RESTSnapshotDAOInfo{},
+	},
+	http.MethodGet + " /ws/v1/stream": {
+		id:       "getEventStream",
+		summary:  "Long poll for application, queue, node and request events after the cursor",
+		query:    []string{"cursor", "types", "wait"},
+		response: dao.EventStreamDAOInfo{},
+	},
+	http.MethodGet + " /ws/v1/openapi.json": {
+		id:       "getOpenAPI",
+		summary:  "OpenAPI document of the REST API",
+		response: apiObject{},
+	},
+}
+
+var (
+	pathParamRegExp = regexp.MustCompile(`{([^}]+)}`)
+	timeType        = reflect.TypeOf(time.Time{})
+)
+
+// The document does not change: it is built once on the first request.
+var openAPIDocument struct {
+	once sync.Once
+	body []byte
+	err  error
+}
+
+func getOpenAPI(w http.ResponseWriter, r *http.Request) {
+	writeHeaders(w)
+	openAPIDocument.once.Do(func() {
+		openAPIDocument.body, openAPIDocument.err = json.Marshal(buildOpenAPIDocument())
+	})
+	if openAPIDocument.err != nil {
+		buildJSONErrorResponse(w, openAPIDocument.err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if _, err := w.Write(openAPIDocument.body); err != nil {
+		buildJSONErrorResponse(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Build the OpenAPI document from the described operations.
+// All named struct types are added to the components of the document and referenced from the operations.
+func buildOpenAPIDocument() apiObject {
+	sb := &schemaBuilder{schemas: make(apiObject)}
+	paths := make(apiObject)
+	for key, op := range apiOperations {
+		parts := strings.SplitN(key, " ", 2)
+		method, pattern := strings.ToLower(parts[0]), parts[1]
+		item, ok := paths[pattern].(apiObject)
+		if !ok {
+			item = make(apiObject)
+			paths[pattern] = item
+		}
+		item[method] = sb.operation(pattern, op)
+	}
+	return apiObject{
+		"openapi": openAPIVersion,
+		"info": apiObject{
+			"title":   "YuniKorn scheduler REST API",
+			"version": "v1",
+		},
+		"paths": paths,
+		"components": apiObject{
+			"schemas": sb.schemas,
+		},
+	}
+}
+
+// Generates the schemas of the DAO types based on the json struct tags.
+type schemaBuilder struct {
+	schemas apiObject
+}
+
+func (sb *schemaBuilder) operation(pattern string, op apiOperation) apiObject {
+	var params []apiObject
+	for _, match := range pathParamRegExp.FindAllStringSubmatch(pattern, -1) {
+		params = append(params, apiObject{
+			"name":     match[1],
+			"in":       "path",
+			"required": true,
+			"schema":   apiObject{"type": "string"},
+		})
+	}
+	for _, name := range op.query {
+		params = append(params, apiObject{
+			"name":   name,
+			"in":     "query",
+			"schema": apiObject{"type": "string"},
+		})
+	}
+	result := apiObject{
+		"operationId": op.id,
+		"summary":     op.summary,
+		"responses": apiObject{
+			"200":     sb.content("OK", op.response, op.responseType),
+			"default": sb.content("Error", dao.YAPIError{}, ""),
+		},
+	}
+	if len(params) != 0 {
+		result["parameters"] = params
+	}
+	if op.request != nil || op.requestType != "" {
+		body := sb.content("", op.request, op.requestType)
+		body["required"] = true
+		result["requestBody"] = body
+	}
+	return result
+}
+
+// Content of a request or response: a JSON value or a string of the given content type.
+func (sb *schemaBuilder) content(description string, value interface{}, contentType string) apiObject {
+	result := make(apiObject)
+	if description != "" {
+		result["description"] = description
+	}
+	switch {
+	case value != nil:
+		result["content"] = apiObject{
+			"application/json": apiObject{"schema": sb.schema(reflect.TypeOf(value))},
+		}
+	case contentType != "":
+		result["content"] = apiObject{
+			contentType: apiObject{"schema": apiObject{"type": "string"}},
+		}
+	}
+	return result
+}
+
+// Schema of a type as encoded by the json package.
+// Named struct types are added to the components and a reference is returned.
+func (sb *schemaBuilder) schema(t reflect.Type) apiObject {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return sb.schema(t.Elem())
+	case reflect.Struct:
+		if t == timeType {
+			return apiObject{"type": "string", "format": "date-time"}
+		}
+		if t.Name() == "" {
+			return sb.object(t)
+		}
+		if _, ok := sb.schemas[t.Name()]; !ok {
+			// add the name before the fields to stop recursion on self referencing types
+			sb.schemas[t.Name()] = apiObject{}
+			sb.schemas[t.Name()] = sb.object(t)
+		}
+		return apiObject{"$ref": "#/components/schemas/" + t.Name()}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return apiObject{"type": "string", "format": "byte"}
+		}
+		return apiObject{"type": "array", "items": sb.schema(t.Elem())}
+	case reflect.Map:
+		return apiObject{"type": "object", "additionalProperties": sb.schema(t.Elem())}
+	case reflect.String:
+		return apiObject{"type": "string"}
+	case reflect.Bool:
+		return apiObject{"type": "boolean"}
+	case reflect.Int32, reflect.Uint32:
+		return apiObject{"type": "integer", "format": "int32"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint64:
+		return apiObject{"type": "integer", "format": "int64"}
+	case reflect.Float32:
+		return apiObject{"type": "number", "format": "float"}
+	case reflect.Float64:
+		return apiObject{"type": "number", "format": "double"}
+	default:
+		// interface values can hold anything
+		return apiObject{}
+	}
+}
+
+func (sb *schemaBuilder) object(t reflect.Type) apiObject {
+	properties := make(apiObject)
+	sb.addProperties(t, properties)
+	return apiObject{"type": "object", "properties": properties}
+}
+
+// Add the fields of the struct that are encoded by the json package, embedded structs are flattened.
+func (sb *schemaBuilder) addProperties(t reflect.Type, properties apiObject) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			sb.addProperties(fieldType, properties)
+			continue
+		}
+		// unexported fields are not encoded
+		if field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = sb.schema(field.Type)
+	}
+}
@@ -0,0 +1,109 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package webservice
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+// all routes of the REST API must be described in the document and the other way around
+func TestOpenAPIOperations(t *testing.T) {
+	routeKeys := make(map[string]bool)
+	for _, webRoute := range webRoutes {
+		if webRoute.Name == "System" {
+			continue
+		}
+		key := webRoute.Method + " " + webRoute.Pattern
+		routeKeys[key] = true
+		_, ok := apiOperations[key]
+		assert.Assert(t, ok, "route not described in the OpenAPI document: %s", key)
+	}
+	ids := make(map[string]bool)
+	for key, op := range apiOperations {
+		assert.Assert(t, routeKeys[key], "described operation has no route: %s", key)
+		assert.Assert(t, !ids[op.id], "duplicate operation id: %s", op.id)
+		ids[op.id] = true
+	}
+}
+
+func TestOpenAPIDocument(t *testing.T) {
+	body, err := json.Marshal(buildOpenAPIDocument())
+	assert.NilError(t, err, "document marshal failed")
+	var doc struct {
+		OpenAPI string                                       `json:"openapi"`
+		Paths   map[string]map[string]map[string]interface{} `json:"paths"`
+		Comps   struct {
+			Schemas map[string]struct {
+				Type       string                 `json:"type"`
+				Properties map[string]interface{} `json:"properties"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	err = json.Unmarshal(body, &doc)
+	assert.NilError(t, err, "document unmarshal failed")
+	assert.Equal(t, doc.OpenAPI, openAPIVersion)
+
+	// path parameters are taken from the pattern
+	op := doc.Paths["/ws/v1/partition/{partition}/node/{node}/taints"]["put"]
+	assert.Assert(t, op != nil, "node taints operation missing")
+	params, ok := op["parameters"].([]interface{})
+	assert.Assert(t, ok, "parameters missing")
+	assert.Equal(t, len(params), 2)
+	assert.Equal(t, params[0].(map[string]interface{})["name"], "partition")
+	assert.Equal(t, params[1].(map[string]interface{})["name"], "node")
+	_, ok = op["requestBody"]
+	assert.Assert(t, ok, "request body missing")
+
+	// properties use the json names, self references are resolved
+	queue, ok := doc.Comps.Schemas["PartitionQueueDAOInfo"]
+	assert.Assert(t, ok, "queue schema missing")
+	assert.Equal(t, queue.Type, "object")
+	_, ok = queue.Properties["queuename"]
+	assert.Assert(t, ok, "queue name property missing")
+	_, ok = queue.Properties["QueueName"]
+	assert.Assert(t, !ok, "field name used instead of json name")
+
+	// all references must point to a schema in the document
+	for _, ref := range strings.Split(string(body), `"$ref":"`)[1:] {
+		name := strings.TrimPrefix(ref[:strings.Index(ref, `"`)], "#/components/schemas/")
+		_, ok = doc.Comps.Schemas[name]
+		assert.Assert(t, ok, "unresolved schema reference: %s", name)
+	}
+}
+
+func TestGetOpenAPI(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "/ws/v1/openapi.json", strings.NewReader(""))
+	assert.NilError(t, err, "request creation failed")
+	resp := &MockResponseWriter{}
+	getOpenAPI(resp, req)
+	assert.Equal(t, resp.statusCode, 0, "unexpected status code")
+	var doc map[string]interface{}
+	err = json.Unmarshal(resp.outputBytes, &doc)
+	assert.NilError(t, err, "response unmarshal failed")
+	assert.Equal(t, doc["openapi"], openAPIVersion)
+	paths, ok := doc["paths"].(map[string]interface{})
+	assert.Assert(t, ok, "paths missing")
+	_, ok = paths["/ws/v1/openapi.json"]
+	assert.Assert(t, ok, "document does not describe itself")
+}
@@ -0,0 +1,275 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package webservice
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/configs"
+	"github.com/apache/incubator-yunikorn-core/pkg/common/security"
+	"github.com/apache/incubator-yunikorn-core/pkg/log"
+	"github.com/apache/incubator-yunikorn-core/pkg/webservice/dao"
+)
+
+// Value that replaces a redacted field
+const redactedValue = "redacted"
+
+// The redaction rules of the current configuration.
+var redactionRules = &redactionCache{}
+
+// The salt of the hashed dynamic queue names: a hash is stable for the lifetime of the scheduler only and cannot be
+// reversed by hashing a list of known user names.
+var queueNameSalt = newQueueNameSalt()
+
+// The redactor removes the sensitive fields from the DAO objects.
+// A nil redactor does not change anything.
+type redactor struct {
+	tags   []*regexp.Regexp
+	queues map[string]bool // the paths of the configured queues
+}
+
+// The admin ACL and the tag expressions of the redaction configuration. The rules are built once for each
+// configuration that is loaded and not for each request.
+type redactionCache struct {
+	conf   *configs.SchedulerConfig // the configuration the rules were built from
+	acl    security.ACL
+	tags   []*regexp.Regexp
+	queues map[string]bool
+
+	sync.Mutex
+}
+
+// Return the admin ACL and the tag expressions for the configuration, the rules are rebuilt if the configuration
+// changed since the last call.
+func (rc *redactionCache) get(conf *configs.SchedulerConfig) (security.ACL, []*regexp.Regexp) {
+	acl, tags, _ := rc.getRules(conf)
+	return acl, tags
+}
+
+// Return the admin ACL, the tag expressions and the configured queue paths for the configuration.
+func (rc *redactionCache) getRules(conf *configs.SchedulerConfig) (security.ACL, []*regexp.Regexp, map[string]bool) {
+	rc.Lock()
+	defer rc.Unlock()
+	if rc.conf == conf {
+		return rc.acl, rc.tags, rc.queues
+	}
+	// the config is validated: the ACL and expressions should never fail
+	acl, err := security.NewACL(conf.Redaction.AdminACL)
+	if err != nil {
+		log.Logger().Warn("redaction admin ACL parsing failed, redacting all responses",
+			zap.Error(err))
+	}
+	tags := make([]*regexp.Regexp, 0, len(conf.Redaction.Tags))
+	for _, tag := range conf.Redaction.Tags {
+		exp, err := regexp.Compile(tag)
+		if err != nil {
+			log.Logger().Warn("redaction tag expression skipped",
+				zap.String("expression", tag),
+				zap.Error(err))
+			continue
+		}
+		tags = append(tags, exp)
+	}
+	queues := make(map[string]bool)
+	for _, partition := range conf.Partitions {
+		addQueuePaths(queues, "", partition.Queues)
+	}
+	rc.conf = conf
+	rc.acl = acl
+	rc.tags = tags
+	rc.queues = queues
+	return acl, tags, queues
+}
+
+// Add the lower case paths of the configured queues and their children to the set.
+func addQueuePaths(paths map[string]bool, parent string, queues []configs.QueueConfig) {
+	for _, queue := range queues {
+		path := strings.ToLower(queue.Name)
+		if parent != "" {
+			path = parent + "." + path
+		}
+		paths[path] = true
+		addQueuePaths(paths, path, queue.Queues)
+	}
+}
+
+func newQueueNameSalt() []byte {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		log.Logger().Warn("queue name salt could not be generated, dynamic queue names are hashed without salt",
+			zap.Error(err))
+	}
+	return salt
+}
+
+// Return the redactor for the caller of the request based on the redaction configuration.
+// The caller is identified by the bearer token of the request, like the callers that change the scheduler state.
+// Returns nil if redaction is not enabled or the caller is an admin.
+func getRedactor(r *http.Request) *redactor {
+	if schedulerContext == nil {
+		return nil
+	}
+	conf := configs.ConfigContext.Get(schedulerContext.GetPolicyGroup())
+	if conf == nil || !conf.Redaction.Enabled {
+		return nil
+	}
+	acl, tags, queues := redactionRules.getRules(conf)
+	// a caller that cannot be identified is redacted
+	if caller, err := authenticate(r, conf.Authentication.Tokens); err == nil && acl.CheckAccess(caller) {
+		return nil
+	}
+	return &redactor{tags: tags, queues: queues}
+}
+
+// Return a copy of the tags without the tags that have a key matching one of the expressions.
+func (rd *redactor) redactTags(tags map[string]string) map[string]string {
+	if rd == nil || len(tags) == 0 || len(rd.tags) == 0 {
+		return tags
+	}
+	redacted := make(map[string]string)
+	for key, value := range tags {
+		if !rd.matchTag(key) {
+			redacted[key] = value
+		}
+	}
+	return redacted
+}
+
+func (rd *redactor) matchTag(key string) bool {
+	for _, exp := range rd.tags {
+		if exp.MatchString(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// Return the queue path with the name of each dynamic queue replaced by a salted hash. Dynamic queues are created
+// by the placement rules and are often named after a user or a group. The names of the configured queues are kept.
+// A redacted queue path cannot be used to look up the queue through the REST API.
+func (rd *redactor) redactQueuePath(path string) string {
+	if rd == nil || path == "" {
+		return path
+	}
+	parts := strings.Split(path, ".")
+	for i := range parts {
+		if !rd.queues[strings.ToLower(strings.Join(parts[:i+1], "."))] {
+			for j := i; j < len(parts); j++ {
+				parts[j] = hashQueueName(parts[j])
+			}
+			break
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+func hashQueueName(name string) string {
+	sum := sha256.Sum256(append(append([]byte{}, queueNameSalt...), name...))
+	return redactedValue + "-" + hex.EncodeToString(sum[:6])
+}
+
+func (rd *redactor) redactApplication(app *dao.ApplicationDAOInfo) {
+	if rd == nil || app == nil {
+		return
+	}
+	if app.User != "" {
+		app.User = redactedValue
+	}
+	app.QueueName = rd.redactQueuePath(app.QueueName)
+	app.RequestedQueue = rd.redactQueuePath(app.RequestedQueue)
+	for i := range app.Allocations {
+		app.Allocations[i].AllocationTags = rd.redactTags(app.Allocations[i].AllocationTags)
+		app.Allocations[i].QueueName = rd.redactQueuePath(app.Allocations[i].QueueName)
+	}
+	for i := range app.PendingAsks {
+		app.PendingAsks[i].AllocationTags = rd.redactTags(app.PendingAsks[i].AllocationTags)
+	}
+}
+
+func (rd *redactor) redactNode(node *dao.NodeDAOInfo) {
+	if rd == nil || node == nil {
+		return
+	}
+	for _, alloc := range node.Allocations {
+		alloc.AllocationTags = rd.redactTags(alloc.AllocationTags)
+		alloc.QueueName = rd.redactQueuePath(alloc.QueueName)
+	}
+}
+
+// The queue name is the name of the queue, not the path: the path is built from the parent path.
+func (rd *redactor) redactQueue(queue *dao.QueueDAOInfo, parent string) {
+	if rd == nil || queue == nil {
+		return
+	}
+	path := queue.QueueName
+	if parent != "" {
+		path = parent + "." + path
+	}
+	redacted := rd.redactQueuePath(path)
+	queue.QueueName = redacted[strings.LastIndex(redacted, ".")+1:]
+	queue.Properties = rd.redactTags(queue.Properties)
+	for i := range queue.ChildQueues {
+		rd.redactQueue(&queue.ChildQueues[i], path)
+	}
+}
+
+func (rd *redactor) redactPartitionQueue(queue *dao.PartitionQueueDAOInfo) {
+	if rd == nil || queue == nil {
+		return
+	}
+	queue.QueueName = rd.redactQueuePath(queue.QueueName)
+	queue.Parent = rd.redactQueuePath(queue.Parent)
+	for i := range queue.Children {
+		rd.redactPartitionQueue(&queue.Children[i])
+	}
+}
+
+func (rd *redactor) redactUserFairness(queue *dao.QueueUserFairnessDAOInfo) {
+	if rd == nil || queue == nil {
+		return
+	}
+	queue.QueueName = rd.redactQueuePath(queue.QueueName)
+	for _, user := range queue.Users {
+		user.User = redactedValue
+	}
+	if queue.MostOverServed != "" {
+		queue.MostOverServed = redactedValue
+	}
+	if queue.MostUnderServed != "" {
+		queue.MostUnderServed = redactedValue
+	}
+}
+
+// The event message is free text that can contain user names and tags: only the reason is kept.
+func (rd *redactor) redactEvent(event *dao.EventDAOInfo) {
+	if rd == nil || event == nil {
+		return
+	}
+	if event.Message != "" {
+		event.Message = redactedValue
+	}
+}
@@ -0,0 +1,293 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package webservice
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common"
+	"github.com/apache/incubator-yunikorn-core/pkg/common/configs"
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+	"github.com/apache/incubator-yunikorn-core/pkg/common/security"
+	"github.com/apache/incubator-yunikorn-core/pkg/events"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/objects"
+	"github.com/apache/incubator-yunikorn-core/pkg/webservice/dao"
+	"github.com/apache/incubator-yunikorn-scheduler-interface/lib/go/si"
+)
+
+// tokens: admin-token (admin), tenant-token (tenant in users), group-token (tenant in users and admins)
+const configRedaction = `
+redaction:
+  enabled: true
+  adminacl: "admin admins"
+  tags:
+    - "^secret\\."
+authentication:
+  adminacl: "admin"
+  tokens:
+    - sha256: 10a4c7c9fc5206d6f36dc6944a81bb6f4a3cb0e25014ae3b12e6c3e52712292a
+      user: admin
+    - sha256: 4f2571e0f820b886744ba8c1a90a7825b1044f5c506536ac8a011d7407b3f3eb
+      user: tenant
+      groups: [users]
+    - sha256: 94b8c020c1f1cdbe71a51dcacf6bb5608cae425e80b02b44bad0e1cdc5b101a0
+      user: tenant
+      groups: [users, admins]
+partitions:
+  - name: default
+    queues:
+      - name: root
+        submitacl: "*"
+        queues:
+          - name: default
+            properties:
+              secret.owner: tenant
+              application.sort.policy: fifo
+`
+
+func newRedactionRequest(token string) *http.Request {
+	req, _ := http.NewRequest("GET", "/ws/v1/apps", strings.NewReader(""))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return req
+}
+
+func TestGetRedactor(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(configDefault))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+	assert.Assert(t, getRedactor(newRedactionRequest("")) == nil, "redaction not configured should not redact")
+
+	configs.MockSchedulerConfigByData([]byte(configRedaction))
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+	assert.Assert(t, getRedactor(newRedactionRequest("")) != nil, "anonymous caller should be redacted")
+	assert.Assert(t, getRedactor(newRedactionRequest("unknown-token")) != nil, "unknown token should be redacted")
+	assert.Assert(t, getRedactor(newRedactionRequest("tenant-token")) != nil, "non admin caller should be redacted")
+	assert.Assert(t, getRedactor(newRedactionRequest("admin-token")) == nil, "admin user should not be redacted")
+	assert.Assert(t, getRedactor(newRedactionRequest("group-token")) == nil, "admin group member should not be redacted")
+	// the headers of a proxy are not trusted
+	req := newRedactionRequest("")
+	req.Header.Set("X-Remote-User", "admin")
+	assert.Assert(t, getRedactor(req) != nil, "proxy header should not identify the caller")
+
+	// the rules are built once for each loaded configuration
+	conf := configs.ConfigContext.Get(schedulerContext.GetPolicyGroup())
+	_, rules := redactionRules.get(conf)
+	_, cached := redactionRules.get(conf)
+	assert.Equal(t, &rules[0], &cached[0], "rules should not be rebuilt for the same configuration")
+
+	rd := getRedactor(newRedactionRequest(""))
+	tags := map[string]string{"secret.user": "bob", "kubernetes.io/label/app": "sleep"}
+	redacted := rd.redactTags(tags)
+	assert.DeepEqual(t, redacted, map[string]string{"kubernetes.io/label/app": "sleep"})
+	assert.Equal(t, len(tags), 2, "original tags should not be changed")
+	var nilRedactor *redactor
+	assert.DeepEqual(t, nilRedactor.redactTags(tags), tags)
+}
+
+func TestRedactApplications(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(configRedaction))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+	partitionName := common.GetNormalizedPartitionName("default", rmID)
+	partition := schedulerContext.GetPartition(partitionName)
+	siApp := &si.AddApplicationRequest{
+		ApplicationID: "app-1",
+		QueueName:     queueName,
+		PartitionName: partitionName,
+	}
+	app := objects.NewApplication(siApp, security.UserGroup{User: "bob"}, nil, rmID)
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "add application to partition should not have failed")
+	nodeRes := resources.NewResourceFromMap(map[string]resources.Quantity{resources.MEMORY: 1000}).ToProto()
+	node := objects.NewNode(&si.NewNodeInfo{NodeID: "node-1", SchedulableResource: nodeRes})
+	ask := &objects.AllocationAsk{
+		AllocationKey:     "alloc-1",
+		QueueName:         queueName,
+		ApplicationID:     "app-1",
+		AllocatedResource: resources.NewResourceFromMap(map[string]resources.Quantity{resources.MEMORY: 100}),
+		Tags:              map[string]string{"secret.user": "bob", "app": "sleep"},
+	}
+	err = partition.AddNode(node, []*objects.Allocation{objects.NewAllocation("alloc-1-uuid", "node-1", ask)})
+	assert.NilError(t, err, "add node to partition should not have failed")
+	err = app.AddAllocationAsk(objects.NewAllocationAsk(&si.AllocationAsk{
+		AllocationKey:  "alloc-2",
+		ApplicationID:  "app-1",
+		ResourceAsk:    resources.NewResourceFromMap(map[string]resources.Quantity{resources.MEMORY: 100}).ToProto(),
+		MaxAllocations: 1,
+		Tags:           map[string]string{"secret.user": "bob", "app": "sleep"},
+	}))
+	assert.NilError(t, err, "add ask to application should not have failed")
+	NewWebApp(schedulerContext, nil)
+
+	// non admin caller
+	resp := &MockResponseWriter{}
+	getApplicationsInfo(resp, newRedactionRequest("tenant-token"))
+	var appsDao []*dao.ApplicationDAOInfo
+	err = json.Unmarshal(resp.outputBytes, &appsDao)
+	assert.NilError(t, err, "failed to unmarshal app dao response from response body: %s", string(resp.outputBytes))
+	assert.Equal(t, len(appsDao), 1, "expected one application")
+	assert.Equal(t, appsDao[0].User, redactedValue, "user should be redacted")
+	assert.Equal(t, appsDao[0].RequestedQueue, queueName, "requested queue should be set")
+	assert.Assert(t, !appsDao[0].QueueCreated, "queue should not have been created")
+	assert.DeepEqual(t, appsDao[0].Allocations[0].AllocationTags, map[string]string{"app": "sleep"})
+	assert.Equal(t, len(appsDao[0].PendingAsks), 1, "expected one pending ask")
+	assert.Equal(t, appsDao[0].PendingAsks[0].PendingCount, int32(1), "unexpected pending count")
+	assert.DeepEqual(t, appsDao[0].PendingAsks[0].AllocationTags, map[string]string{"app": "sleep"})
+
+	resp = &MockResponseWriter{}
+	getNodesInfo(resp, newRedactionRequest("tenant-token"))
+	var nodesDao []*dao.NodesDAOInfo
+	err = json.Unmarshal(resp.outputBytes, &nodesDao)
+	assert.NilError(t, err, "failed to unmarshal node dao response from response body: %s", string(resp.outputBytes))
+	assert.DeepEqual(t, nodesDao[0].Nodes[0].Allocations[0].AllocationTags, map[string]string{"app": "sleep"})
+
+	resp = &MockResponseWriter{}
+	getQueueInfo(resp, newRedactionRequest("tenant-token"))
+	var partitionDao *dao.PartitionDAOInfo
+	err = json.Unmarshal(resp.outputBytes, &partitionDao)
+	assert.NilError(t, err, "failed to unmarshal queue dao response from response body: %s", string(resp.outputBytes))
+	assert.DeepEqual(t, partitionDao.Queues.ChildQueues[0].Properties, map[string]string{"application.sort.policy": "fifo"})
+
+	// admin caller sees everything
+	resp = &MockResponseWriter{}
+	getApplicationsInfo(resp, newRedactionRequest("admin-token"))
+	err = json.Unmarshal(resp.outputBytes, &appsDao)
+	assert.NilError(t, err, "failed to unmarshal app dao response from response body: %s", string(resp.outputBytes))
+	assert.Equal(t, appsDao[0].User, "bob", "user should not be redacted for admin")
+	assert.Equal(t, len(appsDao[0].Allocations[0].AllocationTags), 2, "tags should not be redacted for admin")
+	assert.Equal(t, len(appsDao[0].PendingAsks[0].AllocationTags), 2, "ask tags should not be redacted for admin")
+}
+
+func TestRedactQueuePath(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(configRedaction))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+	rd := getRedactor(newRedactionRequest("tenant-token"))
+	assert.Assert(t, rd != nil, "non admin caller should be redacted")
+
+	// configured queues are kept, dynamic queues and everything below them are hashed
+	assert.Equal(t, rd.redactQueuePath(""), "")
+	assert.Equal(t, rd.redactQueuePath("root"), "root")
+	assert.Equal(t, rd.redactQueuePath(queueName), queueName)
+	bob := rd.redactQueuePath("root.bob")
+	assert.Assert(t, strings.HasPrefix(bob, "root."+redactedValue+"-"), "dynamic queue should be hashed: %s", bob)
+	assert.Assert(t, !strings.Contains(bob, "bob"), "user name should not be part of the path: %s", bob)
+	assert.Equal(t, rd.redactQueuePath("root.bob"), bob, "hash should be stable")
+	assert.Assert(t, rd.redactQueuePath("root.alice") != bob, "different queues should have different hashes")
+	child := rd.redactQueuePath("root.default.bob.child")
+	assert.Assert(t, strings.HasPrefix(child, queueName+"."), "configured parent should be kept: %s", child)
+	assert.Equal(t, len(strings.Split(child, ".")), 4, "segments should be kept: %s", child)
+	assert.Assert(t, !strings.Contains(child, "bob") && !strings.Contains(child, "child"), "dynamic names should be hashed: %s", child)
+
+	// the queue tree only has the names of the queues
+	queue := &dao.QueueDAOInfo{
+		QueueName:   "root",
+		ChildQueues: []dao.QueueDAOInfo{{QueueName: "default"}, {QueueName: "bob"}},
+	}
+	rd.redactQueue(queue, "")
+	assert.Equal(t, queue.QueueName, "root", "root should be kept")
+	assert.Equal(t, queue.ChildQueues[0].QueueName, "default", "configured queue should be kept")
+	assert.Equal(t, "root."+queue.ChildQueues[1].QueueName, bob, "dynamic queue should be hashed like the path")
+
+	partitionQueue := &dao.PartitionQueueDAOInfo{
+		QueueName: "root",
+		Children:  []dao.PartitionQueueDAOInfo{{QueueName: "root.bob", Parent: "root"}},
+	}
+	rd.redactPartitionQueue(partitionQueue)
+	assert.Equal(t, partitionQueue.Children[0].QueueName, bob, "dynamic queue should be hashed")
+	assert.Equal(t, partitionQueue.Children[0].Parent, "root", "configured parent should be kept")
+
+	assert.Assert(t, getRedactor(newRedactionRequest("admin-token")).redactQueuePath("root.bob") == "root.bob", "admin should see the queue path")
+}
+
+func TestRedactConfig(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(configRedaction))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+
+	req := newRedactionRequest("tenant-token")
+	req.Header.Set("Accept", "application/json")
+	resp := &MockResponseWriter{}
+	getClusterConfig(resp, req)
+	var conf configs.SchedulerConfig
+	err = json.Unmarshal(resp.outputBytes, &conf)
+	assert.NilError(t, err, "failed to unmarshal config from response body: %s", string(resp.outputBytes))
+	assert.Equal(t, conf.Redaction.AdminACL, redactedValue, "redaction admin ACL should be redacted")
+	assert.Equal(t, conf.Authentication.AdminACL, redactedValue, "authentication admin ACL should be redacted")
+	assert.Equal(t, len(conf.Authentication.Tokens), 0, "tokens should be redacted")
+	current := configs.ConfigContext.Get(schedulerContext.GetPolicyGroup())
+	assert.Equal(t, current.Redaction.AdminACL, "admin admins", "current config should not be changed")
+
+	req = newRedactionRequest("admin-token")
+	req.Header.Set("Accept", "application/json")
+	resp = &MockResponseWriter{}
+	getClusterConfig(resp, req)
+	err = json.Unmarshal(resp.outputBytes, &conf)
+	assert.NilError(t, err, "failed to unmarshal config from response body: %s", string(resp.outputBytes))
+	assert.Equal(t, conf.Redaction.AdminACL, "admin admins", "admin should see the redaction admin ACL")
+	assert.Equal(t, len(conf.Authentication.Tokens), 3, "admin should see the tokens")
+}
+
+func TestRedactEvents(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(configRedaction))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+	events.CreateAndSetEventCache()
+	cache := events.GetEventCache()
+	cache.StartService()
+	defer cache.Stop()
+	cache.AddEvent(&si.EventRecord{Type: si.EventRecord_REQUEST, ObjectID: "alloc-1", GroupID: "app-1",
+		Reason: "AskRejected", Message: "user bob is not allowed"})
+	err = common.WaitFor(time.Millisecond, time.Second, func() bool {
+		return cache.Feed.GetCursor() == 1
+	})
+	assert.NilError(t, err, "event not processed")
+
+	stream := func(token string) dao.EventStreamDAOInfo {
+		req := newRedactionRequest(token)
+		req.URL.RawQuery = "cursor=0&wait=0s"
+		resp := &MockResponseWriter{}
+		getEventStream(resp, req)
+		var result dao.EventStreamDAOInfo
+		err = json.Unmarshal(resp.outputBytes, &result)
+		assert.NilError(t, err, "failed to unmarshal stream response from response body: %s", string(resp.outputBytes))
+		assert.Equal(t, len(result.Events), 1, "expected one event")
+		return result
+	}
+	result := stream("tenant-token")
+	assert.Equal(t, result.Events[0].Message, redactedValue, "message should be redacted")
+	assert.Equal(t, result.Events[0].Reason, "AskRejected", "reason should not be redacted")
+	result = stream("admin-token")
+	assert.Equal(t, result.Events[0].Message, "user bob is not allowed", "message should not be redacted for admin")
+}
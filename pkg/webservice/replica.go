@@ -0,0 +1,202 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package webservice
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/log"
+	"github.com/apache/incubator-yunikorn-core/pkg/webservice/dao"
+)
+
+// The path of the REST snapshot on the active instance.
+const replicaSnapshotPath = "/ws/v1/replica/snapshot"
+
+// The maximum time to fetch the snapshot from the active instance.
+var replicaFetchTimeout = 30 * time.Second
+
+// The GET endpoints that describe the instance that serves the request: the replica serves them locally.
+var replicaLocalPatterns = map[string]bool{
+	"/ws/v1/stack":        true,
+	"/ws/v1/metrics":      true,
+	"/ws/v1/readiness":    true,
+	"/ws/v1/openapi.json": true,
+}
+
+// The replica cache holds the REST snapshot of the active scheduler instance: the responses of the scheduler GET
+// endpoints for one state of the scheduler. The snapshot is fetched in one request each refresh interval,
+// requests to the replica are served from the snapshot and never reach the active instance.
+type replicaCache struct {
+	active   string // base URL of the active instance, e.g. http://scheduler:9080
+	token    string // bearer token of an admin of the active instance, empty if authentication is not enabled
+	client   *http.Client
+	interval time.Duration
+	snapshot *dao.RESTSnapshotDAOInfo // nil until the first successful refresh
+	fetched  time.Time
+	stop     chan struct{}
+
+	sync.RWMutex
+}
+
+func newReplicaCache(active, token string, interval time.Duration) *replicaCache {
+	return &replicaCache{
+		active:   strings.TrimSuffix(active, "/"),
+		token:    token,
+		client:   &http.Client{Timeout: replicaFetchTimeout},
+		interval: interval,
+		stop:     make(chan struct{}),
+	}
+}
+
+// Create the router for the replica: the GET endpoints in the snapshot are served from the snapshot. The system
+// (profiling) endpoints and the endpoints that describe the instance itself are served locally. The other GET
+// endpoints are not available on a replica, all endpoints that change state, the snapshot and the event stream
+// are rejected.
+func newReplicaRouter(cache *replicaCache) *mux.Router {
+	router := mux.NewRouter().StrictSlash(true)
+	for _, webRoute := range webRoutes {
+		var handler http.Handler
+		switch {
+		case webRoute.Name == "System":
+			handler = webRoute.HandlerFunc
+		case webRoute.Name == "Stream" || webRoute.Name == "Snapshot":
+			// a long poll is not part of the snapshot, the replica has no events of its own
+			handler = corsHandler(http.HandlerFunc(rejectReadOnly))
+		case isSnapshotRoute(webRoute):
+			handler = corsHandler(gzipHandler(http.HandlerFunc(cache.serve)))
+		case webRoute.Method == http.MethodGet && replicaLocalPatterns[webRoute.Pattern]:
+			handler = corsHandler(webRoute.HandlerFunc)
+		case webRoute.Method == http.MethodGet:
+			handler = corsHandler(http.HandlerFunc(notOnReplica))
+		default:
+			handler = corsHandler(http.HandlerFunc(rejectReadOnly))
+		}
+		router.
+			Methods(webRoute.Method).
+			Path(webRoute.Pattern).
+			Name(webRoute.Name).
+			Handler(loggingHandler(metricsHandler(handler, webRoute.Method, webRoute.Pattern), webRoute.Name))
+	}
+	return router
+}
+
+func rejectReadOnly(w http.ResponseWriter, r *http.Request) {
+	writeHeaders(w)
+	buildJSONErrorResponse(w, "read-only replica: "+r.Method+" not supported", http.StatusMethodNotAllowed)
+}
+
+func notOnReplica(w http.ResponseWriter, r *http.Request) {
+	writeHeaders(w)
+	buildJSONErrorResponse(w, "read-only replica: "+r.URL.Path+" is only available on the active scheduler instance", http.StatusNotImplemented)
+}
+
+// Serve the request from the snapshot. The snapshot only holds the responses without query parameters: filtering,
+// grouping and paging are not implemented on a replica and a request with query parameters is rejected with a 501,
+// the request must be sent to the active instance. A stale response is served if the active instance cannot be reached.
+func (rc *replicaCache) serve(w http.ResponseWriter, r *http.Request) {
+	writeHeaders(w)
+	if r.URL.RawQuery != "" {
+		buildJSONErrorResponse(w, "read-only replica: query parameters are only supported on the active scheduler instance", http.StatusNotImplemented)
+		return
+	}
+	rc.RLock()
+	snapshot := rc.snapshot
+	fetched := rc.fetched
+	rc.RUnlock()
+	if snapshot == nil {
+		buildJSONErrorResponse(w, "read-only replica: no snapshot of the active scheduler instance", http.StatusServiceUnavailable)
+		return
+	}
+	response, ok := snapshot.Responses[r.URL.Path]
+	if !ok {
+		buildJSONErrorResponse(w, "read-only replica: "+r.URL.Path+" not found in the snapshot", http.StatusBadRequest)
+		return
+	}
+	if response.ContentType != "" {
+		w.Header().Set("Content-Type", response.ContentType)
+	}
+	w.Header().Set("Age", fmt.Sprintf("%d", int64(time.Since(fetched)/time.Second)))
+	w.WriteHeader(response.Status)
+	if _, err := w.Write(response.Body); err != nil {
+		log.Logger().Debug("replica response write failed",
+			zap.String("path", r.URL.Path),
+			zap.Error(err))
+	}
+}
+
+// Replace the snapshot with the current snapshot of the active instance.
+// A failed refresh leaves the old snapshot in place.
+func (rc *replicaCache) refresh() error {
+	req, err := http.NewRequest(http.MethodGet, rc.active+replicaSnapshotPath, nil)
+	if err != nil {
+		return fmt.Errorf("snapshot request to active scheduler instance failed: %v", err)
+	}
+	if rc.token != "" {
+		req.Header.Set("Authorization", bearerPrefix+rc.token)
+	}
+	resp, err := rc.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("active scheduler instance not reachable: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("snapshot request to active scheduler instance failed: %s", resp.Status)
+	}
+	snapshot := &dao.RESTSnapshotDAOInfo{}
+	if err = json.NewDecoder(resp.Body).Decode(snapshot); err != nil {
+		return fmt.Errorf("failed to read snapshot from active scheduler instance: %v", err)
+	}
+	rc.Lock()
+	defer rc.Unlock()
+	rc.snapshot = snapshot
+	rc.fetched = time.Now()
+	return nil
+}
+
+// Refresh the snapshot right away and then every interval.
+func (rc *replicaCache) startRefresh() {
+	go func() {
+		ticker := time.NewTicker(rc.interval)
+		defer ticker.Stop()
+		for {
+			if err := rc.refresh(); err != nil {
+				log.Logger().Warn("replica snapshot refresh failed",
+					zap.String("active", rc.active),
+					zap.Error(err))
+			}
+			select {
+			case <-rc.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (rc *replicaCache) stopRefresh() {
+	close(rc.stop)
+}
@@ -0,0 +1,152 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package webservice
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common"
+	"github.com/apache/incubator-yunikorn-core/pkg/common/configs"
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/objects"
+	"github.com/apache/incubator-yunikorn-core/pkg/webservice/dao"
+	"github.com/apache/incubator-yunikorn-scheduler-interface/lib/go/si"
+)
+
+func TestBuildRESTSnapshot(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(configDefault))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load clusterInfo from config")
+	partition := schedulerContext.GetPartition(common.GetNormalizedPartitionName("default", rmID))
+	nodeRes := resources.NewResourceFromMap(map[string]resources.Quantity{resources.MEMORY: 1000}).ToProto()
+	err = partition.AddNode(objects.NewNode(&si.NewNodeInfo{NodeID: "node-1", SchedulableResource: nodeRes}), nil)
+	assert.NilError(t, err, "add node failed")
+
+	snapshot := buildRESTSnapshot(snapshotRoutes)
+	assert.Equal(t, snapshot.Version, schedulerContext.GetStateVersion())
+	for _, path := range []string{
+		"/ws/v1/partitions",
+		"/ws/v1/partition/default/queues",
+		"/ws/v1/partition/default/queue/root",
+		"/ws/v1/partition/default/queue/root.default",
+		"/ws/v1/partition/default/nodes",
+	} {
+		response, ok := snapshot.Responses[path]
+		assert.Assert(t, ok, "path missing from snapshot: %s", path)
+		assert.Equal(t, response.Status, http.StatusOK, "unexpected status for %s", path)
+	}
+	var queue dao.PartitionQueueDAOInfo
+	err = json.Unmarshal(snapshot.Responses["/ws/v1/partition/default/queue/root.default"].Body, &queue)
+	assert.NilError(t, err, "queue response unmarshal failed")
+	assert.Equal(t, queue.QueueName, "root.default")
+	// the key set is bounded by the routes and the state
+	for path := range snapshot.Responses {
+		assert.Assert(t, !strings.Contains(path, "{"), "unexpanded path in snapshot: %s", path)
+		assert.Assert(t, path != replicaSnapshotPath && path != "/ws/v1/stream", "route should not be in snapshot: %s", path)
+	}
+	// instance information, expensive and caller specific endpoints are not in the snapshot
+	for _, path := range []string{
+		"/ws/v1/stack",
+		"/ws/v1/metrics",
+		"/ws/v1/status",
+		"/ws/v1/scheduler/healthcheck",
+		"/ws/v1/partition/default/node/node-1/removal-impact",
+		"/ws/v1/partition/default/queue/root/access",
+	} {
+		_, ok := snapshot.Responses[path]
+		assert.Assert(t, !ok, "path should not be in snapshot: %s", path)
+	}
+}
+
+func TestReplicaCache(t *testing.T) {
+	var requests int32
+	snapshot := dao.RESTSnapshotDAOInfo{
+		Version: 1,
+		Responses: map[string]dao.RESTResponseDAOInfo{
+			"/ws/v1/partition/default/nodes":                           {Status: http.StatusOK, ContentType: "application/json", Body: []byte(`{"path":"nodes"}`)},
+			"/ws/v1/partition/default/queue/root.missing/applications": {Status: http.StatusBadRequest, Body: []byte(`{"message":"Queue not found"}`)},
+		},
+	}
+	active := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		assert.Equal(t, r.URL.Path, replicaSnapshotPath, "replica should only request the snapshot")
+		assert.Equal(t, r.Header.Get("Authorization"), "Bearer admin-token", "replica should send the token")
+		err := json.NewEncoder(w).Encode(snapshot)
+		assert.NilError(t, err, "active server write failed")
+	}))
+	cache := newReplicaCache(active.URL, "admin-token", time.Minute)
+	router := newReplicaRouter(cache)
+	request := func(method, uri string) *httptest.ResponseRecorder {
+		resp := httptest.NewRecorder()
+		req, err := http.NewRequest(method, uri, nil)
+		assert.NilError(t, err, "request create failed")
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	// nothing to serve before the first refresh
+	resp := request("GET", "/ws/v1/partition/default/nodes")
+	assert.Equal(t, resp.Code, http.StatusServiceUnavailable, "replica without snapshot should be unavailable")
+
+	// all requests are served from the one snapshot
+	err := cache.refresh()
+	assert.NilError(t, err, "refresh failed")
+	for i := 0; i < 3; i++ {
+		resp = request("GET", "/ws/v1/partition/default/nodes")
+		assert.Equal(t, resp.Code, http.StatusOK, "unexpected status code")
+		assert.Equal(t, resp.Body.String(), `{"path":"nodes"}`, "unexpected body")
+	}
+	resp = request("GET", "/ws/v1/partition/default/queue/root.missing/applications")
+	assert.Equal(t, resp.Code, http.StatusBadRequest, "stored error status should be served")
+	assert.Equal(t, atomic.LoadInt32(&requests), int32(1), "requests should not reach the active instance")
+
+	// query variants and paths not in the snapshot are not fetched
+	resp = request("GET", "/ws/v1/partition/default/nodes?limit=1")
+	assert.Equal(t, resp.Code, http.StatusNotImplemented, "query parameters should not be implemented")
+	resp = request("GET", "/ws/v1/status")
+	assert.Equal(t, resp.Code, http.StatusNotImplemented, "endpoint not in the snapshot should not be implemented")
+	resp = request("GET", "/ws/v1/partition/default/node/node-1/removal-impact")
+	assert.Equal(t, resp.Code, http.StatusNotImplemented, "endpoint not in the snapshot should not be implemented")
+	resp = request("GET", "/ws/v1/partition/other/nodes")
+	assert.Equal(t, resp.Code, http.StatusBadRequest, "unknown path should be rejected")
+	assert.Equal(t, atomic.LoadInt32(&requests), int32(1), "requests should not reach the active instance")
+
+	// changes, the event stream and the snapshot are rejected
+	for _, req := range [][2]string{{"PUT", "/ws/v1/config"}, {"GET", "/ws/v1/stream"}, {"GET", replicaSnapshotPath}} {
+		resp = request(req[0], req[1])
+		assert.Equal(t, resp.Code, http.StatusMethodNotAllowed, "replica should reject %s %s", req[0], req[1])
+	}
+
+	// active instance not reachable: the stale snapshot is served
+	active.Close()
+	err = cache.refresh()
+	assert.ErrorContains(t, err, "not reachable", "refresh should fail")
+	resp = request("GET", "/ws/v1/partition/default/nodes")
+	assert.Equal(t, resp.Code, http.StatusOK, "stale snapshot should have been served")
+}
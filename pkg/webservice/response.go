@@ -0,0 +1,166 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package webservice
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/log"
+)
+
+// GET endpoints that only return scheduler state: the responses are tagged with the scheduler state version.
+var versionedRoutes = map[string]bool{
+	"/ws/v1/queues":                       true,
+	"/ws/v1/clusters":                     true,
+	"/ws/v1/clusters/utilization":         true,
+	"/ws/v1/apps":                         true,
+	"/ws/v1/nodes":                        true,
+	"/ws/v1/nodes/utilization":            true,
+	"/ws/v1/partitions":                   true,
+	"/ws/v1/partition/{partition}/queues": true,
+	"/ws/v1/partition/{partition}/nodes":  true,
+	"/ws/v1/partition/{partition}/node/{node}/removal-impact": true,
+	"/ws/v1/partition/{partition}/queue/{queue}/applications": true,
+}
+
+// Start of this instance, part of the ETag to make sure a tag is never reused after a restart.
+var versionEpoch = time.Now().UnixNano()
+
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	writer io.Writer
+}
+
+// Write the compressed data. The content type is detected on the uncompressed data if not set by the handler.
+func (w gzipResponseWriter) Write(b []byte) (int, error) {
+	if w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", http.DetectContentType(b))
+	}
+	return w.writer.Write(b)
+}
+
+// Flush the compressed data written so far to the client, used when streaming a response.
+func (w gzipResponseWriter) Flush() {
+	if gz, ok := w.writer.(*gzip.Writer); ok {
+		if err := gz.Flush(); err != nil {
+			return
+		}
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Compress the response of the handler if the client accepts a gzip encoded response.
+func gzipHandler(inner http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		if !acceptsGzip(r) {
+			inner.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Header().Del("Content-Length")
+		gz := gzip.NewWriter(w)
+		defer func() {
+			if err := gz.Close(); err != nil {
+				log.Logger().Debug("failed to close compressed response",
+					zap.String("request", r.RequestURI),
+					zap.Error(err))
+			}
+		}()
+		inner.ServeHTTP(gzipResponseWriter{ResponseWriter: w, writer: gz}, r)
+	})
+}
+
+// Check the Accept-Encoding header of the request for gzip support.
+func acceptsGzip(r *http.Request) bool {
+	for _, encoding := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		parts := strings.Split(encoding, ";")
+		if strings.TrimSpace(parts[0]) != "gzip" {
+			continue
+		}
+		// an explicit q=0 means gzip is not acceptable
+		if len(parts) > 1 && strings.Replace(parts[1], " ", "", -1) == "q=0" {
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+// Tag the response of a state only GET endpoint with the scheduler state version and respond with
+// a 304 Not Modified if the client already has the response for the current version.
+// A request that could change the scheduler state marks the state as changed after it is processed.
+func versionHandler(inner http.Handler, method, pattern string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if schedulerContext == nil {
+			inner.ServeHTTP(w, r)
+			return
+		}
+		if method != http.MethodGet {
+			inner.ServeHTTP(w, r)
+			schedulerContext.MarkStateChanged()
+			return
+		}
+		if !versionedRoutes[pattern] {
+			inner.ServeHTTP(w, r)
+			return
+		}
+		etag := getETag(r)
+		w.Header().Set("ETag", etag)
+		if matchETag(r.Header.Get("If-None-Match"), etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		inner.ServeHTTP(w, r)
+	})
+}
+
+// Return the weak ETag for the current scheduler state version.
+// A redacted response is a different representation than the full response and has a different tag.
+func getETag(r *http.Request) string {
+	redacted := ""
+	if getRedactor(r) != nil {
+		redacted = "-r"
+	}
+	return fmt.Sprintf("W/\"%x-%x%s\"", versionEpoch, schedulerContext.GetStateVersion(), redacted)
+}
+
+// Check if the If-None-Match header value matches the tag, tags are compared using the weak comparison.
+func matchETag(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
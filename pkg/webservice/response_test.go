@@ -0,0 +1,143 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package webservice
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/configs"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler"
+)
+
+func TestAcceptsGzip(t *testing.T) {
+	tests := map[string]bool{
+		"":                       false,
+		"identity":               false,
+		"gzip":                   true,
+		"deflate, gzip;q=0.8":    true,
+		"deflate, gzip; q=0":     false,
+		"br,gzip":                true,
+		"xgzip":                  false,
+		"gzip;q=1.0, identity":   true,
+		"identity;q=0.5, *;q=0":  false,
+		"compress;q=0.5, gzip  ": true,
+	}
+	for header, expected := range tests {
+		req, err := http.NewRequest("GET", "/ws/v1/apps", nil)
+		assert.NilError(t, err, "failed to create request")
+		req.Header.Set("Accept-Encoding", header)
+		assert.Equal(t, acceptsGzip(req), expected, "unexpected result for header '%s'", header)
+	}
+}
+
+func TestMatchETag(t *testing.T) {
+	etag := "W/\"1-2\""
+	assert.Assert(t, !matchETag("", etag), "empty header should not match")
+	assert.Assert(t, matchETag(etag, etag), "same tag should match")
+	assert.Assert(t, matchETag("\"1-2\"", etag), "strong tag should match using weak comparison")
+	assert.Assert(t, matchETag("W/\"0-1\", W/\"1-2\"", etag), "tag in list should match")
+	assert.Assert(t, matchETag("*", etag), "wildcard should match")
+	assert.Assert(t, !matchETag("W/\"1-1\"", etag), "other tag should not match")
+}
+
+func TestGzipHandler(t *testing.T) {
+	body := "{\"key\":\"value\"}"
+	handler := gzipHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(body))
+		assert.NilError(t, err, "write failed")
+	}))
+
+	// no compression requested
+	req, err := http.NewRequest("GET", "/ws/v1/apps", nil)
+	assert.NilError(t, err, "failed to create request")
+	resp := httptest.NewRecorder()
+	handler.ServeHTTP(resp, req)
+	assert.Equal(t, resp.Header().Get("Content-Encoding"), "", "response should not be compressed")
+	assert.Equal(t, resp.Header().Get("Vary"), "Accept-Encoding", "vary header not set")
+	assert.Equal(t, resp.Body.String(), body, "unexpected uncompressed body")
+
+	// compressed response, content type is detected on the uncompressed data
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp = httptest.NewRecorder()
+	handler.ServeHTTP(resp, req)
+	assert.Equal(t, resp.Header().Get("Content-Encoding"), "gzip", "response should be compressed")
+	assert.Equal(t, resp.Header().Get("Content-Type"), "text/plain; charset=utf-8", "unexpected content type")
+	var reader *gzip.Reader
+	reader, err = gzip.NewReader(resp.Body)
+	assert.NilError(t, err, "response is not gzip compressed")
+	var data []byte
+	data, err = ioutil.ReadAll(reader)
+	assert.NilError(t, err, "failed to decompress response")
+	assert.Equal(t, string(data), body, "unexpected decompressed body")
+}
+
+func TestVersionHandler(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(configDefault))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+
+	calls := 0
+	inner := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	})
+	handler := versionHandler(inner, http.MethodGet, "/ws/v1/apps")
+	req, err := http.NewRequest("GET", "/ws/v1/apps", nil)
+	assert.NilError(t, err, "failed to create request")
+	resp := httptest.NewRecorder()
+	handler.ServeHTTP(resp, req)
+	etag := resp.Header().Get("ETag")
+	assert.Assert(t, etag != "", "ETag not set on versioned route")
+	assert.Equal(t, resp.Code, http.StatusOK, "first request should return the response")
+	assert.Equal(t, calls, 1, "handler not called")
+
+	// unchanged state: not modified
+	req.Header.Set("If-None-Match", etag)
+	resp = httptest.NewRecorder()
+	handler.ServeHTTP(resp, req)
+	assert.Equal(t, resp.Code, http.StatusNotModified, "unchanged state should not be modified")
+	assert.Equal(t, calls, 1, "handler should not be called for an unchanged state")
+
+	// a state changing request changes the tag
+	var post *http.Request
+	post, err = http.NewRequest("POST", "/ws/v1/partition/default/queues", nil)
+	assert.NilError(t, err, "failed to create request")
+	versionHandler(inner, http.MethodPost, "/ws/v1/partition/{partition}/queues").ServeHTTP(httptest.NewRecorder(), post)
+	assert.Equal(t, calls, 2, "handler not called for state change")
+	resp = httptest.NewRecorder()
+	handler.ServeHTTP(resp, req)
+	assert.Equal(t, resp.Code, http.StatusOK, "changed state should return the response")
+	assert.Equal(t, calls, 3, "handler not called for a changed state")
+	assert.Assert(t, resp.Header().Get("ETag") != etag, "ETag should change with the state")
+
+	// routes that are not versioned are always served
+	req, err = http.NewRequest("GET", "/ws/v1/stack", nil)
+	assert.NilError(t, err, "failed to create request")
+	req.Header.Set("If-None-Match", "*")
+	resp = httptest.NewRecorder()
+	versionHandler(inner, http.MethodGet, "/ws/v1/stack").ServeHTTP(resp, req)
+	assert.Equal(t, resp.Code, http.StatusOK, "route without versions should always be served")
+	assert.Equal(t, resp.Header().Get("ETag"), "", "route without versions should not have an ETag")
+}
@@ -113,6 +113,22 @@ var webRoutes = routes{
 		createClusterConfig,
 	},
 
+	// endpoint to list the history of loaded confs
+	route{
+		"Scheduler",
+		"GET",
+		"/ws/v1/configs",
+		getClusterConfigHistory,
+	},
+
+	// endpoint to roll back to a conf from the history
+	route{
+		"Scheduler",
+		"POST",
+		"/ws/v1/configs",
+		rollbackClusterConfig,
+	},
+
 	// endpoint to validate conf
 	route{
 		"Scheduler",
@@ -134,30 +150,150 @@ var webRoutes = routes{
 		"/ws/v1/history/containers",
 		getContainerHistory,
 	},
+	route{
+		"Scheduler",
+		"GET",
+		"/ws/v1/reports/forecast",
+		getQueueForecast,
+	},
+	route{
+		"Scheduler",
+		"GET",
+		"/ws/v1/reports/user-fairness",
+		getUserFairness,
+	},
 	route{
 		"Partitions",
 		"GET",
 		"/ws/v1/partitions",
 		getPartitions,
 	},
+	route{
+		"Scheduler",
+		"GET",
+		"/ws/v1/rms",
+		getRMInfo,
+	},
+	route{
+		"Scheduler",
+		"GET",
+		"/ws/v1/featuregates",
+		getFeatureGates,
+	},
+	route{
+		"Scheduler",
+		"PUT",
+		"/ws/v1/partition/{partition}/scheduling",
+		updatePartitionScheduling,
+	},
 	route{
 		"Scheduler",
 		"GET",
 		"/ws/v1/partition/{partition}/queues",
 		getPartitionQueues,
 	},
+	route{
+		"Scheduler",
+		"PATCH",
+		"/ws/v1/partition/{partition}/queues/limits",
+		updateQueueLimits,
+	},
+	route{
+		"Scheduler",
+		"POST",
+		"/ws/v1/partition/{partition}/queues",
+		createQueue,
+	},
+	route{
+		"Scheduler",
+		"PUT",
+		"/ws/v1/partition/{partition}/queues",
+		updateQueue,
+	},
+	route{
+		"Scheduler",
+		"DELETE",
+		"/ws/v1/partition/{partition}/queues",
+		deleteQueue,
+	},
 	route{
 		"Scheduler",
 		"GET",
 		"/ws/v1/partition/{partition}/nodes",
 		getPartitionNodes,
 	},
+	route{
+		"Scheduler",
+		"PUT",
+		"/ws/v1/partition/{partition}/node/{node}/taints",
+		updateNodeTaints,
+	},
+	route{
+		"Scheduler",
+		"PUT",
+		"/ws/v1/partition/{partition}/node/{node}/schedulable",
+		updateNodeSchedulable,
+	},
+	route{
+		"Scheduler",
+		"PUT",
+		"/ws/v1/partition/{partition}/node/{node}/drain",
+		drainNode,
+	},
+	route{
+		"Scheduler",
+		"GET",
+		"/ws/v1/partition/{partition}/node/{node}/removal-impact",
+		getNodeRemovalImpact,
+	},
+	route{
+		"Scheduler",
+		"GET",
+		"/ws/v1/partition/{partition}/queue/{queue}",
+		getPartitionQueue,
+	},
 	route{
 		"Scheduler",
 		"GET",
 		"/ws/v1/partition/{partition}/queue/{queue}/applications",
 		getQueueApplications,
 	},
+	route{
+		"Scheduler",
+		"DELETE",
+		"/ws/v1/partition/{partition}/application/{appID}",
+		killApplication,
+	},
+	route{
+		"Scheduler",
+		"GET",
+		"/ws/v1/partition/{partition}/queue/{queue}/access",
+		checkQueueAccess,
+	},
+	route{
+		"Scheduler",
+		"PUT",
+		"/ws/v1/partition/{partition}/queue/{queue}/move",
+		moveQueue,
+	},
+	route{
+		"Scheduler",
+		"PUT",
+		"/ws/v1/partition/{partition}/queue/{queue}/scheduling",
+		updateQueueScheduling,
+	},
+	route{
+		"Scheduler",
+		"GET",
+		"/ws/v1/partition/{partition}/counters",
+		getPartitionCounters,
+	},
+	route{
+		"Scheduler",
+		"GET",
+		"/ws/v1/partition/{partition}/resources",
+		getPartitionResources,
+	},
 	// endpoint to retrieve CPU, Memory profiling data,
 	// this works with pprof tool. By default, pprof endpoints
 	// are only registered to http.DefaultServeMux. Here, we
@@ -235,4 +371,46 @@ var webRoutes = routes{
 		"/ws/v1/scheduler/healthcheck",
 		checkHealthStatus,
 	},
+	route{
+		"Scheduler",
+		"GET",
+		"/ws/v1/status",
+		getStatus,
+	},
+	// endpoint to check readiness: served by the instance itself, also in a replica
+	route{
+		"Readiness",
+		"GET",
+		"/ws/v1/readiness",
+		getReadiness,
+	},
+	// endpoint to retrieve the responses of all scheduler GET endpoints for a read-only replica
+	route{
+		"Snapshot",
+		"GET",
+		"/ws/v1/replica/snapshot",
+		getRESTSnapshot,
+	},
+	// endpoint to long poll for events: not part of the replica snapshot
+	route{
+		"Stream",
+		"GET",
+		"/ws/v1/stream",
+		getEventStream,
+	},
+	// endpoint to retrieve the OpenAPI document of the REST API
+	route{
+		"Scheduler",
+		"GET",
+		"/ws/v1/openapi.json",
+		getOpenAPI,
+	},
+}
+
+// The routes included in the REST snapshot. Set on init: the snapshot handler calls the handlers of the other
+// routes, referencing the routes directly from the handler would be an initialization loop.
+var snapshotRoutes routes
+
+func init() {
+	snapshotRoutes = webRoutes
 }
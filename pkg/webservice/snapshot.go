@@ -0,0 +1,165 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package webservice
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/objects"
+	"github.com/apache/incubator-yunikorn-core/pkg/webservice/dao"
+)
+
+// Get the REST snapshot: the responses of the scheduler GET endpoints for the current state in one response.
+// A read-only replica refreshes from the snapshot instead of fetching each endpoint from this instance.
+// The snapshot exposes the full scheduler state: only an admin is allowed to get it, see authHandler.
+func getRESTSnapshot(w http.ResponseWriter, r *http.Request) {
+	writeHeaders(w)
+	if err := json.NewEncoder(w).Encode(buildRESTSnapshot(snapshotRoutes)); err != nil {
+		buildJSONErrorResponse(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Build the snapshot by calling the handlers of the snapshot routes for every path the routes have in the
+// current state: one path per partition, queue and node for the routes with these variables in the pattern.
+// The handlers are called without a caller identity, the responses are redacted if redaction is enabled.
+func buildRESTSnapshot(snapshotRoutes routes) *dao.RESTSnapshotDAOInfo {
+	snapshot := &dao.RESTSnapshotDAOInfo{
+		Version:   schedulerContext.GetStateVersion(),
+		Created:   time.Now().UnixNano(),
+		Responses: make(map[string]dao.RESTResponseDAOInfo),
+	}
+	for _, webRoute := range snapshotRoutes {
+		if !isSnapshotRoute(webRoute) {
+			continue
+		}
+		for _, vars := range getPatternVars(webRoute.Pattern) {
+			path := webRoute.Pattern
+			for name, value := range vars {
+				path = strings.Replace(path, "{"+name+"}", value, 1)
+			}
+			req := httptest.NewRequest(http.MethodGet, "http://localhost/", nil)
+			req.URL.Path = path
+			req = mux.SetURLVars(req, vars)
+			resp := httptest.NewRecorder()
+			webRoute.HandlerFunc(resp, req)
+			snapshot.Responses[path] = dao.RESTResponseDAOInfo{
+				Status:      resp.Code,
+				ContentType: resp.Header().Get("Content-Type"),
+				Body:        resp.Body.Bytes(),
+			}
+		}
+	}
+	return snapshot
+}
+
+// The GET endpoints in the REST snapshot: the endpoints that list or read the scheduler state. Endpoints that are
+// expensive for each object (node removal impact), need a caller (queue access) or describe the instance that
+// serves the request (stack, metrics, status, health) are not part of the snapshot.
+var snapshotPatterns = map[string]bool{
+	"/ws/v1/queues":                              true,
+	"/ws/v1/clusters":                            true,
+	"/ws/v1/clusters/utilization":                true,
+	"/ws/v1/apps":                                true,
+	"/ws/v1/nodes":                               true,
+	"/ws/v1/nodes/utilization":                   true,
+	"/ws/v1/config":                              true,
+	"/ws/v1/configs":                             true,
+	"/ws/v1/history/apps":                        true,
+	"/ws/v1/history/containers":                  true,
+	"/ws/v1/reports/forecast":                    true,
+	"/ws/v1/reports/user-fairness":               true,
+	"/ws/v1/partitions":                          true,
+	"/ws/v1/rms":                                 true,
+	"/ws/v1/featuregates":                        true,
+	"/ws/v1/partition/{partition}/queues":        true,
+	"/ws/v1/partition/{partition}/nodes":         true,
+	"/ws/v1/partition/{partition}/queue/{queue}": true,
+	"/ws/v1/partition/{partition}/queue/{queue}/applications": true,
+	"/ws/v1/partition/{partition}/counters":                   true,
+	"/ws/v1/partition/{partition}/resources":                  true,
+}
+
+// Check if the route is part of the REST snapshot.
+func isSnapshotRoute(webRoute route) bool {
+	return webRoute.Method == http.MethodGet && snapshotPatterns[webRoute.Pattern]
+}
+
+// Get the variables for all paths of the pattern in the current state.
+// Returns nil if the pattern has a variable that is not a partition, queue or node.
+func getPatternVars(pattern string) []map[string]string {
+	names := make(map[string]bool)
+	for _, match := range pathParamRegExp.FindAllStringSubmatch(pattern, -1) {
+		names[match[1]] = true
+	}
+	if len(names) == 0 {
+		return []map[string]string{{}}
+	}
+	if !names["partition"] {
+		return nil
+	}
+	delete(names, "partition")
+	var result []map[string]string
+	for _, partition := range schedulerContext.GetPartitionMapClone() {
+		partitionName := common.GetPartitionNameWithoutClusterID(partition.Name)
+		switch {
+		case len(names) == 0:
+			result = append(result, map[string]string{"partition": partitionName})
+		case len(names) == 1 && names["queue"]:
+			for _, queuePath := range getQueuePaths(partition.GetQueue("root"), nil) {
+				result = append(result, map[string]string{"partition": partitionName, "queue": queuePath})
+			}
+		case len(names) == 1 && names["node"]:
+			for _, nodeID := range getNodeIDs(partition) {
+				result = append(result, map[string]string{"partition": partitionName, "node": nodeID})
+			}
+		default:
+			return nil
+		}
+	}
+	return result
+}
+
+// Get the paths of the queue and all its descendants.
+func getQueuePaths(queue *objects.Queue, paths []string) []string {
+	if queue == nil {
+		return paths
+	}
+	paths = append(paths, queue.GetQueuePath())
+	for _, child := range queue.GetCopyOfChildren() {
+		paths = getQueuePaths(child, paths)
+	}
+	return paths
+}
+
+func getNodeIDs(partition *scheduler.PartitionContext) []string {
+	nodes := partition.GetNodes()
+	nodeIDs := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		nodeIDs = append(nodeIDs, node.NodeID)
+	}
+	return nodeIDs
+}
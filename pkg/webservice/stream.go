@@ -0,0 +1,125 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package webservice
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/log"
+)
+
+// The number of elements written to a streamed JSON array before the response is flushed to the client.
+var streamFlushInterval = 100
+
+// Write a JSON array to the response one element at a time, the full array is never built in memory.
+// The response is flushed regularly which sends it chunked to the client.
+// The output is the same as encoding the slice: an array without elements is written as null.
+// After the first write error all further writes are skipped and the error is returned on close.
+// The stream stops when the request context is done: the request timed out or the client is gone. The response
+// is incomplete in that case, the status was sent with the first element already.
+type jsonArrayStream struct {
+	ctx     context.Context
+	w       io.Writer
+	flusher http.Flusher
+	count   int
+	err     error
+}
+
+func newJSONArrayStream(w http.ResponseWriter, r *http.Request) *jsonArrayStream {
+	flusher, _ := w.(http.Flusher)
+	return &jsonArrayStream{
+		ctx:     r.Context(),
+		w:       w,
+		flusher: flusher,
+	}
+}
+
+// Create a stream for an array nested in an element of this stream, sharing the writer.
+func (s *jsonArrayStream) nested() *jsonArrayStream {
+	return &jsonArrayStream{
+		ctx:     s.ctx,
+		w:       s.w,
+		flusher: s.flusher,
+	}
+}
+
+// Add the element to the array.
+func (s *jsonArrayStream) add(element interface{}) {
+	if s.err == nil {
+		s.err = s.ctx.Err()
+	}
+	if s.err != nil {
+		return
+	}
+	data, err := json.Marshal(element)
+	if err != nil {
+		s.err = err
+		return
+	}
+	s.next()
+	s.write(data)
+}
+
+// Start the next element of the array: the element is written by the caller.
+func (s *jsonArrayStream) next() {
+	if s.count == 0 {
+		s.write([]byte("["))
+	} else {
+		s.write([]byte(","))
+	}
+	s.count++
+	if s.flusher != nil && s.count%streamFlushInterval == 0 {
+		s.flusher.Flush()
+	}
+}
+
+// Write raw data to the response.
+func (s *jsonArrayStream) write(data []byte) {
+	if s.err != nil {
+		return
+	}
+	_, s.err = s.w.Write(data)
+}
+
+// Close the array, a top level array is terminated by a newline as it is when encoded.
+func (s *jsonArrayStream) close(topLevel bool) error {
+	if s.count == 0 {
+		s.write([]byte("null"))
+	} else {
+		s.write([]byte("]"))
+	}
+	if topLevel {
+		s.write([]byte("\n"))
+	}
+	return s.err
+}
+
+// Log the failure of a streamed response: an error response cannot be sent after the stream started.
+func logStreamError(r *http.Request, err error) {
+	if err != nil {
+		log.Logger().Warn("failed to stream response",
+			zap.String("request", r.RequestURI),
+			zap.Error(err))
+	}
+}
@@ -0,0 +1,97 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package webservice
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/webservice/dao"
+)
+
+type failingWriter struct {
+	MockResponseWriter
+}
+
+func (fw *failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestJSONArrayStream(t *testing.T) {
+	current := streamFlushInterval
+	defer func() { streamFlushInterval = current }()
+	streamFlushInterval = 2
+
+	// the streamed output must be the same as the encoded slice
+	var elements []*dao.NodeDAOInfo
+	for _, count := range []int{0, 1, 5} {
+		if count > 0 {
+			elements = nil
+			for i := 0; i < count; i++ {
+				elements = append(elements, &dao.NodeDAOInfo{NodeID: "node<" + string(rune('a'+i)) + ">"})
+			}
+		}
+		var expected bytes.Buffer
+		err := json.NewEncoder(&expected).Encode(elements)
+		assert.NilError(t, err, "failed to encode elements")
+
+		recorder := httptest.NewRecorder()
+		stream := newJSONArrayStream(recorder, httptest.NewRequest("GET", "/ws/v1/apps", nil))
+		for _, element := range elements {
+			stream.add(element)
+		}
+		assert.NilError(t, stream.close(true), "stream close failed")
+		assert.Equal(t, recorder.Body.String(), expected.String(), "unexpected output for %d elements", count)
+		assert.Equal(t, recorder.Flushed, count >= streamFlushInterval, "unexpected flush for %d elements", count)
+	}
+
+	// nested arrays share the writer
+	recorder := httptest.NewRecorder()
+	stream := newJSONArrayStream(recorder, httptest.NewRequest("GET", "/ws/v1/apps", nil))
+	stream.next()
+	nested := stream.nested()
+	nested.add(1)
+	nested.add(2)
+	assert.NilError(t, nested.close(false), "nested close failed")
+	stream.next()
+	assert.NilError(t, stream.nested().close(false), "empty nested close failed")
+	assert.NilError(t, stream.close(true), "stream close failed")
+	assert.Equal(t, recorder.Body.String(), "[[1,2],null]\n", "unexpected nested output")
+
+	// a write error is returned on close
+	stream = newJSONArrayStream(&failingWriter{}, httptest.NewRequest("GET", "/ws/v1/apps", nil))
+	stream.add(1)
+	assert.ErrorContains(t, stream.close(true), "write failed")
+
+	// the stream stops when the request context is done
+	ctx, cancel := context.WithCancel(context.Background())
+	recorder = httptest.NewRecorder()
+	stream = newJSONArrayStream(recorder, httptest.NewRequest("GET", "/ws/v1/apps", nil).WithContext(ctx))
+	stream.add(1)
+	cancel()
+	stream.add(2)
+	assert.Equal(t, stream.close(true), context.Canceled, "cancelled context should stop the stream")
+	assert.Equal(t, recorder.Body.String(), "[1", "elements should not be written after the cancel")
+}
@@ -0,0 +1,227 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package webservice
+
+import (
+	"crypto/tls"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common"
+	"github.com/apache/incubator-yunikorn-core/pkg/common/configs"
+	"github.com/apache/incubator-yunikorn-core/pkg/log"
+)
+
+// Environment variables used to configure TLS for the web service listener when the web service section of the
+// configuration has no TLS settings, e.g. for a read-only replica that has no configuration.
+// The certificate and key are either read from files or set inline as PEM, the files are used if both are set.
+// Certificates read from files are reloaded after the files change, the files are checked using the reload interval.
+const (
+	EnvTLSCertFile       = "WEBSERVICE_TLS_CERT_FILE"
+	EnvTLSKeyFile        = "WEBSERVICE_TLS_KEY_FILE"
+	EnvTLSCert           = "WEBSERVICE_TLS_CERT"
+	EnvTLSKey            = "WEBSERVICE_TLS_KEY"
+	EnvTLSReloadInterval = "WEBSERVICE_TLS_RELOAD_INTERVAL"
+)
+
+const defaultTLSReloadInterval = time.Minute
+
+// Return the TLS settings from the web service configuration, the settings from the environment if the
+// configuration has no TLS settings.
+func getTLSSettings(conf configs.WebServiceConfig) configs.WebServiceTLS {
+	if conf.TLS.IsSet() {
+		return conf.TLS
+	}
+	return configs.WebServiceTLS{
+		CertFile:       os.Getenv(EnvTLSCertFile),
+		KeyFile:        os.Getenv(EnvTLSKeyFile),
+		Cert:           os.Getenv(EnvTLSCert),
+		Key:            os.Getenv(EnvTLSKey),
+		ReloadInterval: common.GetDurationEnvVar(EnvTLSReloadInterval, defaultTLSReloadInterval),
+	}
+}
+
+// The certificate source serves the certificate of the listener. The certificate is replaced when the TLS
+// settings change on a configuration reload, a listener keeps serving with or without TLS as it was started.
+type certificateSource struct {
+	settings configs.WebServiceTLS // the settings the certificate was created from
+	loader   *certificateLoader    // the certificate from files, nil for an inline certificate
+	cert     *tls.Certificate      // the inline certificate
+
+	sync.RWMutex
+}
+
+// Create the certificate source for the settings.
+// Returns nil if TLS is not configured, an error if the settings are incomplete or the certificate is invalid.
+func newCertificateSource(settings configs.WebServiceTLS) (*certificateSource, error) {
+	if !settings.IsSet() {
+		return nil, nil
+	}
+	cs := &certificateSource{}
+	if err := cs.update(settings); err != nil {
+		return nil, err
+	}
+	return cs, nil
+}
+
+// Replace the certificate if the settings changed. The current certificate is kept if the new settings fail.
+func (cs *certificateSource) update(settings configs.WebServiceTLS) error {
+	cs.RLock()
+	unchanged := cs.settings == settings
+	cs.RUnlock()
+	if unchanged {
+		return nil
+	}
+	var loader *certificateLoader
+	var cert *tls.Certificate
+	switch {
+	case settings.CertFile != "" || settings.KeyFile != "":
+		if settings.CertFile == "" || settings.KeyFile == "" {
+			return fmt.Errorf("both the TLS certificate file and key file must be set")
+		}
+		interval := settings.ReloadInterval
+		if interval == 0 {
+			interval = defaultTLSReloadInterval
+		}
+		loader = newCertificateLoader(settings.CertFile, settings.KeyFile, interval)
+		if err := loader.load(); err != nil {
+			return err
+		}
+	case settings.Cert != "" && settings.Key != "":
+		pair, err := tls.X509KeyPair([]byte(settings.Cert), []byte(settings.Key))
+		if err != nil {
+			return fmt.Errorf("invalid inline TLS certificate: %v", err)
+		}
+		cert = &pair
+	default:
+		return fmt.Errorf("both the TLS certificate and key must be set")
+	}
+	cs.Lock()
+	defer cs.Unlock()
+	cs.settings = settings
+	cs.loader = loader
+	cs.cert = cert
+	return nil
+}
+
+// Return the certificate for the handshake.
+func (cs *certificateSource) getCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	cs.RLock()
+	loader := cs.loader
+	cert := cs.cert
+	cs.RUnlock()
+	if loader != nil {
+		return loader.getCertificate(hello)
+	}
+	return cert, nil
+}
+
+// Create the TLS configuration for the listener that serves the certificate from the source.
+func (cs *certificateSource) tlsConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: cs.getCertificate,
+	}
+}
+
+// The certificate loader serves the certificate from the files and reloads it after a rotation of the files.
+// The files are checked for changes on a handshake at most once per interval.
+type certificateLoader struct {
+	certFile  string
+	keyFile   string
+	interval  time.Duration
+	cert      *tls.Certificate
+	modified  time.Time // latest modification time of the files when the certificate was loaded
+	lastCheck time.Time
+
+	sync.RWMutex
+}
+
+func newCertificateLoader(certFile, keyFile string, interval time.Duration) *certificateLoader {
+	return &certificateLoader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		interval: interval,
+	}
+}
+
+// Load the certificate from the files.
+func (cl *certificateLoader) load() error {
+	modified, err := cl.getModified()
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(cl.certFile, cl.keyFile)
+	if err != nil {
+		return fmt.Errorf("invalid TLS certificate: %v", err)
+	}
+	cl.Lock()
+	defer cl.Unlock()
+	cl.cert = &cert
+	cl.modified = modified
+	cl.lastCheck = time.Now()
+	return nil
+}
+
+// Return the latest modification time of the certificate and key file.
+func (cl *certificateLoader) getModified() (time.Time, error) {
+	var modified time.Time
+	for _, file := range []string{cl.certFile, cl.keyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return modified, fmt.Errorf("TLS file not accessible: %v", err)
+		}
+		if info.ModTime().After(modified) {
+			modified = info.ModTime()
+		}
+	}
+	return modified, nil
+}
+
+// Return the certificate for the handshake, the certificate is reloaded first if the files have changed.
+// The current certificate is used if reloading fails: a partially written rotation is picked up on the next check.
+func (cl *certificateLoader) getCertificate(_ *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	cl.Lock()
+	if time.Since(cl.lastCheck) < cl.interval {
+		cert := cl.cert
+		cl.Unlock()
+		return cert, nil
+	}
+	cl.lastCheck = time.Now()
+	loaded := cl.modified
+	cl.Unlock()
+
+	if modified, err := cl.getModified(); err == nil && modified.After(loaded) {
+		if err = cl.load(); err != nil {
+			log.Logger().Warn("TLS certificate reload failed, using the current certificate",
+				zap.String("certFile", cl.certFile),
+				zap.Error(err))
+		} else {
+			log.Logger().Info("TLS certificate reloaded",
+				zap.String("certFile", cl.certFile))
+		}
+	}
+	cl.RLock()
+	defer cl.RUnlock()
+	return cl.cert, nil
+}
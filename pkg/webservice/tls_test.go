@@ -0,0 +1,177 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package webservice
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/configs"
+)
+
+// Generate a self signed certificate and key in PEM format for the common name.
+func generateCertificate(t *testing.T, commonName string) ([]byte, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NilError(t, err, "failed to generate key")
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NilError(t, err, "failed to create certificate")
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	assert.NilError(t, err, "failed to marshal key")
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+}
+
+// Return the common name of the leaf certificate.
+func getCommonName(t *testing.T, cert []byte) string {
+	t.Helper()
+	parsed, err := x509.ParseCertificate(cert)
+	assert.NilError(t, err, "failed to parse certificate")
+	return parsed.Subject.CommonName
+}
+
+func TestGetTLSSettings(t *testing.T) {
+	settings := getTLSSettings(configs.WebServiceConfig{})
+	assert.Assert(t, !settings.IsSet(), "TLS set without configuration")
+
+	// the environment is only used without settings in the configuration
+	assert.NilError(t, os.Setenv(EnvTLSCertFile, "env.crt"))
+	defer os.Unsetenv(EnvTLSCertFile)
+	assert.NilError(t, os.Setenv(EnvTLSKeyFile, "env.key"))
+	defer os.Unsetenv(EnvTLSKeyFile)
+	settings = getTLSSettings(configs.WebServiceConfig{})
+	assert.Equal(t, settings.CertFile, "env.crt", "environment fallback not used")
+	assert.Equal(t, settings.ReloadInterval, defaultTLSReloadInterval, "default reload interval not set")
+	conf := configs.WebServiceConfig{TLS: configs.WebServiceTLS{CertFile: "conf.crt", KeyFile: "conf.key"}}
+	settings = getTLSSettings(conf)
+	assert.Equal(t, settings, conf.TLS, "configuration should take precedence")
+}
+
+func TestCertificateSource(t *testing.T) {
+	source, err := newCertificateSource(configs.WebServiceTLS{})
+	assert.NilError(t, err, "no configuration should not fail")
+	assert.Assert(t, source == nil, "TLS configured without configuration")
+
+	// inline PEM
+	certPEM, keyPEM := generateCertificate(t, "inline")
+	_, err = newCertificateSource(configs.WebServiceTLS{Cert: string(certPEM)})
+	assert.ErrorContains(t, err, "must be set", "certificate without key should fail")
+	_, err = newCertificateSource(configs.WebServiceTLS{Cert: string(certPEM), Key: "invalid"})
+	assert.ErrorContains(t, err, "invalid inline TLS certificate")
+	inline := configs.WebServiceTLS{Cert: string(certPEM), Key: string(keyPEM)}
+	source, err = newCertificateSource(inline)
+	assert.NilError(t, err, "inline certificate should be valid")
+	tlsConfig := source.tlsConfig()
+	cert, err := tlsConfig.GetCertificate(nil)
+	assert.NilError(t, err, "failed to get certificate")
+	assert.Equal(t, getCommonName(t, cert.Certificate[0]), "inline")
+
+	// files take precedence
+	dir, err := ioutil.TempDir("", "webservice-tls")
+	assert.NilError(t, err, "failed to create temp dir")
+	defer os.RemoveAll(dir)
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+	certPEM, keyPEM = generateCertificate(t, "file")
+	assert.NilError(t, ioutil.WriteFile(certFile, certPEM, 0600))
+	assert.NilError(t, ioutil.WriteFile(keyFile, keyPEM, 0600))
+	files := inline
+	files.CertFile = certFile
+	err = source.update(files)
+	assert.ErrorContains(t, err, "must be set", "certificate file without key file should fail")
+	cert, err = tlsConfig.GetCertificate(nil)
+	assert.NilError(t, err, "failed to get certificate")
+	assert.Equal(t, getCommonName(t, cert.Certificate[0]), "inline", "failed update should keep the current certificate")
+
+	// a configuration update replaces the certificate served by the existing listener configuration
+	files.KeyFile = keyFile
+	err = source.update(files)
+	assert.NilError(t, err, "certificate files should be valid")
+	cert, err = tlsConfig.GetCertificate(nil)
+	assert.NilError(t, err, "failed to get certificate")
+	assert.Equal(t, getCommonName(t, cert.Certificate[0]), "file", "updated certificate should be served")
+	assert.Equal(t, source.loader.interval, defaultTLSReloadInterval, "default reload interval not set")
+
+	files.KeyFile = filepath.Join(dir, "missing.key")
+	_, err = newCertificateSource(files)
+	assert.ErrorContains(t, err, "TLS file not accessible")
+}
+
+func TestCertificateReload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "webservice-tls")
+	assert.NilError(t, err, "failed to create temp dir")
+	defer os.RemoveAll(dir)
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+	certPEM, keyPEM := generateCertificate(t, "first")
+	assert.NilError(t, ioutil.WriteFile(certFile, certPEM, 0600))
+	assert.NilError(t, ioutil.WriteFile(keyFile, keyPEM, 0600))
+
+	loader := newCertificateLoader(certFile, keyFile, 0)
+	assert.NilError(t, loader.load(), "initial load failed")
+	cert, err := loader.getCertificate(nil)
+	assert.NilError(t, err, "failed to get certificate")
+	assert.Equal(t, getCommonName(t, cert.Certificate[0]), "first")
+
+	// partially rotated: the new certificate does not match the old key, the current certificate is kept
+	future := time.Now().Add(time.Minute)
+	certPEM, keyPEM = generateCertificate(t, "second")
+	assert.NilError(t, ioutil.WriteFile(certFile, certPEM, 0600))
+	assert.NilError(t, os.Chtimes(certFile, future, future))
+	cert, err = loader.getCertificate(nil)
+	assert.NilError(t, err, "failed to get certificate")
+	assert.Equal(t, getCommonName(t, cert.Certificate[0]), "first", "mismatched rotation should keep the current certificate")
+
+	// rotation completed
+	assert.NilError(t, ioutil.WriteFile(keyFile, keyPEM, 0600))
+	assert.NilError(t, os.Chtimes(keyFile, future, future))
+	cert, err = loader.getCertificate(nil)
+	assert.NilError(t, err, "failed to get certificate")
+	assert.Equal(t, getCommonName(t, cert.Certificate[0]), "second", "rotated certificate should be loaded")
+
+	// the files are not checked again within the interval
+	loader.interval = time.Hour
+	certPEM, keyPEM = generateCertificate(t, "third")
+	assert.NilError(t, ioutil.WriteFile(certFile, certPEM, 0600))
+	assert.NilError(t, ioutil.WriteFile(keyFile, keyPEM, 0600))
+	later := future.Add(time.Minute)
+	assert.NilError(t, os.Chtimes(certFile, later, later))
+	assert.NilError(t, os.Chtimes(keyFile, later, later))
+	cert, err = loader.getCertificate(nil)
+	assert.NilError(t, err, "failed to get certificate")
+	assert.Equal(t, getCommonName(t, cert.Certificate[0]), "second", "files should not be checked within the interval")
+}
@@ -21,6 +21,7 @@ package webservice
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"sync"
 	"time"
@@ -28,8 +29,10 @@ import (
 	"github.com/gorilla/mux"
 	"go.uber.org/zap"
 
+	"github.com/apache/incubator-yunikorn-core/pkg/common/configs"
 	"github.com/apache/incubator-yunikorn-core/pkg/log"
 	"github.com/apache/incubator-yunikorn-core/pkg/metrics/history"
+	"github.com/apache/incubator-yunikorn-core/pkg/readiness"
 	"github.com/apache/incubator-yunikorn-core/pkg/scheduler"
 )
 
@@ -37,14 +40,35 @@ var imHistory *history.InternalMetricsHistory
 var lock sync.RWMutex
 var schedulerContext *scheduler.ClusterContext
 
+// address the web service listens on if it is not set in the configuration
+const defaultAddress = ":9080"
+
 type WebService struct {
 	httpServer *http.Server
+	handler    http.Handler
+	certs      *certificateSource // nil if the web service listens without TLS
+	replica    *replicaCache
+	startErr   error // error that stopped the web service from listening, nil if listening
+	started    bool
+
+	sync.RWMutex
 }
 
 func newRouter() *mux.Router {
 	router := mux.NewRouter().StrictSlash(true)
+	limits := newRequestLimitsFromEnv()
 	for _, webRoute := range webRoutes {
-		handler := loggingHandler(webRoute.HandlerFunc, webRoute.Name)
+		var handler http.Handler = webRoute.HandlerFunc
+		// the system endpoints for profiling are not compressed: some already return compressed data
+		// they are also not limited: a profile runs longer than a request timeout and is needed when the service is busy
+		if webRoute.Name != "System" {
+			handler = authHandler(handler, webRoute.Method, webRoute.Pattern)
+			handler = versionHandler(gzipHandler(handler), webRoute.Method, webRoute.Pattern)
+			handler = corsHandler(handler)
+			handler = limits.handler(handler, webRoute.Pattern)
+		}
+		handler = metricsHandler(handler, webRoute.Method, webRoute.Pattern)
+		handler = loggingHandler(handler, webRoute.Name)
 		router.
 			Methods(webRoute.Method).
 			Path(webRoute.Pattern).
@@ -63,19 +87,156 @@ func loggingHandler(inner http.Handler, name string) http.Handler {
 	})
 }
 
-// TODO we need the port to be configurable
 func (m *WebService) StartWebApp() {
-	router := newRouter()
-	m.httpServer = &http.Server{Addr: ":9080", Handler: router}
+	var webConf configs.WebServiceConfig
+	if m.replica == nil && schedulerContext != nil {
+		if conf := configs.ConfigContext.Get(schedulerContext.GetPolicyGroup()); conf != nil {
+			webConf = conf.WebService
+		}
+	}
+	// a broken TLS configuration must not expose the web service without TLS
+	certs, err := newCertificateSource(getTLSSettings(webConf))
+	if err != nil {
+		log.Logger().Error("web-app not started: TLS configuration failed",
+			zap.Error(err))
+		m.setStarted(nil, fmt.Errorf("TLS configuration failed: %v", err))
+		return
+	}
+	var router *mux.Router
+	if m.replica != nil {
+		router = newReplicaRouter(m.replica)
+		m.replica.startRefresh()
+		log.Logger().Info("web-app running as read-only replica",
+			zap.String("active", m.replica.active),
+			zap.Duration("refreshInterval", m.replica.interval))
+	} else {
+		router = newRouter()
+	}
+	m.handler = router
+	m.certs = certs
+	address := getAddress(webConf)
+	// bind before returning: an address that is in use is reported in the readiness report
+	server, err := m.listen(address)
+	if err != nil {
+		log.Logger().Error("web-app not started: listen failed",
+			zap.Error(err))
+		m.setStarted(nil, err)
+		return
+	}
+	m.setStarted(server, nil)
+	// the active instance follows the address in the configuration
+	if m.replica == nil && schedulerContext != nil {
+		schedulerContext.SetWebServiceConfigHandler(m.updateConfig)
+	}
+}
 
-	log.Logger().Info("web-app started", zap.Int("port", 9080))
+// Return the address from the web service configuration, the default address if not set.
+func getAddress(conf configs.WebServiceConfig) string {
+	if conf.Address == "" {
+		return defaultAddress
+	}
+	return conf.Address
+}
+
+// Bind to the address and serve the requests in the background.
+func (m *WebService) listen(address string) (*http.Server, error) {
+	server := &http.Server{Addr: address, Handler: m.handler}
+	if m.certs != nil {
+		server.TLSConfig = m.certs.tlsConfig()
+	}
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	log.Logger().Info("web-app started", zap.String("address", address), zap.Bool("tls", m.certs != nil))
 	go func() {
-		httpError := m.httpServer.ListenAndServe()
+		var httpError error
+		if server.TLSConfig != nil {
+			// the certificate is provided by the TLS configuration
+			httpError = server.ServeTLS(listener, "", "")
+		} else {
+			httpError = server.Serve(listener)
+		}
 		if httpError != nil && httpError != http.ErrServerClosed {
 			log.Logger().Error("HTTP serving error",
 				zap.Error(httpError))
 		}
 	}()
+	return server, nil
+}
+
+// Apply the web service configuration after a configuration update: replace a changed certificate and move the web
+// service to a changed address. The old server is only stopped after the new address is bound, if binding fails the
+// old address is kept.
+func (m *WebService) updateConfig(conf configs.WebServiceConfig) {
+	m.updateCertificate(getTLSSettings(conf))
+	address := getAddress(conf)
+	m.Lock()
+	defer m.Unlock()
+	if m.httpServer == nil || m.httpServer.Addr == address {
+		return
+	}
+	server, err := m.listen(address)
+	if err != nil {
+		log.Logger().Error("web-app address not changed: listen failed",
+			zap.String("address", address),
+			zap.Error(err))
+		return
+	}
+	old := m.httpServer
+	m.httpServer = server
+	// the request that changed the configuration could be served by the old server: stop it in the background
+	go func() {
+		if err := shutdown(old); err != nil {
+			log.Logger().Warn("web-app on old address not stopped cleanly",
+				zap.String("address", old.Addr),
+				zap.Error(err))
+		}
+	}()
+}
+
+// Apply the TLS settings after a configuration update. The certificate is replaced, the current certificate is
+// kept if the new settings fail. A listener cannot change between TLS and no TLS without a restart.
+func (m *WebService) updateCertificate(settings configs.WebServiceTLS) {
+	m.RLock()
+	certs := m.certs
+	started := m.httpServer != nil
+	m.RUnlock()
+	switch {
+	case !started:
+		return
+	case certs == nil && settings.IsSet():
+		log.Logger().Warn("web-app TLS not enabled: a restart is required to enable TLS")
+	case certs != nil && !settings.IsSet():
+		log.Logger().Warn("web-app TLS not disabled: a restart is required to disable TLS, the current certificate is kept")
+	case certs != nil:
+		if err := certs.update(settings); err != nil {
+			log.Logger().Error("web-app TLS certificate not changed",
+				zap.Error(err))
+		}
+	}
+}
+
+func (m *WebService) setStarted(server *http.Server, err error) {
+	m.Lock()
+	defer m.Unlock()
+	m.httpServer = server
+	m.started = true
+	m.startErr = err
+}
+
+// Readiness check for the web service: ready if the web service is listening.
+func (m *WebService) GetReadiness() (string, string) {
+	m.RLock()
+	defer m.RUnlock()
+	switch {
+	case !m.started:
+		return readiness.StatusNotReady, "web service not started"
+	case m.startErr != nil:
+		return readiness.StatusNotReady, m.startErr.Error()
+	default:
+		return readiness.StatusReady, "listening on " + m.httpServer.Addr
+	}
 }
 
 func NewWebApp(context *scheduler.ClusterContext, internalMetrics *history.InternalMetricsHistory) *WebService {
@@ -85,13 +246,34 @@ func NewWebApp(context *scheduler.ClusterContext, internalMetrics *history.Inter
 	return m
 }
 
+// Create a web app that runs as a read-only replica of the active scheduler instance.
+// The GET requests that list or read the scheduler state are served from the REST snapshot of the active instance,
+// the snapshot is refreshed using the interval. The snapshot is requested with the token if set, the token must
+// belong to an admin if authentication is enabled on the active instance. Requests with query parameters and
+// requests that change the scheduler state are rejected.
+func NewReplicaWebApp(active, token string, interval time.Duration) *WebService {
+	return &WebService{
+		replica: newReplicaCache(active, token, interval),
+	}
+}
+
 func (m *WebService) StopWebApp() error {
-	if m.httpServer != nil {
-		// graceful shutdown in 5 seconds
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
-		return m.httpServer.Shutdown(ctx)
+	if m.replica != nil {
+		m.replica.stopRefresh()
+	}
+	m.RLock()
+	server := m.httpServer
+	m.RUnlock()
+	if server != nil {
+		return shutdown(server)
 	}
 
 	return nil
 }
+
+// Stop the server, the graceful shutdown is limited to 5 seconds.
+func shutdown(server *http.Server) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return server.Shutdown(ctx)
+}
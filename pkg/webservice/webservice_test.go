@@ -0,0 +1,77 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package webservice
+
+import (
+	"net"
+	"net/http"
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/configs"
+	"github.com/apache/incubator-yunikorn-core/pkg/readiness"
+)
+
+// Return a local address that is free at the time of the call.
+func getFreeAddress(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NilError(t, err, "failed to find a free port")
+	address := listener.Addr().String()
+	assert.NilError(t, listener.Close(), "failed to release the port")
+	return address
+}
+
+func TestWebServiceAddressReload(t *testing.T) {
+	assert.Equal(t, getAddress(configs.WebServiceConfig{}), defaultAddress, "default address should be used")
+
+	m := &WebService{handler: http.NotFoundHandler()}
+	first := getFreeAddress(t)
+	server, err := m.listen(first)
+	assert.NilError(t, err, "listen failed")
+	m.setStarted(server, nil)
+	defer func() {
+		assert.NilError(t, m.StopWebApp(), "stop failed")
+	}()
+
+	// same address: nothing changes
+	m.updateConfig(configs.WebServiceConfig{Address: first})
+	assert.Equal(t, m.httpServer, server, "server should not have been replaced")
+
+	// changed address: the web service moves to the new address
+	second := getFreeAddress(t)
+	m.updateConfig(configs.WebServiceConfig{Address: second})
+	assert.Equal(t, m.httpServer.Addr, second, "server should have moved to the new address")
+	var resp *http.Response
+	resp, err = http.Get("http://" + second + "/ws/v1/unknown")
+	assert.NilError(t, err, "request to the new address failed")
+	assert.NilError(t, resp.Body.Close())
+	assert.Equal(t, resp.StatusCode, http.StatusNotFound, "unexpected response from the new address")
+	status, message := m.GetReadiness()
+	assert.Equal(t, status, readiness.StatusReady, "web service should be ready")
+	assert.Equal(t, message, "listening on "+second)
+
+	// address in use: the web service stays on the current address
+	var blocker net.Listener
+	blocker, err = net.Listen("tcp", "127.0.0.1:0")
+	assert.NilError(t, err, "failed to block a port")
+	defer blocker.Close()
+	m.updateConfig(configs.WebServiceConfig{Address: blocker.Addr().String()})
+	assert.Equal(t, m.httpServer.Addr, second, "server should have stayed on the current address")
+}